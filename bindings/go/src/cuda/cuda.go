@@ -12,6 +12,7 @@ package cuda
 import "C"
 import (
 	"errors"
+	"unsafe"
 )
 
 func CudaSetDevice() error {
@@ -43,3 +44,24 @@ func CudaSetDevice() error {
 
 	return nil
 }
+
+// Allocates the pinned host memory, that the device copies the data from by
+// DMA.
+func hostAlloc(size uint64) (unsafe.Pointer, error) {
+	var buffer unsafe.Pointer
+	if ret := C.cudaMallocHost(&buffer, C.size_t(size)); ret != C.cudaSuccess {
+		return nil, errors.New("Failed to allocate pinned host memory")
+	}
+	return buffer, nil
+}
+
+func hostFree(buffer unsafe.Pointer) {
+	C.cudaFreeHost(buffer)
+}
+
+func copyToDevice(dst unsafe.Pointer, src unsafe.Pointer, size uint64) error {
+	if ret := C.cudaMemcpy(dst, src, C.size_t(size), C.cudaMemcpyHostToDevice); ret != C.cudaSuccess {
+		return errors.New("Failed to copy memory to cuda device")
+	}
+	return nil
+}
@@ -9,8 +9,20 @@ package cuda
 
 import (
 	"errors"
+	"unsafe"
 )
 
 func CudaSetDevice() error {
 	return errors.New("cuda support is disabled")
 }
+
+func hostAlloc(size uint64) (unsafe.Pointer, error) {
+	return nil, errors.New("cuda support is disabled")
+}
+
+func hostFree(buffer unsafe.Pointer) {
+}
+
+func copyToDevice(dst unsafe.Pointer, src unsafe.Pointer, size uint64) error {
+	return errors.New("cuda support is disabled")
+}
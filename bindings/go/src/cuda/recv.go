@@ -0,0 +1,265 @@
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package cuda
+
+import (
+	"context"
+	"strings"
+	"sync"
+	. "ucx"
+	"ucx/ucxinfo"
+	"unsafe"
+)
+
+// Path of the message to the GPU memory, that is reported by GpuReceiver.
+type GpuRecvPath int
+
+const (
+	// UCX receives the message to the GPU memory itself, e.g. by GPUDirect
+	// RDMA of the network device
+	GpuRecvDirect GpuRecvPath = iota
+	// The message is received to the pinned host buffer, and copied to the
+	// GPU memory by cudaMemcpy()
+	GpuRecvStaged
+)
+
+func (p GpuRecvPath) String() string {
+	switch p {
+	case GpuRecvDirect:
+		return "direct"
+	case GpuRecvStaged:
+		return "staged"
+	}
+	return "unknown"
+}
+
+// This routine reports whether the network devices of UCX access the GPU
+// memory directly: the context supports the CUDA memory, and a memory domain
+// of the network, rather than of the CUDA copy transports, registers it, e.g.
+// the InfiniBand device with nvidia-peermem loaded.
+func GdrAvailable(context *UcpContext) bool {
+	memTypes, err := context.MemoryTypesMask()
+	if (err != nil) || !IsMemTypeSupported(UCS_MEMORY_TYPE_CUDA, memTypes) {
+		return false
+	}
+
+	domains, err := ucxinfo.MemoryDomains()
+	if err != nil {
+		return false
+	}
+
+	for i := range domains {
+		component := domains[i].Component
+		if strings.HasPrefix(component, "cuda") || strings.HasPrefix(component, "gdr") ||
+			strings.HasPrefix(component, "rocm") {
+			continue
+		}
+
+		if domains[i].CanRegister(UCS_MEMORY_TYPE_CUDA) {
+			return true
+		}
+	}
+	return false
+}
+
+// Receiver of the tag and Active Messages to the GPU memory, that picks the
+// path once, by GdrAvailable(), so the same code serves the hosts with and
+// without GPUDirect RDMA. The staged receives reuse the pinned host buffers of
+// the receiver, and allocate the larger ones temporarily. The routines of the
+// receiver progress the worker until the message is received, so they must
+// not be called concurrently with the other routines progressing the worker.
+type GpuReceiver struct {
+	worker     *UcpWorker
+	path       GpuRecvPath
+	bufferSize uint64
+	mu         sync.Mutex
+	buffers    []unsafe.Pointer
+}
+
+// Creates the receiver of the worker of the context, with the pinned host
+// buffers of bufferSize bytes for the staged receives. The receiver must be
+// closed by GpuReceiver.Close().
+func NewGpuReceiver(context *UcpContext, worker *UcpWorker, bufferSize uint64) *GpuReceiver {
+	r := &GpuReceiver{worker: worker, path: GpuRecvStaged, bufferSize: bufferSize}
+	if GdrAvailable(context) {
+		r.path = GpuRecvDirect
+	}
+	return r
+}
+
+// Stages the receives even though the direct path is available, e.g. for
+// the messages, which GPUDirect RDMA is slower for, or to compare both paths.
+func (r *GpuReceiver) ForceStaging() *GpuReceiver {
+	r.path = GpuRecvStaged
+	return r
+}
+
+// Returns the path of the receives.
+func (r *GpuReceiver) Path() GpuRecvPath {
+	return r.path
+}
+
+func (r *GpuReceiver) getBuffer(size uint64) (unsafe.Pointer, error) {
+	if size > r.bufferSize {
+		return hostAlloc(size)
+	}
+
+	r.mu.Lock()
+	if n := len(r.buffers); n > 0 {
+		buffer := r.buffers[n-1]
+		r.buffers = r.buffers[:n-1]
+		r.mu.Unlock()
+		return buffer, nil
+	}
+	r.mu.Unlock()
+	return hostAlloc(r.bufferSize)
+}
+
+func (r *GpuReceiver) putBuffer(buffer unsafe.Pointer, size uint64) {
+	if size > r.bufferSize {
+		hostFree(buffer)
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.buffers = append(r.buffers, buffer)
+}
+
+// Returns the params of the receive to the pinned host buffer, which has
+// neither the registration nor the memory type of the GPU buffer.
+func stagedParams(params *UcpRequestParams) *UcpRequestParams {
+	var result UcpRequestParams
+	if params != nil {
+		result = *params
+	}
+	return result.SetMemType(UCS_MEMORY_TYPE_HOST).SetMemory(nil).SetMemoryCache(nil)
+}
+
+// Waits for the request, and copies the received length, that is set by the
+// callback, from the pinned host buffer to the GPU buffer of the size.
+func (r *GpuReceiver) finish(ctx context.Context, request *UcpRequest, err error, address unsafe.Pointer,
+	buffer unsafe.Pointer, size uint64, length *uint64) error {
+	if err != nil {
+		return err
+	}
+	defer request.Close()
+
+	if err := request.WaitContext(ctx); err != nil {
+		return err
+	}
+
+	if *length < size {
+		size = *length
+	}
+
+	if size == 0 {
+		return nil
+	}
+	return copyToDevice(address, buffer, size)
+}
+
+// This routine receives the tag message to the GPU buffer, and returns the
+// info of the message and the path, that it's received by. The direct
+// receive is the same as UcpWorker.RecvTagNonBlocking() with the params, while
+// the staged one receives to the pinned host buffer with the params, that
+// skip the registration and the memory type of the GPU buffer, and copies the
+// data once the receive completes. The callback of the params isn't invoked.
+// ctx cancels the receive, that isn't completed yet.
+func (r *GpuReceiver) RecvTag(ctx context.Context, address unsafe.Pointer, size uint64, tag uint64,
+	tagMask uint64, params *UcpRequestParams) (*UcpTagRecvInfo, GpuRecvPath, error) {
+	var info UcpTagRecvInfo
+	cb := UcpTagRecvCallback(func(request *UcpRequest, status UcsStatus, tagInfo *UcpTagRecvInfo) {
+		info = *tagInfo
+	})
+
+	if r.path == GpuRecvDirect {
+		directParams := &UcpRequestParams{}
+		if params != nil {
+			*directParams = *params
+		}
+		directParams.Cb = cb
+
+		request, err := r.worker.RecvTagNonBlocking(address, size, tag, tagMask, directParams)
+		if err != nil {
+			return nil, r.path, err
+		}
+		defer request.Close()
+		if err := request.WaitContext(ctx); err != nil {
+			return nil, r.path, err
+		}
+		return &info, r.path, nil
+	}
+
+	buffer, err := r.getBuffer(size)
+	if err != nil {
+		return nil, r.path, err
+	}
+	defer r.putBuffer(buffer, size)
+
+	recvParams := stagedParams(params)
+	recvParams.Cb = cb
+	request, err := r.worker.RecvTagNonBlocking(buffer, size, tag, tagMask, recvParams)
+	if err := r.finish(ctx, request, err, address, buffer, size, &info.Length); err != nil {
+		return nil, r.path, err
+	}
+	return &info, r.path, nil
+}
+
+// This routine receives the data of the Active Message to the GPU buffer of at
+// least UcpAmData.Length() bytes, the same as UcpAmData.Receive() on the
+// direct path, and by the pinned host buffer on the staged path, and returns
+// the received length. It's called once the handler returns, with the data
+// held, e.g. in UcpAmDataModePersist mode, since it progresses the worker.
+func (r *GpuReceiver) RecvAm(ctx context.Context, data *UcpAmData, address unsafe.Pointer, size uint64,
+	params *UcpRequestParams) (uint64, GpuRecvPath, error) {
+	var length uint64
+	cb := UcpAmDataRecvCallback(func(request *UcpRequest, status UcsStatus, recvLength uint64) {
+		length = recvLength
+	})
+
+	if r.path == GpuRecvDirect {
+		directParams := &UcpRequestParams{}
+		if params != nil {
+			*directParams = *params
+		}
+		directParams.Cb = cb
+
+		request, err := data.Receive(address, size, directParams)
+		if err != nil {
+			return 0, r.path, err
+		}
+		defer request.Close()
+		if err := request.WaitContext(ctx); err != nil {
+			return 0, r.path, err
+		}
+		return length, r.path, nil
+	}
+
+	buffer, err := r.getBuffer(size)
+	if err != nil {
+		return 0, r.path, err
+	}
+	defer r.putBuffer(buffer, size)
+
+	recvParams := stagedParams(params)
+	recvParams.Cb = cb
+	request, err := data.Receive(buffer, size, recvParams)
+	if err := r.finish(ctx, request, err, address, buffer, size, &length); err != nil {
+		return 0, r.path, err
+	}
+	return length, r.path, nil
+}
+
+// Frees the pinned host buffers of the receiver.
+func (r *GpuReceiver) Close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, buffer := range r.buffers {
+		hostFree(buffer)
+	}
+	r.buffers = nil
+}
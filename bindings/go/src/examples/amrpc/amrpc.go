@@ -0,0 +1,290 @@
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+// Remote procedure calls over Active Messages: the server replies to each call
+// of the client with the CRC32 of its payload, and the client checks the
+// replies, keeping up to -depth calls in flight. Both exit with a non-zero
+// code, if the exchange fails:
+//
+//	amrpc -server -port 13338
+//	amrpc -ip <server ip> -port 13338 -calls 10000 -size 256 -depth 16
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"flag"
+	"fmt"
+	"hash/crc32"
+	"net"
+	"os"
+	"time"
+	. "ucx"
+	"unsafe"
+)
+
+const (
+	// The call carries its ID in the header and the payload in the data, the
+	// call of the empty payload ends the exchange
+	callAmId uint = 1
+	// The reply carries the call ID and the CRC32 of the payload in the header
+	replyAmId       uint = 2
+	replyHeaderSize      = 12
+)
+
+var (
+	server  = flag.Bool("server", false, "run the server")
+	ip      = flag.String("ip", "127.0.0.1", "address of the server")
+	port    = flag.Uint("port", 13338, "port of the server")
+	calls   = flag.Int("calls", 10000, "number of the calls, that the client makes")
+	size    = flag.Int("size", 256, "size of the payload of the calls")
+	depth   = flag.Int("depth", 16, "number of the calls in flight")
+	timeout = flag.Duration("timeout", time.Minute, "time of the whole exchange")
+)
+
+func epErrorHandler(ep *UcpEp, status UcsStatus) {
+	if status != UCS_ERR_CONNECTION_RESET {
+		fmt.Fprintf(os.Stderr, "Endpoint error: %v\n", status)
+	}
+}
+
+// Accepts the first connection to the listener, the others are rejected once
+// the listener is closed.
+func accept(ctx context.Context, worker *UcpWorker) (*UcpEp, error) {
+	var connRequest *UcpConnectionRequest
+	listenerParams := (&UcpListenerParams{}).SetConnectionHandler(func(request *UcpConnectionRequest) {
+		if connRequest == nil {
+			connRequest = request
+		}
+	})
+	addr, _ := net.ResolveTCPAddr("tcp", fmt.Sprintf("0.0.0.0:%v", *port))
+	listenerParams.SetSocketAddress(addr)
+
+	listener, err := worker.NewListener(listenerParams)
+	if err != nil {
+		return nil, err
+	}
+	defer listener.Close()
+	fmt.Printf("Listening on %v\n", addr)
+
+	for connRequest == nil {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		worker.Progress()
+	}
+
+	return worker.NewEndpoint((&UcpEpParams{}).SetConnRequest(connRequest).
+		SetPeerErrorHandling().SetErrorHandler(epErrorHandler))
+}
+
+func closeEp(ctx context.Context, ep *UcpEp) {
+	if request, err := ep.CloseNonBlockingFlush(nil); err == nil {
+		request.WaitContext(ctx)
+		request.Close()
+	}
+}
+
+// Sends the Active Message with the header copied by the library, so the
+// native header is freed right away, and the request isn't waited for.
+func sendAm(ep *UcpEp, id uint, header []byte, data []byte) error {
+	cHeader := CBytes(header)
+	defer FreeNativeMemory(cHeader)
+
+	request, err := ep.SendAmBytesNonBlocking(id, cHeader, uint64(len(header)), data,
+		UCP_AM_SEND_FLAG_REPLY|UCP_AM_SEND_FLAG_COPY_HEADER, nil)
+	if err != nil {
+		return err
+	}
+	request.Close()
+	return nil
+}
+
+func reply(ep *UcpEp, callId uint64, payload []byte) error {
+	header := make([]byte, replyHeaderSize)
+	binary.LittleEndian.PutUint64(header, callId)
+	binary.LittleEndian.PutUint32(header[8:], crc32.ChecksumIEEE(payload))
+	return sendAm(ep, replyAmId, header, nil)
+}
+
+func runServer(ctx context.Context, worker *UcpWorker) error {
+	served := 0
+	done := false
+	var serveErr error
+
+	// The handler runs on the progress of the main goroutine, so the state
+	// isn't guarded
+	err := worker.SetAmRecvHandlerWithMode(callAmId, UCP_AM_FLAG_WHOLE_MSG, UcpAmDataModeCopy,
+		func(header unsafe.Pointer, headerSize uint64, data *UcpAmData, replyEp *UcpEp) UcsStatus {
+			if (replyEp == nil) || (headerSize != 8) {
+				return UCS_OK
+			}
+
+			callId := binary.LittleEndian.Uint64(AmHeader(header, headerSize))
+			if data.Length() == 0 {
+				done = true
+				return UCS_OK
+			}
+
+			if data.IsDataValid() {
+				served++
+				if err := reply(replyEp, callId, data.Bytes()); err != nil {
+					serveErr = err
+				}
+				return UCS_OK
+			}
+
+			// The rendezvous payload is received before the reply
+			payload := make([]byte, data.Length())
+			params := &UcpRequestParams{Cb: UcpAmDataRecvCallback(
+				func(request *UcpRequest, status UcsStatus, length uint64) {
+					served++
+					if status != UCS_OK {
+						serveErr = NewUcxError(status)
+					} else if err := reply(replyEp, callId, payload[:length]); err != nil {
+						serveErr = err
+					}
+				})}
+			if request, err := data.ReceiveBytes(payload, params); err != nil {
+				serveErr = err
+			} else {
+				request.Close()
+			}
+			return UCS_OK
+		})
+	if err != nil {
+		return err
+	}
+
+	ep, err := accept(ctx, worker)
+	if err != nil {
+		return err
+	}
+	defer closeEp(ctx, ep)
+
+	for !done && (serveErr == nil) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		worker.Progress()
+	}
+
+	if serveErr != nil {
+		return serveErr
+	}
+	fmt.Printf("Served %v calls\n", served)
+	return nil
+}
+
+func runClient(ctx context.Context, worker *UcpWorker) error {
+	if *size <= 0 {
+		return fmt.Errorf("size of the payload must be positive, the empty one ends the exchange")
+	}
+
+	// CRC32 of the payloads of the calls in flight
+	pending := make(map[uint64]uint32)
+	var callErr error
+
+	err := worker.SetAmRecvHandler(replyAmId, UCP_AM_FLAG_WHOLE_MSG,
+		func(header unsafe.Pointer, headerSize uint64, data *UcpAmData, replyEp *UcpEp) UcsStatus {
+			if headerSize != replyHeaderSize {
+				callErr = fmt.Errorf("reply header of %v bytes", headerSize)
+				return UCS_OK
+			}
+
+			replyHeader := AmHeader(header, headerSize)
+			callId := binary.LittleEndian.Uint64(replyHeader)
+			expected, found := pending[callId]
+			if !found {
+				callErr = fmt.Errorf("reply to unknown call %v", callId)
+			} else if crc := binary.LittleEndian.Uint32(replyHeader[8:]); crc != expected {
+				callErr = fmt.Errorf("call %v returned CRC32 %x instead of %x", callId, crc, expected)
+			}
+			delete(pending, callId)
+			return UCS_OK
+		})
+	if err != nil {
+		return err
+	}
+
+	epParams := (&UcpEpParams{}).SetAddress(fmt.Sprintf("%v:%v", *ip, *port)).
+		SetPeerErrorHandling().SetErrorHandler(epErrorHandler)
+	ep, err := worker.Connect(ctx, epParams)
+	if err != nil {
+		return err
+	}
+	defer closeEp(ctx, ep)
+
+	header := make([]byte, 8)
+	start := time.Now()
+	for callId := uint64(0); (callId < uint64(*calls)) || (len(pending) > 0); {
+		if callErr != nil {
+			return callErr
+		}
+
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("%v calls are not replied: %w", len(pending), err)
+		}
+
+		if (callId >= uint64(*calls)) || (len(pending) >= *depth) {
+			worker.Progress()
+			continue
+		}
+
+		payload := make([]byte, *size)
+		for i := range payload {
+			payload[i] = byte(callId) + byte(i)
+		}
+
+		binary.LittleEndian.PutUint64(header, callId)
+		pending[callId] = crc32.ChecksumIEEE(payload)
+		if err := sendAm(ep, callAmId, header, payload); err != nil {
+			return fmt.Errorf("call %v: %w", callId, err)
+		}
+		callId++
+	}
+	elapsed := time.Since(start)
+
+	binary.LittleEndian.PutUint64(header, uint64(*calls))
+	if err := sendAm(ep, callAmId, header, nil); err != nil {
+		return fmt.Errorf("last call: %w", err)
+	}
+
+	if *calls > 0 {
+		fmt.Printf("%v calls of %v bytes in %v, %.0f calls/s\n", *calls, *size, elapsed,
+			float64(*calls)/elapsed.Seconds())
+	}
+	return nil
+}
+
+func run() error {
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	context, err := NewUcpContext((&UcpParams{}).EnableAM())
+	if err != nil {
+		return err
+	}
+	defer context.Close()
+
+	worker, err := context.NewWorker(&UcpWorkerParams{})
+	if err != nil {
+		return err
+	}
+	defer worker.Close()
+
+	if *server {
+		return runServer(ctx, worker)
+	}
+	return runClient(ctx, worker)
+}
+
+func main() {
+	flag.Parse()
+	if err := run(); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,144 @@
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+// Peer of the example programs of the other UCX bindings, see the ucxinterop
+// package. It takes the options of the programs, and exits with a non-zero
+// code, if the exchange with the program fails:
+//
+//	goucxinterop -program client-server [-a <server ip>] -p 13337 -c tag -i 1 -s 16
+//	goucxinterop -program read-bw -role receiver -p 54321 -n 5 -t 10000
+//	goucxinterop -program read-bw -role sender -a <receiver ip> -p 54321
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"time"
+	. "ucx"
+	"ucx/ucxinterop"
+)
+
+var (
+	program    = flag.String("program", "client-server", "program of the peer: client-server or read-bw")
+	role       = flag.String("role", "", "role of read-bw: sender or receiver")
+	address    = flag.String("a", "", "address of the peer, client-server runs the server if it's not set")
+	port       = flag.Int("p", 0, "port of the server or the receiver, the default of the program if it's 0")
+	comm       = flag.String("c", "stream", "API of client-server: stream, tag or am")
+	iterations = flag.Int("i", 1, "number of the messages of client-server")
+	msgSize    = flag.Uint64("s", 16, "size of the messages of client-server")
+	reads      = flag.Int("n", 5, "number of the reads of read-bw")
+	totalSize  = flag.Uint64("t", 10000, "size of the memory of read-bw")
+	timeout    = flag.Duration("timeout", ucxinterop.DefaultTimeout, "time of the whole exchange")
+)
+
+func printListening(addr *net.TCPAddr) {
+	fmt.Printf("Listening on %v\n", addr)
+}
+
+func peerAddr(defaultPort int) (*net.TCPAddr, error) {
+	if *port == 0 {
+		*port = defaultPort
+	}
+
+	host := *address
+	if host == "" {
+		host = "0.0.0.0"
+	}
+	return net.ResolveTCPAddr("tcp", fmt.Sprintf("%v:%v", host, *port))
+}
+
+func runClientServer() error {
+	commType, err := ucxinterop.ParseCommType(*comm)
+	if err != nil {
+		return fmt.Errorf("invalid API %q", *comm)
+	}
+
+	addr, err := peerAddr(ucxinterop.ClientServerPort)
+	if err != nil {
+		return err
+	}
+
+	context, err := NewUcpContext(commType.Features(&UcpParams{}))
+	if err != nil {
+		return err
+	}
+	defer context.Close()
+
+	worker, err := context.NewWorker(&UcpWorkerParams{})
+	if err != nil {
+		return err
+	}
+	defer worker.Close()
+
+	config := ucxinterop.ClientServerConfig{
+		Comm:        commType,
+		Iterations:  *iterations,
+		MessageSize: *msgSize,
+		Timeout:     *timeout,
+		OnListen:    printListening,
+	}
+
+	if *address == "" {
+		return ucxinterop.RunClientServerServer(worker, addr, config)
+	}
+	return ucxinterop.RunClientServerClient(worker, addr, config)
+}
+
+func runReadBW() error {
+	addr, err := peerAddr(ucxinterop.ReadBWPort)
+	if err != nil {
+		return err
+	}
+
+	context, err := NewUcpContext((&UcpParams{}).EnableTag().EnableRMA())
+	if err != nil {
+		return err
+	}
+	defer context.Close()
+
+	worker, err := context.NewWorker(&UcpWorkerParams{})
+	if err != nil {
+		return err
+	}
+	defer worker.Close()
+
+	config := ucxinterop.ReadBWConfig{
+		Iterations: *reads,
+		Size:       *totalSize,
+		Timeout:    *timeout,
+		OnListen:   printListening,
+	}
+
+	switch *role {
+	case "sender":
+		return ucxinterop.RunReadBWSender(context, worker, addr, config)
+	case "receiver":
+		return ucxinterop.RunReadBWReceiver(context, worker, addr, config)
+	}
+	return fmt.Errorf("invalid role %q", *role)
+}
+
+func main() {
+	flag.Parse()
+
+	start := time.Now()
+	var err error
+	switch *program {
+	case "client-server":
+		err = runClientServer()
+	case "read-bw":
+		err = runReadBW()
+	default:
+		err = fmt.Errorf("unknown program %q", *program)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Exchange with %v succeeded in %v\n", *program, time.Since(start))
+}
@@ -0,0 +1,293 @@
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+// goucxperf measures latency and bandwidth of the tag, stream, active message
+// and RMA operations through the Go bindings, same as ucx_perftest does with
+// the C API. The server is started without -i and serves a single client,
+// which runs all the requested tests and prints the results.
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+	. "ucx"
+)
+
+type perfParams struct {
+	tests      []perfTest
+	modes      []perfMode
+	sizes      []uint64
+	iterations uint64
+	warmupIter uint64
+	window     int
+	memType    UcsMemoryType
+	format     string
+	cpus       []int
+	ip         string
+	port       uint
+}
+
+type perfResult struct {
+	Test       string  `json:"test"`
+	Mode       string  `json:"mode"`
+	Size       uint64  `json:"size"`
+	Iterations uint64  `json:"iterations"`
+	LatencyUs  float64 `json:"latency_us"`
+	Bandwidth  float64 `json:"bandwidth_mb_s"`
+	MsgRate    float64 `json:"msg_rate"`
+}
+
+var params = perfParams{}
+
+// Parses the size with an optional k, m or g suffix.
+func parseSize(s string) (uint64, error) {
+	multiplier := uint64(1)
+	switch strings.ToLower(s[len(s)-1:]) {
+	case "k":
+		multiplier = 1 << 10
+	case "m":
+		multiplier = 1 << 20
+	case "g":
+		multiplier = 1 << 30
+	}
+
+	if multiplier != 1 {
+		s = s[:len(s)-1]
+	}
+
+	size, err := strconv.ParseUint(s, 10, 64)
+	return size * multiplier, err
+}
+
+// Parses comma separated sizes and ranges "min:max", which are swept by the
+// powers of two.
+func parseSizes(s string) error {
+	params.sizes = nil
+	for _, item := range strings.Split(s, ",") {
+		bounds := strings.SplitN(item, ":", 2)
+		min, err := parseSize(bounds[0])
+		if err != nil {
+			return err
+		}
+
+		max := min
+		if len(bounds) == 2 {
+			if max, err = parseSize(bounds[1]); err != nil {
+				return err
+			}
+		}
+
+		if (min == 0) || (max < min) {
+			return fmt.Errorf("invalid size range %v", item)
+		}
+
+		for size := min; size <= max; size *= 2 {
+			params.sizes = append(params.sizes, size)
+		}
+	}
+	return nil
+}
+
+func parseTests(s string) error {
+	params.tests = nil
+	for _, name := range strings.Split(s, ",") {
+		test, ok := parseTest(name)
+		if !ok {
+			return fmt.Errorf("unknown test %v, supported: %v", name, strings.Join(testNames[:], ","))
+		}
+		params.tests = append(params.tests, test)
+	}
+	return nil
+}
+
+func parseModes(s string) error {
+	params.modes = nil
+	for _, name := range strings.Split(s, ",") {
+		switch name {
+		case "lat":
+			params.modes = append(params.modes, modeLatency)
+		case "bw":
+			params.modes = append(params.modes, modeBandwidth)
+		default:
+			return fmt.Errorf("unknown mode %v, supported: lat,bw", name)
+		}
+	}
+	return nil
+}
+
+func parseCpus(s string) error {
+	params.cpus = nil
+	for _, item := range strings.Split(s, ",") {
+		cpu, err := strconv.Atoi(item)
+		if err != nil {
+			return err
+		}
+		params.cpus = append(params.cpus, cpu)
+	}
+	return nil
+}
+
+// Pins the current OS thread, which runs the benchmark, to the CPUs.
+func setAffinity() error {
+	if len(params.cpus) == 0 {
+		return nil
+	}
+
+	if err := LockOSThreadToCpus(params.cpus); err != nil {
+		return fmt.Errorf("failed to set CPU affinity: %v", err)
+	}
+	return nil
+}
+
+func printResults(results []perfResult) error {
+	switch params.format {
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(results)
+	case "csv":
+		writer := csv.NewWriter(os.Stdout)
+		writer.Write([]string{"test", "mode", "size", "iterations", "latency_us", "bandwidth_mb_s", "msg_rate"})
+		for _, r := range results {
+			writer.Write([]string{r.Test, r.Mode, strconv.FormatUint(r.Size, 10),
+				strconv.FormatUint(r.Iterations, 10), strconv.FormatFloat(r.LatencyUs, 'f', 3, 64),
+				strconv.FormatFloat(r.Bandwidth, 'f', 2, 64), strconv.FormatFloat(r.MsgRate, 'f', 0, 64)})
+		}
+		writer.Flush()
+		return writer.Error()
+	}
+
+	dashes := strings.Repeat("-", 14)
+	fmt.Printf("|%14s|%14s|%14s|%14s|%14s|%14s|\n", dashes, dashes, dashes, dashes, dashes, dashes)
+	fmt.Printf("|%14s|%14s|%14s|%14s|%14s|%14s|\n", "Test", "Mode", "Size", "Latency (us)",
+		"BW (MB/s)", "Msg rate")
+	fmt.Printf("|%14s|%14s|%14s|%14s|%14s|%14s|\n", dashes, dashes, dashes, dashes, dashes, dashes)
+	for _, r := range results {
+		fmt.Printf("|%14s|%14s|%14d|%14.3f|%14.2f|%14.0f|\n", r.Test, r.Mode, r.Size, r.LatencyUs,
+			r.Bandwidth, r.MsgRate)
+	}
+	fmt.Printf("|%14s|%14s|%14s|%14s|%14s|%14s|\n", dashes, dashes, dashes, dashes, dashes, dashes)
+	return nil
+}
+
+func newResult(test perfTest, mode perfMode, size uint64, elapsed time.Duration) perfResult {
+	seconds := elapsed.Seconds()
+	latency := seconds / float64(params.iterations)
+	if (mode == modeLatency) && test.isPingPong() {
+		// Round trip of the message
+		latency /= 2
+	}
+
+	return perfResult{
+		Test:       test.String(),
+		Mode:       mode.String(),
+		Size:       size,
+		Iterations: params.iterations,
+		LatencyUs:  latency * 1e6,
+		Bandwidth:  float64(size*params.iterations) / seconds / (1 << 20),
+		MsgRate:    float64(params.iterations) / seconds,
+	}
+}
+
+func clientRun() error {
+	maxSize := uint64(0)
+	for _, size := range params.sizes {
+		if size > maxSize {
+			maxSize = size
+		}
+	}
+
+	p, err := newClient(maxSize)
+	if err != nil {
+		return err
+	}
+	defer p.close()
+
+	var results []perfResult
+	for _, test := range params.tests {
+		for _, mode := range params.modes {
+			for _, size := range params.sizes {
+				if params.warmupIter > 0 {
+					if _, err := p.run(test, mode, size, params.warmupIter); err != nil {
+						return err
+					}
+				}
+
+				elapsed, err := p.run(test, mode, size, params.iterations)
+				if err != nil {
+					return fmt.Errorf("%v %v of size %v: %w", test, mode, size, err)
+				}
+				results = append(results, newResult(test, mode, size, elapsed))
+			}
+		}
+	}
+
+	if err := p.finish(); err != nil {
+		return err
+	}
+	return printResults(results)
+}
+
+func main() {
+	flag.StringVar(&params.ip, "i", "", "server address to connect, the server is started if not set")
+	flag.UintVar(&params.port, "p", 36459, "port to bind: 36459(default)")
+	flag.Uint64Var(&params.iterations, "n", 1000, "number of iterations to run: 1000(default)")
+	flag.Uint64Var(&params.warmupIter, "warmup", 100, "warmup iterations: 100(default)")
+	flag.IntVar(&params.window, "W", 32, "number of outstanding operations in bandwidth tests: 32(default)")
+	flag.StringVar(&params.format, "f", "table", "output format: table(default), csv, json")
+
+	parseSizes("8")
+	flag.CommandLine.Func("s", "message sizes, e.g. 8,64,1k or 8:1m for powers of two: 8(default)", parseSizes)
+	parseTests("tag")
+	flag.CommandLine.Func("t", "tests: tag(default), stream, am, put, get, comma separated", parseTests)
+	parseModes("lat")
+	flag.CommandLine.Func("mode", "modes: lat(default), bw, comma separated", parseModes)
+	flag.CommandLine.Func("c", "CPUs to pin the benchmark thread, comma separated", parseCpus)
+
+	params.memType = UCS_MEMORY_TYPE_HOST
+	flag.CommandLine.Func("m", "memory type: host(default), cuda", func(p string) error {
+		mtypeStr := strings.ToLower(p)
+		if mtypeStr == "host" {
+			params.memType = UCS_MEMORY_TYPE_HOST
+		} else if mtypeStr == "cuda" {
+			params.memType = UCS_MEMORY_TYPE_CUDA
+		} else {
+			return errors.New("memory type can be host or cuda")
+		}
+		return nil
+	})
+
+	flag.Parse()
+
+	if (params.iterations == 0) || (params.window <= 0) {
+		fmt.Fprintf(os.Stderr, "number of iterations and window must be positive\n")
+		os.Exit(1)
+	}
+
+	// All UCX calls are made from the main goroutine with the single-threaded
+	// worker, so keep it on the pinned thread
+	runtime.LockOSThread()
+	err := setAffinity()
+	if err == nil {
+		if params.ip == "" {
+			err = serverRun()
+		} else {
+			err = clientRun()
+		}
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error running benchmark: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,567 @@
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package main
+
+import (
+	. "cuda"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"time"
+	. "ucx"
+	"unsafe"
+)
+
+type perfTest uint32
+
+const (
+	testTag perfTest = iota
+	testStream
+	testAm
+	testPut
+	testGet
+)
+
+var testNames = [...]string{"tag", "stream", "am", "put", "get"}
+
+func parseTest(name string) (perfTest, bool) {
+	for i, testName := range testNames {
+		if testName == name {
+			return perfTest(i), true
+		}
+	}
+	return 0, false
+}
+
+func (t perfTest) String() string {
+	return testNames[t]
+}
+
+// Ping-pong tests measure the round trip, RMA tests measure the operation
+// completion at the origin.
+func (t perfTest) isPingPong() bool {
+	return (t == testTag) || (t == testStream) || (t == testAm)
+}
+
+type perfMode uint32
+
+const (
+	modeLatency perfMode = iota
+	modeBandwidth
+)
+
+func (m perfMode) String() string {
+	if m == modeLatency {
+		return "lat"
+	}
+	return "bw"
+}
+
+// Tags of the control messages, that synchronize the client and the server,
+// and of the test data.
+const (
+	ctrlTag uint64 = 1
+	ackTag  uint64 = 2
+	rkeyTag uint64 = 3
+	dataTag uint64 = 4
+
+	amId uint = 0
+)
+
+// Commands of the control message, besides the tests.
+const (
+	cmdHello  uint32 = 0xfffffffe
+	cmdFinish uint32 = 0xffffffff
+)
+
+// Control message: command or test, mode, message size and iterations.
+const ctrlSize = 24
+
+type perf struct {
+	context    *UcpContext
+	worker     *UcpWorker
+	listener   *UcpListener
+	ep         *UcpEp
+	memory     *UcpMemory
+	buffer     unsafe.Pointer
+	ctrl       unsafe.Pointer
+	rkey       *UcpRkey
+	remoteAddr uint64
+	amArrived  uint64
+}
+
+func newPerf() (*perf, error) {
+	context, err := NewUcpContext((&UcpParams{}).EnableTag().EnableStream().EnableAM().EnableRMA())
+	if err != nil {
+		return nil, err
+	}
+
+	p := &perf{
+		context: context,
+		ctrl:    AllocateNativeMemory(ctrlSize),
+	}
+
+	if p.worker, err = context.NewWorker(&UcpWorkerParams{}); err != nil {
+		p.close()
+		return nil, err
+	}
+
+	// Arrived messages are counted, the data is received to the buffer
+	p.worker.SetAmRecvHandler(amId, UCP_AM_FLAG_WHOLE_MSG, func(header unsafe.Pointer, headerSize uint64,
+		data *UcpAmData, replyEp *UcpEp) UcsStatus {
+		if data.IsDataValid() {
+			p.amArrived++
+			return UCS_OK
+		}
+
+		data.Receive(p.buffer, data.Length(), p.requestParams().SetCallback(
+			func(request *UcpRequest, status UcsStatus, length uint64) {
+				p.amArrived++
+				request.Close()
+			}))
+		return UCS_OK
+	})
+	return p, nil
+}
+
+func (p *perf) close() {
+	if p.rkey != nil {
+		p.rkey.Close()
+	}
+
+	if p.ep != nil {
+		p.wait(p.ep.CloseNonBlockingFlush(nil))
+	}
+
+	if p.listener != nil {
+		p.listener.Close()
+	}
+
+	if p.memory != nil {
+		p.memory.Close()
+	}
+
+	if p.worker != nil {
+		p.worker.Close()
+	}
+
+	FreeNativeMemory(p.ctrl)
+	p.context.Close()
+}
+
+func (p *perf) requestParams() *UcpRequestParams {
+	return (&UcpRequestParams{}).SetMemType(params.memType)
+}
+
+func (p *perf) allocate(size uint64) error {
+	if params.memType == UCS_MEMORY_TYPE_CUDA {
+		if err := CudaSetDevice(); err != nil {
+			return err
+		}
+	}
+
+	var err error
+	p.memory, _, err = p.context.AllocAndMap(size, (&UcpMmapParams{}).SetMemoryType(params.memType))
+	if err != nil {
+		return err
+	}
+
+	memAttrs, err := p.memory.Query(UCP_MEM_ATTR_FIELD_ADDRESS)
+	if err != nil {
+		return err
+	}
+	p.buffer = memAttrs.Address
+	return nil
+}
+
+// Progresses the worker until the request is completed and releases it.
+func (p *perf) wait(request *UcpRequest, err error) error {
+	if err != nil {
+		if request != nil {
+			request.Close()
+		}
+		return err
+	}
+
+	for request.GetStatus() == UCS_INPROGRESS {
+		p.worker.Progress()
+	}
+
+	status := request.GetStatus()
+	request.Close()
+	return status.Err()
+}
+
+// Posts n operations keeping up to params.window of them in flight.
+func (p *perf) pipeline(n uint64, post func() (*UcpRequest, error)) error {
+	inflight := make([]*UcpRequest, 0, params.window)
+	defer func() {
+		for _, request := range inflight {
+			p.wait(request, nil)
+		}
+	}()
+
+	for i := uint64(0); i < n; i++ {
+		request, err := post()
+		if err != nil {
+			if request != nil {
+				request.Close()
+			}
+			return err
+		}
+
+		inflight = append(inflight, request)
+		if len(inflight) < params.window {
+			continue
+		}
+
+		err = p.wait(inflight[0], nil)
+		inflight = append(inflight[:0], inflight[1:]...)
+		if err != nil {
+			return err
+		}
+	}
+
+	for len(inflight) > 0 {
+		err := p.wait(inflight[0], nil)
+		inflight = inflight[1:]
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *perf) sendCtrl(tag uint64, cmd uint32, mode perfMode, size uint64, n uint64) error {
+	ctrl := (*[ctrlSize]byte)(p.ctrl)[:]
+	binary.LittleEndian.PutUint32(ctrl[0:], cmd)
+	binary.LittleEndian.PutUint32(ctrl[4:], uint32(mode))
+	binary.LittleEndian.PutUint64(ctrl[8:], size)
+	binary.LittleEndian.PutUint64(ctrl[16:], n)
+	return p.wait(p.ep.SendTagNonBlocking(tag, p.ctrl, ctrlSize, nil))
+}
+
+func (p *perf) recvCtrl(tag uint64) (uint32, perfMode, uint64, uint64, error) {
+	if err := p.wait(p.worker.RecvTagNonBlocking(p.ctrl, ctrlSize, tag, ^uint64(0), nil)); err != nil {
+		return 0, 0, 0, 0, err
+	}
+
+	ctrl := (*[ctrlSize]byte)(p.ctrl)[:]
+	return binary.LittleEndian.Uint32(ctrl[0:]), perfMode(binary.LittleEndian.Uint32(ctrl[4:])),
+		binary.LittleEndian.Uint64(ctrl[8:]), binary.LittleEndian.Uint64(ctrl[16:]), nil
+}
+
+func (p *perf) ack() error {
+	return p.sendCtrl(ackTag, 0, 0, 0, 0)
+}
+
+func (p *perf) waitAck() error {
+	_, _, _, _, err := p.recvCtrl(ackTag)
+	return err
+}
+
+func (p *perf) sendTag(size uint64) (*UcpRequest, error) {
+	return p.ep.SendTagNonBlocking(dataTag, p.buffer, size, p.requestParams())
+}
+
+func (p *perf) recvTag(size uint64) (*UcpRequest, error) {
+	return p.worker.RecvTagNonBlocking(p.buffer, size, dataTag, ^uint64(0), p.requestParams())
+}
+
+func (p *perf) sendStream(size uint64) (*UcpRequest, error) {
+	return p.ep.SendStreamNonBlocking(p.buffer, size, p.requestParams())
+}
+
+func (p *perf) recvStream(size uint64) (*UcpRequest, error) {
+	return p.ep.RecvStreamNonBlocking(p.buffer, size,
+		p.requestParams().SetStreamRecvFlags(UCP_STREAM_RECV_FLAG_WAITALL))
+}
+
+func (p *perf) sendAm(size uint64) (*UcpRequest, error) {
+	return p.ep.SendAmNonBlocking(amId, nil, 0, p.buffer, size, 0, p.requestParams())
+}
+
+func (p *perf) waitAm(count uint64) {
+	for p.amArrived < count {
+		p.worker.Progress()
+	}
+}
+
+func (p *perf) put(size uint64) (*UcpRequest, error) {
+	return p.ep.RmaPutNonBlocking(p.buffer, size, p.remoteAddr, p.rkey, p.requestParams())
+}
+
+func (p *perf) get(size uint64) (*UcpRequest, error) {
+	return p.ep.RmaGetNonBlocking(p.buffer, size, p.remoteAddr, p.rkey, p.requestParams())
+}
+
+func (p *perf) flush() error {
+	return p.wait(p.ep.FlushNonBlocking(nil))
+}
+
+func newClient(maxSize uint64) (*perf, error) {
+	p, err := newPerf()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.connect(maxSize); err != nil {
+		p.close()
+		return nil, err
+	}
+	return p, nil
+}
+
+func (p *perf) connect(maxSize uint64) error {
+	if err := p.allocate(maxSize); err != nil {
+		return err
+	}
+
+	serverAddress, err := net.ResolveTCPAddr("tcp", fmt.Sprintf("%v:%v", params.ip, params.port))
+	if err != nil {
+		return err
+	}
+
+	epParams, err := (&UcpEpParams{}).SetPeerErrorHandling().SetSocketAddress(serverAddress)
+	if err != nil {
+		return err
+	}
+
+	if p.ep, err = p.worker.NewEndpoint(epParams); err != nil {
+		return err
+	}
+
+	if err := p.sendCtrl(ctrlTag, cmdHello, 0, maxSize, 0); err != nil {
+		return err
+	}
+
+	// Remote address followed by the packed remote key of the server buffer
+	message := p.worker.TagProbe(rkeyTag, ^uint64(0), true)
+	for message == nil {
+		p.worker.Progress()
+		message = p.worker.TagProbe(rkeyTag, ^uint64(0), true)
+	}
+
+	rkeyBuffer := AllocateNativeMemory(message.Info.Length)
+	defer FreeNativeMemory(rkeyBuffer)
+	if err := p.wait(p.worker.RecvTagMsgNonBlocking(rkeyBuffer, message.Info.Length, message, nil)); err != nil {
+		return err
+	}
+
+	rkeyMessage := GoBytes(rkeyBuffer, message.Info.Length)
+	p.remoteAddr = binary.LittleEndian.Uint64(rkeyMessage)
+	p.rkey, err = p.ep.UnpackRkey(rkeyMessage[8:])
+	return err
+}
+
+// Runs n iterations of the test and returns their duration. The server
+// acknowledges the test, so its responder is ready before the timing starts.
+func (p *perf) run(test perfTest, mode perfMode, size uint64, n uint64) (time.Duration, error) {
+	if err := p.sendCtrl(ctrlTag, uint32(test), mode, size, n); err != nil {
+		return 0, err
+	}
+
+	if err := p.waitAck(); err != nil {
+		return 0, err
+	}
+
+	var err error
+	start := time.Now()
+	switch {
+	case (test == testTag) && (mode == modeLatency):
+		for i := uint64(0); (i < n) && (err == nil); i++ {
+			recvRequest, recvErr := p.recvTag(size)
+			if err = p.wait(p.sendTag(size)); err == nil {
+				err = p.wait(recvRequest, recvErr)
+			}
+		}
+	case (test == testStream) && (mode == modeLatency):
+		for i := uint64(0); (i < n) && (err == nil); i++ {
+			if err = p.wait(p.sendStream(size)); err == nil {
+				err = p.wait(p.recvStream(size))
+			}
+		}
+	case (test == testAm) && (mode == modeLatency):
+		base := p.amArrived
+		for i := uint64(1); (i <= n) && (err == nil); i++ {
+			err = p.wait(p.sendAm(size))
+			p.waitAm(base + i)
+		}
+	case (test == testPut) && (mode == modeLatency):
+		for i := uint64(0); (i < n) && (err == nil); i++ {
+			if err = p.wait(p.put(size)); err == nil {
+				err = p.flush()
+			}
+		}
+	case (test == testGet) && (mode == modeLatency):
+		for i := uint64(0); (i < n) && (err == nil); i++ {
+			err = p.wait(p.get(size))
+		}
+	default:
+		err = p.runBandwidth(test, size, n)
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	if !test.isPingPong() {
+		// RMA operations are passive on the server, so let it proceed
+		err = p.ack()
+	} else if mode == modeBandwidth {
+		err = p.waitAck()
+	}
+	return time.Since(start), err
+}
+
+func (p *perf) runBandwidth(test perfTest, size uint64, n uint64) error {
+	switch test {
+	case testTag:
+		return p.pipeline(n, func() (*UcpRequest, error) { return p.sendTag(size) })
+	case testStream:
+		return p.pipeline(n, func() (*UcpRequest, error) { return p.sendStream(size) })
+	case testAm:
+		return p.pipeline(n, func() (*UcpRequest, error) { return p.sendAm(size) })
+	case testPut:
+		if err := p.pipeline(n, func() (*UcpRequest, error) { return p.put(size) }); err != nil {
+			return err
+		}
+		return p.flush()
+	}
+	return p.pipeline(n, func() (*UcpRequest, error) { return p.get(size) })
+}
+
+func (p *perf) finish() error {
+	return p.sendCtrl(ctrlTag, cmdFinish, 0, 0, 0)
+}
+
+func serverRun() error {
+	p, err := newPerf()
+	if err != nil {
+		return err
+	}
+	defer p.close()
+
+	if err := p.accept(); err != nil {
+		return err
+	}
+
+	for {
+		cmd, mode, size, n, err := p.recvCtrl(ctrlTag)
+		if err != nil {
+			return err
+		}
+
+		switch cmd {
+		case cmdHello:
+			err = p.sendRkey(size)
+		case cmdFinish:
+			return nil
+		default:
+			err = p.respond(perfTest(cmd), mode, size, n)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (p *perf) accept() error {
+	addr, err := net.ResolveTCPAddr("tcp", fmt.Sprintf("0.0.0.0:%v", params.port))
+	if err != nil {
+		return err
+	}
+
+	listenerParams, err := (&UcpListenerParams{}).SetSocketAddress(addr)
+	if err != nil {
+		return err
+	}
+
+	listenerParams.SetConnectionHandler(func(connRequest *UcpConnectionRequest) {
+		if p.ep != nil {
+			connRequest.Reject()
+			return
+		}
+
+		p.ep, err = p.worker.NewEndpointFromConnRequest(connRequest,
+			(&UcpEpParams{}).SetPeerErrorHandling())
+	})
+
+	if p.listener, err = p.worker.NewListener(listenerParams); err != nil {
+		return err
+	}
+	fmt.Printf("Started goucxperf server on address: %v\n", addr)
+
+	for (p.ep == nil) && (err == nil) {
+		p.worker.Progress()
+	}
+	return err
+}
+
+func (p *perf) sendRkey(size uint64) error {
+	if err := p.allocate(size); err != nil {
+		return err
+	}
+
+	rkey, err := p.memory.RkeyPack()
+	if err != nil {
+		return err
+	}
+
+	message := make([]byte, 8+len(rkey))
+	binary.LittleEndian.PutUint64(message, uint64(uintptr(p.buffer)))
+	copy(message[8:], rkey)
+
+	nativeMessage := CBytes(message)
+	defer FreeNativeMemory(nativeMessage)
+	return p.wait(p.ep.SendTagNonBlocking(rkeyTag, nativeMessage, uint64(len(message)), nil))
+}
+
+// Serves n iterations of the test, that the client runs.
+func (p *perf) respond(test perfTest, mode perfMode, size uint64, n uint64) error {
+	base := p.amArrived
+	if err := p.ack(); err != nil {
+		return err
+	}
+
+	var err error
+	switch {
+	case !test.isPingPong():
+		return p.waitAck()
+	case (test == testTag) && (mode == modeLatency):
+		for i := uint64(0); (i < n) && (err == nil); i++ {
+			if err = p.wait(p.recvTag(size)); err == nil {
+				err = p.wait(p.sendTag(size))
+			}
+		}
+		return err
+	case (test == testStream) && (mode == modeLatency):
+		for i := uint64(0); (i < n) && (err == nil); i++ {
+			if err = p.wait(p.recvStream(size)); err == nil {
+				err = p.wait(p.sendStream(size))
+			}
+		}
+		return err
+	case (test == testAm) && (mode == modeLatency):
+		for i := uint64(1); (i <= n) && (err == nil); i++ {
+			p.waitAm(base + i)
+			err = p.wait(p.sendAm(size))
+		}
+		return err
+	case test == testTag:
+		err = p.pipeline(n, func() (*UcpRequest, error) { return p.recvTag(size) })
+	case test == testStream:
+		err = p.pipeline(n, func() (*UcpRequest, error) { return p.recvStream(size) })
+	case test == testAm:
+		p.waitAm(base + n)
+	}
+
+	if err != nil {
+		return err
+	}
+	return p.ack()
+}
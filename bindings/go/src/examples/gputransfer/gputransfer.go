@@ -0,0 +1,276 @@
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+// GPU memory transfer by several workers: each worker of the server listens
+// on its own port, receives the tag messages of the client to the GPU memory
+// by cuda.GpuReceiver, directly or by the pinned host buffers, and echoes them
+// from the GPU memory. Each worker of the client checks the echoes of its
+// messages. The server takes the messages up to its -size, until the empty
+// one. Both exit with a non-zero code, if the transfer fails:
+//
+//	gputransfer -server -port 13341 -workers 4 -size 4194304
+//	gputransfer -ip <server ip> -port 13341 -workers 4 -size 4194304 -iters 100
+package main
+
+import (
+	"bytes"
+	"context"
+	. "cuda"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"runtime"
+	"sync"
+	"time"
+	. "ucx"
+)
+
+const transferTag uint64 = 0x67707574
+
+var (
+	server  = flag.Bool("server", false, "run the server")
+	ip      = flag.String("ip", "127.0.0.1", "address of the server")
+	port    = flag.Uint("port", 13341, "port of the first worker of the server, the others listen on the next ones")
+	workers = flag.Int("workers", 2, "number of the workers and of the connections")
+	iters   = flag.Int("iters", 100, "number of the messages, that each worker of the client sends")
+	size    = flag.Uint64("size", 4*1024*1024, "size of the messages, the largest one on the server")
+	staged  = flag.Bool("staged", false, "stage the receives of the server even with GPUDirect RDMA")
+	timeout = flag.Duration("timeout", 5*time.Minute, "time of the whole transfer")
+)
+
+func epErrorHandler(ep *UcpEp, status UcsStatus) {
+	if status != UCS_ERR_CONNECTION_RESET {
+		fmt.Fprintf(os.Stderr, "Endpoint error: %v\n", status)
+	}
+}
+
+// Accepts the first connection to the listener on the port, the others are
+// rejected once the listener is closed.
+func accept(ctx context.Context, worker *UcpWorker, port uint) (*UcpEp, error) {
+	var connRequest *UcpConnectionRequest
+	listenerParams := (&UcpListenerParams{}).SetConnectionHandler(func(request *UcpConnectionRequest) {
+		if connRequest == nil {
+			connRequest = request
+		}
+	})
+	addr, _ := net.ResolveTCPAddr("tcp", fmt.Sprintf("0.0.0.0:%v", port))
+	listenerParams.SetSocketAddress(addr)
+
+	listener, err := worker.NewListener(listenerParams)
+	if err != nil {
+		return nil, err
+	}
+	defer listener.Close()
+	fmt.Printf("Listening on %v\n", addr)
+
+	for connRequest == nil {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		worker.Progress()
+	}
+
+	return worker.NewEndpoint((&UcpEpParams{}).SetConnRequest(connRequest).
+		SetPeerErrorHandling().SetErrorHandler(epErrorHandler))
+}
+
+func closeEp(ctx context.Context, ep *UcpEp) {
+	if request, err := ep.CloseNonBlockingFlush(nil); err == nil {
+		request.WaitContext(ctx)
+		request.Close()
+	}
+}
+
+func wait(ctx context.Context, request *UcpRequest, err error) error {
+	if err != nil {
+		return err
+	}
+	defer request.Close()
+	return request.WaitContext(ctx)
+}
+
+// Receives the messages to the GPU memory and echoes them, until the empty
+// one. The goroutine is locked to its thread, that the CUDA device is set for.
+func serveWorker(ctx context.Context, ucpContext *UcpContext, worker *UcpWorker, index int) error {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	if err := CudaSetDevice(); err != nil {
+		return err
+	}
+
+	mmapParams := (&UcpMmapParams{}).SetMemoryType(UCS_MEMORY_TYPE_CUDA).Allocate().SetLength(*size)
+	memory, err := ucpContext.MemMap(mmapParams)
+	if err != nil {
+		return err
+	}
+	defer memory.Close()
+
+	memAttrs, err := memory.Query(UCP_MEM_ATTR_FIELD_ADDRESS)
+	if err != nil {
+		return err
+	}
+
+	receiver := NewGpuReceiver(ucpContext, worker, *size)
+	defer receiver.Close()
+	if *staged {
+		receiver.ForceStaging()
+	}
+
+	ep, err := accept(ctx, worker, *port+uint(index))
+	if err != nil {
+		return err
+	}
+	defer closeEp(ctx, ep)
+
+	params := (&UcpRequestParams{}).SetMemType(UCS_MEMORY_TYPE_CUDA).SetMemory(memory)
+	for echoed := 0; ; echoed++ {
+		info, _, err := receiver.RecvTag(ctx, memAttrs.Address, *size, transferTag, ^uint64(0), params)
+		if err != nil {
+			return fmt.Errorf("receive of message %v: %w", echoed, err)
+		}
+
+		if info.Length == 0 {
+			fmt.Printf("Worker %v echoed %v messages, %v receives\n", index, echoed, receiver.Path())
+			return nil
+		}
+
+		request, err := ep.SendTagNonBlocking(transferTag, memAttrs.Address, info.Length, params)
+		if err := wait(ctx, request, err); err != nil {
+			return fmt.Errorf("echo of message %v: %w", echoed, err)
+		}
+	}
+}
+
+// Sends the messages of the host memory, and checks their echoes.
+func runWorker(ctx context.Context, worker *UcpWorker, index int) error {
+	epParams := (&UcpEpParams{}).SetAddress(fmt.Sprintf("%v:%v", *ip, *port+uint(index))).
+		SetPeerErrorHandling().SetErrorHandler(epErrorHandler)
+	ep, err := worker.Connect(ctx, epParams)
+	if err != nil {
+		return err
+	}
+	defer closeEp(ctx, ep)
+
+	sendBuffer := AllocateNativeMemory(*size)
+	defer FreeNativeMemory(sendBuffer)
+	recvBuffer := AllocateNativeMemory(*size)
+	defer FreeNativeMemory(recvBuffer)
+
+	for i := 0; i < *iters; i++ {
+		message := make([]byte, *size)
+		for j := range message {
+			message[j] = byte(index + i + j)
+		}
+		copy((*[1 << 40]byte)(sendBuffer)[:*size:*size], message)
+
+		// The receive is posted first, so the echo isn't unexpected
+		recvRequest, err := worker.RecvTagNonBlocking(recvBuffer, *size, transferTag, ^uint64(0), nil)
+		if err != nil {
+			return err
+		}
+
+		request, err := ep.SendTagNonBlocking(transferTag, sendBuffer, *size, nil)
+		if err := wait(ctx, request, err); err != nil {
+			recvRequest.Close()
+			return fmt.Errorf("send of message %v: %w", i, err)
+		}
+
+		if err := wait(ctx, recvRequest, nil); err != nil {
+			return fmt.Errorf("receive of echo %v: %w", i, err)
+		}
+
+		if !bytes.Equal(GoBytes(recvBuffer, *size), message) {
+			return fmt.Errorf("echo %v doesn't match the message", i)
+		}
+	}
+
+	request, err := ep.SendTagNonBlocking(transferTag, nil, 0, nil)
+	if err := wait(ctx, request, err); err != nil {
+		return fmt.Errorf("send of the last message: %w", err)
+	}
+	return nil
+}
+
+func run() error {
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	if *workers <= 0 {
+		return fmt.Errorf("number of the workers must be positive")
+	}
+
+	context, err := NewUcpContext((&UcpParams{}).EnableTag())
+	if err != nil {
+		return err
+	}
+	defer context.Close()
+
+	if *server {
+		memTypes, err := context.MemoryTypesMask()
+		if err != nil {
+			return err
+		}
+
+		if !IsMemTypeSupported(UCS_MEMORY_TYPE_CUDA, memTypes) {
+			return fmt.Errorf("UCX doesn't support CUDA memory")
+		}
+		fmt.Printf("GPUDirect RDMA available: %v\n", GdrAvailable(context))
+	}
+
+	// Each worker is progressed by its own goroutine only
+	var ucpWorkers []*UcpWorker
+	defer func() {
+		for _, worker := range ucpWorkers {
+			worker.Close()
+		}
+	}()
+	for i := 0; i < *workers; i++ {
+		worker, err := context.NewWorker(&UcpWorkerParams{})
+		if err != nil {
+			return err
+		}
+		ucpWorkers = append(ucpWorkers, worker)
+	}
+
+	errs := make([]error, *workers)
+	var wg sync.WaitGroup
+	start := time.Now()
+	for i, worker := range ucpWorkers {
+		wg.Add(1)
+		go func(i int, worker *UcpWorker) {
+			defer wg.Done()
+			if *server {
+				errs[i] = serveWorker(ctx, context, worker, i)
+			} else {
+				errs[i] = runWorker(ctx, worker, i)
+			}
+		}(i, worker)
+	}
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	for i, err := range errs {
+		if err != nil {
+			return fmt.Errorf("worker %v: %w", i, err)
+		}
+	}
+
+	if !*server && (*iters > 0) {
+		total := 2 * uint64(*workers) * uint64(*iters) * *size
+		fmt.Printf("%v workers transferred %v bytes in %v, %.3f GB/s\n", *workers, total, elapsed,
+			float64(total)/elapsed.Seconds()/1e9)
+	}
+	return nil
+}
+
+func main() {
+	flag.Parse()
+	if err := run(); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,197 @@
+//go:build go1.18
+// +build go1.18
+
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+// Key-value store over ucxkv: the server keeps the values in its registered
+// memory, and the client writes, reads and deletes the keys by RMA.
+//
+//	kvstore -server -port 13337
+//	kvstore -ip <server ip> -port 13337 -keys 1000 -size 1024
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"time"
+	. "ucx"
+	"ucx/ucxkv"
+	"ucx/ucxrpc"
+)
+
+var (
+	server = flag.Bool("server", false, "run the server")
+	ip     = flag.String("ip", "127.0.0.1", "address of the server")
+	port   = flag.Uint("port", 13337, "port of the server")
+	keys   = flag.Int("keys", 1000, "number of the keys, that the client puts")
+	size   = flag.Int("size", 1024, "size of the values")
+	slots  = flag.Int("slots", 4096, "number of the value slots of the server")
+)
+
+func epErrorHandler(ep *UcpEp, status UcsStatus) {
+	if status != UCS_ERR_CONNECTION_RESET {
+		fmt.Fprintf(os.Stderr, "Endpoint error: %v\n", status)
+	}
+}
+
+func runServer(context *UcpContext, worker *UcpWorker, loop *UcpProgressLoop, node *ucxrpc.Node,
+	config ucxkv.Config) error {
+	kv, err := ucxkv.NewServer(context, node, config)
+	if err != nil {
+		return err
+	}
+	defer kv.Close()
+
+	var eps []*UcpEp
+	listenerParams := (&UcpListenerParams{}).SetConnectionHandler(func(connRequest *UcpConnectionRequest) {
+		// The handler is invoked by the worker progress on the loop
+		ep, err := worker.NewEndpoint((&UcpEpParams{}).SetConnRequest(connRequest).
+			SetPeerErrorHandling().SetErrorHandler(epErrorHandler))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to accept connection: %v\n", err)
+			return
+		}
+		eps = append(eps, ep)
+	})
+	addr, _ := net.ResolveTCPAddr("tcp", fmt.Sprintf("0.0.0.0:%v", *port))
+	listenerParams.SetSocketAddress(addr)
+
+	var listener *UcpListener
+	loop.Execute(func() {
+		listener, err = worker.NewListener(listenerParams)
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Serving %v slots of %v bytes on %v\n", config.Slots, config.SlotSize, addr)
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	<-interrupt
+
+	fmt.Printf("Stopping with %v keys\n", kv.Len())
+	// The methods must not access the memory of the store once it's released
+	node.Close()
+	loop.Execute(func() {
+		listener.Close()
+		for _, ep := range eps {
+			if request, err := ep.CloseNonBlockingForce(nil); err == nil {
+				request.Close()
+			}
+		}
+	})
+	return nil
+}
+
+func runClient(worker *UcpWorker, loop *UcpProgressLoop, node *ucxrpc.Node, config ucxkv.Config) error {
+	ctx := context.Background()
+	addr, err := net.ResolveTCPAddr("tcp", fmt.Sprintf("%v:%v", *ip, *port))
+	if err != nil {
+		return err
+	}
+
+	epParams, err := (&UcpEpParams{}).SetSocketAddress(addr)
+	if err != nil {
+		return err
+	}
+	epParams.SetPeerErrorHandling().SetErrorHandler(epErrorHandler)
+
+	var ep *UcpEp
+	loop.Execute(func() {
+		ep, err = worker.NewEndpoint(epParams)
+	})
+	if err != nil {
+		return err
+	}
+	defer loop.Execute(func() {
+		if request, err := ep.CloseNonBlockingFlush(nil); err == nil {
+			request.Close()
+		}
+	})
+
+	kv, err := ucxkv.NewClient(ctx, loop, node, ep, config)
+	if err != nil {
+		return err
+	}
+	defer kv.Close()
+
+	value := func(i int) []byte {
+		return bytes.Repeat([]byte{byte(i)}, *size)
+	}
+
+	start := time.Now()
+	for i := 0; i < *keys; i++ {
+		if err := kv.Put(ctx, fmt.Sprint("key", i), value(i)); err != nil {
+			return fmt.Errorf("put of key %v: %w", i, err)
+		}
+	}
+	fmt.Printf("Put %v keys in %v\n", *keys, time.Since(start))
+
+	start = time.Now()
+	for i := 0; i < *keys; i++ {
+		data, err := kv.Get(ctx, fmt.Sprint("key", i))
+		if err != nil {
+			return fmt.Errorf("get of key %v: %w", i, err)
+		}
+
+		if !bytes.Equal(data, value(i)) {
+			return fmt.Errorf("value of key %v doesn't match", i)
+		}
+	}
+	fmt.Printf("Got %v keys in %v\n", *keys, time.Since(start))
+
+	for i := 0; i < *keys; i++ {
+		if err := kv.Delete(ctx, fmt.Sprint("key", i)); err != nil {
+			return fmt.Errorf("delete of key %v: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func run() error {
+	context, err := NewUcpContext((&UcpParams{}).EnableAM().EnableRMA())
+	if err != nil {
+		return err
+	}
+	defer context.Close()
+
+	worker, err := context.NewWorker(&UcpWorkerParams{})
+	if err != nil {
+		return err
+	}
+	defer worker.Close()
+
+	loop, err := worker.StartProgressLoop(nil)
+	if err != nil {
+		return err
+	}
+	defer loop.Stop()
+
+	node, err := ucxrpc.NewNode(loop, worker, ucxrpc.Config{})
+	if err != nil {
+		return err
+	}
+	defer node.Close()
+
+	config := ucxkv.Config{SlotSize: uint64(*size) + 8, Slots: *slots}
+	if *server {
+		return runServer(context, worker, loop, node, config)
+	}
+	return runClient(worker, loop, node, config)
+}
+
+func main() {
+	flag.Parse()
+	if err := run(); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,310 @@
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+// RMA put and get: the server exposes its memory by the bootstrap info, that
+// carries the packed remote key and is sent over the stream of the listener
+// connection. The client reads the memory, checks it, overwrites it and tells
+// the server to check the result. Both exit with a non-zero code, if the
+// exchange fails:
+//
+//	rmaputget -server -port 13339 -size 1048576
+//	rmaputget -ip <server ip> -port 13339
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"time"
+	. "ucx"
+	"unsafe"
+)
+
+const (
+	memoryName = "data"
+	// Limit of the bootstrap info, that carries the worker address and the
+	// remote key
+	maxInfoSize = 64 * 1024
+)
+
+// Results of the check of the server, that are sent back to the client.
+const (
+	checkPassed byte = iota
+	checkFailed
+)
+
+var (
+	server  = flag.Bool("server", false, "run the server")
+	ip      = flag.String("ip", "127.0.0.1", "address of the server")
+	port    = flag.Uint("port", 13339, "port of the server")
+	size    = flag.Uint64("size", 1024*1024, "size of the memory of the server")
+	timeout = flag.Duration("timeout", time.Minute, "time of the whole exchange")
+)
+
+func epErrorHandler(ep *UcpEp, status UcsStatus) {
+	if status != UCS_ERR_CONNECTION_RESET {
+		fmt.Fprintf(os.Stderr, "Endpoint error: %v\n", status)
+	}
+}
+
+// Accepts the first connection to the listener, the others are rejected once
+// the listener is closed.
+func accept(ctx context.Context, worker *UcpWorker) (*UcpEp, error) {
+	var connRequest *UcpConnectionRequest
+	listenerParams := (&UcpListenerParams{}).SetConnectionHandler(func(request *UcpConnectionRequest) {
+		if connRequest == nil {
+			connRequest = request
+		}
+	})
+	addr, _ := net.ResolveTCPAddr("tcp", fmt.Sprintf("0.0.0.0:%v", *port))
+	listenerParams.SetSocketAddress(addr)
+
+	listener, err := worker.NewListener(listenerParams)
+	if err != nil {
+		return nil, err
+	}
+	defer listener.Close()
+	fmt.Printf("Listening on %v\n", addr)
+
+	for connRequest == nil {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		worker.Progress()
+	}
+
+	return worker.NewEndpoint((&UcpEpParams{}).SetConnRequest(connRequest).
+		SetPeerErrorHandling().SetErrorHandler(epErrorHandler))
+}
+
+func closeEp(ctx context.Context, ep *UcpEp) {
+	if request, err := ep.CloseNonBlockingFlush(nil); err == nil {
+		request.WaitContext(ctx)
+		request.Close()
+	}
+}
+
+func wait(ctx context.Context, request *UcpRequest, err error) error {
+	if err != nil {
+		return err
+	}
+	defer request.Close()
+	return request.WaitContext(ctx)
+}
+
+// The server fills its memory by the first pattern, and the client puts the
+// second one.
+func pattern(i int, second bool) byte {
+	if second {
+		return ^byte(i)
+	}
+	return byte(i)
+}
+
+func checkPattern(data []byte, second bool) error {
+	for i := range data {
+		if data[i] != pattern(i, second) {
+			return fmt.Errorf("byte %v of the memory is %v instead of %v", i, data[i], pattern(i, second))
+		}
+	}
+	return nil
+}
+
+func sendByte(ctx context.Context, ep *UcpEp, value byte) error {
+	message := CBytes([]byte{value})
+	defer FreeNativeMemory(message)
+	return ep.SendFramed(ctx, message, 1)
+}
+
+func recvByte(ctx context.Context, ep *UcpEp) (byte, error) {
+	message := AllocateNativeMemory(1)
+	defer FreeNativeMemory(message)
+	if _, err := ep.RecvFramed(ctx, message, 1); err != nil {
+		return 0, err
+	}
+	return GoBytes(message, 1)[0], nil
+}
+
+func runServer(ctx context.Context, ucpContext *UcpContext, worker *UcpWorker) error {
+	memory, data, err := ucpContext.AllocAndMap(*size, nil)
+	if err != nil {
+		return err
+	}
+	defer memory.Close()
+
+	for i := range data {
+		data[i] = pattern(i, false)
+	}
+
+	info, err := worker.BootstrapInfo()
+	if err != nil {
+		return err
+	}
+
+	if err := info.AddMemory(memoryName, memory); err != nil {
+		return err
+	}
+
+	blob, err := info.MarshalBinary()
+	if err != nil {
+		return err
+	}
+
+	ep, err := accept(ctx, worker)
+	if err != nil {
+		return err
+	}
+	defer closeEp(ctx, ep)
+
+	cBlob := CBytes(blob)
+	defer FreeNativeMemory(cBlob)
+	if err := ep.SendFramed(ctx, cBlob, uint64(len(blob))); err != nil {
+		return fmt.Errorf("send of the bootstrap info: %w", err)
+	}
+
+	// The client puts and flushes its pattern before the message
+	if _, err := recvByte(ctx, ep); err != nil {
+		return fmt.Errorf("receive of the put completion: %w", err)
+	}
+
+	checkErr := checkPattern(data, true)
+	result := checkPassed
+	if checkErr != nil {
+		result = checkFailed
+	}
+
+	if err := sendByte(ctx, ep, result); err != nil {
+		return fmt.Errorf("send of the check result: %w", err)
+	}
+
+	if checkErr != nil {
+		return checkErr
+	}
+	fmt.Printf("Memory of %v bytes is read and written by the client\n", len(data))
+	return nil
+}
+
+func bandwidth(size uint64, elapsed time.Duration) string {
+	return fmt.Sprintf("%v bytes in %v, %.3f GB/s", size, elapsed, float64(size)/elapsed.Seconds()/1e9)
+}
+
+func runClient(ctx context.Context, ucpContext *UcpContext, worker *UcpWorker) error {
+	epParams := (&UcpEpParams{}).SetAddress(fmt.Sprintf("%v:%v", *ip, *port)).
+		SetPeerErrorHandling().SetErrorHandler(epErrorHandler)
+	ep, err := worker.Connect(ctx, epParams)
+	if err != nil {
+		return err
+	}
+	defer closeEp(ctx, ep)
+
+	cBlob := AllocateNativeMemory(maxInfoSize)
+	defer FreeNativeMemory(cBlob)
+	length, err := ep.RecvFramed(ctx, cBlob, maxInfoSize)
+	if err != nil {
+		return fmt.Errorf("receive of the bootstrap info: %w", err)
+	}
+
+	var info UcpBootstrapInfo
+	if err := info.UnmarshalBinary(GoBytes(cBlob, length)); err != nil {
+		return err
+	}
+
+	remote, found := info.Memories[memoryName]
+	if !found {
+		return fmt.Errorf("server doesn't expose memory %q", memoryName)
+	}
+
+	rkey, err := ep.UnpackRkey(remote.Rkey)
+	if err != nil {
+		return err
+	}
+	defer rkey.Close()
+
+	memory, data, err := ucpContext.AllocAndMap(remote.Length, nil)
+	if err != nil {
+		return err
+	}
+	defer memory.Close()
+	address := unsafe.Pointer(&data[0])
+	params := (&UcpRequestParams{}).SetMemory(memory)
+
+	start := time.Now()
+	request, err := ep.RmaGetNonBlocking(address, remote.Length, remote.Address, rkey, params)
+	if err := wait(ctx, request, err); err != nil {
+		return fmt.Errorf("get: %w", err)
+	}
+	fmt.Printf("Get of %v\n", bandwidth(remote.Length, time.Since(start)))
+
+	if err := checkPattern(data, false); err != nil {
+		return err
+	}
+
+	for i := range data {
+		data[i] = pattern(i, true)
+	}
+
+	start = time.Now()
+	request, err = ep.RmaPutNonBlocking(address, remote.Length, remote.Address, rkey, params)
+	if err := wait(ctx, request, err); err != nil {
+		return fmt.Errorf("put: %w", err)
+	}
+
+	// The put is completed remotely once the flush completes
+	request, err = ep.FlushNonBlocking(nil)
+	if err := wait(ctx, request, err); err != nil {
+		return fmt.Errorf("flush: %w", err)
+	}
+	fmt.Printf("Put of %v\n", bandwidth(remote.Length, time.Since(start)))
+
+	if err := sendByte(ctx, ep, 0); err != nil {
+		return fmt.Errorf("send of the put completion: %w", err)
+	}
+
+	result, err := recvByte(ctx, ep)
+	if err != nil {
+		return fmt.Errorf("receive of the check result: %w", err)
+	}
+
+	if result != checkPassed {
+		return fmt.Errorf("server found the wrong data after the put")
+	}
+	return nil
+}
+
+func run() error {
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	if *size == 0 {
+		return fmt.Errorf("size of the memory must be positive")
+	}
+
+	context, err := NewUcpContext((&UcpParams{}).EnableRMA().EnableStream())
+	if err != nil {
+		return err
+	}
+	defer context.Close()
+
+	worker, err := context.NewWorker(&UcpWorkerParams{})
+	if err != nil {
+		return err
+	}
+	defer worker.Close()
+
+	if *server {
+		return runServer(ctx, context, worker)
+	}
+	return runClient(ctx, context, worker)
+}
+
+func main() {
+	flag.Parse()
+	if err := run(); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,239 @@
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+// File transfer over the stream: the client sends the name, the size and the
+// content of the file by the stream writer of the endpoint, the server saves
+// it to the directory and replies with the SHA-256 of the received content,
+// that the client checks. Both exit with a non-zero code, if the transfer
+// fails:
+//
+//	streamfile -server -port 13340 -dir /tmp
+//	streamfile -ip <server ip> -port 13340 -file <path>
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+	. "ucx"
+)
+
+const maxNameSize = 4096
+
+var (
+	server  = flag.Bool("server", false, "run the server")
+	ip      = flag.String("ip", "127.0.0.1", "address of the server")
+	port    = flag.Uint("port", 13340, "port of the server")
+	file    = flag.String("file", "", "file, that the client sends")
+	dir     = flag.String("dir", ".", "directory, that the server saves the file to")
+	timeout = flag.Duration("timeout", 10*time.Minute, "time of the whole transfer")
+)
+
+func epErrorHandler(ep *UcpEp, status UcsStatus) {
+	if status != UCS_ERR_CONNECTION_RESET {
+		fmt.Fprintf(os.Stderr, "Endpoint error: %v\n", status)
+	}
+}
+
+// Accepts the first connection to the listener, the others are rejected once
+// the listener is closed.
+func accept(ctx context.Context, worker *UcpWorker) (*UcpEp, error) {
+	var connRequest *UcpConnectionRequest
+	listenerParams := (&UcpListenerParams{}).SetConnectionHandler(func(request *UcpConnectionRequest) {
+		if connRequest == nil {
+			connRequest = request
+		}
+	})
+	addr, _ := net.ResolveTCPAddr("tcp", fmt.Sprintf("0.0.0.0:%v", *port))
+	listenerParams.SetSocketAddress(addr)
+
+	listener, err := worker.NewListener(listenerParams)
+	if err != nil {
+		return nil, err
+	}
+	defer listener.Close()
+	fmt.Printf("Listening on %v\n", addr)
+
+	for connRequest == nil {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		worker.Progress()
+	}
+
+	return worker.NewEndpoint((&UcpEpParams{}).SetConnRequest(connRequest).
+		SetPeerErrorHandling().SetErrorHandler(epErrorHandler))
+}
+
+func closeEp(ctx context.Context, ep *UcpEp) {
+	if request, err := ep.CloseNonBlockingFlush(nil); err == nil {
+		request.WaitContext(ctx)
+		request.Close()
+	}
+}
+
+func runServer(ctx context.Context, worker *UcpWorker) error {
+	ep, err := accept(ctx, worker)
+	if err != nil {
+		return err
+	}
+	defer closeEp(ctx, ep)
+
+	reader := ep.Reader()
+	defer reader.Close()
+	deadline, _ := ctx.Deadline()
+	reader.SetReadDeadline(deadline)
+
+	var nameSize uint32
+	if err := binary.Read(reader, binary.LittleEndian, &nameSize); err != nil {
+		return fmt.Errorf("receive of the name: %w", err)
+	}
+
+	if nameSize > maxNameSize {
+		return fmt.Errorf("name of %v bytes is too long", nameSize)
+	}
+
+	name := make([]byte, nameSize)
+	if _, err := io.ReadFull(reader, name); err != nil {
+		return fmt.Errorf("receive of the name: %w", err)
+	}
+
+	var size uint64
+	if err := binary.Read(reader, binary.LittleEndian, &size); err != nil {
+		return fmt.Errorf("receive of the size: %w", err)
+	}
+
+	// The name of the client isn't trusted to point outside the directory
+	path := filepath.Join(*dir, filepath.Base(string(name)))
+	output, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer output.Close()
+
+	digest := sha256.New()
+	start := time.Now()
+	if _, err := io.CopyN(io.MultiWriter(output, digest), reader, int64(size)); err != nil {
+		return fmt.Errorf("receive of the content: %w", err)
+	}
+	elapsed := time.Since(start)
+
+	if err := output.Sync(); err != nil {
+		return err
+	}
+
+	sum := CBytes(digest.Sum(nil))
+	defer FreeNativeMemory(sum)
+	if err := ep.SendFramed(ctx, sum, sha256.Size); err != nil {
+		return fmt.Errorf("send of the digest: %w", err)
+	}
+
+	fmt.Printf("Received %v of %v bytes in %v, %.3f GB/s\n", path, size, elapsed,
+		float64(size)/elapsed.Seconds()/1e9)
+	return nil
+}
+
+func runClient(ctx context.Context, worker *UcpWorker) error {
+	input, err := os.Open(*file)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+
+	stat, err := input.Stat()
+	if err != nil {
+		return err
+	}
+
+	epParams := (&UcpEpParams{}).SetAddress(fmt.Sprintf("%v:%v", *ip, *port)).
+		SetPeerErrorHandling().SetErrorHandler(epErrorHandler)
+	ep, err := worker.Connect(ctx, epParams)
+	if err != nil {
+		return err
+	}
+	defer closeEp(ctx, ep)
+
+	writer := ep.Writer()
+	defer writer.Close()
+	deadline, _ := ctx.Deadline()
+	writer.SetWriteDeadline(deadline)
+
+	name := filepath.Base(*file)
+	size := uint64(stat.Size())
+	header := make([]byte, 4+len(name)+8)
+	binary.LittleEndian.PutUint32(header, uint32(len(name)))
+	copy(header[4:], name)
+	binary.LittleEndian.PutUint64(header[4+len(name):], size)
+	if _, err := writer.Write(header); err != nil {
+		return fmt.Errorf("send of the header: %w", err)
+	}
+
+	digest := sha256.New()
+	start := time.Now()
+	if _, err := io.CopyN(writer, io.TeeReader(input, digest), int64(size)); err != nil {
+		return fmt.Errorf("send of the content: %w", err)
+	}
+
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("send of the content: %w", err)
+	}
+
+	sum := AllocateNativeMemory(sha256.Size)
+	defer FreeNativeMemory(sum)
+	if _, err := ep.RecvFramed(ctx, sum, sha256.Size); err != nil {
+		return fmt.Errorf("receive of the digest: %w", err)
+	}
+	elapsed := time.Since(start)
+
+	if !bytes.Equal(GoBytes(sum, sha256.Size), digest.Sum(nil)) {
+		return fmt.Errorf("digest of the server doesn't match the file")
+	}
+
+	fmt.Printf("Sent %v of %v bytes in %v, %.3f GB/s\n", name, size, elapsed,
+		float64(size)/elapsed.Seconds()/1e9)
+	return nil
+}
+
+func run() error {
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	if !*server && (*file == "") {
+		return fmt.Errorf("file to send is not set")
+	}
+
+	context, err := NewUcpContext((&UcpParams{}).EnableStream())
+	if err != nil {
+		return err
+	}
+	defer context.Close()
+
+	worker, err := context.NewWorker(&UcpWorkerParams{})
+	if err != nil {
+		return err
+	}
+	defer worker.Close()
+
+	if *server {
+		return runServer(ctx, worker)
+	}
+	return runClient(ctx, worker)
+}
+
+func main() {
+	flag.Parse()
+	if err := run(); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,217 @@
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+// Tag ping-pong: the server echoes the tag messages of the client, until the
+// empty one, and the client checks the echoes and prints the round-trip time.
+// Both exit with a non-zero code, if the exchange fails:
+//
+//	tagpingpong -server -port 13337
+//	tagpingpong -ip <server ip> -port 13337 -iters 1000 -size 4096
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"time"
+	. "ucx"
+	"unsafe"
+)
+
+const pingTag uint64 = 0x70696e67
+
+var (
+	server  = flag.Bool("server", false, "run the server")
+	ip      = flag.String("ip", "127.0.0.1", "address of the server")
+	port    = flag.Uint("port", 13337, "port of the server")
+	iters   = flag.Int("iters", 1000, "number of the messages, that the client sends")
+	size    = flag.Uint64("size", 4096, "size of the messages")
+	timeout = flag.Duration("timeout", time.Minute, "time of the whole exchange")
+)
+
+func epErrorHandler(ep *UcpEp, status UcsStatus) {
+	if status != UCS_ERR_CONNECTION_RESET {
+		fmt.Fprintf(os.Stderr, "Endpoint error: %v\n", status)
+	}
+}
+
+// Accepts the first connection to the listener, the others are rejected once
+// the listener is closed.
+func accept(ctx context.Context, worker *UcpWorker) (*UcpEp, error) {
+	var connRequest *UcpConnectionRequest
+	listenerParams := (&UcpListenerParams{}).SetConnectionHandler(func(request *UcpConnectionRequest) {
+		if connRequest == nil {
+			connRequest = request
+		}
+	})
+	addr, _ := net.ResolveTCPAddr("tcp", fmt.Sprintf("0.0.0.0:%v", *port))
+	listenerParams.SetSocketAddress(addr)
+
+	listener, err := worker.NewListener(listenerParams)
+	if err != nil {
+		return nil, err
+	}
+	defer listener.Close()
+	fmt.Printf("Listening on %v\n", addr)
+
+	for connRequest == nil {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		worker.Progress()
+	}
+
+	return worker.NewEndpoint((&UcpEpParams{}).SetConnRequest(connRequest).
+		SetPeerErrorHandling().SetErrorHandler(epErrorHandler))
+}
+
+func closeEp(ctx context.Context, ep *UcpEp) {
+	if request, err := ep.CloseNonBlockingFlush(nil); err == nil {
+		request.WaitContext(ctx)
+		request.Close()
+	}
+}
+
+func wait(ctx context.Context, request *UcpRequest, err error) error {
+	if err != nil {
+		return err
+	}
+	defer request.Close()
+	return request.WaitContext(ctx)
+}
+
+func runServer(ctx context.Context, worker *UcpWorker) error {
+	ep, err := accept(ctx, worker)
+	if err != nil {
+		return err
+	}
+	defer closeEp(ctx, ep)
+
+	var buffer unsafe.Pointer
+	var bufferSize uint64
+	defer func() { FreeNativeMemory(buffer) }()
+
+	for echoed := 0; ; echoed++ {
+		// The messages are probed, so the server takes any size of the client
+		message := worker.TagProbe(pingTag, ^uint64(0), true)
+		for ; message == nil; message = worker.TagProbe(pingTag, ^uint64(0), true) {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			worker.Progress()
+		}
+
+		length := message.Info.Length
+		if length > bufferSize {
+			FreeNativeMemory(buffer)
+			buffer = AllocateNativeMemory(length)
+			bufferSize = length
+		}
+
+		request, err := worker.RecvTagMsgNonBlocking(buffer, length, message, nil)
+		if err := wait(ctx, request, err); err != nil {
+			return fmt.Errorf("receive of message %v: %w", echoed, err)
+		}
+
+		if length == 0 {
+			fmt.Printf("Echoed %v messages\n", echoed)
+			return nil
+		}
+
+		request, err = ep.SendTagNonBlocking(pingTag, buffer, length, nil)
+		if err := wait(ctx, request, err); err != nil {
+			return fmt.Errorf("echo of message %v: %w", echoed, err)
+		}
+	}
+}
+
+func runClient(ctx context.Context, worker *UcpWorker) error {
+	epParams := (&UcpEpParams{}).SetAddress(fmt.Sprintf("%v:%v", *ip, *port)).
+		SetPeerErrorHandling().SetErrorHandler(epErrorHandler)
+	ep, err := worker.Connect(ctx, epParams)
+	if err != nil {
+		return err
+	}
+	defer closeEp(ctx, ep)
+
+	sendBuffer := AllocateNativeMemory(*size)
+	defer FreeNativeMemory(sendBuffer)
+	recvBuffer := AllocateNativeMemory(*size)
+	defer FreeNativeMemory(recvBuffer)
+
+	start := time.Now()
+	for i := 0; i < *iters; i++ {
+		message := make([]byte, *size)
+		for j := range message {
+			message[j] = byte(i + j)
+		}
+		copy((*[1 << 40]byte)(sendBuffer)[:*size:*size], message)
+
+		// The receive is posted first, so the echo isn't unexpected
+		recvRequest, err := worker.RecvTagNonBlocking(recvBuffer, *size, pingTag, ^uint64(0), nil)
+		if err != nil {
+			return err
+		}
+
+		request, err := ep.SendTagNonBlocking(pingTag, sendBuffer, *size, nil)
+		if err := wait(ctx, request, err); err != nil {
+			recvRequest.Close()
+			return fmt.Errorf("send of message %v: %w", i, err)
+		}
+
+		if err := wait(ctx, recvRequest, nil); err != nil {
+			return fmt.Errorf("receive of echo %v: %w", i, err)
+		}
+
+		if !bytes.Equal(GoBytes(recvBuffer, *size), message) {
+			return fmt.Errorf("echo %v doesn't match the message", i)
+		}
+	}
+	elapsed := time.Since(start)
+
+	request, err := ep.SendTagNonBlocking(pingTag, nil, 0, nil)
+	if err := wait(ctx, request, err); err != nil {
+		return fmt.Errorf("send of the last message: %w", err)
+	}
+
+	if *iters > 0 {
+		fmt.Printf("%v messages of %v bytes, average round trip: %v\n", *iters, *size,
+			elapsed/time.Duration(*iters))
+	}
+	return nil
+}
+
+func run() error {
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	context, err := NewUcpContext((&UcpParams{}).EnableTag())
+	if err != nil {
+		return err
+	}
+	defer context.Close()
+
+	worker, err := context.NewWorker(&UcpWorkerParams{})
+	if err != nil {
+		return err
+	}
+	defer worker.Close()
+
+	if *server {
+		return runServer(ctx, worker)
+	}
+	return runClient(ctx, worker)
+}
+
+func main() {
+	flag.Parse()
+	if err := run(); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+}
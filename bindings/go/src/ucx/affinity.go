@@ -0,0 +1,62 @@
+//go:build linux
+// +build linux
+
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package ucx
+
+// #define _GNU_SOURCE
+// #include <sched.h>
+// #include <errno.h>
+//
+// static int ucxgo_set_thread_affinity(const int *cpus, int count) {
+//     cpu_set_t cpu_set;
+//     int i;
+//
+//     CPU_ZERO(&cpu_set);
+//     for (i = 0; i < count; ++i) {
+//         CPU_SET(cpus[i], &cpu_set);
+//     }
+//
+//     return (sched_setaffinity(0, sizeof(cpu_set), &cpu_set) == 0) ? 0 : errno;
+// }
+import "C"
+import (
+	"runtime"
+	"syscall"
+)
+
+// Returns the CPUs, which the worker was created with by
+// UcpWorkerParams.SetCpus() or UcpWorkerParams.SetCpuMask().
+func (w *UcpWorker) Cpus() []int {
+	return append([]int(nil), w.cpus...)
+}
+
+// This routine locks the calling goroutine to its OS thread, and restricts the
+// thread to the CPUs, e.g. the ones of the worker, which the goroutine
+// progresses. The thread is never unlocked, so it's terminated once the
+// goroutine exits rather than reused by the other goroutines with the changed
+// affinity.
+func LockOSThreadToCpus(cpus []int) error {
+	if len(cpus) == 0 {
+		return ErrInvalidParam
+	}
+
+	cCpus := make([]C.int, len(cpus))
+	for i, cpu := range cpus {
+		if (cpu < 0) || (cpu >= C.CPU_SETSIZE) {
+			return ErrInvalidParam
+		}
+		cCpus[i] = C.int(cpu)
+	}
+
+	runtime.LockOSThread()
+	if errno := C.ucxgo_set_thread_affinity(&cCpus[0], C.int(len(cCpus))); errno != 0 {
+		runtime.UnlockOSThread()
+		return syscall.Errno(errno)
+	}
+	return nil
+}
@@ -1,3 +1,6 @@
+//go:build linux
+// +build linux
+
 /*
  * Copyright (C) 2021, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
  * See file LICENSE for terms.
@@ -6,22 +9,130 @@
 package ucx
 
 // #include <ucp/api/ucp.h>
+// #include <string.h>
 import "C"
 import (
 	"errors"
+	"sync"
 	"unsafe"
 )
 
+// Ownership of the data, that is passed to UcpAmRecvCallback, see
+// UcpWorker.SetAmRecvHandlerWithMode().
+type UcpAmDataMode int
+
+const (
+	// The data is valid only in the callback, unless the callback returns
+	// UCS_INPROGRESS for the data, that can be persisted (see
+	// UcpAmData.CanPersist()).
+	UcpAmDataModeDescriptor UcpAmDataMode = iota
+	// The data is held by the binding without a copy after the callback
+	// returns, until UcpAmData.Release() is called. The handler is installed
+	// with UCP_AM_FLAG_PERSISTENT_DATA, and the status returned by the
+	// callback is ignored for the received data.
+	UcpAmDataModePersist
+	// The data is copied to the Go slice returned by UcpAmData.Bytes() before
+	// the callback, so it can be used by the application after the callback
+	// returns, while the library reuses its buffer.
+	UcpAmDataModeCopy
+)
+
+// Header of the Active Message, that is passed to UcpAmRecvCallback. It's a
+// view of the library memory without a copy, so it's valid only until the
+// callback returns; UcpAmHeader.Clone() copies it to keep it longer.
+type UcpAmHeader []byte
+
+// Returns the view of the header of headerSize bytes, e.g.
+// AmHeader(header, headerSize) in UcpAmRecvCallback. The view is nil for the
+// empty header.
+func AmHeader(header unsafe.Pointer, headerSize uint64) UcpAmHeader {
+	if (header == nil) || (headerSize == 0) {
+		return nil
+	}
+	return UcpAmHeader((*[1 << 40]byte)(header)[:headerSize:headerSize])
+}
+
+// Copy of the header to the Go memory, that can be used after the callback.
+func (h UcpAmHeader) Clone() []byte {
+	if h == nil {
+		return nil
+	}
+	return append([]byte(nil), h...)
+}
+
 // Active Message data descriptor
 type UcpAmData struct {
 	worker  *UcpWorker
 	dataPtr unsafe.Pointer
 	length  uint64
 	flags   UcpAmRecvAttrs
+	bytes   []byte
+	// Argument of the handler, see UcpAmHandlerParams.SetArg()
+	handlerArg interface{}
+	// The data is held after the callback until it's released
+	held     bool
+	released bool
+}
+
+// The callback of the AM handler with the data ownership mode.
+type amRecvHandler struct {
+	id   uint
+	cb   UcpAmRecvCallback
+	mode UcpAmDataMode
+	arg  interface{}
+}
+
+// Invokes the callback of the handler, and returns the status that tells the
+// library whether the data is held.
+func (h *amRecvHandler) invoke(header unsafe.Pointer, headerSize uint64, data *UcpAmData,
+	replyEp *UcpEp) UcsStatus {
+	// Rendezvous data is received by UcpAmData.Receive() in all the modes
+	if data.IsDataValid() {
+		switch h.mode {
+		case UcpAmDataModePersist:
+			data.held = data.CanPersist()
+			h.cb(header, headerSize, data, replyEp)
+			if data.held {
+				return UCS_INPROGRESS
+			}
+			return UCS_OK
+		case UcpAmDataModeCopy:
+			data.bytes = C.GoBytes(data.dataPtr, C.int(data.length))
+			h.cb(header, headerSize, data, replyEp)
+			return UCS_OK
+		}
+	}
+
+	status := h.cb(header, headerSize, data, replyEp)
+	data.held = status == UCS_INPROGRESS
+	return status
 }
 
 // To connect callback id with worker, to use in AmData.Receive()
 var idToWorker = make(map[uint64]*UcpWorker)
+var idToWorkerMu sync.RWMutex
+
+func getWorkerById(cbId uint64) *UcpWorker {
+	idToWorkerMu.RLock()
+	defer idToWorkerMu.RUnlock()
+	return idToWorker[cbId]
+}
+
+func setWorkerById(cbId uint64, worker *UcpWorker) {
+	idToWorkerMu.Lock()
+	defer idToWorkerMu.Unlock()
+	if worker == nil {
+		delete(idToWorker, cbId)
+	} else {
+		idToWorker[cbId] = worker
+	}
+}
+
+// Returns the argument of the handler, that received the message, see
+// UcpAmHandlerParams.SetArg(), so one callback serves several handlers.
+func (d *UcpAmData) HandlerArg() interface{} {
+	return d.handlerArg
+}
 
 // Whether actual data is received or need to call UcpAmData.Receive()
 func (d *UcpAmData) IsDataValid() bool {
@@ -34,7 +145,8 @@ func (d *UcpAmData) CanPersist() bool {
 	return (d.flags & UCP_AM_RECV_ATTR_FLAG_DATA) != 0
 }
 
-// Pointer to a received data
+// Pointer to a received data. It's valid in the callback, and after it while
+// the data is held, e.g. until UcpAmData.Release() in UcpAmDataModePersist mode.
 func (d *UcpAmData) DataPointer() (unsafe.Pointer, error) {
 	if !d.IsDataValid() {
 		return nil, errors.New("data is not received yet")
@@ -46,10 +158,127 @@ func (d *UcpAmData) Length() uint64 {
 	return d.length
 }
 
+// Copy of the received data in UcpAmDataModeCopy mode. Returns nil in the other
+// modes, or if the data is not received yet (see UcpAmData.IsDataValid()).
+func (d *UcpAmData) Bytes() []byte {
+	return d.bytes
+}
+
 func (d *UcpAmData) Receive(recvBuffer unsafe.Pointer, size uint64, params *UcpRequestParams) (*UcpRequest, error) {
 	return d.worker.RecvAmDataNonBlocking(d, recvBuffer, size, params)
 }
 
+// Fails the receive before it's started, so the callback of the params is not
+// invoked. The returned request is completed with the status.
+func failAmReceive(worker *UcpWorker, status UcsStatus, params *UcpRequestParams) (*UcpRequest, error) {
+	if (params != nil) && (params.release != nil) {
+		params.release()
+	}
+	return &UcpRequest{worker: worker.worker, Status: status}, NewUcxError(status)
+}
+
+// Copies the data, that is already received, to the host buffer, and
+// completes the request immediately, the same as the receive would.
+func (d *UcpAmData) copyTo(recvBuffer unsafe.Pointer, params *UcpRequestParams) (*UcpRequest, error) {
+	requestParams := getRequestParams()
+	defer putRequestParams(requestParams)
+
+	cbId, done := setAmRecvDataParams(params, requestParams)
+	if d.length > 0 {
+		C.memcpy(recvBuffer, d.dataPtr, C.size_t(d.length))
+	}
+	return NewRequest(nil, d.worker.worker, cbId, done, C.size_t(d.length))
+}
+
+// Receives the data to the Go slice of at least Length() bytes, e.g. the
+// destination of the large message, without an intermediate buffer. The slice
+// is pinned until the request completes (see the Go version note in
+// bytes.go), and must not be accessed meanwhile. The data, that is already
+// received (see UcpAmData.IsDataValid()), is copied to the slice, and the
+// request completes immediately, so the handler doesn't depend on the
+// protocol of the message. It's called from the callback, or while the data is
+// held. Fails with UCS_ERR_MESSAGE_TRUNCATED, if the slice is too short.
+func (d *UcpAmData) ReceiveBytes(data []byte, params *UcpRequestParams) (*UcpRequest, error) {
+	if uint64(len(data)) < d.length {
+		return failAmReceive(d.worker, UCS_ERR_MESSAGE_TRUNCATED, params)
+	}
+
+	address, release := pinRecvBytes(data[:d.length])
+	params = withRelease(params, release)
+	params = withCachedBytes(params, d.worker.worker, address, data[:d.length])
+	if d.IsDataValid() {
+		return d.copyTo(address, params)
+	}
+	return d.worker.RecvAmDataNonBlocking(d, address, d.length, params)
+}
+
+// Receives the data to the buffer of the pool, e.g. the registered or the GPU
+// memory, and returns the buffer, that the caller puts back to the pool once
+// the request completes. The memory type of the pool, if it's known, is set
+// to the params. The data, that is already received, is copied to the host
+// buffer the same as by UcpAmData.ReceiveBytes(), whereas it fails with
+// UCS_ERR_UNSUPPORTED for the other memory types. Fails with
+// UCS_ERR_MESSAGE_TRUNCATED, if the buffers of the pool are smaller than the
+// data, and with UCS_ERR_NO_MEMORY, if the pool is exhausted; the buffer is
+// nil on failure.
+func (d *UcpAmData) ReceiveToPool(pool UcpBufferPool, params *UcpRequestParams) (unsafe.Pointer, *UcpRequest, error) {
+	if pool.BufferSize() < d.length {
+		request, err := failAmReceive(d.worker, UCS_ERR_MESSAGE_TRUNCATED, params)
+		return nil, request, err
+	}
+
+	memType := UCS_MEMORY_TYPE_UNKNOWN
+	if memTypePool, ok := pool.(UcpMemoryTypeBufferPool); ok {
+		memType = memTypePool.MemoryType()
+	}
+
+	if d.IsDataValid() && (memType != UCS_MEMORY_TYPE_UNKNOWN) && (memType != UCS_MEMORY_TYPE_HOST) {
+		request, err := failAmReceive(d.worker, UCS_ERR_UNSUPPORTED, params)
+		return nil, request, err
+	}
+
+	buffer := pool.Get()
+	if buffer == nil {
+		request, err := failAmReceive(d.worker, UCS_ERR_NO_MEMORY, params)
+		return nil, request, err
+	}
+
+	if memType != UCS_MEMORY_TYPE_UNKNOWN {
+		var memTypeParams UcpRequestParams
+		if params != nil {
+			memTypeParams = *params
+		}
+		params = memTypeParams.SetMemType(memType)
+	}
+
+	var request *UcpRequest
+	var err error
+	if d.IsDataValid() {
+		request, err = d.copyTo(buffer, params)
+	} else {
+		request, err = d.worker.RecvAmDataNonBlocking(d, buffer, d.length, params)
+	}
+
+	if err != nil {
+		pool.Put(buffer)
+		return nil, request, err
+	}
+	return buffer, request, nil
+}
+
+// Releases the data, that is held after the callback, back to the library.
+// Does nothing if the data is not held, e.g. in UcpAmDataModeCopy mode, or if
+// it's already released. In UcpAmDataModeDescriptor mode the data is held once
+// the callback returns UCS_INPROGRESS, so the routine must not be called
+// before that.
+func (d *UcpAmData) Release() {
+	if d.held && !d.released {
+		d.released = true
+		C.ucp_am_data_release(d.worker.worker, d.dataPtr)
+	}
+}
+
+// Same as UcpAmData.Release().
 func (d *UcpAmData) Close() {
-	C.ucp_am_data_release(d.worker.worker, d.dataPtr)
+	d.Release()
 }
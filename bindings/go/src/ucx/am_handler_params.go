@@ -0,0 +1,63 @@
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package ucx
+
+// Parameters of the Active Message handler, see UcpWorker.SetAmHandler().
+type UcpAmHandlerParams struct {
+	id    uint
+	flags UcpAmCbFlags
+	mode  UcpAmDataMode
+	arg   interface{}
+	cb    UcpAmRecvCallback
+}
+
+// Active Message id, that the handler receives.
+func (p *UcpAmHandlerParams) SetId(id uint) *UcpAmHandlerParams {
+	p.id = id
+	return p
+}
+
+// Flags of the handler, e.g. UCP_AM_FLAG_WHOLE_MSG to receive the message only
+// once all its fragments arrive.
+func (p *UcpAmHandlerParams) SetFlags(flags UcpAmCbFlags) *UcpAmHandlerParams {
+	p.flags = flags
+	return p
+}
+
+// Requests the whole message in the callback, so the eager messages are
+// never split to the fragments.
+func (p *UcpAmHandlerParams) WholeMessage() *UcpAmHandlerParams {
+	p.flags |= UCP_AM_FLAG_WHOLE_MSG
+	return p
+}
+
+// Allows the callback to keep the received data after it returns, until
+// UcpAmData.Release(), by setting UCP_AM_FLAG_PERSISTENT_DATA.
+func (p *UcpAmHandlerParams) PersistentData() *UcpAmHandlerParams {
+	p.flags |= UCP_AM_FLAG_PERSISTENT_DATA
+	return p
+}
+
+// Ownership mode of the received data, see UcpAmDataMode.
+// UcpAmDataModePersist implies UCP_AM_FLAG_PERSISTENT_DATA.
+func (p *UcpAmHandlerParams) SetDataMode(mode UcpAmDataMode) *UcpAmHandlerParams {
+	p.mode = mode
+	return p
+}
+
+// Argument, that is returned by UcpAmData.HandlerArg() of the messages
+// received by the handler, so one callback serves several ids or versions of
+// the handler.
+func (p *UcpAmHandlerParams) SetArg(arg interface{}) *UcpAmHandlerParams {
+	p.arg = arg
+	return p
+}
+
+// Callback of the handler, nil callback removes the handler of the id.
+func (p *UcpAmHandlerParams) SetCallback(cb UcpAmRecvCallback) *UcpAmHandlerParams {
+	p.cb = cb
+	return p
+}
@@ -0,0 +1,299 @@
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package ucx
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"sort"
+)
+
+// Version of the serialized bootstrap info, that is checked on unmarshaling.
+const bootstrapVersion byte = 1
+
+var bootstrapMagic = []byte("UCXB")
+
+// Memory of the worker, that is exposed to the peer for RMA and atomics.
+type UcpBootstrapMemory struct {
+	Address uint64
+	Length  uint64
+	Rkey    []byte
+}
+
+// Everything the peer needs to communicate with the worker: the worker
+// address, the packed remote keys of the exposed memory and the ids of the
+// Active Messages, each of them by the name agreed with the peer. It's
+// serialized to a single blob by MarshalBinary(), or to the text suitable for
+// the environment variables and the command line of the child process by
+// MarshalText(), e.g. by the launcher, that spawns the worker processes.
+type UcpBootstrapInfo struct {
+	Address  []byte
+	Memories map[string]UcpBootstrapMemory
+	AmIds    map[string]uint
+}
+
+// Peer connected by UcpWorker.ConnectBootstrap(), along with the remote keys
+// of its memory, that are unpacked on the endpoint.
+type UcpBootstrapPeer struct {
+	Ep       *UcpEp
+	Memories map[string]*UcpRemoteMemory
+	AmIds    map[string]uint
+}
+
+// Remote memory of the peer, that is accessed by the operations of the
+// endpoint, e.g. UcpEp.RmaPutNonBlocking(memory.Address, memory.Rkey).
+type UcpRemoteMemory struct {
+	Address uint64
+	Length  uint64
+	Rkey    *UcpRkey
+}
+
+// Returns the bootstrap info with the address of the worker, to add the
+// memories and the Active Message ids to.
+func (w *UcpWorker) BootstrapInfo() (*UcpBootstrapInfo, error) {
+	address, err := w.GetAddress()
+	if err != nil {
+		return nil, err
+	}
+	defer address.Close()
+
+	addressBytes, err := address.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	return &UcpBootstrapInfo{
+		Address:  addressBytes,
+		Memories: make(map[string]UcpBootstrapMemory),
+		AmIds:    make(map[string]uint),
+	}, nil
+}
+
+// Exposes the mapped memory to the peer by the name.
+func (i *UcpBootstrapInfo) AddMemory(name string, memory *UcpMemory) error {
+	attrs, err := memory.Query(UCP_MEM_ATTR_FIELD_ADDRESS, UCP_MEM_ATTR_FIELD_LENGTH)
+	if err != nil {
+		return err
+	}
+
+	rkey, err := memory.RkeyPack()
+	if err != nil {
+		return err
+	}
+
+	if i.Memories == nil {
+		i.Memories = make(map[string]UcpBootstrapMemory)
+	}
+	i.Memories[name] = UcpBootstrapMemory{
+		Address: uint64(uintptr(attrs.Address)),
+		Length:  attrs.Length,
+		Rkey:    rkey,
+	}
+	return nil
+}
+
+// Tells the peer the id of the Active Message by the name.
+func (i *UcpBootstrapInfo) AddAmId(name string, id uint) *UcpBootstrapInfo {
+	if i.AmIds == nil {
+		i.AmIds = make(map[string]uint)
+	}
+	i.AmIds[name] = id
+	return i
+}
+
+func appendBootstrapBytes(buffer []byte, data []byte) []byte {
+	var size [binary.MaxVarintLen64]byte
+	buffer = append(buffer, size[:binary.PutUvarint(size[:], uint64(len(data)))]...)
+	return append(buffer, data...)
+}
+
+func appendBootstrapUint(buffer []byte, value uint64) []byte {
+	var data [binary.MaxVarintLen64]byte
+	return append(buffer, data[:binary.PutUvarint(data[:], value)]...)
+}
+
+// Serializes the info, the names are sorted so the blob is deterministic.
+func (i *UcpBootstrapInfo) MarshalBinary() ([]byte, error) {
+	buffer := append(append([]byte(nil), bootstrapMagic...), bootstrapVersion)
+	buffer = appendBootstrapBytes(buffer, i.Address)
+
+	memoryNames := make([]string, 0, len(i.Memories))
+	for name := range i.Memories {
+		memoryNames = append(memoryNames, name)
+	}
+	sort.Strings(memoryNames)
+	buffer = appendBootstrapUint(buffer, uint64(len(memoryNames)))
+	for _, name := range memoryNames {
+		memory := i.Memories[name]
+		buffer = appendBootstrapBytes(buffer, []byte(name))
+		buffer = appendBootstrapUint(buffer, memory.Address)
+		buffer = appendBootstrapUint(buffer, memory.Length)
+		buffer = appendBootstrapBytes(buffer, memory.Rkey)
+	}
+
+	amNames := make([]string, 0, len(i.AmIds))
+	for name := range i.AmIds {
+		amNames = append(amNames, name)
+	}
+	sort.Strings(amNames)
+	buffer = appendBootstrapUint(buffer, uint64(len(amNames)))
+	for _, name := range amNames {
+		buffer = appendBootstrapBytes(buffer, []byte(name))
+		buffer = appendBootstrapUint(buffer, uint64(i.AmIds[name]))
+	}
+	return buffer, nil
+}
+
+// Reader of the serialized info, which fails all the reads after the first
+// malformed field.
+type bootstrapReader struct {
+	data   []byte
+	failed bool
+}
+
+func (r *bootstrapReader) uint() uint64 {
+	if r.failed {
+		return 0
+	}
+
+	value, n := binary.Uvarint(r.data)
+	if n <= 0 {
+		r.failed = true
+		return 0
+	}
+	r.data = r.data[n:]
+	return value
+}
+
+func (r *bootstrapReader) bytes() []byte {
+	size := r.uint()
+	if r.failed || (size > uint64(len(r.data))) {
+		r.failed = true
+		return nil
+	}
+
+	data := append([]byte(nil), r.data[:size]...)
+	r.data = r.data[size:]
+	return data
+}
+
+// Restores the info serialized by UcpBootstrapInfo.MarshalBinary().
+func (i *UcpBootstrapInfo) UnmarshalBinary(data []byte) error {
+	header := len(bootstrapMagic) + 1
+	if (len(data) < header) || (string(data[:len(bootstrapMagic)]) != string(bootstrapMagic)) ||
+		(data[len(bootstrapMagic)] != bootstrapVersion) {
+		return NewUcxError(UCS_ERR_INVALID_PARAM)
+	}
+
+	r := &bootstrapReader{data: data[header:]}
+	info := UcpBootstrapInfo{
+		Address:  r.bytes(),
+		Memories: make(map[string]UcpBootstrapMemory),
+		AmIds:    make(map[string]uint),
+	}
+
+	for count := r.uint(); !r.failed && (count > 0); count-- {
+		name := string(r.bytes())
+		info.Memories[name] = UcpBootstrapMemory{
+			Address: r.uint(),
+			Length:  r.uint(),
+			Rkey:    r.bytes(),
+		}
+	}
+
+	for count := r.uint(); !r.failed && (count > 0); count-- {
+		name := string(r.bytes())
+		info.AmIds[name] = uint(r.uint())
+	}
+
+	if r.failed || (len(r.data) != 0) || (len(info.Address) == 0) {
+		return NewUcxError(UCS_ERR_INVALID_PARAM)
+	}
+
+	*i = info
+	return nil
+}
+
+// Serializes the info to the base64 text, e.g. to pass it to the child process
+// in the environment variable.
+func (i *UcpBootstrapInfo) MarshalText() ([]byte, error) {
+	data, err := i.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	text := make([]byte, base64.RawURLEncoding.EncodedLen(len(data)))
+	base64.RawURLEncoding.Encode(text, data)
+	return text, nil
+}
+
+// Restores the info serialized by UcpBootstrapInfo.MarshalText().
+func (i *UcpBootstrapInfo) UnmarshalText(text []byte) error {
+	data := make([]byte, base64.RawURLEncoding.DecodedLen(len(text)))
+	n, err := base64.RawURLEncoding.Decode(data, text)
+	if err != nil {
+		return NewUcxError(UCS_ERR_INVALID_PARAM)
+	}
+	return i.UnmarshalBinary(data[:n])
+}
+
+// Creates the endpoint to the worker of the info, and unpacks the remote keys
+// of its memories on it. epParams may be nil, otherwise its address is
+// overwritten. The peer must be closed by UcpBootstrapPeer.Close().
+func (w *UcpWorker) ConnectBootstrap(info *UcpBootstrapInfo, epParams *UcpEpParams) (*UcpBootstrapPeer, error) {
+	if epParams == nil {
+		epParams = &UcpEpParams{}
+	}
+
+	ep, err := w.NewEndpoint(epParams.SetUcpAddressBytes(info.Address))
+	if err != nil {
+		return nil, err
+	}
+
+	peer := &UcpBootstrapPeer{
+		Ep:       ep,
+		Memories: make(map[string]*UcpRemoteMemory, len(info.Memories)),
+		AmIds:    make(map[string]uint, len(info.AmIds)),
+	}
+
+	for name, memory := range info.Memories {
+		rkey, err := ep.UnpackRkey(memory.Rkey)
+		if err != nil {
+			peer.closeRkeys()
+			if request, _ := ep.CloseNonBlockingForce(nil); request != nil {
+				request.Close()
+			}
+			return nil, err
+		}
+
+		peer.Memories[name] = &UcpRemoteMemory{
+			Address: memory.Address,
+			Length:  memory.Length,
+			Rkey:    rkey,
+		}
+	}
+
+	for name, id := range info.AmIds {
+		peer.AmIds[name] = id
+	}
+	return peer, nil
+}
+
+func (p *UcpBootstrapPeer) closeRkeys() {
+	for _, memory := range p.Memories {
+		memory.Rkey.Close()
+	}
+	p.Memories = nil
+}
+
+// Destroys the remote keys, and starts the closure of the endpoint by
+// UcpEp.CloseNonBlockingFlush(). The returned request completes once the
+// endpoint is closed. The operations on the remote memory must be completed
+// before this call.
+func (p *UcpBootstrapPeer) Close() (*UcpRequest, error) {
+	p.closeRkeys()
+	return p.Ep.CloseNonBlockingFlush(nil)
+}
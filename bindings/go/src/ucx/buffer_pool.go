@@ -0,0 +1,93 @@
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package ucx
+
+import (
+	"sync"
+	"unsafe"
+)
+
+// Source of native buffers of the same size, e.g. for persistent receives.
+type UcpBufferPool interface {
+	// Returns a buffer, or nil if the pool is exhausted.
+	Get() unsafe.Pointer
+
+	// Returns the buffer, that was obtained by Get(), back to the pool.
+	Put(buffer unsafe.Pointer)
+
+	// Size of every buffer of the pool.
+	BufferSize() uint64
+}
+
+// Optional interface of UcpBufferPool, which buffers are of the same memory
+// type, so the receives to the buffers skip the memory type detection, see
+// UcpRequestParams.SetMemType().
+type UcpMemoryTypeBufferPool interface {
+	MemoryType() UcsMemoryType
+}
+
+// Fixed size pool of buffers allocated from the native memory.
+type UcpNativeBufferPool struct {
+	bufferSize uint64
+	buffers    []unsafe.Pointer
+	free       []unsafe.Pointer
+	mu         sync.Mutex
+}
+
+// Allocates count buffers of bufferSize each. The pool must be released by
+// UcpNativeBufferPool.Close() once all its buffers are returned.
+func NewNativeBufferPool(bufferSize uint64, count int) *UcpNativeBufferPool {
+	p := &UcpNativeBufferPool{
+		bufferSize: bufferSize,
+		buffers:    make([]unsafe.Pointer, count),
+		free:       make([]unsafe.Pointer, count),
+	}
+
+	for i := range p.buffers {
+		p.buffers[i] = AllocateNativeMemory(bufferSize)
+	}
+	copy(p.free, p.buffers)
+	return p
+}
+
+func (p *UcpNativeBufferPool) Get() unsafe.Pointer {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.free) == 0 {
+		return nil
+	}
+
+	buffer := p.free[len(p.free)-1]
+	p.free = p.free[:len(p.free)-1]
+	return buffer
+}
+
+func (p *UcpNativeBufferPool) Put(buffer unsafe.Pointer) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.free = append(p.free, buffer)
+}
+
+func (p *UcpNativeBufferPool) BufferSize() uint64 {
+	return p.bufferSize
+}
+
+func (p *UcpNativeBufferPool) MemoryType() UcsMemoryType {
+	return UCS_MEMORY_TYPE_HOST
+}
+
+// Releases the memory of all the buffers of the pool.
+func (p *UcpNativeBufferPool) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, buffer := range p.buffers {
+		FreeNativeMemory(buffer)
+	}
+	p.buffers = nil
+	p.free = nil
+}
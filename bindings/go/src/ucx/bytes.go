@@ -0,0 +1,44 @@
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package ucx
+
+import (
+	"unsafe"
+)
+
+// The routines below send Go slices without copying them to the native memory.
+// The slice is pinned by runtime.Pinner (Go 1.21 and later) until the operation
+// completes, so it must not be modified meanwhile. Older Go versions can't pin
+// the memory, so the slice is copied to the native memory instead. The slice
+// is registered by the memory cache of the worker, if it's enabled by
+// UcpWorkerParams.SetMemoryCacheCapacity().
+
+// Tag send of the Go slice, see UcpEp.SendTagNonBlocking().
+func (e *UcpEp) SendTagBytesNonBlocking(tag uint64, data []byte, params *UcpRequestParams) (*UcpRequest, error) {
+	address, release := pinBytes(data)
+	params = withRelease(params, release)
+	params = e.withCachedBytes(params, address, data)
+	return e.SendTagNonBlocking(tag, address, uint64(len(data)), params)
+}
+
+// Stream send of the Go slice, see UcpEp.SendStreamNonBlocking().
+func (e *UcpEp) SendStreamBytesNonBlocking(data []byte, params *UcpRequestParams) (*UcpRequest, error) {
+	address, release := pinBytes(data)
+	params = withRelease(params, release)
+	params = e.withCachedBytes(params, address, data)
+	return e.SendStreamNonBlocking(address, uint64(len(data)), params)
+}
+
+// Active message send of the Go slice, see UcpEp.SendAmNonBlocking(). The
+// header is still passed as is, so it has to be valid until the operation
+// completes, unless UCP_AM_SEND_FLAG_COPY_HEADER is set.
+func (e *UcpEp) SendAmBytesNonBlocking(id uint, header unsafe.Pointer, headerSize uint64, data []byte,
+	flags UcpAmSendFlags, params *UcpRequestParams) (*UcpRequest, error) {
+	address, release := pinBytes(data)
+	params = withRelease(params, release)
+	params = e.withCachedBytes(params, address, data)
+	return e.SendAmNonBlocking(id, header, headerSize, address, uint64(len(data)), flags, params)
+}
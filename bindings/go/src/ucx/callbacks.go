@@ -1,3 +1,6 @@
+//go:build linux
+// +build linux
+
 /*
  * Copyright (C) 2021, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
  * See file LICENSE for terms.
@@ -9,6 +12,7 @@ package ucx
 import "C"
 import (
 	"sync"
+	"time"
 	"unsafe"
 )
 
@@ -20,6 +24,10 @@ type UcpTagRecvCallback = func(request *UcpRequest, status UcsStatus, tagInfo *U
 
 type UcpAmDataRecvCallback = func(request *UcpRequest, status UcsStatus, length uint64)
 
+// Has the same signature as UcpAmDataRecvCallback, so both are the same type.
+type UcpStreamRecvCallback = func(request *UcpRequest, status UcsStatus, length uint64)
+
+// The header is valid only until the callback returns, see AmHeader().
 type UcpAmRecvCallback = func(header unsafe.Pointer, headerSize uint64,
 	data *UcpAmData, replyEp *UcpEp) UcsStatus
 
@@ -27,8 +35,29 @@ type UcpAmRecvCallback = func(header unsafe.Pointer, headerSize uint64,
 // connections from remote clients.
 type UcpListenerConnectionHandler = func(connRequest *UcpConnectionRequest)
 
+// The native code can't keep Go pointers (see the cgo pointer passing rules),
+// so the callbacks, which are usually closures capturing Go objects, are
+// passed to C as the handles of this registry instead. A handle is a unique
+// index, that is cast to user_data or arg of the C callback, and back, only on
+// the C side (see goucx.h), so Go never keeps it in a pointer.
+type callbackEntry struct {
+	cb UcpCallback
+	// Releases the resources of the operation, e.g. a native io vector. It's
+	// invoked once the request is completed, even if it's closed before.
+	release func()
+	// The request was closed while in progress, so it's freed on completion
+	// without invoking the callback.
+	detached bool
+	// Deadline of the operation, nil if it's not set
+	deadline *requestDeadline
+	// Transfer accounted on completion, nil if the stats are not collected
+	transfer *transferRecord
+	// See UcpRequestParams.SetUserData()
+	userData interface{}
+}
+
 // Map from the callback id that is passed to C to the actual go callback.
-var callback_map = make(map[uint64]UcpCallback)
+var callback_map = make(map[uint64]*callbackEntry)
 
 // Unique index for each go callback, that passes to user_data.
 var callback_id uint64 = 1
@@ -37,45 +66,122 @@ var mu sync.Mutex
 
 // Associates go callback with a unique id
 func register(cb UcpCallback) uint64 {
+	return registerRequest(cb, nil, time.Time{}, nil, nil)
+}
+
+// Associates the callback of the operation and the release of its resources
+// with a unique id, which is passed to completeRequest() on completion. The
+// deadline is tracked by NewRequest(), unless it's zero.
+func registerRequest(cb UcpCallback, release func(), deadline time.Time, transfer *transferRecord,
+	userData interface{}) uint64 {
 	mu.Lock()
 	defer mu.Unlock()
 	callback_id++
-	callback_map[callback_id] = cb
+	callback_map[callback_id] = &callbackEntry{cb: cb, release: release,
+		deadline: newRequestDeadline(deadline, callback_id), transfer: transfer, userData: userData}
 	return callback_id
 }
 
+// Returns the user data of the operation in progress.
+func requestUserData(id uint64) interface{} {
+	mu.Lock()
+	defer mu.Unlock()
+	if entry, found := callback_map[id]; found {
+		return entry.userData
+	}
+	return nil
+}
+
 // Atomically removes registered callback by it's id
 func deregister(id uint64) (UcpCallback, bool) {
 	mu.Lock()
 	defer mu.Unlock()
-	val, ret := callback_map[id]
+	entry, ret := callback_map[id]
+	if !ret {
+		return nil, false
+	}
 	delete(callback_map, id)
-	return val, ret
+	return entry.cb, true
 }
 
 func getCallback(id uint64) (UcpCallback, bool) {
 	mu.Lock()
 	defer mu.Unlock()
-	val, ret := callback_map[id]
-	return val, ret
+	entry, ret := callback_map[id]
+	if !ret {
+		return nil, false
+	}
+	return entry.cb, true
+}
+
+// Removes the handle of the completed operation and releases its resources.
+// Returns the callback to invoke along with the user data, unless the request was closed before, in
+// which case the request is freed here. request is nil for the immediate
+// completion. length is the received length of the receives.
+func completeRequest(id uint64, request unsafe.Pointer, status UcsStatus, length uint64) (UcpCallback,
+	interface{}, bool) {
+	mu.Lock()
+	entry, found := callback_map[id]
+	delete(callback_map, id)
+	mu.Unlock()
+
+	if !found {
+		return nil, nil, false
+	}
+
+	if entry.deadline != nil {
+		untrackDeadline(entry.deadline)
+	}
+
+	if entry.release != nil {
+		entry.release()
+	}
+
+	if entry.transfer != nil {
+		entry.transfer.complete(status, length)
+	}
+
+	if entry.detached {
+		if request != nil {
+			C.ucp_request_free(request)
+		}
+		return nil, nil, false
+	}
+
+	return entry.cb, entry.userData, entry.cb != nil
+}
+
+// Marks the operation in progress as closed, so its callback is not invoked.
+// Returns false if the operation is already completed.
+func detachRequest(id uint64) bool {
+	mu.Lock()
+	defer mu.Unlock()
+	entry, found := callback_map[id]
+	if found {
+		entry.detached = true
+	}
+	return found
 }
 
 //export ucxgo_completeGoSendRequest
-func ucxgo_completeGoSendRequest(request unsafe.Pointer, status C.ucs_status_t, callbackId unsafe.Pointer) {
-	if callback, found := deregister(uint64(uintptr(callbackId))); found {
+func ucxgo_completeGoSendRequest(request unsafe.Pointer, status C.ucs_status_t, callbackId C.uint64_t) {
+	if callback, userData, found := completeRequest(uint64(callbackId), request, UcsStatus(status), 0); found {
 		callback.(UcpSendCallback)(&UcpRequest{
-			request: request,
-			Status:  UcsStatus(status),
+			request:  request,
+			Status:   UcsStatus(status),
+			userData: userData,
 		}, UcsStatus(status))
 	}
 }
 
 //export ucxgo_completeGoTagRecvRequest
-func ucxgo_completeGoTagRecvRequest(request unsafe.Pointer, status C.ucs_status_t, tag_info *C.ucp_tag_recv_info_t, callbackId unsafe.Pointer) {
-	if callback, found := deregister(uint64(uintptr(callbackId))); found {
+func ucxgo_completeGoTagRecvRequest(request unsafe.Pointer, status C.ucs_status_t, tag_info *C.ucp_tag_recv_info_t, callbackId C.uint64_t) {
+	if callback, userData, found := completeRequest(uint64(callbackId), request, UcsStatus(status),
+		uint64(tag_info.length)); found {
 		callback.(UcpTagRecvCallback)(&UcpRequest{
-			request: request,
-			Status:  UcsStatus(status),
+			request:  request,
+			Status:   UcsStatus(status),
+			userData: userData,
 		}, UcsStatus(status), &UcpTagRecvInfo{
 			SenderTag: uint64(tag_info.sender_tag),
 			Length:    uint64(tag_info.length),
@@ -83,34 +189,90 @@ func ucxgo_completeGoTagRecvRequest(request unsafe.Pointer, status C.ucs_status_
 	}
 }
 
+//export ucxgo_completePersistentTagRecv
+func ucxgo_completePersistentTagRecv(request unsafe.Pointer, status C.ucs_status_t, tag_info *C.ucp_tag_recv_info_t, callbackId C.uint64_t) {
+	C.ucp_request_free(request)
+	if slot, found := getCallback(uint64(callbackId)); found {
+		slot := slot.(*persistentRecvSlot)
+		slot.recv.complete(slot, UcsStatus(status), tag_info)
+	}
+}
+
+//export ucxgo_completePersistentSend
+func ucxgo_completePersistentSend(request unsafe.Pointer, status C.ucs_status_t, callbackId C.uint64_t) {
+	C.ucp_request_free(request)
+	if r, found := getCallback(uint64(callbackId)); found {
+		r.(*UcpPersistentRequest).complete(UcsStatus(status))
+	}
+}
+
 //export ucxgo_amRecvCallback
-func ucxgo_amRecvCallback(calbackId unsafe.Pointer, header unsafe.Pointer, headerSize C.size_t,
+func ucxgo_amRecvCallback(callbackId C.uint64_t, header unsafe.Pointer, headerSize C.size_t,
 	data unsafe.Pointer, dataSize C.size_t, params *C.ucp_am_recv_param_t) C.ucs_status_t {
-	cbId := uint64(uintptr(calbackId))
+	cbId := uint64(callbackId)
 	if callback, found := getCallback(cbId); found {
 		var replyEp *UcpEp
+		var replyEpHandle C.ucp_ep_h
+		worker := getWorkerById(cbId)
 		if (params.recv_attr & C.UCP_AM_RECV_ATTR_FIELD_REPLY_EP) != 0 {
-			replyEp = &UcpEp{ep: params.reply_ep}
+			replyEpHandle = params.reply_ep
+			replyEp = &UcpEp{ep: replyEpHandle, worker: worker.worker}
 		}
+		handler := callback.(*amRecvHandler)
+		countAmRecv(worker.worker, replyEpHandle, handler.id, uint64(headerSize+dataSize))
 		amData := &UcpAmData{
-			worker:  idToWorker[cbId],
-			flags:   UcpAmRecvAttrs(params.recv_attr),
-			dataPtr: data,
-			length:  uint64(dataSize),
+			worker:     worker,
+			flags:      UcpAmRecvAttrs(params.recv_attr),
+			dataPtr:    data,
+			length:     uint64(dataSize),
+			handlerArg: handler.arg,
 		}
-		return C.ucs_status_t(callback.(UcpAmRecvCallback)(header, uint64(headerSize), amData, replyEp))
+		return C.ucs_status_t(handler.invoke(header, uint64(headerSize), amData, replyEp))
 	}
 	return C.UCS_OK
 }
 
 //export ucxgo_completeAmRecvData
 func ucxgo_completeAmRecvData(request unsafe.Pointer, status C.ucs_status_t,
-	length C.size_t, callbackId unsafe.Pointer) {
+	length C.size_t, callbackId C.uint64_t) {
 
-	if callback, found := deregister(uint64(uintptr(callbackId))); found {
+	if callback, userData, found := completeRequest(uint64(callbackId), request, UcsStatus(status),
+		uint64(length)); found {
 		callback.(UcpAmDataRecvCallback)(&UcpRequest{
-			request: request,
-			Status:  UcsStatus(status),
+			request:  request,
+			Status:   UcsStatus(status),
+			userData: userData,
+		}, UcsStatus(status), uint64(length))
+	}
+}
+
+//export ucxgo_completeGoStreamRecvRequest
+func ucxgo_completeGoStreamRecvRequest(request unsafe.Pointer, status C.ucs_status_t,
+	length C.size_t, callbackId C.uint64_t) {
+
+	if callback, userData, found := completeRequest(uint64(callbackId), request, UcsStatus(status),
+		uint64(length)); found {
+		callback.(UcpStreamRecvCallback)(&UcpRequest{
+			request:  request,
+			Status:   UcsStatus(status),
+			userData: userData,
 		}, UcsStatus(status), uint64(length))
 	}
 }
+
+//export ucxgo_logMessage
+func ucxgo_logMessage(file *C.char, line C.uint, function *C.char, level C.ucs_log_level_t,
+	component *C.char, message *C.char) {
+
+	componentName := C.GoString(component)
+	if handler := getLogHandler(componentName, UcsLogLevel(level)); handler != nil {
+		handler(&UcsLogRecord{
+			Level:     UcsLogLevel(level),
+			Component: componentName,
+			File:      C.GoString(file),
+			Line:      uint(line),
+			Function:  C.GoString(function),
+			Message:   C.GoString(message),
+		})
+	}
+}
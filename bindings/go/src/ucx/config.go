@@ -0,0 +1,210 @@
+//go:build linux
+// +build linux
+
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package ucx
+
+// #include <stdio.h>
+// #include <stdlib.h>
+// #include <ucp/api/ucp.h>
+import "C"
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unsafe"
+)
+
+// UCP configuration descriptor, that holds the values of UCX_* settings
+// (e.g. UCX_TLS, UCX_NET_DEVICES). It's read from the environment and can be
+// modified programmatically before passing to UcpParams.SetConfig().
+type UcpConfig struct {
+	config *C.ucp_config_t
+}
+
+// This routine reads the UCP configuration from the environment variables,
+// which names start with the envPrefix and UCX_ prefix, and from the optional
+// configuration file. Empty envPrefix and filename are ignored. The
+// configuration must be released by UcpConfig.Close().
+func NewUcpConfig(envPrefix string, filename string) (*UcpConfig, error) {
+	var config *C.ucp_config_t
+	var cEnvPrefix, cFilename *C.char
+
+	if envPrefix != "" {
+		cEnvPrefix = C.CString(envPrefix)
+		defer C.free(unsafe.Pointer(cEnvPrefix))
+	}
+
+	if filename != "" {
+		cFilename = C.CString(filename)
+		defer C.free(unsafe.Pointer(cFilename))
+	}
+
+	if status := C.ucp_config_read(cEnvPrefix, cFilename, &config); status != C.UCS_OK {
+		return nil, newUcxError(status)
+	}
+
+	return &UcpConfig{
+		config: config,
+	}, nil
+}
+
+// This routine changes one configuration setting, e.g. Modify("TLS", "tcp")
+// has the same effect as UCX_TLS=tcp environment variable.
+func (c *UcpConfig) Modify(name string, value string) error {
+	cName := C.CString(name)
+	defer C.free(unsafe.Pointer(cName))
+	cValue := C.CString(value)
+	defer C.free(unsafe.Pointer(cValue))
+
+	if status := C.ucp_config_modify(c.config, cName, cValue); status != C.UCS_OK {
+		return newUcxError(status)
+	}
+
+	return nil
+}
+
+// This routine limits the network devices, which the context uses, e.g.
+// SetNetDevices("mlx5_0:1", "mlx5_1:1"), same as UCX_NET_DEVICES. Since the
+// devices are selected per context, a process can pin its connections to
+// separate rails by the contexts created with different devices.
+func (c *UcpConfig) SetNetDevices(devices ...string) error {
+	return c.Modify("NET_DEVICES", strings.Join(devices, ","))
+}
+
+// This routine sets the maximal number of the devices, that a single endpoint
+// uses for the eager and the rendezvous protocols, same as
+// UCX_MAX_EAGER_RAILS and UCX_MAX_RNDV_RAILS.
+func (c *UcpConfig) SetMaxRails(rails int) error {
+	if err := c.Modify("MAX_EAGER_RAILS", strconv.Itoa(rails)); err != nil {
+		return err
+	}
+	return c.Modify("MAX_RNDV_RAILS", strconv.Itoa(rails))
+}
+
+// Special values of the size settings, e.g. SetRndvThreshold(UCS_MEMUNITS_INF)
+// disables the rendezvous protocol, and UCS_MEMUNITS_AUTO lets UCX calculate
+// the threshold by the performance of the transports.
+const (
+	UCS_MEMUNITS_INF  uint64 = math.MaxUint64
+	UCS_MEMUNITS_AUTO uint64 = math.MaxUint64 - 1
+)
+
+func memunitsString(size uint64) string {
+	switch size {
+	case UCS_MEMUNITS_INF:
+		return "inf"
+	case UCS_MEMUNITS_AUTO:
+		return "auto"
+	}
+	return strconv.FormatUint(size, 10)
+}
+
+// Scheme of the rendezvous protocol, that transfers the large messages.
+type UcpRndvScheme string
+
+const (
+	UCP_RNDV_SCHEME_AUTO         UcpRndvScheme = "auto"
+	UCP_RNDV_SCHEME_GET_ZCOPY    UcpRndvScheme = "get_zcopy"
+	UCP_RNDV_SCHEME_PUT_ZCOPY    UcpRndvScheme = "put_zcopy"
+	UCP_RNDV_SCHEME_GET_PIPELINE UcpRndvScheme = "get_ppln"
+	UCP_RNDV_SCHEME_PUT_PIPELINE UcpRndvScheme = "put_ppln"
+	UCP_RNDV_SCHEME_RKEY_PTR     UcpRndvScheme = "rkey_ptr"
+	UCP_RNDV_SCHEME_AM           UcpRndvScheme = "am"
+)
+
+// This routine sets the message size, from which the messages are sent by the
+// rendezvous protocol rather than eagerly, same as UCX_RNDV_THRESH.
+func (c *UcpConfig) SetRndvThreshold(size uint64) error {
+	return c.Modify("RNDV_THRESH", memunitsString(size))
+}
+
+// This routine sets the rendezvous thresholds separately for the peers on the
+// same node and on the other nodes, same as UCX_RNDV_THRESH=intra:X,inter:Y.
+func (c *UcpConfig) SetRndvThresholds(intra uint64, inter uint64) error {
+	return c.Modify("RNDV_THRESH", "intra:"+memunitsString(intra)+",inter:"+memunitsString(inter))
+}
+
+// This routine sets the message size, from which the eager messages are sent
+// without copying them to the transport buffers, same as UCX_ZCOPY_THRESH.
+func (c *UcpConfig) SetZcopyThreshold(size uint64) error {
+	return c.Modify("ZCOPY_THRESH", memunitsString(size))
+}
+
+// This routine sets the limit of the eager messages, that are sent inline by
+// the short protocol, the larger ones are copied to the transport buffers,
+// same as UCX_BCOPY_THRESH.
+func (c *UcpConfig) SetBcopyThreshold(size uint64) error {
+	return c.Modify("BCOPY_THRESH", memunitsString(size))
+}
+
+// This routine sets the scheme of the rendezvous protocol, same as
+// UCX_RNDV_SCHEME. Fails with ErrInvalidParam on unknown scheme.
+func (c *UcpConfig) SetRndvScheme(scheme UcpRndvScheme) error {
+	switch scheme {
+	case UCP_RNDV_SCHEME_AUTO, UCP_RNDV_SCHEME_GET_ZCOPY, UCP_RNDV_SCHEME_PUT_ZCOPY,
+		UCP_RNDV_SCHEME_GET_PIPELINE, UCP_RNDV_SCHEME_PUT_PIPELINE,
+		UCP_RNDV_SCHEME_RKEY_PTR, UCP_RNDV_SCHEME_AM:
+		return c.Modify("RNDV_SCHEME", string(scheme))
+	}
+	return ErrInvalidParam
+}
+
+// This routine sets the interval of the keepalive checks of the endpoints,
+// which detect the failure of the peer without the traffic, same as
+// UCX_KEEPALIVE_INTERVAL. The zero interval disables the checks. The failure
+// is reported to the error handler of the endpoint with the peer error
+// handling, see UcpEpParams.SetPeerErrorHandling().
+func (c *UcpConfig) SetKeepaliveInterval(interval time.Duration) error {
+	if interval <= 0 {
+		return c.Modify("KEEPALIVE_INTERVAL", "inf")
+	} else if interval < time.Microsecond {
+		interval = time.Microsecond
+	}
+	return c.Modify("KEEPALIVE_INTERVAL", strconv.FormatInt(interval.Microseconds(), 10)+"us")
+}
+
+// This routine sets the maximal number of the endpoints, that are checked on
+// every keepalive round, same as UCX_KEEPALIVE_NUM_EPS. The zero count checks
+// all the endpoints.
+func (c *UcpConfig) SetKeepaliveNumEps(count uint) error {
+	if count == 0 {
+		return c.Modify("KEEPALIVE_NUM_EPS", "inf")
+	}
+	return c.Modify("KEEPALIVE_NUM_EPS", strconv.FormatUint(uint64(count), 10))
+}
+
+// This routine returns the configuration in a human readable form, which
+// content is defined by print flags.
+func (c *UcpConfig) Print(title string, flags UcsConfigPrintFlags) string {
+	var buffer *C.char
+	var size C.size_t
+
+	cTitle := C.CString(title)
+	defer C.free(unsafe.Pointer(cTitle))
+
+	stream := C.open_memstream(&buffer, &size)
+	if stream == nil {
+		return ""
+	}
+
+	C.ucp_config_print(c.config, stream, cTitle, C.ucs_config_print_flags_t(flags))
+	C.fclose(stream)
+	defer C.free(unsafe.Pointer(buffer))
+
+	return C.GoStringN(buffer, C.int(size))
+}
+
+// This routine releases the configuration descriptor. The configuration can be
+// released right after the context is created.
+func (c *UcpConfig) Close() {
+	if c.config != nil {
+		C.ucp_config_release(c.config)
+		c.config = nil
+	}
+}
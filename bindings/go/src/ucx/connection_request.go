@@ -1,3 +1,6 @@
+//go:build linux
+// +build linux
+
 /*
  * Copyright (C) 2021, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
  * See file LICENSE for terms.
@@ -9,6 +12,7 @@ package ucx
 import "C"
 import (
 	"net"
+	"sync"
 )
 
 type UcpConnectionRequest struct {
@@ -21,13 +25,65 @@ type UcpConnectionRequestAttributes struct {
 	ClientId      uint64
 }
 
+// Connection requests passed to the connection handlers, that are neither
+// accepted nor rejected yet, to their listeners. The handle of the request is
+// released by the library once it's accepted or rejected, so it's used only
+// once, and the pending ones are rejected when their listener is closed.
+var connRequests = make(map[C.ucp_conn_request_h]C.ucp_listener_h)
+var connRequestsMu sync.Mutex
+
+func addConnRequest(connRequest C.ucp_conn_request_h, listener C.ucp_listener_h) {
+	connRequestsMu.Lock()
+	defer connRequestsMu.Unlock()
+	connRequests[connRequest] = listener
+}
+
+// Returns false, if the request is already accepted or rejected.
+func takeConnRequest(connRequest C.ucp_conn_request_h) bool {
+	connRequestsMu.Lock()
+	defer connRequestsMu.Unlock()
+	_, found := connRequests[connRequest]
+	delete(connRequests, connRequest)
+	return found
+}
+
+// Rejects the pending requests of the listener, that is closed.
+func rejectListenerConnRequests(listener C.ucp_listener_h) {
+	connRequestsMu.Lock()
+	var pending []C.ucp_conn_request_h
+	for connRequest, requestListener := range connRequests {
+		if requestListener == listener {
+			pending = append(pending, connRequest)
+			delete(connRequests, connRequest)
+		}
+	}
+	connRequestsMu.Unlock()
+
+	for _, connRequest := range pending {
+		C.ucp_listener_reject(listener, connRequest)
+	}
+}
+
+// This routine rejects the incoming connection request, e.g. to shed the load,
+// so the client endpoint fails with UCS_ERR_REJECTED, and releases the
+// request. Every request passed to the connection handler must be either
+// rejected or accepted by UcpWorker.NewEndpointFromConnRequest(); the ones
+// left are rejected once the listener is closed. Fails with ErrInvalidParam,
+// if the request is already accepted or rejected.
 func (c *UcpConnectionRequest) Reject() error {
+	if !takeConnRequest(c.connRequest) {
+		return ErrInvalidParam
+	}
+
 	if status := C.ucp_listener_reject(c.listener, c.connRequest); status != C.UCS_OK {
 		return newUcxError(status)
 	}
 	return nil
 }
 
+// This routine returns the attributes of the connection request, e.g. the
+// client address. Fails with ErrInvalidParam, if the request is already
+// accepted or rejected, since the library has released it.
 func (c *UcpConnectionRequest) Query(attrs ...UcpConnRequestAttribute) (*UcpConnectionRequestAttributes, error) {
 	var connReqAttr C.ucp_conn_request_attr_t
 
@@ -35,7 +91,16 @@ func (c *UcpConnectionRequest) Query(attrs ...UcpConnRequestAttribute) (*UcpConn
 		connReqAttr.field_mask |= C.ulong(attr)
 	}
 
-	if status := C.ucp_conn_request_query(c.connRequest, &connReqAttr); status != C.UCS_OK {
+	// The lock keeps the request from being accepted or rejected meanwhile
+	connRequestsMu.Lock()
+	if _, found := connRequests[c.connRequest]; !found {
+		connRequestsMu.Unlock()
+		return nil, ErrInvalidParam
+	}
+	status := C.ucp_conn_request_query(c.connRequest, &connReqAttr)
+	connRequestsMu.Unlock()
+
+	if status != C.UCS_OK {
 		return nil, newUcxError(status)
 	}
 
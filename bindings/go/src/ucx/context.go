@@ -1,3 +1,6 @@
+//go:build linux
+// +build linux
+
 /*
  * Copyright (c) NVIDIA CORPORATION & AFFILIATES, 2021. ALL RIGHTS RESERVED.
  * See file LICENSE for terms.
@@ -5,9 +8,17 @@
 
 package ucx
 
+// #include <stdio.h>
+// #include <stdlib.h>
 // #include <ucp/api/ucp.h>
 // #include <ucs/type/status.h>
 import "C"
+import (
+	"io"
+	"runtime"
+	"sync"
+	"unsafe"
+)
 
 // UCP application context (or just a context) is an opaque handle that holds a
 // UCP communication instance's global information. It represents a single UCP
@@ -24,35 +35,133 @@ import "C"
 // operation performed in one application context cannot be received in any
 // other application context.
 type UcpContext struct {
-	context C.ucp_context_h
+	context  C.ucp_context_h
+	features UcpFeatures
+	// Printed configuration, that the context is created with
+	config string
+	// Bits of the tag, that identify the sender
+	tagSenderMask uint64
+	// Workers and memory handles, that are released by Shutdown(). Memory is
+	// tracked by the handle, so the finalizer of UcpMemory can still run, along
+	// with the allocation of the binding, if any.
+	resourcesMu sync.Mutex
+	workers     map[*UcpWorker]struct{}
+	memories    map[C.ucp_mem_h]*memAllocation
+}
+
+type UcpContextAttributes struct {
+	// Size of UCP non-blocking request
+	RequestSize uint64
+	// Thread safe level of the context
+	ThreadMode UcsThreadMode
+	// Mask of the supported memory types, see IsMemTypeSupported()
+	MemoryTypes uint64
+	// Tracing and analysis tools can use name to identify this context
+	Name string
 }
 
 func NewUcpContext(contextParams *UcpParams) (*UcpContext, error) {
 	var ucp_context C.ucp_context_h
+	var config *C.ucp_config_t
+	var configText string
+
+	if err := contextParams.Validate(); err != nil {
+		return nil, err
+	}
 
-	if status := C.ucp_init(&contextParams.params, nil, &ucp_context); status != C.UCS_OK {
+	if contextParams.config != nil {
+		config = contextParams.config.config
+	}
+
+	if status := C.ucp_init(&contextParams.params, config, &ucp_context); status != C.UCS_OK {
 		return nil, newUcxError(status)
 	}
 
+	if config != nil {
+		// The config may be released right after the context is created
+		configText = contextParams.config.Print("UCP context configuration", UCS_CONFIG_PRINT_CONFIG)
+	}
+
+	trackResource("context", unsafe.Pointer(ucp_context))
 	ctx := &UcpContext{
-		context: ucp_context,
+		context:  ucp_context,
+		features: UcpFeatures(contextParams.params.features),
+		config:   configText,
+		workers:  make(map[*UcpWorker]struct{}),
+		memories: make(map[C.ucp_mem_h]*memAllocation),
+	}
+	if contextParams.params.field_mask&C.UCP_PARAM_FIELD_TAG_SENDER_MASK != 0 {
+		ctx.tagSenderMask = uint64(contextParams.params.tag_sender_mask)
 	}
 	return ctx, nil
 }
 
 func (c *UcpContext) Close() error {
+	if c.context == nil {
+		// Already closed by Shutdown()
+		return nil
+	}
 	C.ucp_cleanup(c.context)
+	untrackResource(unsafe.Pointer(c.context))
 	c.context = nil
 	return nil
 }
 
+// This routine writes the information about the context to w: the
+// configuration it's created with, e.g. the protocol thresholds, and the
+// resources it uses.
+func (c *UcpContext) PrintConfig(w io.Writer) error {
+	var buffer *C.char
+	var size C.size_t
+
+	config := c.config
+	if config == "" {
+		// Context without the config reads it from the environment
+		ucpConfig, err := NewUcpConfig("", "")
+		if err != nil {
+			return err
+		}
+		config = ucpConfig.Print("UCP context configuration", UCS_CONFIG_PRINT_CONFIG)
+		ucpConfig.Close()
+	}
+
+	if _, err := io.WriteString(w, config); err != nil {
+		return err
+	}
+
+	stream := C.open_memstream(&buffer, &size)
+	if stream == nil {
+		return ErrNoMemory
+	}
+
+	C.ucp_context_print_info(c.context, stream)
+	C.fclose(stream)
+	defer C.free(unsafe.Pointer(buffer))
+
+	_, err := io.WriteString(w, C.GoStringN(buffer, C.int(size)))
+	return err
+}
+
+// Returns the bits of the tag, that identify the sender, as set by
+// UcpParams.SetTagSenderMask().
+func (c *UcpContext) TagSenderMask() uint64 {
+	return c.tagSenderMask
+}
+
 // Mask which memory types are supported
 func (c *UcpContext) MemoryTypesMask() (uint64, error) {
 	ucp_attrs, err := c.Query(UCP_ATTR_FIELD_MEMORY_TYPES)
 	if err != nil {
 		return 0, err
 	}
-	return uint64(ucp_attrs.memory_types), nil
+	return ucp_attrs.MemoryTypes, nil
+}
+
+// Features, which the context was created with. Creation of the context fails,
+// if a requested feature is not supported, so the application can fall back
+// to other features (e.g. from Active Messages to tag matching) at runtime.
+func (c *UcpContext) Features() UcpFeatures {
+	return c.features
 }
 
 // Associates memory allocated/mapped region with communication operations
@@ -63,18 +172,126 @@ func (c *UcpContext) MemoryTypesMask() (uint64, error) {
 func (c *UcpContext) MemMap(memMapParams *UcpMmapParams) (*UcpMemory, error) {
 	var ucp_memh C.ucp_mem_h
 
-	if status := C.ucp_mem_map(c.context, &memMapParams.params, &ucp_memh); status != C.UCS_OK {
+	if err := memMapParams.Validate(); err != nil {
+		return nil, err
+	}
+
+	if memMapParams.exportedMemh != nil {
+		buffer := C.CBytes(memMapParams.exportedMemh)
+		defer C.free(buffer)
+		memMapParams.params.exported_memh_buffer = buffer
+		defer func() { memMapParams.params.exported_memh_buffer = nil }()
+	}
+
+	params := memMapParams.params
+	var allocation *memAllocation
+	if memMapParams.allocatedByBinding() {
+		var err error
+		allocation, err = allocateMemory(uint64(params.length), memMapParams.alignment, memMapParams.hugePages)
+		if err != nil {
+			return nil, err
+		}
+
+		params.flags &^= C.UCP_MEM_MAP_ALLOCATE
+		params.field_mask |= C.UCP_MEM_MAP_PARAM_FIELD_ADDRESS | C.UCP_MEM_MAP_PARAM_FIELD_MEMORY_TYPE
+		params.address = allocation.address
+		params.length = C.size_t(allocation.length)
+		params.memory_type = C.UCS_MEMORY_TYPE_HOST
+	}
+
+	if status := C.ucp_mem_map(c.context, &params, &ucp_memh); status != C.UCS_OK {
+		allocation.free()
 		return nil, newUcxError(status)
 	}
 
+	trackResource("memory", unsafe.Pointer(ucp_memh))
+	c.resourcesMu.Lock()
+	c.memories[ucp_memh] = allocation
+	c.resourcesMu.Unlock()
+
 	return &UcpMemory{
-		memHandle: ucp_memh,
-		context:   c.context,
+		memHandle:  ucp_memh,
+		context:    c.context,
+		owner:      c,
+		allocation: allocation,
 	}, nil
 }
 
+// This routine imports the memory handle, that was exported by
+// UcpMemory.Export() in another process on the same host, so the memory of
+// that process is accessed by the local operations, which are given the
+// returned memory by UcpRequestParams.SetMemory(). Both contexts have to be
+// created with UcpParams.EnableExportedMemh().
+func (c *UcpContext) MemImport(buffer []byte) (*UcpMemory, error) {
+	return c.MemMap((&UcpMmapParams{}).SetExportedMemh(buffer))
+}
+
+// This routine detects the type of memory, that is pointed by the address,
+// e.g. whether it is a host or a GPU memory. The memory type detection is not
+// a part of the UCP API, so the memory is mapped with unknown memory type to
+// let the library detect it. The detected type can be passed to
+// UcpRequestParams.SetMemType() and UcpMmapParams.SetMemoryType() to avoid
+// detection on every operation.
+func (c *UcpContext) DetectMemoryType(address unsafe.Pointer, length uint64) (UcsMemoryType, error) {
+	mmapParams := &UcpMmapParams{}
+	mmapParams.SetAddress(address).SetLength(length).SetMemoryType(UCS_MEMORY_TYPE_UNKNOWN)
+
+	memory, err := c.MemMap(mmapParams)
+	if err != nil {
+		return UCS_MEMORY_TYPE_UNKNOWN, err
+	}
+	defer memory.Close()
+
+	memAttrs, err := memory.Query(UCP_MEM_ATTR_FIELD_MEM_TYPE)
+	if err != nil {
+		return UCS_MEMORY_TYPE_UNKNOWN, err
+	}
+
+	return memAttrs.MemType, nil
+}
+
+// This routine allocates and maps (registers) the memory of the size, and
+// returns its memory handle along with the []byte view of the host memory,
+// so it can be filled without the copy. The view is nil for non-host memory
+// types. The parameters are optional, e.g. to set UcpMmapParams.Fixed()
+// address or memory type. Huge pages are allocated when "huge" or "thp"
+// precedes other methods in ALLOC_PRIO configuration (see UcpConfig.Modify()).
+// The view is valid until UcpMemory.Close() is called; if the memory becomes
+// unreachable without Close(), it's released by the garbage collector, so the
+// memory handle has to be kept alive while the view is used.
+func (c *UcpContext) AllocAndMap(size uint64, params *UcpMmapParams) (*UcpMemory, []byte, error) {
+	mmapParams := &UcpMmapParams{}
+	if params != nil {
+		*mmapParams = *params
+	}
+	mmapParams.SetLength(size).Allocate()
+
+	memory, err := c.MemMap(mmapParams)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	memAttrs, err := memory.Query(UCP_MEM_ATTR_FIELD_ADDRESS, UCP_MEM_ATTR_FIELD_LENGTH,
+		UCP_MEM_ATTR_FIELD_MEM_TYPE)
+	if err != nil {
+		memory.Close()
+		return nil, nil, err
+	}
+
+	if !isLeakCheckEnabled() {
+		runtime.SetFinalizer(memory, func(m *UcpMemory) { m.Close() })
+	}
+
+	if memAttrs.MemType != UCS_MEMORY_TYPE_HOST {
+		return memory, nil, nil
+	}
+
+	length := memAttrs.Length
+	return memory, (*[1 << 40]byte)(memAttrs.Address)[:length:length], nil
+}
+
 // This routine fetches information about the context.
-func (c *UcpContext) Query(attrs ...UcpContextAttr) (*C.ucp_context_attr_t, error) {
+func (c *UcpContext) Query(attrs ...UcpContextAttr) (*UcpContextAttributes, error) {
 	var ucp_attrs C.ucp_context_attr_t
 
 	for _, attr := range attrs {
@@ -85,18 +302,74 @@ func (c *UcpContext) Query(attrs ...UcpContextAttr) (*C.ucp_context_attr_t, erro
 		return nil, newUcxError(status)
 	}
 
-	return &ucp_attrs, nil
+	result := &UcpContextAttributes{}
+
+	for _, attr := range attrs {
+		switch attr {
+		case UCP_ATTR_FIELD_REQUEST_SIZE:
+			result.RequestSize = uint64(ucp_attrs.request_size)
+		case UCP_ATTR_FIELD_THREAD_MODE:
+			result.ThreadMode = UcsThreadMode(ucp_attrs.thread_mode)
+		case UCP_ATTR_FIELD_MEMORY_TYPES:
+			result.MemoryTypes = uint64(ucp_attrs.memory_types)
+		case UCP_ATTR_FIELD_NAME:
+			result.Name = C.GoString(&ucp_attrs.name[0])
+		}
+	}
+
+	return result, nil
 }
 
 // This routine creates new UcpWorker.
 func (c *UcpContext) NewWorker(workerParams *UcpWorkerParams) (*UcpWorker, error) {
 	var ucp_worker C.ucp_worker_h
 
+	if err := workerParams.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := workerParams.validateFeatures(c.features); err != nil {
+		return nil, err
+	}
+
 	if status := C.ucp_worker_create(c.context, &workerParams.params, &ucp_worker); status != C.UCS_OK {
 		return nil, newUcxError(status)
 	}
 
-	return &UcpWorker{
-		worker: ucp_worker,
-	}, nil
+	reserveRequestParams(workerParams.requestPoolSize)
+	trackResource("worker", unsafe.Pointer(ucp_worker))
+	setWorkerFeatures(ucp_worker, c.features)
+	if workerParams.transferStats {
+		addTransferStats(ucp_worker)
+	}
+
+	if workerParams.tracer != nil {
+		addTracer(ucp_worker, workerParams.tracer)
+	}
+
+	if workerParams.memoryCacheCapacity > 0 {
+		addWorkerMemoryCache(ucp_worker, c.NewMemoryCache(workerParams.memoryCacheCapacity))
+	}
+
+	worker := &UcpWorker{
+		worker:     ucp_worker,
+		amHandlers: make(map[uint]uint64),
+		context:    c,
+		listeners:  make(map[*UcpListener]struct{}),
+		cpus:       append([]int(nil), workerParams.cpus...),
+		reserved:   workerParams.requestPoolSize,
+	}
+
+	c.resourcesMu.Lock()
+	c.workers[worker] = struct{}{}
+	c.resourcesMu.Unlock()
+
+	attrs, err := worker.Query(UCP_WORKER_ATTR_FIELD_THREAD_MODE)
+	if err != nil {
+		worker.Close()
+		return nil, err
+	}
+	worker.threadMode = attrs.ThreadMode
+
+	return worker, nil
 }
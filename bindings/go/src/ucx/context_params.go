@@ -1,3 +1,6 @@
+//go:build linux
+// +build linux
+
 /*
  * Copyright (c) NVIDIA CORPORATION & AFFILIATES, 2021. ALL RIGHTS RESERVED.
  * See file LICENSE for terms.
@@ -23,6 +26,7 @@ import (
 // send/receive queues.
 type UcpParams struct {
 	params C.ucp_params_t
+	config *UcpConfig
 }
 
 // Mask which specifies particular bits of the tag which can uniquely
@@ -83,6 +87,17 @@ func (p *UcpParams) EnableSharedWorkers() *UcpParams {
 	return p
 }
 
+// Requests the features of the context at once, e.g.
+// SetFeatures(UCP_FEATURE_TAG | UCP_FEATURE_AM | UCP_FEATURE_WAKEUP). It
+// replaces the features requested before, unlike the Enable*() routines, that
+// add a single feature. The operations of the features, that are not
+// requested, fail with UcpFeatureError.
+func (p *UcpParams) SetFeatures(features UcpFeatures) *UcpParams {
+	p.params.features = C.uint64_t(features)
+	p.params.field_mask |= C.UCP_PARAM_FIELD_FEATURES
+	return p
+}
+
 // Request tag matching support.
 func (p *UcpParams) EnableTag() *UcpParams {
 	p.params.features |= C.UCP_FEATURE_TAG
@@ -125,9 +140,25 @@ func (p *UcpParams) EnableStream() *UcpParams {
 	return p
 }
 
+// Request support of the memory handles exported by UcpMemory.Export() and
+// imported by UcpContext.MemImport(). Both the exporting and the importing
+// contexts have to enable this feature.
+func (p *UcpParams) EnableExportedMemh() *UcpParams {
+	p.params.features |= C.UCP_FEATURE_EXPORTED_MEMH
+	p.params.field_mask |= C.UCP_PARAM_FIELD_FEATURES
+	return p
+}
+
 // Request Active Message support feature.
 func (p *UcpParams) EnableAM() *UcpParams {
 	p.params.features |= C.UCP_FEATURE_AM
 	p.params.field_mask |= C.UCP_PARAM_FIELD_FEATURES
 	return p
 }
+
+// Configuration to create the context with, instead of the one read from
+// the environment variables.
+func (p *UcpParams) SetConfig(config *UcpConfig) *UcpParams {
+	p.config = config
+	return p
+}
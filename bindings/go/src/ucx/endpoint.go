@@ -1,3 +1,6 @@
+//go:build linux
+// +build linux
+
 /*
  * Copyright (C) 2021, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
  * See file LICENSE for terms.
@@ -5,67 +8,217 @@
 
 package ucx
 
+// #include <stdio.h>
+// #include <stdlib.h>
 // #include <ucp/api/ucp.h>
 // #include "goucx.h"
+// static inline ucp_datatype_t ucxgo_dt_make_contig(size_t elem_size) {
+//     return ucp_dt_make_contig(elem_size);
+// }
 import "C"
 import (
+	"net"
+	"sync"
 	"unsafe"
 )
 
+// Maximal number of transports entries, that are returned by UcpEp.Query().
+const maxEpTransports = 32
+
 type UcpEp struct {
-	ep C.ucp_ep_h
+	ep     C.ucp_ep_h
+	worker C.ucp_worker_h
+}
+
+// Transport and device, that are used by the endpoint.
+type UcpTransportEntry struct {
+	TransportName string
+	DeviceName    string
+}
+
+type UcpEpAttributes struct {
+	Name           string
+	LocalSockAddr  *net.TCPAddr
+	RemoteSockAddr *net.TCPAddr
+	Transports     []UcpTransportEntry
 }
 
+// Error handlers are invoked from the worker progress, so the map is accessed
+// concurrently with endpoints creation and closing.
 var errorHandles = make(map[C.ucp_ep_h]UcpEpErrHandler)
+var errorHandlesMu sync.RWMutex
 
-func setSendParams(goRequestParams *UcpRequestParams, cRequestParams *C.ucp_request_param_t) uint64 {
+func setErrorHandler(ep C.ucp_ep_h, errHandler UcpEpErrHandler) {
+	errorHandlesMu.Lock()
+	defer errorHandlesMu.Unlock()
+	errorHandles[ep] = errHandler
+}
+
+func getErrorHandler(ep C.ucp_ep_h) (UcpEpErrHandler, bool) {
+	errorHandlesMu.RLock()
+	defer errorHandlesMu.RUnlock()
+	errHandler, found := errorHandles[ep]
+	return errHandler, found
+}
+
+func removeErrorHandler(ep C.ucp_ep_h) {
+	errorHandlesMu.Lock()
+	defer errorHandlesMu.Unlock()
+	delete(errorHandles, ep)
+}
+
+type endpointEntry struct {
+	worker   C.ucp_worker_h
+	userData interface{}
+}
+
+// Endpoints, that are not closed yet, to their workers. The endpoints are
+// closed by UcpContext.Shutdown(), so the ones closed after that are skipped.
+// The callbacks create new UcpEp objects of the same endpoint, so its user data
+// is kept here as well.
+var endpoints = make(map[C.ucp_ep_h]*endpointEntry)
+var endpointsMu sync.Mutex
+
+func addEndpoint(ep C.ucp_ep_h, worker C.ucp_worker_h, userData interface{}) {
+	trackResource("endpoint", unsafe.Pointer(ep))
+	endpointsMu.Lock()
+	defer endpointsMu.Unlock()
+	endpoints[ep] = &endpointEntry{worker: worker, userData: userData}
+}
+
+// Returns false, if the endpoint is already closed.
+func removeEndpoint(ep C.ucp_ep_h) bool {
+	endpointsMu.Lock()
+	defer endpointsMu.Unlock()
+	_, found := endpoints[ep]
+	delete(endpoints, ep)
+	untrackResource(unsafe.Pointer(ep))
+	return found
+}
+
+func getWorkerEndpoints(worker C.ucp_worker_h) []*UcpEp {
+	endpointsMu.Lock()
+	defer endpointsMu.Unlock()
+	var result []*UcpEp
+	for ep, entry := range endpoints {
+		if entry.worker == worker {
+			result = append(result, &UcpEp{ep: ep, worker: worker})
+		}
+	}
+	return result
+}
+
+// Endpoints are destroyed along with the worker.
+func removeWorkerEndpoints(worker C.ucp_worker_h) {
+	endpointsMu.Lock()
+	defer endpointsMu.Unlock()
+	for ep, entry := range endpoints {
+		if entry.worker == worker {
+			delete(endpoints, ep)
+			removeErrorHandler(ep)
+			untrackResource(unsafe.Pointer(ep))
+		}
+	}
+}
+
+func setSendParams(goRequestParams *UcpRequestParams, cRequestParams *C.ucp_request_param_t) (uint64, chan UcsStatus) {
 	var cbId uint64
+	var done chan UcsStatus
 	if goRequestParams != nil {
-		if goRequestParams.Cb != nil {
-			cbId = register(goRequestParams.Cb)
+		cb := goRequestParams.Cb
+		if goRequestParams.doneChannel {
+			done = make(chan UcsStatus, 1)
+			userCb, _ := cb.(UcpSendCallback)
+			cb = UcpSendCallback(func(request *UcpRequest, status UcsStatus) {
+				if userCb != nil {
+					userCb(request, status)
+				}
+				done <- status
+			})
+		}
+
+		if (cb != nil) || (goRequestParams.release != nil) || !goRequestParams.deadline.IsZero() ||
+			(goRequestParams.transfer != nil) || (goRequestParams.userData != nil) {
+			cbId = registerRequest(cb, goRequestParams.release, goRequestParams.deadline,
+				goRequestParams.transfer, goRequestParams.userData)
 			cRequestParams.op_attr_mask |= C.UCP_OP_ATTR_FIELD_CALLBACK | C.UCP_OP_ATTR_FIELD_USER_DATA
 			cbAddr := (*C.ucp_send_nbx_callback_t)(unsafe.Pointer(&cRequestParams.cb[0]))
-			*cbAddr = (C.ucp_send_nbx_callback_t)(C.ucxgo_completeGoSendRequest)
-			cRequestParams.user_data = unsafe.Pointer(uintptr(cbId))
+			*cbAddr = (C.ucp_send_nbx_callback_t)(C.ucxgo_send_cb)
+			C.ucxgo_request_param_set_user_data(cRequestParams, C.uint64_t(cbId))
 		}
 
-		setMemType(goRequestParams, cRequestParams)
+		setCommonParams(goRequestParams, cRequestParams)
 	}
 
-	return cbId
+	return cbId, done
 }
 
 // This routine flushes all outstanding AMO and RMA communications on the endpoint.
 // All the AMO and RMA operations issued on the ep prior to this call are completed
 // both at the origin and at the target endpoint when this call returns.
 func (e *UcpEp) FlushNonBlocking(params *UcpRequestParams) (*UcpRequest, error) {
-	var requestParams C.ucp_request_param_t
-	var cbId uint64
+	requestParams := getRequestParams()
+	defer putRequestParams(requestParams)
 
-	setSendParams(params, &requestParams)
+	params = withTransfer(params, e.worker, e.ep, UcpTraceEvent{Op: UcpTraceFlush})
+	cbId, done := setSendParams(params, requestParams)
 
-	request := C.ucp_ep_flush_nbx(e.ep, &requestParams)
-	return NewRequest(request, cbId, nil)
+	request := C.ucp_ep_flush_nbx(e.ep, requestParams)
+	return NewRequest(request, e.worker, cbId, done, nil)
 }
 
-func (e *UcpEp) CloseNonBlocking(mode C.uint, params *UcpRequestParams) (*UcpRequest, error) {
-	var requestParams C.ucp_request_param_t
-	var cbId uint64
+// Attaches an application value to the endpoint, e.g. the state of the
+// connection. The value is returned by UcpEp.UserData() of all the UcpEp
+// objects of this endpoint, including the ones passed to the error handler and
+// the reply endpoint of the Active Message callback, until the endpoint is
+// closed.
+func (e *UcpEp) SetUserData(userData interface{}) {
+	endpointsMu.Lock()
+	defer endpointsMu.Unlock()
+	if entry, found := endpoints[e.ep]; found {
+		entry.userData = userData
+	}
+}
+
+// Returns the value set by UcpEp.SetUserData() or UcpEpParams.SetUserData(),
+// or nil if it's not set or the endpoint is closed.
+func (e *UcpEp) UserData() interface{} {
+	endpointsMu.Lock()
+	defer endpointsMu.Unlock()
+	if entry, found := endpoints[e.ep]; found {
+		return entry.userData
+	}
+	return nil
+}
+
+// Non-blocking endpoint closure. The closure is graceful, unless
+// UCP_EP_CLOSE_FLAG_FORCE is set in flags: outstanding operations are flushed
+// and the peer is notified. The endpoint is released once the returned request
+// completes, so the caller has to progress the worker until then.
+func (e *UcpEp) CloseNonBlocking(flags UcpEpCloseFlags, params *UcpRequestParams) (*UcpRequest, error) {
+	requestParams := getRequestParams()
+	defer putRequestParams(requestParams)
 	requestParams.op_attr_mask = C.UCP_OP_ATTR_FIELD_FLAGS
-	requestParams.flags = mode
+	requestParams.flags = C.uint32_t(flags)
+
+	cbId, done := setSendParams(params, requestParams)
 
-	setSendParams(params, &requestParams)
+	if !removeEndpoint(e.ep) {
+		// Already closed by UcpContext.Shutdown()
+		return NewRequest(nil, e.worker, cbId, done, nil)
+	}
 
-	request := C.ucp_ep_close_nbx(e.ep, &requestParams)
-	delete(errorHandles, e.ep)
-	return NewRequest(request, cbId, nil)
+	request := C.ucp_ep_close_nbx(e.ep, requestParams)
+	removeErrorHandler(e.ep)
+	removeEpTransferStats(e.worker, e.ep)
+	return NewRequest(request, e.worker, cbId, done, nil)
 }
 
 // Non-blocking endpoint closure. Releases the endpoint without any
 // confirmation from the peer. All outstanding requests will be
 // completed with UCS_ERR_CANCELED error.
 func (e *UcpEp) CloseNonBlockingForce(params *UcpRequestParams) (*UcpRequest, error) {
-	return e.CloseNonBlocking(C.UCP_EP_CLOSE_FLAG_FORCE, params)
+	return e.CloseNonBlocking(UCP_EP_CLOSE_FLAG_FORCE, params)
 }
 
 // Non-blocking endpoint close. Schedules flushes on all outstanding operations.
@@ -73,6 +226,71 @@ func (e *UcpEp) CloseNonBlockingFlush(params *UcpRequestParams) (*UcpRequest, er
 	return e.CloseNonBlocking(0, params)
 }
 
+// This routine fetches information about the endpoint. Socket addresses are
+// available only for endpoints, that were created by the socket address or
+// from the connection request.
+func (e *UcpEp) Query(attrs ...UcpEpAttribute) (*UcpEpAttributes, error) {
+	var epAttr C.ucp_ep_attr_t
+
+	for _, attr := range attrs {
+		epAttr.field_mask |= C.uint64_t(attr)
+	}
+
+	if (epAttr.field_mask & C.UCP_EP_ATTR_FIELD_TRANSPORTS) != 0 {
+		entries := AllocateNativeMemory(maxEpTransports * C.sizeof_ucp_transport_entry_t)
+		defer FreeNativeMemory(entries)
+		epAttr.transports.entries = (*C.ucp_transport_entry_t)(entries)
+		epAttr.transports.num_entries = maxEpTransports
+		epAttr.transports.entry_size = C.sizeof_ucp_transport_entry_t
+	}
+
+	if status := C.ucp_ep_query(e.ep, &epAttr); status != C.UCS_OK {
+		return nil, newUcxError(status)
+	}
+
+	result := &UcpEpAttributes{}
+
+	for _, attr := range attrs {
+		switch attr {
+		case UCP_EP_ATTR_FIELD_NAME:
+			result.Name = C.GoString(&epAttr.name[0])
+		case UCP_EP_ATTR_FIELD_LOCAL_SOCKADDR:
+			result.LocalSockAddr = toTcpAddr(&epAttr.local_sockaddr)
+		case UCP_EP_ATTR_FIELD_REMOTE_SOCKADDR:
+			result.RemoteSockAddr = toTcpAddr(&epAttr.remote_sockaddr)
+		case UCP_EP_ATTR_FIELD_TRANSPORTS:
+			numEntries := int(epAttr.transports.num_entries)
+			entries := (*[1 << 16]C.ucp_transport_entry_t)(unsafe.Pointer(epAttr.transports.entries))[:numEntries:numEntries]
+			result.Transports = make([]UcpTransportEntry, numEntries)
+			for i, entry := range entries {
+				result.Transports[i] = UcpTransportEntry{
+					TransportName: C.GoString(entry.transport_name),
+					DeviceName:    C.GoString(entry.device_name),
+				}
+			}
+		}
+	}
+	return result, nil
+}
+
+// This routine returns the information about the endpoint configuration:
+// the lanes, transports and protocols selected for it.
+func (e *UcpEp) PrintInfo() string {
+	var buffer *C.char
+	var size C.size_t
+
+	stream := C.open_memstream(&buffer, &size)
+	if stream == nil {
+		return ""
+	}
+
+	C.ucp_ep_print_info(e.ep, stream)
+	C.fclose(stream)
+	defer C.free(unsafe.Pointer(buffer))
+
+	return C.GoStringN(buffer, C.int(size))
+}
+
 // This routine sends a messages that is described by the local address and size
 // to the destination endpoint. Each message is associated with a  tag value that is used for message
 // matching on the UcpWorker.RecvTagNonBlocking "receiver".
@@ -81,13 +299,45 @@ func (e *UcpEp) CloseNonBlockingFlush(params *UcpRequestParams) (*UcpRequest, er
 // completed when it is safe to reuse the source buffer.
 func (e *UcpEp) SendTagNonBlocking(tag uint64, address unsafe.Pointer, size uint64,
 	params *UcpRequestParams) (*UcpRequest, error) {
-	var requestParams C.ucp_request_param_t
-	var cbId uint64
+	if request, err := checkRequestFeature(e.worker, UCP_FEATURE_TAG, params); err != nil {
+		return request, err
+	}
+
+	requestParams := getRequestParams()
+	defer putRequestParams(requestParams)
+
+	params = setCachedMemory(params, address, size, requestParams)
+	params = withTransfer(params, e.worker, e.ep, UcpTraceEvent{Op: UcpTraceTagSend, Tag: tag, Size: size})
+	cbId, done := setSendParams(params, requestParams)
 
-	setSendParams(params, &requestParams)
+	request := postWithRetry(params, e.worker, func() C.ucs_status_ptr_t {
+		return C.ucp_tag_send_nbx(e.ep, address, C.size_t(size), C.ucp_tag_t(tag), requestParams)
+	})
+	return NewRequest(request, e.worker, cbId, done, nil)
+}
+
+// This routine sends a message, that is gathered from the buffers of the io
+// vector, to the destination endpoint. It's the same as
+// UcpEp.SendTagNonBlocking(), but avoids copying scattered application buffers
+// (e.g. header and payload) to a single buffer.
+func (e *UcpEp) SendTagIovNonBlocking(tag uint64, iov []UcpIov, params *UcpRequestParams) (*UcpRequest, error) {
+	if request, err := checkRequestFeature(e.worker, UCP_FEATURE_TAG, params); err != nil {
+		return request, err
+	}
+
+	requestParams := getRequestParams()
+	defer putRequestParams(requestParams)
+
+	cIov := setIovParams(iov, requestParams)
+	params = withRelease(params, func() { C.free(cIov) })
+	params = withTransfer(params, e.worker, e.ep, UcpTraceEvent{Op: UcpTraceTagSend, Tag: tag,
+		Size: iovLength(iov)})
+	cbId, done := setSendParams(params, requestParams)
 
-	request := C.ucp_tag_send_nbx(e.ep, address, C.size_t(size), C.ucp_tag_t(tag), &requestParams)
-	return NewRequest(request, cbId, nil)
+	request := postWithRetry(params, e.worker, func() C.ucs_status_ptr_t {
+		return C.ucp_tag_send_nbx(e.ep, cIov, C.size_t(len(iov)), C.ucp_tag_t(tag), requestParams)
+	})
+	return NewRequest(request, e.worker, cbId, done, nil)
 }
 
 // This routine sends an Active Message to an ep.
@@ -95,14 +345,341 @@ func (e *UcpEp) SendTagNonBlocking(tag uint64, address unsafe.Pointer, size uint
 // The maximum allowed header size can be obtained by querying worker attributes by the UcpWorker.Query() routine.
 func (e *UcpEp) SendAmNonBlocking(id uint, header unsafe.Pointer, headerSize uint64,
 	data unsafe.Pointer, dataSize uint64, flags UcpAmSendFlags, params *UcpRequestParams) (*UcpRequest, error) {
-	var requestParams C.ucp_request_param_t
-	var cbId uint64
+	if request, err := checkRequestFeature(e.worker, UCP_FEATURE_AM, params); err != nil {
+		return request, err
+	}
+
+	requestParams := getRequestParams()
+	defer putRequestParams(requestParams)
+
+	params = setCachedMemory(params, data, dataSize, requestParams)
+	params = withTransfer(params, e.worker, e.ep, UcpTraceEvent{Op: UcpTraceAmSend, AmId: id,
+		Size: headerSize + dataSize})
+	cbId, done := setSendParams(params, requestParams)
+
+	requestParams.op_attr_mask |= C.UCP_OP_ATTR_FIELD_FLAGS
+	requestParams.flags = C.uint(flags)
+
+	request := postWithRetry(params, e.worker, func() C.ucs_status_ptr_t {
+		return C.ucp_am_send_nbx(e.ep, C.uint(id), header, C.size_t(headerSize), data, C.size_t(dataSize), requestParams)
+	})
+	return NewRequest(request, e.worker, cbId, done, nil)
+}
+
+// This routine sends an Active Message with the data, that is gathered from the
+// buffers of the io vector. It's the same as UcpEp.SendAmNonBlocking() otherwise.
+func (e *UcpEp) SendAmIovNonBlocking(id uint, header unsafe.Pointer, headerSize uint64,
+	iov []UcpIov, flags UcpAmSendFlags, params *UcpRequestParams) (*UcpRequest, error) {
+	if request, err := checkRequestFeature(e.worker, UCP_FEATURE_AM, params); err != nil {
+		return request, err
+	}
+
+	requestParams := getRequestParams()
+	defer putRequestParams(requestParams)
 
-	setSendParams(params, &requestParams)
+	cIov := setIovParams(iov, requestParams)
+	params = withRelease(params, func() { C.free(cIov) })
+	params = withTransfer(params, e.worker, e.ep, UcpTraceEvent{Op: UcpTraceAmSend, AmId: id,
+		Size: headerSize + iovLength(iov)})
+	cbId, done := setSendParams(params, requestParams)
 
 	requestParams.op_attr_mask |= C.UCP_OP_ATTR_FIELD_FLAGS
 	requestParams.flags = C.uint(flags)
 
-	request := C.ucp_am_send_nbx(e.ep, C.uint(id), header, C.size_t(headerSize), data, C.size_t(dataSize), &requestParams)
-	return NewRequest(request, cbId, nil)
+	request := postWithRetry(params, e.worker, func() C.ucs_status_ptr_t {
+		return C.ucp_am_send_nbx(e.ep, C.uint(id), header, C.size_t(headerSize), cIov, C.size_t(len(iov)), requestParams)
+	})
+	return NewRequest(request, e.worker, cbId, done, nil)
+}
+
+// This routine unpacks the remote key (RKEY) that was packed on the peer side
+// by UcpMemory.RkeyPack(). The resulting UcpRkey can be used for RMA
+// operations only on this endpoint and must be closed before the endpoint.
+func (e *UcpEp) UnpackRkey(rkeyBuffer []byte) (*UcpRkey, error) {
+	var rkey C.ucp_rkey_h
+
+	buffer := C.CBytes(rkeyBuffer)
+	defer C.free(buffer)
+
+	if status := C.ucp_ep_rkey_unpack(e.ep, buffer, &rkey); status != C.UCS_OK {
+		return nil, newUcxError(status)
+	}
+
+	return &UcpRkey{
+		rkey: rkey,
+	}, nil
+}
+
+// This routine initiates a storage of contiguous block of data that is
+// described by the local address and size in the remote contiguous memory
+// region described by remoteAddr address and the rkey "memory handle".
+// The routine returns immediately and does not guarantee re-usability of the
+// source address. The operation is considered completed, when the callback is
+// invoked or the returned request is completed. It does not guarantee the
+// remote completion, which can be achieved by UcpEp.FlushNonBlocking().
+func (e *UcpEp) RmaPutNonBlocking(address unsafe.Pointer, size uint64, remoteAddr uint64,
+	rkey *UcpRkey, params *UcpRequestParams) (*UcpRequest, error) {
+	if request, err := checkRequestFeature(e.worker, UCP_FEATURE_RMA, params); err != nil {
+		return request, err
+	}
+
+	requestParams := getRequestParams()
+	defer putRequestParams(requestParams)
+
+	params = setCachedMemory(params, address, size, requestParams)
+	params = withTransfer(params, e.worker, e.ep, UcpTraceEvent{Op: UcpTraceRmaPut, RemoteAddr: remoteAddr,
+		Size: size})
+	cbId, done := setSendParams(params, requestParams)
+
+	request := postWithRetry(params, e.worker, func() C.ucs_status_ptr_t {
+		return C.ucp_put_nbx(e.ep, address, C.size_t(size), C.uint64_t(remoteAddr), rkey.rkey, requestParams)
+	})
+	return NewRequest(request, e.worker, cbId, done, nil)
+}
+
+// This routine initiates a load of a contiguous block of data that is
+// described by the remote memory address remoteAddr and the rkey "memory
+// handle". The routine returns immediately and does not guarantee that remote
+// data is loaded and stored under the local address. The operation is
+// considered completed, when the callback is invoked or the returned request
+// is completed.
+func (e *UcpEp) RmaGetNonBlocking(address unsafe.Pointer, size uint64, remoteAddr uint64,
+	rkey *UcpRkey, params *UcpRequestParams) (*UcpRequest, error) {
+	if request, err := checkRequestFeature(e.worker, UCP_FEATURE_RMA, params); err != nil {
+		return request, err
+	}
+
+	requestParams := getRequestParams()
+	defer putRequestParams(requestParams)
+
+	params = setCachedMemory(params, address, size, requestParams)
+	params = withTransfer(params, e.worker, e.ep, UcpTraceEvent{Op: UcpTraceRmaGet, RemoteAddr: remoteAddr,
+		Size: size})
+	cbId, done := setSendParams(params, requestParams)
+
+	request := postWithRetry(params, e.worker, func() C.ucs_status_ptr_t {
+		return C.ucp_get_nbx(e.ep, address, C.size_t(size), C.uint64_t(remoteAddr), rkey.rkey, requestParams)
+	})
+	return NewRequest(request, e.worker, cbId, done, nil)
+}
+
+// This routine posts an atomic memory operation to the remote memory described
+// by remoteAddr address and the rkey "memory handle". The operand is read from
+// the local buffer of opSize bytes, only 4 and 8 bytes operands are supported.
+// In order to enable fetching semantics, reply buffer of the same size has to
+// be set with UcpRequestParams.SetReplyBuffer(). The buffer and the reply
+// buffer must not be modified until the operation completes. Fails with
+// ErrInvalidParam for the other operand sizes.
+func (e *UcpEp) AtomicNonBlocking(op UcpAtomicOp, buffer unsafe.Pointer, opSize uint64, remoteAddr uint64,
+	rkey *UcpRkey, params *UcpRequestParams) (*UcpRequest, error) {
+	if (opSize != 4) && (opSize != 8) {
+		return failRequest(e.worker, params, UCS_ERR_INVALID_PARAM, ErrInvalidParam)
+	}
+
+	if request, err := checkRequestFeature(e.worker, atomicFeature(opSize), params); err != nil {
+		return request, err
+	}
+
+	requestParams := getRequestParams()
+	defer putRequestParams(requestParams)
+
+	params = withTransfer(params, e.worker, e.ep, UcpTraceEvent{Op: UcpTraceAtomic, RemoteAddr: remoteAddr,
+		Size: opSize})
+	cbId, done := setSendParams(params, requestParams)
+
+	requestParams.op_attr_mask |= C.UCP_OP_ATTR_FIELD_DATATYPE
+	requestParams.datatype = C.ucxgo_dt_make_contig(C.size_t(opSize))
+
+	if (params != nil) && (params.replyBuffer != nil) {
+		requestParams.op_attr_mask |= C.UCP_OP_ATTR_FIELD_REPLY_BUFFER
+		requestParams.reply_buffer = params.replyBuffer
+	}
+
+	request := postWithRetry(params, e.worker, func() C.ucs_status_ptr_t {
+		return C.ucp_atomic_op_nbx(e.ep, C.ucp_atomic_op_t(op), buffer, 1, C.uint64_t(remoteAddr),
+			rkey.rkey, requestParams)
+	})
+	return NewRequest(request, e.worker, cbId, done, nil)
+}
+
+// This routine posts an atomic memory operation of the 32-bit value, see
+// UcpEp.AtomicNonBlocking(). The operand and the reply are kept in the native
+// memory by the bindings. Unless result is nil, the operation fetches the
+// previous remote value to *result, once it completes. For UCP_ATOMIC_OP_CSWAP
+// *result also holds the value to be swapped. result must not be accessed
+// until the operation completes.
+func (e *UcpEp) Atomic32(op UcpAtomicOp, value uint32, remoteAddr uint64, rkey *UcpRkey, result *uint32,
+	params *UcpRequestParams) (*UcpRequest, error) {
+	operands := (*[2]uint32)(C.calloc(2, C.size_t(unsafe.Sizeof(value))))
+	operands[0] = value
+
+	var fetch func()
+	if result != nil {
+		operands[1] = *result
+		fetch = func() { *result = operands[1] }
+	}
+	return e.atomicOperands(op, unsafe.Pointer(operands), uint64(unsafe.Sizeof(value)), remoteAddr, rkey,
+		fetch, params)
+}
+
+// This routine posts an atomic memory operation of the 64-bit value, it's the
+// same as UcpEp.Atomic32() otherwise.
+func (e *UcpEp) Atomic64(op UcpAtomicOp, value uint64, remoteAddr uint64, rkey *UcpRkey, result *uint64,
+	params *UcpRequestParams) (*UcpRequest, error) {
+	operands := (*[2]uint64)(C.calloc(2, C.size_t(unsafe.Sizeof(value))))
+	operands[0] = value
+
+	var fetch func()
+	if result != nil {
+		operands[1] = *result
+		fetch = func() { *result = operands[1] }
+	}
+	return e.atomicOperands(op, unsafe.Pointer(operands), uint64(unsafe.Sizeof(value)), remoteAddr, rkey,
+		fetch, params)
+}
+
+// Posts the atomic operation of the native operand, that is followed by the
+// reply of the same size. fetch copies the reply out, unless it's nil, before
+// the operands are freed on completion.
+func (e *UcpEp) atomicOperands(op UcpAtomicOp, operands unsafe.Pointer, opSize uint64, remoteAddr uint64,
+	rkey *UcpRkey, fetch func(), params *UcpRequestParams) (*UcpRequest, error) {
+	params = withRelease(params, func() {
+		if fetch != nil {
+			fetch()
+		}
+		C.free(operands)
+	})
+
+	params.replyBuffer = nil
+	if fetch != nil {
+		params.replyBuffer = unsafe.Pointer(uintptr(operands) + uintptr(opSize))
+	}
+	return e.AtomicNonBlocking(op, operands, opSize, remoteAddr, rkey, params)
+}
+
+// This routine sends data that is described by the local address and size to
+// the destination endpoint. The routine is non-blocking and therefore returns
+// immediately, however the actual send operation may be delayed. The send
+// operation is considered completed when it is safe to reuse the source buffer.
+// Stream does not preserve message boundaries, so the data may be received
+// with several UcpEp.RecvStreamNonBlocking() calls on the remote side.
+func (e *UcpEp) SendStreamNonBlocking(address unsafe.Pointer, size uint64,
+	params *UcpRequestParams) (*UcpRequest, error) {
+	if request, err := checkRequestFeature(e.worker, UCP_FEATURE_STREAM, params); err != nil {
+		return request, err
+	}
+
+	requestParams := getRequestParams()
+	defer putRequestParams(requestParams)
+
+	params = setCachedMemory(params, address, size, requestParams)
+	params = withTransfer(params, e.worker, e.ep, UcpTraceEvent{Op: UcpTraceStreamSend, Size: size})
+	cbId, done := setSendParams(params, requestParams)
+
+	request := postWithRetry(params, e.worker, func() C.ucs_status_ptr_t {
+		return C.ucp_stream_send_nbx(e.ep, address, C.size_t(size), requestParams)
+	})
+	return NewRequest(request, e.worker, cbId, done, nil)
+}
+
+func setStreamRecvParams(goRequestParams *UcpRequestParams, cRequestParams *C.ucp_request_param_t) (uint64, chan UcsStatus) {
+	var cbId uint64
+	var done chan UcsStatus
+	if goRequestParams != nil {
+		setCommonParams(goRequestParams, cRequestParams)
+
+		if goRequestParams.recvFlags != 0 {
+			cRequestParams.op_attr_mask |= C.UCP_OP_ATTR_FIELD_FLAGS
+			cRequestParams.flags = C.uint32_t(goRequestParams.recvFlags)
+		}
+
+		cb := goRequestParams.Cb
+		if goRequestParams.doneChannel {
+			done = make(chan UcsStatus, 1)
+			userCb, _ := cb.(UcpStreamRecvCallback)
+			cb = UcpStreamRecvCallback(func(request *UcpRequest, status UcsStatus, length uint64) {
+				if userCb != nil {
+					userCb(request, status, length)
+				}
+				done <- status
+			})
+		}
+
+		if (cb != nil) || (goRequestParams.release != nil) || !goRequestParams.deadline.IsZero() ||
+			(goRequestParams.transfer != nil) || (goRequestParams.userData != nil) {
+			cbId = registerRequest(cb, goRequestParams.release, goRequestParams.deadline,
+				goRequestParams.transfer, goRequestParams.userData)
+			cRequestParams.op_attr_mask |= C.UCP_OP_ATTR_FIELD_CALLBACK | C.UCP_OP_ATTR_FIELD_USER_DATA
+			cbAddr := (*C.ucp_stream_recv_nbx_callback_t)(unsafe.Pointer(&cRequestParams.cb[0]))
+			*cbAddr = (C.ucp_stream_recv_nbx_callback_t)(C.ucxgo_stream_recv_cb)
+			C.ucxgo_request_param_set_user_data(cRequestParams, C.uint64_t(cbId))
+		}
+	}
+
+	return cbId, done
+}
+
+// This routine receives data that is described by the local address and size
+// on the endpoint. The routine is non-blocking and therefore returns
+// immediately. The receive operation is considered completed when some data,
+// up to size bytes, is delivered to the buffer. The amount of received data is
+// passed to UcpStreamRecvCallback.
+func (e *UcpEp) RecvStreamNonBlocking(address unsafe.Pointer, size uint64,
+	params *UcpRequestParams) (*UcpRequest, error) {
+	if request, err := checkRequestFeature(e.worker, UCP_FEATURE_STREAM, params); err != nil {
+		return request, err
+	}
+
+	requestParams := getRequestParams()
+	defer putRequestParams(requestParams)
+	var length C.size_t
+
+	params = setCachedMemory(params, address, size, requestParams)
+	params = withTransfer(params, e.worker, e.ep, UcpTraceEvent{Op: UcpTraceStreamRecv, Size: size})
+	cbId, done := setStreamRecvParams(params, requestParams)
+
+	request := C.ucp_stream_recv_nbx(e.ep, address, C.size_t(size), &length, requestParams)
+	return NewRequest(request, e.worker, cbId, done, length)
+}
+
+// This routine sends data, that is gathered from the buffers of the io vector,
+// to the destination endpoint. It's the same as UcpEp.SendStreamNonBlocking()
+// otherwise.
+func (e *UcpEp) SendStreamIovNonBlocking(iov []UcpIov, params *UcpRequestParams) (*UcpRequest, error) {
+	if request, err := checkRequestFeature(e.worker, UCP_FEATURE_STREAM, params); err != nil {
+		return request, err
+	}
+
+	requestParams := getRequestParams()
+	defer putRequestParams(requestParams)
+
+	cIov := setIovParams(iov, requestParams)
+	params = withRelease(params, func() { C.free(cIov) })
+	params = withTransfer(params, e.worker, e.ep, UcpTraceEvent{Op: UcpTraceStreamSend, Size: iovLength(iov)})
+	cbId, done := setSendParams(params, requestParams)
+
+	request := postWithRetry(params, e.worker, func() C.ucs_status_ptr_t {
+		return C.ucp_stream_send_nbx(e.ep, cIov, C.size_t(len(iov)), requestParams)
+	})
+	return NewRequest(request, e.worker, cbId, done, nil)
+}
+
+// This routine receives data to the buffers of the io vector, which are filled
+// one after another. It's the same as UcpEp.RecvStreamNonBlocking() otherwise.
+func (e *UcpEp) RecvStreamIovNonBlocking(iov []UcpIov, params *UcpRequestParams) (*UcpRequest, error) {
+	if request, err := checkRequestFeature(e.worker, UCP_FEATURE_STREAM, params); err != nil {
+		return request, err
+	}
+
+	requestParams := getRequestParams()
+	defer putRequestParams(requestParams)
+	var length C.size_t
+
+	cIov := setIovParams(iov, requestParams)
+	params = withRelease(params, func() { C.free(cIov) })
+	params = withTransfer(params, e.worker, e.ep, UcpTraceEvent{Op: UcpTraceStreamRecv, Size: iovLength(iov)})
+	cbId, done := setStreamRecvParams(params, requestParams)
+
+	request := C.ucp_stream_recv_nbx(e.ep, cIov, C.size_t(len(iov)), &length, requestParams)
+	return NewRequest(request, e.worker, cbId, done, length)
 }
@@ -0,0 +1,200 @@
+//go:build linux
+// +build linux
+
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package ucx
+
+// #include <ucp/api/ucp.h>
+import "C"
+import (
+	"context"
+	"net"
+	"time"
+)
+
+// Delay of the next connection attempt of UcpWorker.Connect(), while the
+// previous attempts are neither established nor failed, as recommended by
+// RFC 8305.
+const connectAttemptDelay = 250 * time.Millisecond
+
+// Endpoint to one of the resolved addresses, which is established once its
+// flush completes.
+type connectAttempt struct {
+	ep    *UcpEp
+	flush *UcpRequest
+}
+
+func (a *connectAttempt) close() {
+	a.flush.Close()
+	if request, err := a.ep.CloseNonBlockingForce(nil); err == nil {
+		request.Close()
+	}
+}
+
+// Resolves the "host:port" address to the TCP addresses. The addresses of the
+// two families are interleaved starting from the family of the first one, so
+// the attempts alternate between IPv6 and IPv4.
+func resolveTCPAddrs(ctx context.Context, address string) ([]*net.TCPAddr, error) {
+	host, service, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+
+	port, err := net.DefaultResolver.LookupPort(ctx, "tcp", service)
+	if err != nil {
+		return nil, err
+	}
+
+	ips, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	var primary, secondary []*net.TCPAddr
+	for _, ip := range ips {
+		addr := &net.TCPAddr{IP: ip.IP, Port: port, Zone: ip.Zone}
+		if (ip.IP.To4() != nil) == (ips[0].IP.To4() != nil) {
+			primary = append(primary, addr)
+		} else {
+			secondary = append(secondary, addr)
+		}
+	}
+
+	result := make([]*net.TCPAddr, 0, len(ips))
+	for i := 0; (i < len(primary)) || (i < len(secondary)); i++ {
+		if i < len(primary) {
+			result = append(result, primary[i])
+		}
+
+		if i < len(secondary) {
+			result = append(result, secondary[i])
+		}
+	}
+	return result, nil
+}
+
+// Creates the endpoint by the copy of the params, connected to the address
+// unless it's nil. The error handler is set only on the established endpoint,
+// so it's not invoked for the failed attempts.
+func (w *UcpWorker) newConnectAttempt(epParams *UcpEpParams, addr *net.TCPAddr) (*connectAttempt, error) {
+	attemptParams := *epParams
+	attemptParams.hostAddress = ""
+	attemptParams.SetErrorHandler(nil)
+	if (attemptParams.params.field_mask & C.UCP_EP_PARAM_FIELD_ERR_HANDLING_MODE) == 0 {
+		attemptParams.SetPeerErrorHandling()
+	}
+
+	if addr != nil {
+		if _, err := attemptParams.SetSocketAddress(addr); err != nil {
+			return nil, err
+		}
+		defer freeParamsAddress(&attemptParams)
+	}
+
+	ep, err := w.NewEndpoint(&attemptParams)
+	if err != nil {
+		return nil, err
+	}
+
+	// Flush completes once the connection is established
+	flush, err := ep.FlushNonBlocking(nil)
+	if err != nil {
+		if request, err := ep.CloseNonBlockingForce(nil); err == nil {
+			request.Close()
+		}
+		return nil, err
+	}
+	return &connectAttempt{ep: ep, flush: flush}, nil
+}
+
+// This routine creates the endpoint and progresses the worker until the
+// connection is established or ctx is done. The address set by
+// UcpEpParams.SetAddress() is resolved with ctx, and the resolved addresses
+// are tried by the "happy eyeballs" of RFC 8305: the next attempt starts once
+// the previous ones fail or don't complete in 250ms, the first established
+// endpoint is returned, and the others are closed. The endpoints are created
+// with the peer error handling, unless the params set another mode, so the
+// failed attempts are detected. The params without that address are connected
+// as they are. The worker is progressed with the backoff of
+// DefaultUcpWaitBackoff while there is nothing to progress, same as
+// UcpRequest.WaitFor(). The routine must not be called concurrently with the
+// other routines progressing the worker.
+func (w *UcpWorker) Connect(ctx context.Context, epParams *UcpEpParams) (*UcpEp, error) {
+	targets := []*net.TCPAddr{nil}
+	if epParams.hostAddress != "" {
+		var err error
+		if targets, err = resolveTCPAddrs(ctx, epParams.hostAddress); err != nil {
+			return nil, err
+		}
+	}
+
+	var attempts []*connectAttempt
+	var lastErr error
+	closeAttempts := func() {
+		for _, attempt := range attempts {
+			attempt.close()
+		}
+	}
+
+	next := 0
+	var nextTime time.Time
+	state := newWaitBackoffState(DefaultUcpWaitBackoff)
+	for {
+		if (next < len(targets)) && ((len(attempts) == 0) || !time.Now().Before(nextTime)) {
+			attempt, err := w.newConnectAttempt(epParams, targets[next])
+			next++
+			nextTime = time.Now().Add(connectAttemptDelay)
+			state.reset()
+			if err != nil {
+				lastErr = err
+			} else {
+				attempts = append(attempts, attempt)
+			}
+			continue
+		}
+
+		if len(attempts) == 0 {
+			return nil, lastErr
+		}
+
+		select {
+		case <-ctx.Done():
+			closeAttempts()
+			return nil, ctx.Err()
+		default:
+		}
+
+		if progressWorker(w.worker) != 0 {
+			state.reset()
+		} else {
+			state.wait()
+		}
+
+		pending := attempts[:0]
+		for i, attempt := range attempts {
+			switch status := attempt.flush.GetStatus(); status {
+			case UCS_INPROGRESS:
+				pending = append(pending, attempt)
+			case UCS_OK:
+				attempt.flush.Close()
+				attempts = append(pending, attempts[i+1:]...)
+				closeAttempts()
+
+				if epParams.errorHandler != nil {
+					setErrorHandler(attempt.ep.ep, epParams.errorHandler)
+				}
+				return attempt.ep, nil
+			default:
+				// The next attempt doesn't wait for the delay
+				lastErr = NewUcxError(status)
+				attempt.close()
+				nextTime = time.Now()
+			}
+		}
+		attempts = pending
+	}
+}
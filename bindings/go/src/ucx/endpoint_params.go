@@ -1,3 +1,6 @@
+//go:build linux
+// +build linux
+
 /*
  * Copyright (C) 2021, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
  * See file LICENSE for terms.
@@ -18,6 +21,13 @@ import (
 type UcpEpParams struct {
 	params       C.ucp_ep_params_t
 	errorHandler UcpEpErrHandler
+	// Copied to the native memory only for the endpoint creation
+	addressBytes []byte
+	// Converted to the native sockaddr only for the endpoint creation
+	localAddr *net.TCPAddr
+	// Resolved only by UcpWorker.Connect()
+	hostAddress string
+	userData    interface{}
 }
 
 // This callback routine is invoked when transport level error detected.
@@ -29,10 +39,10 @@ type UcpEpErrHandler func(ep *UcpEp, status UcsStatus)
 
 //export ucxgo_completeGoErrorHandler
 func ucxgo_completeGoErrorHandler(user_data unsafe.Pointer, ep C.ucp_ep_h, status C.ucs_status_t) {
-	errHandleGoCallback, found := errorHandles[ep]
-	if found {
+	if errHandleGoCallback, found := getErrorHandler(ep); found {
 		errHandleGoCallback(&UcpEp{
-			ep: ep,
+			ep:     ep,
+			worker: C.ucp_worker_h(user_data),
 		}, UcsStatus(status))
 	}
 }
@@ -41,6 +51,14 @@ func ucxgo_completeGoErrorHandler(user_data unsafe.Pointer, ep C.ucp_ep_h, statu
 func (p *UcpEpParams) SetUcpAddress(a *UcpAddress) *UcpEpParams {
 	p.params.field_mask |= C.UCP_EP_PARAM_FIELD_REMOTE_ADDRESS
 	p.params.address = a.Address
+	p.addressBytes = nil
+	return p
+}
+
+// Destination address, serialized by UcpAddress.MarshalBinary() on the peer.
+func (p *UcpEpParams) SetUcpAddressBytes(address []byte) *UcpEpParams {
+	p.params.field_mask |= C.UCP_EP_PARAM_FIELD_REMOTE_ADDRESS
+	p.addressBytes = append([]byte(nil), address...)
 	return p
 }
 
@@ -48,12 +66,20 @@ func (p *UcpEpParams) SetUcpAddress(a *UcpAddress) *UcpEpParams {
 // case of remote failure, disables protocols and APIs which may cause a hang or undefined
 // behavior in case of peer failure, may affect performance and memory footprint
 func (p *UcpEpParams) SetPeerErrorHandling() *UcpEpParams {
+	return p.SetErrorHandlingMode(UCP_ERR_HANDLING_MODE_PEER)
+}
+
+// Error handling mode of the endpoint. With UCP_ERR_HANDLING_MODE_PEER
+// outstanding requests are completed with an error status once the peer
+// fails, and the handler set by UcpEpParams.SetErrorHandler() is invoked.
+func (p *UcpEpParams) SetErrorHandlingMode(mode UcpErrHandlingMode) *UcpEpParams {
 	p.params.field_mask |= C.UCP_EP_PARAM_FIELD_ERR_HANDLING_MODE
-	p.params.err_mode = C.ucp_err_handling_mode_t(C.UCP_ERR_HANDLING_MODE_PEER)
+	p.params.err_mode = C.ucp_err_handling_mode_t(mode)
 	return p
 }
 
-// Handler to process transport level failure.
+// Handler to process transport level failure. The status can be converted
+// to the error by NewUcxError(), which can be matched with errors.Is().
 func (p *UcpEpParams) SetErrorHandler(errHandler UcpEpErrHandler) *UcpEpParams {
 	var err_handler_t C.ucp_err_handler_t
 	err_handler_t.cb = (C.ucp_err_handler_cb_t)(C.ucxgo_completeGoErrorHandler)
@@ -63,6 +89,14 @@ func (p *UcpEpParams) SetErrorHandler(errHandler UcpEpErrHandler) *UcpEpParams {
 	return p
 }
 
+// Application value, that is attached to the created endpoint as by
+// UcpEp.SetUserData(). E.g. the server sets the per-connection state, when it
+// creates the endpoint from the connection request.
+func (p *UcpEpParams) SetUserData(userData interface{}) *UcpEpParams {
+	p.userData = userData
+	return p
+}
+
 // Tracing and analysis tools can identify the endpoint using this name.
 func (p *UcpEpParams) SetName(name string) *UcpEpParams {
 	freeParamsName(p)
@@ -82,6 +116,7 @@ func (p *UcpEpParams) SetSocketAddress(a *net.TCPAddr) (*UcpEpParams, error) {
 	}
 
 	freeParamsAddress(p)
+	p.hostAddress = ""
 
 	p.params.sockaddr = *sockAddr
 	runtime.SetFinalizer(p, func(f *UcpEpParams) { FreeNativeMemory(unsafe.Pointer(f.params.sockaddr.addr)) })
@@ -90,6 +125,57 @@ func (p *UcpEpParams) SetSocketAddress(a *net.TCPAddr) (*UcpEpParams, error) {
 	return p, nil
 }
 
+// Destination address of the listener, which is either *net.TCPAddr, *net.UDPAddr
+// or *net.IPAddr of IPv4 or IPv6 network, e.g. resolved by
+// net.ResolveTCPAddr("tcp", "[fe80::1%eth0]:13337"). Same as
+// UcpEpParams.SetSocketAddress() otherwise.
+func (p *UcpEpParams) SetSockAddr(a net.Addr) (*UcpEpParams, error) {
+	tcpAddr, err := toTcpAddrFromNetAddr(a)
+	if err != nil {
+		return nil, err
+	}
+	return p.SetSocketAddress(tcpAddr)
+}
+
+// Destination address of the listener in the "host:port" form of net.Dial(),
+// e.g. "server.example.com:13337" or "[fe80::1%eth0]:13337". The host is
+// resolved by UcpWorker.Connect(), which tries the resolved addresses, so the
+// endpoint is created only by that routine. Same as
+// UcpEpParams.SetSocketAddress() otherwise.
+func (p *UcpEpParams) SetAddress(address string) *UcpEpParams {
+	freeParamsAddress(p)
+	p.params.sockaddr = C.ucs_sock_addr_t{}
+	p.params.field_mask &^= C.UCP_EP_PARAM_FIELD_SOCK_ADDR
+	p.hostAddress = address
+	return p
+}
+
+// Local address to connect from to the destination set by
+// UcpEpParams.SetSockAddr(). The address selects the network interface, that
+// the connection is established through, so a process with multiple NICs can
+// pin the endpoint to one of them. Port 0 lets the system choose the port.
+// The devices, which transports the endpoint lanes may use, are limited per
+// context by UcpConfig.SetNetDevices().
+func (p *UcpEpParams) SetLocalSockAddr(a net.Addr) (*UcpEpParams, error) {
+	tcpAddr, err := toTcpAddrFromNetAddr(a)
+	if err != nil {
+		return nil, err
+	}
+
+	p.localAddr = tcpAddr
+	p.params.field_mask |= C.UCP_EP_PARAM_FIELD_LOCAL_SOCK_ADDR
+	return p, nil
+}
+
+// Flags of the endpoint creation, e.g. UCP_EP_PARAMS_FLAGS_NO_LOOPBACK. The
+// flags are added to the ones set by the other routines, e.g.
+// UCP_EP_PARAMS_FLAGS_CLIENT_SERVER set by UcpEpParams.SetSockAddr().
+func (p *UcpEpParams) SetConnectionFlags(flags UcpEpParamsFlags) *UcpEpParams {
+	p.params.flags |= C.uint(flags)
+	p.params.field_mask |= C.UCP_EP_PARAM_FIELD_FLAGS
+	return p
+}
+
 // Connection request from client; means that this type of the endpoint
 // creation is possible only on server side in client-server connection
 // establishment flow.
@@ -0,0 +1,128 @@
+//go:build linux
+// +build linux
+
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package ucx
+
+// #include <ucp/api/ucp.h>
+import "C"
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Error of the operation, which feature is not requested by UcpParams of the
+// context. It matches ErrUnsupported, e.g. errors.Is(err, ErrUnsupported).
+type UcpFeatureError struct {
+	// Feature, which the operation requires
+	Feature UcpFeatures
+}
+
+func (e *UcpFeatureError) Error() string {
+	return fmt.Sprintf("UCP feature %v is not requested by the context", e.Feature)
+}
+
+func (e *UcpFeatureError) Is(target error) bool {
+	return target == error(ErrUnsupported)
+}
+
+var featureNames = []struct {
+	feature UcpFeatures
+	name    string
+}{
+	{UCP_FEATURE_TAG, "TAG"},
+	{UCP_FEATURE_RMA, "RMA"},
+	{UCP_FEATURE_AMO32, "AMO32"},
+	{UCP_FEATURE_AMO64, "AMO64"},
+	{UCP_FEATURE_WAKEUP, "WAKEUP"},
+	{UCP_FEATURE_STREAM, "STREAM"},
+	{UCP_FEATURE_AM, "AM"},
+	{UCP_FEATURE_EXPORTED_MEMH, "EXPORTED_MEMH"},
+}
+
+// Names of the features separated by "|", e.g. "TAG|AM".
+func (f UcpFeatures) String() string {
+	var names []string
+	for _, n := range featureNames {
+		if (f & n.feature) != 0 {
+			names = append(names, n.name)
+			f &^= n.feature
+		}
+	}
+
+	if f != 0 {
+		names = append(names, fmt.Sprintf("0x%x", uint64(f)))
+	}
+
+	if len(names) == 0 {
+		return "0"
+	}
+	return strings.Join(names, "|")
+}
+
+var workerFeaturesMu sync.RWMutex
+
+// Features of the context of every worker, so the operations of the worker
+// and its endpoints are validated.
+var workerFeatures = make(map[C.ucp_worker_h]UcpFeatures)
+
+func setWorkerFeatures(worker C.ucp_worker_h, features UcpFeatures) {
+	workerFeaturesMu.Lock()
+	defer workerFeaturesMu.Unlock()
+	workerFeatures[worker] = features
+}
+
+func removeWorkerFeatures(worker C.ucp_worker_h) {
+	workerFeaturesMu.Lock()
+	defer workerFeaturesMu.Unlock()
+	delete(workerFeatures, worker)
+}
+
+// Returns UcpFeatureError, if the context of the worker is created without
+// the feature.
+func checkFeature(worker C.ucp_worker_h, feature UcpFeatures) error {
+	workerFeaturesMu.RLock()
+	features, found := workerFeatures[worker]
+	workerFeaturesMu.RUnlock()
+
+	if found && ((features & feature) == 0) {
+		return &UcpFeatureError{Feature: feature}
+	}
+	return nil
+}
+
+// Fails the operation, which feature is not requested by the context, before
+// it's started, so the callback of the params is not invoked. The returned
+// request is completed with UCS_ERR_UNSUPPORTED status.
+func checkRequestFeature(worker C.ucp_worker_h, feature UcpFeatures,
+	params *UcpRequestParams) (*UcpRequest, error) {
+	err := checkFeature(worker, feature)
+	if err == nil {
+		return nil, nil
+	}
+
+	return failRequest(worker, params, UCS_ERR_UNSUPPORTED, err)
+}
+
+// Fails the operation with the status before it's started, and releases the
+// resources of its params.
+func failRequest(worker C.ucp_worker_h, params *UcpRequestParams, status UcsStatus,
+	err error) (*UcpRequest, error) {
+	if (params != nil) && (params.release != nil) {
+		params.release()
+	}
+	return &UcpRequest{worker: worker, Status: status}, err
+}
+
+// Atomic operations of 32-bit and 64-bit operands are separate features.
+func atomicFeature(opSize uint64) UcpFeatures {
+	if opSize == 4 {
+		return UCP_FEATURE_AMO32
+	}
+	return UCP_FEATURE_AMO64
+}
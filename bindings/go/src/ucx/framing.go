@@ -0,0 +1,82 @@
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package ucx
+
+import (
+	"context"
+	"encoding/binary"
+	"unsafe"
+)
+
+// Size of the length prefix of the framed stream messages.
+const frameHeaderSize = 8
+
+// This routine sends the message, that is described by the local address and
+// size, to the stream of the endpoint, prefixed by its length. So that the peer
+// receives exactly this message by UcpEp.RecvFramed(), although the stream
+// does not preserve message boundaries. The routine progresses the worker
+// until the message is sent or the ctx is done.
+func (e *UcpEp) SendFramed(ctx context.Context, address unsafe.Pointer, size uint64) error {
+	header := make([]byte, frameHeaderSize)
+	binary.LittleEndian.PutUint64(header, size)
+	cHeader := CBytes(header)
+	defer FreeNativeMemory(cHeader)
+
+	request, err := e.SendStreamIovNonBlocking([]UcpIov{
+		{Buffer: cHeader, Length: frameHeaderSize},
+		{Buffer: address, Length: size},
+	}, nil)
+	if err != nil {
+		return err
+	}
+	defer request.Close()
+
+	return request.WaitContext(ctx)
+}
+
+// This routine receives the message, that was sent by UcpEp.SendFramed(), to
+// the buffer described by the local address and size, and returns the message
+// length. If the message is longer than the buffer, it's discarded and the
+// error with UCS_ERR_MESSAGE_TRUNCATED status is returned along with the
+// message length. The routine progresses the worker until the message is
+// received or the ctx is done.
+func (e *UcpEp) RecvFramed(ctx context.Context, address unsafe.Pointer, size uint64) (uint64, error) {
+	header := AllocateNativeMemory(frameHeaderSize)
+	defer FreeNativeMemory(header)
+
+	if err := e.recvStreamAll(ctx, header, frameHeaderSize); err != nil {
+		return 0, err
+	}
+
+	length := binary.LittleEndian.Uint64(GoBytes(header, frameHeaderSize))
+	if length == 0 {
+		return 0, nil
+	}
+
+	if length > size {
+		// Keep the stream at the boundary of the next message
+		discard := AllocateNativeMemory(length)
+		defer FreeNativeMemory(discard)
+		if err := e.recvStreamAll(ctx, discard, length); err != nil {
+			return 0, err
+		}
+		return length, NewUcxError(UCS_ERR_MESSAGE_TRUNCATED)
+	}
+
+	return length, e.recvStreamAll(ctx, address, length)
+}
+
+func (e *UcpEp) recvStreamAll(ctx context.Context, address unsafe.Pointer, size uint64) error {
+	params := (&UcpRequestParams{}).SetStreamRecvFlags(UCP_STREAM_RECV_FLAG_WAITALL)
+
+	request, err := e.RecvStreamNonBlocking(address, size, params)
+	if err != nil {
+		return err
+	}
+	defer request.Close()
+
+	return request.WaitContext(ctx)
+}
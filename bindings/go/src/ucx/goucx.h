@@ -2,17 +2,102 @@
  * Copyright (C) 2021, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
  * See file LICENSE for terms.
  */
+
+#ifndef GOUCX_H_
+#define GOUCX_H_
+
 #include <ucp/api/ucp.h>
+#include <stdint.h>
+
+extern void ucxgo_completeGoSendRequest(void *request, ucs_status_t status, uint64_t callback_id);
 
-extern void ucxgo_completeGoSendRequest(void *request, ucs_status_t status, void *callback_id);
+extern void ucxgo_completeGoTagRecvRequest(void *request, ucs_status_t status, ucp_tag_recv_info_t *info, uint64_t callback_id);
 
-extern void ucxgo_completeGoTagRecvRequest(void *request, ucs_status_t status, ucp_tag_recv_info_t *info, void *callback_id);
+extern void ucxgo_completePersistentTagRecv(void *request, ucs_status_t status, ucp_tag_recv_info_t *info, uint64_t callback_id);
+
+extern void ucxgo_completePersistentSend(void *request, ucs_status_t status, uint64_t callback_id);
 
 extern void ucxgo_completeGoErrorHandler(void* arg, ucp_ep_h ep, ucs_status_t status);
 
-extern void ucxgo_completeConnHandler(ucp_conn_request_h conn_request, void *callback_id);
+extern void ucxgo_completeConnHandler(ucp_conn_request_h conn_request, uint64_t callback_id);
 
-extern ucs_status_t ucxgo_amRecvCallback(void *callback_id, void *header, size_t header_length,
+extern ucs_status_t ucxgo_amRecvCallback(uint64_t callback_id, void *header, size_t header_length,
                                          void *data, size_t length, ucp_am_recv_param_t *param);
 
-extern void ucxgo_completeAmRecvData(void *request, ucs_status_t status, size_t length, void *callback_id);
\ No newline at end of file
+extern void ucxgo_completeAmRecvData(void *request, ucs_status_t status, size_t length, uint64_t callback_id);
+
+extern void ucxgo_completeGoStreamRecvRequest(void *request, ucs_status_t status, size_t length, uint64_t callback_id);
+
+extern void ucxgo_logMessage(char *file, unsigned line, char *function, ucs_log_level_t level,
+                             char *component, char *message);
+
+/*
+ * The callback handles are the integers, which are cast to user_data or arg
+ * only on the C side, see the cgo pointer passing rules.
+ */
+static inline void ucxgo_send_cb(void *request, ucs_status_t status, void *user_data)
+{
+    ucxgo_completeGoSendRequest(request, status, (uintptr_t)user_data);
+}
+
+static inline void ucxgo_tag_recv_cb(void *request, ucs_status_t status,
+                                     const ucp_tag_recv_info_t *info, void *user_data)
+{
+    ucxgo_completeGoTagRecvRequest(request, status, (ucp_tag_recv_info_t*)info,
+                                   (uintptr_t)user_data);
+}
+
+static inline void ucxgo_persistent_tag_recv_cb(void *request, ucs_status_t status,
+                                                const ucp_tag_recv_info_t *info,
+                                                void *user_data)
+{
+    ucxgo_completePersistentTagRecv(request, status, (ucp_tag_recv_info_t*)info,
+                                    (uintptr_t)user_data);
+}
+
+static inline void ucxgo_persistent_send_cb(void *request, ucs_status_t status, void *user_data)
+{
+    ucxgo_completePersistentSend(request, status, (uintptr_t)user_data);
+}
+
+static inline void ucxgo_conn_handler_cb(ucp_conn_request_h conn_request, void *arg)
+{
+    ucxgo_completeConnHandler(conn_request, (uintptr_t)arg);
+}
+
+static inline ucs_status_t ucxgo_am_recv_cb(void *arg, const void *header, size_t header_length,
+                                            void *data, size_t length,
+                                            const ucp_am_recv_param_t *param)
+{
+    return ucxgo_amRecvCallback((uintptr_t)arg, (void*)header, header_length, data, length,
+                                (ucp_am_recv_param_t*)param);
+}
+
+static inline void ucxgo_am_recv_data_cb(void *request, ucs_status_t status, size_t length,
+                                         void *user_data)
+{
+    ucxgo_completeAmRecvData(request, status, length, (uintptr_t)user_data);
+}
+
+static inline void ucxgo_stream_recv_cb(void *request, ucs_status_t status, size_t length,
+                                        void *user_data)
+{
+    ucxgo_completeGoStreamRecvRequest(request, status, length, (uintptr_t)user_data);
+}
+
+static inline void ucxgo_request_param_set_user_data(ucp_request_param_t *param, uint64_t handle)
+{
+    param->user_data = (void*)(uintptr_t)handle;
+}
+
+static inline void ucxgo_am_handler_param_set_arg(ucp_am_handler_param_t *param, uint64_t handle)
+{
+    param->arg = (void*)(uintptr_t)handle;
+}
+
+static inline void ucxgo_conn_handler_set_arg(ucp_listener_conn_handler_t *handler, uint64_t handle)
+{
+    handler->arg = (void*)(uintptr_t)handle;
+}
+
+#endif
@@ -0,0 +1,411 @@
+//go:build linux
+// +build linux
+
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package ucx
+
+// #include <ucp/api/ucp.h>
+import "C"
+import (
+	"context"
+	"sync"
+	"unsafe"
+)
+
+// Kinds of the handshake messages, which are the first byte of the header.
+const (
+	handshakeMessage byte = 1
+	handshakeAccept  byte = 2
+	handshakeReject  byte = 3
+	// The client starts the handshake, once it's ready to receive the messages
+	// of the server, which may otherwise arrive before the client endpoint is
+	// returned by UcpWorker.Connect()
+	handshakeHello byte = 4
+)
+
+const (
+	// The messages are sent in the Active Message header, so they are always
+	// delivered eagerly and received by the handler in full
+	handshakeMaxMessage = 4096
+	// Messages of the endpoint, that are received but not read yet
+	handshakeMaxPending = 16
+)
+
+// Application-defined handshake of the connection, e.g. the exchange of the
+// tokens or the mutual authentication, which is run by both sides over the
+// messages of UcpHandshakeConn. The connection is rejected, unless both sides
+// return nil.
+type UcpHandshake = func(conn *UcpHandshakeConn) error
+
+// Error of the rejected handshake, that matches ErrRejected by errors.Is().
+// Err is the error of the local handshake, or nil if the peer rejected the
+// connection, since the reason of the peer isn't sent over the wire.
+type UcpHandshakeError struct {
+	Err error
+}
+
+func (e *UcpHandshakeError) Error() string {
+	if e.Err == nil {
+		return "handshake is rejected by the peer"
+	}
+	return "handshake failed: " + e.Err.Error()
+}
+
+func (e *UcpHandshakeError) Is(target error) bool {
+	return target == error(ErrRejected)
+}
+
+func (e *UcpHandshakeError) Unwrap() error {
+	return e.Err
+}
+
+// Messages received on the endpoint during its handshake.
+type handshakeInbox struct {
+	messages [][]byte
+	hello    bool
+	// handshakeAccept or handshakeReject of the peer, once it's received
+	result byte
+}
+
+// Active Message handler of the handshakes of the worker.
+type handshakeService struct {
+	id      uint
+	mu      sync.Mutex
+	inboxes map[C.ucp_ep_h]*handshakeInbox
+}
+
+var handshakeServicesMu sync.Mutex
+
+// Handshake services of the workers, that enabled the handshake.
+var handshakeServices = make(map[C.ucp_worker_h]*handshakeService)
+
+func getHandshakeService(worker C.ucp_worker_h) *handshakeService {
+	handshakeServicesMu.Lock()
+	defer handshakeServicesMu.Unlock()
+	return handshakeServices[worker]
+}
+
+func removeHandshakeService(worker C.ucp_worker_h) {
+	handshakeServicesMu.Lock()
+	defer handshakeServicesMu.Unlock()
+	delete(handshakeServices, worker)
+}
+
+// This routine installs the Active Message handler of the id, that receives
+// the handshake messages of UcpWorker.ConnectHandshake() and
+// UcpWorker.AcceptHandshake(). The peers must enable the handshake with the
+// same id. The context must be created with UcpParams.EnableAM().
+func (w *UcpWorker) EnableHandshake(id uint) error {
+	service := &handshakeService{id: id, inboxes: make(map[C.ucp_ep_h]*handshakeInbox)}
+	if err := w.SetAmRecvHandler(id, UCP_AM_FLAG_WHOLE_MSG, service.handle); err != nil {
+		return err
+	}
+
+	handshakeServicesMu.Lock()
+	defer handshakeServicesMu.Unlock()
+	handshakeServices[w.worker] = service
+	return nil
+}
+
+// The messages of the endpoints, that don't run the handshake, are dropped, so
+// the peer can't grow the memory of the worker by them.
+func (s *handshakeService) handle(header unsafe.Pointer, headerSize uint64, data *UcpAmData,
+	replyEp *UcpEp) UcsStatus {
+	if (replyEp == nil) || (headerSize == 0) {
+		return UCS_OK
+	}
+
+	message := AmHeader(header, headerSize).Clone()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	inbox := s.inboxes[replyEp.ep]
+	if inbox == nil {
+		return UCS_OK
+	}
+
+	switch message[0] {
+	case handshakeMessage:
+		if len(inbox.messages) < handshakeMaxPending {
+			inbox.messages = append(inbox.messages, message[1:])
+		}
+	case handshakeHello:
+		inbox.hello = true
+	case handshakeAccept, handshakeReject:
+		if inbox.result == 0 {
+			inbox.result = message[0]
+		}
+	}
+	return UCS_OK
+}
+
+func (s *handshakeService) open(ep C.ucp_ep_h) *handshakeInbox {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	inbox := &handshakeInbox{}
+	s.inboxes[ep] = inbox
+	return inbox
+}
+
+func (s *handshakeService) close(ep C.ucp_ep_h) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.inboxes, ep)
+}
+
+// Returns the next message of the inbox, or the result of the peer, once all
+// the messages are read.
+func (s *handshakeService) next(inbox *handshakeInbox) ([]byte, byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(inbox.messages) > 0 {
+		message := inbox.messages[0]
+		inbox.messages = inbox.messages[1:]
+		return message, 0
+	}
+	return nil, inbox.result
+}
+
+func (s *handshakeService) isStarted(inbox *handshakeInbox) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return inbox.hello || (inbox.result != 0)
+}
+
+// Connection, that runs the handshake, before its endpoint is returned to the
+// application.
+type UcpHandshakeConn struct {
+	ctx     context.Context
+	ep      *UcpEp
+	server  bool
+	service *handshakeService
+	inbox   *handshakeInbox
+}
+
+// Returns the endpoint of the connection, e.g. to query its peer address. The
+// endpoint must not be closed by the handshake.
+func (c *UcpHandshakeConn) Ep() *UcpEp {
+	return c.ep
+}
+
+// Reports whether the connection is accepted by UcpWorker.AcceptHandshake(),
+// so the same handshake tells the client and the server roles apart.
+func (c *UcpHandshakeConn) IsServer() bool {
+	return c.server
+}
+
+func (c *UcpHandshakeConn) send(kind byte, message []byte) error {
+	size := len(message) + 1
+	header := AllocateNativeMemory(uint64(size))
+	defer FreeNativeMemory(header)
+
+	handshakeHeader := (*[1 << 40]byte)(header)[:size:size]
+	handshakeHeader[0] = kind
+	copy(handshakeHeader[1:], message)
+
+	request, err := c.ep.SendAmNonBlocking(c.service.id, header, uint64(size), nil, 0,
+		UCP_AM_SEND_FLAG_REPLY|UCP_AM_SEND_FLAG_COPY_HEADER, nil)
+	if err != nil {
+		return err
+	}
+	defer request.Close()
+
+	state := newWaitBackoffState(DefaultUcpWaitBackoff)
+	for {
+		switch status := request.GetStatus(); status {
+		case UCS_OK:
+			return nil
+		case UCS_INPROGRESS:
+		default:
+			return NewUcxError(status)
+		}
+
+		select {
+		case <-c.ctx.Done():
+			return c.ctx.Err()
+		default:
+		}
+		if progressWorker(c.ep.worker) != 0 {
+			state.reset()
+		} else {
+			state.wait()
+		}
+	}
+}
+
+// This routine sends the message of the handshake to the peer, and
+// progresses the worker until it's sent. The message is up to 4KB, larger
+// ones fail with ErrExceedsLimit.
+func (c *UcpHandshakeConn) Send(message []byte) error {
+	if len(message) > handshakeMaxMessage {
+		return ErrExceedsLimit
+	}
+	return c.send(handshakeMessage, message)
+}
+
+// This routine progresses the worker until the next message of the peer is
+// received, and returns it. Fails with UcpHandshakeError, once the peer
+// rejects the connection, with ErrNoElem, once the peer completes its
+// handshake without sending more messages, and with the error of ctx.
+// The worker is progressed with the backoff of DefaultUcpWaitBackoff while
+// there is nothing to progress, same as UcpRequest.WaitFor().
+func (c *UcpHandshakeConn) Recv() ([]byte, error) {
+	state := newWaitBackoffState(DefaultUcpWaitBackoff)
+	for {
+		message, result := c.service.next(c.inbox)
+		switch {
+		case message != nil:
+			return message, nil
+		case result == handshakeReject:
+			return nil, &UcpHandshakeError{}
+		case result == handshakeAccept:
+			return nil, ErrNoElem
+		}
+
+		select {
+		case <-c.ctx.Done():
+			return nil, c.ctx.Err()
+		default:
+		}
+		if progressWorker(c.ep.worker) != 0 {
+			state.reset()
+		} else {
+			state.wait()
+		}
+	}
+}
+
+// Runs the handshake on the endpoint, and waits for the result of the peer,
+// so neither side returns the endpoint before both accept the connection.
+func (w *UcpWorker) runHandshake(ctx context.Context, service *handshakeService, ep *UcpEp, server bool,
+	handshake UcpHandshake) error {
+	conn := &UcpHandshakeConn{
+		ctx:     ctx,
+		ep:      ep,
+		server:  server,
+		service: service,
+		inbox:   service.open(ep.ep),
+	}
+	defer service.close(ep.ep)
+
+	if !server {
+		if err := conn.send(handshakeHello, nil); err != nil {
+			return err
+		}
+	} else {
+		state := newWaitBackoffState(DefaultUcpWaitBackoff)
+		for !service.isStarted(conn.inbox) {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			default:
+			}
+			if progressWorker(w.worker) != 0 {
+				state.reset()
+			} else {
+				state.wait()
+			}
+		}
+	}
+
+	if err := handshake(conn); err != nil {
+		// The peer fails by the force closure anyway
+		conn.send(handshakeReject, nil)
+		if _, rejected := err.(*UcpHandshakeError); rejected {
+			return err
+		}
+		return &UcpHandshakeError{Err: err}
+	}
+
+	if err := conn.send(handshakeAccept, nil); err != nil {
+		return err
+	}
+
+	state := newWaitBackoffState(DefaultUcpWaitBackoff)
+	for {
+		message, result := service.next(conn.inbox)
+		switch {
+		case message != nil:
+			// The messages, that the handshake hasn't read, are dropped
+			continue
+		case result == handshakeAccept:
+			return nil
+		case result == handshakeReject:
+			return &UcpHandshakeError{}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+		if progressWorker(w.worker) != 0 {
+			state.reset()
+		} else {
+			state.wait()
+		}
+	}
+}
+
+func closeHandshakeEp(ep *UcpEp) {
+	if request, err := ep.CloseNonBlockingForce(nil); err == nil {
+		request.Close()
+	}
+}
+
+// This routine connects the endpoint by UcpWorker.Connect(), and runs the
+// handshake with the peer, that accepts it by UcpWorker.AcceptHandshake(). The
+// endpoint is returned once both sides complete their handshakes, otherwise
+// it's closed, and the routine fails with UcpHandshakeError, the error of the
+// endpoint, or the error of ctx, which limits the connection and the
+// handshake. Fails with ErrInvalidParam, unless the handshake is enabled by
+// UcpWorker.EnableHandshake(). The routine must not be called concurrently
+// with the other routines progressing the worker.
+func (w *UcpWorker) ConnectHandshake(ctx context.Context, epParams *UcpEpParams,
+	handshake UcpHandshake) (*UcpEp, error) {
+	service := getHandshakeService(w.worker)
+	if service == nil {
+		return nil, ErrInvalidParam
+	}
+
+	ep, err := w.Connect(ctx, epParams)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := w.runHandshake(ctx, service, ep, false, handshake); err != nil {
+		closeHandshakeEp(ep)
+		return nil, err
+	}
+	return ep, nil
+}
+
+// This routine accepts the connection request by
+// UcpWorker.NewEndpointFromConnRequest(), and runs the handshake with the
+// client of UcpWorker.ConnectHandshake(), same as on the client side. The
+// endpoint of the rejected handshake is closed, so the client fails too. It
+// must be called by the goroutine progressing the worker, but outside the
+// connection handler of the listener, since it progresses the worker itself,
+// e.g. once the handler passes the request to that goroutine.
+func (w *UcpWorker) AcceptHandshake(ctx context.Context, connRequest *UcpConnectionRequest,
+	epParams *UcpEpParams, handshake UcpHandshake) (*UcpEp, error) {
+	service := getHandshakeService(w.worker)
+	if service == nil {
+		return nil, ErrInvalidParam
+	}
+
+	ep, err := w.NewEndpointFromConnRequest(connRequest, epParams)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := w.runHandshake(ctx, service, ep, true, handshake); err != nil {
+		closeHandshakeEp(ep)
+		return nil, err
+	}
+	return ep, nil
+}
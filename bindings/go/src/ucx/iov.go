@@ -0,0 +1,64 @@
+//go:build linux
+// +build linux
+
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package ucx
+
+// #include <stdlib.h>
+// #include <ucp/api/ucp.h>
+import "C"
+import (
+	"unsafe"
+)
+
+// Buffer of the scatter-gather list, that is sent or received by a single
+// operation. Like in other operations the buffer has to be allocated by the
+// native code (e.g. by AllocateNativeMemory() or UcpContext.MemMap()), since
+// it's accessed by the library after the call returns.
+type UcpIov struct {
+	Buffer unsafe.Pointer
+	Length uint64
+}
+
+// Copies the io vector to the native memory, that stays valid until the
+// operation completes and sets the datatype of the operation to iov.
+func setIovParams(iov []UcpIov, cRequestParams *C.ucp_request_param_t) unsafe.Pointer {
+	// Extra element keeps the allocation valid for the empty vector
+	cIov := C.malloc(C.size_t(len(iov)+1) * C.sizeof_ucp_dt_iov_t)
+	cIovSlice := (*[1 << 28]C.ucp_dt_iov_t)(cIov)[:len(iov):len(iov)]
+
+	for i, buffer := range iov {
+		cIovSlice[i].buffer = buffer.Buffer
+		cIovSlice[i].length = C.size_t(buffer.Length)
+	}
+
+	cRequestParams.op_attr_mask |= C.UCP_OP_ATTR_FIELD_DATATYPE
+	cRequestParams.datatype = C.ucp_datatype_t(C.UCP_DATATYPE_IOV)
+	return cIov
+}
+
+// Returns a copy of request params, which calls release once the operation is
+// completed, including the immediate completion and the completion of the
+// request closed in progress.
+func withRelease(params *UcpRequestParams, release func()) *UcpRequestParams {
+	var result UcpRequestParams
+
+	if params != nil {
+		result = *params
+	}
+
+	if prevRelease := result.release; prevRelease != nil {
+		result.release = func() {
+			release()
+			prevRelease()
+		}
+	} else {
+		result.release = release
+	}
+
+	return &result
+}
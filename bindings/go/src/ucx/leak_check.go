@@ -0,0 +1,140 @@
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package ucx
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+)
+
+// Resource, that was not closed, along with the stack of its creation.
+type UcxLeak struct {
+	// Kind of the resource: "context", "worker", "endpoint", "listener",
+	// "memory" or "request"
+	Resource string
+	Stack    string
+}
+
+type leakEntry struct {
+	resource string
+	stack    string
+	// Order of the creation, so the leaks are reported in the same order
+	seq uint64
+}
+
+var leakCheck int32
+
+var leaksMu sync.Mutex
+
+// Native handles of the live resources, that were created while the leak
+// check is enabled.
+var liveResources = make(map[unsafe.Pointer]leakEntry)
+
+var leakSeq uint64
+
+// Enables the debug mode, that tracks all the contexts, workers, endpoints,
+// listeners, memory handles and requests, that are created afterwards, until
+// they are closed. The resources, that are not closed, are returned by Leaks()
+// along with the stacks of their creation, e.g. to call CheckLeaks() at the
+// end of the main or TestMain. While enabled, the memory of
+// UcpContext.AllocAndMap() is not unmapped by the garbage collector, so the
+// missing UcpMemory.Close() is reported instead of being masked.
+//
+// The mode costs the stack capture for every resource, so it's meant for the
+// debugging only. Disabling it drops all the tracked resources.
+func SetLeakCheck(enabled bool) {
+	if enabled {
+		atomic.StoreInt32(&leakCheck, 1)
+		return
+	}
+
+	atomic.StoreInt32(&leakCheck, 0)
+	leaksMu.Lock()
+	defer leaksMu.Unlock()
+	liveResources = make(map[unsafe.Pointer]leakEntry)
+}
+
+func isLeakCheckEnabled() bool {
+	return atomic.LoadInt32(&leakCheck) != 0
+}
+
+// Returns the resources, that are not closed yet, in the order of creation.
+func Leaks() []UcxLeak {
+	leaksMu.Lock()
+	entries := make([]leakEntry, 0, len(liveResources))
+	for _, entry := range liveResources {
+		entries = append(entries, entry)
+	}
+	leaksMu.Unlock()
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].seq < entries[j].seq })
+	leaks := make([]UcxLeak, len(entries))
+	for i, entry := range entries {
+		leaks[i] = UcxLeak{Resource: entry.resource, Stack: entry.stack}
+	}
+	return leaks
+}
+
+// Returns the error describing all the resources, that are not closed yet, or
+// nil if there are none.
+func CheckLeaks() error {
+	leaks := Leaks()
+	if len(leaks) == 0 {
+		return nil
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%v UCX resources are not closed:", len(leaks))
+	for _, leak := range leaks {
+		fmt.Fprintf(&sb, "\n%v created at:\n%v", leak.Resource, leak.Stack)
+	}
+	return fmt.Errorf("%s", sb.String())
+}
+
+// Starts tracking the resource of the native handle, if the leak check is
+// enabled.
+func trackResource(resource string, handle unsafe.Pointer) {
+	if !isLeakCheckEnabled() {
+		return
+	}
+
+	stack := creationStack()
+	leaksMu.Lock()
+	defer leaksMu.Unlock()
+	leakSeq++
+	liveResources[handle] = leakEntry{resource: resource, stack: stack, seq: leakSeq}
+}
+
+func untrackResource(handle unsafe.Pointer) {
+	if !isLeakCheckEnabled() {
+		return
+	}
+
+	leaksMu.Lock()
+	defer leaksMu.Unlock()
+	delete(liveResources, handle)
+}
+
+func creationStack() string {
+	pcs := make([]uintptr, 32)
+	// Skips runtime.Callers, creationStack and trackResource
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var sb strings.Builder
+	for {
+		frame, more := frames.Next()
+		fmt.Fprintf(&sb, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		if !more {
+			return sb.String()
+		}
+	}
+}
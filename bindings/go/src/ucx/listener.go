@@ -1,3 +1,6 @@
+//go:build linux
+// +build linux
+
 /*
  * Copyright (C) 2021, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
  * See file LICENSE for terms.
@@ -7,24 +10,58 @@ package ucx
 
 // #include <ucp/api/ucp.h>
 import "C"
-import "net"
+import (
+	"net"
+	"sync"
+	"unsafe"
+)
 
 type UcpListener struct {
 	listener      C.ucp_listener_h
 	connHandlerId uint64
+	worker        *UcpWorker
 }
 
 // Needed to call connHandler.Reject() rather than listener.Reject(connHandler)
 var connHandles2Listener = make(map[uint64]C.ucp_listener_h)
+var connHandlesMu sync.RWMutex
+
+func getListenerByConnHandler(id uint64) C.ucp_listener_h {
+	connHandlesMu.RLock()
+	defer connHandlesMu.RUnlock()
+	return connHandles2Listener[id]
+}
+
+func setListenerByConnHandler(id uint64, listener C.ucp_listener_h) {
+	connHandlesMu.Lock()
+	defer connHandlesMu.Unlock()
+	if listener == nil {
+		delete(connHandles2Listener, id)
+	} else {
+		connHandles2Listener[id] = listener
+	}
+}
 
 type UcpListenerAttributes struct {
 	Address *net.TCPAddr
 }
 
 func (l *UcpListener) Close() {
+	if l.listener == nil {
+		// Already closed by UcpContext.Shutdown()
+		return
+	}
+
+	rejectListenerConnRequests(l.listener)
 	C.ucp_listener_destroy(l.listener)
+	untrackResource(unsafe.Pointer(l.listener))
 	deregister(l.connHandlerId)
-	delete(connHandles2Listener, l.connHandlerId)
+	setListenerByConnHandler(l.connHandlerId, nil)
+	l.listener = nil
+
+	l.worker.resourcesMu.Lock()
+	defer l.worker.resourcesMu.Unlock()
+	delete(l.worker.listeners, l)
 }
 
 func (l *UcpListener) Query(attrs ...UcpListenerAttribute) (*UcpListenerAttributes, error) {
@@ -48,3 +85,47 @@ func (l *UcpListener) Query(attrs ...UcpListenerAttribute) (*UcpListenerAttribut
 	}
 	return result, nil
 }
+
+// This routine returns the address, that the listener is bound to, e.g. the
+// actual port of the listener created on port 0.
+func (l *UcpListener) Addr() (*net.TCPAddr, error) {
+	attrs, err := l.Query(UCP_LISTENER_ATTR_FIELD_SOCKADDR)
+	if err != nil {
+		return nil, err
+	}
+	return attrs.Address, nil
+}
+
+// This routine returns the addresses, that the clients can connect to, e.g.
+// to advertise them by the service discovery. The listener bound to the
+// wildcard address, e.g. 0.0.0.0 or ::, is reachable by the addresses of all
+// the network interfaces of the same family, except the loopback and the
+// link-local ones, which are returned with the actual port of the listener. Otherwise it's the bound
+// address.
+func (l *UcpListener) AdvertisedAddrs() ([]*net.TCPAddr, error) {
+	bound, err := l.Addr()
+	if err != nil {
+		return nil, err
+	}
+
+	if !bound.IP.IsUnspecified() {
+		return []*net.TCPAddr{bound}, nil
+	}
+
+	interfaceAddrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return nil, err
+	}
+
+	ipv4 := bound.IP.To4() != nil
+	var result []*net.TCPAddr
+	for _, interfaceAddr := range interfaceAddrs {
+		ipNet, ok := interfaceAddr.(*net.IPNet)
+		if !ok || ipNet.IP.IsLoopback() || ipNet.IP.IsLinkLocalUnicast() ||
+			((ipNet.IP.To4() != nil) != ipv4) {
+			continue
+		}
+		result = append(result, &net.TCPAddr{IP: ipNet.IP, Port: bound.Port})
+	}
+	return result, nil
+}
@@ -1,3 +1,6 @@
+//go:build linux
+// +build linux
+
 /*
  * Copyright (C) 2021, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
  * See file LICENSE for terms.
@@ -18,18 +21,66 @@ import (
 type UcpListenerParams struct {
 	params        C.ucp_listener_params_t
 	connHandlerId uint64
+	connHandler   *listenerConnHandler
+}
+
+// Decides whether the incoming connection is accepted by the client address
+// and the client id, which is zero unless the client sends it, see
+// UcpEpParams.SendClientId().
+type UcpConnectionFilter = func(attrs *UcpConnectionRequestAttributes) bool
+
+// Handler and filter of the connection requests of the listener, which is
+// registered as the callback of the listener.
+type listenerConnHandler struct {
+	handler UcpListenerConnectionHandler
+	filter  UcpConnectionFilter
+}
+
+// Queries the client of the request for the filter. The client id is queried
+// separately, since it's not available unless the client sends it.
+func filterAttributes(request *UcpConnectionRequest) *UcpConnectionRequestAttributes {
+	attrs, err := request.Query(UCP_CONN_REQUEST_ATTR_FIELD_CLIENT_ADDR)
+	if err != nil {
+		attrs = &UcpConnectionRequestAttributes{}
+	}
+
+	if idAttrs, err := request.Query(UCP_CONN_REQUEST_ATTR_FIELD_CLIENT_ID); err == nil {
+		attrs.ClientId = idAttrs.ClientId
+	}
+	return attrs
 }
 
 //export ucxgo_completeConnHandler
-func ucxgo_completeConnHandler(connRequest C.ucp_conn_request_h, cbId unsafe.Pointer) {
-	id := uint64(uintptr((cbId)))
+func ucxgo_completeConnHandler(connRequest C.ucp_conn_request_h, cbId C.uint64_t) {
+	id := uint64(cbId)
 	if callback, found := getCallback(id); found {
-		listener := connHandles2Listener[id]
-		callback.(UcpListenerConnectionHandler)(&UcpConnectionRequest{
+		connHandler := callback.(*listenerConnHandler)
+		request := &UcpConnectionRequest{
 			connRequest: connRequest,
-			listener:    listener,
-		})
+			listener:    getListenerByConnHandler(id),
+		}
+		addConnRequest(connRequest, request.listener)
+
+		if (connHandler.filter != nil) && !connHandler.filter(filterAttributes(request)) {
+			request.Reject()
+			return
+		}
+		connHandler.handler(request)
+	}
+}
+
+// Registers the handler of the connection requests once, so the handler and
+// the filter may be set in any order.
+func (p *UcpListenerParams) getConnHandler() *listenerConnHandler {
+	if p.connHandler == nil {
+		var ucpConnHndl C.ucp_listener_conn_handler_t
+		p.connHandler = &listenerConnHandler{}
+		p.connHandlerId = register(p.connHandler)
+		ucpConnHndl.cb = (C.ucp_listener_conn_callback_t)(C.ucxgo_conn_handler_cb)
+		C.ucxgo_conn_handler_set_arg(&ucpConnHndl, C.uint64_t(p.connHandlerId))
+		p.params.conn_handler = ucpConnHndl
 	}
+	return p.connHandler
 }
 
 // Destination address
@@ -48,13 +99,18 @@ func (p *UcpListenerParams) SetSocketAddress(a *net.TCPAddr) (*UcpListenerParams
 
 // Handler of an incoming connection request in a client-server connection flow.
 func (p *UcpListenerParams) SetConnectionHandler(connHandler UcpListenerConnectionHandler) *UcpListenerParams {
-	var ucpConnHndl C.ucp_listener_conn_handler_t
-	cbId := register(connHandler)
-
-	p.connHandlerId = cbId
-	ucpConnHndl.arg = unsafe.Pointer(uintptr(cbId))
-	ucpConnHndl.cb = (C.ucp_listener_conn_callback_t)(C.ucxgo_completeConnHandler)
+	p.getConnHandler().handler = connHandler
 	p.params.field_mask |= C.UCP_LISTENER_PARAM_FIELD_CONN_HANDLER
-	p.params.conn_handler = ucpConnHndl
+	return p
+}
+
+// Filter of the incoming connection requests, e.g. the allow-list of the
+// client addresses, which is applied before the connection handler. The
+// requests, that the filter declines, are rejected by
+// UcpConnectionRequest.Reject() without invoking the connection handler, so
+// the client endpoint fails with UCS_ERR_REJECTED. The filter is called from
+// the progress of the worker, so it must not block.
+func (p *UcpListenerParams) SetConnectionFilter(filter UcpConnectionFilter) *UcpListenerParams {
+	p.getConnHandler().filter = filter
 	return p
 }
@@ -0,0 +1,138 @@
+//go:build linux
+// +build linux
+
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package ucx
+
+// #include <stdio.h>
+// #include <stdlib.h>
+// #include <ucs/debug/log_def.h>
+// #include "goucx.h"
+//
+// static volatile int ucxgo_log_enabled = 0;
+//
+// static ucs_log_func_rc_t
+// ucxgo_log_handler(const char *file, unsigned line, const char *function,
+//                   ucs_log_level_t level, const ucs_log_component_config_t *comp_conf,
+//                   const char *format, va_list ap) {
+//     char message[2048];
+//
+//     if (!ucxgo_log_enabled) {
+//         return UCS_LOG_FUNC_RC_CONTINUE;
+//     }
+//
+//     if (!ucs_log_component_is_enabled(level, comp_conf) &&
+//         (level != UCS_LOG_LEVEL_PRINT)) {
+//         return UCS_LOG_FUNC_RC_STOP;
+//     }
+//
+//     vsnprintf(message, sizeof(message), format, ap);
+//     ucxgo_logMessage((char*)file, line, (char*)function, level,
+//                      (char*)comp_conf->name, message);
+//     return UCS_LOG_FUNC_RC_STOP;
+// }
+//
+// static void ucxgo_log_install() {
+//     ucs_log_push_handler(ucxgo_log_handler);
+// }
+//
+// static void ucxgo_log_enable(int enable) {
+//     ucxgo_log_enabled = enable;
+// }
+//
+// static const char* ucxgo_log_level_name(ucs_log_level_t level) {
+//     return ucs_log_level_names[level];
+// }
+import "C"
+import (
+	"sync"
+	"unsafe"
+)
+
+// UCX log message, that is passed to the Go log handler.
+type UcsLogRecord struct {
+	Level     UcsLogLevel
+	Component string
+	File      string
+	Line      uint
+	Function  string
+	Message   string
+}
+
+// Log handler is invoked from the thread, that emits the message, which may
+// also be an internal UCX thread. The handler must not call UCX routines.
+type UcsLogHandler = func(record *UcsLogRecord)
+
+var logHandler UcsLogHandler
+
+// Maximal level of messages per log component, e.g. "UCX" or "UCS".
+var logComponentLevels = make(map[string]UcsLogLevel)
+
+var logMu sync.RWMutex
+
+var logInstallOnce sync.Once
+
+func (l UcsLogLevel) String() string {
+	if (l < UCS_LOG_LEVEL_FATAL) || (l > UCS_LOG_LEVEL_PRINT) || (l == C.UCS_LOG_LEVEL_LAST) {
+		return "UNKNOWN"
+	}
+	return C.GoString(C.ucxgo_log_level_name(C.ucs_log_level_t(l)))
+}
+
+// This routine redirects UCX log messages from stderr (or UCX_LOG_FILE) to the
+// handler. The messages are emitted according to UCX_LOG_LEVEL, which can be
+// changed by SetLogLevel(). Passing nil restores the default UCX output.
+func SetLogHandler(handler UcsLogHandler) {
+	logInstallOnce.Do(func() {
+		C.ucxgo_log_install()
+	})
+
+	logMu.Lock()
+	logHandler = handler
+	logMu.Unlock()
+
+	if handler != nil {
+		C.ucxgo_log_enable(1)
+	} else {
+		C.ucxgo_log_enable(0)
+	}
+}
+
+// This routine limits the messages of the log component, that are passed to
+// the log handler, by the level. Messages of other components are not filtered.
+func SetLogComponentLevel(component string, level UcsLogLevel) {
+	logMu.Lock()
+	defer logMu.Unlock()
+	logComponentLevels[component] = level
+}
+
+// This routine sets the global UCX log level, same as UCX_LOG_LEVEL
+// environment variable.
+func SetLogLevel(level UcsLogLevel) error {
+	name := C.CString(level.String())
+	defer C.free(unsafe.Pointer(name))
+
+	logLevel := C.CString("LOG_LEVEL")
+	defer C.free(unsafe.Pointer(logLevel))
+
+	if status := C.ucs_global_opts_set_value(logLevel, name); status != C.UCS_OK {
+		return newUcxError(status)
+	}
+	return nil
+}
+
+// Returns the handler for the message of the component at the level, or nil
+// if the message is filtered out.
+func getLogHandler(component string, level UcsLogLevel) UcsLogHandler {
+	logMu.RLock()
+	defer logMu.RUnlock()
+	if maxLevel, found := logComponentLevels[component]; found && (level > maxLevel) &&
+		(level != UCS_LOG_LEVEL_PRINT) {
+		return nil
+	}
+	return logHandler
+}
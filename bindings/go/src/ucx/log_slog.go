@@ -0,0 +1,53 @@
+//go:build go1.21
+// +build go1.21
+
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package ucx
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// Converts UCX log level to slog level. Trace levels are mapped below
+// slog.LevelDebug, so they can be filtered by the slog handler.
+func slogLevel(level UcsLogLevel) slog.Level {
+	switch {
+	case level <= UCS_LOG_LEVEL_ERROR:
+		return slog.LevelError
+	case level == UCS_LOG_LEVEL_WARN:
+		return slog.LevelWarn
+	case level <= UCS_LOG_LEVEL_INFO, level == UCS_LOG_LEVEL_PRINT:
+		return slog.LevelInfo
+	case level == UCS_LOG_LEVEL_DEBUG:
+		return slog.LevelDebug
+	}
+	return slog.LevelDebug - slog.Level(level-UCS_LOG_LEVEL_DEBUG)
+}
+
+// Returns the log handler, that passes UCX log messages to the slog handler,
+// e.g. SetLogHandler(NewSlogLogHandler(slog.Default().Handler())).
+func NewSlogLogHandler(handler slog.Handler) UcsLogHandler {
+	return func(record *UcsLogRecord) {
+		level := slogLevel(record.Level)
+		ctx := context.Background()
+		if !handler.Enabled(ctx, level) {
+			return
+		}
+
+		r := slog.NewRecord(time.Now(), level, record.Message, 0)
+		r.AddAttrs(
+			slog.String("component", record.Component),
+			slog.String("ucx_level", record.Level.String()),
+			slog.String("file", record.File),
+			slog.Int("line", int(record.Line)),
+			slog.String("function", record.Function),
+		)
+		handler.Handle(ctx, r)
+	}
+}
@@ -1,3 +1,6 @@
+//go:build linux
+// +build linux
+
 /*
  * Copyright (C) 2021, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
  * See file LICENSE for terms.
@@ -7,7 +10,10 @@ package ucx
 
 // #include <ucp/api/ucp.h>
 import "C"
-import "unsafe"
+import (
+	"runtime"
+	"unsafe"
+)
 
 // Memory handle is an opaque object representing a memory region allocated
 // through UCP library, which is optimized for remote memory access
@@ -17,6 +23,9 @@ import "unsafe"
 type UcpMemory struct {
 	memHandle C.ucp_mem_h
 	context   C.ucp_context_h
+	owner     *UcpContext
+	// Allocation of the binding, which is freed by UcpMemory.Close()
+	allocation *memAllocation
 }
 
 type UcpMemAttributes struct {
@@ -60,10 +69,73 @@ func (m *UcpMemory) Query(attrs ...UcpMemAttribute) (*UcpMemAttributes, error) {
 	return result, nil
 }
 
+// This routine packs the memory handle into a buffer, which contains the remote
+// key (RKEY). The buffer has to be passed to the peer, that will unpack it with
+// UcpEp.UnpackRkey() to access this memory region with RMA operations.
+func (m *UcpMemory) RkeyPack() ([]byte, error) {
+	var packParams C.ucp_memh_pack_params_t
+	var releaseParams C.ucp_memh_buffer_release_params_t
+	var buffer unsafe.Pointer
+	var size C.size_t
+
+	if status := C.ucp_memh_pack(m.memHandle, &packParams, &buffer, &size); status != C.UCS_OK {
+		return nil, newUcxError(status)
+	}
+
+	result := C.GoBytes(buffer, C.int(size))
+	C.ucp_memh_buffer_release(buffer, &releaseParams)
+	return result, nil
+}
+
+// This routine packs the memory handle into a buffer, which can be passed to
+// another process on the same host, that imports it by UcpContext.MemImport().
+// Unlike UcpMemory.RkeyPack(), the imported handle is used for local operations
+// of that process, e.g. to copy the data between the segments of co-located
+// processes without staging. Returns UCS_ERR_UNSUPPORTED error, if none of the
+// memory domains of the memory can export it.
+func (m *UcpMemory) Export() ([]byte, error) {
+	var packParams C.ucp_memh_pack_params_t
+	var releaseParams C.ucp_memh_buffer_release_params_t
+	var buffer unsafe.Pointer
+	var size C.size_t
+
+	packParams.field_mask = C.UCP_MEMH_PACK_PARAM_FIELD_FLAGS
+	packParams.flags = C.UCP_MEMH_PACK_FLAG_EXPORT
+
+	if status := C.ucp_memh_pack(m.memHandle, &packParams, &buffer, &size); status != C.UCS_OK {
+		return nil, newUcxError(status)
+	}
+
+	result := C.GoBytes(buffer, C.int(size))
+	C.ucp_memh_buffer_release(buffer, &releaseParams)
+	return result, nil
+}
+
 func (m *UcpMemory) Close() error {
-	if status := C.ucp_mem_unmap(m.context, m.memHandle); status != C.UCS_OK {
+	runtime.SetFinalizer(m, nil)
+	if m.memHandle == nil {
+		return nil
+	}
+
+	m.owner.resourcesMu.Lock()
+	allocation, found := m.owner.memories[m.memHandle]
+	delete(m.owner.memories, m.memHandle)
+	m.owner.resourcesMu.Unlock()
+
+	memHandle := m.memHandle
+	m.memHandle = nil
+	untrackResource(unsafe.Pointer(memHandle))
+	if !found {
+		// Already closed by UcpContext.Shutdown()
+		return nil
+	}
+
+	status := C.ucp_mem_unmap(m.context, memHandle)
+	if status != C.UCS_OK {
+		// The memory may still be registered, so it's not freed
 		return newUcxError(status)
 	}
 
+	allocation.free()
 	return nil
 }
@@ -0,0 +1,223 @@
+//go:build linux
+// +build linux
+
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package ucx
+
+// #include <sys/mman.h>
+// #include <stdint.h>
+//
+// static void *ucxgo_mmap_aligned(size_t length, size_t alignment, int flags) {
+//     size_t total = length + alignment;
+//     uintptr_t start, aligned;
+//     void *ptr;
+//
+//     ptr = mmap(NULL, total, PROT_READ | PROT_WRITE,
+//                MAP_PRIVATE | MAP_ANONYMOUS | flags, -1, 0);
+//     if (ptr == MAP_FAILED) {
+//         return NULL;
+//     }
+//
+//     start   = (uintptr_t)ptr;
+//     aligned = (start + alignment - 1) & ~(uintptr_t)(alignment - 1);
+//     if (aligned != start) {
+//         munmap(ptr, aligned - start);
+//     }
+//
+//     if (start + total != aligned + length) {
+//         munmap((void*)(aligned + length), start + total - aligned - length);
+//     }
+//     return (void*)aligned;
+// }
+import "C"
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unsafe"
+)
+
+// Huge pages of the memory, that is allocated by UcpContext.MemMap().
+type UcpHugePages int
+
+const (
+	// Base pages, unless UCP allocates the huge pages by ALLOC_PRIO
+	UcpHugePagesNone UcpHugePages = iota
+	// Pages of the hugetlbfs pool, which has to be reserved by
+	// /proc/sys/vm/nr_hugepages
+	UcpHugePagesHugeTLB
+	// Base pages, that the kernel is advised to collapse to the transparent
+	// huge pages by madvise(MADV_HUGEPAGE)
+	UcpHugePagesTransparent
+)
+
+// Properties of the allocated or registered memory, see UcpMemory.Attributes().
+type UcpMemAllocAttributes struct {
+	UcpMemAttributes
+
+	// Largest power of 2, that divides the address
+	Alignment uint64
+
+	// Huge pages, that the memory is allocated with by UcpMmapParams
+	HugePages UcpHugePages
+
+	// Page size of the kernel mapping, e.g. the huge page size of the hugetlb
+	// memory. Zero for non-host memory.
+	PageSize uint64
+
+	// Bytes of the kernel mapping, that contains the memory, which are
+	// currently backed by the transparent huge pages
+	TransparentHugeBytes uint64
+}
+
+// Memory, that is allocated by the binding for the options of UcpMmapParams,
+// which UCP doesn't support, and registered by UCP.
+type memAllocation struct {
+	address   unsafe.Pointer
+	length    uint64
+	hugePages UcpHugePages
+}
+
+// Returns the default page size of the hugetlbfs pool.
+func hugeTLBPageSize() (uint64, error) {
+	file, err := os.Open("/proc/meminfo")
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if fields := strings.Fields(scanner.Text()); (len(fields) == 3) && (fields[0] == "Hugepagesize:") {
+			size, err := strconv.ParseUint(fields[1], 10, 64)
+			return size * 1024, err
+		}
+	}
+	return 0, ErrUnsupported
+}
+
+func allocateMemory(length uint64, alignment uint64, hugePages UcpHugePages) (*memAllocation, error) {
+	pageSize := uint64(os.Getpagesize())
+	flags := C.int(0)
+	if hugePages == UcpHugePagesHugeTLB {
+		hugePageSize, err := hugeTLBPageSize()
+		if err != nil {
+			return nil, err
+		}
+		pageSize = hugePageSize
+		flags |= C.MAP_HUGETLB
+	}
+
+	// The mapping is trimmed by the whole pages
+	if alignment < pageSize {
+		alignment = pageSize
+	}
+	length = (length + pageSize - 1) &^ (pageSize - 1)
+
+	address, err := C.ucxgo_mmap_aligned(C.size_t(length), C.size_t(alignment), flags)
+	if address == nil {
+		return nil, err
+	}
+
+	if hugePages == UcpHugePagesTransparent {
+		if ret, err := C.madvise(address, C.size_t(length), C.MADV_HUGEPAGE); ret != 0 {
+			C.munmap(address, C.size_t(length))
+			return nil, err
+		}
+	}
+	return &memAllocation{address: address, length: length, hugePages: hugePages}, nil
+}
+
+func (a *memAllocation) free() {
+	if a != nil {
+		C.munmap(a.address, C.size_t(a.length))
+	}
+}
+
+// Reads the page size and the transparent huge pages of the kernel mapping,
+// that contains the address, from /proc/self/smaps.
+func readSmaps(address uintptr) (pageSize uint64, hugeBytes uint64, err error) {
+	file, err := os.Open("/proc/self/smaps")
+	if err != nil {
+		return 0, 0, err
+	}
+	defer file.Close()
+
+	found := false
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		if !strings.HasSuffix(fields[0], ":") {
+			// Header of the next mapping, e.g. "7f2a4c000000-7f2a4c200000 rw-p ..."
+			if found {
+				break
+			}
+
+			var start, end uintptr
+			if _, err := fmt.Sscanf(fields[0], "%x-%x", &start, &end); err == nil {
+				found = (address >= start) && (address < end)
+			}
+			continue
+		}
+
+		if !found || (len(fields) != 3) {
+			continue
+		}
+
+		value, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+
+		switch fields[0] {
+		case "KernelPageSize:":
+			pageSize = value * 1024
+		case "AnonHugePages:":
+			hugeBytes = value * 1024
+		}
+	}
+
+	if !found {
+		return 0, 0, ErrNoElem
+	}
+	return pageSize, hugeBytes, scanner.Err()
+}
+
+// This routine returns the properties of the memory, which are used for the
+// capacity planning: its address, alignment and page size, and how much of it
+// is backed by the transparent huge pages right now. The memory, that UCP
+// allocates without the options of UcpMmapParams, reports the huge pages of
+// ALLOC_PRIO only by the page size.
+func (m *UcpMemory) Attributes() (*UcpMemAllocAttributes, error) {
+	memAttrs, err := m.Query(UCP_MEM_ATTR_FIELD_ADDRESS, UCP_MEM_ATTR_FIELD_LENGTH, UCP_MEM_ATTR_FIELD_MEM_TYPE)
+	if err != nil {
+		return nil, err
+	}
+
+	address := uintptr(memAttrs.Address)
+	result := &UcpMemAllocAttributes{
+		UcpMemAttributes: *memAttrs,
+		Alignment:        uint64(address & -address),
+	}
+
+	if m.allocation != nil {
+		result.HugePages = m.allocation.hugePages
+	}
+
+	if memAttrs.MemType == UCS_MEMORY_TYPE_HOST {
+		if result.PageSize, result.TransparentHugeBytes, err = readSmaps(address); err != nil {
+			result.PageSize = uint64(os.Getpagesize())
+		}
+	}
+	return result, nil
+}
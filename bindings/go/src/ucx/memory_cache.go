@@ -0,0 +1,326 @@
+//go:build linux
+// +build linux
+
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package ucx
+
+// #include <ucp/api/ucp.h>
+import "C"
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+)
+
+// Cache of the memory registrations of the buffers, which are used by the
+// communication operations repeatedly. Operations look up the cache, and pass
+// the memory handle to the library instead of registering the buffer on every
+// call. The Go slices of the operations, e.g. UcpEp.SendTagBytesNonBlocking(),
+// are looked up in the cache of the worker, see
+// UcpWorkerParams.SetMemoryCacheCapacity(), and the other buffers in the cache
+// set by UcpRequestParams.SetMemoryCache(). The registrations, which are not
+// used by outstanding operations, are evicted in least recently used order
+// once the cache exceeds its capacity.
+//
+// The registrations are keyed by the end of the buffer, so the sub-slices of
+// the registered slice, which share its backing array, reuse its registration.
+// The registered slice is kept reachable and pinned as long as it's cached,
+// whereas the native memory must be invalidated by UcpMemoryCache.Invalidate()
+// before it's freed.
+type UcpMemoryCache struct {
+	context  *UcpContext
+	capacity int
+	mu       sync.Mutex
+	entries  map[uintptr]*memoryCacheEntry
+	byMemory map[*UcpMemory]*memoryCacheEntry
+	// Unused entries, the least recently used one is at the front
+	lru *list.List
+}
+
+type memoryCacheEntry struct {
+	address uintptr
+	end     uintptr
+	memory  *UcpMemory
+	// Backing array of the registered Go slice, and the routine, that unpins it
+	owner []byte
+	unpin func()
+	refs  int
+	// Position in the lru list, when the entry is not used
+	lruElem *list.Element
+	// Unmapped once it's not used, instead of being cached
+	invalid bool
+}
+
+// This routine creates new UcpMemoryCache, which keeps up to capacity unused
+// registrations. With capacity 0 the registrations are unmapped as soon as
+// they are not used, which effectively disables the caching.
+func (c *UcpContext) NewMemoryCache(capacity int) *UcpMemoryCache {
+	return &UcpMemoryCache{
+		context:  c,
+		capacity: capacity,
+		entries:  make(map[uintptr]*memoryCacheEntry),
+		byMemory: make(map[*UcpMemory]*memoryCacheEntry),
+		lru:      list.New(),
+	}
+}
+
+// Returns the registration of the native buffer, registering it if it's not
+// cached. The registration stays valid until it's released by
+// UcpMemoryCache.Put().
+func (m *UcpMemoryCache) Get(address unsafe.Pointer, length uint64) (*UcpMemory, error) {
+	return m.get(address, length, nil)
+}
+
+// Returns the registration of the Go slice, which covers its capacity, so the
+// other sub-slices of the backing array up to its end reuse it. It's the same
+// as UcpMemoryCache.Get() otherwise.
+func (m *UcpMemoryCache) GetBytes(data []byte) (*UcpMemory, error) {
+	if cap(data) == 0 {
+		return nil, ErrInvalidParam
+	}
+
+	owner := data[:cap(data)]
+	return m.get(unsafe.Pointer(&owner[0]), uint64(len(owner)), owner)
+}
+
+func (m *UcpMemoryCache) get(address unsafe.Pointer, length uint64, owner []byte) (*UcpMemory, error) {
+	start := uintptr(address)
+	end := start + uintptr(length)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, found := m.entries[end]
+	if found && (entry.address <= start) {
+		if entry.lruElem != nil {
+			m.lru.Remove(entry.lruElem)
+			entry.lruElem = nil
+		}
+		entry.refs++
+		return entry.memory, nil
+	}
+
+	mmapParams := &UcpMmapParams{}
+	mmapParams.SetAddress(address).SetLength(length)
+
+	memory, err := m.context.MemMap(mmapParams)
+	if err != nil {
+		return nil, err
+	}
+
+	// The registration of the shorter buffer with the same end is replaced
+	if found {
+		m.invalidate(entry)
+	}
+
+	entry = &memoryCacheEntry{
+		address: start,
+		end:     end,
+		memory:  memory,
+		owner:   owner,
+		unpin:   func() {},
+		refs:    1,
+	}
+	if owner != nil {
+		entry.unpin = pinSlice(owner)
+	}
+	m.entries[end] = entry
+	m.byMemory[memory] = entry
+	return memory, nil
+}
+
+// Releases the registration returned by UcpMemoryCache.Get(). The unused
+// registrations are kept in the cache up to its capacity.
+func (m *UcpMemoryCache) Put(memory *UcpMemory) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, found := m.byMemory[memory]
+	if !found {
+		return
+	}
+
+	if entry.refs--; entry.refs > 0 {
+		return
+	}
+
+	if entry.invalid {
+		m.release(entry)
+		return
+	}
+
+	entry.lruElem = m.lru.PushBack(entry)
+	for m.lru.Len() > m.capacity {
+		m.release(m.lru.Front().Value.(*memoryCacheEntry))
+	}
+}
+
+// Removes the registrations, which overlap the buffer, from the cache, e.g.
+// before the buffer is freed. The registrations are unmapped once they are not
+// used by operations.
+func (m *UcpMemoryCache) Invalidate(address unsafe.Pointer, length uint64) {
+	start := uintptr(address)
+	end := start + uintptr(length)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, entry := range m.entries {
+		if (entry.address < end) && (start < entry.end) {
+			m.invalidate(entry)
+		}
+	}
+}
+
+func (m *UcpMemoryCache) invalidate(entry *memoryCacheEntry) {
+	delete(m.entries, entry.end)
+	if entry.refs == 0 {
+		m.release(entry)
+	} else {
+		entry.invalid = true
+	}
+}
+
+// Unmaps all unused registrations. The cache must not be used after this call.
+func (m *UcpMemoryCache) Close() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for m.lru.Len() > 0 {
+		m.release(m.lru.Front().Value.(*memoryCacheEntry))
+	}
+}
+
+func (m *UcpMemoryCache) release(entry *memoryCacheEntry) {
+	if entry.lruElem != nil {
+		m.lru.Remove(entry.lruElem)
+		entry.lruElem = nil
+	}
+
+	if !entry.invalid {
+		delete(m.entries, entry.end)
+	}
+	delete(m.byMemory, entry.memory)
+	entry.memory.Close()
+	entry.unpin()
+	entry.owner = nil
+}
+
+// Sets the registration of the buffer from the memory cache of the request
+// params. The registration is released, once the operation completes. If the
+// buffer can't be registered, the library registers it as usual.
+func setCachedMemory(params *UcpRequestParams, address unsafe.Pointer, size uint64,
+	cRequestParams *C.ucp_request_param_t) *UcpRequestParams {
+	if (params == nil) || (params.memoryCache == nil) || (params.memory != nil) ||
+		(address == nil) || (size == 0) {
+		return params
+	}
+
+	memoryCache := params.memoryCache
+	memory, err := memoryCache.Get(address, size)
+	if err != nil {
+		return params
+	}
+
+	cRequestParams.op_attr_mask |= C.UCP_OP_ATTR_FIELD_MEMH
+	cRequestParams.memh = memory.memHandle
+	return withRelease(params, func() { memoryCache.Put(memory) })
+}
+
+// Sets the registration of the Go slice from the memory cache of the params,
+// or of the worker, see UcpWorkerParams.SetMemoryCacheCapacity(). The slice
+// isn't looked up, if the memory of the operation is set, or if the operation
+// doesn't access the slice itself, e.g. when it's copied to the native memory
+// before Go 1.21.
+func withCachedBytes(params *UcpRequestParams, worker C.ucp_worker_h, address unsafe.Pointer,
+	data []byte) *UcpRequestParams {
+	if (len(data) == 0) || (address != unsafe.Pointer(&data[0])) ||
+		((params != nil) && (params.memory != nil)) {
+		return params
+	}
+
+	var memoryCache *UcpMemoryCache
+	if params != nil {
+		memoryCache = params.memoryCache
+	}
+	if memoryCache == nil {
+		memoryCache = getWorkerMemoryCache(worker)
+	}
+	if memoryCache == nil {
+		return params
+	}
+
+	memory, err := memoryCache.GetBytes(data)
+	if err != nil {
+		return params
+	}
+
+	params = withRelease(params, func() { memoryCache.Put(memory) })
+	params.memory = memory
+	return params
+}
+
+// See withCachedBytes().
+func (e *UcpEp) withCachedBytes(params *UcpRequestParams, address unsafe.Pointer,
+	data []byte) *UcpRequestParams {
+	return withCachedBytes(params, e.worker, address, data)
+}
+
+var workerMemoryCachesMu sync.RWMutex
+
+// Memory caches of the workers created with
+// UcpWorkerParams.SetMemoryCacheCapacity().
+var workerMemoryCaches = make(map[C.ucp_worker_h]*UcpMemoryCache)
+
+// Number of the workers in workerMemoryCaches, so the operations skip the
+// lookup while there are none.
+var memoryCacheWorkers int32
+
+func addWorkerMemoryCache(worker C.ucp_worker_h, memoryCache *UcpMemoryCache) {
+	workerMemoryCachesMu.Lock()
+	defer workerMemoryCachesMu.Unlock()
+	workerMemoryCaches[worker] = memoryCache
+	atomic.AddInt32(&memoryCacheWorkers, 1)
+}
+
+// Unmaps the unused registrations of the closed worker. The ones used by the
+// operations, that are still completing, are unmapped once they are released.
+func removeWorkerMemoryCache(worker C.ucp_worker_h) {
+	workerMemoryCachesMu.Lock()
+	memoryCache, found := workerMemoryCaches[worker]
+	if found {
+		delete(workerMemoryCaches, worker)
+		atomic.AddInt32(&memoryCacheWorkers, -1)
+	}
+	workerMemoryCachesMu.Unlock()
+
+	if found {
+		memoryCache.mu.Lock()
+		memoryCache.capacity = 0
+		memoryCache.mu.Unlock()
+		memoryCache.Close()
+	}
+}
+
+func getWorkerMemoryCache(worker C.ucp_worker_h) *UcpMemoryCache {
+	if atomic.LoadInt32(&memoryCacheWorkers) == 0 {
+		return nil
+	}
+
+	workerMemoryCachesMu.RLock()
+	defer workerMemoryCachesMu.RUnlock()
+	return workerMemoryCaches[worker]
+}
+
+// Returns the memory cache of the Go slices of the worker, see
+// UcpWorkerParams.SetMemoryCacheCapacity(), or nil if the worker has none. The
+// cache can also be set to the operations on the native memory by
+// UcpRequestParams.SetMemoryCache().
+func (w *UcpWorker) MemoryCache() *UcpMemoryCache {
+	return getWorkerMemoryCache(w.worker)
+}
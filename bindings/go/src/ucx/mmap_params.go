@@ -1,3 +1,6 @@
+//go:build linux
+// +build linux
+
 /*
  * Copyright (C) 2021, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
  * See file LICENSE for terms.
@@ -12,6 +15,11 @@ import "unsafe"
 // Tuning parameters for the UCP memory mapping.
 type UcpMmapParams struct {
 	params C.ucp_mem_map_params_t
+	// Copied to the native memory only for the mapping
+	exportedMemh []byte
+	// Options of the allocation, that is done by the binding
+	alignment uint64
+	hugePages UcpHugePages
 }
 
 // If the address is not NULL, the routine maps (registers) the memory segment
@@ -47,6 +55,31 @@ func (p *UcpMmapParams) Nonblocking() *UcpMmapParams {
 	return p
 }
 
+// Alignment of the allocated memory, which must be a power of 2, e.g. the
+// huge page size for the NIC translation tables or the cache line size of the
+// ring buffers. UCP doesn't align the allocation, so the memory with this or
+// SetHugePages() option is allocated by mmap() of the binding, and then
+// registered by UCP, which still honors Nonblocking() and the protection.
+// Requires Allocate() of the host memory without Fixed().
+func (p *UcpMmapParams) SetAlignment(alignment uint64) *UcpMmapParams {
+	p.alignment = alignment
+	return p
+}
+
+// Huge pages of the allocated memory, see UcpHugePages and SetAlignment().
+// The length of the hugetlb memory is rounded up to the huge page size, and
+// the allocation fails with ENOMEM once the hugetlbfs pool is exhausted. The
+// actual pages are reported by UcpMemory.Attributes().
+func (p *UcpMmapParams) SetHugePages(hugePages UcpHugePages) *UcpMmapParams {
+	p.hugePages = hugePages
+	return p
+}
+
+// Whether the memory is allocated by the binding rather than by UCP.
+func (p *UcpMmapParams) allocatedByBinding() bool {
+	return (p.alignment != 0) || (p.hugePages != UcpHugePagesNone)
+}
+
 // Don't interpret address as a hint: place the mapping at exactly that
 // address. The address must be a multiple of the page size.
 func (p *UcpMmapParams) Fixed() *UcpMmapParams {
@@ -83,3 +116,16 @@ func (p *UcpMmapParams) SetMemoryType(memType UcsMemoryType) *UcpMmapParams {
 	p.params.memory_type = C.ucs_memory_type_t(memType)
 	return p
 }
+
+// Memory handle, that was exported by UcpMemory.Export() in another process on
+// the same host. The memory of that handle is mapped instead of the address,
+// so the other fields of the buffer are not required. The context must be
+// created with UcpParams.EnableExportedMemh(), and the memory, e.g. the GPU
+// memory of the decoder process, is then accessed by GPUDirect without the
+// copies. The memory domains, that export the handles, are reported by
+// ucxinfo.MemoryDomain.ExportedMemh.
+func (p *UcpMmapParams) SetExportedMemh(buffer []byte) *UcpMmapParams {
+	p.exportedMemh = append([]byte(nil), buffer...)
+	p.params.field_mask |= C.UCP_MEM_MAP_PARAM_FIELD_EXPORTED_MEMH_BUFFER
+	return p
+}
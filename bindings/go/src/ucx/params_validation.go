@@ -0,0 +1,155 @@
+//go:build linux
+// +build linux
+
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package ucx
+
+// #include <ucp/api/ucp.h>
+import "C"
+import "fmt"
+
+// Error of the invalid combination of the parameters, which is returned by
+// Validate() of the params and by the routines creating the objects from
+// them, instead of the failure of UCX. It matches ErrInvalidParam by
+// errors.Is().
+type UcpParamsError struct {
+	// Type of the params, e.g. "UcpEpParams"
+	Params string
+	Reason string
+}
+
+func (e *UcpParamsError) Error() string {
+	return fmt.Sprintf("invalid %v: %v", e.Params, e.Reason)
+}
+
+func (e *UcpParamsError) Is(target error) bool {
+	return target == error(ErrInvalidParam)
+}
+
+// Checks the parameters of the context, which NewUcpContext() does before
+// initializing UCP. UCP itself only warns about the context without features.
+func (p *UcpParams) Validate() error {
+	if ((p.params.field_mask & C.UCP_PARAM_FIELD_FEATURES) == 0) || (p.params.features == 0) {
+		return &UcpParamsError{"UcpParams", "no features are requested"}
+	}
+	return nil
+}
+
+// Checks the parameters of the worker, which UcpContext.NewWorker() does
+// before creating the worker. The wake-up events are checked against the
+// features of the context only by UcpContext.NewWorker().
+func (p *UcpWorkerParams) Validate() error {
+	if p.requestPoolSize < 0 {
+		return &UcpParamsError{"UcpWorkerParams", fmt.Sprintf("request pool size %v is negative",
+			p.requestPoolSize)}
+	}
+
+	if p.memoryCacheCapacity < 0 {
+		return &UcpParamsError{"UcpWorkerParams", fmt.Sprintf("memory cache capacity %v is negative",
+			p.memoryCacheCapacity)}
+	}
+
+	if alignment := uint64(p.params.am_alignment); ((p.params.field_mask &
+		C.UCP_WORKER_PARAM_FIELD_AM_ALIGNMENT) != 0) && ((alignment & (alignment - 1)) != 0) {
+		return &UcpParamsError{"UcpWorkerParams", fmt.Sprintf("AM alignment %v is not a power of 2", alignment)}
+	}
+	return nil
+}
+
+func (p *UcpWorkerParams) validateFeatures(features UcpFeatures) error {
+	if ((p.params.field_mask & (C.UCP_WORKER_PARAM_FIELD_EVENTS | C.UCP_WORKER_PARAM_FIELD_EVENT_FD)) != 0) &&
+		((features & UCP_FEATURE_WAKEUP) == 0) {
+		return &UcpParamsError{"UcpWorkerParams",
+			"wake-up events are requested from the context without UCP_FEATURE_WAKEUP"}
+	}
+	return nil
+}
+
+// Checks the parameters of the endpoint, which UcpWorker.NewEndpoint() does
+// before creating the endpoint: exactly one destination has to be set, and the
+// client-server options require the socket address.
+func (p *UcpEpParams) Validate() error {
+	destinations := 0
+	for _, field := range []C.uint64_t{C.UCP_EP_PARAM_FIELD_REMOTE_ADDRESS, C.UCP_EP_PARAM_FIELD_SOCK_ADDR,
+		C.UCP_EP_PARAM_FIELD_CONN_REQUEST} {
+		if (p.params.field_mask & field) != 0 {
+			destinations++
+		}
+	}
+
+	if p.hostAddress != "" {
+		destinations++
+	}
+
+	switch {
+	case destinations == 0:
+		return &UcpParamsError{"UcpEpParams", "destination address is not set"}
+	case destinations > 1:
+		return &UcpParamsError{"UcpEpParams", "more than one destination is set"}
+	}
+
+	clientServer := ((p.params.field_mask & C.UCP_EP_PARAM_FIELD_SOCK_ADDR) != 0) || (p.hostAddress != "")
+	if !clientServer && ((p.params.flags & C.UCP_EP_PARAMS_FLAGS_SEND_CLIENT_ID) != 0) {
+		return &UcpParamsError{"UcpEpParams", "client id is sent without the socket address"}
+	}
+
+	if !clientServer && ((p.params.field_mask & C.UCP_EP_PARAM_FIELD_LOCAL_SOCK_ADDR) != 0) {
+		return &UcpParamsError{"UcpEpParams", "local socket address is set without the socket address"}
+	}
+
+	if (p.errorHandler != nil) && ((p.params.field_mask & C.UCP_EP_PARAM_FIELD_ERR_HANDLING_MODE) != 0) &&
+		(p.params.err_mode == C.UCP_ERR_HANDLING_MODE_NONE) {
+		return &UcpParamsError{"UcpEpParams", "error handler is set with UCP_ERR_HANDLING_MODE_NONE"}
+	}
+	return nil
+}
+
+// Checks the parameters of the listener, which UcpWorker.NewListener() does
+// before creating the listener.
+func (p *UcpListenerParams) Validate() error {
+	if (p.params.field_mask & C.UCP_LISTENER_PARAM_FIELD_SOCK_ADDR) == 0 {
+		return &UcpParamsError{"UcpListenerParams", "socket address is not set"}
+	}
+
+	if (p.connHandler == nil) || (p.connHandler.handler == nil) {
+		return &UcpParamsError{"UcpListenerParams", "connection handler is not set"}
+	}
+	return nil
+}
+
+// Checks the parameters of the memory mapping, which UcpContext.MemMap() does
+// before mapping the memory. The options of the allocation, that UCP doesn't
+// support, are checked only by the binding.
+func (p *UcpMmapParams) Validate() error {
+	if (p.alignment & (p.alignment - 1)) != 0 {
+		return &UcpParamsError{"UcpMmapParams", fmt.Sprintf("alignment %v is not a power of 2", p.alignment)}
+	}
+
+	if (p.hugePages < UcpHugePagesNone) || (p.hugePages > UcpHugePagesTransparent) {
+		return &UcpParamsError{"UcpMmapParams", fmt.Sprintf("unknown huge pages %v", p.hugePages)}
+	}
+
+	if !p.allocatedByBinding() {
+		return nil
+	}
+
+	switch {
+	case (p.params.field_mask&C.UCP_MEM_MAP_PARAM_FIELD_FLAGS) == 0 ||
+		(p.params.flags&C.UCP_MEM_MAP_ALLOCATE) == 0:
+		return &UcpParamsError{"UcpMmapParams", "alignment or huge pages are set without allocation"}
+	case (p.params.flags & C.UCP_MEM_MAP_FIXED) != 0:
+		return &UcpParamsError{"UcpMmapParams", "alignment or huge pages are set with the fixed address"}
+	case ((p.params.field_mask & C.UCP_MEM_MAP_PARAM_FIELD_MEMORY_TYPE) != 0) &&
+		(p.params.memory_type != C.UCS_MEMORY_TYPE_HOST):
+		return &UcpParamsError{"UcpMmapParams", "alignment or huge pages are set for non-host memory"}
+	case p.exportedMemh != nil:
+		return &UcpParamsError{"UcpMmapParams", "alignment or huge pages are set with the exported memory handle"}
+	case ((p.params.field_mask & C.UCP_MEM_MAP_PARAM_FIELD_LENGTH) == 0) || (p.params.length == 0):
+		return &UcpParamsError{"UcpMmapParams", "length of the allocation is not set"}
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+//go:build linux
+// +build linux
+
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package ucx
+
+// #include <ucp/api/ucp.h>
+// #include "goucx.h"
+import "C"
+import (
+	"unsafe"
+)
+
+// This callback routine is invoked for every message, that is received by
+// the persistent receive. The buffer holds the message data and is valid only
+// until the callback returns, then it's reused for the next receive.
+type UcpTagPersistentRecvCallback = func(buffer unsafe.Pointer, info *UcpTagRecvInfo, status UcsStatus)
+
+// Tag receive, that is reposted automatically after every received message.
+// All the routines must be called from the thread, that progresses the worker.
+type UcpTagPersistentRecv struct {
+	worker   *UcpWorker
+	tag      uint64
+	tagMask  uint64
+	pool     UcpBufferPool
+	callback UcpTagPersistentRecvCallback
+	params   *C.ucp_request_param_t
+	slots    []*persistentRecvSlot
+	active   int
+	closed   bool
+}
+
+// Posted receive of the persistent receive. Everything the receive needs is
+// allocated once, so reposting doesn't allocate memory.
+type persistentRecvSlot struct {
+	recv    *UcpTagPersistentRecv
+	id      uint64
+	buffer  unsafe.Pointer
+	request unsafe.Pointer
+	cInfo   *C.ucp_tag_recv_info_t
+	info    UcpTagRecvInfo
+}
+
+// This routine posts a tag receive for every buffer, that is available in
+// the pool, and invokes the callback for each received message. The receives
+// are reposted until UcpTagPersistentRecv.Close() is called, so the number of
+// messages in flight is limited by the number of buffers in the pool. The
+// receives skip the memory type detection, if the pool implements
+// UcpMemoryTypeBufferPool.
+func (w *UcpWorker) RecvTagPersistent(tag uint64, tagMask uint64, pool UcpBufferPool,
+	cb UcpTagPersistentRecvCallback) (*UcpTagPersistentRecv, error) {
+	r := &UcpTagPersistentRecv{
+		worker:   w,
+		tag:      tag,
+		tagMask:  tagMask,
+		pool:     pool,
+		callback: cb,
+		params:   (*C.ucp_request_param_t)(AllocateNativeMemory(C.sizeof_ucp_request_param_t)),
+	}
+
+	*r.params = C.ucp_request_param_t{}
+	r.params.op_attr_mask = C.UCP_OP_ATTR_FIELD_CALLBACK | C.UCP_OP_ATTR_FIELD_USER_DATA |
+		C.UCP_OP_ATTR_FIELD_RECV_INFO
+	cbAddr := (*C.ucp_tag_recv_nbx_callback_t)(unsafe.Pointer(&r.params.cb[0]))
+	*cbAddr = (C.ucp_tag_recv_nbx_callback_t)(C.ucxgo_persistent_tag_recv_cb)
+	if memTypePool, ok := pool.(UcpMemoryTypeBufferPool); ok {
+		r.params.op_attr_mask |= C.UCP_OP_ATTR_FIELD_MEMORY_TYPE
+		r.params.memory_type = C.ucs_memory_type_t(memTypePool.MemoryType())
+	}
+
+	for buffer := pool.Get(); buffer != nil; buffer = pool.Get() {
+		slot := &persistentRecvSlot{
+			recv:   r,
+			buffer: buffer,
+			cInfo:  (*C.ucp_tag_recv_info_t)(AllocateNativeMemory(C.sizeof_ucp_tag_recv_info_t)),
+		}
+		slot.id = register(slot)
+		r.slots = append(r.slots, slot)
+	}
+
+	if len(r.slots) == 0 {
+		FreeNativeMemory(unsafe.Pointer(r.params))
+		return nil, NewUcxError(UCS_ERR_NO_RESOURCE)
+	}
+
+	r.active = len(r.slots)
+	for _, slot := range r.slots {
+		r.post(slot)
+	}
+	return r, nil
+}
+
+// Posts the receive to the slot buffer, and handles immediate completions in
+// the loop, until the receive is pending or failed.
+func (r *UcpTagPersistentRecv) post(s *persistentRecvSlot) {
+	for !r.closed {
+		C.ucxgo_request_param_set_user_data(r.params, C.uint64_t(s.id))
+		recvInfoAddr := (**C.ucp_tag_recv_info_t)(unsafe.Pointer(&r.params.recv_info[0]))
+		*recvInfoAddr = s.cInfo
+
+		request := C.ucp_tag_recv_nbx(r.worker.worker, s.buffer, C.size_t(r.pool.BufferSize()),
+			C.ucp_tag_t(r.tag), C.ucp_tag_t(r.tagMask), r.params)
+		if isRequestPtr(request) {
+			s.request = unsafe.Pointer(uintptr(request))
+			return
+		}
+
+		status := UcsStatus(int64(uintptr(request)))
+		r.deliver(s, status)
+		if status != UCS_OK && status != UCS_ERR_MESSAGE_TRUNCATED {
+			break
+		}
+	}
+
+	r.release(s)
+}
+
+func (r *UcpTagPersistentRecv) deliver(s *persistentRecvSlot, status UcsStatus) {
+	s.info.SenderTag = uint64(s.cInfo.sender_tag)
+	s.info.Length = uint64(s.cInfo.length)
+	r.callback(s.buffer, &s.info, status)
+}
+
+func (r *UcpTagPersistentRecv) complete(s *persistentRecvSlot, status UcsStatus,
+	info *C.ucp_tag_recv_info_t) {
+	s.request = nil
+	if status == UCS_ERR_CANCELED || r.closed {
+		r.release(s)
+		return
+	}
+
+	*s.cInfo = *info
+	r.deliver(s, status)
+	r.post(s)
+}
+
+// Returns the slot buffer to the pool, once the slot is not reposted anymore.
+func (r *UcpTagPersistentRecv) release(s *persistentRecvSlot) {
+	if s.buffer != nil {
+		r.pool.Put(s.buffer)
+		s.buffer = nil
+		r.active--
+	}
+}
+
+// Returns the number of posted receives.
+func (r *UcpTagPersistentRecv) Active() int {
+	return r.active
+}
+
+// This routine cancels all posted receives, progresses the worker until the
+// cancellation is completed and returns the buffers to the pool. It must not
+// be called from the persistent receive callback.
+func (r *UcpTagPersistentRecv) Close() {
+	if r.closed {
+		return
+	}
+	r.closed = true
+
+	for _, slot := range r.slots {
+		if slot.request != nil {
+			C.ucp_request_cancel(r.worker.worker, slot.request)
+		}
+	}
+
+	for r.active > 0 {
+		r.worker.Progress()
+	}
+
+	for _, slot := range r.slots {
+		deregister(slot.id)
+		FreeNativeMemory(unsafe.Pointer(slot.cInfo))
+	}
+	FreeNativeMemory(unsafe.Pointer(r.params))
+	r.slots = nil
+}
@@ -0,0 +1,177 @@
+//go:build linux
+// +build linux
+
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package ucx
+
+// #include <ucp/api/ucp.h>
+// #include "goucx.h"
+import "C"
+import (
+	"unsafe"
+)
+
+// This callback routine is invoked once the submission of the persistent
+// request is completed, so the buffer can be updated for the next one.
+type UcpPersistentSendCallback = func(request *UcpPersistentRequest, status UcsStatus)
+
+// Send, that is built once and submitted repeatedly by
+// UcpPersistentRequest.Resubmit(), e.g. the heartbeat or the telemetry
+// message of the fixed size. The native params and the handle of the callback
+// are allocated once, so the submission doesn't marshal the params or
+// allocate memory. At most one submission is in progress at a time. All the
+// routines must be called from the thread, that progresses the worker.
+type UcpPersistentRequest struct {
+	ep         *UcpEp
+	am         bool
+	tag        uint64
+	amId       uint
+	header     unsafe.Pointer
+	headerSize uint64
+	address    unsafe.Pointer
+	size       uint64
+	callback   UcpPersistentSendCallback
+	params     *C.ucp_request_param_t
+	id         uint64
+	request    unsafe.Pointer
+	status     UcsStatus
+}
+
+// Allocates the native params of the operation from the memory type, the
+// memory and the operation flags of params, the rest of them are ignored.
+func (e *UcpEp) newPersistentRequest(feature UcpFeatures, params *UcpRequestParams,
+	cb UcpPersistentSendCallback) (*UcpPersistentRequest, error) {
+	if _, err := checkRequestFeature(e.worker, feature, params); err != nil {
+		return nil, err
+	}
+
+	r := &UcpPersistentRequest{
+		ep:       e,
+		callback: cb,
+		params:   (*C.ucp_request_param_t)(AllocateNativeMemory(C.sizeof_ucp_request_param_t)),
+		status:   UCS_OK,
+	}
+
+	*r.params = C.ucp_request_param_t{}
+	setCommonParams(params, r.params)
+	r.params.op_attr_mask |= C.UCP_OP_ATTR_FIELD_CALLBACK | C.UCP_OP_ATTR_FIELD_USER_DATA
+	cbAddr := (*C.ucp_send_nbx_callback_t)(unsafe.Pointer(&r.params.cb[0]))
+	*cbAddr = (C.ucp_send_nbx_callback_t)(C.ucxgo_persistent_send_cb)
+	r.id = register(r)
+	C.ucxgo_request_param_set_user_data(r.params, C.uint64_t(r.id))
+	return r, nil
+}
+
+// This routine builds the persistent request, that sends the tag message of
+// the buffer on every submission, same as UcpEp.SendTagNonBlocking(). The
+// buffer must stay valid until the request is closed, and may be updated
+// while no submission is in progress. cb may be nil.
+func (e *UcpEp) NewPersistentSendTag(tag uint64, address unsafe.Pointer, size uint64,
+	params *UcpRequestParams, cb UcpPersistentSendCallback) (*UcpPersistentRequest, error) {
+	r, err := e.newPersistentRequest(UCP_FEATURE_TAG, params, cb)
+	if err != nil {
+		return nil, err
+	}
+
+	r.tag = tag
+	r.address = address
+	r.size = size
+	return r, nil
+}
+
+// This routine builds the persistent request, that sends the Active Message of
+// the header and the data on every submission, same as
+// UcpEp.SendAmNonBlocking(). Both buffers must stay valid until the request is
+// closed, same as for NewPersistentSendTag().
+func (e *UcpEp) NewPersistentSendAm(id uint, header unsafe.Pointer, headerSize uint64, data unsafe.Pointer,
+	dataSize uint64, flags UcpAmSendFlags, params *UcpRequestParams,
+	cb UcpPersistentSendCallback) (*UcpPersistentRequest, error) {
+	r, err := e.newPersistentRequest(UCP_FEATURE_AM, params, cb)
+	if err != nil {
+		return nil, err
+	}
+
+	r.params.op_attr_mask |= C.UCP_OP_ATTR_FIELD_FLAGS
+	r.params.flags = C.uint(flags)
+	r.am = true
+	r.amId = id
+	r.header = header
+	r.headerSize = headerSize
+	r.address = data
+	r.size = dataSize
+	return r, nil
+}
+
+// This routine submits the operation of the request again. The callback is
+// invoked once the submission completes, including the immediate completion,
+// when it's invoked by this routine. Fails with ErrBusy, if the previous
+// submission is still in progress, and with ErrInvalidParam, once the request
+// is closed.
+func (r *UcpPersistentRequest) Resubmit() error {
+	if r.params == nil {
+		return ErrInvalidParam
+	}
+
+	if r.request != nil {
+		return ErrBusy
+	}
+
+	var request C.ucs_status_ptr_t
+	if r.am {
+		request = C.ucp_am_send_nbx(r.ep.ep, C.uint(r.amId), r.header, C.size_t(r.headerSize), r.address,
+			C.size_t(r.size), r.params)
+	} else {
+		request = C.ucp_tag_send_nbx(r.ep.ep, r.address, C.size_t(r.size), C.ucp_tag_t(r.tag), r.params)
+	}
+
+	if isRequestPtr(request) {
+		r.request = unsafe.Pointer(uintptr(request))
+		r.status = UCS_INPROGRESS
+		return nil
+	}
+
+	r.complete(UcsStatus(int64(uintptr(request))))
+	if r.status != UCS_OK {
+		return NewUcxError(r.status)
+	}
+	return nil
+}
+
+func (r *UcpPersistentRequest) complete(status UcsStatus) {
+	r.request = nil
+	r.status = status
+	if r.callback != nil {
+		r.callback(r, status)
+	}
+}
+
+// Returns the status of the last submission, UCS_INPROGRESS while it's in
+// progress, or UCS_OK before the first one.
+func (r *UcpPersistentRequest) GetStatus() UcsStatus {
+	return r.status
+}
+
+// This routine cancels the submission in progress, progresses the worker
+// until it's completed, and releases the request. It must not be called from
+// the callback of the request.
+func (r *UcpPersistentRequest) Close() {
+	if r.params == nil {
+		return
+	}
+
+	if r.request != nil {
+		C.ucp_request_cancel(r.ep.worker, r.request)
+	}
+
+	for r.request != nil {
+		progressWorker(r.ep.worker)
+	}
+
+	deregister(r.id)
+	FreeNativeMemory(unsafe.Pointer(r.params))
+	r.params = nil
+}
@@ -0,0 +1,39 @@
+//go:build go1.21
+// +build go1.21
+
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package ucx
+
+import (
+	"runtime"
+	"unsafe"
+)
+
+// Pins the slice memory, so it can be accessed by the library after the call
+// returns. Returns the address of the memory and the routine, that unpins it.
+func pinBytes(data []byte) (unsafe.Pointer, func()) {
+	if len(data) == 0 {
+		return nil, func() {}
+	}
+
+	var pinner runtime.Pinner
+	pinner.Pin(&data[0])
+	return unsafe.Pointer(&data[0]), pinner.Unpin
+}
+
+// Pins the slice memory, that the library receives to.
+func pinRecvBytes(data []byte) (unsafe.Pointer, func()) {
+	return pinBytes(data)
+}
+
+// Pins the backing array of the slice, that is registered by the memory cache,
+// until the returned routine is called.
+func pinSlice(data []byte) func() {
+	var pinner runtime.Pinner
+	pinner.Pin(&data[0])
+	return pinner.Unpin
+}
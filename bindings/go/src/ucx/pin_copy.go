@@ -0,0 +1,47 @@
+//go:build linux && !go1.21
+// +build linux,!go1.21
+
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package ucx
+
+// #include <stdlib.h>
+import "C"
+import (
+	"unsafe"
+)
+
+// Go memory can't be pinned before Go 1.21, so the slice is copied to the
+// native memory. Returns the address of the copy and the routine, that frees it.
+func pinBytes(data []byte) (unsafe.Pointer, func()) {
+	if len(data) == 0 {
+		return nil, func() {}
+	}
+
+	address := C.CBytes(data)
+	return address, func() { C.free(address) }
+}
+
+// The slice is received to the native memory, which is copied back to the
+// slice and freed by the returned routine, once the receive completes.
+func pinRecvBytes(data []byte) (unsafe.Pointer, func()) {
+	if len(data) == 0 {
+		return nil, func() {}
+	}
+
+	address := C.malloc(C.size_t(len(data)))
+	return address, func() {
+		copy(data, (*[1 << 40]byte)(address)[:len(data):len(data)])
+		C.free(address)
+	}
+}
+
+// Go memory can't be pinned before Go 1.21, so the backing array of the slice,
+// that is registered by the memory cache, is only kept reachable by the cache,
+// since the GC doesn't move the heap objects.
+func pinSlice(data []byte) func() {
+	return func() {}
+}
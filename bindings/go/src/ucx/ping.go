@@ -0,0 +1,170 @@
+//go:build linux
+// +build linux
+
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package ucx
+
+// #include <ucp/api/ucp.h>
+import "C"
+import (
+	"encoding/binary"
+	"sync"
+	"time"
+	"unsafe"
+)
+
+// Header of the ping messages: the kind and the sequence number of the ping.
+const (
+	pingRequest    byte = 1
+	pingReply      byte = 2
+	pingHeaderSize      = 9
+)
+
+// Active Message handler of the pings of the worker, which tracks the pings
+// waiting for the replies.
+type pingService struct {
+	id      uint
+	mu      sync.Mutex
+	seq     uint64
+	replied map[uint64]bool
+}
+
+var pingServicesMu sync.Mutex
+
+// Ping services of the workers, that enabled the ping.
+var pingServices = make(map[C.ucp_worker_h]*pingService)
+
+func getPingService(worker C.ucp_worker_h) *pingService {
+	pingServicesMu.Lock()
+	defer pingServicesMu.Unlock()
+	return pingServices[worker]
+}
+
+func removePingService(worker C.ucp_worker_h) {
+	pingServicesMu.Lock()
+	defer pingServicesMu.Unlock()
+	delete(pingServices, worker)
+}
+
+// This routine installs the Active Message handler of the id, that replies to
+// the pings of the peers and receives the replies to UcpEp.Ping() of this
+// worker. The peers must enable the ping with the same id. The context must be
+// created with UcpParams.EnableAM().
+func (w *UcpWorker) EnablePing(id uint) error {
+	service := &pingService{id: id, replied: make(map[uint64]bool)}
+	if err := w.SetAmRecvHandler(id, UCP_AM_FLAG_WHOLE_MSG, service.handle); err != nil {
+		return err
+	}
+
+	pingServicesMu.Lock()
+	defer pingServicesMu.Unlock()
+	pingServices[w.worker] = service
+	return nil
+}
+
+func (s *pingService) handle(header unsafe.Pointer, headerSize uint64, data *UcpAmData,
+	replyEp *UcpEp) UcsStatus {
+	if headerSize != pingHeaderSize {
+		return UCS_OK
+	}
+
+	pingHeader := AmHeader(header, headerSize)
+	seq := binary.LittleEndian.Uint64(pingHeader[1:])
+	switch pingHeader[0] {
+	case pingRequest:
+		// The reply isn't waited for, the peer retries on the timeout
+		if replyEp != nil {
+			if request, _ := sendPing(replyEp, s.id, pingReply, seq, 0); request != nil {
+				request.Close()
+			}
+		}
+	case pingReply:
+		s.mu.Lock()
+		if _, found := s.replied[seq]; found {
+			s.replied[seq] = true
+		}
+		s.mu.Unlock()
+	}
+	return UCS_OK
+}
+
+func (s *pingService) start() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.seq++
+	s.replied[s.seq] = false
+	return s.seq
+}
+
+func (s *pingService) isReplied(seq uint64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.replied[seq]
+}
+
+func (s *pingService) finish(seq uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.replied, seq)
+}
+
+// Sends the zero-byte message with the copied header, so the header is freed
+// right away.
+func sendPing(ep *UcpEp, id uint, kind byte, seq uint64, flags UcpAmSendFlags) (*UcpRequest, error) {
+	header := AllocateNativeMemory(pingHeaderSize)
+	defer FreeNativeMemory(header)
+
+	pingHeader := (*[pingHeaderSize]byte)(header)
+	pingHeader[0] = kind
+	binary.LittleEndian.PutUint64(pingHeader[1:], seq)
+	return ep.SendAmNonBlocking(id, header, pingHeaderSize, nil, 0,
+		flags|UCP_AM_SEND_FLAG_COPY_HEADER, nil)
+}
+
+// This routine checks that the peer of the endpoint is alive, so the dead
+// peer is detected before the data is sent to it. It sends the zero-byte
+// Active Message, and progresses the worker until the peer replies, and
+// returns the round trip time. Both workers must enable the ping by
+// UcpWorker.EnablePing() with the same id, otherwise it fails with
+// ErrInvalidParam locally or ErrTimedOut on the peer. It fails with the error
+// of the send, e.g. once the failure is detected by the keepalive (see
+// UcpConfig.SetKeepaliveInterval()), and with ErrTimedOut, if the peer
+// doesn't reply in time. The routine must not be called concurrently with
+// the other routines progressing the worker, unless the thread mode of the
+// worker is UCS_THREAD_MODE_MULTI.
+func (e *UcpEp) Ping(timeout time.Duration) (time.Duration, error) {
+	service := getPingService(e.worker)
+	if service == nil {
+		return 0, ErrInvalidParam
+	}
+
+	seq := service.start()
+	defer service.finish(seq)
+
+	start := time.Now()
+	request, err := sendPing(e, service.id, pingRequest, seq, UCP_AM_SEND_FLAG_REPLY)
+	defer request.Close()
+	if err != nil {
+		return 0, err
+	}
+
+	deadline := start.Add(timeout)
+	for {
+		if service.isReplied(seq) {
+			return time.Since(start), nil
+		}
+
+		if status := request.GetStatus(); (status != UCS_OK) && (status != UCS_INPROGRESS) {
+			return 0, NewUcxError(status)
+		}
+
+		if time.Now().After(deadline) {
+			return 0, ErrTimedOut
+		}
+		progressWorker(e.worker)
+	}
+}
@@ -0,0 +1,458 @@
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package ucx
+
+import (
+	"errors"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Options of the progress engine. The yield is the number of the completions,
+// that a progress call of the worker reports on average during the interval.
+// The worker, that is polled less often than its events arrive, accumulates
+// them between the calls, so its yield grows. The zero fields are set to
+// the defaults.
+type UcpProgressEngineOptions struct {
+	// Bounds of the number of the progress threads, 1 and
+	// runtime.GOMAXPROCS(0) by default. The engine doesn't run more threads,
+	// than it has workers.
+	MinThreads int
+	MaxThreads int
+
+	// Interval of the yield measurement, 100ms by default.
+	Interval time.Duration
+
+	// A thread is added once the yield of any worker exceeds ScaleUpYield, 1
+	// by default, and removed once the yields of all the workers are below
+	// ScaleDownYield, 0.1 by default.
+	ScaleUpYield   float64
+	ScaleDownYield float64
+
+	// Number of the consecutive intervals, that have to satisfy the
+	// condition, before the threads are added or removed, 3 by default.
+	Hysteresis int
+}
+
+func (o UcpProgressEngineOptions) withDefaults() UcpProgressEngineOptions {
+	if o.MinThreads <= 0 {
+		o.MinThreads = 1
+	}
+
+	if o.MaxThreads <= 0 {
+		o.MaxThreads = runtime.GOMAXPROCS(0)
+	}
+
+	if o.MaxThreads < o.MinThreads {
+		o.MaxThreads = o.MinThreads
+	}
+
+	if o.Interval <= 0 {
+		o.Interval = 100 * time.Millisecond
+	}
+
+	if o.ScaleUpYield <= 0 {
+		o.ScaleUpYield = 1
+	}
+
+	if o.ScaleDownYield <= 0 {
+		o.ScaleDownYield = 0.1
+	}
+
+	if o.Hysteresis <= 0 {
+		o.Hysteresis = 3
+	}
+	return o
+}
+
+// Worker of the engine and its progress counters.
+type engineWorker struct {
+	worker *UcpWorker
+	// Set while a thread progresses the worker or UcpProgressEngine.Execute()
+	// runs, and left set once the worker is removed
+	busy        int32
+	removed     int32
+	polls       uint64
+	completions uint64
+	// Accessed only under the engine lock
+	lastPolls       uint64
+	lastCompletions uint64
+	yield           float64
+	load            uint64
+}
+
+// Waits until the threads don't progress the worker. Returns false, if the
+// worker is removed.
+func (w *engineWorker) acquire() bool {
+	for !atomic.CompareAndSwapInt32(&w.busy, 0, 1) {
+		if atomic.LoadInt32(&w.removed) != 0 {
+			return false
+		}
+		runtime.Gosched()
+	}
+	return true
+}
+
+func (w *engineWorker) release() {
+	atomic.StoreInt32(&w.busy, 0)
+}
+
+// Progress thread and the workers, that are assigned to it.
+type engineThread struct {
+	workers atomic.Value
+	quit    chan struct{}
+	exited  chan struct{}
+}
+
+func (t *engineThread) run() {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	defer close(t.exited)
+
+	state := newWaitBackoffState(DefaultUcpWaitBackoff)
+	for {
+		select {
+		case <-t.quit:
+			return
+		default:
+		}
+
+		var events uint
+		for _, w := range t.workers.Load().([]*engineWorker) {
+			// The worker is moved to another thread or removed
+			if !atomic.CompareAndSwapInt32(&w.busy, 0, 1) {
+				continue
+			}
+
+			n := w.worker.Progress()
+			atomic.AddUint64(&w.polls, 1)
+			atomic.AddUint64(&w.completions, uint64(n))
+			w.release()
+			events += n
+		}
+
+		if events != 0 {
+			state.reset()
+		} else {
+			state.wait()
+		}
+	}
+}
+
+// Progress engine progresses multiple workers on a varying number of
+// goroutines, which are locked to their OS threads. Every worker is progressed
+// by one thread at a time, and the workers are balanced between the threads by
+// their completions. The engine measures the yield of the workers, and adds
+// or removes the threads within the bounds of UcpProgressEngineOptions, once
+// the yield stays beyond the thresholds for several intervals.
+//
+// The workers move between the OS threads, so they must be created with
+// UCS_THREAD_MODE_SERIALIZED or UCS_THREAD_MODE_MULTI, and the operations on
+// the serialized workers have to be submitted by UcpProgressEngine.Execute().
+// Same as for UcpProgressLoop, the callbacks of the workers are invoked from
+// the engine threads, and the workers must not be progressed by anyone else.
+type UcpProgressEngine struct {
+	options UcpProgressEngineOptions
+	mu      sync.Mutex
+	workers []*engineWorker
+	threads []*engineThread
+	// Consecutive intervals above or below the thresholds
+	upIntervals   int
+	downIntervals int
+	stopped       bool
+	quit          chan struct{}
+	exited        chan struct{}
+	stopOnce      sync.Once
+}
+
+var errProgressEngineStopped = errors.New("progress engine is stopped")
+
+// This routine creates the progress engine of the workers with the minimal
+// number of the threads. opts may be nil to use the default options. More
+// workers can be added later by UcpProgressEngine.Add().
+func NewProgressEngine(workers []*UcpWorker, opts *UcpProgressEngineOptions) (*UcpProgressEngine, error) {
+	var options UcpProgressEngineOptions
+	if opts != nil {
+		options = *opts
+	}
+
+	e := &UcpProgressEngine{
+		options: options.withDefaults(),
+		quit:    make(chan struct{}),
+		exited:  make(chan struct{}),
+	}
+	go e.monitor()
+
+	for _, worker := range workers {
+		if err := e.Add(worker); err != nil {
+			e.Stop()
+			return nil, err
+		}
+	}
+	return e, nil
+}
+
+// Number of the threads, that the engine runs for its workers.
+func (e *UcpProgressEngine) targetThreads(threads int) int {
+	if threads > e.options.MaxThreads {
+		threads = e.options.MaxThreads
+	}
+
+	if threads > len(e.workers) {
+		threads = len(e.workers)
+	}
+
+	if (threads < e.options.MinThreads) && (len(e.workers) != 0) {
+		threads = e.options.MinThreads
+		if threads > len(e.workers) {
+			threads = len(e.workers)
+		}
+	}
+	return threads
+}
+
+// Starts or stops the threads to reach the number, and assigns every worker to
+// the least loaded thread, starting from the most loaded workers. Must be
+// called under the engine lock.
+func (e *UcpProgressEngine) rebalance(threads int) {
+	threads = e.targetThreads(threads)
+	for len(e.threads) < threads {
+		t := &engineThread{quit: make(chan struct{}), exited: make(chan struct{})}
+		t.workers.Store([]*engineWorker(nil))
+		e.threads = append(e.threads, t)
+		go t.run()
+	}
+
+	removed := e.threads[threads:]
+	e.threads = e.threads[:threads]
+
+	workers := append([]*engineWorker(nil), e.workers...)
+	sort.SliceStable(workers, func(i, j int) bool { return workers[i].load > workers[j].load })
+
+	assigned := make([][]*engineWorker, len(e.threads))
+	loads := make([]uint64, len(e.threads))
+	for _, w := range workers {
+		min := 0
+		for i := range loads {
+			if (loads[i] < loads[min]) || ((loads[i] == loads[min]) && (len(assigned[i]) < len(assigned[min]))) {
+				min = i
+			}
+		}
+		assigned[min] = append(assigned[min], w)
+		loads[min] += w.load
+	}
+
+	for i, t := range e.threads {
+		t.workers.Store(assigned[i])
+	}
+
+	// The workers of the removed threads are already progressed by the others
+	for _, t := range removed {
+		close(t.quit)
+		<-t.exited
+	}
+}
+
+// Measures the yield of the workers every interval, and scales the threads.
+func (e *UcpProgressEngine) monitor() {
+	defer close(e.exited)
+	ticker := time.NewTicker(e.options.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-e.quit:
+			return
+		case <-ticker.C:
+		}
+
+		e.mu.Lock()
+		e.measure()
+		e.mu.Unlock()
+	}
+}
+
+// Must be called under the engine lock.
+func (e *UcpProgressEngine) measure() {
+	if len(e.workers) == 0 {
+		return
+	}
+
+	up, down := false, true
+	for _, w := range e.workers {
+		polls := atomic.LoadUint64(&w.polls)
+		completions := atomic.LoadUint64(&w.completions)
+		w.load = completions - w.lastCompletions
+		w.yield = 0
+		if polls != w.lastPolls {
+			w.yield = float64(w.load) / float64(polls-w.lastPolls)
+		}
+		w.lastPolls, w.lastCompletions = polls, completions
+
+		up = up || (w.yield > e.options.ScaleUpYield)
+		down = down && (w.yield < e.options.ScaleDownYield)
+	}
+
+	if up {
+		e.upIntervals++
+	} else {
+		e.upIntervals = 0
+	}
+
+	if down {
+		e.downIntervals++
+	} else {
+		e.downIntervals = 0
+	}
+
+	threads := len(e.threads)
+	if e.upIntervals >= e.options.Hysteresis {
+		threads++
+	} else if e.downIntervals >= e.options.Hysteresis {
+		threads--
+	} else {
+		return
+	}
+
+	e.upIntervals, e.downIntervals = 0, 0
+	if e.targetThreads(threads) != len(e.threads) {
+		e.rebalance(threads)
+	}
+}
+
+// Returns the number of the threads, that progress the workers.
+func (e *UcpProgressEngine) Threads() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return len(e.threads)
+}
+
+// Returns the yield of the worker during the last interval, or 0 if the
+// engine doesn't progress it.
+func (e *UcpProgressEngine) Yield(worker *UcpWorker) float64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, w := range e.workers {
+		if w.worker == worker {
+			return w.yield
+		}
+	}
+	return 0
+}
+
+// This routine adds the worker to the engine, which starts progressing it.
+// The worker must not be progressed by anyone else, including progress loops
+// and worker sets.
+func (e *UcpProgressEngine) Add(worker *UcpWorker) error {
+	if worker.ThreadMode() == UCS_THREAD_MODE_SINGLE {
+		return ErrInvalidParam
+	}
+
+	e.mu.Lock()
+	if e.stopped {
+		e.mu.Unlock()
+		return errProgressEngineStopped
+	}
+
+	e.workers = append(e.workers, &engineWorker{worker: worker})
+	e.rebalance(len(e.threads))
+	e.mu.Unlock()
+
+	worker.resourcesMu.Lock()
+	worker.progressLoop = e
+	worker.resourcesMu.Unlock()
+	return nil
+}
+
+func (e *UcpProgressEngine) find(worker *UcpWorker) *engineWorker {
+	for _, w := range e.workers {
+		if w.worker == worker {
+			return w
+		}
+	}
+	return nil
+}
+
+// This routine removes the worker from the engine, and waits until the
+// threads don't progress it. The worker has to be removed before it's closed.
+// It must not be called from the worker callbacks.
+func (e *UcpProgressEngine) Remove(worker *UcpWorker) error {
+	e.mu.Lock()
+	w := e.find(worker)
+	if w == nil {
+		e.mu.Unlock()
+		return ErrInvalidParam
+	}
+
+	for i := range e.workers {
+		if e.workers[i] == w {
+			e.workers = append(e.workers[:i], e.workers[i+1:]...)
+			break
+		}
+	}
+	e.rebalance(len(e.threads))
+	e.mu.Unlock()
+
+	// The busy flag is never released, so the threads skip the worker
+	atomic.StoreInt32(&w.removed, 1)
+	for !atomic.CompareAndSwapInt32(&w.busy, 0, 1) {
+		runtime.Gosched()
+	}
+
+	worker.resourcesMu.Lock()
+	if worker.progressLoop == progressDriver(e) {
+		worker.progressLoop = nil
+	}
+	worker.resourcesMu.Unlock()
+	return nil
+}
+
+func (e *UcpProgressEngine) detach(worker *UcpWorker) {
+	e.Remove(worker)
+}
+
+// This routine executes f, while none of the threads progresses the worker,
+// so the operations on the worker created with UCS_THREAD_MODE_SERIALIZED can
+// be called from any goroutine. f is executed on the calling goroutine. It
+// must not be called from the worker callbacks.
+func (e *UcpProgressEngine) Execute(worker *UcpWorker, f func()) error {
+	e.mu.Lock()
+	w := e.find(worker)
+	e.mu.Unlock()
+	if (w == nil) || !w.acquire() {
+		return ErrInvalidParam
+	}
+	defer w.release()
+	f()
+	return nil
+}
+
+// This routine stops all the threads and the monitor, and waits for them to
+// exit. It must not be called from the worker callbacks.
+func (e *UcpProgressEngine) Stop() {
+	e.stopOnce.Do(func() {
+		close(e.quit)
+	})
+	<-e.exited
+
+	e.mu.Lock()
+	e.stopped = true
+	workers := e.workers
+	e.workers = nil
+	e.rebalance(0)
+	e.mu.Unlock()
+
+	for _, w := range workers {
+		w.worker.resourcesMu.Lock()
+		if w.worker.progressLoop == progressDriver(e) {
+			w.worker.progressLoop = nil
+		}
+		w.worker.resourcesMu.Unlock()
+	}
+}
@@ -0,0 +1,196 @@
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package ucx
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+)
+
+type UcpProgressMode int
+
+const (
+	// Progress the worker continuously. Gives the lowest latency at the cost
+	// of a fully occupied CPU core.
+	UcpProgressModePoll UcpProgressMode = iota
+	// Sleep on the worker event file descriptor, when there is nothing to
+	// progress. Requires the context created with UcpParams.EnableWakeup().
+	UcpProgressModeEvent
+)
+
+// Tuning parameters for the progress loop.
+type UcpProgressLoopParams struct {
+	mode    UcpProgressMode
+	pinCpus bool
+}
+
+// Mode of the progress loop, UcpProgressModePoll by default.
+func (p *UcpProgressLoopParams) SetMode(mode UcpProgressMode) *UcpProgressLoopParams {
+	p.mode = mode
+	return p
+}
+
+// Restricts the OS thread of the loop to the CPUs of the worker, which are set
+// by UcpWorkerParams.SetCpus(), so the worker is progressed on the same NUMA
+// node, where its resources are allocated. See LockOSThreadToCpus().
+func (p *UcpProgressLoopParams) PinToWorkerCpus() *UcpProgressLoopParams {
+	p.pinCpus = true
+	return p
+}
+
+// Progress loop runs the worker progress on a dedicated goroutine, which is
+// locked to its OS thread. All the callbacks of the worker operations are
+// invoked from this goroutine, so they must not block waiting for the progress.
+// The worker must not be progressed by anyone else while the loop runs. Other
+// operations on the worker can be called from any goroutine only if the worker
+// thread mode is UCS_THREAD_MODE_MULTI, otherwise they have to be submitted to
+// the loop by UcpProgressLoop.Execute().
+type UcpProgressLoop struct {
+	worker   *UcpWorker
+	mode     UcpProgressMode
+	tasks    chan func()
+	quit     chan struct{}
+	exited   chan struct{}
+	stopOnce sync.Once
+}
+
+// This routine starts the progress loop of the worker. params may be nil to
+// use the default parameters.
+func (w *UcpWorker) StartProgressLoop(params *UcpProgressLoopParams) (*UcpProgressLoop, error) {
+	if params == nil {
+		params = &UcpProgressLoopParams{}
+	}
+
+	// Fail early, if the worker does not support the wake-up mechanism
+	if params.mode == UcpProgressModeEvent {
+		if _, err := w.getEfdFile(); err != nil {
+			return nil, err
+		}
+	}
+
+	if params.pinCpus && (len(w.cpus) == 0) {
+		return nil, ErrInvalidParam
+	}
+
+	loop := &UcpProgressLoop{
+		worker: w,
+		mode:   params.mode,
+		tasks:  make(chan func(), 64),
+		quit:   make(chan struct{}),
+		exited: make(chan struct{}),
+	}
+
+	w.resourcesMu.Lock()
+	w.progressLoop = loop
+	w.resourcesMu.Unlock()
+
+	started := make(chan error, 1)
+	go loop.run(params.pinCpus, started)
+	if err := <-started; err != nil {
+		w.resourcesMu.Lock()
+		w.progressLoop = nil
+		w.resourcesMu.Unlock()
+		return nil, err
+	}
+	return loop, nil
+}
+
+func (l *UcpProgressLoop) run(pinCpus bool, started chan<- error) {
+	if pinCpus {
+		// The pinned thread is terminated with the goroutine
+		if err := LockOSThreadToCpus(l.worker.cpus); err != nil {
+			started <- err
+			return
+		}
+	} else {
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+	}
+	started <- nil
+	defer close(l.exited)
+
+	for {
+		select {
+		case task := <-l.tasks:
+			task()
+			continue
+		case <-l.quit:
+			l.drain()
+			return
+		default:
+		}
+
+		if (l.worker.Progress() != 0) || (l.mode == UcpProgressModePoll) {
+			continue
+		}
+
+		if l.worker.WaitEvents() != nil {
+			return
+		}
+	}
+}
+
+// Executes tasks, that were submitted before the loop was stopped.
+func (l *UcpProgressLoop) drain() {
+	for {
+		select {
+		case task := <-l.tasks:
+			task()
+		default:
+			return
+		}
+	}
+}
+
+var errProgressLoopStopped = errors.New("progress loop is stopped")
+
+// This routine executes f on the progress loop goroutine and waits for its
+// completion. This way operations on the worker created with
+// UCS_THREAD_MODE_SINGLE or UCS_THREAD_MODE_SERIALIZED can be submitted from
+// any goroutine. It returns an error, if the loop was stopped before f was
+// executed. It must not be called from the worker callbacks.
+func (l *UcpProgressLoop) Execute(f func()) error {
+	done := make(chan struct{})
+
+	select {
+	case l.tasks <- func() { f(); close(done) }:
+	case <-l.exited:
+		return errProgressLoopStopped
+	}
+
+	if l.mode == UcpProgressModeEvent {
+		l.worker.Signal()
+	}
+
+	select {
+	case <-done:
+		return nil
+	case <-l.exited:
+		select {
+		case <-done:
+			return nil
+		default:
+			return errProgressLoopStopped
+		}
+	}
+}
+
+func (l *UcpProgressLoop) detach(worker *UcpWorker) {
+	l.Stop()
+}
+
+// This routine stops the progress loop and waits for its goroutine to exit.
+// It must not be called from the worker callbacks.
+func (l *UcpProgressLoop) Stop() {
+	l.stopOnce.Do(func() {
+		close(l.quit)
+		if l.mode == UcpProgressModeEvent {
+			l.worker.Signal()
+		}
+	})
+	<-l.exited
+}
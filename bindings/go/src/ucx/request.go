@@ -1,3 +1,6 @@
+//go:build linux
+// +build linux
+
 /*
  * Copyright (C) 2021, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
  * See file LICENSE for terms.
@@ -9,31 +12,204 @@ package ucx
 // #include "goucx.h"
 import "C"
 import (
+	"context"
+	"sync"
+	"time"
 	"unsafe"
 )
 
+// Request of the non-blocking operation. The operation, that is completed
+// immediately and has no callback, done channel and user data, returns nil
+// request, whose methods report the completed operation: GetStatus() returns
+// UCS_OK, the waits return nil right away, and Close() does nothing.
 type UcpRequest struct {
 	request unsafe.Pointer
-	Status  UcsStatus
+	worker  C.ucp_worker_h
+	done    chan UcsStatus
+	// Handle of the callback of the request in progress
+	callbackId uint64
+	// Receive with the truncation policy, see UcpRequestParams.SetTruncationPolicy()
+	probed *probedRecv
+	// See UcpRequestParams.SetUserData()
+	userData interface{}
+	Status   UcsStatus
+}
+
+// Pool of the request params, that are allocated by every non-blocking
+// operation, since they are passed to C. The pool is emptied by the GC, so
+// the params reserved by UcpWorkerParams.SetRequestPoolSize() are kept in the
+// free list, that is taken first, and whose capacity is the sum of the pool
+// sizes of the workers, that are not closed.
+var requestParamsPool = sync.Pool{
+	New: func() interface{} { return &C.ucp_request_param_t{} },
+}
+
+var reservedParams struct {
+	sync.Mutex
+	free     []*C.ucp_request_param_t
+	capacity int
+}
+
+// Reserves count request params for the worker, see
+// UcpWorkerParams.SetRequestPoolSize().
+func reserveRequestParams(count int) {
+	reservedParams.Lock()
+	defer reservedParams.Unlock()
+	reservedParams.capacity += count
+	for i := 0; i < count; i++ {
+		reservedParams.free = append(reservedParams.free, &C.ucp_request_param_t{})
+	}
+}
+
+// Releases the reserve of the closed worker.
+func releaseRequestParams(count int) {
+	reservedParams.Lock()
+	defer reservedParams.Unlock()
+	reservedParams.capacity -= count
+	if len(reservedParams.free) > reservedParams.capacity {
+		for i := reservedParams.capacity; i < len(reservedParams.free); i++ {
+			reservedParams.free[i] = nil
+		}
+		reservedParams.free = reservedParams.free[:reservedParams.capacity]
+	}
+}
+
+// Returns zeroed request params. The params are used only during the
+// operation call, so they are released right after it.
+func getRequestParams() *C.ucp_request_param_t {
+	var params *C.ucp_request_param_t
+	reservedParams.Lock()
+	if last := len(reservedParams.free) - 1; last >= 0 {
+		params = reservedParams.free[last]
+		reservedParams.free[last] = nil
+		reservedParams.free = reservedParams.free[:last]
+	}
+	reservedParams.Unlock()
+
+	if params == nil {
+		params = requestParamsPool.Get().(*C.ucp_request_param_t)
+	}
+	*params = C.ucp_request_param_t{}
+	return params
+}
+
+func putRequestParams(params *C.ucp_request_param_t) {
+	reservedParams.Lock()
+	if len(reservedParams.free) < reservedParams.capacity {
+		reservedParams.free = append(reservedParams.free, params)
+		params = nil
+	}
+	reservedParams.Unlock()
+
+	if params != nil {
+		requestParamsPool.Put(params)
+	}
 }
 
 type UcpRequestParams struct {
-	memTypeSet bool
-	memType    UcsMemoryType
-	Cb         UcpCallback
+	memTypeSet  bool
+	memType     UcsMemoryType
+	memory      *UcpMemory
+	memoryCache *UcpMemoryCache
+	replyBuffer unsafe.Pointer
+	recvFlags   UcpStreamRecvFlags
+	opFlags     UcpOpAttrFlags
+	doneChannel bool
+	deadline    time.Time
+	release     func()
+	transfer    *transferRecord
+	truncation  UcpTruncationPolicy
+	// See UcpRequestParams.SetRetryPolicy()
+	maxRetries   int
+	retryBackoff time.Duration
+	// Parent of the span of the traced operation
+	traceContext context.Context
+	userData     interface{}
+	Cb           UcpCallback
 }
 
+// Memory type of the operation buffer, e.g. UCS_MEMORY_TYPE_CUDA. The library
+// detects the memory type of every buffer otherwise, which costs a lookup on
+// every operation, so the hint is worth setting on the hot paths, that use the
+// buffers of the known memory type. The type must match the buffer; the
+// detection is still done for UCS_MEMORY_TYPE_UNKNOWN.
 func (p *UcpRequestParams) SetMemType(memType UcsMemoryType) *UcpRequestParams {
 	p.memTypeSet = true
 	p.memType = memType
 	return p
 }
 
-func setMemType(params *UcpRequestParams, p *C.ucp_request_param_t) {
+// Memory handle of the buffer, which is registered by UcpContext.MemMap(), so
+// the library doesn't have to look up the registration of the buffer.
+func (p *UcpRequestParams) SetMemory(memory *UcpMemory) *UcpRequestParams {
+	p.memory = memory
+	return p
+}
+
+// Cache to look up the registration of the operation buffer in, see
+// UcpMemoryCache. The native buffers, which are cached, must be invalidated
+// by UcpMemoryCache.Invalidate() before they are freed. Has no effect if the
+// memory is set explicitly by UcpRequestParams.SetMemory(). The Go slices of
+// the operations, e.g. UcpEp.SendTagBytesNonBlocking(), are kept reachable
+// while they are cached, see UcpMemoryCache.GetBytes().
+func (p *UcpRequestParams) SetMemoryCache(memoryCache *UcpMemoryCache) *UcpRequestParams {
+	p.memoryCache = memoryCache
+	return p
+}
+
+// Operation flags, that steer protocol selection and completion semantics of
+// the operation:
+//
+//   - UCP_OP_ATTR_FLAG_FAST_CMPL: expedite local completion, e.g. by copying
+//     the data into eager buffers instead of the rendezvous protocol, even if
+//     it delays remote data delivery.
+//
+//   - UCP_OP_ATTR_FLAG_MULTI_SEND: optimize for bandwidth of multiple in-flight
+//     operations rather than for latency, e.g. prefer zero-copy protocols.
+//
+//   - UCP_OP_ATTR_FLAG_NO_IMM_CMPL: deny immediate completion, so the operation
+//     always returns a request, that completes via the callback.
+//
+//   - UCP_OP_ATTR_FLAG_FORCE_IMM_CMPL: fail with UCS_ERR_NO_RESOURCE, if the
+//     operation can't be completed immediately.
+//
+// Message size thresholds of the protocols are configured per context, e.g.
+// by RNDV_THRESH (see UcpConfig.Modify()).
+func (p *UcpRequestParams) SetOpAttrFlags(flags UcpOpAttrFlags) *UcpRequestParams {
+	p.opFlags |= flags
+	return p
+}
+
+// Applies the params, that are common for all the operations.
+func setCommonParams(params *UcpRequestParams, p *C.ucp_request_param_t) {
+	if (params != nil) && (params.opFlags != 0) {
+		p.op_attr_mask |= C.uint32_t(params.opFlags)
+	}
+
 	if (params != nil) && params.memTypeSet {
-		p.op_attr_mask = C.UCP_OP_ATTR_FIELD_MEMORY_TYPE
+		p.op_attr_mask |= C.UCP_OP_ATTR_FIELD_MEMORY_TYPE
 		p.memory_type = C.ucs_memory_type_t(params.memType)
 	}
+
+	if (params != nil) && (params.memory != nil) {
+		p.op_attr_mask |= C.UCP_OP_ATTR_FIELD_MEMH
+		p.memh = params.memory.memHandle
+	}
+}
+
+// Buffer to store the result of a fetching atomic operation
+// (see UcpEp.AtomicNonBlocking). For UCP_ATOMIC_OP_CSWAP it also holds the
+// value to be swapped. The buffer must stay valid until the operation completes.
+func (p *UcpRequestParams) SetReplyBuffer(buffer unsafe.Pointer) *UcpRequestParams {
+	p.replyBuffer = buffer
+	return p
+}
+
+// Flags of the stream receive operations, e.g. UCP_STREAM_RECV_FLAG_WAITALL to
+// complete UcpEp.RecvStreamNonBlocking() only once the buffer is full.
+func (p *UcpRequestParams) SetStreamRecvFlags(flags UcpStreamRecvFlags) *UcpRequestParams {
+	p.recvFlags = flags
+	return p
 }
 
 func (p *UcpRequestParams) SetCallback(cb UcpCallback) *UcpRequestParams {
@@ -41,30 +217,116 @@ func (p *UcpRequestParams) SetCallback(cb UcpCallback) *UcpRequestParams {
 	return p
 }
 
+// Arbitrary value of the application, e.g. the state of the transfer, that is
+// returned by UcpRequest.UserData() of the request, which is passed to the
+// completion callback, and of the request, that is returned by the operation,
+// so the completion handlers find their state without the closures or the
+// global maps.
+func (p *UcpRequestParams) SetUserData(userData interface{}) *UcpRequestParams {
+	p.userData = userData
+	return p
+}
+
+// Request completion status to be delivered to the channel returned by
+// UcpRequest.Done(). The status is delivered from the worker progress
+// without blocking it, in addition to the callback if it's set.
+func (p *UcpRequestParams) EnableDoneChannel() *UcpRequestParams {
+	p.doneChannel = true
+	return p
+}
+
+// Deadline of the operation: the request, that is still in progress once the
+// deadline passes, is canceled by the worker progress, same as by
+// UcpRequest.Cancel(), and is completed with UCS_ERR_CANCELED status. The
+// deadline is checked only by the routines progressing the worker, so it is
+// not enforced while the worker is waiting for events, e.g. by
+// UcpWorker.Wait(). Operations, that can't be canceled, are completed as usual.
+func (p *UcpRequestParams) SetDeadline(deadline time.Time) *UcpRequestParams {
+	p.deadline = deadline
+	return p
+}
+
+// Retries of the send, that fails immediately with the transient error of
+// the transport, UCS_ERR_NO_RESOURCE or UCS_ERR_BUSY, e.g. with
+// UCP_OP_ATTR_FLAG_FORCE_IMM_CMPL while the send queue is full: the send is
+// posted again up to maxRetries times, and the worker is progressed for
+// backoff before the first retry, doubling it before every next one. The
+// error of the last attempt is returned, once the retries are exhausted, and
+// the callback is invoked only for it. The send progresses the worker, so it
+// must not be called concurrently with the other routines progressing it.
+func (p *UcpRequestParams) SetRetryPolicy(maxRetries int, backoff time.Duration) *UcpRequestParams {
+	p.maxRetries = maxRetries
+	p.retryBackoff = backoff
+	return p
+}
+
+// Received length of the immediately completed receive.
+func immediateLength(immidiateInfo interface{}) uint64 {
+	switch info := immidiateInfo.(type) {
+	case *UcpTagRecvInfo:
+		return info.Length
+	case C.size_t:
+		return uint64(info)
+	}
+	return 0
+}
+
 // Checks wether request is a pointer
 func isRequestPtr(request C.ucs_status_ptr_t) bool {
 	errLast := UCS_ERR_LAST
 	return (uint64(uintptr(request)) - 1) < (uint64(errLast) - 1)
 }
 
-func NewRequest(request C.ucs_status_ptr_t, callbackId uint64, immidiateInfo interface{}) (*UcpRequest, error) {
-	ucpRequest := &UcpRequest{}
+// Returns the request of the operation. The operation, that UCP completed
+// immediately with UCS_OK, e.g. the small send with UCP_OP_ATTR_FLAG_FAST_CMPL
+// (see UcpRequestParams.SetOpAttrFlags()), and that has no callback, done
+// channel and user data, returns nil request and nil error, so the fast path
+// doesn't allocate the request. The methods of the nil request report the
+// completed operation, see UcpRequest.
+func NewRequest(request C.ucs_status_ptr_t, worker C.ucp_worker_h, callbackId uint64,
+	done chan UcsStatus, immidiateInfo interface{}) (*UcpRequest, error) {
+	var userData interface{}
+	if callbackId != 0 {
+		userData = requestUserData(callbackId)
+	}
+
+	if (request == nil) && (done == nil) && (userData == nil) {
+		if callbackId == 0 {
+			return nil, nil
+		}
+
+		// The callback gets the request, that it may keep
+		callback, _, found := completeRequest(callbackId, nil, UCS_OK, immediateLength(immidiateInfo))
+		if !found {
+			return nil, nil
+		}
+
+		ucpRequest := &UcpRequest{worker: worker, Status: UCS_OK}
+		invokeCallback(callback, ucpRequest, immidiateInfo)
+		return ucpRequest, nil
+	}
+
+	ucpRequest := &UcpRequest{
+		worker:   worker,
+		done:     done,
+		userData: userData,
+	}
 
 	if isRequestPtr(request) {
 		ucpRequest.request = unsafe.Pointer(uintptr(request))
 		ucpRequest.Status = UCS_INPROGRESS
+		ucpRequest.callbackId = callbackId
+		trackResource("request", ucpRequest.request)
+		if callbackId != 0 {
+			trackDeadline(callbackId, worker, ucpRequest.request)
+		}
 	} else {
 		ucpRequest.Status = UcsStatus(int64(uintptr(request)))
-		if callback, found := deregister(callbackId); found {
-			switch callback := callback.(type) {
-			case UcpSendCallback:
-				callback(ucpRequest, ucpRequest.Status)
-			case UcpTagRecvCallback:
-				callback(ucpRequest, ucpRequest.Status, immidiateInfo.(*UcpTagRecvInfo))
-			case UcpAmDataRecvCallback:
-				callback(ucpRequest, ucpRequest.Status, uint64(immidiateInfo.(C.size_t)))
-			}
+		if callback, _, found := completeRequest(callbackId, nil, ucpRequest.Status,
+			immediateLength(immidiateInfo)); found {
+			invokeCallback(callback, ucpRequest, immidiateInfo)
 		}
+
 		if ucpRequest.Status != UCS_OK {
 			return ucpRequest, NewUcxError(ucpRequest.Status)
 		}
@@ -73,23 +335,141 @@ func NewRequest(request C.ucs_status_ptr_t, callbackId uint64, immidiateInfo int
 	return ucpRequest, nil
 }
 
+// Invokes the callback of the operation, that completed immediately with the
+// status of the request.
+func invokeCallback(callback UcpCallback, ucpRequest *UcpRequest, immidiateInfo interface{}) {
+	switch callback := callback.(type) {
+	case UcpSendCallback:
+		callback(ucpRequest, ucpRequest.Status)
+	case UcpTagRecvCallback:
+		callback(ucpRequest, ucpRequest.Status, immidiateInfo.(*UcpTagRecvInfo))
+	case UcpAmDataRecvCallback:
+		callback(ucpRequest, ucpRequest.Status, uint64(immidiateInfo.(C.size_t)))
+	}
+}
+
 // This routine checks the state of the request and returns its current status.
 // Any value different from UCS_INPROGRESS means that request is in a completed
 // state.
 func (r *UcpRequest) GetStatus() UcsStatus {
-	if r.Status != UCS_INPROGRESS {
+	if r == nil {
+		return UCS_OK
+	}
+
+	if (r.Status != UCS_INPROGRESS) || ((r.request == nil) && (r.probed == nil)) {
 		return r.Status
 	}
+
+	if r.probed != nil {
+		return r.probed.getStatus()
+	}
 	return UcsStatus(C.ucp_request_check_status(r.request))
 }
 
+// Returns the value, that was set by UcpRequestParams.SetUserData() for the
+// operation of the request, or nil.
+func (r *UcpRequest) UserData() interface{} {
+	if r == nil {
+		return nil
+	}
+	return r.userData
+}
+
+// Returns the channel, that receives the request completion status once the
+// request is completed, including immediate completion. The worker still
+// needs to be progressed for the request to complete. Returns nil if
+// UcpRequestParams.EnableDoneChannel() was not set for the operation.
+func (r *UcpRequest) Done() <-chan UcsStatus {
+	if r == nil {
+		return nil
+	}
+	return r.done
+}
+
+// This routine cancels the request, that is still in progress. Currently only
+// the tag receive requests can be canceled, and only before they are matched
+// with a message. The canceled request is completed with UCS_ERR_CANCELED
+// status, which is passed to the callback and to the channel returned by
+// UcpRequest.Done(). The completion is usually invoked by this routine, or by
+// the worker progress if the receive is offloaded to the transport. The
+// request still has to be released by UcpRequest.Close().
+func (r *UcpRequest) Cancel() {
+	if r == nil {
+		return
+	}
+
+	if r.probed != nil {
+		r.probed.cancel()
+	}
+
+	if (r.request != nil) && (r.GetStatus() == UCS_INPROGRESS) {
+		C.ucp_request_cancel(r.worker, r.request)
+	}
+}
+
+// This routine progresses the worker, that the request was issued on, until
+// the request is completed or the ctx is done. In the latter case the request
+// is canceled, and the routine returns ctx.Err() once the request is completed
+// with UCS_ERR_CANCELED. The request, that can't be canceled anymore, e.g. the
+// send or the matched receive, is waited for, and its own status is returned,
+// so the delivered message isn't reported as lost. The routine must not be
+// called concurrently with other routines that progress the same worker.
+func (r *UcpRequest) WaitContext(ctx context.Context) error {
+	if r == nil {
+		return nil
+	}
+
+	canceled := false
+
+	for status := r.GetStatus(); status == UCS_INPROGRESS; status = r.GetStatus() {
+		if !canceled {
+			select {
+			case <-ctx.Done():
+				r.Cancel()
+				canceled = true
+			default:
+			}
+		}
+		progressWorker(r.worker)
+	}
+
+	status := r.GetStatus()
+	if canceled && (status == UCS_ERR_CANCELED) {
+		return ctx.Err()
+	}
+
+	if status != UCS_OK {
+		return NewUcxError(status)
+	}
+
+	return nil
+}
+
 // This routine releases the non-blocking request back to the library, regardless
 // of its current state. Communications operations associated with this request
 // will make progress internally, however no further notifications or callbacks
-// will be invoked for this request.
+// will be invoked for this request. The resources of the operation, e.g. the
+// cached memory registration, are still released once it completes. The
+// request keeps the status, that it had when closed.
 func (r *UcpRequest) Close() {
+	if r == nil {
+		return
+	}
+
 	if r.request != nil {
-		C.ucp_request_free(r.request)
+		if r.Status == UCS_INPROGRESS {
+			r.Status = UcsStatus(C.ucp_request_check_status(r.request))
+		}
+
+		// The detached request is freed by its completion callback
+		if (r.callbackId == 0) || !detachRequest(r.callbackId) {
+			C.ucp_request_free(r.request)
+		}
+		untrackResource(r.request)
 		r.request = nil
 	}
+
+	if r.probed != nil {
+		r.probed.close()
+	}
 }
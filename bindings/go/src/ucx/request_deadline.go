@@ -0,0 +1,161 @@
+//go:build linux
+// +build linux
+
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package ucx
+
+// #include <ucp/api/ucp.h>
+import "C"
+import (
+	"container/heap"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unsafe"
+)
+
+// Deadline of the request, see UcpRequestParams.SetDeadline(). It's created
+// with the callback handle, and is tracked once the operation returns the
+// request in progress.
+type requestDeadline struct {
+	deadline time.Time
+	id       uint64
+	request  unsafe.Pointer
+	worker   C.ucp_worker_h
+	// Position in the heap of the worker, -1 if not tracked
+	index int
+}
+
+// Min-heap of the deadlines of the worker requests.
+type deadlineHeap []*requestDeadline
+
+func (h deadlineHeap) Len() int           { return len(h) }
+func (h deadlineHeap) Less(i, j int) bool { return h[i].deadline.Before(h[j].deadline) }
+
+func (h deadlineHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *deadlineHeap) Push(x interface{}) {
+	d := x.(*requestDeadline)
+	d.index = len(*h)
+	*h = append(*h, d)
+}
+
+func (h *deadlineHeap) Pop() interface{} {
+	old := *h
+	d := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	d.index = -1
+	return d
+}
+
+var deadlinesMu sync.Mutex
+
+// Tracked deadlines of the requests per worker.
+var deadlines = make(map[C.ucp_worker_h]*deadlineHeap)
+
+// Number of the tracked deadlines, so the progress checks them only if any.
+var deadlinesCount int64
+
+func newRequestDeadline(deadline time.Time, id uint64) *requestDeadline {
+	if deadline.IsZero() {
+		return nil
+	}
+	return &requestDeadline{deadline: deadline, id: id, index: -1}
+}
+
+// Starts tracking the deadline of the request in progress, if it's set.
+func trackDeadline(id uint64, worker C.ucp_worker_h, request unsafe.Pointer) {
+	mu.Lock()
+	entry, found := callback_map[id]
+	mu.Unlock()
+
+	if !found || (entry.deadline == nil) {
+		return
+	}
+
+	deadlinesMu.Lock()
+	defer deadlinesMu.Unlock()
+	h, found := deadlines[worker]
+	if !found {
+		h = &deadlineHeap{}
+		deadlines[worker] = h
+	}
+
+	entry.deadline.worker = worker
+	entry.deadline.request = request
+	heap.Push(h, entry.deadline)
+	atomic.AddInt64(&deadlinesCount, 1)
+}
+
+// Stops tracking the deadline of the completed request.
+func untrackDeadline(d *requestDeadline) {
+	deadlinesMu.Lock()
+	defer deadlinesMu.Unlock()
+	if d.index < 0 {
+		return
+	}
+
+	heap.Remove(deadlines[d.worker], d.index)
+	atomic.AddInt64(&deadlinesCount, -1)
+}
+
+// Cancels the requests of the worker, which deadlines passed. The canceled
+// requests are completed with UCS_ERR_CANCELED status.
+func expireDeadlines(worker C.ucp_worker_h) {
+	if atomic.LoadInt64(&deadlinesCount) == 0 {
+		return
+	}
+
+	now := time.Now()
+	var expired []*requestDeadline
+
+	deadlinesMu.Lock()
+	if h, found := deadlines[worker]; found {
+		for (h.Len() > 0) && !now.Before((*h)[0].deadline) {
+			expired = append(expired, heap.Pop(h).(*requestDeadline))
+			atomic.AddInt64(&deadlinesCount, -1)
+		}
+	}
+	deadlinesMu.Unlock()
+
+	for _, d := range expired {
+		// The request is valid until its completion removes the handle
+		mu.Lock()
+		_, found := callback_map[d.id]
+		mu.Unlock()
+
+		if found {
+			C.ucp_request_cancel(worker, d.request)
+		}
+	}
+}
+
+// Drops the deadlines of the destroyed worker.
+func removeWorkerDeadlines(worker C.ucp_worker_h) {
+	deadlinesMu.Lock()
+	defer deadlinesMu.Unlock()
+	if h, found := deadlines[worker]; found {
+		for _, d := range *h {
+			d.index = -1
+		}
+		atomic.AddInt64(&deadlinesCount, -int64(h.Len()))
+		delete(deadlines, worker)
+	}
+}
+
+// Progresses the worker and cancels the requests, which deadlines passed.
+func progressWorker(worker C.ucp_worker_h) uint {
+	count := uint(C.ucp_worker_progress(worker))
+	expireDeadlines(worker)
+	matchProbedRecvs(worker)
+	return count
+}
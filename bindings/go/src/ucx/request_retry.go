@@ -0,0 +1,51 @@
+//go:build linux
+// +build linux
+
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package ucx
+
+// #include <ucp/api/ucp.h>
+import "C"
+import (
+	"time"
+)
+
+func isTransientStatus(request C.ucs_status_ptr_t) bool {
+	if isRequestPtr(request) {
+		return false
+	}
+
+	status := UcsStatus(int64(uintptr(request)))
+	return (status == UCS_ERR_NO_RESOURCE) || (status == UCS_ERR_BUSY)
+}
+
+// Posts the send, and posts it again by the retry policy of the params, while
+// it fails with the transient error, see UcpRequestParams.SetRetryPolicy().
+// The native params aren't consumed by the failed attempt, so every attempt
+// reuses them.
+func postWithRetry(params *UcpRequestParams, worker C.ucp_worker_h,
+	post func() C.ucs_status_ptr_t) C.ucs_status_ptr_t {
+	request := post()
+	if params == nil {
+		return request
+	}
+
+	backoff := params.retryBackoff
+	for retry := 0; (retry < params.maxRetries) && isTransientStatus(request); retry++ {
+		// The progress releases the resources of the transport
+		for deadline := time.Now().Add(backoff); ; {
+			progressWorker(worker)
+			if !time.Now().Before(deadline) {
+				break
+			}
+		}
+
+		backoff *= 2
+		request = post()
+	}
+	return request
+}
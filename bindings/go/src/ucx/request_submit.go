@@ -0,0 +1,56 @@
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package ucx
+
+import "errors"
+
+// This routine posts the operation, that completes by UcpSendCallback, e.g.
+// the send, the flush or the endpoint closure, and releases its request once
+// it's completed, so the caller doesn't keep the request. op passes the params
+// with the callback to the operation. The completion status is passed to cb
+// exactly once: by the completion callback, by the immediate completion, or
+// right away, if the operation failed before it was posted, e.g. with
+// UCS_ERR_UNSUPPORTED. The routine must be called from the goroutine, that
+// progresses the worker.
+func SubmitRequest(op func(params *UcpRequestParams) (*UcpRequest, error), cb func(status UcsStatus)) {
+	var request *UcpRequest
+	completed := false
+	params := (&UcpRequestParams{}).SetCallback(UcpSendCallback(func(_ *UcpRequest, status UcsStatus) {
+		completed = true
+		if request != nil {
+			request.Close()
+		}
+		cb(status)
+	}))
+
+	posted, err := op(params)
+	if (posted != nil) && !completed && (posted.GetStatus() == UCS_INPROGRESS) {
+		request = posted
+		return
+	}
+
+	if !completed {
+		cb(submitStatus(posted, err))
+	}
+
+	if posted != nil {
+		posted.Close()
+	}
+}
+
+// Status of the operation, whose callback wasn't invoked.
+func submitStatus(posted *UcpRequest, err error) UcsStatus {
+	var ucxErr *UcxError
+	switch {
+	case errors.As(err, &ucxErr):
+		return ucxErr.GetStatus()
+	case posted != nil:
+		return posted.GetStatus()
+	case err != nil:
+		return UCS_ERR_IO_ERROR
+	}
+	return UCS_OK
+}
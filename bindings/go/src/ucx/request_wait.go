@@ -0,0 +1,153 @@
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package ucx
+
+import (
+	"runtime"
+	"time"
+)
+
+// Strategy of UcpRequest.WaitFor() after the progress calls, that report no
+// events: it spins for the first SpinCount calls, then yields the processor to
+// the other goroutines by runtime.Gosched() for the next YieldCount calls, and
+// sleeps afterwards, starting from MinSleep and doubling the sleep up to
+// MaxSleep. The strategy starts over once the progress reports events. The
+// zero fields are set to DefaultUcpWaitBackoff, and the negative counts skip
+// the stage.
+type UcpWaitBackoff struct {
+	SpinCount  int
+	YieldCount int
+	MinSleep   time.Duration
+	MaxSleep   time.Duration
+}
+
+// Backoff of UcpRequest.WaitFor(), that sleeps after about a millisecond of
+// the idle progress.
+var DefaultUcpWaitBackoff = UcpWaitBackoff{
+	SpinCount:  100,
+	YieldCount: 1000,
+	MinSleep:   time.Microsecond,
+	MaxSleep:   time.Millisecond,
+}
+
+func (b UcpWaitBackoff) withDefaults() UcpWaitBackoff {
+	if b.SpinCount == 0 {
+		b.SpinCount = DefaultUcpWaitBackoff.SpinCount
+	}
+
+	if b.YieldCount == 0 {
+		b.YieldCount = DefaultUcpWaitBackoff.YieldCount
+	}
+
+	if b.MinSleep <= 0 {
+		b.MinSleep = DefaultUcpWaitBackoff.MinSleep
+	}
+
+	if b.MaxSleep < b.MinSleep {
+		b.MaxSleep = DefaultUcpWaitBackoff.MaxSleep
+		if b.MaxSleep < b.MinSleep {
+			b.MaxSleep = b.MinSleep
+		}
+	}
+	return b
+}
+
+// Idle progress calls since the last events, and the current sleep.
+type waitBackoffState struct {
+	spins    int
+	yields   int
+	idle     int
+	sleep    time.Duration
+	minSleep time.Duration
+	maxSleep time.Duration
+}
+
+func newWaitBackoffState(config UcpWaitBackoff) *waitBackoffState {
+	s := &waitBackoffState{minSleep: config.MinSleep, maxSleep: config.MaxSleep}
+	if config.SpinCount > 0 {
+		s.spins = config.SpinCount
+	}
+
+	if config.YieldCount > 0 {
+		s.yields = config.YieldCount
+	}
+
+	s.reset()
+	return s
+}
+
+func (s *waitBackoffState) reset() {
+	s.idle = 0
+	s.sleep = s.minSleep
+}
+
+func (s *waitBackoffState) wait() {
+	s.idle++
+	if s.idle <= s.spins {
+		return
+	}
+
+	if s.idle <= s.spins+s.yields {
+		runtime.Gosched()
+		return
+	}
+
+	time.Sleep(s.sleep)
+	if s.sleep *= 2; s.sleep > s.maxSleep {
+		s.sleep = s.maxSleep
+	}
+}
+
+// This routine checks whether the request is completed, without progressing
+// the worker. The error is the status of the failed request.
+func (r *UcpRequest) Poll() (bool, error) {
+	status := r.GetStatus()
+	switch status {
+	case UCS_INPROGRESS:
+		return false, nil
+	case UCS_OK:
+		return true, nil
+	}
+	return true, NewUcxError(status)
+}
+
+// This routine calls progress until the request is completed, and backs off
+// between the calls, that report no events, so the waiting goroutine doesn't
+// starve the others. progress may be nil, in which case the worker of the
+// request is progressed, otherwise it progresses e.g. all the workers, that
+// the request depends on. backoff may be nil for DefaultUcpWaitBackoff. The
+// request isn't canceled by this routine, see UcpRequest.WaitContext() for
+// that.
+func (r *UcpRequest) WaitFor(progress func() uint, backoff *UcpWaitBackoff) error {
+	if r == nil {
+		return nil
+	}
+
+	if progress == nil {
+		worker := r.worker
+		progress = func() uint {
+			return progressWorker(worker)
+		}
+	}
+
+	config := DefaultUcpWaitBackoff
+	if backoff != nil {
+		config = backoff.withDefaults()
+	}
+	state := newWaitBackoffState(config)
+
+	for {
+		if done, err := r.Poll(); done {
+			return err
+		}
+
+		if progress() != 0 {
+			state.reset()
+		} else {
+			state.wait()
+		}
+	}
+}
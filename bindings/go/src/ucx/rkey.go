@@ -0,0 +1,46 @@
+//go:build linux
+// +build linux
+
+/*
+ * Copyright (C) 2021, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package ucx
+
+// #include <ucp/api/ucp.h>
+import "C"
+import "unsafe"
+
+// Remote key handle is an opaque object that is used by the local side to
+// access remote memory with RMA and atomic operations. It is created from a
+// buffer packed by UcpMemory.RkeyPack() on the peer side, and can be used for
+// communications only on the endpoint on which it was unpacked.
+type UcpRkey struct {
+	rkey C.ucp_rkey_h
+}
+
+// This routine destroys the remote key. It must be called after all
+// outstanding operations which are using it are flushed, and before the
+// endpoint on which it was unpacked is closed.
+func (r *UcpRkey) Close() {
+	if r.rkey != nil {
+		C.ucp_rkey_destroy(r.rkey)
+		r.rkey = nil
+	}
+}
+
+// This routine returns a local pointer to the remote memory at remoteAddr, if
+// the memory is directly accessible, e.g. by shared memory of the peer on the
+// same host. Otherwise it returns an error with UCS_ERR_UNREACHABLE status and
+// the memory has to be accessed by RMA operations. The pointer is valid until
+// the remote key is closed.
+func (r *UcpRkey) Ptr(remoteAddr uint64) (unsafe.Pointer, error) {
+	var localAddr unsafe.Pointer
+
+	if status := C.ucp_rkey_ptr(r.rkey, C.uint64_t(remoteAddr), &localAddr); status != C.UCS_OK {
+		return nil, newUcxError(status)
+	}
+
+	return localAddr, nil
+}
@@ -0,0 +1,146 @@
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package ucx
+
+import (
+	"context"
+	"sync/atomic"
+	"unsafe"
+)
+
+type wouldBlockError struct{}
+
+func (wouldBlockError) Error() string {
+	return "send queue is full"
+}
+
+func (wouldBlockError) Is(target error) bool {
+	return target == error(ErrNoResource)
+}
+
+// Error of UcpSendQueue, that has too many outstanding requests. It matches
+// ErrNoResource by errors.Is(), same as the operations, that UCX can't post
+// without the resources, e.g. with UCP_OP_ATTR_FLAG_FORCE_IMM_CMPL, so both
+// are retried the same way.
+var ErrWouldBlock error = wouldBlockError{}
+
+// Bounded queue of the operations of the endpoint, which limits the number of
+// the outstanding requests, so the sends to the slow peer don't grow the
+// memory of the pending requests and their buffers without the bound. The
+// operation takes a slot of the queue, until it completes or fails, including
+// the requests closed in progress. The queue is full once all the slots are
+// taken, and the next operation either fails with ErrWouldBlock, or blocks
+// until a slot is released, see UcpSendQueue.SetBlocking().
+//
+// The blocking queue waits for the completions of the other requests, so the
+// worker must be progressed by another goroutine meanwhile, e.g. by
+// UcpProgressLoop. The queue is safe to use from several goroutines, as long as
+// the endpoint operations are.
+type UcpSendQueue struct {
+	ep       *UcpEp
+	slots    chan struct{}
+	blocking bool
+}
+
+// Creates the queue of the endpoint with up to maxOutstanding requests in
+// progress.
+func NewUcpSendQueue(ep *UcpEp, maxOutstanding int) (*UcpSendQueue, error) {
+	if maxOutstanding <= 0 {
+		return nil, ErrInvalidParam
+	}
+
+	return &UcpSendQueue{
+		ep:    ep,
+		slots: make(chan struct{}, maxOutstanding),
+	}, nil
+}
+
+// Makes the full queue block the operations until a slot is released or their
+// ctx is done, instead of failing them with ErrWouldBlock.
+func (q *UcpSendQueue) SetBlocking(blocking bool) *UcpSendQueue {
+	q.blocking = blocking
+	return q
+}
+
+// Returns the number of the requests in progress.
+func (q *UcpSendQueue) Outstanding() int {
+	return len(q.slots)
+}
+
+func (q *UcpSendQueue) acquire(ctx context.Context) error {
+	if !q.blocking {
+		select {
+		case q.slots <- struct{}{}:
+			return nil
+		default:
+			return ErrWouldBlock
+		}
+	}
+
+	if ctx == nil {
+		q.slots <- struct{}{}
+		return nil
+	}
+
+	select {
+	case q.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// This routine posts the operation of the endpoint, e.g. UcpEp.RmaPutNonBlocking(),
+// with the params, that release the slot of the queue once the operation is
+// completed. The ctx of the blocking queue limits the wait for the slot, and
+// is ignored otherwise, nil ctx waits without the limit.
+func (q *UcpSendQueue) Submit(ctx context.Context, op func(params *UcpRequestParams) (*UcpRequest, error),
+	params *UcpRequestParams) (*UcpRequest, error) {
+	if err := q.acquire(ctx); err != nil {
+		return nil, err
+	}
+
+	// The failed operation may not invoke the release
+	var released int32
+	release := func() {
+		if atomic.CompareAndSwapInt32(&released, 0, 1) {
+			<-q.slots
+		}
+	}
+
+	request, err := op(withRelease(params, release))
+	if err != nil {
+		release()
+	}
+	return request, err
+}
+
+// This routine sends the tag message same as UcpEp.SendTagNonBlocking(), once
+// the queue has the space for it.
+func (q *UcpSendQueue) SendTag(ctx context.Context, tag uint64, address unsafe.Pointer, size uint64,
+	params *UcpRequestParams) (*UcpRequest, error) {
+	return q.Submit(ctx, func(params *UcpRequestParams) (*UcpRequest, error) {
+		return q.ep.SendTagNonBlocking(tag, address, size, params)
+	}, params)
+}
+
+// This routine sends the Active Message same as UcpEp.SendAmNonBlocking(),
+// once the queue has the space for it.
+func (q *UcpSendQueue) SendAm(ctx context.Context, id uint, header unsafe.Pointer, headerSize uint64,
+	data unsafe.Pointer, dataSize uint64, flags UcpAmSendFlags, params *UcpRequestParams) (*UcpRequest, error) {
+	return q.Submit(ctx, func(params *UcpRequestParams) (*UcpRequest, error) {
+		return q.ep.SendAmNonBlocking(id, header, headerSize, data, dataSize, flags, params)
+	}, params)
+}
+
+// This routine sends the stream data same as UcpEp.SendStreamNonBlocking(),
+// once the queue has the space for it.
+func (q *UcpSendQueue) SendStream(ctx context.Context, address unsafe.Pointer, size uint64,
+	params *UcpRequestParams) (*UcpRequest, error) {
+	return q.Submit(ctx, func(params *UcpRequestParams) (*UcpRequest, error) {
+		return q.ep.SendStreamNonBlocking(address, size, params)
+	}, params)
+}
@@ -0,0 +1,133 @@
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package ucx
+
+import (
+	"context"
+)
+
+// This routine releases all the resources of the context in the order, that
+// UCX requires:
+//  1. Stops the progress loops of the workers, removes them from the worker
+//     sets, and closes their listeners.
+//  2. Flushes the workers.
+//  3. Closes the endpoints gracefully, or by force if the flush did not
+//     complete before ctx is done.
+//  4. Destroys the workers, that also destroys the endpoints, which closure
+//     did not complete before ctx is done.
+//  5. Unmaps the memory and cleans up the context.
+//
+// The routine progresses the workers itself, so it must not be called from
+// the worker callbacks or concurrently with other routines on the workers.
+// All the resources are released regardless of ctx, and ctx.Err() is
+// returned if the graceful shutdown did not complete in time. Close() of the
+// released resources can still be called and does nothing.
+func (c *UcpContext) Shutdown(ctx context.Context) error {
+	if c.context == nil {
+		return nil
+	}
+
+	c.resourcesMu.Lock()
+	workers := make([]*UcpWorker, 0, len(c.workers))
+	for worker := range c.workers {
+		workers = append(workers, worker)
+	}
+	c.resourcesMu.Unlock()
+
+	for _, worker := range workers {
+		worker.stopAndCloseListeners()
+	}
+
+	var requests []*UcpRequest
+	for _, worker := range workers {
+		if request, err := worker.FlushNonBlocking(nil); err == nil {
+			requests = append(requests, request)
+		}
+	}
+	err := progressRequests(ctx, workers, requests)
+
+	var closeFlags UcpEpCloseFlags
+	if err != nil {
+		closeFlags = UCP_EP_CLOSE_FLAG_FORCE
+	}
+
+	requests = nil
+	for _, worker := range workers {
+		for _, ep := range getWorkerEndpoints(worker.worker) {
+			if request, closeErr := ep.CloseNonBlocking(closeFlags, nil); closeErr == nil {
+				requests = append(requests, request)
+			}
+		}
+	}
+
+	if closeFlags == UCP_EP_CLOSE_FLAG_FORCE {
+		// Forced closure does not wait for the peers, so it always completes
+		progressRequests(context.Background(), workers, requests)
+	} else {
+		err = progressRequests(ctx, workers, requests)
+	}
+
+	for _, worker := range workers {
+		worker.Close()
+	}
+
+	c.resourcesMu.Lock()
+	memories := make([]*UcpMemory, 0, len(c.memories))
+	for memHandle := range c.memories {
+		memories = append(memories, &UcpMemory{memHandle: memHandle, context: c.context, owner: c})
+	}
+	c.resourcesMu.Unlock()
+
+	for _, memory := range memories {
+		memory.Close()
+	}
+
+	c.Close()
+	return err
+}
+
+func (w *UcpWorker) stopAndCloseListeners() {
+	w.resourcesMu.Lock()
+	loop := w.progressLoop
+	listeners := make([]*UcpListener, 0, len(w.listeners))
+	for listener := range w.listeners {
+		listeners = append(listeners, listener)
+	}
+	w.resourcesMu.Unlock()
+
+	if loop != nil {
+		loop.detach(w)
+	}
+
+	for _, listener := range listeners {
+		listener.Close()
+	}
+}
+
+// Progresses the workers until all the requests are completed or ctx is done,
+// then releases the requests.
+func progressRequests(ctx context.Context, workers []*UcpWorker, requests []*UcpRequest) error {
+	defer func() {
+		for _, request := range requests {
+			request.Close()
+		}
+	}()
+
+	for _, request := range requests {
+		for request.GetStatus() == UCS_INPROGRESS {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			default:
+			}
+
+			for _, worker := range workers {
+				worker.Progress()
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+//go:build linux
+// +build linux
+
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package ucx
+
+// #include <stddef.h>
+// #include <stdint.h>
+// #include <stdlib.h>
+//
+// /* Same layout as ucs_stats_aggrgt_counter_name_t */
+// typedef struct {
+//     const char *class_name;
+//     const char *counter_name;
+// } ucxgo_stats_counter_name_t;
+//
+// /* Aggregation API is present only when UCX is built with --enable-stats */
+// extern size_t ucs_stats_aggregate(uint64_t *counters, size_t max_counters) __attribute__((weak));
+// extern void ucs_stats_aggregate_get_counter_names(const ucxgo_stats_counter_name_t **names_p,
+//                                                   size_t *size_p) __attribute__((weak));
+// extern int ucs_stats_is_active();
+// extern void ucs_stats_dump();
+//
+// static inline int ucxgo_stats_supported() {
+//     return (ucs_stats_aggregate != NULL) && (ucs_stats_aggregate_get_counter_names != NULL);
+// }
+//
+// static inline size_t ucxgo_stats_aggregate(uint64_t *counters, size_t max_counters) {
+//     return ucs_stats_aggregate(counters, max_counters);
+// }
+//
+// static inline void ucxgo_stats_counter_names(const ucxgo_stats_counter_name_t **names_p,
+//                                              size_t *size_p) {
+//     ucs_stats_aggregate_get_counter_names(names_p, size_p);
+// }
+import "C"
+import (
+	"unsafe"
+)
+
+// Initial number of counters, that are fetched by UcsStatsSnapshot().
+const statsInitialCounters = 256
+
+// Value of the UCX statistics counter. Counters of all the instances of the
+// same class (e.g. all "ucp_ep" or "ucp_worker" objects) are summed up.
+type UcsStatsCounter struct {
+	Class string
+	Name  string
+	Value uint64
+}
+
+// Returns whether UCX is built with statistics support.
+func UcsStatsSupported() bool {
+	return C.ucxgo_stats_supported() != 0
+}
+
+// Returns whether statistics are collected, i.e. UCX is built with statistics
+// support and UCX_STATS_DEST is set.
+func UcsStatsIsActive() bool {
+	return C.ucs_stats_is_active() != 0
+}
+
+// This routine dumps the statistics to the destination, that is configured by
+// UCX_STATS_DEST.
+func UcsStatsDump() {
+	C.ucs_stats_dump()
+}
+
+// This routine takes a snapshot of the statistics counters, aggregated by the
+// classes of UCX objects (context, worker, endpoint, transport interfaces and
+// memory domains). Counters are filtered by UCX_STATS_FILTER. Returns
+// UCS_ERR_UNSUPPORTED if UCX is built without statistics support.
+func UcsStatsSnapshot() ([]UcsStatsCounter, error) {
+	if !UcsStatsSupported() {
+		return nil, NewUcxError(UCS_ERR_UNSUPPORTED)
+	}
+
+	maxCounters := C.size_t(statsInitialCounters)
+	for {
+		counters := AllocateNativeMemory(uint64(maxCounters) * C.sizeof_uint64_t)
+		numCounters := C.ucxgo_stats_aggregate((*C.uint64_t)(counters), maxCounters)
+		if numCounters > maxCounters {
+			// New counters were added since the previous snapshot
+			FreeNativeMemory(counters)
+			maxCounters = numCounters
+			continue
+		}
+
+		var names *C.ucxgo_stats_counter_name_t
+		var numNames C.size_t
+		C.ucxgo_stats_counter_names(&names, &numNames)
+		if numNames < numCounters {
+			numCounters = numNames
+		}
+
+		n := int(numCounters)
+		result := make([]UcsStatsCounter, n)
+		if n > 0 {
+			values := (*[1 << 28]C.uint64_t)(counters)[:n:n]
+			namesSlice := (*[1 << 28]C.ucxgo_stats_counter_name_t)(unsafe.Pointer(names))[:n:n]
+			for i := range result {
+				result[i] = UcsStatsCounter{
+					Class: C.GoString(namesSlice[i].class_name),
+					Name:  C.GoString(namesSlice[i].counter_name),
+					Value: uint64(values[i]),
+				}
+			}
+		}
+
+		FreeNativeMemory(counters)
+		return result, nil
+	}
+}
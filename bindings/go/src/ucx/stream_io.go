@@ -0,0 +1,302 @@
+//go:build linux
+// +build linux
+
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package ucx
+
+// #include <ucp/api/ucp.h>
+import "C"
+import (
+	"errors"
+	"io"
+	"os"
+	"time"
+	"unsafe"
+)
+
+// Size of the native buffers of the stream reader and writer.
+const streamBufferSize = 64 * 1024
+
+var errStreamClosed = errors.New("stream reader or writer is closed")
+
+var _ io.ReadCloser = (*UcpStreamReader)(nil)
+var _ io.WriteCloser = (*UcpStreamWriter)(nil)
+
+// Stream reader receives the stream of the endpoint to the native buffer, so
+// small reads, e.g. by bufio.Reader or json.Decoder, don't post a receive
+// operation each. Same as UcpEp.SendFramed(), the routines progress the worker
+// of the endpoint, so they must not be called concurrently with other routines
+// progressing the worker.
+type UcpStreamReader struct {
+	ep       *UcpEp
+	buffer   unsafe.Pointer
+	start    uint64
+	end      uint64
+	deadline time.Time
+	// Receive, that is still in progress after the deadline passed
+	pending       *UcpRequest
+	pendingLength uint64
+	closed        bool
+}
+
+// Stream writer collects the written data in the native buffer, and sends it
+// to the stream of the endpoint once the buffer is full, or by
+// UcpStreamWriter.Flush(). Same as UcpStreamReader, the routines progress the
+// worker of the endpoint.
+type UcpStreamWriter struct {
+	ep       *UcpEp
+	buffer   unsafe.Pointer
+	length   uint64
+	deadline time.Time
+	// Send of the buffer, that is still in progress after the deadline passed
+	pending *UcpRequest
+	closed  bool
+}
+
+// Returns the reader of the endpoint stream. There should be a single reader of
+// the endpoint, since the data, that is buffered by one, is not seen by the
+// others.
+func (e *UcpEp) Reader() *UcpStreamReader {
+	return &UcpStreamReader{
+		ep:     e,
+		buffer: AllocateNativeMemory(streamBufferSize),
+	}
+}
+
+// Returns the buffered writer to the endpoint stream.
+func (e *UcpEp) Writer() *UcpStreamWriter {
+	return &UcpStreamWriter{
+		ep:     e,
+		buffer: AllocateNativeMemory(streamBufferSize),
+	}
+}
+
+// Progresses the worker of the endpoint until the request is completed or the
+// deadline passes, in which case the request stays in progress.
+func waitDeadline(e *UcpEp, request *UcpRequest, deadline time.Time) error {
+	for request.GetStatus() == UCS_INPROGRESS {
+		if progressWorker(e.worker) != 0 {
+			continue
+		}
+
+		if !deadline.IsZero() && time.Now().After(deadline) {
+			return os.ErrDeadlineExceeded
+		}
+	}
+
+	if status := request.GetStatus(); status != UCS_OK {
+		return NewUcxError(status)
+	}
+	return nil
+}
+
+// The stream ends, once the peer closes the connection. Then the reads return
+// io.EOF like the ones of net.Conn.
+func isStreamEnd(err error) bool {
+	return errors.Is(err, ErrConnectionReset) || errors.Is(err, ErrNotConnected) ||
+		errors.Is(err, ErrEndpointTimeout)
+}
+
+// Releases the request of the closed reader or writer. The request still in
+// progress keeps using the buffer, so the buffer is freed by the release of the
+// request params on completion.
+func closeStreamRequest(request *UcpRequest, buffer unsafe.Pointer) {
+	if (request != nil) && (request.GetStatus() == UCS_INPROGRESS) {
+		request.Close()
+		return
+	}
+
+	if request != nil {
+		request.Close()
+	}
+	FreeNativeMemory(buffer)
+}
+
+// Sets the deadline of the Read() calls, zero value means no deadline. Once
+// the deadline passes, Read() returns os.ErrDeadlineExceeded, and the next Read()
+// continues waiting for the same data.
+func (r *UcpStreamReader) SetReadDeadline(t time.Time) error {
+	r.deadline = t
+	return nil
+}
+
+// Read reads the data of the stream, that is already buffered, or waits for
+// new data to be received.
+func (r *UcpStreamReader) Read(p []byte) (int, error) {
+	if r.closed {
+		return 0, errStreamClosed
+	}
+
+	if r.start == r.end {
+		if len(p) == 0 {
+			return 0, nil
+		}
+
+		if err := r.fill(); err != nil {
+			return 0, err
+		}
+	}
+
+	buffer := (*[1 << 40]byte)(r.buffer)[r.start:r.end:r.end]
+	n := copy(p, buffer)
+	r.start += uint64(n)
+	return n, nil
+}
+
+func (r *UcpStreamReader) fill() error {
+	if r.pending == nil {
+		params := (&UcpRequestParams{}).SetCallback(func(request *UcpRequest, status UcsStatus,
+			length uint64) {
+			r.pendingLength = length
+		})
+		params = withRelease(params, func() {
+			if r.closed {
+				FreeNativeMemory(r.buffer)
+			}
+		})
+
+		request, err := r.ep.RecvStreamNonBlocking(r.buffer, streamBufferSize, params)
+		if err != nil {
+			request.Close()
+			if isStreamEnd(err) {
+				return io.EOF
+			}
+			return err
+		}
+		r.pending = request
+	}
+
+	if err := waitDeadline(r.ep, r.pending, r.deadline); err == os.ErrDeadlineExceeded {
+		return err
+	} else if err != nil {
+		r.pending.Close()
+		r.pending = nil
+		if isStreamEnd(err) {
+			return io.EOF
+		}
+		return err
+	}
+
+	r.pending.Close()
+	r.pending = nil
+	r.start = 0
+	r.end = r.pendingLength
+	return nil
+}
+
+// Close releases the buffer of the reader, the endpoint stays open. The
+// receive, that is still in progress, completes once the endpoint is closed.
+func (r *UcpStreamReader) Close() error {
+	if !r.closed {
+		r.closed = true
+		closeStreamRequest(r.pending, r.buffer)
+		r.pending = nil
+	}
+	return nil
+}
+
+// Sets the deadline of the Write(), Flush() and Close() calls, zero value
+// means no deadline. Once the deadline passes, the routines return
+// os.ErrDeadlineExceeded, while the data, that was flushed, may still be
+// delivered to the peer.
+func (w *UcpStreamWriter) SetWriteDeadline(t time.Time) error {
+	w.deadline = t
+	return nil
+}
+
+// Write copies the data to the buffer, and sends the buffer whenever it's full.
+// It returns the number of bytes copied, which is less than len(p) only with
+// an error.
+func (w *UcpStreamWriter) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, errStreamClosed
+	}
+
+	written := 0
+	for len(p) > 0 {
+		if err := w.waitPending(); err != nil {
+			return written, err
+		}
+
+		buffer := (*[1 << 40]byte)(w.buffer)[:streamBufferSize:streamBufferSize]
+		n := copy(buffer[w.length:], p)
+		w.length += uint64(n)
+		written += n
+		p = p[n:]
+
+		if w.length == streamBufferSize {
+			if err := w.send(); err != nil {
+				return written, err
+			}
+		}
+	}
+	return written, nil
+}
+
+// Waits for the send of the buffer, that was in progress after the deadline.
+func (w *UcpStreamWriter) waitPending() error {
+	if w.pending == nil {
+		return nil
+	}
+
+	err := waitDeadline(w.ep, w.pending, w.deadline)
+	if err == os.ErrDeadlineExceeded {
+		return err
+	}
+
+	w.pending.Close()
+	w.pending = nil
+	return err
+}
+
+func (w *UcpStreamWriter) send() error {
+	params := withRelease(nil, func() {
+		if w.closed {
+			FreeNativeMemory(w.buffer)
+		}
+	})
+
+	request, err := w.ep.SendStreamNonBlocking(w.buffer, w.length, params)
+	w.length = 0
+	if err != nil {
+		request.Close()
+		return err
+	}
+
+	w.pending = request
+	return w.waitPending()
+}
+
+// Flush sends the buffered data and waits until the send is completed.
+func (w *UcpStreamWriter) Flush() error {
+	if w.closed {
+		return errStreamClosed
+	}
+
+	if err := w.waitPending(); err != nil {
+		return err
+	}
+
+	if w.length == 0 {
+		return nil
+	}
+	return w.send()
+}
+
+// Close flushes the buffered data and releases the buffer of the writer, the
+// endpoint stays open. The buffer is released even if the flush fails.
+func (w *UcpStreamWriter) Close() error {
+	if w.closed {
+		return nil
+	}
+
+	err := w.Flush()
+	w.closed = true
+	closeStreamRequest(w.pending, w.buffer)
+	w.pending = nil
+	return err
+}
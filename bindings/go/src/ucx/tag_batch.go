@@ -0,0 +1,125 @@
+//go:build linux
+// +build linux
+
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package ucx
+
+// #include <ucp/api/ucp.h>
+// #include "goucx.h"
+//
+// typedef struct {
+//     void      *buffer;
+//     size_t    length;
+//     ucp_tag_t tag;
+//     ucp_mem_h memh;
+//     void      *user_data;
+// } ucxgo_tag_msg_t;
+//
+// static void ucxgo_tag_send_batch(ucp_ep_h ep, const ucxgo_tag_msg_t *msgs, size_t count,
+//                                  const ucp_request_param_t *param, ucs_status_ptr_t *requests) {
+//     ucp_request_param_t msg_param = *param;
+//     size_t i;
+//
+//     for (i = 0; i < count; ++i) {
+//         msg_param.user_data = msgs[i].user_data;
+//         if (msgs[i].memh != NULL) {
+//             msg_param.op_attr_mask = param->op_attr_mask | UCP_OP_ATTR_FIELD_MEMH;
+//             msg_param.memh         = msgs[i].memh;
+//         } else {
+//             msg_param.op_attr_mask = param->op_attr_mask;
+//             msg_param.memh         = param->memh;
+//         }
+//
+//         requests[i] = ucp_tag_send_nbx(ep, msgs[i].buffer, msgs[i].length, msgs[i].tag,
+//                                        &msg_param);
+//     }
+// }
+import "C"
+import (
+	"unsafe"
+)
+
+// Message of the tag send batch, see UcpEp.SendTagBatch().
+type UcpTagMsg struct {
+	Tag     uint64
+	Address unsafe.Pointer
+	Size    uint64
+}
+
+// This routine sends the messages to the destination endpoint, same as
+// UcpEp.SendTagNonBlocking() for every message, but submits all of them at
+// once, so the overhead of crossing to the library is paid once per batch
+// rather than once per message. The params apply to every message, e.g. the
+// callback is invoked for every request.
+//
+// The requests are returned in the order of the messages, and all of them
+// must be closed. The request of the immediately completed send may be nil,
+// see UcpRequest. The sends are independent, so a failure of one does not
+// stop the others: the error of the first failed send is returned along with
+// all the requests.
+func (e *UcpEp) SendTagBatch(msgs []UcpTagMsg, params *UcpRequestParams) ([]*UcpRequest, error) {
+	if err := checkFeature(e.worker, UCP_FEATURE_TAG); err != nil {
+		return nil, err
+	}
+
+	count := len(msgs)
+	if count == 0 {
+		return nil, nil
+	}
+
+	requestParams := getRequestParams()
+	defer putRequestParams(requestParams)
+
+	cMsgsPtr := AllocateNativeMemory(uint64(count) * C.sizeof_ucxgo_tag_msg_t)
+	defer FreeNativeMemory(cMsgsPtr)
+	cMsgs := (*[1 << 30]C.ucxgo_tag_msg_t)(cMsgsPtr)[:count:count]
+
+	cRequestsPtr := AllocateNativeMemory(uint64(count) * C.sizeof_ucs_status_ptr_t)
+	defer FreeNativeMemory(cRequestsPtr)
+	cRequests := (*[1 << 30]C.ucs_status_ptr_t)(cRequestsPtr)[:count:count]
+
+	cbIds := make([]uint64, count)
+	dones := make([]chan UcsStatus, count)
+	for i, msg := range msgs {
+		cMsgs[i] = C.ucxgo_tag_msg_t{
+			buffer: msg.Address,
+			length: C.size_t(msg.Size),
+			tag:    C.ucp_tag_t(msg.Tag),
+		}
+
+		// Every message has its own registration and callback handle
+		msgParams := params
+		if (params != nil) && (params.memoryCache != nil) && (params.memory == nil) &&
+			(msg.Address != nil) && (msg.Size != 0) {
+			memoryCache := params.memoryCache
+			if memory, err := memoryCache.Get(msg.Address, msg.Size); err == nil {
+				cMsgs[i].memh = memory.memHandle
+				msgParams = withRelease(params, func() { memoryCache.Put(memory) })
+			}
+		}
+
+		msgParams = withTransfer(msgParams, e.worker, e.ep, UcpTraceEvent{Op: UcpTraceTagSend, Tag: msg.Tag,
+			Size: msg.Size})
+		requestParams.user_data = nil
+		cbIds[i], dones[i] = setSendParams(msgParams, requestParams)
+		cMsgs[i].user_data = requestParams.user_data
+	}
+
+	C.ucxgo_tag_send_batch(e.ep, &cMsgs[0], C.size_t(count), requestParams, &cRequests[0])
+
+	var firstErr error
+	requests := make([]*UcpRequest, count)
+	for i := range msgs {
+		request, err := NewRequest(cRequests[i], e.worker, cbIds[i], dones[i], nil)
+		if (err != nil) && (firstErr == nil) {
+			firstErr = err
+		}
+		requests[i] = request
+	}
+
+	return requests, firstErr
+}
@@ -0,0 +1,228 @@
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package ucx
+
+import (
+	"context"
+	"sync"
+	"unsafe"
+)
+
+// Message, that is delivered to the channel of the route. The data is the
+// copy of the received message, so it's owned by the consumer. Err is set,
+// if the receive of the matched message failed, e.g. once the sender failed.
+type UcpTagRouteMessage struct {
+	Data      []byte
+	SenderTag uint64
+	Err       error
+}
+
+// Route of the messages, which tags match the tag and tagMask, to the bounded
+// channel of one consumer.
+type UcpTagRoute struct {
+	demux    *UcpTagDemux
+	tag      uint64
+	tagMask  uint64
+	messages chan UcpTagRouteMessage
+	// Receives of the messages, that have the space reserved in the channel.
+	// Accessed only by the goroutine polling the demultiplexer.
+	pending int
+	closed  bool
+}
+
+// Receive of the matched message, until it's delivered to the route.
+type demuxRecv struct {
+	route   *UcpTagRoute
+	request *UcpRequest
+	buffer  unsafe.Pointer
+	info    UcpTagRecvInfo
+}
+
+// Demultiplexer of the tag receives, which delivers the messages to the
+// channels of the routes, so every consumer goroutine owns the messages of
+// its tag range. The message is matched only once its route channel has the
+// space for it, so the messages of the slow consumer stay in the worker
+// unexpected queue, and the rendezvous sends of them wait for the receive,
+// while the other routes keep receiving. The routes are matched in the order
+// of their creation, so the message, that matches several routes, goes to
+// the first one, that has the space for it. The tag ranges of the routes
+// shouldn't overlap, so every consumer gets only its messages.
+//
+// The demultiplexer receives the messages from UcpTagDemux.Poll() or
+// UcpTagDemux.Run(), which must be called by the goroutine progressing the
+// worker. The routes are created and closed from any goroutine.
+type UcpTagDemux struct {
+	worker  *UcpWorker
+	mu      sync.Mutex
+	routes  []*UcpTagRoute
+	pending []*demuxRecv
+}
+
+func NewUcpTagDemux(worker *UcpWorker) *UcpTagDemux {
+	return &UcpTagDemux{worker: worker}
+}
+
+// This routine adds the route of the tags, which channel buffers up to
+// capacity messages. The channel is closed once the route is closed and its
+// received messages are delivered.
+func (d *UcpTagDemux) Route(tag uint64, tagMask uint64, capacity int) (*UcpTagRoute, error) {
+	if capacity <= 0 {
+		return nil, ErrInvalidParam
+	}
+
+	r := &UcpTagRoute{
+		demux:    d,
+		tag:      tag,
+		tagMask:  tagMask,
+		messages: make(chan UcpTagRouteMessage, capacity),
+	}
+
+	d.mu.Lock()
+	d.routes = append(d.routes, r)
+	d.mu.Unlock()
+	return r, nil
+}
+
+// Returns the channel of the route messages.
+func (r *UcpTagRoute) Messages() <-chan UcpTagRouteMessage {
+	return r.messages
+}
+
+// This routine stops matching the messages of the route. The messages, that
+// are already matched, are still delivered before the channel is closed.
+func (r *UcpTagRoute) Close() {
+	r.demux.mu.Lock()
+	r.closed = true
+	r.demux.mu.Unlock()
+}
+
+// Delivers the completed receives, releases the closed routes, and matches
+// the messages of the routes, that have the space in their channels. Returns
+// the number of the delivered messages.
+func (d *UcpTagDemux) Poll() int {
+	delivered := d.deliver()
+
+	var open []*UcpTagRoute
+	d.mu.Lock()
+	routes := d.routes[:0]
+	for _, r := range d.routes {
+		if !r.closed {
+			open = append(open, r)
+			routes = append(routes, r)
+		} else if r.pending == 0 {
+			close(r.messages)
+		} else {
+			// Closed once the pending receives are delivered
+			routes = append(routes, r)
+		}
+	}
+	d.routes = routes
+	d.mu.Unlock()
+
+	for _, r := range open {
+		// Only this goroutine sends to the channel, so the reserved space
+		// stays available
+		for len(r.messages)+r.pending < cap(r.messages) {
+			message := d.worker.TagProbe(r.tag, r.tagMask, true)
+			if message == nil {
+				break
+			}
+			d.recv(r, message)
+		}
+	}
+	return delivered + d.deliver()
+}
+
+func (d *UcpTagDemux) recv(r *UcpTagRoute, message *UcpTagMessage) {
+	size := message.Info.Length
+	if size == 0 {
+		size = 1
+	}
+
+	recv := &demuxRecv{route: r, buffer: AllocateNativeMemory(size), info: message.Info}
+	request, err := d.worker.RecvTagMsgNonBlocking(recv.buffer, message.Info.Length, message, nil)
+	if err != nil {
+		request.Close()
+		FreeNativeMemory(recv.buffer)
+		r.messages <- UcpTagRouteMessage{SenderTag: message.Info.SenderTag, Err: err}
+		return
+	}
+
+	recv.request = request
+	r.pending++
+	d.pending = append(d.pending, recv)
+}
+
+// Delivers the completed receives to their routes in the order of matching.
+func (d *UcpTagDemux) deliver() int {
+	delivered := 0
+	pending := d.pending[:0]
+	for _, recv := range d.pending {
+		status := recv.request.GetStatus()
+		if status == UCS_INPROGRESS {
+			pending = append(pending, recv)
+			continue
+		}
+
+		message := UcpTagRouteMessage{SenderTag: recv.info.SenderTag}
+		if status == UCS_OK {
+			message.Data = GoBytes(recv.buffer, recv.info.Length)
+		} else {
+			message.Err = NewUcxError(status)
+		}
+
+		recv.request.Close()
+		FreeNativeMemory(recv.buffer)
+		recv.route.pending--
+		recv.route.messages <- message
+		delivered++
+	}
+
+	for i := len(pending); i < len(d.pending); i++ {
+		d.pending[i] = nil
+	}
+	d.pending = pending
+	return delivered
+}
+
+// This routine progresses the worker and polls the demultiplexer until ctx is
+// done, and backs off while there is nothing to progress, same as
+// UcpRequest.WaitFor(). It must not be called concurrently with other routines
+// progressing the worker.
+func (d *UcpTagDemux) Run(ctx context.Context) error {
+	state := newWaitBackoffState(DefaultUcpWaitBackoff)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if (d.worker.Progress() + uint(d.Poll())) != 0 {
+			state.reset()
+		} else {
+			state.wait()
+		}
+	}
+}
+
+// This routine closes all the routes, progresses the worker until the
+// matched messages are delivered to the channels, which have the space
+// reserved for them, and closes the channels. It must be called by the
+// goroutine progressing the worker.
+func (d *UcpTagDemux) Close() {
+	d.mu.Lock()
+	routes := d.routes
+	d.routes = nil
+	d.mu.Unlock()
+
+	for len(d.pending) != 0 {
+		d.worker.Progress()
+		d.deliver()
+	}
+
+	for _, r := range routes {
+		close(r.messages)
+	}
+}
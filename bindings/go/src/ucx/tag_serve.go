@@ -0,0 +1,98 @@
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package ucx
+
+import (
+	"context"
+	"unsafe"
+)
+
+// This callback routine is invoked by UcpWorker.Serve() for every received
+// message. The buffer holds the message data and is valid only until the
+// callback returns. The error returned by the callback stops serving.
+type UcpTagServeHandler = func(buffer unsafe.Pointer, info *UcpTagRecvInfo) error
+
+// This routine receives the messages, which tags match the tag and tagMask,
+// e.g. any tag with zero tagMask, and invokes the handler for each of them
+// with the sender tag and the length of the message. Every message is probed
+// first, so the receive buffer fits the message of any size, and the buffer is
+// reused by the following messages.
+//
+// The routine progresses the worker until ctx is done or the handler returns
+// an error, and returns that error. The worker, created from the context with
+// UcpParams.EnableWakeup(), is waited for the events while there is nothing to
+// progress, otherwise it's polled. The routine must not be called concurrently
+// with other routines progressing the worker.
+func (w *UcpWorker) Serve(ctx context.Context, tag uint64, tagMask uint64,
+	handler UcpTagServeHandler) error {
+	var buffer unsafe.Pointer
+	var bufferSize uint64
+	defer func() {
+		if buffer != nil {
+			FreeNativeMemory(buffer)
+		}
+	}()
+
+	// Wakes up the wait for the events, once ctx is done
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+			w.Signal()
+		case <-stop:
+		}
+	}()
+
+	wakeup := true
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		message := w.TagProbe(tag, tagMask, true)
+		if message == nil {
+			if (w.Progress() == 0) && wakeup && (w.WaitEvents() != nil) {
+				wakeup = false
+			}
+			continue
+		}
+
+		if size := message.Info.Length; (buffer == nil) || (size > bufferSize) {
+			if buffer != nil {
+				FreeNativeMemory(buffer)
+			}
+
+			bufferSize = size
+			if bufferSize == 0 {
+				bufferSize = 1
+			}
+			buffer = AllocateNativeMemory(bufferSize)
+		}
+
+		// The message is already matched, so the receive completes regardless of ctx
+		request, err := w.RecvTagMsgNonBlocking(buffer, message.Info.Length, message, nil)
+		if err != nil {
+			request.Close()
+			return err
+		}
+
+		for request.GetStatus() == UCS_INPROGRESS {
+			w.Progress()
+		}
+
+		status := request.GetStatus()
+		request.Close()
+		if status != UCS_OK {
+			return NewUcxError(status)
+		}
+
+		info := message.Info
+		if err := handler(buffer, &info); err != nil {
+			return err
+		}
+	}
+}
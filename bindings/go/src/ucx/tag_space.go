@@ -0,0 +1,222 @@
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package ucx
+
+import (
+	"math/bits"
+	"sync"
+)
+
+// Tag space divides the 64-bit tags among the subsystems, e.g. the libraries
+// sharing the worker, so their messages don't collide. Every range of the
+// space is identified by the upper bits of the tag, and the rest of the bits
+// carry the tags of the subsystem, except the bits of the tag sender mask of
+// the context, which stay for the sender identification.
+type UcpTagSpace struct {
+	mu         sync.Mutex
+	idBits     int
+	senderMask uint64
+	ranges     map[uint64]*UcpTagRange
+	names      map[string]*UcpTagRange
+}
+
+// Range of the tag space, that is reserved for the subsystem.
+type UcpTagRange struct {
+	space *UcpTagSpace
+	name  string
+	id    uint64
+	// Fixed bits of the range and the bits of the subsystem tags
+	prefix   uint64
+	idMask   uint64
+	freeBits uint64
+}
+
+// Creates the tag space of the context with up to 2^idBits ranges. Fails with
+// ErrInvalidParam, if the upper idBits of the tag overlap the tag sender mask
+// of the context, since the senders would change the range of their tags.
+func NewUcpTagSpace(context *UcpContext, idBits int) (*UcpTagSpace, error) {
+	if (idBits <= 0) || (idBits >= 64) {
+		return nil, ErrInvalidParam
+	}
+
+	idMask := ^uint64(0) << (64 - idBits)
+	if context.TagSenderMask()&idMask != 0 {
+		return nil, ErrInvalidParam
+	}
+
+	return &UcpTagSpace{
+		idBits:     idBits,
+		senderMask: context.TagSenderMask(),
+		ranges:     make(map[uint64]*UcpTagRange),
+		names:      make(map[string]*UcpTagRange),
+	}, nil
+}
+
+// Reserves the range for the subsystem by its name, and returns it until it's
+// released. Fails with ErrAlreadyExists, if the name is already reserved, and
+// with ErrNoElem, if all the ranges are reserved.
+func (s *UcpTagSpace) Allocate(name string) (*UcpTagRange, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, found := s.names[name]; found {
+		return nil, ErrAlreadyExists
+	}
+
+	for id := uint64(0); id < 1<<uint(s.idBits); id++ {
+		if _, found := s.ranges[id]; !found {
+			return s.reserve(name, id), nil
+		}
+	}
+	return nil, ErrNoElem
+}
+
+// Reserves the range by its id, e.g. the one agreed with the peers, that
+// allocate their ranges in a different order.
+func (s *UcpTagSpace) AllocateId(name string, id uint64) (*UcpTagRange, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if id >= 1<<uint(s.idBits) {
+		return nil, ErrInvalidParam
+	}
+
+	if _, found := s.names[name]; found {
+		return nil, ErrAlreadyExists
+	}
+
+	if _, found := s.ranges[id]; found {
+		return nil, ErrAlreadyExists
+	}
+	return s.reserve(name, id), nil
+}
+
+func (s *UcpTagSpace) reserve(name string, id uint64) *UcpTagRange {
+	shift := uint(64 - s.idBits)
+	idMask := ^uint64(0) << shift
+	r := &UcpTagRange{
+		space:    s,
+		name:     name,
+		id:       id,
+		prefix:   id << shift,
+		idMask:   idMask,
+		freeBits: ^idMask &^ s.senderMask,
+	}
+
+	s.ranges[id] = r
+	s.names[name] = r
+	return r
+}
+
+// Returns the range reserved by the name, or nil.
+func (s *UcpTagSpace) Lookup(name string) *UcpTagRange {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.names[name]
+}
+
+func (r *UcpTagRange) Name() string {
+	return r.name
+}
+
+func (r *UcpTagRange) Id() uint64 {
+	return r.id
+}
+
+// Number of the distinct tags of the range.
+func (r *UcpTagRange) Capacity() uint64 {
+	return 1 << uint(bits.OnesCount64(r.freeBits))
+}
+
+// Places the bits of the value to the free bits of the range, lowest first.
+func (r *UcpTagRange) deposit(value uint64) (uint64, error) {
+	// Common case of no sender bits in the middle
+	if r.freeBits&(r.freeBits+1) == 0 {
+		if value&^r.freeBits != 0 {
+			return 0, ErrOutOfRange
+		}
+		return value, nil
+	}
+
+	var result uint64
+	for free := r.freeBits; (free != 0) && (value != 0); free &= free - 1 {
+		if value&1 != 0 {
+			result |= free & -free
+		}
+		value >>= 1
+	}
+
+	if value != 0 {
+		return 0, ErrOutOfRange
+	}
+	return result, nil
+}
+
+// Returns the tag of the send, that carries the value of the subsystem, e.g.
+// the message type or the sequence number. The bits of the value are placed
+// to the bits of the tag around the tag sender mask, so the value is less
+// than Capacity(), otherwise it fails with ErrOutOfRange.
+func (r *UcpTagRange) Tag(value uint64) (uint64, error) {
+	valueBits, err := r.deposit(value)
+	if err != nil {
+		return 0, err
+	}
+	return r.prefix | valueBits, nil
+}
+
+// Returns the value of the subsystem, that is carried by the tag of the range,
+// e.g. the sender tag of the received message.
+func (r *UcpTagRange) Value(tag uint64) uint64 {
+	if r.freeBits&(r.freeBits+1) == 0 {
+		return tag & r.freeBits
+	}
+
+	var value uint64
+	bit := uint64(1)
+	for free := r.freeBits; free != 0; free &= free - 1 {
+		if tag&(free&-free) != 0 {
+			value |= bit
+		}
+		bit <<= 1
+	}
+	return value
+}
+
+// Returns the tag and the tag mask of the receive, that matches the sends of
+// the value by any sender.
+func (r *UcpTagRange) Match(value uint64) (uint64, uint64, error) {
+	tag, err := r.Tag(value)
+	if err != nil {
+		return 0, 0, err
+	}
+	return tag, r.idMask | r.freeBits, nil
+}
+
+// Returns the tag and the tag mask of the receive, that matches any tag of the
+// range.
+func (r *UcpTagRange) Any() (uint64, uint64) {
+	return r.prefix, r.idMask
+}
+
+// Whether the tag, e.g. the sender tag of the received message, belongs to the
+// range.
+func (r *UcpTagRange) Contains(tag uint64) bool {
+	return tag&r.idMask == r.prefix
+}
+
+// Returns the range to the space, the tags of the range must not be used
+// after this call.
+func (r *UcpTagRange) Release() {
+	s := r.space
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.ranges[r.id] == r {
+		delete(s.ranges, r.id)
+		delete(s.names, r.name)
+	}
+}
@@ -0,0 +1,170 @@
+//go:build linux
+// +build linux
+
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package ucx
+
+// #include <ucp/api/ucp.h>
+import "C"
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// Operation traced by UcpTracer.
+type UcpTraceOp int
+
+const (
+	UcpTraceTagSend UcpTraceOp = iota
+	UcpTraceTagRecv
+	UcpTraceAmSend
+	// Active Message, that arrived to the handler
+	UcpTraceAmRecv
+	UcpTraceStreamSend
+	UcpTraceStreamRecv
+	// One-sided operations on the remote memory
+	UcpTraceRmaPut
+	UcpTraceRmaGet
+	UcpTraceAtomic
+	// Flush of the endpoint or of the worker
+	UcpTraceFlush
+)
+
+var traceOpNames = [...]string{
+	UcpTraceTagSend:    "tag_send",
+	UcpTraceTagRecv:    "tag_recv",
+	UcpTraceAmSend:     "am_send",
+	UcpTraceAmRecv:     "am_recv",
+	UcpTraceStreamSend: "stream_send",
+	UcpTraceStreamRecv: "stream_recv",
+	UcpTraceRmaPut:     "rma_put",
+	UcpTraceRmaGet:     "rma_get",
+	UcpTraceAtomic:     "atomic",
+	UcpTraceFlush:      "flush",
+}
+
+func (o UcpTraceOp) String() string {
+	if (o < 0) || (int(o) >= len(traceOpNames)) {
+		return "unknown"
+	}
+	return traceOpNames[o]
+}
+
+func (o UcpTraceOp) isRecv() bool {
+	return (o == UcpTraceTagRecv) || (o == UcpTraceAmRecv) || (o == UcpTraceStreamRecv) ||
+		(o == UcpTraceRmaGet)
+}
+
+// Attributes of the traced operation, which are passed to UcpTracer.Start()
+// on submit.
+type UcpTraceEvent struct {
+	Op UcpTraceOp
+	// Context of the operation, see UcpRequestParams.SetTraceContext(), or
+	// context.Background()
+	Context context.Context
+	// Endpoint of the operation, nil for the tag receives and the flushes of
+	// the worker and for the Active Messages without the reply endpoint
+	Ep *UcpEp
+	// Tag of the send, or the tag and the tag mask of the receive. The tag
+	// receive of the probed message has the sender tag of the message.
+	Tag     uint64
+	TagMask uint64
+	// Id of the Active Message
+	AmId uint
+	// Remote memory address of the RMA and atomic operations
+	RemoteAddr uint64
+	// Size of the send or of the receive buffer, the arrived message size
+	// for UcpTraceAmRecv, the operand size of the atomics, and 0 for the
+	// flushes
+	Size uint64
+}
+
+// Span of the traced operation, that is ended once, when the operation is
+// completed. The length is the received length of the receives and the size
+// of the other operations. The status is UCS_OK, or the error of the operation,
+// including UCS_ERR_CANCELED.
+type UcpTraceSpan interface {
+	End(status UcsStatus, length uint64)
+}
+
+// Tracing hooks of the worker, see UcpWorkerParams.SetTracer(). Start() is
+// invoked by the goroutine submitting the operation, and the span is ended
+// from the progress of the worker, so both must be fast and must not call
+// UCX routines. The Active Messages are traced once they arrive to the
+// handler, and their spans are ended right away. The RMA, atomic and flush
+// operations are traced the same as the sends, and the RMA gets the same as
+// the receives. See the ucxotel package for the OpenTelemetry tracer.
+type UcpTracer interface {
+	Start(event *UcpTraceEvent) UcpTraceSpan
+}
+
+var tracersMu sync.RWMutex
+
+// Tracers of the workers created with UcpWorkerParams.SetTracer().
+var tracers = make(map[C.ucp_worker_h]UcpTracer)
+
+// Number of the workers in tracers, so the operations skip the lookup while
+// there are none.
+var tracerWorkers int32
+
+func addTracer(worker C.ucp_worker_h, tracer UcpTracer) {
+	tracersMu.Lock()
+	defer tracersMu.Unlock()
+	tracers[worker] = tracer
+	atomic.AddInt32(&tracerWorkers, 1)
+}
+
+func removeTracer(worker C.ucp_worker_h) {
+	tracersMu.Lock()
+	defer tracersMu.Unlock()
+	if _, found := tracers[worker]; found {
+		delete(tracers, worker)
+		atomic.AddInt32(&tracerWorkers, -1)
+	}
+}
+
+func getTracer(worker C.ucp_worker_h) UcpTracer {
+	if atomic.LoadInt32(&tracerWorkers) == 0 {
+		return nil
+	}
+
+	tracersMu.RLock()
+	defer tracersMu.RUnlock()
+	return tracers[worker]
+}
+
+// Starts the span of the operation, unless the worker isn't traced. The
+// event is passed by value, so the untraced operations don't allocate it.
+func startTrace(params *UcpRequestParams, worker C.ucp_worker_h, ep C.ucp_ep_h,
+	event UcpTraceEvent) UcpTraceSpan {
+	tracer := getTracer(worker)
+	if tracer == nil {
+		return nil
+	}
+
+	event.Context = context.Background()
+	if (params != nil) && (params.traceContext != nil) {
+		event.Context = params.traceContext
+	}
+
+	if ep != nil {
+		event.Ep = &UcpEp{ep: ep, worker: worker}
+	}
+
+	// The event escapes to the tracer, so it's copied only once the worker is
+	// known to be traced
+	traced := event
+	return tracer.Start(&traced)
+}
+
+// Context of the operation, that is passed to the tracer of the worker, e.g.
+// the context of the parent span, see UcpTracer.
+func (p *UcpRequestParams) SetTraceContext(ctx context.Context) *UcpRequestParams {
+	p.traceContext = ctx
+	return p
+}
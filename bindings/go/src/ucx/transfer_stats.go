@@ -0,0 +1,294 @@
+//go:build linux
+// +build linux
+
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package ucx
+
+// #include <ucp/api/ucp.h>
+import "C"
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// Transfer counters of the endpoint or the worker, which are collected by the
+// bindings independently of the UCX statistics, once the worker is created
+// with UcpWorkerParams.EnableTransferStats(). The tag, stream, RMA and atomic
+// operations are accounted on completion, and the Active Messages are
+// accounted once they arrive to the handler. The RMA puts and the atomics are
+// accounted as sent, and the RMA gets as received. Flushes transfer no data,
+// so they are accounted only as the pending requests and the errors.
+type UcpTransferStats struct {
+	MessagesSent     uint64
+	BytesSent        uint64
+	MessagesReceived uint64
+	BytesReceived    uint64
+	// Operations in progress
+	PendingRequests int64
+	// Operations completed with an error status, including the canceled ones
+	Errors uint64
+}
+
+// Transfer counters of the endpoint, see UcpWorker.EndpointStats().
+type UcpEpTransferStats struct {
+	Ep *UcpEp
+	// Name of the endpoint, see UCP_EP_ATTR_FIELD_NAME
+	Name  string
+	Stats UcpTransferStats
+}
+
+type transferCounters struct {
+	messagesSent     uint64
+	bytesSent        uint64
+	messagesReceived uint64
+	bytesReceived    uint64
+	errors           uint64
+	pending          int64
+}
+
+func (c *transferCounters) load() UcpTransferStats {
+	return UcpTransferStats{
+		MessagesSent:     atomic.LoadUint64(&c.messagesSent),
+		BytesSent:        atomic.LoadUint64(&c.bytesSent),
+		MessagesReceived: atomic.LoadUint64(&c.messagesReceived),
+		BytesReceived:    atomic.LoadUint64(&c.bytesReceived),
+		PendingRequests:  atomic.LoadInt64(&c.pending),
+		Errors:           atomic.LoadUint64(&c.errors),
+	}
+}
+
+func (c *transferCounters) received(length uint64) {
+	atomic.AddUint64(&c.messagesReceived, 1)
+	atomic.AddUint64(&c.bytesReceived, length)
+}
+
+type epTransferCounters struct {
+	counters transferCounters
+	name     string
+}
+
+// Counters of the worker and of its endpoints, that have transferred data.
+type workerTransferCounters struct {
+	counters transferCounters
+	mu       sync.Mutex
+	eps      map[C.ucp_ep_h]*epTransferCounters
+}
+
+var transferStatsMu sync.RWMutex
+
+// Counters of the workers created with UcpWorkerParams.EnableTransferStats().
+var transferStats = make(map[C.ucp_worker_h]*workerTransferCounters)
+
+// Number of the workers in transferStats, so the operations skip the lookup
+// while there are none.
+var transferStatsWorkers int32
+
+func addTransferStats(worker C.ucp_worker_h) {
+	transferStatsMu.Lock()
+	defer transferStatsMu.Unlock()
+	transferStats[worker] = &workerTransferCounters{eps: make(map[C.ucp_ep_h]*epTransferCounters)}
+	atomic.AddInt32(&transferStatsWorkers, 1)
+}
+
+func removeTransferStats(worker C.ucp_worker_h) {
+	transferStatsMu.Lock()
+	defer transferStatsMu.Unlock()
+	if _, found := transferStats[worker]; found {
+		delete(transferStats, worker)
+		atomic.AddInt32(&transferStatsWorkers, -1)
+	}
+}
+
+func getTransferStats(worker C.ucp_worker_h) *workerTransferCounters {
+	if atomic.LoadInt32(&transferStatsWorkers) == 0 {
+		return nil
+	}
+
+	transferStatsMu.RLock()
+	defer transferStatsMu.RUnlock()
+	return transferStats[worker]
+}
+
+// Returns the counters of the endpoint, which are created by its first
+// transfer.
+func (s *workerTransferCounters) endpoint(ep C.ucp_ep_h) *transferCounters {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if entry, found := s.eps[ep]; found {
+		return &entry.counters
+	}
+
+	entry := &epTransferCounters{}
+	var attr C.ucp_ep_attr_t
+	attr.field_mask = C.UCP_EP_ATTR_FIELD_NAME
+	if C.ucp_ep_query(ep, &attr) == C.UCS_OK {
+		entry.name = C.GoString(&attr.name[0])
+	}
+	s.eps[ep] = entry
+	return &entry.counters
+}
+
+// The closed endpoint handle may be reused by a new endpoint, which starts
+// with zero counters.
+func removeEpTransferStats(worker C.ucp_worker_h, ep C.ucp_ep_h) {
+	if stats := getTransferStats(worker); stats != nil {
+		stats.mu.Lock()
+		delete(stats.eps, ep)
+		stats.mu.Unlock()
+	}
+}
+
+// Transfer of the operation, which is accounted and traced on completion.
+type transferRecord struct {
+	// nil unless the worker collects the stats, and for the receives of
+	// the worker
+	worker *transferCounters
+	ep     *transferCounters
+	size   uint64
+	op     UcpTraceOp
+	// nil unless the worker is traced
+	span UcpTraceSpan
+}
+
+func (r *transferRecord) complete(status UcsStatus, length uint64) {
+	recv := r.op.isRecv()
+	// The RMA get completes by the send callback, once the whole buffer is
+	// loaded
+	if r.op == UcpTraceRmaGet {
+		length = r.size
+	}
+
+	for _, c := range [...]*transferCounters{r.worker, r.ep} {
+		if c == nil {
+			continue
+		}
+
+		atomic.AddInt64(&c.pending, -1)
+		switch {
+		case status != UCS_OK:
+			atomic.AddUint64(&c.errors, 1)
+		case recv:
+			c.received(length)
+		case r.op != UcpTraceFlush:
+			atomic.AddUint64(&c.messagesSent, 1)
+			atomic.AddUint64(&c.bytesSent, r.size)
+		}
+	}
+
+	if r.span != nil {
+		if !recv {
+			length = r.size
+		}
+		r.span.End(status, length)
+	}
+}
+
+// Returns the params with the transfer record of the operation, unless the
+// worker neither collects the stats nor is traced. The size of the sends is
+// accounted, while the receives account the received length.
+func withTransfer(params *UcpRequestParams, worker C.ucp_worker_h, ep C.ucp_ep_h,
+	event UcpTraceEvent) *UcpRequestParams {
+	stats := getTransferStats(worker)
+	span := startTrace(params, worker, ep, event)
+	if (stats == nil) && (span == nil) {
+		return params
+	}
+
+	record := &transferRecord{size: event.Size, op: event.Op, span: span}
+	if stats != nil {
+		record.worker = &stats.counters
+		atomic.AddInt64(&record.worker.pending, 1)
+		if ep != nil {
+			record.ep = stats.endpoint(ep)
+			atomic.AddInt64(&record.ep.pending, 1)
+		}
+	}
+
+	var result UcpRequestParams
+	if params != nil {
+		result = *params
+	}
+	result.transfer = record
+	return &result
+}
+
+// Accounts and traces the Active Message, that arrived to the handler, on the
+// worker and on the reply endpoint, if it's known.
+func countAmRecv(worker C.ucp_worker_h, replyEp C.ucp_ep_h, id uint, length uint64) {
+	if span := startTrace(nil, worker, replyEp, UcpTraceEvent{Op: UcpTraceAmRecv,
+		AmId: id, Size: length}); span != nil {
+		span.End(UCS_OK, length)
+	}
+
+	stats := getTransferStats(worker)
+	if stats == nil {
+		return
+	}
+
+	stats.counters.received(length)
+	if replyEp != nil {
+		stats.endpoint(replyEp).received(length)
+	}
+}
+
+func iovLength(iov []UcpIov) uint64 {
+	var length uint64
+	for _, entry := range iov {
+		length += entry.Length
+	}
+	return length
+}
+
+// Returns the transfer counters of the worker, which are zero unless the
+// worker is created with UcpWorkerParams.EnableTransferStats().
+func (w *UcpWorker) Stats() UcpTransferStats {
+	if stats := getTransferStats(w.worker); stats != nil {
+		return stats.counters.load()
+	}
+	return UcpTransferStats{}
+}
+
+// Returns the transfer counters of the endpoints of the worker, that have
+// transferred data since they were created, sorted by their names.
+func (w *UcpWorker) EndpointStats() []UcpEpTransferStats {
+	stats := getTransferStats(w.worker)
+	if stats == nil {
+		return nil
+	}
+
+	stats.mu.Lock()
+	result := make([]UcpEpTransferStats, 0, len(stats.eps))
+	for ep, entry := range stats.eps {
+		result = append(result, UcpEpTransferStats{
+			Ep:    &UcpEp{ep: ep, worker: w.worker},
+			Name:  entry.name,
+			Stats: entry.counters.load(),
+		})
+	}
+	stats.mu.Unlock()
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// Returns the transfer counters of the endpoint, which are zero unless its
+// worker is created with UcpWorkerParams.EnableTransferStats(). The counters
+// are dropped once the endpoint is closed.
+func (e *UcpEp) Stats() UcpTransferStats {
+	stats := getTransferStats(e.worker)
+	if stats == nil {
+		return UcpTransferStats{}
+	}
+
+	stats.mu.Lock()
+	defer stats.mu.Unlock()
+	if entry, found := stats.eps[e.ep]; found {
+		return entry.counters.load()
+	}
+	return UcpTransferStats{}
+}
@@ -0,0 +1,292 @@
+//go:build linux
+// +build linux
+
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package ucx
+
+// #include <ucp/api/ucp.h>
+import "C"
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+	"unsafe"
+)
+
+// Behavior of the tag receive, that matches the message larger than its
+// buffer, see UcpRequestParams.SetTruncationPolicy().
+type UcpTruncationPolicy int
+
+const (
+	// The receive fails with UCS_ERR_MESSAGE_TRUNCATED, and the data is lost
+	UcpTruncationError UcpTruncationPolicy = iota
+	// The receive completes with the part of the message, that fits the
+	// buffer, and UcpTagRecvInfo.Length is the length of the whole message
+	UcpTruncationTruncate
+	// The whole message is received to the new buffer of its length, see
+	// UcpRequest.GrownBuffer()
+	UcpTruncationGrow
+)
+
+// Policy of UcpWorker.RecvTagNonBlocking() for the message larger than the
+// buffer. The receives with the policy other than UcpTruncationError probe
+// the message first, see UcpWorker.TagProbe(), so they are matched by the
+// routines progressing the worker in the order they are posted, and only with
+// the messages, that are not matched by the receives without the policy. The
+// deadline of the receive cancels it only until the message is matched.
+func (p *UcpRequestParams) SetTruncationPolicy(policy UcpTruncationPolicy) *UcpRequestParams {
+	p.truncation = policy
+	return p
+}
+
+// Tag receive, that waits for the probe of its message.
+type probedRecv struct {
+	worker  *UcpWorker
+	request *UcpRequest
+	address unsafe.Pointer
+	size    uint64
+	tag     uint64
+	tagMask uint64
+	params  UcpRequestParams
+	cb      UcpTagRecvCallback
+	done    chan UcsStatus
+
+	mu     sync.Mutex
+	status UcsStatus
+	// Receive of the matched message, and the buffer of the message, that
+	// doesn't fit the receive buffer
+	inner   *UcpRequest
+	staging unsafe.Pointer
+	grown   unsafe.Pointer
+	// Whether the request is released by UcpRequest.Close()
+	closed bool
+}
+
+var probedRecvsMu sync.Mutex
+
+// Receives of the workers, that are not matched yet, in the order of post.
+var probedRecvs = make(map[C.ucp_worker_h][]*probedRecv)
+
+// Number of the receives in probedRecvs, so the progress skips the lookup
+// while there are none.
+var probedRecvsCount int32
+
+func (w *UcpWorker) recvTagProbed(address unsafe.Pointer, size uint64, tag uint64, tagMask uint64,
+	params *UcpRequestParams) *UcpRequest {
+	recv := &probedRecv{
+		worker:  w,
+		address: address,
+		size:    size,
+		tag:     tag,
+		tagMask: tagMask,
+		params:  *params,
+		status:  UCS_INPROGRESS,
+	}
+	recv.cb, _ = params.Cb.(UcpTagRecvCallback)
+	if params.doneChannel {
+		recv.done = make(chan UcsStatus, 1)
+	}
+
+	request := &UcpRequest{
+		worker:   w.worker,
+		done:     recv.done,
+		Status:   UCS_INPROGRESS,
+		probed:   recv,
+		userData: params.userData,
+	}
+	recv.request = request
+
+	probedRecvsMu.Lock()
+	probedRecvs[w.worker] = append(probedRecvs[w.worker], recv)
+	atomic.AddInt32(&probedRecvsCount, 1)
+	probedRecvsMu.Unlock()
+
+	matchProbedRecvs(w.worker)
+	return request
+}
+
+// Removes the receive, that is not matched yet, and returns whether it was
+// found.
+func (r *probedRecv) unlink() bool {
+	probedRecvsMu.Lock()
+	defer probedRecvsMu.Unlock()
+
+	recvs := probedRecvs[r.worker.worker]
+	for i, recv := range recvs {
+		if recv == r {
+			probedRecvs[r.worker.worker] = append(recvs[:i:i], recvs[i+1:]...)
+			atomic.AddInt32(&probedRecvsCount, -1)
+			return true
+		}
+	}
+	return false
+}
+
+// Probes the messages of the pending receives of the worker, and starts the
+// receives of the matched messages. The messages are removed from the worker
+// queue under the lock, so the concurrent progress doesn't match them twice,
+// while the receives are started after it, since their callbacks may post new
+// receives.
+func matchProbedRecvs(worker C.ucp_worker_h) {
+	if atomic.LoadInt32(&probedRecvsCount) == 0 {
+		return
+	}
+
+	type match struct {
+		recv    *probedRecv
+		message *UcpTagMessage
+	}
+	var matches []match
+	var expired []*probedRecv
+	now := time.Now()
+
+	probedRecvsMu.Lock()
+	recvs := probedRecvs[worker]
+	pending := recvs[:0]
+	for _, recv := range recvs {
+		if message := recv.worker.TagProbe(recv.tag, recv.tagMask, true); message != nil {
+			matches = append(matches, match{recv, message})
+		} else if !recv.params.deadline.IsZero() && now.After(recv.params.deadline) {
+			expired = append(expired, recv)
+		} else {
+			pending = append(pending, recv)
+		}
+	}
+
+	if len(recvs) != 0 {
+		for i := len(pending); i < len(recvs); i++ {
+			recvs[i] = nil
+		}
+		probedRecvs[worker] = pending
+		atomic.AddInt32(&probedRecvsCount, -int32(len(matches)+len(expired)))
+	}
+	probedRecvsMu.Unlock()
+
+	for _, m := range matches {
+		m.recv.start(m.message)
+	}
+
+	for _, recv := range expired {
+		recv.complete(UCS_ERR_CANCELED, &UcpTagRecvInfo{})
+	}
+}
+
+func removeProbedRecvs(worker C.ucp_worker_h) {
+	probedRecvsMu.Lock()
+	defer probedRecvsMu.Unlock()
+	atomic.AddInt32(&probedRecvsCount, -int32(len(probedRecvs[worker])))
+	delete(probedRecvs, worker)
+}
+
+func (r *probedRecv) start(message *UcpTagMessage) {
+	buffer, size := r.address, r.size
+	params := r.params
+	params.truncation = UcpTruncationError
+	params.doneChannel = false
+	if message.Info.Length > r.size {
+		size = message.Info.Length
+		if size == 0 {
+			size = 1
+		}
+		buffer = AllocateNativeMemory(size)
+		r.staging = buffer
+
+		// The staging buffer is the host memory, that isn't registered
+		params.memTypeSet = false
+		params.memory = nil
+		params.memoryCache = nil
+	}
+
+	params.Cb = UcpTagRecvCallback(func(request *UcpRequest, status UcsStatus, info *UcpTagRecvInfo) {
+		r.complete(status, &message.Info)
+	})
+
+	inner, _ := r.worker.RecvTagMsgNonBlocking(buffer, message.Info.Length, message, &params)
+	r.mu.Lock()
+	if r.status == UCS_INPROGRESS {
+		r.inner = inner
+		inner = nil
+	}
+	r.mu.Unlock()
+
+	if inner != nil {
+		inner.Close()
+	}
+}
+
+func (r *probedRecv) complete(status UcsStatus, info *UcpTagRecvInfo) {
+	r.mu.Lock()
+	if r.staging != nil {
+		if (status == UCS_OK) && !r.closed && (r.params.truncation == UcpTruncationGrow) {
+			r.grown = r.staging
+		} else {
+			if (status == UCS_OK) && !r.closed && (r.size != 0) {
+				copy((*[1 << 40]byte)(r.address)[:r.size:r.size],
+					(*[1 << 40]byte)(r.staging)[:r.size:r.size])
+			}
+			FreeNativeMemory(r.staging)
+		}
+		r.staging = nil
+	}
+
+	r.status = status
+	closed := r.closed
+	inner := r.inner
+	r.inner = nil
+	r.mu.Unlock()
+
+	if inner != nil {
+		inner.Close()
+	}
+
+	if closed {
+		return
+	}
+
+	if r.cb != nil {
+		r.cb(r.request, status, &UcpTagRecvInfo{SenderTag: info.SenderTag, Length: info.Length})
+	}
+
+	if r.done != nil {
+		r.done <- status
+	}
+}
+
+func (r *probedRecv) getStatus() UcsStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.status
+}
+
+// The matched receive isn't canceled, same as the matched tag receive.
+func (r *probedRecv) cancel() {
+	if r.unlink() {
+		r.complete(UCS_ERR_CANCELED, &UcpTagRecvInfo{})
+	}
+}
+
+func (r *probedRecv) close() {
+	r.mu.Lock()
+	r.closed = true
+	r.mu.Unlock()
+
+	r.unlink()
+}
+
+// Returns the buffer of the completed receive with UcpTruncationGrow, that
+// holds the message larger than the receive buffer, or nil if the message fit
+// the receive buffer. The buffer is owned by the caller, and must be released
+// by FreeNativeMemory().
+func (r *UcpRequest) GrownBuffer() unsafe.Pointer {
+	if (r == nil) || (r.probed == nil) {
+		return nil
+	}
+
+	r.probed.mu.Lock()
+	defer r.probed.mu.Unlock()
+	return r.probed.grown
+}
@@ -1,3 +1,6 @@
+//go:build linux
+// +build linux
+
 /*
  * Copyright (C) 2021, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
  * See file LICENSE for terms.
@@ -17,6 +20,20 @@ const (
 	UCP_MEM_MAP_PROT_REMOTE_WRITE UcpProtection = C.UCP_MEM_MAP_PROT_REMOTE_WRITE
 )
 
+type UcpFeatures uint64
+
+const (
+	UCP_FEATURE_TAG    UcpFeatures = C.UCP_FEATURE_TAG    /**< Request tag matching support */
+	UCP_FEATURE_RMA    UcpFeatures = C.UCP_FEATURE_RMA    /**< Request remote memory access support */
+	UCP_FEATURE_AMO32  UcpFeatures = C.UCP_FEATURE_AMO32  /**< Request 32-bit atomic operations support */
+	UCP_FEATURE_AMO64  UcpFeatures = C.UCP_FEATURE_AMO64  /**< Request 64-bit atomic operations support */
+	UCP_FEATURE_WAKEUP UcpFeatures = C.UCP_FEATURE_WAKEUP /**< Request interrupt notification support */
+	UCP_FEATURE_STREAM UcpFeatures = C.UCP_FEATURE_STREAM /**< Request stream support */
+	UCP_FEATURE_AM     UcpFeatures = C.UCP_FEATURE_AM     /**< Request Active Message support */
+
+	UCP_FEATURE_EXPORTED_MEMH UcpFeatures = C.UCP_FEATURE_EXPORTED_MEMH /**< Request support of exported memory handles */
+)
+
 type UcpContextAttr uint32
 
 const (
@@ -57,6 +74,18 @@ const (
 	UCP_WORKER_ATTR_FIELD_MAX_INFO_STRING UcpWorkerAttribute = C.UCP_WORKER_ATTR_FIELD_MAX_INFO_STRING
 )
 
+type UcpWorkerAddressFlags uint32
+
+const (
+	UCP_WORKER_ADDRESS_FLAG_NET_ONLY UcpWorkerAddressFlags = C.UCP_WORKER_ADDRESS_FLAG_NET_ONLY
+)
+
+type UcpWorkerAddressAttribute uint64
+
+const (
+	UCP_WORKER_ADDRESS_ATTR_FIELD_UID UcpWorkerAddressAttribute = C.UCP_WORKER_ADDRESS_ATTR_FIELD_UID
+)
+
 type UcpListenerAttribute uint32
 
 const (
@@ -72,6 +101,8 @@ const (
 	UCP_AM_SEND_FLAG_EAGER UcpAmSendFlags = C.UCP_AM_SEND_FLAG_EAGER
 	// Force UCP to use only rendezvous protocol for AM sends.
 	UCP_AM_SEND_FLAG_RNDV UcpAmSendFlags = C.UCP_AM_SEND_FLAG_RNDV
+	// Copy the header, so it can be released once the send routine returns.
+	UCP_AM_SEND_FLAG_COPY_HEADER UcpAmSendFlags = C.UCP_AM_SEND_FLAG_COPY_HEADER
 )
 
 type UcpAmRecvAttrs uint64
@@ -90,7 +121,7 @@ const (
 
 	// Guarantees that the specified callback, will always be called
 	// with UCP_AM_RECV_ATTR_FLAG_DATA flag set,so the data will be accessible outside the callback,
-	// until UcpAmData.Close() is called.
+	// until UcpAmData.Release() is called.
 	UCP_AM_FLAG_PERSISTENT_DATA UcpAmCbFlags = C.UCP_AM_FLAG_PERSISTENT_DATA
 )
 
@@ -100,3 +131,80 @@ const (
 	UCP_CONN_REQUEST_ATTR_FIELD_CLIENT_ADDR = C.UCP_CONN_REQUEST_ATTR_FIELD_CLIENT_ADDR
 	UCP_CONN_REQUEST_ATTR_FIELD_CLIENT_ID   = C.UCP_CONN_REQUEST_ATTR_FIELD_CLIENT_ID
 )
+
+type UcpOpAttrFlags uint32
+
+const (
+	UCP_OP_ATTR_FLAG_NO_IMM_CMPL    UcpOpAttrFlags = C.UCP_OP_ATTR_FLAG_NO_IMM_CMPL
+	UCP_OP_ATTR_FLAG_FAST_CMPL      UcpOpAttrFlags = C.UCP_OP_ATTR_FLAG_FAST_CMPL
+	UCP_OP_ATTR_FLAG_FORCE_IMM_CMPL UcpOpAttrFlags = C.UCP_OP_ATTR_FLAG_FORCE_IMM_CMPL
+	UCP_OP_ATTR_FLAG_MULTI_SEND     UcpOpAttrFlags = C.UCP_OP_ATTR_FLAG_MULTI_SEND
+)
+
+type UcpEpAttribute uint32
+
+const (
+	UCP_EP_ATTR_FIELD_NAME            UcpEpAttribute = C.UCP_EP_ATTR_FIELD_NAME
+	UCP_EP_ATTR_FIELD_LOCAL_SOCKADDR  UcpEpAttribute = C.UCP_EP_ATTR_FIELD_LOCAL_SOCKADDR
+	UCP_EP_ATTR_FIELD_REMOTE_SOCKADDR UcpEpAttribute = C.UCP_EP_ATTR_FIELD_REMOTE_SOCKADDR
+	UCP_EP_ATTR_FIELD_TRANSPORTS      UcpEpAttribute = C.UCP_EP_ATTR_FIELD_TRANSPORTS
+)
+
+type UcpAtomicOp int
+
+const (
+	// Atomic add: Result=Y; Y+=X
+	UCP_ATOMIC_OP_ADD UcpAtomicOp = C.UCP_ATOMIC_OP_ADD
+	// Atomic swap: Result=Y; Y=X
+	UCP_ATOMIC_OP_SWAP UcpAtomicOp = C.UCP_ATOMIC_OP_SWAP
+	// Atomic conditional swap: Result=Y; if (X==Y) then Y=Z.
+	// Z and Result are passed in the reply buffer.
+	UCP_ATOMIC_OP_CSWAP UcpAtomicOp = C.UCP_ATOMIC_OP_CSWAP
+	// Atomic and: Result=Y; Y&=X
+	UCP_ATOMIC_OP_AND UcpAtomicOp = C.UCP_ATOMIC_OP_AND
+	// Atomic or: Result=Y; Y|=X
+	UCP_ATOMIC_OP_OR UcpAtomicOp = C.UCP_ATOMIC_OP_OR
+	// Atomic xor: Result=Y; Y^=X
+	UCP_ATOMIC_OP_XOR UcpAtomicOp = C.UCP_ATOMIC_OP_XOR
+)
+
+type UcpErrHandlingMode int
+
+const (
+	// No guarantees about error reporting, imposes minimal overhead from a
+	// performance perspective
+	UCP_ERR_HANDLING_MODE_NONE UcpErrHandlingMode = C.UCP_ERR_HANDLING_MODE_NONE
+	// Guarantees that send requests are always completed (successfully or
+	// error) even in case of remote failure
+	UCP_ERR_HANDLING_MODE_PEER UcpErrHandlingMode = C.UCP_ERR_HANDLING_MODE_PEER
+)
+
+type UcpEpParamsFlags uint32
+
+const (
+	// Using a client-server connection establishment mechanism, the endpoint
+	// is created to the socket address of the listener.
+	UCP_EP_PARAMS_FLAGS_CLIENT_SERVER UcpEpParamsFlags = C.UCP_EP_PARAMS_FLAGS_CLIENT_SERVER
+	// Avoid connecting the endpoint to itself, when connecting to the same
+	// worker it was created on.
+	UCP_EP_PARAMS_FLAGS_NO_LOOPBACK UcpEpParamsFlags = C.UCP_EP_PARAMS_FLAGS_NO_LOOPBACK
+	// Send the client id as part of the connection request payload.
+	UCP_EP_PARAMS_FLAGS_SEND_CLIENT_ID UcpEpParamsFlags = C.UCP_EP_PARAMS_FLAGS_SEND_CLIENT_ID
+)
+
+type UcpEpCloseFlags uint32
+
+const (
+	// Releases the endpoint without any confirmation from the peer. All
+	// outstanding requests will be completed with UCS_ERR_CANCELED error.
+	// Without this flag all outstanding operations are flushed before the
+	// endpoint is released.
+	UCP_EP_CLOSE_FLAG_FORCE UcpEpCloseFlags = C.UCP_EP_CLOSE_FLAG_FORCE
+)
+
+type UcpStreamRecvFlags uint32
+
+const (
+	// Receive operation is completed only when the whole buffer is filled
+	UCP_STREAM_RECV_FLAG_WAITALL UcpStreamRecvFlags = C.UCP_STREAM_RECV_FLAG_WAITALL
+)
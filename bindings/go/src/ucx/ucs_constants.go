@@ -1,3 +1,6 @@
+//go:build linux
+// +build linux
+
 /*
  * Copyright (C) 2021, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
  * See file LICENSE for terms.
@@ -19,6 +22,34 @@ const (
 	UCS_THREAD_MODE_MULTI      UcsThreadMode = C.UCS_THREAD_MODE_MULTI
 )
 
+type UcsConfigPrintFlags int
+
+const (
+	UCS_CONFIG_PRINT_CONFIG          UcsConfigPrintFlags = C.UCS_CONFIG_PRINT_CONFIG
+	UCS_CONFIG_PRINT_HEADER          UcsConfigPrintFlags = C.UCS_CONFIG_PRINT_HEADER
+	UCS_CONFIG_PRINT_DOC             UcsConfigPrintFlags = C.UCS_CONFIG_PRINT_DOC
+	UCS_CONFIG_PRINT_HIDDEN          UcsConfigPrintFlags = C.UCS_CONFIG_PRINT_HIDDEN
+	UCS_CONFIG_PRINT_COMMENT_DEFAULT UcsConfigPrintFlags = C.UCS_CONFIG_PRINT_COMMENT_DEFAULT
+)
+
+type UcsLogLevel int
+
+const (
+	UCS_LOG_LEVEL_FATAL       UcsLogLevel = C.UCS_LOG_LEVEL_FATAL       /**< Immediate termination */
+	UCS_LOG_LEVEL_ERROR       UcsLogLevel = C.UCS_LOG_LEVEL_ERROR       /**< Error is returned to the user */
+	UCS_LOG_LEVEL_WARN        UcsLogLevel = C.UCS_LOG_LEVEL_WARN        /**< Something's wrong, but we continue */
+	UCS_LOG_LEVEL_DIAG        UcsLogLevel = C.UCS_LOG_LEVEL_DIAG        /**< Diagnostics, silent adjustments or internal error handling */
+	UCS_LOG_LEVEL_INFO        UcsLogLevel = C.UCS_LOG_LEVEL_INFO        /**< Information */
+	UCS_LOG_LEVEL_DEBUG       UcsLogLevel = C.UCS_LOG_LEVEL_DEBUG       /**< Low-volume debugging */
+	UCS_LOG_LEVEL_TRACE       UcsLogLevel = C.UCS_LOG_LEVEL_TRACE       /**< High-volume debugging */
+	UCS_LOG_LEVEL_TRACE_REQ   UcsLogLevel = C.UCS_LOG_LEVEL_TRACE_REQ   /**< Every send/receive request */
+	UCS_LOG_LEVEL_TRACE_DATA  UcsLogLevel = C.UCS_LOG_LEVEL_TRACE_DATA  /**< Data sent/received on the transport */
+	UCS_LOG_LEVEL_TRACE_ASYNC UcsLogLevel = C.UCS_LOG_LEVEL_TRACE_ASYNC /**< Asynchronous progress engine */
+	UCS_LOG_LEVEL_TRACE_FUNC  UcsLogLevel = C.UCS_LOG_LEVEL_TRACE_FUNC  /**< Function calls */
+	UCS_LOG_LEVEL_TRACE_POLL  UcsLogLevel = C.UCS_LOG_LEVEL_TRACE_POLL  /**< Polling functions */
+	UCS_LOG_LEVEL_PRINT       UcsLogLevel = C.UCS_LOG_LEVEL_PRINT       /**< Temporary output */
+)
+
 type UcsMemoryType int
 
 const (
@@ -27,6 +58,10 @@ const (
 	UCS_MEMORY_TYPE_CUDA_MANAGED UcsMemoryType = C.UCS_MEMORY_TYPE_CUDA_MANAGED /**< NVIDIA CUDA managed (or unified) memory */
 	UCS_MEMORY_TYPE_ROCM         UcsMemoryType = C.UCS_MEMORY_TYPE_ROCM         /**< AMD ROCM memory */
 	UCS_MEMORY_TYPE_ROCM_MANAGED UcsMemoryType = C.UCS_MEMORY_TYPE_ROCM_MANAGED /**< AMD ROCM managed system memory */
+	UCS_MEMORY_TYPE_RDMA         UcsMemoryType = C.UCS_MEMORY_TYPE_RDMA         /**< RDMA device memory */
+	UCS_MEMORY_TYPE_ZE_HOST      UcsMemoryType = C.UCS_MEMORY_TYPE_ZE_HOST      /**< Intel ZE memory (USM host) */
+	UCS_MEMORY_TYPE_ZE_DEVICE    UcsMemoryType = C.UCS_MEMORY_TYPE_ZE_DEVICE    /**< Intel ZE memory (USM device) */
+	UCS_MEMORY_TYPE_ZE_MANAGED   UcsMemoryType = C.UCS_MEMORY_TYPE_ZE_MANAGED   /**< Intel ZE managed memory (USM shared) */
 	UCS_MEMORY_TYPE_UNKNOWN      UcsMemoryType = C.UCS_MEMORY_TYPE_UNKNOWN
 )
 
@@ -81,3 +116,7 @@ const (
 func (m UcsStatus) String() string {
 	return C.GoString(C.ucs_status_string(C.ucs_status_t(m)))
 }
+
+func newUcxError(status C.ucs_status_t) error {
+	return NewUcxError(UcsStatus(status))
+}
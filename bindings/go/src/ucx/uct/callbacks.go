@@ -0,0 +1,89 @@
+//go:build linux
+// +build linux
+
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package uct
+
+// #include <uct/api/uct.h>
+import "C"
+import (
+	"sync"
+	. "ucx"
+	"unsafe"
+)
+
+// Handler of the Active Messages, the data is valid only until it returns.
+type AmHandler = func(data []byte)
+
+// Callback of the zero-copy operation or the flush, with the status of the
+// operation.
+type CompletionCallback = func(status UcsStatus)
+
+// Same as the registry of the ucx package, the handlers and the callbacks
+// are passed to C as the handles, since the native code can't keep Go
+// pointers.
+var amHandlers = make(map[uint64]AmHandler)
+
+var completions = make(map[uint64]CompletionCallback)
+
+var handleId uint64 = 1
+
+var mu sync.Mutex
+
+func registerAmHandler(handler AmHandler) uint64 {
+	mu.Lock()
+	defer mu.Unlock()
+	handleId++
+	amHandlers[handleId] = handler
+	return handleId
+}
+
+func deregisterAmHandler(handle uint64) {
+	mu.Lock()
+	defer mu.Unlock()
+	delete(amHandlers, handle)
+}
+
+func registerCompletion(cb CompletionCallback) uint64 {
+	mu.Lock()
+	defer mu.Unlock()
+	handleId++
+	completions[handleId] = cb
+	return handleId
+}
+
+func deregisterCompletion(handle uint64) {
+	mu.Lock()
+	defer mu.Unlock()
+	delete(completions, handle)
+}
+
+//export ucxgo_uctAmHandler
+func ucxgo_uctAmHandler(handle C.uint64_t, data unsafe.Pointer, length C.size_t, flags C.unsigned) C.ucs_status_t {
+	mu.Lock()
+	handler := amHandlers[uint64(handle)]
+	mu.Unlock()
+
+	if handler != nil {
+		n := int(length)
+		handler((*[1 << 40]byte)(data)[:n:n])
+	}
+	// The data is released by the transport once the handler returns
+	return C.UCS_OK
+}
+
+//export ucxgo_uctComplete
+func ucxgo_uctComplete(handle C.uint64_t, status C.ucs_status_t) {
+	mu.Lock()
+	cb := completions[uint64(handle)]
+	delete(completions, uint64(handle))
+	mu.Unlock()
+
+	if cb != nil {
+		cb(UcsStatus(status))
+	}
+}
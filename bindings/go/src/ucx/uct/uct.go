@@ -0,0 +1,581 @@
+//go:build linux
+// +build linux
+
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+// Package uct exposes the low-level UCT API for the custom protocols, that
+// can't afford the overhead of UCP: the interfaces of the transport devices,
+// the endpoints connected to the remote interfaces, the short, buffered and
+// zero-copy Active Messages, and their completions. Unlike UCP, UCT doesn't
+// select the transport, fragment the messages, or wire up the endpoints, so
+// the protocol exchanges the addresses itself, and retries the operations,
+// that fail with ucx.ErrNoResource, after progressing the worker. Only the
+// transports, which connect to the remote interface, e.g. "self", "posix" or
+// "ud_verbs", are supported.
+//
+// The worker and its interfaces are single-threaded, so all their routines,
+// including the progress, must be called by one goroutine, e.g. the one
+// locked to its OS thread. The Active Message handlers and the completion
+// callbacks are invoked from Worker.Progress().
+package uct
+
+// #include <stdlib.h>
+// #include <string.h>
+// #include <uct/api/uct.h>
+// #include <ucs/async/async_fwd.h>
+//
+// extern ucs_status_t ucxgo_uctAmHandler(uint64_t handle, void *data, size_t length, unsigned flags);
+// extern void ucxgo_uctComplete(uint64_t handle, ucs_status_t status);
+//
+// typedef struct {
+//     uct_completion_t super;
+//     uint64_t         handle;
+// } ucxgo_uct_completion_t;
+//
+// typedef struct {
+//     const void *buffer;
+//     size_t     length;
+// } ucxgo_uct_pack_arg_t;
+//
+// static ucs_status_t ucxgo_uct_am_cb(void *arg, void *data, size_t length, unsigned flags) {
+//     return ucxgo_uctAmHandler((uintptr_t)arg, data, length, flags);
+// }
+//
+// static ucs_status_t ucxgo_uct_set_am_handler(uct_iface_h iface, uint8_t id, uint64_t handle) {
+//     return uct_iface_set_am_handler(iface, id, (handle != 0) ? ucxgo_uct_am_cb : NULL,
+//                                     (void*)(uintptr_t)handle, 0);
+// }
+//
+// static void ucxgo_uct_completion_cb(uct_completion_t *self) {
+//     ucxgo_uct_completion_t *comp = (ucxgo_uct_completion_t*)self;
+//     uint64_t handle              = comp->handle;
+//     ucs_status_t status          = self->status;
+//
+//     free(comp);
+//     ucxgo_uctComplete(handle, status);
+// }
+//
+// static uct_completion_t *ucxgo_uct_completion_create(uint64_t handle) {
+//     ucxgo_uct_completion_t *comp = malloc(sizeof(*comp));
+//
+//     if (comp == NULL) {
+//         return NULL;
+//     }
+//
+//     comp->super.func   = ucxgo_uct_completion_cb;
+//     comp->super.count  = 1;
+//     comp->super.status = UCS_OK;
+//     comp->handle       = handle;
+//     return &comp->super;
+// }
+//
+// static size_t ucxgo_uct_pack(void *dest, void *arg) {
+//     ucxgo_uct_pack_arg_t *pack = arg;
+//
+//     memcpy(dest, pack->buffer, pack->length);
+//     return pack->length;
+// }
+//
+// static ssize_t ucxgo_uct_am_bcopy(uct_ep_h ep, uint8_t id, const void *buffer, size_t length) {
+//     ucxgo_uct_pack_arg_t pack = {buffer, length};
+//
+//     return uct_ep_am_bcopy(ep, id, ucxgo_uct_pack, &pack, 0);
+// }
+//
+// static ucs_status_t ucxgo_uct_ep_create(uct_iface_h iface, const void *dev_addr,
+//                                         const void *iface_addr, uct_ep_h *ep_p) {
+//     uct_ep_params_t params = {0};
+//
+//     params.field_mask = UCT_EP_PARAM_FIELD_IFACE | UCT_EP_PARAM_FIELD_DEV_ADDR |
+//                         UCT_EP_PARAM_FIELD_IFACE_ADDR;
+//     params.iface      = iface;
+//     params.dev_addr   = dev_addr;
+//     params.iface_addr = iface_addr;
+//     return uct_ep_create(&params, ep_p);
+// }
+//
+// static ucs_status_t ucxgo_uct_iface_open(uct_md_h md, uct_worker_h worker,
+//                                          const char *tl_name, const char *dev_name,
+//                                          uct_iface_h *iface_p) {
+//     uct_iface_params_t params = {0};
+//     uct_iface_config_t *config;
+//     ucs_status_t status;
+//
+//     params.field_mask           = UCT_IFACE_PARAM_FIELD_OPEN_MODE |
+//                                   UCT_IFACE_PARAM_FIELD_DEVICE    |
+//                                   UCT_IFACE_PARAM_FIELD_RX_HEADROOM;
+//     params.open_mode            = UCT_IFACE_OPEN_MODE_DEVICE;
+//     params.mode.device.tl_name  = tl_name;
+//     params.mode.device.dev_name = dev_name;
+//     params.rx_headroom          = 0;
+//
+//     status = uct_md_iface_config_read(md, tl_name, NULL, NULL, &config);
+//     if (status != UCS_OK) {
+//         return status;
+//     }
+//
+//     status = uct_iface_open(md, worker, &params, config, iface_p);
+//     uct_config_release(config);
+//     return status;
+// }
+//
+// static ucs_status_t ucxgo_uct_md_open(uct_component_h component, const char *md_name,
+//                                       uct_md_h *md_p) {
+//     uct_md_config_t *config;
+//     ucs_status_t status;
+//
+//     status = uct_md_config_read(component, NULL, NULL, &config);
+//     if (status != UCS_OK) {
+//         return status;
+//     }
+//
+//     status = uct_md_open(component, md_name, config, md_p);
+//     uct_config_release(config);
+//     return status;
+// }
+import "C"
+import (
+	. "ucx"
+	"ucx/ucxinfo"
+	"unsafe"
+)
+
+// Worker progresses the interfaces, that are opened on it.
+type Worker struct {
+	async  *C.ucs_async_context_t
+	worker C.uct_worker_h
+}
+
+// Interface of the transport on the device, e.g. "posix" on "memory", which
+// receives the Active Messages of the endpoints connected to it.
+type Iface struct {
+	worker *Worker
+	md     C.uct_md_h
+	iface  C.uct_iface_h
+	attrs  IfaceAttributes
+	// Lengths of the addresses, that Iface.Address() returns
+	deviceAddrLen uint64
+	ifaceAddrLen  uint64
+	// Handles of the Active Message handlers by their ids
+	amHandlers map[uint8]uint64
+}
+
+type IfaceAttributes struct {
+	Flags ucxinfo.IfaceFlag
+	Am    ucxinfo.Limits
+	// Maximal header of Ep.AmZcopy()
+	MaxAmHeader uint64
+	// Whether the memory domain of the interface registers the memory for
+	// the zero-copy operations, see Iface.RegisterMemory()
+	RegisterMemory bool
+}
+
+// Endpoint to the remote interface.
+type Ep struct {
+	iface *Iface
+	ep    C.uct_ep_h
+}
+
+// Memory, that is registered for the zero-copy operations by the memory
+// domain of the interface.
+type Memory struct {
+	md      C.uct_md_h
+	memh    C.uct_mem_h
+	Address unsafe.Pointer
+	Length  uint64
+}
+
+// Buffer of the zero-copy operation, which must be in the registered memory.
+type Iov struct {
+	Buffer unsafe.Pointer
+	Length uint64
+	Memory *Memory
+}
+
+func newError(status C.ucs_status_t) error {
+	return NewUcxError(UcsStatus(status))
+}
+
+// This routine creates the worker, that progresses the interfaces opened on it
+// from one goroutine.
+func NewWorker() (*Worker, error) {
+	w := &Worker{}
+	if status := C.ucs_async_context_create(C.UCS_ASYNC_MODE_THREAD_SPINLOCK, &w.async); status != C.UCS_OK {
+		return nil, newError(status)
+	}
+
+	if status := C.uct_worker_create(w.async, C.UCS_THREAD_MODE_SINGLE, &w.worker); status != C.UCS_OK {
+		C.ucs_async_context_destroy(w.async)
+		return nil, newError(status)
+	}
+	return w, nil
+}
+
+// This routine progresses the communications of the interfaces, and invokes
+// their handlers and completions. Returns the number of the progressed events.
+func (w *Worker) Progress() uint {
+	return uint(C.uct_worker_progress(w.worker))
+}
+
+// Destroys the worker, once all its interfaces are closed.
+func (w *Worker) Close() {
+	if w.worker != nil {
+		C.uct_worker_destroy(w.worker)
+		C.ucs_async_context_destroy(w.async)
+		w.worker = nil
+	}
+}
+
+// This routine opens the interface of the transport on the device, as they are
+// reported by ucxinfo.Devices(), e.g. "self" on "memory". The progress of the
+// interface is enabled, so its events are progressed by Worker.Progress().
+func (w *Worker) OpenIface(transport string, device string) (*Iface, error) {
+	var components *C.uct_component_h
+	var numComponents C.uint
+
+	if status := C.uct_query_components(&components, &numComponents); status != C.UCS_OK {
+		return nil, newError(status)
+	}
+	defer C.uct_release_component_list(components)
+
+	n := int(numComponents)
+	for _, component := range (*[1 << 16]C.uct_component_h)(unsafe.Pointer(components))[:n:n] {
+		if iface, err := w.openComponentIface(component, transport, device); (iface != nil) || (err != nil) {
+			return iface, err
+		}
+	}
+	return nil, ErrNoDevice
+}
+
+func (w *Worker) openComponentIface(component C.uct_component_h, transport string,
+	device string) (*Iface, error) {
+	var componentAttr C.uct_component_attr_t
+
+	componentAttr.field_mask = C.UCT_COMPONENT_ATTR_FIELD_MD_RESOURCE_COUNT
+	if status := C.uct_component_query(component, &componentAttr); status != C.UCS_OK {
+		return nil, newError(status)
+	}
+
+	n := int(componentAttr.md_resource_count)
+	if n == 0 {
+		return nil, nil
+	}
+
+	mdResources := AllocateNativeMemory(uint64(n) * C.sizeof_uct_md_resource_desc_t)
+	defer FreeNativeMemory(mdResources)
+
+	componentAttr.field_mask = C.UCT_COMPONENT_ATTR_FIELD_MD_RESOURCES
+	componentAttr.md_resources = (*C.uct_md_resource_desc_t)(mdResources)
+	if status := C.uct_component_query(component, &componentAttr); status != C.UCS_OK {
+		return nil, newError(status)
+	}
+
+	for _, mdResource := range (*[1 << 16]C.uct_md_resource_desc_t)(mdResources)[:n:n] {
+		var md C.uct_md_h
+		if status := C.ucxgo_uct_md_open(component, &mdResource.md_name[0], &md); status != C.UCS_OK {
+			continue
+		}
+
+		iface, err := w.openMdIface(md, transport, device)
+		if (iface == nil) || (err != nil) {
+			C.uct_md_close(md)
+		}
+
+		if (iface != nil) || (err != nil) {
+			return iface, err
+		}
+	}
+	return nil, nil
+}
+
+// Opens the interface, if the memory domain has the resource of the transport on
+// the device.
+func (w *Worker) openMdIface(md C.uct_md_h, transport string, device string) (*Iface, error) {
+	var resources *C.uct_tl_resource_desc_t
+	var numResources C.uint
+
+	if status := C.uct_md_query_tl_resources(md, &resources, &numResources); status != C.UCS_OK {
+		return nil, nil
+	}
+	defer C.uct_release_tl_resource_list(resources)
+
+	n := int(numResources)
+	for _, resource := range (*[1 << 16]C.uct_tl_resource_desc_t)(unsafe.Pointer(resources))[:n:n] {
+		if (C.GoString(&resource.tl_name[0]) != transport) || (C.GoString(&resource.dev_name[0]) != device) {
+			continue
+		}
+
+		iface := &Iface{worker: w, md: md, amHandlers: make(map[uint8]uint64)}
+		if status := C.ucxgo_uct_iface_open(md, w.worker, &resource.tl_name[0], &resource.dev_name[0],
+			&iface.iface); status != C.UCS_OK {
+			return nil, newError(status)
+		}
+
+		if err := iface.query(); err != nil {
+			C.uct_iface_close(iface.iface)
+			return nil, err
+		}
+
+		C.uct_iface_progress_enable(iface.iface, C.UCT_PROGRESS_SEND|C.UCT_PROGRESS_RECV)
+		return iface, nil
+	}
+	return nil, nil
+}
+
+func (i *Iface) query() error {
+	var ifaceAttr C.uct_iface_attr_t
+	var mdAttr C.uct_md_attr_t
+
+	if status := C.uct_iface_query(i.iface, &ifaceAttr); status != C.UCS_OK {
+		return newError(status)
+	}
+
+	if status := C.uct_md_query(i.md, &mdAttr); status != C.UCS_OK {
+		return newError(status)
+	}
+
+	i.attrs = IfaceAttributes{
+		Flags: ucxinfo.IfaceFlag(ifaceAttr.cap.flags),
+		Am: ucxinfo.Limits{
+			MaxShort: uint64(ifaceAttr.cap.am.max_short),
+			MaxBcopy: uint64(ifaceAttr.cap.am.max_bcopy),
+			MinZcopy: uint64(ifaceAttr.cap.am.min_zcopy),
+			MaxZcopy: uint64(ifaceAttr.cap.am.max_zcopy),
+		},
+		MaxAmHeader:    uint64(ifaceAttr.cap.am.max_hdr),
+		RegisterMemory: (mdAttr.cap.flags & C.UCT_MD_FLAG_REG) != 0,
+	}
+	i.deviceAddrLen = uint64(ifaceAttr.device_addr_len)
+	i.ifaceAddrLen = uint64(ifaceAttr.iface_addr_len)
+	return nil
+}
+
+func (i *Iface) Attributes() IfaceAttributes {
+	return i.attrs
+}
+
+// Reports whether the interface supports all the capabilities of the flags.
+func (i *Iface) Has(flags ucxinfo.IfaceFlag) bool {
+	return (i.attrs.Flags & flags) == flags
+}
+
+// This routine returns the addresses of the device and of the interface, that
+// the remote side passes to Iface.Connect().
+func (i *Iface) Address() (deviceAddr []byte, ifaceAddr []byte, err error) {
+	if !i.Has(ucxinfo.UCT_IFACE_FLAG_CONNECT_TO_IFACE) {
+		return nil, nil, ErrUnsupported
+	}
+
+	deviceAddr = make([]byte, i.deviceAddrLen+1)
+	ifaceAddr = make([]byte, i.ifaceAddrLen+1)
+	if status := C.uct_iface_get_device_address(i.iface,
+		(*C.uct_device_addr_t)(unsafe.Pointer(&deviceAddr[0]))); status != C.UCS_OK {
+		return nil, nil, newError(status)
+	}
+
+	if status := C.uct_iface_get_address(i.iface,
+		(*C.uct_iface_addr_t)(unsafe.Pointer(&ifaceAddr[0]))); status != C.UCS_OK {
+		return nil, nil, newError(status)
+	}
+	return deviceAddr[:i.deviceAddrLen], ifaceAddr[:i.ifaceAddrLen], nil
+}
+
+// This routine installs the handler of the Active Messages with the id, nil
+// handler removes it. The data of the message is valid only until the handler
+// returns.
+func (i *Iface) SetAmHandler(id uint8, handler AmHandler) error {
+	var handle uint64
+	if handler != nil {
+		handle = registerAmHandler(handler)
+	}
+
+	if status := C.ucxgo_uct_set_am_handler(i.iface, C.uint8_t(id), C.uint64_t(handle)); status != C.UCS_OK {
+		deregisterAmHandler(handle)
+		return newError(status)
+	}
+
+	deregisterAmHandler(i.amHandlers[id])
+	delete(i.amHandlers, id)
+	if handle != 0 {
+		i.amHandlers[id] = handle
+	}
+	return nil
+}
+
+// This routine creates the endpoint to the remote interface of the addresses,
+// that are returned by Iface.Address() of the remote side.
+func (i *Iface) Connect(deviceAddr []byte, ifaceAddr []byte) (*Ep, error) {
+	if !i.Has(ucxinfo.UCT_IFACE_FLAG_CONNECT_TO_IFACE) {
+		return nil, ErrUnsupported
+	}
+
+	// The addresses are copied, so the endpoint doesn't keep the Go memory
+	cDeviceAddr := C.CBytes(append(deviceAddr, 0))
+	defer C.free(cDeviceAddr)
+	cIfaceAddr := C.CBytes(append(ifaceAddr, 0))
+	defer C.free(cIfaceAddr)
+
+	ep := &Ep{iface: i}
+	if status := C.ucxgo_uct_ep_create(i.iface, cDeviceAddr, cIfaceAddr, &ep.ep); status != C.UCS_OK {
+		return nil, newError(status)
+	}
+	return ep, nil
+}
+
+// This routine registers the native memory, e.g. allocated by
+// ucx.AllocateNativeMemory(), for the zero-copy operations of the interface.
+func (i *Iface) RegisterMemory(address unsafe.Pointer, length uint64) (*Memory, error) {
+	if !i.attrs.RegisterMemory {
+		return nil, ErrUnsupported
+	}
+
+	memory := &Memory{md: i.md, Address: address, Length: length}
+	if status := C.uct_md_mem_reg(i.md, address, C.size_t(length), C.UCT_MD_MEM_ACCESS_ALL,
+		&memory.memh); status != C.UCS_OK {
+		return nil, newError(status)
+	}
+	return memory, nil
+}
+
+func (m *Memory) Close() error {
+	if m.memh == nil {
+		return nil
+	}
+
+	memh := m.memh
+	m.memh = nil
+	if status := C.uct_md_mem_dereg(m.md, memh); status != C.UCS_OK {
+		return newError(status)
+	}
+	return nil
+}
+
+// Closes the interface, once its endpoints are closed and its memory is
+// deregistered.
+func (i *Iface) Close() {
+	if i.iface == nil {
+		return
+	}
+
+	C.uct_iface_close(i.iface)
+	C.uct_md_close(i.md)
+	i.iface = nil
+	for _, handle := range i.amHandlers {
+		deregisterAmHandler(handle)
+	}
+	i.amHandlers = nil
+}
+
+// This routine sends the short Active Message, which is the header followed
+// by the payload, up to IfaceAttributes.Am.MaxShort bytes. The handler of the
+// receiver gets the header in the first 8 bytes of the data. Fails with
+// ucx.ErrNoResource, if the transport has no resources to send it now.
+func (e *Ep) AmShort(id uint8, header uint64, payload []byte) error {
+	var buffer unsafe.Pointer
+	if len(payload) > 0 {
+		buffer = unsafe.Pointer(&payload[0])
+	}
+
+	if status := C.uct_ep_am_short(e.ep, C.uint8_t(id), C.uint64_t(header), buffer,
+		C.uint(len(payload))); status != C.UCS_OK {
+		return newError(status)
+	}
+	return nil
+}
+
+// This routine copies the data to the buffer of the transport and sends it as
+// the Active Message, up to IfaceAttributes.Am.MaxBcopy bytes. Returns the
+// number of the sent bytes.
+func (e *Ep) AmBcopy(id uint8, data []byte) (int, error) {
+	var buffer unsafe.Pointer
+	if len(data) > 0 {
+		buffer = unsafe.Pointer(&data[0])
+	}
+
+	length := C.ucxgo_uct_am_bcopy(e.ep, C.uint8_t(id), buffer, C.size_t(len(data)))
+	if length < 0 {
+		return 0, newError(C.ucs_status_t(length))
+	}
+	return int(length), nil
+}
+
+// Posts the operation with the completion of the callback, which is invoked
+// once the operation, that is in progress, completes. The callback is invoked
+// by this routine, if the operation completes immediately, and isn't invoked
+// at all, if it fails.
+func complete(cb CompletionCallback, op func(comp *C.uct_completion_t) C.ucs_status_t) error {
+	handle := registerCompletion(cb)
+	comp := C.ucxgo_uct_completion_create(C.uint64_t(handle))
+	if comp == nil {
+		deregisterCompletion(handle)
+		return ErrNoMemory
+	}
+
+	status := op(comp)
+	if status == C.UCS_INPROGRESS {
+		return nil
+	}
+
+	C.free(unsafe.Pointer(comp))
+	deregisterCompletion(handle)
+	if status != C.UCS_OK {
+		return newError(status)
+	}
+
+	if cb != nil {
+		cb(UCS_OK)
+	}
+	return nil
+}
+
+// This routine sends the Active Message of the header and the registered
+// buffers without the copy. The buffers must not be modified until the
+// callback is invoked with the completion status.
+func (e *Ep) AmZcopy(id uint8, header []byte, iov []Iov, cb CompletionCallback) error {
+	var cHeader unsafe.Pointer
+	if len(header) > 0 {
+		cHeader = unsafe.Pointer(&header[0])
+	}
+
+	cIov := make([]C.uct_iov_t, len(iov))
+	for i, buffer := range iov {
+		if buffer.Memory == nil {
+			return ErrInvalidParam
+		}
+
+		cIov[i].buffer = buffer.Buffer
+		cIov[i].length = C.size_t(buffer.Length)
+		cIov[i].memh = buffer.Memory.memh
+		cIov[i].count = 1
+	}
+
+	var cIovPtr *C.uct_iov_t
+	if len(cIov) > 0 {
+		cIovPtr = &cIov[0]
+	}
+
+	return complete(cb, func(comp *C.uct_completion_t) C.ucs_status_t {
+		return C.uct_ep_am_zcopy(e.ep, C.uint8_t(id), cHeader, C.uint(len(header)), cIovPtr,
+			C.size_t(len(cIov)), 0, comp)
+	})
+}
+
+// This routine flushes the operations of the endpoint, so the callback is
+// invoked once they are completed locally.
+func (e *Ep) Flush(cb CompletionCallback) error {
+	return complete(cb, func(comp *C.uct_completion_t) C.ucs_status_t {
+		return C.uct_ep_flush(e.ep, C.UCT_FLUSH_FLAG_LOCAL, comp)
+	})
+}
+
+// Destroys the endpoint without waiting for its operations, which have to be
+// flushed before.
+func (e *Ep) Close() {
+	if e.ep != nil {
+		C.uct_ep_destroy(e.ep)
+		e.ep = nil
+	}
+}
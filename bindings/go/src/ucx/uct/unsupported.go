@@ -0,0 +1,111 @@
+//go:build !linux || !cgo
+// +build !linux !cgo
+
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package uct
+
+import (
+	"fmt"
+	. "ucx"
+	"ucx/ucxinfo"
+	"unsafe"
+)
+
+// UCT runs only on Linux, so on the other platforms, or with CGO_ENABLED=0,
+// the package is built of these stubs with the same API, same as the ucx
+// package. The stubs fail with the error, which matches ucx.ErrUnsupported.
+var errUnsupportedPlatform = fmt.Errorf("%w: unsupported platform, UCT requires Linux and cgo", ErrUnsupported)
+
+type AmHandler = func(data []byte)
+
+type CompletionCallback = func(status UcsStatus)
+
+type Worker struct{}
+
+type Iface struct{}
+
+type IfaceAttributes struct {
+	Flags          ucxinfo.IfaceFlag
+	Am             ucxinfo.Limits
+	MaxAmHeader    uint64
+	RegisterMemory bool
+}
+
+type Ep struct{}
+
+type Memory struct {
+	Address unsafe.Pointer
+	Length  uint64
+}
+
+type Iov struct {
+	Buffer unsafe.Pointer
+	Length uint64
+	Memory *Memory
+}
+
+func NewWorker() (*Worker, error) {
+	return nil, errUnsupportedPlatform
+}
+
+func (w *Worker) Progress() uint {
+	return 0
+}
+
+func (w *Worker) Close() {}
+
+func (w *Worker) OpenIface(transport string, device string) (*Iface, error) {
+	return nil, errUnsupportedPlatform
+}
+
+func (i *Iface) Attributes() IfaceAttributes {
+	return IfaceAttributes{}
+}
+
+func (i *Iface) Has(flags ucxinfo.IfaceFlag) bool {
+	return false
+}
+
+func (i *Iface) Address() ([]byte, []byte, error) {
+	return nil, nil, errUnsupportedPlatform
+}
+
+func (i *Iface) SetAmHandler(id uint8, handler AmHandler) error {
+	return errUnsupportedPlatform
+}
+
+func (i *Iface) Connect(deviceAddr []byte, ifaceAddr []byte) (*Ep, error) {
+	return nil, errUnsupportedPlatform
+}
+
+func (i *Iface) RegisterMemory(address unsafe.Pointer, length uint64) (*Memory, error) {
+	return nil, errUnsupportedPlatform
+}
+
+func (m *Memory) Close() error {
+	return errUnsupportedPlatform
+}
+
+func (i *Iface) Close() {}
+
+func (e *Ep) AmShort(id uint8, header uint64, payload []byte) error {
+	return errUnsupportedPlatform
+}
+
+func (e *Ep) AmBcopy(id uint8, data []byte) (int, error) {
+	return 0, errUnsupportedPlatform
+}
+
+func (e *Ep) AmZcopy(id uint8, header []byte, iov []Iov, cb CompletionCallback) error {
+	return errUnsupportedPlatform
+}
+
+func (e *Ep) Flush(cb CompletionCallback) error {
+	return errUnsupportedPlatform
+}
+
+func (e *Ep) Close() {}
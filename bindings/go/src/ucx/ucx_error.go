@@ -5,28 +5,117 @@
 
 package ucx
 
-// #include <ucs/type/status.h>
-import "C"
-
 type UcxError struct {
 	msg    string
 	status UcsStatus
 }
 
-func NewUcxError(status UcsStatus) error {
+// Errors returned by the operations, which can be matched by errors.Is() or
+// compared directly, e.g. errors.Is(err, ErrConnectionReset).
+var (
+	ErrNoMessage          = makeUcxError(UCS_ERR_NO_MESSAGE)
+	ErrNoResource         = makeUcxError(UCS_ERR_NO_RESOURCE)
+	ErrIoError            = makeUcxError(UCS_ERR_IO_ERROR)
+	ErrNoMemory           = makeUcxError(UCS_ERR_NO_MEMORY)
+	ErrInvalidParam       = makeUcxError(UCS_ERR_INVALID_PARAM)
+	ErrUnreachable        = makeUcxError(UCS_ERR_UNREACHABLE)
+	ErrInvalidAddr        = makeUcxError(UCS_ERR_INVALID_ADDR)
+	ErrNotImplemented     = makeUcxError(UCS_ERR_NOT_IMPLEMENTED)
+	ErrMessageTruncated   = makeUcxError(UCS_ERR_MESSAGE_TRUNCATED)
+	ErrNoProgress         = makeUcxError(UCS_ERR_NO_PROGRESS)
+	ErrBufferTooSmall     = makeUcxError(UCS_ERR_BUFFER_TOO_SMALL)
+	ErrNoElem             = makeUcxError(UCS_ERR_NO_ELEM)
+	ErrSomeConnectsFailed = makeUcxError(UCS_ERR_SOME_CONNECTS_FAILED)
+	ErrNoDevice           = makeUcxError(UCS_ERR_NO_DEVICE)
+	ErrBusy               = makeUcxError(UCS_ERR_BUSY)
+	ErrCanceled           = makeUcxError(UCS_ERR_CANCELED)
+	ErrShmemSegment       = makeUcxError(UCS_ERR_SHMEM_SEGMENT)
+	ErrAlreadyExists      = makeUcxError(UCS_ERR_ALREADY_EXISTS)
+	ErrOutOfRange         = makeUcxError(UCS_ERR_OUT_OF_RANGE)
+	ErrTimedOut           = makeUcxError(UCS_ERR_TIMED_OUT)
+	ErrExceedsLimit       = makeUcxError(UCS_ERR_EXCEEDS_LIMIT)
+	ErrUnsupported        = makeUcxError(UCS_ERR_UNSUPPORTED)
+	ErrRejected           = makeUcxError(UCS_ERR_REJECTED)
+	ErrNotConnected       = makeUcxError(UCS_ERR_NOT_CONNECTED)
+	ErrConnectionReset    = makeUcxError(UCS_ERR_CONNECTION_RESET)
+	ErrEndpointTimeout    = makeUcxError(UCS_ERR_ENDPOINT_TIMEOUT)
+)
+
+var errorsByStatus = make(map[UcsStatus]*UcxError)
+
+// Classes of errors, that match any status of the range, e.g.
+// errors.Is(NewUcxError(UCS_ERR_ENDPOINT_TIMEOUT), ErrEndpointFailure).
+var (
+	ErrLinkFailure error = &ucxErrorClass{
+		msg:   "Link failure",
+		first: UCS_ERR_FIRST_LINK_FAILURE,
+		last:  UCS_ERR_LAST_LINK_FAILURE,
+	}
+	ErrEndpointFailure error = &ucxErrorClass{
+		msg:   "Endpoint failure",
+		first: UCS_ERR_FIRST_ENDPOINT_FAILURE,
+		last:  UCS_ERR_LAST_ENDPOINT_FAILURE,
+	}
+)
+
+// Range of statuses from first down to last, since errors are negative.
+type ucxErrorClass struct {
+	msg   string
+	first UcsStatus
+	last  UcsStatus
+}
+
+func (c *ucxErrorClass) Error() string { return c.msg }
+
+func makeUcxError(status UcsStatus) *UcxError {
 	return &UcxError{
-		msg:    C.GoString(C.ucs_status_string(C.ucs_status_t(status))),
+		msg:    status.String(),
 		status: status,
 	}
 }
 
-func newUcxError(status C.ucs_status_t) error {
-	return &UcxError{
-		msg:    C.GoString(C.ucs_status_string(status)),
-		status: UcsStatus(status),
+func init() {
+	for _, err := range []*UcxError{ErrNoMessage, ErrNoResource, ErrIoError, ErrNoMemory,
+		ErrInvalidParam, ErrUnreachable, ErrInvalidAddr, ErrNotImplemented, ErrMessageTruncated,
+		ErrNoProgress, ErrBufferTooSmall, ErrNoElem, ErrSomeConnectsFailed, ErrNoDevice, ErrBusy,
+		ErrCanceled, ErrShmemSegment, ErrAlreadyExists, ErrOutOfRange, ErrTimedOut,
+		ErrExceedsLimit, ErrUnsupported, ErrRejected, ErrNotConnected, ErrConnectionReset,
+		ErrEndpointTimeout} {
+		errorsByStatus[err.status] = err
 	}
 }
 
+// Returns the error of the status. Errors of the known statuses are the same
+// as the corresponding Err* variables.
+func NewUcxError(status UcsStatus) error {
+	if err, found := errorsByStatus[status]; found {
+		return err
+	}
+	return makeUcxError(status)
+}
+
 func (e *UcxError) Error() string { return e.msg }
 
 func (e *UcxError) GetStatus() UcsStatus { return e.status }
+
+// Reports whether the target is UcxError with the same status, or the class
+// of errors, that includes the status. So errors can be matched by
+// errors.Is(err, ErrConnectionReset) or errors.Is(err, ErrEndpointFailure).
+func (e *UcxError) Is(target error) bool {
+	switch t := target.(type) {
+	case *UcxError:
+		return t.status == e.status
+	case *ucxErrorClass:
+		return (e.status <= t.first) && (e.status >= t.last)
+	}
+	return false
+}
+
+// Returns the error of the status, which is passed to the completion
+// callbacks, or nil if the status is not an error.
+func (m UcsStatus) Err() error {
+	if m >= UCS_OK {
+		return nil
+	}
+	return NewUcxError(m)
+}
@@ -0,0 +1,193 @@
+//go:build go1.18
+// +build go1.18
+
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+// Package ucxcodec sends and receives Go values as UCP tag messages, using
+// user-supplied codecs (gob, protobuf, flatbuffers, etc.). The encoding and
+// native buffers are reused between the messages.
+package ucxcodec
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"encoding/json"
+	"runtime"
+	"sync"
+	. "ucx"
+	"unsafe"
+)
+
+// Codec encodes and decodes values of type T.
+type Codec[T any] interface {
+	// Appends the encoded value to buf and returns the extended buffer.
+	Marshal(buf []byte, value T) ([]byte, error)
+
+	// Decodes the data to the value. The data is reused once Unmarshal
+	// returns, so the value must not reference it.
+	Unmarshal(data []byte, value *T) error
+}
+
+// Codec, that is built from a pair of functions, e.g. for protobuf:
+//
+//	CodecFuncs[*pb.Msg]{
+//		MarshalFunc:   func(b []byte, m *pb.Msg) ([]byte, error) { return proto.MarshalOptions{}.MarshalAppend(b, m) },
+//		UnmarshalFunc: func(d []byte, m **pb.Msg) error { *m = &pb.Msg{}; return proto.Unmarshal(d, *m) },
+//	}
+type CodecFuncs[T any] struct {
+	MarshalFunc   func(buf []byte, value T) ([]byte, error)
+	UnmarshalFunc func(data []byte, value *T) error
+}
+
+func (c CodecFuncs[T]) Marshal(buf []byte, value T) ([]byte, error) {
+	return c.MarshalFunc(buf, value)
+}
+
+func (c CodecFuncs[T]) Unmarshal(data []byte, value *T) error {
+	return c.UnmarshalFunc(data, value)
+}
+
+// Codec based on encoding/gob. Every message is encoded in a separate gob
+// stream, so it carries the type information.
+type GobCodec[T any] struct{}
+
+func (GobCodec[T]) Marshal(buf []byte, value T) ([]byte, error) {
+	b := bytes.NewBuffer(buf)
+	err := gob.NewEncoder(b).Encode(value)
+	return b.Bytes(), err
+}
+
+func (GobCodec[T]) Unmarshal(data []byte, value *T) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(value)
+}
+
+// Codec based on encoding/json, which the peers of the other languages decode.
+type JSONCodec[T any] struct{}
+
+func (JSONCodec[T]) Marshal(buf []byte, value T) ([]byte, error) {
+	data, err := json.Marshal(value)
+	return append(buf, data...), err
+}
+
+func (JSONCodec[T]) Unmarshal(data []byte, value *T) error {
+	return json.Unmarshal(data, value)
+}
+
+// Codec of the data as is, e.g. the data encoded by the application. The
+// decoded value is the copy of the data.
+type RawCodec struct{}
+
+func (RawCodec) Marshal(buf []byte, value []byte) ([]byte, error) {
+	return append(buf, value...), nil
+}
+
+func (RawCodec) Unmarshal(data []byte, value *[]byte) error {
+	*value = append([]byte(nil), data...)
+	return nil
+}
+
+// Native buffer of the message and the encoding buffer, which are reused
+// between the messages.
+type buffer struct {
+	native  unsafe.Pointer
+	size    uint64
+	encoded []byte
+}
+
+var buffers = sync.Pool{
+	New: func() interface{} {
+		b := &buffer{}
+		runtime.SetFinalizer(b, func(b *buffer) {
+			if b.native != nil {
+				FreeNativeMemory(b.native)
+			}
+		})
+		return b
+	},
+}
+
+// Returns the native memory of at least size bytes.
+func (b *buffer) reserve(size uint64) unsafe.Pointer {
+	if size == 0 {
+		size = 1
+	}
+
+	if b.size < size {
+		if b.native != nil {
+			FreeNativeMemory(b.native)
+		}
+		b.native = AllocateNativeMemory(size)
+		b.size = size
+	}
+	return b.native
+}
+
+// Send encodes the value by the codec and sends it with the tag to the
+// endpoint. The routine progresses the worker until the message is sent or the
+// ctx is done.
+func Send[T any](ctx context.Context, ep *UcpEp, tag uint64, value T, codec Codec[T]) error {
+	b := buffers.Get().(*buffer)
+	defer buffers.Put(b)
+
+	encoded, err := codec.Marshal(b.encoded[:0], value)
+	if err != nil {
+		return err
+	}
+	b.encoded = encoded
+
+	size := uint64(len(encoded))
+	native := b.reserve(size)
+	copy(unsafe.Slice((*byte)(native), size), encoded)
+
+	request, err := ep.SendTagNonBlocking(tag, native, size, nil)
+	if err != nil {
+		return err
+	}
+	defer request.Close()
+
+	return request.WaitContext(ctx)
+}
+
+// Recv receives the message, that matches the tag and tagMask, and decodes it
+// by the codec. The routine progresses the worker until the message is
+// received or the ctx is done.
+func Recv[T any](ctx context.Context, worker *UcpWorker, tag uint64, tagMask uint64,
+	codec Codec[T]) (T, error) {
+	var value T
+
+	message := worker.TagProbe(tag, tagMask, true)
+	for message == nil {
+		select {
+		case <-ctx.Done():
+			return value, ctx.Err()
+		default:
+		}
+
+		worker.Progress()
+		message = worker.TagProbe(tag, tagMask, true)
+	}
+
+	b := buffers.Get().(*buffer)
+	defer buffers.Put(b)
+
+	size := message.Info.Length
+	native := b.reserve(size)
+
+	// The message is already matched, so the receive can't be canceled
+	request, err := worker.RecvTagMsgNonBlocking(native, size, message, nil)
+	if err != nil {
+		return value, err
+	}
+	defer request.Close()
+
+	if err := request.WaitContext(context.Background()); err != nil {
+		return value, err
+	}
+
+	err = codec.Unmarshal(unsafe.Slice((*byte)(native), size), &value)
+	return value, err
+}
@@ -0,0 +1,324 @@
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+// Package ucxcoll implements the collective operations, e.g. barrier,
+// broadcast, allreduce and allgather, over the group of UCP endpoints with the
+// tag matching. Every member of the group calls the same collectives in the
+// same order, and each of them returns once the member's part is complete.
+package ucxcoll
+
+import (
+	"errors"
+	"fmt"
+	. "ucx"
+	"unsafe"
+)
+
+var (
+	ErrRank      = errors.New("ucxcoll: rank is out of the group")
+	ErrSize      = errors.New("ucxcoll: buffer sizes don't match")
+	ErrGroupSize = errors.New("ucxcoll: group is too large")
+)
+
+// Tag of the collective messages: the group id, the sequence number of the
+// collective and the rank of the sender.
+const (
+	rankBits = 20
+	seqBits  = 28
+	maxRanks = 1 << rankBits
+	seqMask  = 1<<seqBits - 1
+)
+
+type Config struct {
+	// Identifies the group, so the collectives of the groups, that share the
+	// worker, don't intercept each other's messages. The other tags of the
+	// application must not match the tag of any group, i.e. their upper 16
+	// bits must differ from the group ids.
+	GroupId uint16
+}
+
+// Member of the group, which ranks are the indices of the endpoints. The
+// routines must be called from the goroutine, that progresses the worker.
+type Group struct {
+	worker *UcpWorker
+	rank   int
+	eps    []*UcpEp
+	config Config
+	seq    uint64
+}
+
+// Creates the member of the group with the endpoints to all the members by
+// their ranks, the endpoint of the member itself isn't used and may be nil.
+// The context of the worker must be created with UcpParams.EnableTag().
+func NewGroup(worker *UcpWorker, rank int, eps []*UcpEp, config Config) (*Group, error) {
+	if len(eps) > maxRanks {
+		return nil, ErrGroupSize
+	}
+
+	if (rank < 0) || (rank >= len(eps)) {
+		return nil, ErrRank
+	}
+
+	for peer, ep := range eps {
+		if (ep == nil) && (peer != rank) {
+			return nil, fmt.Errorf("ucxcoll: endpoint to rank %v is not set", peer)
+		}
+	}
+
+	return &Group{
+		worker: worker,
+		rank:   rank,
+		eps:    append([]*UcpEp(nil), eps...),
+		config: config,
+	}, nil
+}
+
+func (g *Group) Rank() int {
+	return g.rank
+}
+
+func (g *Group) Size() int {
+	return len(g.eps)
+}
+
+// Starts the next collective, and returns its tag without the sender rank.
+func (g *Group) next() uint64 {
+	seq := g.seq & seqMask
+	g.seq++
+	return uint64(g.config.GroupId)<<(seqBits+rankBits) | seq<<rankBits
+}
+
+// Progresses the worker until all the requests complete, and closes them.
+// Returns the error of the first failed request.
+func (g *Group) wait(requests ...*UcpRequest) error {
+	var firstErr error
+	for _, request := range requests {
+		for request.GetStatus() == UCS_INPROGRESS {
+			g.worker.Progress()
+		}
+
+		if status := request.GetStatus(); (status != UCS_OK) && (firstErr == nil) {
+			firstErr = NewUcxError(status)
+		}
+		request.Close()
+	}
+	return firstErr
+}
+
+func (g *Group) send(tag uint64, peer int, data []byte) (*UcpRequest, error) {
+	request, err := g.eps[peer].SendTagBytesNonBlocking(tag|uint64(g.rank), data, nil)
+	if err != nil {
+		request.Close()
+		return nil, err
+	}
+	return request, nil
+}
+
+// Native buffer of the receive, which data is copied to the Go memory once the
+// receive completes, since Go memory can't be pinned for the receive.
+type recvBuffer struct {
+	request *UcpRequest
+	native  unsafe.Pointer
+	data    []byte
+}
+
+func (g *Group) recv(tag uint64, peer int, data []byte) (*recvBuffer, error) {
+	size := uint64(len(data))
+	if size == 0 {
+		size = 1
+	}
+
+	native := AllocateNativeMemory(size)
+	request, err := g.worker.RecvTagNonBlocking(native, uint64(len(data)), tag|uint64(peer), ^uint64(0), nil)
+	if err != nil {
+		request.Close()
+		FreeNativeMemory(native)
+		return nil, err
+	}
+	return &recvBuffer{request: request, native: native, data: data}, nil
+}
+
+// Waits for the receive and copies its data.
+func (g *Group) complete(r *recvBuffer) error {
+	defer FreeNativeMemory(r.native)
+	if err := g.wait(r.request); err != nil {
+		return err
+	}
+
+	copy(r.data, (*[1 << 40]byte)(r.native)[:len(r.data):len(r.data)])
+	return nil
+}
+
+// Exchanges the data with the peers: sends to the first peer and receives from
+// the second one concurrently.
+func (g *Group) sendRecv(tag uint64, to int, send []byte, from int, recv []byte) error {
+	r, err := g.recv(tag, from, recv)
+	if err != nil {
+		return err
+	}
+
+	request, err := g.send(tag, to, send)
+	if err != nil {
+		r.request.Cancel()
+		g.complete(r)
+		return err
+	}
+
+	sendErr := g.wait(request)
+	if err := g.complete(r); err != nil {
+		return err
+	}
+	return sendErr
+}
+
+// Blocks until all the members of the group enter the barrier, by the
+// dissemination algorithm in log2(size) rounds.
+func (g *Group) Barrier() error {
+	tag := g.next()
+	size := len(g.eps)
+	for distance := 1; distance < size; distance *= 2 {
+		to := (g.rank + distance) % size
+		from := (g.rank - distance + size) % size
+		if err := g.sendRecv(tag, to, nil, from, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Copies the buffer of the root to the buffers of the other members by the
+// binomial tree, the buffers must be of the same size on all the members.
+func (g *Group) Broadcast(buffer []byte, root int) error {
+	if (root < 0) || (root >= len(g.eps)) {
+		return ErrRank
+	}
+
+	tag := g.next()
+	size := len(g.eps)
+	// Rank relative to the root, which is the root of the tree
+	relative := (g.rank - root + size) % size
+
+	// Receives from the parent, which differs in the lowest set bit
+	mask := 1
+	for ; mask < size; mask *= 2 {
+		if relative&mask != 0 {
+			r, err := g.recv(tag, (relative-mask+root)%size, buffer)
+			if err != nil {
+				return err
+			}
+
+			if err := g.complete(r); err != nil {
+				return err
+			}
+			break
+		}
+	}
+
+	// Sends to the children below the bit of the parent
+	var requests []*UcpRequest
+	var firstErr error
+	for mask /= 2; mask > 0; mask /= 2 {
+		if relative+mask < size {
+			request, err := g.send(tag, (relative+mask+root)%size, buffer)
+			if err != nil {
+				firstErr = err
+				break
+			}
+			requests = append(requests, request)
+		}
+	}
+
+	if err := g.wait(requests...); firstErr == nil {
+		firstErr = err
+	}
+	return firstErr
+}
+
+// Combines the data of all the members by the reduction, and stores the result
+// to recv of every member. The buffers must be of the same size on all the
+// members, and send may be the same slice as recv.
+func (g *Group) Allreduce(send []byte, recv []byte, reduce Reduction) error {
+	if len(send) != len(recv) {
+		return ErrSize
+	}
+
+	if err := g.Reduce(send, recv, reduce, 0); err != nil {
+		return err
+	}
+	return g.Broadcast(recv, 0)
+}
+
+// Combines the data of all the members by the reduction to recv of the root,
+// recv of the other members is used as the scratch buffer. The data is
+// reduced by the binomial tree, so the reduction must be associative and
+// commutative.
+func (g *Group) Reduce(send []byte, recv []byte, reduce Reduction, root int) error {
+	if len(send) != len(recv) {
+		return ErrSize
+	}
+
+	if (root < 0) || (root >= len(g.eps)) {
+		return ErrRank
+	}
+
+	tag := g.next()
+	size := len(g.eps)
+	relative := (g.rank - root + size) % size
+
+	copy(recv, send)
+	scratch := make([]byte, len(recv))
+	for mask := 1; mask < size; mask *= 2 {
+		if relative&mask != 0 {
+			request, err := g.send(tag, (relative-mask+root)%size, recv)
+			if err != nil {
+				return err
+			}
+			return g.wait(request)
+		}
+
+		if relative+mask < size {
+			r, err := g.recv(tag, (relative+mask+root)%size, scratch)
+			if err != nil {
+				return err
+			}
+
+			if err := g.complete(r); err != nil {
+				return err
+			}
+
+			if err := reduce(recv, scratch); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Gathers the send buffers of all the members to recv of every member, in the
+// order of their ranks. The send buffers must be of the same size on all the
+// members, and recv must be size times larger. The blocks are passed around
+// the ring in size-1 steps.
+func (g *Group) Allgather(send []byte, recv []byte) error {
+	size := len(g.eps)
+	block := len(send)
+	if len(recv) != block*size {
+		return ErrSize
+	}
+
+	tag := g.next()
+	copy(recv[g.rank*block:], send)
+
+	right := (g.rank + 1) % size
+	left := (g.rank - 1 + size) % size
+	for step := 0; step < size-1; step++ {
+		sendBlock := (g.rank - step + size) % size
+		recvBlock := (g.rank - step - 1 + size) % size
+		if err := g.sendRecv(tag, right, recv[sendBlock*block:(sendBlock+1)*block],
+			left, recv[recvBlock*block:(recvBlock+1)*block]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
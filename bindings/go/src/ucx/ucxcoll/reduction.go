@@ -0,0 +1,71 @@
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package ucxcoll
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// Combines the data of the other member into inout, both of the same size.
+// The predefined reductions treat the data as the little-endian array of the
+// 8-byte elements, and fail with ErrSize if the size isn't a multiple of 8.
+type Reduction func(inout []byte, in []byte) error
+
+func reduceUint64(combine func(a, b uint64) uint64) Reduction {
+	return func(inout []byte, in []byte) error {
+		if (len(inout) != len(in)) || (len(inout)%8 != 0) {
+			return ErrSize
+		}
+
+		for i := 0; i < len(inout); i += 8 {
+			a := binary.LittleEndian.Uint64(inout[i:])
+			b := binary.LittleEndian.Uint64(in[i:])
+			binary.LittleEndian.PutUint64(inout[i:], combine(a, b))
+		}
+		return nil
+	}
+}
+
+func reduceFloat64(combine func(a, b float64) float64) Reduction {
+	return reduceUint64(func(a, b uint64) uint64 {
+		return math.Float64bits(combine(math.Float64frombits(a), math.Float64frombits(b)))
+	})
+}
+
+var (
+	SumInt64 = reduceUint64(func(a, b uint64) uint64 { return a + b })
+	MaxInt64 = reduceUint64(func(a, b uint64) uint64 {
+		if int64(a) > int64(b) {
+			return a
+		}
+		return b
+	})
+	MinInt64 = reduceUint64(func(a, b uint64) uint64 {
+		if int64(a) < int64(b) {
+			return a
+		}
+		return b
+	})
+	MaxUint64 = reduceUint64(func(a, b uint64) uint64 {
+		if a > b {
+			return a
+		}
+		return b
+	})
+	MinUint64 = reduceUint64(func(a, b uint64) uint64 {
+		if a < b {
+			return a
+		}
+		return b
+	})
+	BitOr  = reduceUint64(func(a, b uint64) uint64 { return a | b })
+	BitAnd = reduceUint64(func(a, b uint64) uint64 { return a & b })
+
+	SumFloat64 = reduceFloat64(func(a, b float64) float64 { return a + b })
+	MaxFloat64 = reduceFloat64(math.Max)
+	MinFloat64 = reduceFloat64(math.Min)
+)
@@ -0,0 +1,478 @@
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+// Package ucxconn manages UCP endpoints to the peers, that are identified by
+// the application IDs. Failed connections are re-established in background,
+// and the messages sent meanwhile are queued until the peer is reachable.
+package ucxconn
+
+import (
+	"errors"
+	"sync"
+	"time"
+	. "ucx"
+	"unsafe"
+)
+
+var (
+	ErrClosed      = errors.New("ucxconn: manager is closed")
+	ErrRemoved     = errors.New("ucxconn: peer is removed")
+	ErrQueueFull   = errors.New("ucxconn: too many pending sends to the peer")
+	errNoEndpoints = errors.New("ucxconn: EndpointParams is not set")
+)
+
+type State int
+
+const (
+	// The endpoint is created and the wire-up is in progress.
+	StateConnecting State = iota
+	// The endpoint is established, the sends are posted immediately.
+	StateConnected
+	// The endpoint failed, the reconnect is scheduled after the backoff.
+	StateDisconnected
+)
+
+func (s State) String() string {
+	switch s {
+	case StateConnecting:
+		return "connecting"
+	case StateConnected:
+		return "connected"
+	case StateDisconnected:
+		return "disconnected"
+	}
+	return "unknown"
+}
+
+type Config struct {
+	// Returns the parameters of the endpoint to the peer, e.g. its socket
+	// address or worker address. It's invoked for every connection attempt.
+	// Peer error handling and the error handler are set by the manager.
+	EndpointParams func(peer string) (*UcpEpParams, error)
+
+	// Delay before the first reconnect, which is doubled after every failed
+	// attempt up to MaxBackoff. 10ms and 5s by default.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+
+	// Maximal number of sends, that are queued to the peer while it's not
+	// connected. 1024 by default.
+	MaxPending int
+
+	// Invoked from the progress loop, when the connection state of the peer
+	// changes.
+	OnStateChange func(peer string, state State)
+
+	// Time the peer stays without sends, before its endpoint is flushed and
+	// closed, so the long-running managers don't accumulate the endpoints to
+	// the peers, that are gone. The peer is idle only once its sends are
+	// completed, and its next Send connects again. Zero keeps the endpoints
+	// until they are removed, see also Manager.SetIdleTimeout().
+	IdleTimeout time.Duration
+
+	// Interval, that the idle peers are checked with, so the endpoint is
+	// closed up to the interval after its idle timeout. 1s by default.
+	SweepInterval time.Duration
+
+	// Invoked from the progress loop before the endpoint of the idle peer is
+	// closed. Returning false keeps the endpoint for another idle timeout.
+	OnIdle func(peer string) bool
+}
+
+// Manager owns the endpoints of the worker, which is progressed by the loop.
+// All the endpoint operations are executed on the loop, so the worker thread
+// mode can be any. The routines of the manager must not be called from the
+// worker callbacks.
+//
+// Sends, that are posted on the endpoint, when it fails, complete with the
+// endpoint error and are not retried, since the peer might have received them.
+type Manager struct {
+	loop    *UcpProgressLoop
+	worker  *UcpWorker
+	config  Config
+	peers   map[string]*peer
+	closed  bool
+	closing sync.WaitGroup
+	// Stops the sweeper of the idle peers, nil until it's started
+	sweeperStop chan struct{}
+}
+
+type peer struct {
+	id      string
+	state   State
+	ep      *UcpEp
+	attempt uint64
+	backoff time.Duration
+	timer   *time.Timer
+	pending []*send
+	// Sends, that are posted and not completed yet
+	active int
+	// Time of the last send or its completion
+	lastUsed time.Time
+	// Overrides Config.IdleTimeout, unless it's zero
+	idleTimeout time.Duration
+}
+
+type send struct {
+	tag    uint64
+	buffer unsafe.Pointer
+	size   uint64
+	done   chan error
+}
+
+func (s *send) complete(err error) {
+	FreeNativeMemory(s.buffer)
+	s.done <- err
+}
+
+// Creates the manager of the endpoints of the worker, which must be the one
+// progressed by the loop.
+func NewManager(loop *UcpProgressLoop, worker *UcpWorker, config Config) (*Manager, error) {
+	if config.EndpointParams == nil {
+		return nil, errNoEndpoints
+	}
+
+	if config.InitialBackoff <= 0 {
+		config.InitialBackoff = 10 * time.Millisecond
+	}
+
+	if config.MaxBackoff < config.InitialBackoff {
+		config.MaxBackoff = 5 * time.Second
+		if config.MaxBackoff < config.InitialBackoff {
+			config.MaxBackoff = config.InitialBackoff
+		}
+	}
+
+	if config.MaxPending <= 0 {
+		config.MaxPending = 1024
+	}
+
+	if config.SweepInterval <= 0 {
+		config.SweepInterval = time.Second
+	}
+
+	m := &Manager{
+		loop:   loop,
+		worker: worker,
+		config: config,
+		peers:  make(map[string]*peer),
+	}
+
+	if config.IdleTimeout > 0 {
+		m.startSweeper()
+	}
+	return m, nil
+}
+
+// Executes f on the progress loop, unless the manager is closed.
+func (m *Manager) execute(f func() error) error {
+	var err error
+	if loopErr := m.loop.Execute(func() {
+		if m.closed {
+			err = ErrClosed
+			return
+		}
+		err = f()
+	}); loopErr != nil {
+		return loopErr
+	}
+	return err
+}
+
+// Send sends the data with the tag to the peer, connecting to it first if
+// needed. The data is copied, so it can be reused once Send returns. The
+// returned channel receives the completion error, or nil once the message is
+// sent.
+func (m *Manager) Send(peerId string, tag uint64, data []byte) <-chan error {
+	s := &send{
+		tag:    tag,
+		buffer: CBytes(data),
+		size:   uint64(len(data)),
+		done:   make(chan error, 1),
+	}
+
+	if err := m.execute(func() error {
+		p := m.getPeer(peerId)
+		p.lastUsed = time.Now()
+		if p.state == StateConnected {
+			m.post(p, s)
+			return nil
+		}
+
+		if len(p.pending) >= m.config.MaxPending {
+			return ErrQueueFull
+		}
+		p.pending = append(p.pending, s)
+		return nil
+	}); err != nil {
+		s.complete(err)
+	}
+
+	return s.done
+}
+
+// Returns the connection state of the peer. Peers, that were never sent to, are
+// reported as StateDisconnected.
+func (m *Manager) State(peerId string) State {
+	state := StateDisconnected
+	m.execute(func() error {
+		if p, found := m.peers[peerId]; found {
+			state = p.state
+		}
+		return nil
+	})
+	return state
+}
+
+// Closes the endpoint to the peer and stops reconnecting to it. The pending
+// sends complete with ErrRemoved. The next Send to the peer connects again.
+func (m *Manager) Remove(peerId string) error {
+	return m.execute(func() error {
+		if p, found := m.peers[peerId]; found {
+			m.remove(p, ErrRemoved)
+		}
+		return nil
+	})
+}
+
+// Sets the idle timeout of the peer, which overrides Config.IdleTimeout for
+// its endpoint, e.g. to keep the endpoints of the long-lived peers with the
+// negative timeout. The timeout is kept until the peer is removed, and zero
+// timeout restores the one of Config. Fails with ErrRemoved for the peer, that
+// is not sent to since it was removed.
+func (m *Manager) SetIdleTimeout(peerId string, timeout time.Duration) error {
+	return m.execute(func() error {
+		p, found := m.peers[peerId]
+		if !found {
+			return ErrRemoved
+		}
+
+		p.idleTimeout = timeout
+		if (timeout > 0) && (m.sweeperStop == nil) {
+			m.startSweeper()
+		}
+		return nil
+	})
+}
+
+// Closes all the endpoints and waits for their closure. The pending sends
+// complete with ErrClosed. The progress loop must still run.
+func (m *Manager) Close() error {
+	err := m.execute(func() error {
+		for _, p := range m.peers {
+			m.remove(p, ErrClosed)
+		}
+		m.closed = true
+		if m.sweeperStop != nil {
+			close(m.sweeperStop)
+		}
+		return nil
+	})
+
+	if err == ErrClosed {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	m.closing.Wait()
+	return nil
+}
+
+func (m *Manager) getPeer(peerId string) *peer {
+	p, found := m.peers[peerId]
+	if !found {
+		p = &peer{
+			id:       peerId,
+			backoff:  m.config.InitialBackoff,
+			lastUsed: time.Now(),
+		}
+		m.peers[peerId] = p
+		m.connect(p)
+	}
+	return p
+}
+
+func (m *Manager) setState(p *peer, state State) {
+	p.state = state
+	if m.config.OnStateChange != nil {
+		m.config.OnStateChange(p.id, state)
+	}
+}
+
+func (m *Manager) post(p *peer, s *send) {
+	p.active++
+	SubmitRequest(func(params *UcpRequestParams) (*UcpRequest, error) {
+		return p.ep.SendTagNonBlocking(s.tag, s.buffer, s.size, params)
+	}, func(status UcsStatus) {
+		p.active--
+		p.lastUsed = time.Now()
+		s.complete(status.Err())
+	})
+}
+
+// Creates the endpoint to the peer and flushes it, so the flush completes once
+// the connection is established. Notifications of every attempt are matched by
+// its number, so the ones of the abandoned attempts are ignored.
+func (m *Manager) connect(p *peer) {
+	p.attempt++
+	attempt := p.attempt
+	m.setState(p, StateConnecting)
+
+	params, err := m.config.EndpointParams(p.id)
+	if err == nil {
+		params.SetPeerErrorHandling().SetErrorHandler(func(ep *UcpEp, status UcsStatus) {
+			// Invoked from the worker progress, so the endpoint is closed by
+			// the next loop task
+			go m.execute(func() error {
+				m.fail(p, attempt)
+				return nil
+			})
+		})
+		p.ep, err = m.worker.NewEndpoint(params)
+	}
+
+	if err != nil {
+		p.ep = nil
+		m.fail(p, attempt)
+		return
+	}
+
+	SubmitRequest(p.ep.FlushNonBlocking, func(status UcsStatus) {
+		if status == UCS_OK {
+			m.connected(p, attempt)
+		} else {
+			m.fail(p, attempt)
+		}
+	})
+}
+
+func (m *Manager) connected(p *peer, attempt uint64) {
+	if (attempt != p.attempt) || (p.state != StateConnecting) {
+		return
+	}
+
+	p.backoff = m.config.InitialBackoff
+	m.setState(p, StateConnected)
+
+	pending := p.pending
+	p.pending = nil
+	for _, s := range pending {
+		m.post(p, s)
+	}
+}
+
+// Closes the failed endpoint and schedules the reconnect.
+func (m *Manager) fail(p *peer, attempt uint64) {
+	if (attempt != p.attempt) || (p.state == StateDisconnected) {
+		return
+	}
+
+	m.closeEndpoint(p)
+	m.setState(p, StateDisconnected)
+
+	p.timer = time.AfterFunc(p.backoff, func() {
+		m.execute(func() error {
+			if (attempt == p.attempt) && (m.peers[p.id] == p) {
+				m.connect(p)
+			}
+			return nil
+		})
+	})
+
+	if p.backoff *= 2; p.backoff > m.config.MaxBackoff {
+		p.backoff = m.config.MaxBackoff
+	}
+}
+
+func (m *Manager) remove(p *peer, err error) {
+	// Abandons the current attempt
+	p.attempt++
+	if p.timer != nil {
+		p.timer.Stop()
+	}
+
+	m.closeEndpoint(p)
+	delete(m.peers, p.id)
+
+	for _, s := range p.pending {
+		s.complete(err)
+	}
+	p.pending = nil
+}
+
+// Force closure completes the outstanding sends with UCS_ERR_CANCELED.
+func (m *Manager) closeEndpoint(p *peer) {
+	if p.ep == nil {
+		return
+	}
+
+	ep := p.ep
+	p.ep = nil
+	m.closing.Add(1)
+	SubmitRequest(ep.CloseNonBlockingForce, func(status UcsStatus) {
+		m.closing.Done()
+	})
+}
+
+// Sweeps the idle peers on the progress loop every SweepInterval until the
+// manager is closed.
+func (m *Manager) startSweeper() {
+	stop := make(chan struct{})
+	m.sweeperStop = stop
+	go func() {
+		ticker := time.NewTicker(m.config.SweepInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				if m.execute(m.sweep) != nil {
+					return
+				}
+			}
+		}
+	}()
+}
+
+func (m *Manager) sweep() error {
+	now := time.Now()
+	for _, p := range m.peers {
+		timeout := p.idleTimeout
+		if timeout == 0 {
+			timeout = m.config.IdleTimeout
+		}
+
+		if (timeout <= 0) || (p.active != 0) || (len(p.pending) != 0) || (now.Sub(p.lastUsed) < timeout) {
+			continue
+		}
+
+		if (m.config.OnIdle != nil) && !m.config.OnIdle(p.id) {
+			p.lastUsed = now
+			continue
+		}
+		m.closeIdle(p)
+	}
+	return nil
+}
+
+// Removes the idle peer, and closes its endpoint once the flush completes, so
+// the peer has received the sends before the endpoint is closed.
+func (m *Manager) closeIdle(p *peer) {
+	ep := p.ep
+	p.ep = nil
+	m.remove(p, ErrRemoved)
+	if ep == nil {
+		return
+	}
+
+	m.closing.Add(1)
+	SubmitRequest(ep.FlushNonBlocking, func(status UcsStatus) {
+		SubmitRequest(ep.CloseNonBlockingForce, func(status UcsStatus) {
+			m.closing.Done()
+		})
+	})
+}
@@ -0,0 +1,247 @@
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package ucxconn
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"time"
+	. "ucx"
+)
+
+var ErrPoolClosed = errors.New("ucxconn: pool is closed")
+
+type PoolConfig struct {
+	// Time the endpoint, that is not used by anyone, stays in the pool, before
+	// it's closed. 90s by default, the negative timeout keeps the idle
+	// endpoints until the pool is closed.
+	IdleTimeout time.Duration
+
+	// Invoked for every new endpoint, e.g. to set its name. Peer error
+	// handling and the error handler are set by the pool.
+	Configure func(params *UcpEpParams)
+}
+
+// Pool shares the endpoints of the worker, which is progressed by the loop, to
+// the same peer address, like the connection pool of http.Transport. The
+// endpoint is taken from the pool by Get(), and is returned by Put() once it's
+// not used anymore, so the endpoint is created only for the first user of the
+// peer, and is closed once it stays unused for the idle timeout. Failed
+// endpoints are dropped from the pool, so the next Get() of the peer creates
+// the new one, and are closed once their last user returns them. The routines
+// of the pool are safe to call concurrently, but must not be called from the
+// worker callbacks. The endpoints are used like the other endpoints of the
+// worker, i.e. on the progress loop, unless the thread mode of the worker is
+// UCS_THREAD_MODE_MULTI.
+type Pool struct {
+	loop    *UcpProgressLoop
+	worker  *UcpWorker
+	config  PoolConfig
+	eps     map[string]*PooledEp
+	closed  bool
+	closing sync.WaitGroup
+}
+
+// Endpoint of the pool, that is valid until it's returned by Pool.Put().
+type PooledEp struct {
+	key    string
+	ep     *UcpEp
+	refs   int
+	failed bool
+	// Number of the times, that the endpoint became idle, so the idle timers
+	// of the previous times are ignored
+	idle  uint64
+	timer *time.Timer
+}
+
+func (e *PooledEp) Ep() *UcpEp {
+	return e.ep
+}
+
+// Creates the pool of the endpoints of the worker, which must be the one
+// progressed by the loop.
+func NewPool(loop *UcpProgressLoop, worker *UcpWorker, config PoolConfig) *Pool {
+	if config.IdleTimeout == 0 {
+		config.IdleTimeout = 90 * time.Second
+	}
+
+	return &Pool{
+		loop:   loop,
+		worker: worker,
+		config: config,
+		eps:    make(map[string]*PooledEp),
+	}
+}
+
+// Returns the endpoint to the listener of the socket address.
+func (p *Pool) Get(addr net.Addr) (*PooledEp, error) {
+	return p.get(addr.Network()+"/"+addr.String(), func() (*UcpEpParams, error) {
+		return (&UcpEpParams{}).SetSockAddr(addr)
+	})
+}
+
+// Returns the endpoint to the worker of the address, see
+// UcpAddress.MarshalBinary().
+func (p *Pool) GetWorker(address []byte) (*PooledEp, error) {
+	return p.get("worker/"+string(address), func() (*UcpEpParams, error) {
+		return (&UcpEpParams{}).SetUcpAddressBytes(address), nil
+	})
+}
+
+func (p *Pool) get(key string, newParams func() (*UcpEpParams, error)) (*PooledEp, error) {
+	var result *PooledEp
+	var err error
+	if loopErr := p.loop.Execute(func() {
+		if p.closed {
+			err = ErrPoolClosed
+			return
+		}
+
+		e, found := p.eps[key]
+		if !found {
+			if e, err = p.connect(key, newParams); err != nil {
+				return
+			}
+			p.eps[key] = e
+		}
+
+		if e.timer != nil {
+			e.timer.Stop()
+			e.timer = nil
+		}
+		e.refs++
+		result = e
+	}); loopErr != nil {
+		return nil, loopErr
+	}
+	return result, err
+}
+
+func (p *Pool) connect(key string, newParams func() (*UcpEpParams, error)) (*PooledEp, error) {
+	params, err := newParams()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.config.Configure != nil {
+		p.config.Configure(params)
+	}
+
+	e := &PooledEp{key: key}
+	params.SetPeerErrorHandling().SetErrorHandler(func(ep *UcpEp, status UcsStatus) {
+		// Invoked from the worker progress, so the endpoint is dropped by the
+		// next loop task
+		go p.loop.Execute(func() {
+			p.fail(e)
+		})
+	})
+
+	if e.ep, err = p.worker.NewEndpoint(params); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Returns the endpoint, that was taken by Get(), to the pool. The endpoint
+// must not be used after this call.
+func (p *Pool) Put(e *PooledEp) {
+	p.loop.Execute(func() {
+		if e.refs--; e.refs > 0 {
+			return
+		}
+
+		if e.failed || p.closed {
+			p.closeEndpoint(e)
+			return
+		}
+
+		if p.config.IdleTimeout < 0 {
+			return
+		}
+
+		e.idle++
+		idle := e.idle
+		e.timer = time.AfterFunc(p.config.IdleTimeout, func() {
+			p.loop.Execute(func() {
+				if (e.idle == idle) && (e.refs == 0) && !e.failed {
+					p.drop(e)
+					p.closeEndpoint(e)
+				}
+			})
+		})
+	})
+}
+
+// Returns the number of the endpoints in the pool, including the idle ones.
+func (p *Pool) Len() int {
+	count := 0
+	p.loop.Execute(func() {
+		count = len(p.eps)
+	})
+	return count
+}
+
+// Closes the idle endpoints and waits for their closure. The endpoints in use
+// are closed once they are returned by Put(), and Get() fails with
+// ErrPoolClosed afterwards. The progress loop must still run.
+func (p *Pool) Close() error {
+	if err := p.loop.Execute(func() {
+		if p.closed {
+			return
+		}
+
+		p.closed = true
+		for _, e := range p.eps {
+			p.drop(e)
+			if e.refs == 0 {
+				p.closeEndpoint(e)
+			}
+		}
+	}); err != nil {
+		return err
+	}
+
+	p.closing.Wait()
+	return nil
+}
+
+func (p *Pool) fail(e *PooledEp) {
+	if e.failed {
+		return
+	}
+
+	e.failed = true
+	p.drop(e)
+	if e.refs == 0 {
+		p.closeEndpoint(e)
+	}
+}
+
+func (p *Pool) drop(e *PooledEp) {
+	if e.timer != nil {
+		e.timer.Stop()
+		e.timer = nil
+	}
+
+	if p.eps[e.key] == e {
+		delete(p.eps, e.key)
+	}
+}
+
+// Force closure completes the outstanding operations with UCS_ERR_CANCELED.
+func (p *Pool) closeEndpoint(e *PooledEp) {
+	if e.ep == nil {
+		return
+	}
+
+	ep := e.ep
+	e.ep = nil
+	p.closing.Add(1)
+	SubmitRequest(ep.CloseNonBlockingForce, func(status UcsStatus) {
+		p.closing.Done()
+	})
+}
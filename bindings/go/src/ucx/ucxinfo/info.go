@@ -0,0 +1,310 @@
+//go:build linux
+// +build linux
+
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+// Package ucxinfo enumerates the transports and devices, that are available
+// to UCX, along with their capabilities, same as "ucx_info -d" prints, and
+// the memory domains with the memory types they support.
+package ucxinfo
+
+// #include <stdlib.h>
+// #include <uct/api/uct.h>
+// #include <ucs/async/async_fwd.h>
+//
+// static ucs_status_t ucxgo_iface_open(uct_md_h md, uct_worker_h worker,
+//                                      uct_tl_resource_desc_t *resource,
+//                                      uct_iface_h *iface_p) {
+//     uct_iface_params_t params = {0};
+//     uct_iface_config_t *config;
+//     ucs_status_t status;
+//
+//     params.field_mask           = UCT_IFACE_PARAM_FIELD_OPEN_MODE |
+//                                   UCT_IFACE_PARAM_FIELD_DEVICE    |
+//                                   UCT_IFACE_PARAM_FIELD_RX_HEADROOM;
+//     params.open_mode            = UCT_IFACE_OPEN_MODE_DEVICE;
+//     params.mode.device.tl_name  = resource->tl_name;
+//     params.mode.device.dev_name = resource->dev_name;
+//     params.rx_headroom          = 0;
+//
+//     status = uct_md_iface_config_read(md, resource->tl_name, NULL, NULL, &config);
+//     if (status != UCS_OK) {
+//         return status;
+//     }
+//
+//     status = uct_iface_open(md, worker, &params, config, iface_p);
+//     uct_config_release(config);
+//     return status;
+// }
+//
+// static ucs_status_t ucxgo_md_open(uct_component_h component, const char *md_name,
+//                                   uct_md_h *md_p) {
+//     uct_md_config_t *config;
+//     ucs_status_t status;
+//
+//     status = uct_md_config_read(component, NULL, NULL, &config);
+//     if (status != UCS_OK) {
+//         return status;
+//     }
+//
+//     status = uct_md_open(component, md_name, config, md_p);
+//     uct_config_release(config);
+//     return status;
+// }
+//
+// static const char* ucxgo_device_type_name(uct_device_type_t type) {
+//     return uct_device_type_names[type];
+// }
+import "C"
+import (
+	"time"
+	. "ucx"
+	"unsafe"
+)
+
+type DeviceType int
+
+const (
+	UCT_DEVICE_TYPE_NET  DeviceType = C.UCT_DEVICE_TYPE_NET  /**< Network devices */
+	UCT_DEVICE_TYPE_SHM  DeviceType = C.UCT_DEVICE_TYPE_SHM  /**< Shared memory devices */
+	UCT_DEVICE_TYPE_ACC  DeviceType = C.UCT_DEVICE_TYPE_ACC  /**< Acceleration devices */
+	UCT_DEVICE_TYPE_SELF DeviceType = C.UCT_DEVICE_TYPE_SELF /**< Loop-back device */
+)
+
+func (t DeviceType) String() string {
+	if (t < UCT_DEVICE_TYPE_NET) || (t >= C.UCT_DEVICE_TYPE_LAST) {
+		return "unknown"
+	}
+	return C.GoString(C.ucxgo_device_type_name(C.uct_device_type_t(t)))
+}
+
+// Interface capabilities, see Device.Has().
+type IfaceFlag uint64
+
+const (
+	UCT_IFACE_FLAG_AM_SHORT               IfaceFlag = C.UCT_IFACE_FLAG_AM_SHORT
+	UCT_IFACE_FLAG_AM_BCOPY               IfaceFlag = C.UCT_IFACE_FLAG_AM_BCOPY
+	UCT_IFACE_FLAG_AM_ZCOPY               IfaceFlag = C.UCT_IFACE_FLAG_AM_ZCOPY
+	UCT_IFACE_FLAG_PENDING                IfaceFlag = C.UCT_IFACE_FLAG_PENDING
+	UCT_IFACE_FLAG_PUT_SHORT              IfaceFlag = C.UCT_IFACE_FLAG_PUT_SHORT
+	UCT_IFACE_FLAG_PUT_BCOPY              IfaceFlag = C.UCT_IFACE_FLAG_PUT_BCOPY
+	UCT_IFACE_FLAG_PUT_ZCOPY              IfaceFlag = C.UCT_IFACE_FLAG_PUT_ZCOPY
+	UCT_IFACE_FLAG_GET_SHORT              IfaceFlag = C.UCT_IFACE_FLAG_GET_SHORT
+	UCT_IFACE_FLAG_GET_BCOPY              IfaceFlag = C.UCT_IFACE_FLAG_GET_BCOPY
+	UCT_IFACE_FLAG_GET_ZCOPY              IfaceFlag = C.UCT_IFACE_FLAG_GET_ZCOPY
+	UCT_IFACE_FLAG_ATOMIC_CPU             IfaceFlag = C.UCT_IFACE_FLAG_ATOMIC_CPU
+	UCT_IFACE_FLAG_ATOMIC_DEVICE          IfaceFlag = C.UCT_IFACE_FLAG_ATOMIC_DEVICE
+	UCT_IFACE_FLAG_ERRHANDLE_PEER_FAILURE IfaceFlag = C.UCT_IFACE_FLAG_ERRHANDLE_PEER_FAILURE
+	UCT_IFACE_FLAG_EP_CHECK               IfaceFlag = C.UCT_IFACE_FLAG_EP_CHECK
+	UCT_IFACE_FLAG_CONNECT_TO_IFACE       IfaceFlag = C.UCT_IFACE_FLAG_CONNECT_TO_IFACE
+	UCT_IFACE_FLAG_CONNECT_TO_EP          IfaceFlag = C.UCT_IFACE_FLAG_CONNECT_TO_EP
+	UCT_IFACE_FLAG_CONNECT_TO_SOCKADDR    IfaceFlag = C.UCT_IFACE_FLAG_CONNECT_TO_SOCKADDR
+	UCT_IFACE_FLAG_EP_KEEPALIVE           IfaceFlag = C.UCT_IFACE_FLAG_EP_KEEPALIVE
+	UCT_IFACE_FLAG_TAG_EAGER_SHORT        IfaceFlag = C.UCT_IFACE_FLAG_TAG_EAGER_SHORT
+	UCT_IFACE_FLAG_TAG_EAGER_BCOPY        IfaceFlag = C.UCT_IFACE_FLAG_TAG_EAGER_BCOPY
+	UCT_IFACE_FLAG_TAG_EAGER_ZCOPY        IfaceFlag = C.UCT_IFACE_FLAG_TAG_EAGER_ZCOPY
+	UCT_IFACE_FLAG_TAG_RNDV_ZCOPY         IfaceFlag = C.UCT_IFACE_FLAG_TAG_RNDV_ZCOPY
+)
+
+// Message size limits of the operation, e.g. active message or put.
+type Limits struct {
+	MaxShort uint64
+	MaxBcopy uint64
+	MinZcopy uint64
+	MaxZcopy uint64
+}
+
+// Transport on the device, e.g. "rc_mlx5" on "mlx5_0:1".
+type Device struct {
+	Component    string
+	MemoryDomain string
+	Transport    string
+	Device       string
+	Type         DeviceType
+
+	// Bandwidth in bytes per second: the dedicated part, and the part, that
+	// is shared between the processes on the node.
+	BandwidthDedicated float64
+	BandwidthShared    float64
+
+	// Latency with a single endpoint, and its growth with every active one.
+	Latency            time.Duration
+	LatencyPerEndpoint time.Duration
+	Overhead           time.Duration
+
+	Priority     uint8
+	MaxEndpoints uint64
+	Flags        IfaceFlag
+	Am           Limits
+	Put          Limits
+	Get          Limits
+
+	// Set if the interface could not be opened, so the capabilities are
+	// unknown.
+	Err error
+}
+
+// Reports whether the device supports all the capabilities of the flags.
+func (d *Device) Has(flags IfaceFlag) bool {
+	return (d.Flags & flags) == flags
+}
+
+func seconds(value C.double) time.Duration {
+	return time.Duration(float64(value) * float64(time.Second))
+}
+
+// Devices returns all the transports and devices of all memory domains.
+func Devices() ([]Device, error) {
+	var async *C.ucs_async_context_t
+	if status := C.ucs_async_context_create(C.UCS_ASYNC_MODE_THREAD_SPINLOCK, &async); status != C.UCS_OK {
+		return nil, NewUcxError(UcsStatus(status))
+	}
+	defer C.ucs_async_context_destroy(async)
+
+	var worker C.uct_worker_h
+	if status := C.uct_worker_create(async, C.UCS_THREAD_MODE_SINGLE, &worker); status != C.UCS_OK {
+		return nil, NewUcxError(UcsStatus(status))
+	}
+	defer C.uct_worker_destroy(worker)
+
+	var result []Device
+	err := walkMemoryDomains(func(componentName, mdName string, md C.uct_md_h) bool {
+		result = append(result, mdDevices(md, worker, componentName, mdName)...)
+		return false
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Opens the memory domains of all the components, and passes them to visit,
+// which returns whether it keeps the memory domain open. Otherwise the memory
+// domain is closed once visit returns.
+func walkMemoryDomains(visit func(componentName, mdName string, md C.uct_md_h) bool) error {
+	var components *C.uct_component_h
+	var numComponents C.uint
+
+	if status := C.uct_query_components(&components, &numComponents); status != C.UCS_OK {
+		return NewUcxError(UcsStatus(status))
+	}
+	defer C.uct_release_component_list(components)
+
+	n := int(numComponents)
+	for _, component := range (*[1 << 16]C.uct_component_h)(unsafe.Pointer(components))[:n:n] {
+		if err := walkComponent(component, visit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func walkComponent(component C.uct_component_h,
+	visit func(componentName, mdName string, md C.uct_md_h) bool) error {
+	var componentAttr C.uct_component_attr_t
+
+	componentAttr.field_mask = C.UCT_COMPONENT_ATTR_FIELD_NAME | C.UCT_COMPONENT_ATTR_FIELD_MD_RESOURCE_COUNT
+	if status := C.uct_component_query(component, &componentAttr); status != C.UCS_OK {
+		return NewUcxError(UcsStatus(status))
+	}
+
+	n := int(componentAttr.md_resource_count)
+	if n == 0 {
+		return nil
+	}
+
+	mdResources := AllocateNativeMemory(uint64(n) * C.sizeof_uct_md_resource_desc_t)
+	defer FreeNativeMemory(mdResources)
+
+	componentAttr.field_mask = C.UCT_COMPONENT_ATTR_FIELD_MD_RESOURCES
+	componentAttr.md_resources = (*C.uct_md_resource_desc_t)(mdResources)
+	if status := C.uct_component_query(component, &componentAttr); status != C.UCS_OK {
+		return NewUcxError(UcsStatus(status))
+	}
+
+	componentName := C.GoString(&componentAttr.name[0])
+	for _, mdResource := range (*[1 << 16]C.uct_md_resource_desc_t)(mdResources)[:n:n] {
+		var md C.uct_md_h
+		if status := C.ucxgo_md_open(component, &mdResource.md_name[0], &md); status != C.UCS_OK {
+			// Memory domain may be unusable on this host, e.g. no devices
+			continue
+		}
+
+		if !visit(componentName, C.GoString(&mdResource.md_name[0]), md) {
+			C.uct_md_close(md)
+		}
+	}
+	return nil
+}
+
+func mdDevices(md C.uct_md_h, worker C.uct_worker_h, componentName, mdName string) []Device {
+	var resources *C.uct_tl_resource_desc_t
+	var numResources C.uint
+
+	if status := C.uct_md_query_tl_resources(md, &resources, &numResources); status != C.UCS_OK {
+		return nil
+	}
+	defer C.uct_release_tl_resource_list(resources)
+
+	n := int(numResources)
+	result := make([]Device, 0, n)
+	for i := 0; i < n; i++ {
+		resource := (*C.uct_tl_resource_desc_t)(unsafe.Pointer(uintptr(unsafe.Pointer(resources)) +
+			uintptr(i)*C.sizeof_uct_tl_resource_desc_t))
+		device := Device{
+			Component:    componentName,
+			MemoryDomain: mdName,
+			Transport:    C.GoString(&resource.tl_name[0]),
+			Device:       C.GoString(&resource.dev_name[0]),
+			Type:         DeviceType(resource.dev_type),
+		}
+		device.Err = queryIface(md, worker, resource, &device)
+		result = append(result, device)
+	}
+	return result
+}
+
+func queryIface(md C.uct_md_h, worker C.uct_worker_h, resource *C.uct_tl_resource_desc_t, device *Device) error {
+	var iface C.uct_iface_h
+	var ifaceAttr C.uct_iface_attr_t
+
+	if status := C.ucxgo_iface_open(md, worker, resource, &iface); status != C.UCS_OK {
+		return NewUcxError(UcsStatus(status))
+	}
+	defer C.uct_iface_close(iface)
+
+	if status := C.uct_iface_query(iface, &ifaceAttr); status != C.UCS_OK {
+		return NewUcxError(UcsStatus(status))
+	}
+
+	device.BandwidthDedicated = float64(ifaceAttr.bandwidth.dedicated)
+	device.BandwidthShared = float64(ifaceAttr.bandwidth.shared)
+	device.Latency = seconds(ifaceAttr.latency.c)
+	device.LatencyPerEndpoint = seconds(ifaceAttr.latency.m)
+	device.Overhead = seconds(ifaceAttr.overhead)
+	device.Priority = uint8(ifaceAttr.priority)
+	device.MaxEndpoints = uint64(ifaceAttr.max_num_eps)
+	device.Flags = IfaceFlag(ifaceAttr.cap.flags)
+	device.Am = Limits{
+		MaxShort: uint64(ifaceAttr.cap.am.max_short),
+		MaxBcopy: uint64(ifaceAttr.cap.am.max_bcopy),
+		MinZcopy: uint64(ifaceAttr.cap.am.min_zcopy),
+		MaxZcopy: uint64(ifaceAttr.cap.am.max_zcopy),
+	}
+	device.Put = Limits{
+		MaxShort: uint64(ifaceAttr.cap.put.max_short),
+		MaxBcopy: uint64(ifaceAttr.cap.put.max_bcopy),
+		MinZcopy: uint64(ifaceAttr.cap.put.min_zcopy),
+		MaxZcopy: uint64(ifaceAttr.cap.put.max_zcopy),
+	}
+	device.Get = Limits{
+		MaxShort: uint64(ifaceAttr.cap.get.max_short),
+		MaxBcopy: uint64(ifaceAttr.cap.get.max_bcopy),
+		MinZcopy: uint64(ifaceAttr.cap.get.min_zcopy),
+		MaxZcopy: uint64(ifaceAttr.cap.get.max_zcopy),
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+//go:build linux
+// +build linux
+
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package ucxinfo
+
+// #include <uct/api/uct.h>
+import "C"
+import (
+	"sync"
+	"time"
+	. "ucx"
+	"unsafe"
+)
+
+// Memory domain of the component, e.g. "mlx5_0" of "ib" or "cuda_cpy" of
+// "cuda_cpy", and the masks of the memory types it supports, which are checked
+// by IsMemTypeSupported().
+type MemoryDomain struct {
+	Component string
+	Name      string
+	// Memory types, that the memory domain can register, detect, allocate
+	// and access
+	RegMemTypes    uint64
+	DetectMemTypes uint64
+	AllocMemTypes  uint64
+	AccessMemTypes uint64
+	// Maximal size of the allocation and of the registration
+	MaxAlloc uint64
+	MaxReg   uint64
+	// The memory domain exports the memory handles to the other processes
+	// and maps the exported ones, see UcpMemory.Export()
+	ExportedMemh bool
+	// Estimation of the registration time, that is linear in the buffer
+	// size: the overhead of each registration and the seconds per byte, see
+	// RegistrationCost()
+	RegOverhead time.Duration
+	RegPerByte  float64
+	// Size of the packed remote key of the registered memory
+	RkeyPackedSize uint64
+}
+
+// Reports whether the memory domain can register the memory of the type, e.g.
+// the arena, that is mapped by UcpContext.MemMap() with
+// UcpMmapParams.SetMemoryType().
+func (d *MemoryDomain) CanRegister(memType UcsMemoryType) bool {
+	return IsMemTypeSupported(memType, d.RegMemTypes)
+}
+
+// Returns the estimated time of the registration of the buffer of the size.
+func (d *MemoryDomain) RegistrationCost(size uint64) time.Duration {
+	return d.RegOverhead + seconds(C.double(d.RegPerByte*float64(size)))
+}
+
+// Reports whether the registration of the buffer of the memory type and the
+// size is cheaper than its copy to the registered bounce buffer, by the copy
+// bandwidth in bytes per second, e.g. the one measured by the framework. The
+// buffer, that is used several times, e.g. by UcpMemoryCache, is worth the
+// registration at the copy bandwidth divided by the number of the uses, and
+// the non-positive bandwidth means the buffer can't be copied at all. The
+// buffer of the type, that the memory domain can't register, or larger than
+// MaxReg, is always copied.
+func (d *MemoryDomain) PreferRegistration(memType UcsMemoryType, size uint64, copyBandwidth float64) bool {
+	if !d.CanRegister(memType) || (size > d.MaxReg) {
+		return false
+	}
+
+	if copyBandwidth <= 0 {
+		return true
+	}
+	return d.RegistrationCost(size) < seconds(C.double(float64(size)/copyBandwidth))
+}
+
+func queryMemoryDomain(componentName, mdName string, md C.uct_md_h) (MemoryDomain, error) {
+	var mdAttr C.uct_md_attr_t
+	if status := C.uct_md_query(md, &mdAttr); status != C.UCS_OK {
+		return MemoryDomain{}, NewUcxError(UcsStatus(status))
+	}
+
+	return MemoryDomain{
+		Component:      componentName,
+		Name:           mdName,
+		RegMemTypes:    uint64(mdAttr.cap.reg_mem_types),
+		DetectMemTypes: uint64(mdAttr.cap.detect_mem_types),
+		AllocMemTypes:  uint64(mdAttr.cap.alloc_mem_types),
+		AccessMemTypes: uint64(mdAttr.cap.access_mem_types),
+		MaxAlloc:       uint64(mdAttr.cap.max_alloc),
+		MaxReg:         uint64(mdAttr.cap.max_reg),
+		ExportedMemh:   (mdAttr.cap.flags & C.UCT_MD_FLAG_EXPORTED_MKEY) != 0,
+		RegOverhead:    seconds(mdAttr.reg_cost.c),
+		RegPerByte:     float64(mdAttr.reg_cost.m),
+		RkeyPackedSize: uint64(mdAttr.rkey_packed_size),
+	}, nil
+}
+
+// MemoryDomains returns the memory domains of all the components, that are
+// usable on the host. The memory types, that the context can register, are
+// the union of the memory types of its memory domains, see
+// UcpContext.MemoryTypesMask().
+func MemoryDomains() ([]MemoryDomain, error) {
+	var result []MemoryDomain
+	var queryErr error
+	err := walkMemoryDomains(func(componentName, mdName string, md C.uct_md_h) bool {
+		domain, err := queryMemoryDomain(componentName, mdName, md)
+		if err != nil {
+			queryErr = err
+			return false
+		}
+		result = append(result, domain)
+		return false
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if queryErr != nil {
+		return nil, queryErr
+	}
+	return result, nil
+}
+
+// Detects the memory type of the buffers, e.g. of the arenas, that are
+// allocated by the frameworks outside of UCX, by the memory domains, that are
+// able to detect it. The memory domains stay open until Close(), so the
+// detector is meant to be reused for many buffers. It's safe for concurrent
+// use.
+type MemoryTypeDetector struct {
+	mu  sync.RWMutex
+	mds []C.uct_md_h
+}
+
+// Opens the memory domains, that detect any memory type, e.g. the CUDA and
+// ROCm ones. The detector without them reports every buffer as the host
+// memory.
+func NewMemoryTypeDetector() (*MemoryTypeDetector, error) {
+	detector := &MemoryTypeDetector{}
+	err := walkMemoryDomains(func(componentName, mdName string, md C.uct_md_h) bool {
+		domain, err := queryMemoryDomain(componentName, mdName, md)
+		if (err != nil) || (domain.DetectMemTypes == 0) {
+			return false
+		}
+
+		detector.mds = append(detector.mds, md)
+		return true
+	})
+	if err != nil {
+		detector.Close()
+		return nil, err
+	}
+	return detector, nil
+}
+
+// Returns the memory type of the buffer, which is UCS_MEMORY_TYPE_HOST unless a
+// memory domain detects it, e.g. as UCS_MEMORY_TYPE_CUDA. The type is meant for
+// UcpMmapParams.SetMemoryType() and UcpRequestParams.SetMemType(), so UCX
+// doesn't detect it again.
+func (d *MemoryTypeDetector) Detect(address unsafe.Pointer, length uint64) UcsMemoryType {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	for _, md := range d.mds {
+		var memType C.ucs_memory_type_t
+		if C.uct_md_detect_memory_type(md, address, C.size_t(length), &memType) == C.UCS_OK {
+			return UcsMemoryType(memType)
+		}
+	}
+	return UCS_MEMORY_TYPE_HOST
+}
+
+// Closes the memory domains of the detector, which must not be used after
+// this call.
+func (d *MemoryTypeDetector) Close() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, md := range d.mds {
+		C.uct_md_close(md)
+	}
+	d.mds = nil
+}
@@ -0,0 +1,139 @@
+//go:build !linux || !cgo
+// +build !linux !cgo
+
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package ucxinfo
+
+import (
+	"fmt"
+	"time"
+	. "ucx"
+	"unsafe"
+)
+
+// UCX runs only on Linux, so on the other platforms, or with CGO_ENABLED=0,
+// the package is built of these stubs with the same API, same as the ucx
+// package. The stubs fail with the error, which matches ucx.ErrUnsupported.
+var errUnsupportedPlatform = fmt.Errorf("%w: unsupported platform, UCX requires Linux and cgo", ErrUnsupported)
+
+type DeviceType int
+
+const (
+	UCT_DEVICE_TYPE_NET  DeviceType = 0
+	UCT_DEVICE_TYPE_SHM  DeviceType = 1
+	UCT_DEVICE_TYPE_ACC  DeviceType = 2
+	UCT_DEVICE_TYPE_SELF DeviceType = 3
+)
+
+func (t DeviceType) String() string {
+	return ""
+}
+
+type IfaceFlag uint64
+
+const (
+	UCT_IFACE_FLAG_AM_SHORT               IfaceFlag = 1
+	UCT_IFACE_FLAG_AM_BCOPY               IfaceFlag = 2
+	UCT_IFACE_FLAG_AM_ZCOPY               IfaceFlag = 4
+	UCT_IFACE_FLAG_PENDING                IfaceFlag = 0x8
+	UCT_IFACE_FLAG_PUT_SHORT              IfaceFlag = 0x10
+	UCT_IFACE_FLAG_PUT_BCOPY              IfaceFlag = 0x20
+	UCT_IFACE_FLAG_PUT_ZCOPY              IfaceFlag = 0x40
+	UCT_IFACE_FLAG_GET_SHORT              IfaceFlag = 0x100
+	UCT_IFACE_FLAG_GET_BCOPY              IfaceFlag = 0x200
+	UCT_IFACE_FLAG_GET_ZCOPY              IfaceFlag = 0x400
+	UCT_IFACE_FLAG_ATOMIC_CPU             IfaceFlag = 0x40000000
+	UCT_IFACE_FLAG_ATOMIC_DEVICE          IfaceFlag = 0x80000000
+	UCT_IFACE_FLAG_ERRHANDLE_PEER_FAILURE IfaceFlag = 0x4000000000
+	UCT_IFACE_FLAG_EP_CHECK               IfaceFlag = 0x8000000000
+	UCT_IFACE_FLAG_CONNECT_TO_IFACE       IfaceFlag = 0x10000000000
+	UCT_IFACE_FLAG_CONNECT_TO_EP          IfaceFlag = 0x20000000000
+	UCT_IFACE_FLAG_CONNECT_TO_SOCKADDR    IfaceFlag = 0x40000000000
+	UCT_IFACE_FLAG_EP_KEEPALIVE           IfaceFlag = 0x400000000000
+	UCT_IFACE_FLAG_TAG_EAGER_SHORT        IfaceFlag = 0x4000000000000
+	UCT_IFACE_FLAG_TAG_EAGER_BCOPY        IfaceFlag = 0x8000000000000
+	UCT_IFACE_FLAG_TAG_EAGER_ZCOPY        IfaceFlag = 0x10000000000000
+	UCT_IFACE_FLAG_TAG_RNDV_ZCOPY         IfaceFlag = 0x20000000000000
+)
+
+type Limits struct {
+	MaxShort uint64
+	MaxBcopy uint64
+	MinZcopy uint64
+	MaxZcopy uint64
+}
+
+type Device struct {
+	Component          string
+	MemoryDomain       string
+	Transport          string
+	Device             string
+	Type               DeviceType
+	BandwidthDedicated float64
+	BandwidthShared    float64
+	Latency            time.Duration
+	LatencyPerEndpoint time.Duration
+	Overhead           time.Duration
+	Priority           uint8
+	MaxEndpoints       uint64
+	Flags              IfaceFlag
+	Am                 Limits
+	Put                Limits
+	Get                Limits
+	Err                error
+}
+
+func (d *Device) Has(flags IfaceFlag) bool {
+	return false
+}
+
+func Devices() ([]Device, error) {
+	return nil, errUnsupportedPlatform
+}
+
+type MemoryDomain struct {
+	Component      string
+	Name           string
+	RegMemTypes    uint64
+	DetectMemTypes uint64
+	AllocMemTypes  uint64
+	AccessMemTypes uint64
+	MaxAlloc       uint64
+	MaxReg         uint64
+	ExportedMemh   bool
+	RegOverhead    time.Duration
+	RegPerByte     float64
+	RkeyPackedSize uint64
+}
+
+func (d *MemoryDomain) CanRegister(memType UcsMemoryType) bool {
+	return false
+}
+
+func (d *MemoryDomain) RegistrationCost(size uint64) time.Duration {
+	return 0
+}
+
+func (d *MemoryDomain) PreferRegistration(memType UcsMemoryType, size uint64, copyBandwidth float64) bool {
+	return false
+}
+
+func MemoryDomains() ([]MemoryDomain, error) {
+	return nil, errUnsupportedPlatform
+}
+
+type MemoryTypeDetector struct{}
+
+func NewMemoryTypeDetector() (*MemoryTypeDetector, error) {
+	return nil, errUnsupportedPlatform
+}
+
+func (d *MemoryTypeDetector) Detect(address unsafe.Pointer, length uint64) UcsMemoryType {
+	return 0
+}
+
+func (d *MemoryTypeDetector) Close() {}
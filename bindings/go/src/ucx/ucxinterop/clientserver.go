@@ -0,0 +1,315 @@
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package ucxinterop
+
+import (
+	"bytes"
+	"net"
+	"strings"
+	"time"
+	. "ucx"
+	"unsafe"
+)
+
+// Conventions of ucp_client_server: the port of the server, the tag of the
+// messages, which the server receives with the zero tag mask, and the Active
+// Message id of the messages without the header.
+const (
+	ClientServerPort         = 13337
+	ClientServerTag   uint64 = 0xCAFE
+	ClientServerAmId  uint   = 0
+	clientServerMsgSz uint64 = 16
+)
+
+// API of ucp_client_server messages, the -c option of the program.
+type CommType int
+
+const (
+	CommStream CommType = iota
+	CommTag
+	CommAm
+)
+
+func (c CommType) String() string {
+	switch c {
+	case CommStream:
+		return "stream"
+	case CommTag:
+		return "tag"
+	case CommAm:
+		return "am"
+	}
+	return "unknown"
+}
+
+// Parses the -c option of ucp_client_server.
+func ParseCommType(s string) (CommType, error) {
+	for _, c := range []CommType{CommStream, CommTag, CommAm} {
+		if strings.EqualFold(s, c.String()) {
+			return c, nil
+		}
+	}
+	return 0, ErrInvalidParam
+}
+
+// Features of the context, that the peer of the API needs.
+func (c CommType) Features(params *UcpParams) *UcpParams {
+	switch c {
+	case CommStream:
+		return params.EnableStream()
+	case CommTag:
+		return params.EnableTag()
+	}
+	return params.EnableAM()
+}
+
+type ClientServerConfig struct {
+	Comm CommType
+
+	// Number of the messages from the client to the server, the -i option of
+	// the program. 1 by default.
+	Iterations int
+
+	// Size of the messages, the -s option of the program. 16 by default.
+	MessageSize uint64
+
+	// Time of the whole exchange, DefaultTimeout by default.
+	Timeout time.Duration
+
+	// Invoked by the server with the listening address, e.g. to start the
+	// client.
+	OnListen func(addr *net.TCPAddr)
+}
+
+func (c ClientServerConfig) withDefaults() ClientServerConfig {
+	if c.Iterations <= 0 {
+		c.Iterations = 1
+	}
+
+	if c.MessageSize == 0 {
+		c.MessageSize = clientServerMsgSz
+	}
+
+	if c.Timeout <= 0 {
+		c.Timeout = DefaultTimeout
+	}
+	return c
+}
+
+// Returns the message of ucp_client_server: the letters from 'A' to 'Z'
+// repeated, and the terminating zero.
+func TestString(size uint64) []byte {
+	data := make([]byte, size)
+	for i := uint64(0); i+1 < size; i++ {
+		data[i] = 'A' + byte(i%26)
+	}
+	return data
+}
+
+// Peer of ucp_client_server, that sends and receives the messages of the API.
+type clientServerPeer struct {
+	worker   *UcpWorker
+	peer     *peerEp
+	config   ClientServerConfig
+	deadline time.Time
+
+	// Active Messages, that arrived to the handler, and the rendezvous
+	// receives of the messages in the order of arrival
+	am []*amMessage
+}
+
+type amMessage struct {
+	data    []byte
+	request *UcpRequest
+	buffer  unsafe.Pointer
+	length  uint64
+}
+
+func newClientServerPeer(worker *UcpWorker, config ClientServerConfig) (*clientServerPeer, error) {
+	config = config.withDefaults()
+	p := &clientServerPeer{
+		worker:   worker,
+		config:   config,
+		deadline: time.Now().Add(config.Timeout),
+	}
+
+	if config.Comm == CommAm {
+		if err := worker.SetAmRecvHandlerWithMode(ClientServerAmId, UCP_AM_FLAG_WHOLE_MSG,
+			UcpAmDataModeCopy, p.onAm); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+func (p *clientServerPeer) close() {
+	if p.config.Comm == CommAm {
+		p.worker.SetAmRecvHandler(ClientServerAmId, 0, nil)
+	}
+
+	for _, m := range p.am {
+		m.release()
+	}
+	p.am = nil
+
+	if p.peer != nil {
+		closeEp(p.worker, p.peer.ep, false, time.Now().Add(p.config.Timeout))
+	}
+}
+
+func (p *clientServerPeer) onAm(header unsafe.Pointer, headerSize uint64, data *UcpAmData,
+	replyEp *UcpEp) UcsStatus {
+	m := &amMessage{}
+	if headerSize != 0 {
+		// The program doesn't send the header, so the message fails the check
+		m.data = []byte{}
+	} else if data.IsDataValid() {
+		m.data = data.Bytes()
+	} else {
+		m.length = data.Length()
+		m.buffer = AllocateNativeMemory(m.length + 1)
+		m.request, _ = data.Receive(m.buffer, m.length, nil)
+	}
+	p.am = append(p.am, m)
+	return UCS_OK
+}
+
+func (m *amMessage) release() {
+	if m.request != nil {
+		m.request.Close()
+		m.request = nil
+	}
+
+	if m.buffer != nil {
+		FreeNativeMemory(m.buffer)
+		m.buffer = nil
+	}
+}
+
+func (p *clientServerPeer) send(data []byte) error {
+	ep := p.peer.ep
+	var request *UcpRequest
+	var err error
+	switch p.config.Comm {
+	case CommStream:
+		request, err = ep.SendStreamBytesNonBlocking(data, nil)
+	case CommTag:
+		request, err = ep.SendTagBytesNonBlocking(ClientServerTag, data, nil)
+	default:
+		request, err = ep.SendAmBytesNonBlocking(ClientServerAmId, nil, 0, data, 0, nil)
+	}
+	return wait(p.worker, request, err, p.deadline)
+}
+
+func (p *clientServerPeer) recv() ([]byte, error) {
+	size := p.config.MessageSize
+	switch p.config.Comm {
+	case CommStream, CommTag:
+		buffer := AllocateNativeMemory(size)
+		defer FreeNativeMemory(buffer)
+
+		var request *UcpRequest
+		var err error
+		if p.config.Comm == CommStream {
+			request, err = p.peer.ep.RecvStreamNonBlocking(buffer, size,
+				(&UcpRequestParams{}).SetStreamRecvFlags(UCP_STREAM_RECV_FLAG_WAITALL))
+		} else {
+			request, err = p.worker.RecvTagNonBlocking(buffer, size, ClientServerTag, 0, nil)
+		}
+
+		if err := wait(p.worker, request, err, p.deadline); err != nil {
+			return nil, err
+		}
+		return GoBytes(buffer, size), nil
+	}
+
+	if err := progressUntil(p.worker, p.deadline, func() bool { return len(p.am) != 0 }); err != nil {
+		return nil, err
+	}
+
+	m := p.am[0]
+	p.am = p.am[1:]
+	defer m.release()
+
+	// The rendezvous data, that is received immediately, has no request
+	if m.buffer != nil {
+		request := m.request
+		m.request = nil
+		if err := wait(p.worker, request, nil, p.deadline); err != nil {
+			return nil, err
+		}
+		return GoBytes(m.buffer, m.length), nil
+	}
+	return m.data, nil
+}
+
+func (p *clientServerPeer) check(data []byte) error {
+	if expected := TestString(p.config.MessageSize); !bytes.Equal(data, expected) {
+		return mismatch("%v message %q != %q", p.config.Comm, data, expected)
+	}
+	return nil
+}
+
+// The iterations of the client messages, and the FIN message in the reverse
+// direction, which is the only message, that the server sends.
+func (p *clientServerPeer) exchange(isServer bool) error {
+	message := TestString(p.config.MessageSize)
+	for i := 0; i <= p.config.Iterations; i++ {
+		fin := i == p.config.Iterations
+		if fin == isServer {
+			if err := p.send(message); err != nil {
+				return err
+			}
+			continue
+		}
+
+		data, err := p.recv()
+		if err != nil {
+			return err
+		}
+
+		if err := p.check(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Runs the client of ucp_client_server, which connects to the server on the
+// address, sends the messages, and receives the FIN message. The context of
+// the worker must have the features of the API, see CommType.Features().
+func RunClientServerClient(worker *UcpWorker, addr *net.TCPAddr, config ClientServerConfig) error {
+	p, err := newClientServerPeer(worker, config)
+	if err != nil {
+		return err
+	}
+	defer p.close()
+
+	if p.peer, err = connect(worker, addr); err != nil {
+		return err
+	}
+	return p.exchange(false)
+}
+
+// Runs the server of ucp_client_server for one client: it listens on the
+// address, receives the messages of the client, sends the FIN message, and
+// waits for the client to close the connection.
+func RunClientServerServer(worker *UcpWorker, addr *net.TCPAddr, config ClientServerConfig) error {
+	p, err := newClientServerPeer(worker, config)
+	if err != nil {
+		return err
+	}
+	defer p.close()
+
+	if p.peer, err = accept(worker, addr, p.config.OnListen, p.deadline); err != nil {
+		return err
+	}
+
+	if err := p.exchange(true); err != nil {
+		return err
+	}
+	return progressUntil(worker, p.deadline, p.peer.failed)
+}
@@ -0,0 +1,152 @@
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+// Package ucxinterop implements the peers of the example programs of the
+// other UCX bindings, so the Go bindings exchange the messages with them the
+// same way, as the programs do between themselves:
+//
+//   - ucp_client_server of the C examples, over the tag, stream and Active
+//     Message APIs, see RunClientServerClient() and RunClientServerServer().
+//
+//   - UcxReadBWBenchmarkSender and UcxReadBWBenchmarkReceiver of the Java
+//     bindings, which exchange the remote key of the memory, see
+//     RunReadBWSender() and RunReadBWReceiver().
+//
+// The peers verify the message formats, so the Go peer against the program
+// fails on the mismatch, and are run by the goucxinterop test binary. The
+// routines progress the worker until the exchange is completed, so they must
+// not be called concurrently with the other routines progressing it.
+package ucxinterop
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"time"
+	. "ucx"
+)
+
+var ErrMismatch = errors.New("ucxinterop: message doesn't match the peer program")
+
+// Time of the whole exchange by default.
+const DefaultTimeout = 30 * time.Second
+
+// Progresses the worker until the request is completed, and releases it.
+func wait(worker *UcpWorker, request *UcpRequest, err error, deadline time.Time) error {
+	if err != nil {
+		request.Close()
+		return err
+	}
+	defer request.Close()
+
+	for request.GetStatus() == UCS_INPROGRESS {
+		if time.Now().After(deadline) {
+			request.Cancel()
+			return ErrTimedOut
+		}
+		worker.Progress()
+	}
+	return request.GetStatus().Err()
+}
+
+// Progresses the worker until done returns true.
+func progressUntil(worker *UcpWorker, deadline time.Time, done func() bool) error {
+	for !done() {
+		if time.Now().After(deadline) {
+			return ErrTimedOut
+		}
+		worker.Progress()
+	}
+	return nil
+}
+
+// Closes the endpoint, and waits for the closure.
+func closeEp(worker *UcpWorker, ep *UcpEp, flush bool, deadline time.Time) error {
+	var request *UcpRequest
+	var err error
+	if flush {
+		request, err = ep.CloseNonBlockingFlush(nil)
+	} else {
+		request, err = ep.CloseNonBlockingForce(nil)
+	}
+	return wait(worker, request, err, deadline)
+}
+
+// Peer endpoint, that tracks the failure, e.g. once the peer closes it.
+type peerEp struct {
+	ep     *UcpEp
+	status UcsStatus
+}
+
+func (p *peerEp) failed() bool {
+	return p.status != UCS_OK
+}
+
+func (p *peerEp) params() *UcpEpParams {
+	return (&UcpEpParams{}).SetPeerErrorHandling().SetErrorHandler(func(ep *UcpEp, status UcsStatus) {
+		p.status = status
+	})
+}
+
+// Connects to the listener of the program.
+func connect(worker *UcpWorker, addr *net.TCPAddr) (*peerEp, error) {
+	p := &peerEp{status: UCS_OK}
+	params, err := p.params().SetSocketAddress(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.ep, err = worker.NewEndpoint(params); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Listens on the address and accepts the first connection. onListen is
+// invoked with the listening address, e.g. to start the program connecting
+// to it.
+func accept(worker *UcpWorker, addr *net.TCPAddr, onListen func(addr *net.TCPAddr),
+	deadline time.Time) (*peerEp, error) {
+	var connRequest *UcpConnectionRequest
+	listenerParams := (&UcpListenerParams{}).SetConnectionHandler(func(request *UcpConnectionRequest) {
+		if connRequest == nil {
+			connRequest = request
+		} else {
+			request.Reject()
+		}
+	})
+
+	if _, err := listenerParams.SetSocketAddress(addr); err != nil {
+		return nil, err
+	}
+
+	listener, err := worker.NewListener(listenerParams)
+	if err != nil {
+		return nil, err
+	}
+	defer listener.Close()
+
+	if onListen != nil {
+		listenAddr, err := listener.Addr()
+		if err != nil {
+			return nil, err
+		}
+		onListen(listenAddr)
+	}
+
+	if err := progressUntil(worker, deadline, func() bool { return connRequest != nil }); err != nil {
+		return nil, err
+	}
+
+	p := &peerEp{status: UCS_OK}
+	if p.ep, err = worker.NewEndpoint(p.params().SetConnRequest(connRequest)); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func mismatch(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrMismatch, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,245 @@
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package ucxinterop
+
+import (
+	"encoding/binary"
+	"math"
+	"net"
+	"time"
+	. "ucx"
+	"unsafe"
+)
+
+// Conventions of UcxReadBWBenchmarkSender and UcxReadBWBenchmarkReceiver of
+// the Java bindings: the port of the receiver, the tag of the memory
+// description, and the size of the receive buffer of the description.
+const (
+	ReadBWPort              = 54321
+	ReadBWTag        uint64 = 0
+	readBWBufferSize uint64 = 4096
+	readBWHeaderSize        = 8 + 8 + 4 + 4
+)
+
+// Memory of the sender, that the receiver reads by RMA.
+type RemoteBuffer struct {
+	Address uint64
+	Size    uint64
+	// Packed remote key of the memory, see UcpMemory.RkeyPack()
+	Rkey []byte
+	// java.nio.ByteBuffer.hashCode() of the memory, see ByteBufferHashCode()
+	HashCode int32
+}
+
+// Encodes the description of the memory by the layout of the Java sender:
+// the big-endian address, size and remote key size, the remote key, and the
+// hash code.
+func (b *RemoteBuffer) MarshalBinary() ([]byte, error) {
+	data := make([]byte, readBWHeaderSize+len(b.Rkey))
+	binary.BigEndian.PutUint64(data[0:], b.Address)
+	binary.BigEndian.PutUint64(data[8:], b.Size)
+	binary.BigEndian.PutUint32(data[16:], uint32(len(b.Rkey)))
+	copy(data[20:], b.Rkey)
+	binary.BigEndian.PutUint32(data[20+len(b.Rkey):], uint32(b.HashCode))
+	return data, nil
+}
+
+func (b *RemoteBuffer) UnmarshalBinary(data []byte) error {
+	if len(data) < readBWHeaderSize {
+		return mismatch("memory description of %v bytes", len(data))
+	}
+
+	rkeySize := uint64(binary.BigEndian.Uint32(data[16:]))
+	if rkeySize > uint64(len(data)-readBWHeaderSize) {
+		return mismatch("remote key of %v bytes in %v bytes", rkeySize, len(data))
+	}
+
+	b.Address = binary.BigEndian.Uint64(data[0:])
+	b.Size = binary.BigEndian.Uint64(data[8:])
+	b.Rkey = append([]byte(nil), data[20:20+rkeySize]...)
+	b.HashCode = int32(binary.BigEndian.Uint32(data[20+rkeySize:]))
+	return nil
+}
+
+// Returns java.nio.ByteBuffer.hashCode() of the data, which the Java programs
+// use to verify the read memory. The Java buffer views at most
+// math.MaxInt32 bytes of the memory.
+func ByteBufferHashCode(data []byte) int32 {
+	if len(data) > math.MaxInt32 {
+		data = data[:math.MaxInt32]
+	}
+
+	h := int32(1)
+	for i := len(data) - 1; i >= 0; i-- {
+		h = 31*h + int32(int8(data[i]))
+	}
+	return h
+}
+
+type ReadBWConfig struct {
+	// Number of the reads of the memory, the n option of the programs. 5 by
+	// default.
+	Iterations int
+
+	// Size of the memory, the t option of the programs. 10000 by default.
+	Size uint64
+
+	// Time of the whole exchange, DefaultTimeout by default.
+	Timeout time.Duration
+
+	// Invoked by the receiver with the listening address, e.g. to start the
+	// sender.
+	OnListen func(addr *net.TCPAddr)
+}
+
+func (c ReadBWConfig) withDefaults() ReadBWConfig {
+	if c.Iterations <= 0 {
+		c.Iterations = 5
+	}
+
+	if c.Size == 0 {
+		c.Size = 10000
+	}
+
+	if c.Timeout <= 0 {
+		c.Timeout = DefaultTimeout
+	}
+	return c
+}
+
+// Maps the memory of the size, and returns it with its address.
+func mapMemory(context *UcpContext, size uint64) (*UcpMemory, unsafe.Pointer, error) {
+	memory, err := context.MemMap((&UcpMmapParams{}).Allocate().SetLength(size))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	attrs, err := memory.Query(UCP_MEM_ATTR_FIELD_ADDRESS)
+	if err != nil {
+		memory.Close()
+		return nil, nil, err
+	}
+	return memory, attrs.Address, nil
+}
+
+func hashMemory(address unsafe.Pointer, size uint64) int32 {
+	if size > math.MaxInt32 {
+		size = math.MaxInt32
+	}
+	return ByteBufferHashCode((*[1 << 40]byte)(address)[:size:size])
+}
+
+// Runs the sender of the Java benchmark, which connects to the receiver on the
+// address, sends the description of its memory, and waits for the receiver
+// to close the connection once it has read the memory. The context of the
+// worker must be created with the tag and RMA features.
+func RunReadBWSender(context *UcpContext, worker *UcpWorker, addr *net.TCPAddr, config ReadBWConfig) error {
+	config = config.withDefaults()
+	deadline := time.Now().Add(config.Timeout)
+
+	memory, address, err := mapMemory(context, config.Size)
+	if err != nil {
+		return err
+	}
+	defer memory.Close()
+
+	data := (*[1 << 40]byte)(address)[:config.Size:config.Size]
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+
+	rkey, err := memory.RkeyPack()
+	if err != nil {
+		return err
+	}
+
+	description, _ := (&RemoteBuffer{
+		Address:  uint64(uintptr(address)),
+		Size:     config.Size,
+		Rkey:     rkey,
+		HashCode: hashMemory(address, config.Size),
+	}).MarshalBinary()
+
+	peer, err := connect(worker, addr)
+	if err != nil {
+		return err
+	}
+	defer closeEp(worker, peer.ep, false, time.Now().Add(config.Timeout))
+
+	request, err := peer.ep.SendTagBytesNonBlocking(ReadBWTag, description, nil)
+	if err := wait(worker, request, err, deadline); err != nil {
+		return err
+	}
+	return progressUntil(worker, deadline, peer.failed)
+}
+
+// Runs the receiver of the Java benchmark for one sender: it listens on the
+// address, receives the description of the sender memory, reads the memory,
+// and verifies its hash code after every read.
+func RunReadBWReceiver(context *UcpContext, worker *UcpWorker, addr *net.TCPAddr, config ReadBWConfig) error {
+	config = config.withDefaults()
+	deadline := time.Now().Add(config.Timeout)
+
+	peer, err := accept(worker, addr, config.OnListen, deadline)
+	if err != nil {
+		return err
+	}
+
+	err = readRemoteBuffer(context, worker, peer.ep, config, deadline)
+	if closeErr := closeEp(worker, peer.ep, true, deadline); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func readRemoteBuffer(context *UcpContext, worker *UcpWorker, ep *UcpEp, config ReadBWConfig,
+	deadline time.Time) error {
+	buffer := AllocateNativeMemory(readBWBufferSize)
+	defer FreeNativeMemory(buffer)
+
+	var length uint64
+	request, err := worker.RecvTagNonBlocking(buffer, readBWBufferSize, ReadBWTag, 0,
+		(&UcpRequestParams{}).SetCallback(UcpTagRecvCallback(func(request *UcpRequest,
+			status UcsStatus, info *UcpTagRecvInfo) {
+			length = info.Length
+		})))
+	if err := wait(worker, request, err, deadline); err != nil {
+		return err
+	}
+
+	var remote RemoteBuffer
+	if err := remote.UnmarshalBinary(GoBytes(buffer, length)); err != nil {
+		return err
+	}
+
+	rkey, err := ep.UnpackRkey(remote.Rkey)
+	if err != nil {
+		return err
+	}
+	defer rkey.Close()
+
+	memory, address, err := mapMemory(context, remote.Size)
+	if err != nil {
+		return err
+	}
+	defer memory.Close()
+
+	for i := 0; i < config.Iterations; i++ {
+		// The data of the previous read doesn't match the next one
+		*(*byte)(address) ^= 1
+
+		request, err := ep.RmaGetNonBlocking(address, remote.Size, remote.Address, rkey,
+			(&UcpRequestParams{}).SetMemory(memory))
+		if err := wait(worker, request, err, deadline); err != nil {
+			return err
+		}
+
+		if hashCode := hashMemory(address, remote.Size); hashCode != remote.HashCode {
+			return mismatch("hash code of read memory %v != %v", hashCode, remote.HashCode)
+		}
+	}
+	return nil
+}
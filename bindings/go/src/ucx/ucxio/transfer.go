@@ -0,0 +1,402 @@
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+// Package ucxio transfers objects of any size, e.g. files of many gigabytes,
+// over the stream of the endpoint. The object is divided into chunks, and
+// several chunks are in flight at once, so the transfer isn't stalled by the
+// round trip of every chunk.
+package ucxio
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	. "ucx"
+	"unsafe"
+)
+
+var (
+	// The sender failed to read the object, so only its part is received.
+	ErrAborted = errors.New("ucxio: transfer is aborted by the sender")
+	// The stream doesn't carry the object sent by SendLargeObject().
+	ErrMalformed = errors.New("ucxio: malformed transfer stream")
+)
+
+// The stream is the preamble: the window, the chunk size and the sizes of the
+// first window of chunks, followed by the frames of the chunks. The frame
+// header contains the size of the chunk, that is a window ahead of it. So the
+// receiver posts the receives of the exact sizes, and the receives never take
+// the data, that follows the object on the stream.
+const (
+	headerSize = 8
+	// Size announced for the chunks after the end of the object
+	noChunk = 0
+	// Size announced for the chunks after the sender failed to read
+	abortChunk   = ^uint64(0)
+	maxWindow    = 1024
+	maxChunkSize = 1 << 30
+)
+
+type Config struct {
+	// Context, that the chunk buffers are registered on. Unless set, the
+	// buffers are registered by the operations.
+	Context *UcpContext
+
+	// Size of the chunks of the object, 1 MiB by default and 1 GiB at most.
+	// The receiver uses the chunk size of the sender.
+	ChunkSize uint64
+
+	// Number of the chunks in flight, 4 by default. The receiver uses the
+	// window of the sender.
+	Window int
+}
+
+func (c *Config) setDefaults() {
+	if c.ChunkSize == 0 {
+		c.ChunkSize = 1 << 20
+	} else if c.ChunkSize > maxChunkSize {
+		c.ChunkSize = maxChunkSize
+	}
+
+	if c.Window <= 0 {
+		c.Window = 4
+	} else if c.Window > maxWindow {
+		c.Window = maxWindow
+	}
+}
+
+// Buffers of the chunks with their frame headers.
+type slots struct {
+	memory *UcpMemory
+	native unsafe.Pointer
+	view   []byte
+	size   uint64
+}
+
+func newSlots(config Config, count int) (*slots, error) {
+	s := &slots{size: headerSize + config.ChunkSize}
+	total := s.size * uint64(count)
+
+	if config.Context != nil {
+		memory, view, err := config.Context.AllocAndMap(total, nil)
+		if err != nil {
+			return nil, err
+		}
+		s.memory = memory
+		s.view = view[:total:total]
+	} else {
+		s.native = AllocateNativeMemory(total)
+		s.view = (*[1 << 40]byte)(s.native)[:total:total]
+	}
+	return s, nil
+}
+
+func (s *slots) slot(i int) []byte {
+	offset := uint64(i) * s.size
+	return s.view[offset : offset+s.size : offset+s.size]
+}
+
+func (s *slots) params() *UcpRequestParams {
+	if s.memory == nil {
+		return nil
+	}
+	return (&UcpRequestParams{}).SetMemory(s.memory)
+}
+
+func (s *slots) close() {
+	if s.memory != nil {
+		s.memory.Close()
+	} else {
+		FreeNativeMemory(s.native)
+	}
+}
+
+func wait(ctx context.Context, request *UcpRequest) error {
+	defer request.Close()
+	return request.WaitContext(ctx)
+}
+
+func send(ep *UcpEp, data []byte, params *UcpRequestParams) (*UcpRequest, error) {
+	request, err := ep.SendStreamNonBlocking(unsafe.Pointer(&data[0]), uint64(len(data)), params)
+	if err != nil {
+		request.Close()
+		return nil, err
+	}
+	return request, nil
+}
+
+func recvAll(ep *UcpEp, data []byte, params *UcpRequestParams) (*UcpRequest, error) {
+	if params == nil {
+		params = &UcpRequestParams{}
+	}
+
+	params.SetStreamRecvFlags(UCP_STREAM_RECV_FLAG_WAITALL)
+	request, err := ep.RecvStreamNonBlocking(unsafe.Pointer(&data[0]), uint64(len(data)), params)
+	if err != nil {
+		request.Close()
+		return nil, err
+	}
+	return request, nil
+}
+
+// Sender reads the chunks a window ahead of the sends, so every frame
+// announces the size of the chunk, that follows it by the window.
+type sender struct {
+	r       io.Reader
+	slots   *slots
+	sizes   []uint64
+	readErr error
+	eof     bool
+}
+
+// Reads the chunk to the slot, and returns its announced size.
+func (s *sender) read(slot int) uint64 {
+	if s.eof {
+		return noChunk
+	} else if s.readErr != nil {
+		return abortChunk
+	}
+
+	n, err := io.ReadFull(s.r, s.slots.slot(slot)[headerSize:])
+	switch err {
+	case nil:
+	case io.EOF, io.ErrUnexpectedEOF:
+		s.eof = true
+	default:
+		s.readErr = err
+		return abortChunk
+	}
+
+	if n == 0 {
+		return noChunk
+	}
+	return uint64(n)
+}
+
+// This routine reads the object from r until io.EOF, and sends it to the
+// stream of the endpoint, which peer receives it by RecvLargeObject(). The
+// routine progresses the worker of the endpoint, so it must not be called
+// concurrently with other routines progressing the worker, unless the thread
+// mode of the worker is UCS_THREAD_MODE_MULTI. It returns the number of sent
+// bytes of the object. If reading fails, the transfer is aborted, so the peer
+// fails with ErrAborted, and the read error is returned.
+func SendLargeObject(ctx context.Context, ep *UcpEp, r io.Reader, config Config) (int64, error) {
+	config.setDefaults()
+	window := config.Window
+
+	// The chunk is read to the extra slot, while the window is in flight
+	slotCount := window + 1
+	buffers, err := newSlots(config, slotCount)
+	if err != nil {
+		return 0, err
+	}
+	defer buffers.close()
+
+	s := &sender{
+		r:     r,
+		slots: buffers,
+		sizes: make([]uint64, slotCount),
+	}
+
+	preamble := make([]byte, headerSize*(window+2))
+	binary.LittleEndian.PutUint64(preamble, uint64(window))
+	binary.LittleEndian.PutUint64(preamble[headerSize:], config.ChunkSize)
+	for i := 0; i < window; i++ {
+		s.sizes[i] = s.read(i)
+		binary.LittleEndian.PutUint64(preamble[headerSize*(i+2):], s.sizes[i])
+	}
+
+	request, err := ep.SendStreamBytesNonBlocking(preamble, nil)
+	if err != nil {
+		request.Close()
+		return 0, err
+	}
+	if err := wait(ctx, request); err != nil {
+		return 0, err
+	}
+
+	var sent int64
+	var firstErr error
+	requests := make([]*UcpRequest, slotCount)
+	for i := 0; ; i++ {
+		slot := i % slotCount
+		size := s.sizes[slot]
+		if (size == noChunk) || (size == abortChunk) {
+			break
+		}
+
+		// The slot of the chunk ahead is released by the send of the previous
+		// chunk
+		ahead := (i + window) % slotCount
+		if requests[ahead] != nil {
+			if err := wait(ctx, requests[ahead]); err != nil {
+				firstErr = err
+				requests[ahead] = nil
+				break
+			}
+			requests[ahead] = nil
+		}
+		s.sizes[ahead] = s.read(ahead)
+
+		frame := buffers.slot(slot)[:headerSize+size]
+		binary.LittleEndian.PutUint64(frame, s.sizes[ahead])
+		if requests[slot], err = send(ep, frame, buffers.params()); err != nil {
+			firstErr = err
+			break
+		}
+		sent += int64(size)
+	}
+
+	for _, request := range requests {
+		if request != nil {
+			if err := wait(ctx, request); (err != nil) && (firstErr == nil) {
+				firstErr = err
+			}
+		}
+	}
+
+	if firstErr == nil {
+		firstErr = s.readErr
+	}
+	return sent, firstErr
+}
+
+// This routine receives the object sent by SendLargeObject() from the stream
+// of the endpoint, and writes it to w. The same as SendLargeObject(), it
+// progresses the worker of the endpoint. The chunk size and the window are
+// defined by the sender, only the Context of config is used. If writing
+// fails, the rest of the object is received and discarded, so the stream
+// stays usable, and the write error is returned. It returns the number of
+// written bytes.
+func RecvLargeObject(ctx context.Context, ep *UcpEp, w io.Writer, config Config) (int64, error) {
+	preamble, err := recvPreamble(ctx, ep, 2)
+	if err != nil {
+		return 0, err
+	}
+
+	window := preamble[0]
+	config.ChunkSize = preamble[1]
+	if (window == 0) || (window > maxWindow) || (config.ChunkSize == 0) ||
+		(config.ChunkSize > maxChunkSize) {
+		return 0, ErrMalformed
+	}
+	config.Window = int(window)
+
+	sizes, err := recvPreamble(ctx, ep, config.Window)
+	if err != nil {
+		return 0, err
+	}
+
+	buffers, err := newSlots(config, config.Window)
+	if err != nil {
+		return 0, err
+	}
+	defer buffers.close()
+
+	var written int64
+	var firstErr error
+	aborted := false
+	requests := make([]*UcpRequest, config.Window)
+	// The receive, that is completed immediately, has no request
+	posted := make([]bool, config.Window)
+
+	// Posts the receive of the chunk to the slot, unless there is no chunk
+	post := func(slot int, size uint64) {
+		switch {
+		case size == noChunk:
+			return
+		case size == abortChunk:
+			aborted = true
+			return
+		case size > config.ChunkSize:
+			firstErr = ErrMalformed
+			return
+		}
+
+		frame := buffers.slot(slot)[:headerSize+size]
+		request, err := recvAll(ep, frame, buffers.params())
+		if err != nil {
+			firstErr = err
+			return
+		}
+		requests[slot] = request
+		posted[slot] = true
+	}
+
+	for slot, size := range sizes {
+		if post(slot, size); !posted[slot] {
+			break
+		}
+	}
+
+	// The frames complete in the order of the receives
+	for i := 0; ; i++ {
+		slot := i % config.Window
+		if !posted[slot] {
+			break
+		}
+
+		err := wait(ctx, requests[slot])
+		requests[slot] = nil
+		posted[slot] = false
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			break
+		}
+
+		frame := buffers.slot(slot)[:headerSize+sizes[slot]]
+		if firstErr == nil {
+			n, err := w.Write(frame[headerSize:])
+			written += int64(n)
+			if err != nil {
+				firstErr = err
+			}
+		}
+
+		// After the write error the rest of the object is discarded
+		sizes[slot] = binary.LittleEndian.Uint64(frame)
+		if post(slot, sizes[slot]); firstErr == ErrMalformed {
+			break
+		}
+	}
+
+	// Receives are not canceled on the stream, so they are completed by the
+	// peer or by the endpoint failure
+	for _, request := range requests {
+		if request != nil {
+			wait(ctx, request)
+		}
+	}
+
+	if (firstErr == nil) && aborted {
+		firstErr = ErrAborted
+	}
+	return written, firstErr
+}
+
+// Receives the values of the preamble.
+func recvPreamble(ctx context.Context, ep *UcpEp, count int) ([]uint64, error) {
+	size := uint64(headerSize * count)
+	buffer := AllocateNativeMemory(size)
+	defer FreeNativeMemory(buffer)
+
+	view := (*[1 << 40]byte)(buffer)[:size:size]
+	request, err := recvAll(ep, view, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := wait(ctx, request); err != nil {
+		return nil, err
+	}
+
+	values := make([]uint64, count)
+	for i := range values {
+		values[i] = binary.LittleEndian.Uint64(view[headerSize*i:])
+	}
+	return values, nil
+}
@@ -0,0 +1,503 @@
+//go:build go1.18
+// +build go1.18
+
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+// Package ucxkv is the key-value store, which values are kept in the memory
+// registered by the server, and are written and read by the clients with RMA,
+// while the server only allocates the memory and indexes the keys, which is
+// done by the ucxrpc calls. The server memory is divided into the slots of the
+// same size, that start with the 8-byte version of the slot, which is
+// incremented by the server, once the slot is freed. So the client, that read
+// the value, that was concurrently overwritten, detects it by the version, and
+// retries the read.
+package ucxkv
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	. "ucx"
+	"ucx/ucxcodec"
+	"ucx/ucxrpc"
+	"unsafe"
+)
+
+var (
+	ErrNotFound = errors.New("ucxkv: key is not found")
+	ErrTooLarge = errors.New("ucxkv: value doesn't fit the slot")
+	ErrNoSpace  = errors.New("ucxkv: all the slots of the server are used")
+	ErrConflict = errors.New("ucxkv: value is overwritten during every read attempt")
+)
+
+// Size of the slot version, that precedes the value.
+const versionSize = 8
+
+type Config struct {
+	// Id of the first ucxrpc method of the store, the store uses 6 methods
+	// starting with it, which must be the same on all the peers. 0x6b760000
+	// by default.
+	MethodBase uint32
+
+	// Size of the slots of the server memory, including the version, and the
+	// number of the slots. 4 KiB and 1024 by default.
+	SlotSize uint64
+	Slots    int
+
+	// Number of the attempts of the client to read the value, that is
+	// overwritten concurrently. 8 by default.
+	ReadAttempts int
+}
+
+func (c Config) withDefaults() Config {
+	if c.MethodBase == 0 {
+		c.MethodBase = 0x6b760000
+	}
+
+	if c.SlotSize <= versionSize {
+		c.SlotSize = 4096
+	}
+
+	if c.Slots <= 0 {
+		c.Slots = 1024
+	}
+
+	if c.ReadAttempts <= 0 {
+		c.ReadAttempts = 8
+	}
+	return c
+}
+
+type helloRequest struct {
+	SlotSize uint64
+}
+
+type helloResponse struct {
+	Rkey []byte
+}
+
+type reserveRequest struct {
+	Size uint64
+}
+
+type reserveResponse struct {
+	NoSpace bool
+	Slot    uint32
+	Address uint64
+}
+
+type commitRequest struct {
+	Key    string
+	Slot   uint32
+	Length uint64
+}
+
+type slotRequest struct {
+	Slot uint32
+}
+
+type keyRequest struct {
+	Key string
+}
+
+type lookupResponse struct {
+	Found   bool
+	Address uint64
+	Length  uint64
+	Version uint64
+}
+
+type ack struct {
+	Found bool
+}
+
+// Methods of the store, which ids follow Config.MethodBase.
+type methods struct {
+	hello   ucxrpc.Method[helloRequest, helloResponse]
+	reserve ucxrpc.Method[reserveRequest, reserveResponse]
+	commit  ucxrpc.Method[commitRequest, ack]
+	release ucxrpc.Method[slotRequest, ack]
+	lookup  ucxrpc.Method[keyRequest, lookupResponse]
+	remove  ucxrpc.Method[keyRequest, ack]
+}
+
+func newMethods(base uint32) methods {
+	return methods{
+		hello: ucxrpc.Method[helloRequest, helloResponse]{Id: base,
+			RequestCodec: ucxcodec.GobCodec[helloRequest]{}, ResponseCodec: ucxcodec.GobCodec[helloResponse]{}},
+		reserve: ucxrpc.Method[reserveRequest, reserveResponse]{Id: base + 1,
+			RequestCodec: ucxcodec.GobCodec[reserveRequest]{}, ResponseCodec: ucxcodec.GobCodec[reserveResponse]{}},
+		commit: ucxrpc.Method[commitRequest, ack]{Id: base + 2,
+			RequestCodec: ucxcodec.GobCodec[commitRequest]{}, ResponseCodec: ucxcodec.GobCodec[ack]{}},
+		release: ucxrpc.Method[slotRequest, ack]{Id: base + 3,
+			RequestCodec: ucxcodec.GobCodec[slotRequest]{}, ResponseCodec: ucxcodec.GobCodec[ack]{}},
+		lookup: ucxrpc.Method[keyRequest, lookupResponse]{Id: base + 4,
+			RequestCodec: ucxcodec.GobCodec[keyRequest]{}, ResponseCodec: ucxcodec.GobCodec[lookupResponse]{}},
+		remove: ucxrpc.Method[keyRequest, ack]{Id: base + 5,
+			RequestCodec: ucxcodec.GobCodec[keyRequest]{}, ResponseCodec: ucxcodec.GobCodec[ack]{}},
+	}
+}
+
+// Value of the key: the slot and the length of the value in it.
+type entry struct {
+	slot   uint32
+	length uint64
+}
+
+// Server keeps the values in the memory of the context, and serves the index
+// of the keys by the methods of the node.
+type Server struct {
+	config  Config
+	memory  *UcpMemory
+	base    unsafe.Pointer
+	rkey    []byte
+	methods methods
+
+	mu       sync.Mutex
+	index    map[string]entry
+	free     []uint32
+	reserved map[uint32]bool
+}
+
+// Creates the server, which registers the memory of the store in the context
+// and the handlers of the store methods on the node. The context must be
+// created with UcpParams.EnableRMA() and UcpParams.EnableAM().
+func NewServer(context *UcpContext, node *ucxrpc.Node, config Config) (*Server, error) {
+	config = config.withDefaults()
+	memory, err := context.MemMap((&UcpMmapParams{}).Allocate().SetLength(config.SlotSize * uint64(config.Slots)))
+	if err != nil {
+		return nil, err
+	}
+
+	attrs, err := memory.Query(UCP_MEM_ATTR_FIELD_ADDRESS)
+	if err != nil {
+		memory.Close()
+		return nil, err
+	}
+
+	rkey, err := memory.RkeyPack()
+	if err != nil {
+		memory.Close()
+		return nil, err
+	}
+
+	s := &Server{
+		config:   config,
+		memory:   memory,
+		base:     attrs.Address,
+		rkey:     rkey,
+		methods:  newMethods(config.MethodBase),
+		index:    make(map[string]entry),
+		free:     make([]uint32, config.Slots),
+		reserved: make(map[uint32]bool),
+	}
+
+	for i := range s.free {
+		// The lowest slots are used first
+		s.free[i] = uint32(config.Slots - 1 - i)
+		atomic.StoreUint64(s.version(s.free[i]), 0)
+	}
+
+	s.methods.hello.Handle(node, s.hello)
+	s.methods.reserve.Handle(node, s.reserve)
+	s.methods.commit.Handle(node, s.commit)
+	s.methods.release.Handle(node, s.release)
+	s.methods.lookup.Handle(node, s.lookup)
+	s.methods.remove.Handle(node, s.remove)
+	return s, nil
+}
+
+func (s *Server) version(slot uint32) *uint64 {
+	return (*uint64)(unsafe.Pointer(uintptr(s.base) + uintptr(uint64(slot)*s.config.SlotSize)))
+}
+
+func (s *Server) address(slot uint32) uint64 {
+	return uint64(uintptr(s.base)) + uint64(slot)*s.config.SlotSize
+}
+
+// Returns the slot to the free ones, so the readers of its value detect the
+// reuse.
+func (s *Server) freeSlot(slot uint32) {
+	atomic.AddUint64(s.version(slot), 1)
+	s.free = append(s.free, slot)
+}
+
+func (s *Server) hello(ctx context.Context, req helloRequest) (helloResponse, error) {
+	if req.SlotSize != s.config.SlotSize {
+		return helloResponse{}, errors.New("ucxkv: slot size of the client doesn't match the server")
+	}
+	return helloResponse{Rkey: s.rkey}, nil
+}
+
+func (s *Server) reserve(ctx context.Context, req reserveRequest) (reserveResponse, error) {
+	if req.Size > s.config.SlotSize-versionSize {
+		return reserveResponse{}, ErrTooLarge
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.free) == 0 {
+		return reserveResponse{NoSpace: true}, nil
+	}
+
+	slot := s.free[len(s.free)-1]
+	s.free = s.free[:len(s.free)-1]
+	s.reserved[slot] = true
+	return reserveResponse{Slot: slot, Address: s.address(slot)}, nil
+}
+
+func (s *Server) commit(ctx context.Context, req commitRequest) (ack, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.reserved[req.Slot] {
+		return ack{}, errors.New("ucxkv: slot is not reserved")
+	}
+	delete(s.reserved, req.Slot)
+
+	old, found := s.index[req.Key]
+	s.index[req.Key] = entry{slot: req.Slot, length: req.Length}
+	if found {
+		s.freeSlot(old.slot)
+	}
+	return ack{Found: found}, nil
+}
+
+func (s *Server) release(ctx context.Context, req slotRequest) (ack, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.reserved[req.Slot] {
+		return ack{}, nil
+	}
+
+	delete(s.reserved, req.Slot)
+	s.freeSlot(req.Slot)
+	return ack{Found: true}, nil
+}
+
+func (s *Server) lookup(ctx context.Context, req keyRequest) (lookupResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, found := s.index[req.Key]
+	if !found {
+		return lookupResponse{}, nil
+	}
+
+	return lookupResponse{
+		Found:   true,
+		Address: s.address(e.slot),
+		Length:  e.length,
+		Version: atomic.LoadUint64(s.version(e.slot)),
+	}, nil
+}
+
+func (s *Server) remove(ctx context.Context, req keyRequest) (ack, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, found := s.index[req.Key]
+	if found {
+		delete(s.index, req.Key)
+		s.freeSlot(e.slot)
+	}
+	return ack{Found: found}, nil
+}
+
+// Number of the keys of the store.
+func (s *Server) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.index)
+}
+
+// Releases the memory of the store. The node must be closed before, so the
+// methods are not served anymore, and the clients must not access the memory
+// afterwards.
+func (s *Server) Close() error {
+	return s.memory.Close()
+}
+
+// Client of the server on the endpoint. The routines can be called
+// concurrently.
+type Client struct {
+	config  Config
+	loop    *UcpProgressLoop
+	node    *ucxrpc.Node
+	ep      *UcpEp
+	rkey    *UcpRkey
+	methods methods
+}
+
+// Creates the client of the server on the endpoint, which receives the remote
+// key of the server memory. The node must be created on the worker of the
+// endpoint, which is progressed by the loop. The config must match the one of
+// the server.
+func NewClient(ctx context.Context, loop *UcpProgressLoop, node *ucxrpc.Node, ep *UcpEp,
+	config Config) (*Client, error) {
+	config = config.withDefaults()
+	c := &Client{
+		config:  config,
+		loop:    loop,
+		node:    node,
+		ep:      ep,
+		methods: newMethods(config.MethodBase),
+	}
+
+	resp, err := c.methods.hello.Call(ctx, node, ep, helloRequest{SlotSize: config.SlotSize})
+	if err != nil {
+		return nil, err
+	}
+
+	if loopErr := loop.Execute(func() {
+		c.rkey, err = ep.UnpackRkey(resp.Rkey)
+	}); loopErr != nil {
+		return nil, loopErr
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Executes the operation on the loop, and waits for its completion. The wait
+// isn't interrupted by the context, since the operation buffers must stay
+// valid until the completion.
+func (c *Client) execute(op func(params *UcpRequestParams) (*UcpRequest, error)) error {
+	done := make(chan UcsStatus, 1)
+	if err := c.loop.Execute(func() {
+		SubmitRequest(op, func(status UcsStatus) { done <- status })
+	}); err != nil {
+		return err
+	}
+
+	if status := <-done; status != UCS_OK {
+		return NewUcxError(status)
+	}
+	return nil
+}
+
+// Writes the value to the free slot of the server, and then publishes it
+// under the key, replacing the previous value. The value is flushed to the
+// server memory before it's published, so the readers never see the partial
+// value.
+func (c *Client) Put(ctx context.Context, key string, value []byte) error {
+	if uint64(len(value)) > c.config.SlotSize-versionSize {
+		return ErrTooLarge
+	}
+
+	reserved, err := c.methods.reserve.Call(ctx, c.node, c.ep, reserveRequest{Size: uint64(len(value))})
+	if err != nil {
+		return err
+	}
+
+	if reserved.NoSpace {
+		return ErrNoSpace
+	}
+
+	if err = c.write(reserved.Address+versionSize, value); err == nil {
+		_, err = c.methods.commit.Call(ctx, c.node, c.ep, commitRequest{
+			Key:    key,
+			Slot:   reserved.Slot,
+			Length: uint64(len(value)),
+		})
+		if err == nil {
+			return nil
+		}
+	}
+
+	// The slot is returned by the best effort, e.g. unless the server is gone
+	c.methods.release.Call(ctx, c.node, c.ep, slotRequest{Slot: reserved.Slot})
+	return err
+}
+
+// Puts the value to the remote address, and flushes the endpoint, so the
+// value is in the server memory once it returns.
+func (c *Client) write(address uint64, value []byte) error {
+	if len(value) != 0 {
+		buffer := CBytes(value)
+		defer FreeNativeMemory(buffer)
+		if err := c.execute(func(params *UcpRequestParams) (*UcpRequest, error) {
+			return c.ep.RmaPutNonBlocking(buffer, uint64(len(value)), address, c.rkey, params)
+		}); err != nil {
+			return err
+		}
+	}
+
+	return c.execute(func(params *UcpRequestParams) (*UcpRequest, error) {
+		return c.ep.FlushNonBlocking(params)
+	})
+}
+
+// Reads the value of the key from the server memory. The version of the slot
+// is read after the value, so the value, that is overwritten during the read,
+// is read again, up to Config.ReadAttempts times, and ErrConflict is
+// returned afterwards.
+func (c *Client) Get(ctx context.Context, key string) ([]byte, error) {
+	version := AllocateNativeMemory(versionSize)
+	defer FreeNativeMemory(version)
+
+	for attempt := 0; attempt < c.config.ReadAttempts; attempt++ {
+		found, err := c.methods.lookup.Call(ctx, c.node, c.ep, keyRequest{Key: key})
+		if err != nil {
+			return nil, err
+		}
+
+		if !found.Found {
+			return nil, ErrNotFound
+		}
+
+		value, err := c.read(found.Address+versionSize, found.Length)
+		if err != nil {
+			return nil, err
+		}
+
+		if err = c.execute(func(params *UcpRequestParams) (*UcpRequest, error) {
+			return c.ep.RmaGetNonBlocking(version, versionSize, found.Address, c.rkey, params)
+		}); err != nil {
+			return nil, err
+		}
+
+		if *(*uint64)(version) == found.Version {
+			return value, nil
+		}
+	}
+	return nil, ErrConflict
+}
+
+func (c *Client) read(address uint64, length uint64) ([]byte, error) {
+	if length == 0 {
+		return []byte{}, nil
+	}
+
+	buffer := AllocateNativeMemory(length)
+	defer FreeNativeMemory(buffer)
+	if err := c.execute(func(params *UcpRequestParams) (*UcpRequest, error) {
+		return c.ep.RmaGetNonBlocking(buffer, length, address, c.rkey, params)
+	}); err != nil {
+		return nil, err
+	}
+	return GoBytes(buffer, length), nil
+}
+
+// Removes the key from the store, and returns ErrNotFound if there is no such
+// key.
+func (c *Client) Delete(ctx context.Context, key string) error {
+	resp, err := c.methods.remove.Call(ctx, c.node, c.ep, keyRequest{Key: key})
+	if err != nil {
+		return err
+	}
+
+	if !resp.Found {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Destroys the remote key of the server memory. The client must not be used
+// after this call, and it must be closed before the endpoint.
+func (c *Client) Close() error {
+	return c.loop.Execute(c.rkey.Close)
+}
@@ -0,0 +1,303 @@
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+// Package ucxmem hands out buffers of the memory, that is registered by UCP in
+// large slabs, so the operations on the buffers reuse the registration instead
+// of registering every message.
+package ucxmem
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	. "ucx"
+	"unsafe"
+)
+
+var (
+	ErrClosed   = errors.New("ucxmem: pool is closed")
+	ErrTooLarge = errors.New("ucxmem: size exceeds the largest size class")
+	ErrLimit    = errors.New("ucxmem: memory limit of the pool is reached")
+)
+
+type Config struct {
+	// Sizes of the buffers, which are rounded up to the closest one. 256 B,
+	// 4 KiB, 64 KiB and 1 MiB by default.
+	SizeClasses []uint64
+
+	// Size of the memory, that is registered at once and divided into the
+	// buffers of the same size class. It's increased to the size class, if
+	// that is larger. 4 MiB by default.
+	SlabSize uint64
+
+	// Maximal total size of the slabs, zero means no limit.
+	MaxMemory uint64
+
+	// Records the stack of Get() for every buffer, so the buffers, that are
+	// not released, are reported by Pool.Leaks() and by OnLeak.
+	TrackLeaks bool
+
+	// Invoked from the finalizer of the buffer, that is garbage collected
+	// without Buffer.Release(). The stack is empty unless TrackLeaks is set.
+	// The memory of the leaked buffer is not reused, since it may still be
+	// referenced by its slice.
+	OnLeak func(stack string)
+}
+
+// Pool of the buffers of the registered memory of the context. The routines
+// can be called concurrently.
+type Pool struct {
+	context *UcpContext
+	config  Config
+	mu      sync.Mutex
+	classes []*sizeClass
+	slabs   []*UcpMemory
+	mapped  uint64
+	// Addresses of the buffers, that are not released, to the stack of their
+	// Get(). The buffers are not referenced, so their finalizers detect leaks.
+	outstanding map[uintptr]string
+	closed      bool
+}
+
+type sizeClass struct {
+	size uint64
+	free []chunk
+}
+
+type chunk struct {
+	address unsafe.Pointer
+	memory  *UcpMemory
+}
+
+// Buffer of the pool, which memory registration is passed to the operations
+// by Buffer.Params() or UcpRequestParams.SetMemory(Buffer.Memory()).
+type Buffer struct {
+	pool     *Pool
+	class    *sizeClass
+	chunk    chunk
+	length   uint64
+	released bool
+}
+
+// Creates the pool of the memory registered on the context. The slabs are
+// registered on demand, and are unmapped by Pool.Close().
+func NewPool(context *UcpContext, config Config) *Pool {
+	if len(config.SizeClasses) == 0 {
+		config.SizeClasses = []uint64{256, 4 << 10, 64 << 10, 1 << 20}
+	}
+
+	if config.SlabSize == 0 {
+		config.SlabSize = 4 << 20
+	}
+
+	sizes := append([]uint64(nil), config.SizeClasses...)
+	sort.Slice(sizes, func(i, j int) bool { return sizes[i] < sizes[j] })
+
+	p := &Pool{
+		context:     context,
+		config:      config,
+		outstanding: make(map[uintptr]string),
+	}
+	for _, size := range sizes {
+		if size > 0 {
+			p.classes = append(p.classes, &sizeClass{size: size})
+		}
+	}
+	return p
+}
+
+func (p *Pool) classOf(size uint64) *sizeClass {
+	for _, class := range p.classes {
+		if class.size >= size {
+			return class
+		}
+	}
+	return nil
+}
+
+// Registers a new slab, and divides it into the free chunks of the class.
+func (p *Pool) grow(class *sizeClass) error {
+	slabSize := p.config.SlabSize
+	if slabSize < class.size {
+		slabSize = class.size
+	}
+	slabSize -= slabSize % class.size
+
+	if (p.config.MaxMemory != 0) && (p.mapped+slabSize > p.config.MaxMemory) {
+		return ErrLimit
+	}
+
+	memory, err := p.context.MemMap((&UcpMmapParams{}).Allocate().SetLength(slabSize))
+	if err != nil {
+		return err
+	}
+
+	attrs, err := memory.Query(UCP_MEM_ATTR_FIELD_ADDRESS)
+	if err != nil {
+		memory.Close()
+		return err
+	}
+
+	p.slabs = append(p.slabs, memory)
+	p.mapped += slabSize
+	for offset := uint64(0); offset < slabSize; offset += class.size {
+		class.free = append(class.free, chunk{
+			address: unsafe.Pointer(uintptr(attrs.Address) + uintptr(offset)),
+			memory:  memory,
+		})
+	}
+	return nil
+}
+
+// Returns the buffer of size bytes, which capacity is its size class. The
+// buffer must be released by Buffer.Release().
+func (p *Pool) Get(size uint64) (*Buffer, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil, ErrClosed
+	}
+
+	class := p.classOf(size)
+	if class == nil {
+		return nil, ErrTooLarge
+	}
+
+	if len(class.free) == 0 {
+		if err := p.grow(class); err != nil {
+			return nil, err
+		}
+	}
+
+	b := &Buffer{
+		pool:   p,
+		class:  class,
+		chunk:  class.free[len(class.free)-1],
+		length: size,
+	}
+	class.free = class.free[:len(class.free)-1]
+
+	var stack string
+	if p.config.TrackLeaks {
+		stack = callerStack()
+	}
+	p.outstanding[uintptr(b.chunk.address)] = stack
+	runtime.SetFinalizer(b, (*Buffer).leaked)
+	return b, nil
+}
+
+func callerStack() string {
+	pcs := make([]uintptr, 32)
+	// Skips runtime.Callers, callerStack and Pool.Get
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var sb strings.Builder
+	for {
+		frame, more := frames.Next()
+		fmt.Fprintf(&sb, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		if !more {
+			return sb.String()
+		}
+	}
+}
+
+// Returns the stacks of Get() of the buffers, that are not released. The
+// stacks are empty unless Config.TrackLeaks is set.
+func (p *Pool) Leaks() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	leaks := make([]string, 0, len(p.outstanding))
+	for _, stack := range p.outstanding {
+		leaks = append(leaks, stack)
+	}
+	return leaks
+}
+
+// Unmaps all the slabs of the pool. Fails if any of the buffers is not
+// released, since its memory would be unmapped while in use.
+func (p *Pool) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil
+	}
+
+	if len(p.outstanding) != 0 {
+		return fmt.Errorf("ucxmem: %v buffers are not released", len(p.outstanding))
+	}
+
+	p.closed = true
+	for _, slab := range p.slabs {
+		slab.Close()
+	}
+	p.slabs = nil
+	for _, class := range p.classes {
+		class.free = nil
+	}
+	return nil
+}
+
+// Invoked by the finalizer of the buffer, that is not released.
+func (b *Buffer) leaked() {
+	p := b.pool
+	p.mu.Lock()
+	stack := p.outstanding[uintptr(b.chunk.address)]
+	delete(p.outstanding, uintptr(b.chunk.address))
+	p.mu.Unlock()
+
+	if p.config.OnLeak != nil {
+		p.config.OnLeak(stack)
+	}
+}
+
+// Returns the memory of the buffer back to the pool. Neither the buffer nor its
+// slices may be used after this call.
+func (b *Buffer) Release() {
+	p := b.pool
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if b.released {
+		return
+	}
+
+	b.released = true
+	delete(p.outstanding, uintptr(b.chunk.address))
+	runtime.SetFinalizer(b, nil)
+	b.class.free = append(b.class.free, b.chunk)
+}
+
+// Slice of the buffer memory of the requested length, which capacity is the
+// size class of the buffer.
+func (b *Buffer) Bytes() []byte {
+	return (*[1 << 40]byte)(b.chunk.address)[:b.length:b.class.size]
+}
+
+func (b *Buffer) Pointer() unsafe.Pointer {
+	return b.chunk.address
+}
+
+// Requested length of the buffer.
+func (b *Buffer) Length() uint64 {
+	return b.length
+}
+
+// Registration of the slab, that contains the buffer.
+func (b *Buffer) Memory() *UcpMemory {
+	return b.chunk.memory
+}
+
+// Request params with the registration of the buffer, so the operation doesn't
+// look it up.
+func (b *Buffer) Params() *UcpRequestParams {
+	return (&UcpRequestParams{}).SetMemory(b.chunk.memory)
+}
@@ -0,0 +1,97 @@
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+// Package ucxmetrics exports UCX statistics counters in Prometheus text
+// exposition format, so they can be scraped without any extra dependency.
+// UCX has to be built with --enable-stats and run with UCX_STATS_DEST set
+// (e.g. UCX_STATS_DEST=file:/dev/null) for the counters to be collected.
+package ucxmetrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	. "ucx"
+)
+
+// Prefix of all the exported metrics names.
+const metricPrefix = "ucx_"
+
+// Content type of Prometheus text exposition format.
+const contentType = "text/plain; version=0.0.4; charset=utf-8"
+
+type metric struct {
+	name  string
+	value uint64
+}
+
+// Converts class and counter names to a valid Prometheus metric name, e.g.
+// "ucp_ep" and "tx_eager" to "ucx_ucp_ep_tx_eager_total".
+func metricName(class, counter string) string {
+	sanitize := func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}
+	return metricPrefix + strings.Map(sanitize, class) + "_" + strings.Map(sanitize, counter) + "_total"
+}
+
+// Snapshot of the counters, sorted by metric name. Counters, that map to the
+// same name, are summed up.
+func collect() ([]metric, error) {
+	counters, err := UcsStatsSnapshot()
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]uint64, len(counters))
+	for _, counter := range counters {
+		values[metricName(counter.Class, counter.Name)] += counter.Value
+	}
+
+	metrics := make([]metric, 0, len(values))
+	for name, value := range values {
+		metrics = append(metrics, metric{name, value})
+	}
+	sort.Slice(metrics, func(i, j int) bool { return metrics[i].name < metrics[j].name })
+	return metrics, nil
+}
+
+func write(w io.Writer, metrics []metric) error {
+	bw := bufio.NewWriter(w)
+	for _, m := range metrics {
+		fmt.Fprintf(bw, "# TYPE %s counter\n%s %d\n", m.name, m.name, m.value)
+	}
+	return bw.Flush()
+}
+
+// WriteMetrics writes the current values of UCX counters to w in Prometheus
+// text exposition format.
+func WriteMetrics(w io.Writer) error {
+	metrics, err := collect()
+	if err != nil {
+		return err
+	}
+	return write(w, metrics)
+}
+
+// Handler returns an HTTP handler, that serves UCX counters to Prometheus
+// scrapes, e.g. http.Handle("/metrics", ucxmetrics.Handler()).
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		metrics, err := collect()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		write(w, metrics)
+	})
+}
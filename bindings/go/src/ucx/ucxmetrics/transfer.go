@@ -0,0 +1,115 @@
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package ucxmetrics
+
+import (
+	"bufio"
+	"expvar"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	. "ucx"
+)
+
+// Transfer counters of the worker and of its endpoints by their names, as
+// they are published by PublishTransferStats().
+type TransferStats struct {
+	Worker    UcpTransferStats
+	Endpoints map[string]UcpTransferStats
+}
+
+func collectTransferStats(worker *UcpWorker) TransferStats {
+	stats := TransferStats{
+		Worker:    worker.Stats(),
+		Endpoints: make(map[string]UcpTransferStats),
+	}
+
+	for _, ep := range worker.EndpointStats() {
+		stats.Endpoints[ep.Name] = ep.Stats
+	}
+	return stats
+}
+
+// PublishTransferStats publishes the transfer counters of the worker, that is
+// created with UcpWorkerParams.EnableTransferStats(), as the expvar variable
+// of the name, so they are served by the /debug/vars handler. Same as
+// expvar.Publish(), it panics if the name is already published, and the
+// variable can't be removed, so the worker must stay open.
+func PublishTransferStats(name string, worker *UcpWorker) {
+	expvar.Publish(name, expvar.Func(func() interface{} {
+		return collectTransferStats(worker)
+	}))
+}
+
+type transferMetric struct {
+	name  string
+	kind  string
+	help  string
+	value func(stats UcpTransferStats) string
+}
+
+var transferMetrics = []transferMetric{
+	{"messages_sent_total", "counter", "Messages sent", func(s UcpTransferStats) string {
+		return strconv.FormatUint(s.MessagesSent, 10)
+	}},
+	{"bytes_sent_total", "counter", "Bytes sent", func(s UcpTransferStats) string {
+		return strconv.FormatUint(s.BytesSent, 10)
+	}},
+	{"messages_received_total", "counter", "Messages received", func(s UcpTransferStats) string {
+		return strconv.FormatUint(s.MessagesReceived, 10)
+	}},
+	{"bytes_received_total", "counter", "Bytes received", func(s UcpTransferStats) string {
+		return strconv.FormatUint(s.BytesReceived, 10)
+	}},
+	{"pending_requests", "gauge", "Operations in progress", func(s UcpTransferStats) string {
+		return strconv.FormatInt(s.PendingRequests, 10)
+	}},
+	{"errors_total", "counter", "Operations failed", func(s UcpTransferStats) string {
+		return strconv.FormatUint(s.Errors, 10)
+	}},
+}
+
+// WriteTransferStats writes the transfer counters of the workers by their
+// names to w in Prometheus text exposition format, e.g. the worker counters
+// as ucx_worker_bytes_sent_total{worker="..."} and the endpoint counters as
+// ucx_endpoint_bytes_sent_total{worker="...",endpoint="..."}.
+func WriteTransferStats(w io.Writer, workers map[string]*UcpWorker) error {
+	names := make([]string, 0, len(workers))
+	stats := make(map[string]TransferStats, len(workers))
+	for name, worker := range workers {
+		names = append(names, name)
+		stats[name] = collectTransferStats(worker)
+	}
+	sort.Strings(names)
+
+	bw := bufio.NewWriter(w)
+	for _, m := range transferMetrics {
+		name := metricPrefix + "worker_" + m.name
+		fmt.Fprintf(bw, "# HELP %s %s by the worker\n# TYPE %s %s\n", name, m.help, name, m.kind)
+		for _, worker := range names {
+			fmt.Fprintf(bw, "%s{worker=%q} %s\n", name, worker, m.value(stats[worker].Worker))
+		}
+	}
+
+	for _, m := range transferMetrics {
+		name := metricPrefix + "endpoint_" + m.name
+		fmt.Fprintf(bw, "# HELP %s %s by the endpoint\n# TYPE %s %s\n", name, m.help, name, m.kind)
+		for _, worker := range names {
+			eps := make([]string, 0, len(stats[worker].Endpoints))
+			for ep := range stats[worker].Endpoints {
+				eps = append(eps, ep)
+			}
+			sort.Strings(eps)
+
+			for _, ep := range eps {
+				fmt.Fprintf(bw, "%s{worker=%q,endpoint=%q} %s\n", name, worker, ep,
+					m.value(stats[worker].Endpoints[ep]))
+			}
+		}
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,332 @@
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+// Package ucxnet implements net.Conn and net.Listener on top of UCP stream
+// endpoints, so that existing Go code can communicate over UCX transparently.
+package ucxnet
+
+import (
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+	. "ucx"
+	"unsafe"
+)
+
+var _ net.Conn = (*Conn)(nil)
+
+// Default size of the native buffer, that receives the stream data of the
+// connection.
+const defaultReadBufferSize = 64 * 1024
+
+// Conn is a net.Conn over UCP stream endpoint.
+type Conn struct {
+	engine     *engine
+	ep         *UcpEp
+	localAddr  net.Addr
+	remoteAddr net.Addr
+
+	readMu         sync.Mutex
+	recvBuffer     unsafe.Pointer
+	recvBufferSize uint64
+	pendingRecv    *UcpRequest
+	pendingLen     uint64
+	leftover       []byte
+
+	writeMu sync.Mutex
+	// Sends, that are still in progress after the write deadline passed
+	expiredSends sync.WaitGroup
+
+	readDeadline  *deadline
+	writeDeadline *deadline
+
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func newConn(e *engine, ep *UcpEp, localAddr, remoteAddr net.Addr, readBufferSize int) *Conn {
+	if readBufferSize <= 0 {
+		readBufferSize = defaultReadBufferSize
+	}
+
+	return &Conn{
+		engine:         e,
+		ep:             ep,
+		localAddr:      localAddr,
+		remoteAddr:     remoteAddr,
+		recvBuffer:     AllocateNativeMemory(uint64(readBufferSize)),
+		recvBufferSize: uint64(readBufferSize),
+		readDeadline:   newDeadline(),
+		writeDeadline:  newDeadline(),
+		closed:         make(chan struct{}),
+	}
+}
+
+// Endpoint parameters of the connection, which make pending operations to
+// complete with an error once the peer is gone.
+func newEpParams() *UcpEpParams {
+	return (&UcpEpParams{}).SetPeerErrorHandling().SetErrorHandler(func(ep *UcpEp, status UcsStatus) {})
+}
+
+func statusToError(status UcsStatus) error {
+	switch status {
+	case UCS_OK:
+		return nil
+	case UCS_ERR_CONNECTION_RESET, UCS_ERR_NOT_CONNECTED, UCS_ERR_ENDPOINT_TIMEOUT:
+		return io.EOF
+	case UCS_ERR_CANCELED:
+		return net.ErrClosed
+	}
+	return NewUcxError(status)
+}
+
+func (c *Conn) isClosed() bool {
+	select {
+	case <-c.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+// Read reads data from the connection. If the read deadline passes, the
+// posted receive is kept, so no data is lost and the next Read returns it.
+func (c *Conn) Read(b []byte) (int, error) {
+	c.readMu.Lock()
+	defer c.readMu.Unlock()
+
+	if c.isClosed() {
+		return 0, net.ErrClosed
+	}
+
+	if len(c.leftover) > 0 {
+		n := copy(b, c.leftover)
+		c.leftover = c.leftover[n:]
+		return n, nil
+	}
+
+	if len(b) == 0 {
+		return 0, nil
+	}
+
+	if c.pendingRecv == nil {
+		size := uint64(len(b))
+		if size > c.recvBufferSize {
+			size = c.recvBufferSize
+		}
+
+		var err error
+		params := (&UcpRequestParams{}).EnableDoneChannel().SetCallback(
+			func(request *UcpRequest, status UcsStatus, length uint64) {
+				c.pendingLen = length
+			})
+		if callErr := c.engine.call(func() {
+			c.pendingRecv, err = c.ep.RecvStreamNonBlocking(c.recvBuffer, size, params)
+		}); callErr != nil {
+			return 0, callErr
+		}
+
+		if err != nil {
+			c.pendingRecv = nil
+			return 0, err
+		}
+	}
+
+	for {
+		expired, changed, stop := c.readDeadline.timer()
+		select {
+		case status := <-c.pendingRecv.Done():
+			stop()
+			c.releaseRequest(c.pendingRecv)
+			c.pendingRecv = nil
+			if status != UCS_OK {
+				return 0, statusToError(status)
+			}
+
+			data := GoBytes(c.recvBuffer, c.pendingLen)
+			n := copy(b, data)
+			c.leftover = data[n:]
+			return n, nil
+		case <-expired:
+			return 0, os.ErrDeadlineExceeded
+		case <-changed:
+			stop()
+		case <-c.closed:
+			stop()
+			return 0, net.ErrClosed
+		}
+	}
+}
+
+// Write writes data to the connection. If the write deadline passes, Write
+// returns an error, while the data may still be delivered to the peer.
+func (c *Conn) Write(b []byte) (int, error) {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	if c.isClosed() {
+		return 0, net.ErrClosed
+	}
+
+	if len(b) == 0 {
+		return 0, nil
+	}
+
+	var request *UcpRequest
+	var err error
+	sendBuffer := CBytes(b)
+	params := (&UcpRequestParams{}).EnableDoneChannel()
+	if callErr := c.engine.call(func() {
+		request, err = c.ep.SendStreamNonBlocking(sendBuffer, uint64(len(b)), params)
+	}); callErr != nil {
+		FreeNativeMemory(sendBuffer)
+		return 0, callErr
+	}
+
+	if err != nil {
+		FreeNativeMemory(sendBuffer)
+		return 0, err
+	}
+
+	for {
+		expired, changed, stop := c.writeDeadline.timer()
+		select {
+		case status := <-request.Done():
+			stop()
+			c.releaseRequest(request)
+			FreeNativeMemory(sendBuffer)
+			if status != UCS_OK {
+				return 0, statusToError(status)
+			}
+			return len(b), nil
+		case <-expired:
+			// The send buffer can be released only after the completion
+			c.expiredSends.Add(1)
+			go func() {
+				defer c.expiredSends.Done()
+				<-request.Done()
+				c.releaseRequest(request)
+				FreeNativeMemory(sendBuffer)
+			}()
+			return 0, os.ErrDeadlineExceeded
+		case <-changed:
+			stop()
+		}
+	}
+}
+
+func (c *Conn) releaseRequest(request *UcpRequest) {
+	c.engine.call(request.Close)
+}
+
+// Close closes the connection. Any blocked Read or Write operations will be
+// unblocked and return errors.
+func (c *Conn) Close() error {
+	closed := false
+	c.closeOnce.Do(func() {
+		close(c.closed)
+		closed = true
+	})
+
+	if !closed {
+		return net.ErrClosed
+	}
+
+	// Closing the endpoint completes all its outstanding operations
+	var closeRequest *UcpRequest
+	var err error
+	c.engine.call(func() {
+		closeRequest, err = c.ep.CloseNonBlockingFlush((&UcpRequestParams{}).EnableDoneChannel())
+	})
+	if err == nil {
+		<-closeRequest.Done()
+		c.releaseRequest(closeRequest)
+	}
+
+	c.writeMu.Lock()
+	c.expiredSends.Wait()
+	c.writeMu.Unlock()
+
+	c.readMu.Lock()
+	if c.pendingRecv != nil {
+		<-c.pendingRecv.Done()
+		c.releaseRequest(c.pendingRecv)
+		c.pendingRecv = nil
+	}
+	FreeNativeMemory(c.recvBuffer)
+	c.readMu.Unlock()
+
+	c.engine.unref()
+	return err
+}
+
+// LocalAddr returns the local network address, if known.
+func (c *Conn) LocalAddr() net.Addr {
+	return c.localAddr
+}
+
+// RemoteAddr returns the remote network address, if known.
+func (c *Conn) RemoteAddr() net.Addr {
+	return c.remoteAddr
+}
+
+// SetDeadline sets the read and write deadlines associated with the
+// connection. A zero value for t means I/O operations will not time out.
+func (c *Conn) SetDeadline(t time.Time) error {
+	c.readDeadline.set(t)
+	c.writeDeadline.set(t)
+	return nil
+}
+
+// SetReadDeadline sets the deadline for future Read calls and any
+// currently-blocked Read call.
+func (c *Conn) SetReadDeadline(t time.Time) error {
+	c.readDeadline.set(t)
+	return nil
+}
+
+// SetWriteDeadline sets the deadline for future Write calls and any
+// currently-blocked Write call.
+func (c *Conn) SetWriteDeadline(t time.Time) error {
+	c.writeDeadline.set(t)
+	return nil
+}
+
+// Deadline of I/O operations, which notifies blocked operations on update.
+type deadline struct {
+	mu      sync.Mutex
+	t       time.Time
+	changed chan struct{}
+}
+
+func newDeadline() *deadline {
+	return &deadline{
+		changed: make(chan struct{}),
+	}
+}
+
+func (d *deadline) set(t time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.t = t
+	close(d.changed)
+	d.changed = make(chan struct{})
+}
+
+// Returns the channel, that fires when the deadline passes, the channel, that
+// fires when the deadline is changed, and the function to release the timer.
+func (d *deadline) timer() (<-chan time.Time, <-chan struct{}, func()) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.t.IsZero() {
+		return nil, d.changed, func() {}
+	}
+
+	timer := time.NewTimer(time.Until(d.t))
+	return timer.C, d.changed, func() { timer.Stop() }
+}
@@ -0,0 +1,111 @@
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package ucxnet
+
+import (
+	"context"
+	"net"
+	"time"
+	. "ucx"
+)
+
+// Dialer contains options for connecting to a Listener.
+type Dialer struct {
+	// Maximum amount of time a dial will wait for a connection to be
+	// established. Zero value means no timeout.
+	Timeout time.Duration
+
+	// Size of the native buffer, that receives the stream data of the
+	// connection, 64 KiB by default. Larger buffer takes less receive
+	// operations to read bulk data, e.g. of gRPC with large flow control
+	// windows.
+	ReadBufferSize int
+}
+
+// Dial connects to the address on the named network, which must be "tcp",
+// "tcp4" or "tcp6".
+func Dial(network, address string) (*Conn, error) {
+	var d Dialer
+	return d.DialContext(context.Background(), network, address)
+}
+
+// DialContext connects to the address on the named network using the provided
+// context. The connection is established, when DialContext returns. Every
+// connection uses its own UCP worker.
+func (d *Dialer) DialContext(ctx context.Context, network, address string) (*Conn, error) {
+	if d.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d.Timeout)
+		defer cancel()
+	}
+
+	tcpAddr, err := net.ResolveTCPAddr(network, address)
+	if err != nil {
+		return nil, err
+	}
+
+	epParams, err := newEpParams().SetSocketAddress(tcpAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	e, err := newEngine()
+	if err != nil {
+		return nil, err
+	}
+
+	var ep *UcpEp
+	var flushRequest *UcpRequest
+	e.call(func() {
+		if ep, err = e.worker.NewEndpoint(epParams); err != nil {
+			return
+		}
+
+		// Flush completes once the connection is established
+		flushRequest, err = ep.FlushNonBlocking((&UcpRequestParams{}).EnableDoneChannel())
+	})
+
+	if err != nil {
+		e.unref()
+		return nil, err
+	}
+
+	conn := newConn(e, ep, &net.TCPAddr{}, tcpAddr, d.ReadBufferSize)
+	select {
+	case status := <-flushRequest.Done():
+		conn.releaseRequest(flushRequest)
+		if status != UCS_OK {
+			conn.Close()
+			return nil, NewUcxError(status)
+		}
+	case <-ctx.Done():
+		// Closing the endpoint also completes the flush request
+		conn.Close()
+		return nil, ctx.Err()
+	}
+
+	return conn, nil
+}
+
+// DialFunc returns the function, that connects to the address on the named
+// network by the dialer. It's compatible with grpc.WithContextDialer(), so a
+// gRPC client is switched to UCX by:
+//
+//	grpc.Dial(address, grpc.WithContextDialer((&ucxnet.Dialer{}).DialFunc("tcp")), ...)
+//
+// The gRPC server serves the connections of Listener by grpc.Server.Serve().
+// TLS credentials, including ALPN negotiation, work on top of the connections
+// same as on top of TCP ones.
+func (d *Dialer) DialFunc(network string) func(ctx context.Context, address string) (net.Conn, error) {
+	return func(ctx context.Context, address string) (net.Conn, error) {
+		conn, err := d.DialContext(ctx, network, address)
+		if err != nil {
+			// Avoid returning the typed nil in the interface
+			return nil, err
+		}
+		return conn, nil
+	}
+}
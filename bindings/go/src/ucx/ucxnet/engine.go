@@ -0,0 +1,120 @@
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package ucxnet
+
+import (
+	"net"
+	"sync"
+	. "ucx"
+)
+
+// Engine owns UCP context and worker, and runs all operations on them from a
+// single progress goroutine. The goroutine sleeps in Go runtime poller on the
+// worker event file descriptor when there is nothing to progress.
+type engine struct {
+	context *UcpContext
+	worker  *UcpWorker
+	tasks   chan func()
+	quit    chan struct{}
+	mu      sync.Mutex
+	refs    int
+}
+
+func newEngine() (*engine, error) {
+	context, err := NewUcpContext((&UcpParams{}).EnableStream().EnableWakeup())
+	if err != nil {
+		return nil, err
+	}
+
+	workerParams := (&UcpWorkerParams{}).SetThreadMode(UCS_THREAD_MODE_MULTI)
+	workerParams.WakeupTX().WakeupRX()
+	worker, err := context.NewWorker(workerParams)
+	if err != nil {
+		context.Close()
+		return nil, err
+	}
+
+	e := &engine{
+		context: context,
+		worker:  worker,
+		tasks:   make(chan func(), 64),
+		quit:    make(chan struct{}),
+		refs:    1,
+	}
+
+	go e.run()
+	return e, nil
+}
+
+func (e *engine) run() {
+	for {
+		select {
+		case task := <-e.tasks:
+			task()
+			continue
+		case <-e.quit:
+			e.drain()
+			e.worker.Close()
+			e.context.Close()
+			return
+		default:
+		}
+
+		if e.worker.Progress() != 0 {
+			continue
+		}
+
+		// Returns immediately if the worker was signaled by call()
+		e.worker.WaitEvents()
+	}
+}
+
+// Executes tasks, that were submitted before the engine was stopped.
+func (e *engine) drain() {
+	for {
+		select {
+		case task := <-e.tasks:
+			task()
+		default:
+			return
+		}
+	}
+}
+
+// Executes f on the progress goroutine and waits for its completion.
+func (e *engine) call(f func()) error {
+	done := make(chan struct{})
+
+	e.mu.Lock()
+	if e.refs == 0 {
+		e.mu.Unlock()
+		return net.ErrClosed
+	}
+	e.tasks <- func() { f(); close(done) }
+	e.worker.Signal()
+	e.mu.Unlock()
+
+	<-done
+	return nil
+}
+
+// Adds a user of the engine, every user has to call unref() when done.
+func (e *engine) ref() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.refs++
+}
+
+// Stops the progress goroutine and releases UCP resources when the last user
+// is done with the engine.
+func (e *engine) unref() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.refs--; e.refs == 0 {
+		close(e.quit)
+		e.worker.Signal()
+	}
+}
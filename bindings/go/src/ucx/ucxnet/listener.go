@@ -0,0 +1,165 @@
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package ucxnet
+
+import (
+	"net"
+	"sync"
+	. "ucx"
+)
+
+var _ net.Listener = (*Listener)(nil)
+
+// Maximal number of connection requests, that wait to be accepted. Requests
+// above this limit are rejected.
+const acceptBacklog = 128
+
+type connRequest struct {
+	request    *UcpConnectionRequest
+	clientAddr net.Addr
+}
+
+// Listener is a net.Listener, that accepts UCP client connections on a
+// socket address.
+type Listener struct {
+	engine         *engine
+	readBufferSize int
+	listener       *UcpListener
+	addr           net.Addr
+	requests       chan connRequest
+	closed         chan struct{}
+	closeOnce      sync.Once
+}
+
+// ListenConfig contains options for listening to an address.
+type ListenConfig struct {
+	// Size of the native buffer, that receives the stream data of every
+	// accepted connection, see Dialer.ReadBufferSize.
+	ReadBufferSize int
+}
+
+// Listen announces on the local network address. The network must be "tcp",
+// "tcp4" or "tcp6". Accepted connections share the UCP worker of the listener.
+func Listen(network, address string) (*Listener, error) {
+	var lc ListenConfig
+	return lc.Listen(network, address)
+}
+
+// Listen announces on the local network address with the options of the
+// config, see Listen().
+func (lc *ListenConfig) Listen(network, address string) (*Listener, error) {
+	tcpAddr, err := net.ResolveTCPAddr(network, address)
+	if err != nil {
+		return nil, err
+	}
+
+	listenerParams, err := (&UcpListenerParams{}).SetSocketAddress(tcpAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	e, err := newEngine()
+	if err != nil {
+		return nil, err
+	}
+
+	l := &Listener{
+		engine:         e,
+		readBufferSize: lc.ReadBufferSize,
+		requests:       make(chan connRequest, acceptBacklog),
+		closed:         make(chan struct{}),
+	}
+
+	// Called from the progress goroutine, so must not block
+	listenerParams.SetConnectionHandler(func(request *UcpConnectionRequest) {
+		var clientAddr net.Addr = &net.TCPAddr{}
+		if attrs, err := request.Query(UCP_CONN_REQUEST_ATTR_FIELD_CLIENT_ADDR); err == nil {
+			clientAddr = attrs.ClientAddress
+		}
+
+		select {
+		case l.requests <- connRequest{request, clientAddr}:
+		default:
+			request.Reject()
+		}
+	})
+
+	e.call(func() {
+		var attrs *UcpListenerAttributes
+		if l.listener, err = e.worker.NewListener(listenerParams); err != nil {
+			return
+		}
+
+		if attrs, err = l.listener.Query(UCP_LISTENER_ATTR_FIELD_SOCKADDR); err != nil {
+			l.listener.Close()
+			return
+		}
+		l.addr = attrs.Address
+	})
+
+	if err != nil {
+		e.unref()
+		return nil, err
+	}
+
+	return l, nil
+}
+
+// Accept waits for and returns the next connection to the listener.
+func (l *Listener) Accept() (net.Conn, error) {
+	select {
+	case request := <-l.requests:
+		var ep *UcpEp
+		var err error
+		if callErr := l.engine.call(func() {
+			ep, err = l.engine.worker.NewEndpointFromConnRequest(request.request, newEpParams())
+		}); callErr != nil {
+			return nil, callErr
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		l.engine.ref()
+		return newConn(l.engine, ep, l.addr, request.clientAddr, l.readBufferSize), nil
+	case <-l.closed:
+		return nil, net.ErrClosed
+	}
+}
+
+// Close stops listening. Already accepted connections are not closed.
+func (l *Listener) Close() error {
+	closed := false
+	l.closeOnce.Do(func() {
+		close(l.closed)
+		closed = true
+	})
+
+	if !closed {
+		return net.ErrClosed
+	}
+
+	l.engine.call(func() {
+		l.listener.Close()
+		for {
+			select {
+			case request := <-l.requests:
+				request.request.Reject()
+			default:
+				return
+			}
+		}
+	})
+
+	l.engine.unref()
+	return nil
+}
+
+// Addr returns the listener's network address.
+func (l *Listener) Addr() net.Addr {
+	return l.addr
+}
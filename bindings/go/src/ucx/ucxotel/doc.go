@@ -0,0 +1,11 @@
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+// Package ucxotel traces the UCX operations by the OpenTelemetry Go SDK, see
+// NewTracer(), and propagates the trace context to the peers in the Active
+// Message headers, so the traces span the UCX hops. The package depends on
+// go.opentelemetry.io/otel, so it's built only with the otel build tag, e.g.
+// "go build -tags otel", once the dependency is available in GOPATH.
+package ucxotel
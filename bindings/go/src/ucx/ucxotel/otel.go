@@ -0,0 +1,101 @@
+//go:build otel
+// +build otel
+
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package ucxotel
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	. "ucx"
+)
+
+// Name of the instrumentation scope of the tracer.
+const instrumentationName = "ucx"
+
+// Tracer emits the span of every traced operation, named after the operation,
+// e.g. "ucx.tag_send", with the ucx.tag, ucx.am_id, ucx.remote_addr and byte
+// count attributes. The sends, the RMA puts and the atomics are the producer
+// spans, the receives and the RMA gets are the consumer spans, and the
+// flushes are the internal spans. The failed operations record the UCX error.
+type Tracer struct {
+	tracer trace.Tracer
+}
+
+// Creates the tracer of the provider, e.g. otel.GetTracerProvider(), which is
+// installed on the worker by UcpWorkerParams.SetTracer().
+func NewTracer(provider trace.TracerProvider) *Tracer {
+	return &Tracer{tracer: provider.Tracer(instrumentationName)}
+}
+
+type span struct {
+	span trace.Span
+}
+
+func (t *Tracer) Start(event *UcpTraceEvent) UcpTraceSpan {
+	attrs := make([]attribute.KeyValue, 0, 4)
+	attrs = append(attrs, attribute.Int64("ucx.size", int64(event.Size)))
+	switch event.Op {
+	case UcpTraceTagSend:
+		attrs = append(attrs, attribute.Int64("ucx.tag", int64(event.Tag)))
+	case UcpTraceTagRecv:
+		attrs = append(attrs, attribute.Int64("ucx.tag", int64(event.Tag)),
+			attribute.Int64("ucx.tag_mask", int64(event.TagMask)))
+	case UcpTraceAmSend, UcpTraceAmRecv:
+		attrs = append(attrs, attribute.Int("ucx.am_id", int(event.AmId)))
+	case UcpTraceRmaPut, UcpTraceRmaGet, UcpTraceAtomic:
+		attrs = append(attrs, attribute.Int64("ucx.remote_addr", int64(event.RemoteAddr)))
+	}
+
+	kind := trace.SpanKindProducer
+	switch event.Op {
+	case UcpTraceTagRecv, UcpTraceAmRecv, UcpTraceStreamRecv, UcpTraceRmaGet:
+		kind = trace.SpanKindConsumer
+	case UcpTraceFlush:
+		kind = trace.SpanKindInternal
+	}
+
+	_, s := t.tracer.Start(event.Context, "ucx."+event.Op.String(), trace.WithSpanKind(kind),
+		trace.WithAttributes(attrs...))
+	return &span{span: s}
+}
+
+func (s *span) End(status UcsStatus, length uint64) {
+	s.span.SetAttributes(attribute.Int64("ucx.length", int64(length)))
+	if status != UCS_OK {
+		s.span.RecordError(NewUcxError(status))
+		s.span.SetStatus(codes.Error, status.String())
+	}
+	s.span.End()
+}
+
+// W3C trace context, which is carried in the headers.
+var propagator = propagation.TraceContext{}
+
+// Returns the header, that carries the span context of ctx to the peer, e.g.
+// as the prefix of the Active Message header. The header is empty, if ctx has
+// no valid span.
+func InjectHeader(ctx context.Context) []byte {
+	carrier := propagation.MapCarrier{}
+	propagator.Inject(ctx, carrier)
+	return []byte(carrier.Get("traceparent"))
+}
+
+// Returns the context with the remote span context of the header, that is
+// created by InjectHeader() on the peer, so the spans, that the handler starts
+// from the context, e.g. the operations with UcpRequestParams.SetTraceContext(),
+// continue the trace of the peer.
+func ExtractHeader(ctx context.Context, header []byte) context.Context {
+	if len(header) == 0 {
+		return ctx
+	}
+	return propagator.Extract(ctx, propagation.MapCarrier{"traceparent": string(header)})
+}
@@ -0,0 +1,584 @@
+//go:build go1.18
+// +build go1.18
+
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+// Package ucxrpc implements request-response calls over UCP Active Messages.
+// The handlers are registered by method IDs, and are executed by a pool of
+// goroutines, while the responses are correlated with the calls by the IDs,
+// that are carried in the message headers. The deadline of the call context
+// is passed to the handler context of the remote peer.
+//
+// The methods may accept several encodings, which are identified by the
+// content types, so the peers of the other UCX bindings call them with the
+// encodings, that they support. The message headers are little-endian:
+//
+//	request:  call ID (8), deadline in Unix nanoseconds or 0 (8), method ID (4),
+//	          content type of the request (1), of the response (1)
+//	response: call ID (8), status (4), content type of the response (1)
+//
+// The status of the response is 0 on success, and the response data is
+// encoded by the requested content type then. The other statuses are the
+// handler error with its message as the data, the unknown method, the
+// exceeded deadline, the full queue, and the unsupported content type.
+package ucxrpc
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"runtime"
+	"sync"
+	"time"
+	. "ucx"
+	"ucx/ucxcodec"
+	"unsafe"
+)
+
+var (
+	ErrClosed                 = errors.New("ucxrpc: node is closed")
+	ErrUnknownMethod          = errors.New("ucxrpc: unknown method")
+	ErrQueueFull              = errors.New("ucxrpc: too many requests in the queue of the peer")
+	ErrUnsupportedContentType = errors.New("ucxrpc: content type is not supported by the method")
+	errResponseLost           = errors.New("ucxrpc: failed to receive the response data")
+)
+
+// Error, that is returned by the handler of the remote peer.
+type RemoteError struct {
+	Message string
+}
+
+func (e *RemoteError) Error() string {
+	return "ucxrpc: remote error: " + e.Message
+}
+
+// Status of the response, that is carried in its header.
+const (
+	statusOK uint32 = iota
+	statusError
+	statusUnknownMethod
+	statusDeadlineExceeded
+	statusQueueFull
+	statusUnsupportedContentType
+)
+
+// Request header: call ID, deadline in Unix nanoseconds or 0, method ID,
+// content types of the request and of the response.
+const requestHeaderSize = 8 + 8 + 4 + 1 + 1
+
+// Response header: call ID, status, content type.
+const responseHeaderSize = 8 + 4 + 1
+
+// Encoding of the request or the response data, that is carried in the
+// message headers, so the peers agree on the codec. The values are the same
+// for all the UCX bindings.
+type ContentType uint8
+
+const (
+	// Codecs of Method.RequestCodec and Method.ResponseCodec, which are
+	// known only to the peers sharing the method definition
+	ContentTypeDefault ContentType = iota
+	// Data as is, see ucxcodec.RawCodec
+	ContentTypeRaw
+	ContentTypeJSON
+	ContentTypeProtobuf
+	ContentTypeMsgpack
+	ContentTypeGob
+)
+
+func (t ContentType) String() string {
+	switch t {
+	case ContentTypeDefault:
+		return "default"
+	case ContentTypeRaw:
+		return "raw"
+	case ContentTypeJSON:
+		return "json"
+	case ContentTypeProtobuf:
+		return "protobuf"
+	case ContentTypeMsgpack:
+		return "msgpack"
+	case ContentTypeGob:
+		return "gob"
+	}
+	return "unknown"
+}
+
+type Config struct {
+	// Active Message IDs of the requests and the responses, which must be
+	// the same on all the peers. 0xec00 and 0xec01 by default.
+	RequestAmId  uint
+	ResponseAmId uint
+
+	// Number of goroutines, that execute the handlers. runtime.NumCPU() by
+	// default.
+	Workers int
+
+	// Maximal number of the requests, that wait for a free goroutine. Further
+	// requests are rejected, so the calls return ErrQueueFull. 1024 by default.
+	QueueSize int
+}
+
+// Node serves the registered methods and calls the methods of the peers on the
+// endpoints of the worker, which is progressed by the loop. All the worker
+// operations are executed on the loop, so the worker thread mode can be any.
+// The routines of the node must not be called from the worker callbacks.
+type Node struct {
+	loop   *UcpProgressLoop
+	worker *UcpWorker
+	config Config
+
+	handlersMu sync.RWMutex
+	handlers   map[uint32]handler
+
+	// Accessed only by the loop
+	calls  map[uint64]*call
+	nextId uint64
+	closed bool
+
+	queue    chan *request
+	handling sync.WaitGroup
+	// Canceled on Close(), the parent of the handler contexts
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// Handler of the encoded request, that returns the response encoded by the
+// content type, or errUnsupportedContentType.
+type handler func(ctx context.Context, data []byte, requestType ContentType,
+	responseType ContentType) ([]byte, error)
+
+var errUnsupportedContentType = errors.New("unsupported content type")
+
+type request struct {
+	callId       uint64
+	method       uint32
+	deadline     int64
+	requestType  ContentType
+	responseType ContentType
+	data         []byte
+	replyEp      *UcpEp
+}
+
+type response struct {
+	status      uint32
+	contentType ContentType
+	data        []byte
+	err         error
+}
+
+type call struct {
+	done chan response
+}
+
+// Creates the node on the worker, which must be the one progressed by the loop,
+// and installs the Active Message handlers of the requests and the responses.
+func NewNode(loop *UcpProgressLoop, worker *UcpWorker, config Config) (*Node, error) {
+	if config.RequestAmId == config.ResponseAmId {
+		config.RequestAmId = 0xec00
+		config.ResponseAmId = 0xec01
+	}
+
+	if config.Workers <= 0 {
+		config.Workers = runtime.NumCPU()
+	}
+
+	if config.QueueSize <= 0 {
+		config.QueueSize = 1024
+	}
+
+	n := &Node{
+		loop:     loop,
+		worker:   worker,
+		config:   config,
+		handlers: make(map[uint32]handler),
+		calls:    make(map[uint64]*call),
+		queue:    make(chan *request, config.QueueSize),
+	}
+	n.ctx, n.cancel = context.WithCancel(context.Background())
+
+	var err error
+	if loopErr := loop.Execute(func() {
+		if err = worker.SetAmRecvHandlerWithMode(config.RequestAmId, UCP_AM_FLAG_WHOLE_MSG,
+			UcpAmDataModeCopy, n.onRequest); err != nil {
+			return
+		}
+
+		if err = worker.SetAmRecvHandlerWithMode(config.ResponseAmId, UCP_AM_FLAG_WHOLE_MSG,
+			UcpAmDataModeCopy, n.onResponse); err != nil {
+			worker.SetAmRecvHandler(config.RequestAmId, 0, nil)
+		}
+	}); loopErr != nil {
+		return nil, loopErr
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	for i := 0; i < config.Workers; i++ {
+		n.handling.Add(1)
+		go n.serve()
+	}
+	return n, nil
+}
+
+// Passes the data of the message to cb, receiving the rendezvous data first.
+// cb is invoked from the progress loop, with nil data if the receive failed.
+func receive(data *UcpAmData, cb func(bytes []byte)) {
+	if data.IsDataValid() {
+		cb(data.Bytes())
+		return
+	}
+
+	length := data.Length()
+	buffer := AllocateNativeMemory(length)
+	var request *UcpRequest
+	params := (&UcpRequestParams{}).SetCallback(func(_ *UcpRequest, status UcsStatus, _ uint64) {
+		if request != nil {
+			request.Close()
+		}
+
+		var bytes []byte
+		if status == UCS_OK {
+			bytes = GoBytes(buffer, length)
+		}
+		FreeNativeMemory(buffer)
+		cb(bytes)
+	})
+
+	if posted, _ := data.Receive(buffer, length, params); posted.GetStatus() == UCS_INPROGRESS {
+		request = posted
+	} else {
+		posted.Close()
+	}
+}
+
+func (n *Node) onRequest(header unsafe.Pointer, headerSize uint64, data *UcpAmData,
+	replyEp *UcpEp) UcsStatus {
+	if (headerSize != requestHeaderSize) || (replyEp == nil) {
+		return UCS_OK
+	}
+
+	h := AmHeader(header, headerSize)
+	r := &request{
+		callId:       binary.LittleEndian.Uint64(h[0:]),
+		deadline:     int64(binary.LittleEndian.Uint64(h[8:])),
+		method:       binary.LittleEndian.Uint32(h[16:]),
+		requestType:  ContentType(h[20]),
+		responseType: ContentType(h[21]),
+		replyEp:      replyEp,
+	}
+
+	receive(data, func(bytes []byte) {
+		if (bytes == nil) && (data.Length() != 0) {
+			return
+		}
+		r.data = bytes
+
+		if n.closed {
+			return
+		}
+
+		select {
+		case n.queue <- r:
+		default:
+			n.respond(r, statusQueueFull, nil)
+		}
+	})
+	return UCS_OK
+}
+
+func (n *Node) onResponse(header unsafe.Pointer, headerSize uint64, data *UcpAmData,
+	replyEp *UcpEp) UcsStatus {
+	if headerSize != responseHeaderSize {
+		return UCS_OK
+	}
+
+	h := AmHeader(header, headerSize)
+	callId := binary.LittleEndian.Uint64(h[0:])
+	status := binary.LittleEndian.Uint32(h[8:])
+	contentType := ContentType(h[12])
+
+	receive(data, func(bytes []byte) {
+		// The call, that was abandoned by its context, is already removed
+		if c, found := n.calls[callId]; found {
+			delete(n.calls, callId)
+			if (bytes == nil) && (data.Length() != 0) {
+				c.done <- response{err: errResponseLost}
+			} else {
+				c.done <- response{status: status, contentType: contentType, data: bytes}
+			}
+		}
+	})
+	return UCS_OK
+}
+
+// Sends the response to the request on the loop.
+func (n *Node) respond(r *request, status uint32, data []byte) {
+	header := make([]byte, responseHeaderSize)
+	binary.LittleEndian.PutUint64(header[0:], r.callId)
+	binary.LittleEndian.PutUint32(header[8:], status)
+	header[12] = byte(r.responseType)
+
+	// Failure of the requester endpoint drops the response
+	SubmitRequest(func(params *UcpRequestParams) (*UcpRequest, error) {
+		return r.replyEp.SendAmBytesNonBlocking(n.config.ResponseAmId, unsafe.Pointer(&header[0]),
+			responseHeaderSize, data, UCP_AM_SEND_FLAG_COPY_HEADER, params)
+	}, func(status UcsStatus) {})
+}
+
+// Executes the handlers of the queued requests, until the node is closed.
+func (n *Node) serve() {
+	defer n.handling.Done()
+
+	for r := range n.queue {
+		status, data := n.handle(r)
+		n.loop.Execute(func() {
+			if !n.closed {
+				n.respond(r, status, data)
+			}
+		})
+	}
+}
+
+func (n *Node) handle(r *request) (uint32, []byte) {
+	n.handlersMu.RLock()
+	h, found := n.handlers[r.method]
+	n.handlersMu.RUnlock()
+
+	if !found {
+		return statusUnknownMethod, nil
+	}
+
+	ctx := n.ctx
+	if r.deadline != 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, time.Unix(0, r.deadline))
+		defer cancel()
+	}
+
+	if ctx.Err() != nil {
+		return statusDeadlineExceeded, nil
+	}
+
+	data, err := h(ctx, r.data, r.requestType, r.responseType)
+	if err == errUnsupportedContentType {
+		return statusUnsupportedContentType, nil
+	} else if errors.Is(err, context.DeadlineExceeded) {
+		return statusDeadlineExceeded, nil
+	} else if err != nil {
+		return statusError, []byte(err.Error())
+	}
+	return statusOK, data
+}
+
+// Sends the request to the peer and waits for the response, or until the ctx
+// is done. The response, that arrives after ctx is done, is dropped.
+func (n *Node) call(ctx context.Context, ep *UcpEp, method uint32, contentType ContentType,
+	data []byte) ([]byte, error) {
+	header := make([]byte, requestHeaderSize)
+	if deadline, ok := ctx.Deadline(); ok {
+		binary.LittleEndian.PutUint64(header[8:], uint64(deadline.UnixNano()))
+	}
+	binary.LittleEndian.PutUint32(header[16:], method)
+	header[20] = byte(contentType)
+	header[21] = byte(contentType)
+
+	c := &call{done: make(chan response, 1)}
+	var callId uint64
+	if err := n.execute(func() error {
+		n.nextId++
+		callId = n.nextId
+		binary.LittleEndian.PutUint64(header[0:], callId)
+		n.calls[callId] = c
+
+		SubmitRequest(func(params *UcpRequestParams) (*UcpRequest, error) {
+			return ep.SendAmBytesNonBlocking(n.config.RequestAmId, unsafe.Pointer(&header[0]),
+				requestHeaderSize, data, UCP_AM_SEND_FLAG_COPY_HEADER|UCP_AM_SEND_FLAG_REPLY, params)
+		}, func(status UcsStatus) {
+			if _, found := n.calls[callId]; found && (status != UCS_OK) {
+				delete(n.calls, callId)
+				c.done <- response{err: NewUcxError(status)}
+			}
+		})
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	select {
+	case r := <-c.done:
+		if (r.err == nil) && (r.status == statusOK) && (r.contentType != contentType) {
+			return nil, ErrUnsupportedContentType
+		}
+		return r.result()
+	case <-ctx.Done():
+		n.execute(func() error {
+			delete(n.calls, callId)
+			return nil
+		})
+		return nil, ctx.Err()
+	}
+}
+
+func (r response) result() ([]byte, error) {
+	switch {
+	case r.err != nil:
+		return nil, r.err
+	case r.status == statusOK:
+		return r.data, nil
+	case r.status == statusUnknownMethod:
+		return nil, ErrUnknownMethod
+	case r.status == statusDeadlineExceeded:
+		return nil, context.DeadlineExceeded
+	case r.status == statusQueueFull:
+		return nil, ErrQueueFull
+	case r.status == statusUnsupportedContentType:
+		return nil, ErrUnsupportedContentType
+	}
+	return nil, &RemoteError{Message: string(r.data)}
+}
+
+// Executes f on the progress loop, unless the node is closed.
+func (n *Node) execute(f func() error) error {
+	var err error
+	if loopErr := n.loop.Execute(func() {
+		if n.closed {
+			err = ErrClosed
+			return
+		}
+		err = f()
+	}); loopErr != nil {
+		return loopErr
+	}
+	return err
+}
+
+// Removes the Active Message handlers, completes the calls in progress with
+// ErrClosed, and waits for the running handlers, which contexts are canceled.
+// The progress loop must still run.
+func (n *Node) Close() error {
+	err := n.execute(func() error {
+		n.closed = true
+		n.worker.SetAmRecvHandler(n.config.RequestAmId, 0, nil)
+		n.worker.SetAmRecvHandler(n.config.ResponseAmId, 0, nil)
+
+		for callId, c := range n.calls {
+			delete(n.calls, callId)
+			c.done <- response{err: ErrClosed}
+		}
+		close(n.queue)
+		return nil
+	})
+
+	if err == ErrClosed {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	n.cancel()
+	n.handling.Wait()
+	return nil
+}
+
+// Codecs of the request and the response of the method for a content type.
+type Codecs[Req, Resp any] struct {
+	RequestCodec  ucxcodec.Codec[Req]
+	ResponseCodec ucxcodec.Codec[Resp]
+}
+
+// Method describes the remote procedure by its ID and the codecs of its
+// request and response, so the same value is used to register the handler on
+// the serving peer and to call it from the others. RequestCodec and
+// ResponseCodec are the codecs of ContentType, and the handler accepts the
+// content types of Codecs as well. The response is encoded by the content
+// type, that the caller requested, so the call of the content type, that the
+// handler doesn't support, fails with ErrUnsupportedContentType.
+type Method[Req, Resp any] struct {
+	Id            uint32
+	RequestCodec  ucxcodec.Codec[Req]
+	ResponseCodec ucxcodec.Codec[Resp]
+	ContentType   ContentType
+	Codecs        map[ContentType]Codecs[Req, Resp]
+}
+
+func (m Method[Req, Resp]) codecs(contentType ContentType) (Codecs[Req, Resp], bool) {
+	if (contentType == m.ContentType) && (m.RequestCodec != nil) {
+		return Codecs[Req, Resp]{m.RequestCodec, m.ResponseCodec}, true
+	}
+
+	codecs, found := m.Codecs[contentType]
+	return codecs, found
+}
+
+// Registers the handler of the method on the node, replacing the previous one.
+// The handler is executed by the node goroutines, concurrently with the other
+// handlers. Its context is done once the deadline of the call passes, or the
+// node is closed. The error of the handler is returned to the caller as
+// RemoteError.
+func (m Method[Req, Resp]) Handle(n *Node, h func(ctx context.Context, req Req) (Resp, error)) {
+	n.handlersMu.Lock()
+	defer n.handlersMu.Unlock()
+
+	n.handlers[m.Id] = func(ctx context.Context, data []byte, requestType ContentType,
+		responseType ContentType) ([]byte, error) {
+		requestCodecs, found := m.codecs(requestType)
+		if !found {
+			return nil, errUnsupportedContentType
+		}
+
+		responseCodecs, found := m.codecs(responseType)
+		if !found {
+			return nil, errUnsupportedContentType
+		}
+
+		var req Req
+		if err := requestCodecs.RequestCodec.Unmarshal(data, &req); err != nil {
+			return nil, err
+		}
+
+		resp, err := h(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		return responseCodecs.ResponseCodec.Marshal(nil, resp)
+	}
+}
+
+// Calls the method of the peer on the endpoint, which must be created on the
+// worker of the node, and waits for the response until the ctx is done. The
+// deadline of ctx is passed to the handler, so the clocks of the peers are
+// expected to be synchronized.
+func (m Method[Req, Resp]) Call(ctx context.Context, n *Node, ep *UcpEp, req Req) (Resp, error) {
+	return m.CallAs(ctx, n, ep, req, m.ContentType)
+}
+
+// Calls the method with the request and the response encoded by the codecs of
+// the content type, see Method.Call().
+func (m Method[Req, Resp]) CallAs(ctx context.Context, n *Node, ep *UcpEp, req Req,
+	contentType ContentType) (Resp, error) {
+	var resp Resp
+
+	codecs, found := m.codecs(contentType)
+	if !found {
+		return resp, ErrUnsupportedContentType
+	}
+
+	data, err := codecs.RequestCodec.Marshal(nil, req)
+	if err != nil {
+		return resp, err
+	}
+
+	data, err = n.call(ctx, ep, m.Id, contentType, data)
+	if err != nil {
+		return resp, err
+	}
+
+	err = codecs.ResponseCodec.Unmarshal(data, &resp)
+	return resp, err
+}
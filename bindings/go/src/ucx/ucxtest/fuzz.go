@@ -0,0 +1,721 @@
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package ucxtest
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	. "ucx"
+	"unsafe"
+)
+
+const (
+	fuzzAmId = 7
+	// Number of the last calls, that the failure reports
+	fuzzHistorySize = 32
+	// Time of the completion of the operations, that are left by the calls
+	fuzzDrainTimeout = 30 * time.Second
+)
+
+type FuzzConfig struct {
+	// Configuration of the pair, that the calls are made on. The workers are
+	// created in the multi-threaded mode, unless Concurrency is 1.
+	Pair Config
+
+	// Seed of the sequence of the calls, a random one by default. The seed is
+	// logged by the test and reported by its failure, so the same sequence is
+	// replayed by passing it back. The completion order of the operations
+	// still depends on the transports.
+	Seed int64
+
+	// Number of the calls, 1000 by default.
+	Steps int
+
+	// Maximal size of the messages and of the memory, 256KB by default, so the
+	// rendezvous protocols are used too.
+	MaxSize uint64
+
+	// Number of the goroutines, that submit the sends concurrently, 4 by
+	// default.
+	Concurrency int
+
+	// Enables the leak check of the ucx package during the run, and fails the
+	// test, if any resource isn't closed once the pair is closed. The leak
+	// check must not be enabled by the caller meanwhile.
+	CheckLeaks bool
+}
+
+// Tag message between the peers, that is sent and received by the separate
+// calls, so the receive is posted before or after the send, and its buffer
+// may be shorter than the message.
+type fuzzMessage struct {
+	id       uint64
+	from     *Peer
+	to       *Peer
+	size     uint64
+	send     unsafe.Pointer
+	recv     unsafe.Pointer
+	recvSize uint64
+	sendReq  *UcpRequest
+	recvReq  *UcpRequest
+	// The side is posted, or its request is closed, so the operation isn't
+	// checked, while it still completes in the library
+	sendPosted   bool
+	recvPosted   bool
+	sendDetached bool
+	recvDetached bool
+	canceled     bool
+}
+
+type fuzzAm struct {
+	size     uint64
+	sendReq  *UcpRequest
+	received bool
+}
+
+// Receive of the rendezvous data of the Active Message.
+type fuzzAmRecv struct {
+	id      uint64
+	buffer  unsafe.Pointer
+	request *UcpRequest
+}
+
+type fuzzer struct {
+	tb       testing.TB
+	config   FuzzConfig
+	rng      *rand.Rand
+	pair     *Pair
+	history  []string
+	messages []*fuzzMessage
+	ams      []*fuzzAm
+	amRecvs  []*fuzzAmRecv
+	memories []*UcpMemory
+	eps      []*UcpEp
+	// Flushes and closures of the endpoints, that are waited for
+	requests []*UcpRequest
+	// Native buffers of the operations, which are freed once the workers are
+	// closed, since the closed requests may still use them
+	buffers []unsafe.Pointer
+	// Failures of the callbacks, that can't fail the test themselves
+	errors []string
+}
+
+// This routine drives the random sequence of the calls against the pair of
+// the loopback peers: the tag sends and receives of the random sizes, that are
+// posted in either order, with the buffers shorter than the messages, the
+// cancellations and the early closures of the requests, the Active Messages of
+// the eager and rendezvous protocols, the concurrent submits, the mapping and
+// unmapping of the memory, and the creation and closure of the endpoints. Once
+// the calls end, the operations are completed, and their data and statuses
+// are checked. Fails the test with the seed and the last calls, so the failure
+// is reproduced by FuzzConfig.Seed. The routine must be called from the
+// goroutine of the test.
+func Fuzz(tb testing.TB, config FuzzConfig) {
+	tb.Helper()
+
+	if config.Seed == 0 {
+		config.Seed = time.Now().UnixNano()
+	}
+
+	if config.Steps == 0 {
+		config.Steps = 1000
+	}
+
+	if config.MaxSize == 0 {
+		config.MaxSize = 256 * 1024
+	}
+
+	if config.Concurrency == 0 {
+		config.Concurrency = 4
+	}
+
+	if (config.Concurrency > 1) && (config.Pair.WorkerParams == nil) {
+		config.Pair.WorkerParams = (&UcpWorkerParams{}).SetThreadMode(UCS_THREAD_MODE_MULTI)
+	}
+	tb.Logf("ucxtest: fuzz seed %v", config.Seed)
+
+	f := &fuzzer{
+		tb:     tb,
+		config: config,
+		rng:    rand.New(rand.NewSource(config.Seed)),
+	}
+	defer f.freeBuffers()
+
+	if config.CheckLeaks {
+		SetLeakCheck(true)
+		defer SetLeakCheck(false)
+	}
+
+	f.pair = NewPair(tb, config.Pair)
+	defer f.pair.Close()
+
+	for _, peer := range []*Peer{f.pair.A, f.pair.B} {
+		if err := peer.Worker.SetAmRecvHandler(fuzzAmId, UCP_AM_FLAG_WHOLE_MSG, f.handleAm); err != nil {
+			f.fail("failed to set Active Message handler: %v", err)
+		}
+	}
+
+	for step := 0; step < config.Steps; step++ {
+		f.step()
+		f.checkErrors()
+	}
+
+	f.drain()
+	f.check()
+	f.close()
+
+	if config.CheckLeaks {
+		f.pair.Close()
+		if err := CheckLeaks(); err != nil {
+			f.fail("%v", err)
+		}
+	}
+}
+
+func (f *fuzzer) log(format string, args ...interface{}) {
+	if len(f.history) == fuzzHistorySize {
+		f.history = f.history[1:]
+	}
+	f.history = append(f.history, fmt.Sprintf(format, args...))
+}
+
+func (f *fuzzer) fail(format string, args ...interface{}) {
+	f.tb.Helper()
+	f.tb.Fatalf("ucxtest: %v\nreplay by seed %v, the last calls:\n%v", fmt.Sprintf(format, args...),
+		f.config.Seed, strings.Join(f.history, "\n"))
+}
+
+func (f *fuzzer) checkErrors() {
+	if len(f.errors) > 0 {
+		f.fail("%v", strings.Join(f.errors, "\n"))
+	}
+}
+
+func (f *fuzzer) alloc(size uint64) unsafe.Pointer {
+	// malloc(0) may return nil
+	buffer := AllocateNativeMemory(size + 1)
+	f.buffers = append(f.buffers, buffer)
+	return buffer
+}
+
+func (f *fuzzer) freeBuffers() {
+	for _, buffer := range f.buffers {
+		FreeNativeMemory(buffer)
+	}
+	f.buffers = nil
+}
+
+func view(buffer unsafe.Pointer, size uint64) []byte {
+	return (*[1 << 40]byte)(buffer)[:size:size]
+}
+
+// The content of the message of the id, so the receiver checks it without a
+// copy of the sent data.
+func pattern(id uint64, size uint64) []byte {
+	data := make([]byte, size)
+	for i := range data {
+		data[i] = byte(id*31 + uint64(i))
+	}
+	return data
+}
+
+// Sizes of the short, the medium and the rendezvous messages, including the
+// empty ones.
+func (f *fuzzer) size() uint64 {
+	switch f.rng.Intn(4) {
+	case 0:
+		return uint64(f.rng.Intn(65))
+	case 1:
+		return uint64(f.rng.Intn(8192))
+	}
+	return uint64(f.rng.Int63n(int64(f.config.MaxSize) + 1))
+}
+
+// Returns the random direction of the pair.
+func (f *fuzzer) peers() (*Peer, *Peer) {
+	if f.rng.Intn(2) == 0 {
+		return f.pair.A, f.pair.B
+	}
+	return f.pair.B, f.pair.A
+}
+
+func (f *fuzzer) step() {
+	switch n := f.rng.Intn(100); {
+	case n < 25:
+		m := f.newMessage()
+		if f.rng.Intn(2) == 0 {
+			f.postSend(m)
+		} else {
+			f.postRecv(m)
+		}
+	case n < 40:
+		f.postOther()
+	case n < 48:
+		f.cancel()
+	case n < 55:
+		f.detach()
+	case n < 68:
+		count := f.rng.Intn(16) + 1
+		f.log("progress %v", count)
+		for i := 0; i < count; i++ {
+			f.pair.Progress()
+		}
+	case n < 80:
+		f.sendAm()
+	case n < 86:
+		f.memory()
+	case n < 92:
+		f.endpoint()
+	default:
+		f.burst()
+	}
+}
+
+func (f *fuzzer) newMessage() *fuzzMessage {
+	from, to := f.peers()
+	m := &fuzzMessage{
+		id:   uint64(len(f.messages)),
+		from: from,
+		to:   to,
+		size: f.size(),
+	}
+
+	m.recvSize = m.size + uint64(f.rng.Intn(64))
+	if (m.size > 0) && (f.rng.Intn(5) == 0) {
+		m.recvSize = uint64(f.rng.Int63n(int64(m.size)))
+	}
+
+	m.send = f.alloc(m.size)
+	copy(view(m.send, m.size), pattern(m.id, m.size))
+	m.recv = f.alloc(m.recvSize)
+	f.messages = append(f.messages, m)
+	return m
+}
+
+func (f *fuzzer) postSend(m *fuzzMessage) {
+	f.log("send %v: %v bytes", m.id, m.size)
+	request, err := m.from.Ep.SendTagNonBlocking(m.id, m.send, m.size, nil)
+	if err != nil {
+		f.fail("send of message %v failed: %v", m.id, err)
+	}
+	m.sendReq = request
+	m.sendPosted = true
+}
+
+func (f *fuzzer) postRecv(m *fuzzMessage) {
+	f.log("receive %v: %v bytes to %v bytes", m.id, m.size, m.recvSize)
+	request, err := m.to.Worker.RecvTagNonBlocking(m.recv, m.recvSize, m.id, ^uint64(0), nil)
+	if err != nil {
+		f.fail("receive of message %v failed: %v", m.id, err)
+	}
+	m.recvReq = request
+	m.recvPosted = true
+}
+
+// Picks the target of the call by the sequence only, so the calls of the seed
+// don't depend on the completion order.
+func (f *fuzzer) pickMessage() *fuzzMessage {
+	if len(f.messages) == 0 {
+		return nil
+	}
+	return f.messages[f.rng.Intn(len(f.messages))]
+}
+
+func (f *fuzzer) postOther() {
+	m := f.pickMessage()
+	switch {
+	case m == nil:
+	case !m.sendPosted:
+		f.postSend(m)
+	case !m.recvPosted:
+		f.postRecv(m)
+	}
+}
+
+func (f *fuzzer) cancel() {
+	m := f.pickMessage()
+	if (m == nil) || (m.recvReq == nil) || m.canceled {
+		return
+	}
+
+	f.log("cancel %v", m.id)
+	m.recvReq.Cancel()
+	m.canceled = true
+}
+
+// Closes the request before its completion, so the library completes it
+// without the binding. The canceled receives aren't detached, since their
+// messages are received again.
+func (f *fuzzer) detach() {
+	m := f.pickMessage()
+	if m == nil {
+		return
+	}
+
+	if f.rng.Intn(2) == 0 {
+		if m.sendReq != nil {
+			f.log("close send %v", m.id)
+			m.sendReq.Close()
+			m.sendReq = nil
+			m.sendDetached = true
+		}
+	} else if (m.recvReq != nil) && !m.canceled {
+		f.log("close receive %v", m.id)
+		m.recvReq.Close()
+		m.recvReq = nil
+		m.recvDetached = true
+	}
+}
+
+// Submits the sends of the new messages by the goroutines at once.
+func (f *fuzzer) burst() {
+	if f.config.Concurrency < 2 {
+		return
+	}
+
+	messages := make([]*fuzzMessage, f.config.Concurrency)
+	for i := range messages {
+		messages[i] = f.newMessage()
+	}
+	f.log("concurrent sends %v-%v", messages[0].id, messages[len(messages)-1].id)
+
+	errs := make([]error, len(messages))
+	var wg sync.WaitGroup
+	for i, m := range messages {
+		wg.Add(1)
+		go func(i int, m *fuzzMessage) {
+			defer wg.Done()
+			m.sendReq, errs[i] = m.from.Ep.SendTagNonBlocking(m.id, m.send, m.size, nil)
+		}(i, m)
+	}
+	wg.Wait()
+
+	for i, m := range messages {
+		if errs[i] != nil {
+			f.fail("concurrent send of message %v failed: %v", m.id, errs[i])
+		}
+		m.sendPosted = true
+	}
+}
+
+func (f *fuzzer) sendAm() {
+	from, _ := f.peers()
+	id := uint64(len(f.ams))
+	am := &fuzzAm{size: f.size()}
+	f.ams = append(f.ams, am)
+
+	header := f.alloc(8)
+	binary.LittleEndian.PutUint64(view(header, 8), id)
+	data := f.alloc(am.size)
+	copy(view(data, am.size), pattern(id, am.size))
+
+	var flags UcpAmSendFlags
+	if f.rng.Intn(2) == 0 {
+		flags |= UCP_AM_SEND_FLAG_REPLY
+	}
+
+	if f.rng.Intn(2) == 0 {
+		flags |= UCP_AM_SEND_FLAG_COPY_HEADER
+	}
+
+	switch f.rng.Intn(4) {
+	case 0:
+		flags |= UCP_AM_SEND_FLAG_EAGER
+	case 1:
+		flags |= UCP_AM_SEND_FLAG_RNDV
+	}
+
+	f.log("active message %v: %v bytes, flags %v", id, am.size, flags)
+	request, err := from.Ep.SendAmNonBlocking(fuzzAmId, header, 8, data, am.size, flags, nil)
+	if err != nil {
+		f.fail("send of active message %v failed: %v", id, err)
+	}
+	am.sendReq = request
+}
+
+// The handler only records the failures, since it's invoked by the progress
+// of the worker from the native code.
+func (f *fuzzer) handleAm(header unsafe.Pointer, headerSize uint64, data *UcpAmData, replyEp *UcpEp) UcsStatus {
+	if headerSize != 8 {
+		f.errors = append(f.errors, fmt.Sprintf("active message header of %v bytes", headerSize))
+		return UCS_OK
+	}
+
+	id := binary.LittleEndian.Uint64(AmHeader(header, headerSize))
+	if id >= uint64(len(f.ams)) {
+		f.errors = append(f.errors, fmt.Sprintf("unknown active message %v", id))
+		return UCS_OK
+	}
+
+	am := f.ams[id]
+	if data.Length() != am.size {
+		f.errors = append(f.errors, fmt.Sprintf("active message %v of %v bytes instead of %v", id,
+			data.Length(), am.size))
+		return UCS_OK
+	}
+
+	if !data.IsDataValid() {
+		buffer := f.alloc(am.size)
+		request, err := data.Receive(buffer, am.size, nil)
+		if err != nil {
+			f.errors = append(f.errors, fmt.Sprintf("receive of active message %v failed: %v", id, err))
+			return UCS_OK
+		}
+		f.amRecvs = append(f.amRecvs, &fuzzAmRecv{id: id, buffer: buffer, request: request})
+		return UCS_OK
+	}
+
+	if am.size > 0 {
+		address, _ := data.DataPointer()
+		if !bytes.Equal(view(address, am.size), pattern(id, am.size)) {
+			f.errors = append(f.errors, fmt.Sprintf("data of active message %v is corrupted", id))
+		}
+	}
+	am.received = true
+	return UCS_OK
+}
+
+func (f *fuzzer) memory() {
+	from, _ := f.peers()
+	if (len(f.memories) == 0) || (f.rng.Intn(2) == 0) {
+		size := f.size() + 1
+		f.log("map %v bytes", size)
+		memory, _, err := from.Context.AllocAndMap(size, nil)
+		if err != nil {
+			f.fail("mapping of %v bytes failed: %v", size, err)
+		}
+
+		if f.rng.Intn(2) == 0 {
+			if _, err := memory.RkeyPack(); err != nil {
+				f.fail("packing of remote key failed: %v", err)
+			}
+		}
+		f.memories = append(f.memories, memory)
+		return
+	}
+
+	i := f.rng.Intn(len(f.memories))
+	if f.memories[i] != nil {
+		f.log("unmap %v", i)
+		f.memories[i].Close()
+		f.memories[i] = nil
+	}
+}
+
+func (f *fuzzer) endpoint() {
+	from, to := f.peers()
+	if (len(f.eps) == 0) || (f.rng.Intn(2) == 0) {
+		address, err := to.Worker.GetAddress()
+		if err != nil {
+			f.fail("failed to get worker address: %v", err)
+		}
+		defer address.Close()
+
+		f.log("create endpoint %v", len(f.eps))
+		ep, err := from.Worker.NewEndpoint((&UcpEpParams{}).SetUcpAddress(address).SetPeerErrorHandling())
+		if err != nil {
+			f.fail("failed to create endpoint: %v", err)
+		}
+		f.eps = append(f.eps, ep)
+
+		if f.rng.Intn(2) == 0 {
+			request, err := ep.FlushNonBlocking(nil)
+			if err != nil {
+				f.fail("flush of endpoint failed: %v", err)
+			}
+			f.requests = append(f.requests, request)
+		}
+		return
+	}
+
+	i := f.rng.Intn(len(f.eps))
+	if f.eps[i] == nil {
+		return
+	}
+
+	var request *UcpRequest
+	var err error
+	if f.rng.Intn(2) == 0 {
+		f.log("close endpoint %v by flush", i)
+		request, err = f.eps[i].CloseNonBlockingFlush(nil)
+	} else {
+		f.log("close endpoint %v by force", i)
+		request, err = f.eps[i].CloseNonBlockingForce(nil)
+	}
+	f.eps[i] = nil
+
+	if err != nil {
+		f.fail("closure of endpoint failed: %v", err)
+	}
+
+	// The closure, that isn't waited for, completes in the library
+	if f.rng.Intn(2) == 0 {
+		request.Close()
+	} else {
+		f.requests = append(f.requests, request)
+	}
+}
+
+func isPending(request *UcpRequest) bool {
+	return (request != nil) && (request.GetStatus() == UCS_INPROGRESS)
+}
+
+// Posts the sides of the messages, that aren't posted yet, receives the
+// canceled messages again, and progresses the pair, until all the operations
+// are completed.
+func (f *fuzzer) drain() {
+	f.log("drain")
+	for i, ep := range f.eps {
+		if ep != nil {
+			request, err := ep.CloseNonBlockingForce(nil)
+			if err != nil {
+				f.fail("closure of endpoint failed: %v", err)
+			}
+			f.requests = append(f.requests, request)
+			f.eps[i] = nil
+		}
+	}
+
+	for _, m := range f.messages {
+		if !m.sendPosted {
+			f.postSend(m)
+		}
+
+		if !m.recvPosted {
+			f.postRecv(m)
+		}
+	}
+
+	deadline := time.Now().Add(fuzzDrainTimeout)
+	for {
+		f.checkErrors()
+
+		pending := false
+		for _, m := range f.messages {
+			if m.canceled && (m.recvReq.GetStatus() == UCS_ERR_CANCELED) {
+				// The message of the canceled receive is still unexpected
+				m.recvReq.Close()
+				m.canceled = false
+				m.recvSize = m.size
+				m.recv = f.alloc(m.size)
+				f.postRecv(m)
+			}
+			pending = pending || isPending(m.sendReq) || isPending(m.recvReq)
+		}
+
+		for _, am := range f.ams {
+			pending = pending || isPending(am.sendReq) || !am.received
+		}
+
+		for _, amRecv := range f.amRecvs {
+			pending = pending || isPending(amRecv.request)
+		}
+
+		for _, request := range f.requests {
+			pending = pending || isPending(request)
+		}
+
+		for _, amRecv := range f.amRecvs {
+			if !isPending(amRecv.request) && !f.ams[amRecv.id].received {
+				f.checkAmRecv(amRecv)
+			}
+		}
+
+		if !pending {
+			return
+		}
+
+		if time.Now().After(deadline) {
+			f.fail("operations are not completed in %v", fuzzDrainTimeout)
+		}
+		f.pair.Progress()
+	}
+}
+
+func (f *fuzzer) checkAmRecv(amRecv *fuzzAmRecv) {
+	am := f.ams[amRecv.id]
+	if status := amRecv.request.GetStatus(); status != UCS_OK {
+		f.fail("receive of active message %v completed with %v", amRecv.id, status)
+	}
+
+	if !bytes.Equal(view(amRecv.buffer, am.size), pattern(amRecv.id, am.size)) {
+		f.fail("data of active message %v is corrupted", amRecv.id)
+	}
+	am.received = true
+}
+
+func (f *fuzzer) check() {
+	for _, m := range f.messages {
+		truncated := m.recvSize < m.size
+		if m.recvReq != nil {
+			switch status := m.recvReq.GetStatus(); {
+			case (status == UCS_OK) && truncated:
+				f.fail("message %v of %v bytes isn't truncated by %v bytes buffer", m.id, m.size,
+					m.recvSize)
+			case status == UCS_OK:
+				if !bytes.Equal(view(m.recv, m.size), pattern(m.id, m.size)) {
+					f.fail("data of message %v is corrupted", m.id)
+				}
+			case (status == UCS_ERR_MESSAGE_TRUNCATED) && truncated:
+			default:
+				f.fail("receive of message %v completed with %v", m.id, status)
+			}
+		}
+
+		if m.sendReq != nil {
+			status := m.sendReq.GetStatus()
+			if (status != UCS_OK) && !((status == UCS_ERR_MESSAGE_TRUNCATED) && truncated) {
+				f.fail("send of message %v completed with %v", m.id, status)
+			}
+		}
+	}
+
+	for _, am := range f.ams {
+		if status := am.sendReq.GetStatus(); status != UCS_OK {
+			f.fail("send of active message completed with %v", status)
+		}
+	}
+}
+
+func (f *fuzzer) close() {
+	for _, m := range f.messages {
+		if m.sendReq != nil {
+			m.sendReq.Close()
+		}
+
+		if m.recvReq != nil {
+			m.recvReq.Close()
+		}
+	}
+
+	for _, am := range f.ams {
+		am.sendReq.Close()
+	}
+
+	for _, amRecv := range f.amRecvs {
+		amRecv.request.Close()
+	}
+
+	for _, request := range f.requests {
+		request.Close()
+	}
+
+	for _, memory := range f.memories {
+		if memory != nil {
+			memory.Close()
+		}
+	}
+
+	for _, peer := range []*Peer{f.pair.A, f.pair.B} {
+		peer.Worker.SetAmRecvHandler(fuzzAmId, 0, nil)
+	}
+}
@@ -0,0 +1,227 @@
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+// Package ucxtest helps to test the fault tolerance of the applications
+// without a real fabric: it connects a pair of peers over the loopback in the
+// same process, and injects the failure of either of them, so the survivor
+// detects it as if the remote process died. Fuzz drives the random sequences
+// of the calls over the pair, reproduced by their seed.
+package ucxtest
+
+import (
+	"testing"
+	"time"
+	. "ucx"
+)
+
+type Config struct {
+	// Parameters of the contexts of both peers. Tag matching and Active
+	// Messages are requested by default.
+	Params *UcpParams
+
+	// Parameters of the workers of both peers, the default ones by default,
+	// e.g. the multi-threaded mode for the concurrent submits.
+	WorkerParams *UcpWorkerParams
+
+	// Transports of the peers, "tcp" by default. The failure of the peer in
+	// the same process is detected only by the transports, that lose the
+	// connection with it, e.g. shared memory ones don't.
+	Transports string
+
+	// Interval of the keepalive checks of the endpoints, so the failure is
+	// detected even without the traffic. 100ms by default.
+	KeepaliveInterval time.Duration
+}
+
+// Side of the pair, the fields are valid until the peer is killed.
+type Peer struct {
+	Context *UcpContext
+	Worker  *UcpWorker
+	// Endpoint to the other peer of the pair, with peer error handling
+	Ep     *UcpEp
+	errors chan UcsStatus
+	killed bool
+}
+
+// Pair of the peers connected to each other. All the routines must be called
+// from the goroutine of the test, which progresses both workers.
+type Pair struct {
+	tb testing.TB
+	A  *Peer
+	B  *Peer
+}
+
+// Creates the pair of connected peers, that is closed by the cleanup of the
+// test. Fails the test, if the peers can't be created or connected.
+func NewPair(tb testing.TB, config Config) *Pair {
+	tb.Helper()
+
+	if config.Params == nil {
+		config.Params = (&UcpParams{}).EnableTag().EnableAM()
+	}
+
+	if config.WorkerParams == nil {
+		config.WorkerParams = &UcpWorkerParams{}
+	}
+
+	if config.Transports == "" {
+		config.Transports = "tcp"
+	}
+
+	if config.KeepaliveInterval == 0 {
+		config.KeepaliveInterval = 100 * time.Millisecond
+	}
+
+	p := &Pair{tb: tb}
+	tb.Cleanup(p.Close)
+
+	p.A = p.newPeer(config)
+	p.B = p.newPeer(config)
+	p.A.Ep = p.connect(p.A, p.B)
+	p.B.Ep = p.connect(p.B, p.A)
+
+	// Completes the wire-up, so the endpoints are connected before the failure
+	for _, peer := range []*Peer{p.A, p.B} {
+		request, err := peer.Ep.FlushNonBlocking(nil)
+		if err != nil {
+			tb.Fatalf("ucxtest: failed to flush endpoint: %v", err)
+		}
+		p.wait(request)
+	}
+	return p
+}
+
+func (p *Pair) newPeer(config Config) *Peer {
+	p.tb.Helper()
+
+	ucpConfig, err := NewUcpConfig("", "")
+	if err != nil {
+		p.tb.Fatalf("ucxtest: failed to read config: %v", err)
+	}
+	defer ucpConfig.Close()
+
+	for name, value := range map[string]string{
+		"TLS":                config.Transports,
+		"KEEPALIVE_INTERVAL": config.KeepaliveInterval.String(),
+	} {
+		if err := ucpConfig.Modify(name, value); err != nil {
+			p.tb.Fatalf("ucxtest: failed to set %v=%v: %v", name, value, err)
+		}
+	}
+
+	params := *config.Params
+	context, err := NewUcpContext(params.SetConfig(ucpConfig))
+	if err != nil {
+		p.tb.Fatalf("ucxtest: failed to create context: %v", err)
+	}
+
+	worker, err := context.NewWorker(config.WorkerParams)
+	if err != nil {
+		context.Close()
+		p.tb.Fatalf("ucxtest: failed to create worker: %v", err)
+	}
+
+	return &Peer{
+		Context: context,
+		Worker:  worker,
+		errors:  make(chan UcsStatus, 1),
+	}
+}
+
+func (p *Pair) connect(from *Peer, to *Peer) *UcpEp {
+	p.tb.Helper()
+
+	address, err := to.Worker.GetAddress()
+	if err != nil {
+		p.tb.Fatalf("ucxtest: failed to get worker address: %v", err)
+	}
+	defer address.Close()
+
+	ep, err := from.Worker.NewEndpoint((&UcpEpParams{}).SetUcpAddress(address).
+		SetPeerErrorHandling().SetErrorHandler(func(ep *UcpEp, status UcsStatus) {
+		select {
+		case from.errors <- status:
+		default:
+		}
+	}))
+	if err != nil {
+		p.tb.Fatalf("ucxtest: failed to create endpoint: %v", err)
+	}
+	return ep
+}
+
+// Progresses the workers of the peers, that are not killed.
+func (p *Pair) Progress() {
+	for _, peer := range []*Peer{p.A, p.B} {
+		if (peer != nil) && !peer.killed {
+			peer.Worker.Progress()
+		}
+	}
+}
+
+func (p *Pair) wait(request *UcpRequest) {
+	for request.GetStatus() == UCS_INPROGRESS {
+		p.Progress()
+	}
+	request.Close()
+}
+
+// Destroys the worker and the context of the peer without closing its
+// endpoint, as if the process of the peer died.
+func (p *Pair) Kill(peer *Peer) {
+	if peer.killed {
+		return
+	}
+
+	peer.killed = true
+	peer.Worker.Close()
+	peer.Context.Close()
+}
+
+// Returns the channel, that receives the status of the endpoint failure.
+func (peer *Peer) Errors() <-chan UcsStatus {
+	return peer.errors
+}
+
+// Progresses the pair until the error handler of the peer endpoint is
+// invoked, and returns its status. Fails the test on the timeout.
+func (p *Pair) WaitPeerError(peer *Peer, timeout time.Duration) UcsStatus {
+	p.tb.Helper()
+
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		select {
+		case status := <-peer.errors:
+			return status
+		default:
+		}
+		p.Progress()
+	}
+
+	p.tb.Fatalf("ucxtest: endpoint error is not reported in %v", timeout)
+	return UCS_OK
+}
+
+// Closes the endpoints, the workers and the contexts of the peers, that are
+// not killed.
+func (p *Pair) Close() {
+	for _, peer := range []*Peer{p.A, p.B} {
+		if (peer == nil) || (peer.Ep == nil) || peer.killed {
+			continue
+		}
+
+		// Forced closure doesn't wait for the killed peer
+		if request, _ := peer.Ep.CloseNonBlockingForce(nil); request != nil {
+			p.wait(request)
+		}
+		peer.Ep = nil
+	}
+
+	for _, peer := range []*Peer{p.A, p.B} {
+		if peer != nil {
+			p.Kill(peer)
+		}
+	}
+}
@@ -0,0 +1,1675 @@
+//go:build !linux || !cgo
+// +build !linux !cgo
+
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package ucx
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"os"
+	"sync"
+	"time"
+	"unsafe"
+)
+
+// UCX runs only on Linux, and the bindings call it through cgo. On the other
+// platforms, e.g. Windows and macOS, or with CGO_ENABLED=0, the package is
+// built of these stubs instead, so the projects, that are built there too,
+// compile with the same API. There is no emulation: the stubs fail with the
+// unsupported platform error, which matches ErrUnsupported, and return the
+// zero values otherwise. The API of the stubs is checked against the cgo files
+// by TestUnsupportedPlatformApi.
+var errUnsupportedPlatform = &UcxError{
+	msg:    "Unsupported platform, UCX requires Linux and cgo",
+	status: UCS_ERR_UNSUPPORTED,
+}
+
+func (w *UcpWorker) Cpus() []int {
+	return nil
+}
+
+func LockOSThreadToCpus(cpus []int) error {
+	return errUnsupportedPlatform
+}
+
+type UcpAmDataMode int
+
+const (
+	UcpAmDataModeDescriptor UcpAmDataMode = 0
+	UcpAmDataModePersist    UcpAmDataMode = 1
+	UcpAmDataModeCopy       UcpAmDataMode = 2
+)
+
+type UcpAmHeader []byte
+
+func AmHeader(header unsafe.Pointer, headerSize uint64) UcpAmHeader {
+	return nil
+}
+
+func (h UcpAmHeader) Clone() []byte {
+	return nil
+}
+
+type UcpAmData struct{}
+
+func (d *UcpAmData) HandlerArg() interface{} {
+	return nil
+}
+
+func (d *UcpAmData) IsDataValid() bool {
+	return false
+}
+
+func (d *UcpAmData) CanPersist() bool {
+	return false
+}
+
+func (d *UcpAmData) DataPointer() (unsafe.Pointer, error) {
+	return nil, errUnsupportedPlatform
+}
+
+func (d *UcpAmData) Length() uint64 {
+	return 0
+}
+
+func (d *UcpAmData) Bytes() []byte {
+	return nil
+}
+
+func (d *UcpAmData) Receive(recvBuffer unsafe.Pointer, size uint64, params *UcpRequestParams) (*UcpRequest, error) {
+	return nil, errUnsupportedPlatform
+}
+
+func (d *UcpAmData) ReceiveBytes(data []byte, params *UcpRequestParams) (*UcpRequest, error) {
+	return nil, errUnsupportedPlatform
+}
+
+func (d *UcpAmData) ReceiveToPool(pool UcpBufferPool, params *UcpRequestParams) (unsafe.Pointer, *UcpRequest, error) {
+	return nil, nil, errUnsupportedPlatform
+}
+
+func (d *UcpAmData) Release() {}
+
+func (d *UcpAmData) Close() {}
+
+type UcpCallback interface{}
+
+type UcpSendCallback = func(request *UcpRequest, status UcsStatus)
+
+type UcpTagRecvCallback = func(request *UcpRequest, status UcsStatus, tagInfo *UcpTagRecvInfo)
+
+type UcpAmDataRecvCallback = func(request *UcpRequest, status UcsStatus, length uint64)
+
+type UcpStreamRecvCallback = func(request *UcpRequest, status UcsStatus, length uint64)
+
+type UcpAmRecvCallback = func(header unsafe.Pointer, headerSize uint64, data *UcpAmData, replyEp *UcpEp) UcsStatus
+
+type UcpListenerConnectionHandler = func(connRequest *UcpConnectionRequest)
+
+type UcpConfig struct{}
+
+func NewUcpConfig(envPrefix string, filename string) (*UcpConfig, error) {
+	return nil, errUnsupportedPlatform
+}
+
+func (c *UcpConfig) Modify(name string, value string) error {
+	return errUnsupportedPlatform
+}
+
+func (c *UcpConfig) SetNetDevices(devices ...string) error {
+	return errUnsupportedPlatform
+}
+
+func (c *UcpConfig) SetMaxRails(rails int) error {
+	return errUnsupportedPlatform
+}
+
+const (
+	UCS_MEMUNITS_INF  uint64 = 0xffffffffffffffff
+	UCS_MEMUNITS_AUTO uint64 = 0xfffffffffffffffe
+)
+
+type UcpRndvScheme string
+
+const (
+	UCP_RNDV_SCHEME_AUTO         UcpRndvScheme = "auto"
+	UCP_RNDV_SCHEME_GET_ZCOPY    UcpRndvScheme = "get_zcopy"
+	UCP_RNDV_SCHEME_PUT_ZCOPY    UcpRndvScheme = "put_zcopy"
+	UCP_RNDV_SCHEME_GET_PIPELINE UcpRndvScheme = "get_ppln"
+	UCP_RNDV_SCHEME_PUT_PIPELINE UcpRndvScheme = "put_ppln"
+	UCP_RNDV_SCHEME_RKEY_PTR     UcpRndvScheme = "rkey_ptr"
+	UCP_RNDV_SCHEME_AM           UcpRndvScheme = "am"
+)
+
+func (c *UcpConfig) SetRndvThreshold(size uint64) error {
+	return errUnsupportedPlatform
+}
+
+func (c *UcpConfig) SetRndvThresholds(intra uint64, inter uint64) error {
+	return errUnsupportedPlatform
+}
+
+func (c *UcpConfig) SetZcopyThreshold(size uint64) error {
+	return errUnsupportedPlatform
+}
+
+func (c *UcpConfig) SetBcopyThreshold(size uint64) error {
+	return errUnsupportedPlatform
+}
+
+func (c *UcpConfig) SetRndvScheme(scheme UcpRndvScheme) error {
+	return errUnsupportedPlatform
+}
+
+func (c *UcpConfig) SetKeepaliveInterval(interval time.Duration) error {
+	return errUnsupportedPlatform
+}
+
+func (c *UcpConfig) SetKeepaliveNumEps(count uint) error {
+	return errUnsupportedPlatform
+}
+
+func (c *UcpConfig) Print(title string, flags UcsConfigPrintFlags) string {
+	return ""
+}
+
+func (c *UcpConfig) Close() {}
+
+type UcpConnectionRequest struct{}
+
+type UcpConnectionRequestAttributes struct {
+	ClientAddress *net.TCPAddr
+	ClientId      uint64
+}
+
+func (c *UcpConnectionRequest) Reject() error {
+	return errUnsupportedPlatform
+}
+
+func (c *UcpConnectionRequest) Query(attrs ...UcpConnRequestAttribute) (*UcpConnectionRequestAttributes, error) {
+	return nil, errUnsupportedPlatform
+}
+
+type UcpContext struct {
+	context     unsafe.Pointer
+	resourcesMu sync.Mutex
+	workers     map[*UcpWorker]struct{}
+	memories    map[unsafe.Pointer]*memAllocation
+}
+
+type UcpContextAttributes struct {
+	RequestSize uint64
+	ThreadMode  UcsThreadMode
+	MemoryTypes uint64
+	Name        string
+}
+
+func NewUcpContext(contextParams *UcpParams) (*UcpContext, error) {
+	return nil, errUnsupportedPlatform
+}
+
+func (c *UcpContext) Close() error {
+	return errUnsupportedPlatform
+}
+
+func (c *UcpContext) PrintConfig(w io.Writer) error {
+	return errUnsupportedPlatform
+}
+
+func (c *UcpContext) TagSenderMask() uint64 {
+	return 0
+}
+
+func (c *UcpContext) MemoryTypesMask() (uint64, error) {
+	return 0, errUnsupportedPlatform
+}
+
+func (c *UcpContext) Features() UcpFeatures {
+	return 0
+}
+
+func (c *UcpContext) MemMap(memMapParams *UcpMmapParams) (*UcpMemory, error) {
+	return nil, errUnsupportedPlatform
+}
+
+func (c *UcpContext) MemImport(buffer []byte) (*UcpMemory, error) {
+	return nil, errUnsupportedPlatform
+}
+
+func (c *UcpContext) DetectMemoryType(address unsafe.Pointer, length uint64) (UcsMemoryType, error) {
+	return 0, errUnsupportedPlatform
+}
+
+func (c *UcpContext) AllocAndMap(size uint64, params *UcpMmapParams) (*UcpMemory, []byte, error) {
+	return nil, nil, errUnsupportedPlatform
+}
+
+func (c *UcpContext) Query(attrs ...UcpContextAttr) (*UcpContextAttributes, error) {
+	return nil, errUnsupportedPlatform
+}
+
+func (c *UcpContext) NewWorker(workerParams *UcpWorkerParams) (*UcpWorker, error) {
+	return nil, errUnsupportedPlatform
+}
+
+type UcpParams struct{}
+
+func (p *UcpParams) SetTagSenderMask(tagSenderMask uint64) *UcpParams {
+	return p
+}
+
+func (p *UcpParams) SetEstimatedNumEPS(estimatedNumEPS uint64) *UcpParams {
+	return p
+}
+
+func (p *UcpParams) SetEstimatedNumPPN(estimatedNumPPN uint64) *UcpParams {
+	return p
+}
+
+func (p *UcpParams) SetName(name string) *UcpParams {
+	return p
+}
+
+func (p *UcpParams) EnableSharedWorkers() *UcpParams {
+	return p
+}
+
+func (p *UcpParams) SetFeatures(features UcpFeatures) *UcpParams {
+	return p
+}
+
+func (p *UcpParams) EnableTag() *UcpParams {
+	return p
+}
+
+func (p *UcpParams) EnableRMA() *UcpParams {
+	return p
+}
+
+func (p *UcpParams) EnableAtomic32Bit() *UcpParams {
+	return p
+}
+
+func (p *UcpParams) EnableAtomic64Bit() *UcpParams {
+	return p
+}
+
+func (p *UcpParams) EnableWakeup() *UcpParams {
+	return p
+}
+
+func (p *UcpParams) EnableStream() *UcpParams {
+	return p
+}
+
+func (p *UcpParams) EnableExportedMemh() *UcpParams {
+	return p
+}
+
+func (p *UcpParams) EnableAM() *UcpParams {
+	return p
+}
+
+func (p *UcpParams) SetConfig(config *UcpConfig) *UcpParams {
+	return p
+}
+
+type UcpEp struct{}
+
+type UcpTransportEntry struct {
+	TransportName string
+	DeviceName    string
+}
+
+type UcpEpAttributes struct {
+	Name           string
+	LocalSockAddr  *net.TCPAddr
+	RemoteSockAddr *net.TCPAddr
+	Transports     []UcpTransportEntry
+}
+
+func getWorkerEndpoints(worker unsafe.Pointer) []*UcpEp {
+	return nil
+}
+
+func (e *UcpEp) FlushNonBlocking(params *UcpRequestParams) (*UcpRequest, error) {
+	return nil, errUnsupportedPlatform
+}
+
+func (e *UcpEp) SetUserData(userData interface{}) {}
+
+func (e *UcpEp) UserData() interface{} {
+	return nil
+}
+
+func (e *UcpEp) CloseNonBlocking(flags UcpEpCloseFlags, params *UcpRequestParams) (*UcpRequest, error) {
+	return nil, errUnsupportedPlatform
+}
+
+func (e *UcpEp) CloseNonBlockingForce(params *UcpRequestParams) (*UcpRequest, error) {
+	return nil, errUnsupportedPlatform
+}
+
+func (e *UcpEp) CloseNonBlockingFlush(params *UcpRequestParams) (*UcpRequest, error) {
+	return nil, errUnsupportedPlatform
+}
+
+func (e *UcpEp) Query(attrs ...UcpEpAttribute) (*UcpEpAttributes, error) {
+	return nil, errUnsupportedPlatform
+}
+
+func (e *UcpEp) PrintInfo() string {
+	return ""
+}
+
+func (e *UcpEp) SendTagNonBlocking(tag uint64, address unsafe.Pointer, size uint64, params *UcpRequestParams) (*UcpRequest, error) {
+	return nil, errUnsupportedPlatform
+}
+
+func (e *UcpEp) SendTagIovNonBlocking(tag uint64, iov []UcpIov, params *UcpRequestParams) (*UcpRequest, error) {
+	return nil, errUnsupportedPlatform
+}
+
+func (e *UcpEp) SendAmNonBlocking(id uint, header unsafe.Pointer, headerSize uint64, data unsafe.Pointer, dataSize uint64, flags UcpAmSendFlags, params *UcpRequestParams) (*UcpRequest, error) {
+	return nil, errUnsupportedPlatform
+}
+
+func (e *UcpEp) SendAmIovNonBlocking(id uint, header unsafe.Pointer, headerSize uint64, iov []UcpIov, flags UcpAmSendFlags, params *UcpRequestParams) (*UcpRequest, error) {
+	return nil, errUnsupportedPlatform
+}
+
+func (e *UcpEp) UnpackRkey(rkeyBuffer []byte) (*UcpRkey, error) {
+	return nil, errUnsupportedPlatform
+}
+
+func (e *UcpEp) RmaPutNonBlocking(address unsafe.Pointer, size uint64, remoteAddr uint64, rkey *UcpRkey, params *UcpRequestParams) (*UcpRequest, error) {
+	return nil, errUnsupportedPlatform
+}
+
+func (e *UcpEp) RmaGetNonBlocking(address unsafe.Pointer, size uint64, remoteAddr uint64, rkey *UcpRkey, params *UcpRequestParams) (*UcpRequest, error) {
+	return nil, errUnsupportedPlatform
+}
+
+func (e *UcpEp) AtomicNonBlocking(op UcpAtomicOp, buffer unsafe.Pointer, opSize uint64, remoteAddr uint64, rkey *UcpRkey, params *UcpRequestParams) (*UcpRequest, error) {
+	return nil, errUnsupportedPlatform
+}
+
+func (e *UcpEp) Atomic32(op UcpAtomicOp, value uint32, remoteAddr uint64, rkey *UcpRkey, result *uint32, params *UcpRequestParams) (*UcpRequest, error) {
+	return nil, errUnsupportedPlatform
+}
+
+func (e *UcpEp) Atomic64(op UcpAtomicOp, value uint64, remoteAddr uint64, rkey *UcpRkey, result *uint64, params *UcpRequestParams) (*UcpRequest, error) {
+	return nil, errUnsupportedPlatform
+}
+
+func (e *UcpEp) SendStreamNonBlocking(address unsafe.Pointer, size uint64, params *UcpRequestParams) (*UcpRequest, error) {
+	return nil, errUnsupportedPlatform
+}
+
+func (e *UcpEp) RecvStreamNonBlocking(address unsafe.Pointer, size uint64, params *UcpRequestParams) (*UcpRequest, error) {
+	return nil, errUnsupportedPlatform
+}
+
+func (e *UcpEp) SendStreamIovNonBlocking(iov []UcpIov, params *UcpRequestParams) (*UcpRequest, error) {
+	return nil, errUnsupportedPlatform
+}
+
+func (e *UcpEp) RecvStreamIovNonBlocking(iov []UcpIov, params *UcpRequestParams) (*UcpRequest, error) {
+	return nil, errUnsupportedPlatform
+}
+
+func (w *UcpWorker) Connect(ctx context.Context, epParams *UcpEpParams) (*UcpEp, error) {
+	return nil, errUnsupportedPlatform
+}
+
+type UcpEpParams struct{}
+
+type UcpEpErrHandler func(ep *UcpEp, status UcsStatus)
+
+func (p *UcpEpParams) SetUcpAddress(a *UcpAddress) *UcpEpParams {
+	return p
+}
+
+func (p *UcpEpParams) SetUcpAddressBytes(address []byte) *UcpEpParams {
+	return p
+}
+
+func (p *UcpEpParams) SetPeerErrorHandling() *UcpEpParams {
+	return p
+}
+
+func (p *UcpEpParams) SetErrorHandlingMode(mode UcpErrHandlingMode) *UcpEpParams {
+	return p
+}
+
+func (p *UcpEpParams) SetErrorHandler(errHandler UcpEpErrHandler) *UcpEpParams {
+	return p
+}
+
+func (p *UcpEpParams) SetUserData(userData interface{}) *UcpEpParams {
+	return p
+}
+
+func (p *UcpEpParams) SetName(name string) *UcpEpParams {
+	return p
+}
+
+func (p *UcpEpParams) SetSocketAddress(a *net.TCPAddr) (*UcpEpParams, error) {
+	return p, errUnsupportedPlatform
+}
+
+func (p *UcpEpParams) SetSockAddr(a net.Addr) (*UcpEpParams, error) {
+	return p, errUnsupportedPlatform
+}
+
+func (p *UcpEpParams) SetAddress(address string) *UcpEpParams {
+	return p
+}
+
+func (p *UcpEpParams) SetLocalSockAddr(a net.Addr) (*UcpEpParams, error) {
+	return p, errUnsupportedPlatform
+}
+
+func (p *UcpEpParams) SetConnectionFlags(flags UcpEpParamsFlags) *UcpEpParams {
+	return p
+}
+
+func (p *UcpEpParams) SetConnRequest(c *UcpConnectionRequest) *UcpEpParams {
+	return p
+}
+
+func (p *UcpEpParams) SendClientId() *UcpEpParams {
+	return p
+}
+
+type UcpFeatureError struct {
+	Feature UcpFeatures
+}
+
+func (e *UcpFeatureError) Error() string {
+	return ""
+}
+
+func (e *UcpFeatureError) Is(target error) bool {
+	return false
+}
+
+func (f UcpFeatures) String() string {
+	return ""
+}
+
+type UcpHandshake = func(conn *UcpHandshakeConn) error
+
+type UcpHandshakeError struct {
+	Err error
+}
+
+func (e *UcpHandshakeError) Error() string {
+	return ""
+}
+
+func (e *UcpHandshakeError) Is(target error) bool {
+	return false
+}
+
+func (e *UcpHandshakeError) Unwrap() error {
+	return errUnsupportedPlatform
+}
+
+func (w *UcpWorker) EnableHandshake(id uint) error {
+	return errUnsupportedPlatform
+}
+
+type UcpHandshakeConn struct{}
+
+func (c *UcpHandshakeConn) Ep() *UcpEp {
+	return nil
+}
+
+func (c *UcpHandshakeConn) IsServer() bool {
+	return false
+}
+
+func (c *UcpHandshakeConn) Send(message []byte) error {
+	return errUnsupportedPlatform
+}
+
+func (c *UcpHandshakeConn) Recv() ([]byte, error) {
+	return nil, errUnsupportedPlatform
+}
+
+func (w *UcpWorker) ConnectHandshake(ctx context.Context, epParams *UcpEpParams, handshake UcpHandshake) (*UcpEp, error) {
+	return nil, errUnsupportedPlatform
+}
+
+func (w *UcpWorker) AcceptHandshake(ctx context.Context, connRequest *UcpConnectionRequest, epParams *UcpEpParams, handshake UcpHandshake) (*UcpEp, error) {
+	return nil, errUnsupportedPlatform
+}
+
+type UcpIov struct {
+	Buffer unsafe.Pointer
+	Length uint64
+}
+
+func withRelease(params *UcpRequestParams, release func()) *UcpRequestParams {
+	return nil
+}
+
+type UcpListener struct{}
+
+type UcpListenerAttributes struct {
+	Address *net.TCPAddr
+}
+
+func (l *UcpListener) Close() {}
+
+func (l *UcpListener) Query(attrs ...UcpListenerAttribute) (*UcpListenerAttributes, error) {
+	return nil, errUnsupportedPlatform
+}
+
+func (l *UcpListener) Addr() (*net.TCPAddr, error) {
+	return nil, errUnsupportedPlatform
+}
+
+func (l *UcpListener) AdvertisedAddrs() ([]*net.TCPAddr, error) {
+	return nil, errUnsupportedPlatform
+}
+
+type UcpListenerParams struct{}
+
+type UcpConnectionFilter = func(attrs *UcpConnectionRequestAttributes) bool
+
+func (p *UcpListenerParams) SetSocketAddress(a *net.TCPAddr) (*UcpListenerParams, error) {
+	return p, errUnsupportedPlatform
+}
+
+func (p *UcpListenerParams) SetConnectionHandler(connHandler UcpListenerConnectionHandler) *UcpListenerParams {
+	return p
+}
+
+func (p *UcpListenerParams) SetConnectionFilter(filter UcpConnectionFilter) *UcpListenerParams {
+	return p
+}
+
+type UcsLogRecord struct {
+	Level     UcsLogLevel
+	Component string
+	File      string
+	Line      uint
+	Function  string
+	Message   string
+}
+
+type UcsLogHandler = func(record *UcsLogRecord)
+
+func (l UcsLogLevel) String() string {
+	return ""
+}
+
+func SetLogHandler(handler UcsLogHandler) {}
+
+func SetLogComponentLevel(component string, level UcsLogLevel) {}
+
+func SetLogLevel(level UcsLogLevel) error {
+	return errUnsupportedPlatform
+}
+
+type UcpMemory struct {
+	memHandle unsafe.Pointer
+	context   unsafe.Pointer
+	owner     *UcpContext
+}
+
+type UcpMemAttributes struct {
+	Address unsafe.Pointer
+	Length  uint64
+	MemType UcsMemoryType
+}
+
+func (m *UcpMemory) Query(attrs ...UcpMemAttribute) (*UcpMemAttributes, error) {
+	return nil, errUnsupportedPlatform
+}
+
+func (m *UcpMemory) RkeyPack() ([]byte, error) {
+	return nil, errUnsupportedPlatform
+}
+
+func (m *UcpMemory) Export() ([]byte, error) {
+	return nil, errUnsupportedPlatform
+}
+
+func (m *UcpMemory) Close() error {
+	return errUnsupportedPlatform
+}
+
+type UcpHugePages int
+
+const (
+	UcpHugePagesNone        UcpHugePages = 0
+	UcpHugePagesHugeTLB     UcpHugePages = 1
+	UcpHugePagesTransparent UcpHugePages = 2
+)
+
+type UcpMemAllocAttributes struct {
+	UcpMemAttributes
+	Alignment            uint64
+	HugePages            UcpHugePages
+	PageSize             uint64
+	TransparentHugeBytes uint64
+}
+
+type memAllocation struct{}
+
+func (m *UcpMemory) Attributes() (*UcpMemAllocAttributes, error) {
+	return nil, errUnsupportedPlatform
+}
+
+type UcpMemoryCache struct{}
+
+func (c *UcpContext) NewMemoryCache(capacity int) *UcpMemoryCache {
+	return nil
+}
+
+func (m *UcpMemoryCache) Get(address unsafe.Pointer, length uint64) (*UcpMemory, error) {
+	return nil, errUnsupportedPlatform
+}
+
+func (m *UcpMemoryCache) GetBytes(data []byte) (*UcpMemory, error) {
+	return nil, errUnsupportedPlatform
+}
+
+func (m *UcpMemoryCache) Put(memory *UcpMemory) {}
+
+func (m *UcpMemoryCache) Invalidate(address unsafe.Pointer, length uint64) {}
+
+func (m *UcpMemoryCache) Close() {}
+
+func (e *UcpEp) withCachedBytes(params *UcpRequestParams, address unsafe.Pointer, data []byte) *UcpRequestParams {
+	return nil
+}
+
+func (w *UcpWorker) MemoryCache() *UcpMemoryCache {
+	return nil
+}
+
+type UcpMmapParams struct{}
+
+func (p *UcpMmapParams) SetAddress(address unsafe.Pointer) *UcpMmapParams {
+	return p
+}
+
+func (p *UcpMmapParams) SetLength(length uint64) *UcpMmapParams {
+	return p
+}
+
+func (p *UcpMmapParams) Allocate() *UcpMmapParams {
+	return p
+}
+
+func (p *UcpMmapParams) Nonblocking() *UcpMmapParams {
+	return p
+}
+
+func (p *UcpMmapParams) SetAlignment(alignment uint64) *UcpMmapParams {
+	return p
+}
+
+func (p *UcpMmapParams) SetHugePages(hugePages UcpHugePages) *UcpMmapParams {
+	return p
+}
+
+func (p *UcpMmapParams) Fixed() *UcpMmapParams {
+	return p
+}
+
+func (p *UcpMmapParams) SetProtection(prot UcpProtection) *UcpMmapParams {
+	return p
+}
+
+func (p *UcpMmapParams) SetMemoryType(memType UcsMemoryType) *UcpMmapParams {
+	return p
+}
+
+func (p *UcpMmapParams) SetExportedMemh(buffer []byte) *UcpMmapParams {
+	return p
+}
+
+type UcpParamsError struct {
+	Params string
+	Reason string
+}
+
+func (e *UcpParamsError) Error() string {
+	return ""
+}
+
+func (e *UcpParamsError) Is(target error) bool {
+	return false
+}
+
+func (p *UcpParams) Validate() error {
+	return errUnsupportedPlatform
+}
+
+func (p *UcpWorkerParams) Validate() error {
+	return errUnsupportedPlatform
+}
+
+func (p *UcpEpParams) Validate() error {
+	return errUnsupportedPlatform
+}
+
+func (p *UcpListenerParams) Validate() error {
+	return errUnsupportedPlatform
+}
+
+func (p *UcpMmapParams) Validate() error {
+	return errUnsupportedPlatform
+}
+
+type UcpTagPersistentRecvCallback = func(buffer unsafe.Pointer, info *UcpTagRecvInfo, status UcsStatus)
+
+type UcpTagPersistentRecv struct{}
+
+func (w *UcpWorker) RecvTagPersistent(tag uint64, tagMask uint64, pool UcpBufferPool, cb UcpTagPersistentRecvCallback) (*UcpTagPersistentRecv, error) {
+	return nil, errUnsupportedPlatform
+}
+
+func (r *UcpTagPersistentRecv) Active() int {
+	return 0
+}
+
+func (r *UcpTagPersistentRecv) Close() {}
+
+type UcpPersistentSendCallback = func(request *UcpPersistentRequest, status UcsStatus)
+
+type UcpPersistentRequest struct{}
+
+func (e *UcpEp) NewPersistentSendTag(tag uint64, address unsafe.Pointer, size uint64, params *UcpRequestParams, cb UcpPersistentSendCallback) (*UcpPersistentRequest, error) {
+	return nil, errUnsupportedPlatform
+}
+
+func (e *UcpEp) NewPersistentSendAm(id uint, header unsafe.Pointer, headerSize uint64, data unsafe.Pointer, dataSize uint64, flags UcpAmSendFlags, params *UcpRequestParams, cb UcpPersistentSendCallback) (*UcpPersistentRequest, error) {
+	return nil, errUnsupportedPlatform
+}
+
+func (r *UcpPersistentRequest) Resubmit() error {
+	return errUnsupportedPlatform
+}
+
+func (r *UcpPersistentRequest) GetStatus() UcsStatus {
+	return UCS_ERR_UNSUPPORTED
+}
+
+func (r *UcpPersistentRequest) Close() {}
+
+func (w *UcpWorker) EnablePing(id uint) error {
+	return errUnsupportedPlatform
+}
+
+func (e *UcpEp) Ping(timeout time.Duration) (time.Duration, error) {
+	return 0, errUnsupportedPlatform
+}
+
+type UcpRequest struct {
+	worker unsafe.Pointer
+	Status UcsStatus
+}
+
+type UcpRequestParams struct {
+	Cb UcpCallback
+}
+
+func (p *UcpRequestParams) SetMemType(memType UcsMemoryType) *UcpRequestParams {
+	return p
+}
+
+func (p *UcpRequestParams) SetMemory(memory *UcpMemory) *UcpRequestParams {
+	return p
+}
+
+func (p *UcpRequestParams) SetMemoryCache(memoryCache *UcpMemoryCache) *UcpRequestParams {
+	return p
+}
+
+func (p *UcpRequestParams) SetOpAttrFlags(flags UcpOpAttrFlags) *UcpRequestParams {
+	return p
+}
+
+func (p *UcpRequestParams) SetReplyBuffer(buffer unsafe.Pointer) *UcpRequestParams {
+	return p
+}
+
+func (p *UcpRequestParams) SetStreamRecvFlags(flags UcpStreamRecvFlags) *UcpRequestParams {
+	return p
+}
+
+func (p *UcpRequestParams) SetCallback(cb UcpCallback) *UcpRequestParams {
+	return p
+}
+
+func (p *UcpRequestParams) SetUserData(userData interface{}) *UcpRequestParams {
+	return p
+}
+
+func (p *UcpRequestParams) EnableDoneChannel() *UcpRequestParams {
+	return p
+}
+
+func (p *UcpRequestParams) SetDeadline(deadline time.Time) *UcpRequestParams {
+	return p
+}
+
+func (p *UcpRequestParams) SetRetryPolicy(maxRetries int, backoff time.Duration) *UcpRequestParams {
+	return p
+}
+
+func NewRequest(request unsafe.Pointer, worker unsafe.Pointer, callbackId uint64, done chan UcsStatus, immidiateInfo interface{}) (*UcpRequest, error) {
+	return nil, errUnsupportedPlatform
+}
+
+func (r *UcpRequest) GetStatus() UcsStatus {
+	return UCS_ERR_UNSUPPORTED
+}
+
+func (r *UcpRequest) UserData() interface{} {
+	return nil
+}
+
+func (r *UcpRequest) Done() <-chan UcsStatus {
+	return nil
+}
+
+func (r *UcpRequest) Cancel() {}
+
+func (r *UcpRequest) WaitContext(ctx context.Context) error {
+	return errUnsupportedPlatform
+}
+
+func (r *UcpRequest) Close() {}
+
+func progressWorker(worker unsafe.Pointer) uint {
+	return 0
+}
+
+type UcpRkey struct{}
+
+func (r *UcpRkey) Close() {}
+
+func (r *UcpRkey) Ptr(remoteAddr uint64) (unsafe.Pointer, error) {
+	return nil, errUnsupportedPlatform
+}
+
+type UcsStatsCounter struct {
+	Class string
+	Name  string
+	Value uint64
+}
+
+func UcsStatsSupported() bool {
+	return false
+}
+
+func UcsStatsIsActive() bool {
+	return false
+}
+
+func UcsStatsDump() {}
+
+func UcsStatsSnapshot() ([]UcsStatsCounter, error) {
+	return nil, errUnsupportedPlatform
+}
+
+type UcpStreamReader struct{}
+
+type UcpStreamWriter struct{}
+
+func (e *UcpEp) Reader() *UcpStreamReader {
+	return nil
+}
+
+func (e *UcpEp) Writer() *UcpStreamWriter {
+	return nil
+}
+
+func (r *UcpStreamReader) SetReadDeadline(t time.Time) error {
+	return errUnsupportedPlatform
+}
+
+func (r *UcpStreamReader) Read(p []byte) (int, error) {
+	return 0, errUnsupportedPlatform
+}
+
+func (r *UcpStreamReader) Close() error {
+	return errUnsupportedPlatform
+}
+
+func (w *UcpStreamWriter) SetWriteDeadline(t time.Time) error {
+	return errUnsupportedPlatform
+}
+
+func (w *UcpStreamWriter) Write(p []byte) (int, error) {
+	return 0, errUnsupportedPlatform
+}
+
+func (w *UcpStreamWriter) Flush() error {
+	return errUnsupportedPlatform
+}
+
+func (w *UcpStreamWriter) Close() error {
+	return errUnsupportedPlatform
+}
+
+type UcpTagMsg struct {
+	Tag     uint64
+	Address unsafe.Pointer
+	Size    uint64
+}
+
+func (e *UcpEp) SendTagBatch(msgs []UcpTagMsg, params *UcpRequestParams) ([]*UcpRequest, error) {
+	return nil, errUnsupportedPlatform
+}
+
+type UcpTraceOp int
+
+const (
+	UcpTraceTagSend    UcpTraceOp = 0
+	UcpTraceTagRecv    UcpTraceOp = 1
+	UcpTraceAmSend     UcpTraceOp = 2
+	UcpTraceAmRecv     UcpTraceOp = 3
+	UcpTraceStreamSend UcpTraceOp = 4
+	UcpTraceStreamRecv UcpTraceOp = 5
+	UcpTraceRmaPut     UcpTraceOp = 6
+	UcpTraceRmaGet     UcpTraceOp = 7
+	UcpTraceAtomic     UcpTraceOp = 0x8
+	UcpTraceFlush      UcpTraceOp = 9
+)
+
+func (o UcpTraceOp) String() string {
+	return ""
+}
+
+type UcpTraceEvent struct {
+	Op         UcpTraceOp
+	Context    context.Context
+	Ep         *UcpEp
+	Tag        uint64
+	TagMask    uint64
+	AmId       uint
+	RemoteAddr uint64
+	Size       uint64
+}
+
+type UcpTraceSpan interface {
+	End(status UcsStatus, length uint64)
+}
+
+type UcpTracer interface {
+	Start(event *UcpTraceEvent) UcpTraceSpan
+}
+
+func (p *UcpRequestParams) SetTraceContext(ctx context.Context) *UcpRequestParams {
+	return p
+}
+
+type UcpTransferStats struct {
+	MessagesSent     uint64
+	BytesSent        uint64
+	MessagesReceived uint64
+	BytesReceived    uint64
+	PendingRequests  int64
+	Errors           uint64
+}
+
+type UcpEpTransferStats struct {
+	Ep    *UcpEp
+	Name  string
+	Stats UcpTransferStats
+}
+
+func (w *UcpWorker) Stats() UcpTransferStats {
+	return UcpTransferStats{}
+}
+
+func (w *UcpWorker) EndpointStats() []UcpEpTransferStats {
+	return nil
+}
+
+func (e *UcpEp) Stats() UcpTransferStats {
+	return UcpTransferStats{}
+}
+
+type UcpTruncationPolicy int
+
+const (
+	UcpTruncationError    UcpTruncationPolicy = 0
+	UcpTruncationTruncate UcpTruncationPolicy = 1
+	UcpTruncationGrow     UcpTruncationPolicy = 2
+)
+
+func (p *UcpRequestParams) SetTruncationPolicy(policy UcpTruncationPolicy) *UcpRequestParams {
+	return p
+}
+
+func (r *UcpRequest) GrownBuffer() unsafe.Pointer {
+	return nil
+}
+
+type UcpProtection uint32
+
+const (
+	UCP_MEM_MAP_PROT_LOCAL_READ   UcpProtection = 1
+	UCP_MEM_MAP_PROT_LOCAL_WRITE  UcpProtection = 2
+	UCP_MEM_MAP_PROT_REMOTE_READ  UcpProtection = 0x100
+	UCP_MEM_MAP_PROT_REMOTE_WRITE UcpProtection = 0x200
+)
+
+type UcpFeatures uint64
+
+const (
+	UCP_FEATURE_TAG           UcpFeatures = 1
+	UCP_FEATURE_RMA           UcpFeatures = 2
+	UCP_FEATURE_AMO32         UcpFeatures = 4
+	UCP_FEATURE_AMO64         UcpFeatures = 0x8
+	UCP_FEATURE_WAKEUP        UcpFeatures = 0x10
+	UCP_FEATURE_STREAM        UcpFeatures = 0x20
+	UCP_FEATURE_AM            UcpFeatures = 0x40
+	UCP_FEATURE_EXPORTED_MEMH UcpFeatures = 0x80
+)
+
+type UcpContextAttr uint32
+
+const (
+	UCP_ATTR_FIELD_REQUEST_SIZE UcpContextAttr = 1
+	UCP_ATTR_FIELD_THREAD_MODE  UcpContextAttr = 2
+	UCP_ATTR_FIELD_MEMORY_TYPES UcpContextAttr = 4
+	UCP_ATTR_FIELD_NAME         UcpContextAttr = 0x8
+)
+
+type UcpMemAttribute uint32
+
+const (
+	UCP_MEM_ATTR_FIELD_ADDRESS  UcpMemAttribute = 1
+	UCP_MEM_ATTR_FIELD_LENGTH   UcpMemAttribute = 2
+	UCP_MEM_ATTR_FIELD_MEM_TYPE UcpMemAttribute = 4
+)
+
+type UcpWakeupEvent uint32
+
+const (
+	UCP_WAKEUP_RMA      UcpWakeupEvent = 1
+	UCP_WAKEUP_AMO      UcpWakeupEvent = 2
+	UCP_WAKEUP_TAG_SEND UcpWakeupEvent = 4
+	UCP_WAKEUP_TAG_RECV UcpWakeupEvent = 0x8
+	UCP_WAKEUP_TX       UcpWakeupEvent = 0x400
+	UCP_WAKEUP_RX       UcpWakeupEvent = 0x800
+	UCP_WAKEUP_EDGE     UcpWakeupEvent = 0x10000
+)
+
+type UcpWorkerAttribute uint32
+
+const (
+	UCP_WORKER_ATTR_FIELD_THREAD_MODE     UcpWorkerAttribute = 1
+	UCP_WORKER_ATTR_FIELD_ADDRESS         UcpWorkerAttribute = 2
+	UCP_WORKER_ATTR_FIELD_ADDRESS_FLAGS   UcpWorkerAttribute = 4
+	UCP_WORKER_ATTR_FIELD_MAX_AM_HEADER   UcpWorkerAttribute = 0x8
+	UCP_WORKER_ATTR_FIELD_NAME            UcpWorkerAttribute = 0x10
+	UCP_WORKER_ATTR_FIELD_MAX_INFO_STRING UcpWorkerAttribute = 0x20
+)
+
+type UcpWorkerAddressFlags uint32
+
+const (
+	UCP_WORKER_ADDRESS_FLAG_NET_ONLY UcpWorkerAddressFlags = 1
+)
+
+type UcpWorkerAddressAttribute uint64
+
+const (
+	UCP_WORKER_ADDRESS_ATTR_FIELD_UID UcpWorkerAddressAttribute = 1
+)
+
+type UcpListenerAttribute uint32
+
+const (
+	UCP_LISTENER_ATTR_FIELD_SOCKADDR UcpListenerAttribute = 1
+)
+
+type UcpAmSendFlags uint64
+
+const (
+	UCP_AM_SEND_FLAG_REPLY       UcpAmSendFlags = 1
+	UCP_AM_SEND_FLAG_EAGER       UcpAmSendFlags = 2
+	UCP_AM_SEND_FLAG_RNDV        UcpAmSendFlags = 4
+	UCP_AM_SEND_FLAG_COPY_HEADER UcpAmSendFlags = 0x8
+)
+
+type UcpAmRecvAttrs uint64
+
+const (
+	UCP_AM_RECV_ATTR_FIELD_REPLY_EP UcpAmRecvAttrs = 1
+	UCP_AM_RECV_ATTR_FLAG_DATA      UcpAmRecvAttrs = 0x10000
+	UCP_AM_RECV_ATTR_FLAG_RNDV      UcpAmRecvAttrs = 0x20000
+)
+
+type UcpAmCbFlags uint64
+
+const (
+	UCP_AM_FLAG_WHOLE_MSG       UcpAmCbFlags = 1
+	UCP_AM_FLAG_PERSISTENT_DATA UcpAmCbFlags = 2
+)
+
+type UcpConnRequestAttribute uint32
+
+const (
+	UCP_CONN_REQUEST_ATTR_FIELD_CLIENT_ADDR = 1
+	UCP_CONN_REQUEST_ATTR_FIELD_CLIENT_ID   = 2
+)
+
+type UcpOpAttrFlags uint32
+
+const (
+	UCP_OP_ATTR_FLAG_NO_IMM_CMPL    UcpOpAttrFlags = 0x10000
+	UCP_OP_ATTR_FLAG_FAST_CMPL      UcpOpAttrFlags = 0x20000
+	UCP_OP_ATTR_FLAG_FORCE_IMM_CMPL UcpOpAttrFlags = 0x40000
+	UCP_OP_ATTR_FLAG_MULTI_SEND     UcpOpAttrFlags = 0x80000
+)
+
+type UcpEpAttribute uint32
+
+const (
+	UCP_EP_ATTR_FIELD_NAME            UcpEpAttribute = 1
+	UCP_EP_ATTR_FIELD_LOCAL_SOCKADDR  UcpEpAttribute = 2
+	UCP_EP_ATTR_FIELD_REMOTE_SOCKADDR UcpEpAttribute = 4
+	UCP_EP_ATTR_FIELD_TRANSPORTS      UcpEpAttribute = 0x8
+)
+
+type UcpAtomicOp int
+
+const (
+	UCP_ATOMIC_OP_ADD   UcpAtomicOp = 0
+	UCP_ATOMIC_OP_SWAP  UcpAtomicOp = 1
+	UCP_ATOMIC_OP_CSWAP UcpAtomicOp = 2
+	UCP_ATOMIC_OP_AND   UcpAtomicOp = 3
+	UCP_ATOMIC_OP_OR    UcpAtomicOp = 4
+	UCP_ATOMIC_OP_XOR   UcpAtomicOp = 5
+)
+
+type UcpErrHandlingMode int
+
+const (
+	UCP_ERR_HANDLING_MODE_NONE UcpErrHandlingMode = 0
+	UCP_ERR_HANDLING_MODE_PEER UcpErrHandlingMode = 1
+)
+
+type UcpEpParamsFlags uint32
+
+const (
+	UCP_EP_PARAMS_FLAGS_CLIENT_SERVER  UcpEpParamsFlags = 1
+	UCP_EP_PARAMS_FLAGS_NO_LOOPBACK    UcpEpParamsFlags = 2
+	UCP_EP_PARAMS_FLAGS_SEND_CLIENT_ID UcpEpParamsFlags = 4
+)
+
+type UcpEpCloseFlags uint32
+
+const (
+	UCP_EP_CLOSE_FLAG_FORCE UcpEpCloseFlags = 1
+)
+
+type UcpStreamRecvFlags uint32
+
+const (
+	UCP_STREAM_RECV_FLAG_WAITALL UcpStreamRecvFlags = 1
+)
+
+type UcsThreadMode int
+
+const (
+	UCS_THREAD_MODE_SINGLE     UcsThreadMode = 0
+	UCS_THREAD_MODE_SERIALIZED UcsThreadMode = 1
+	UCS_THREAD_MODE_MULTI      UcsThreadMode = 2
+)
+
+type UcsConfigPrintFlags int
+
+const (
+	UCS_CONFIG_PRINT_CONFIG          UcsConfigPrintFlags = 1
+	UCS_CONFIG_PRINT_HEADER          UcsConfigPrintFlags = 2
+	UCS_CONFIG_PRINT_DOC             UcsConfigPrintFlags = 4
+	UCS_CONFIG_PRINT_HIDDEN          UcsConfigPrintFlags = 0x8
+	UCS_CONFIG_PRINT_COMMENT_DEFAULT UcsConfigPrintFlags = 0x10
+)
+
+type UcsLogLevel int
+
+const (
+	UCS_LOG_LEVEL_FATAL       UcsLogLevel = 0
+	UCS_LOG_LEVEL_ERROR       UcsLogLevel = 1
+	UCS_LOG_LEVEL_WARN        UcsLogLevel = 2
+	UCS_LOG_LEVEL_DIAG        UcsLogLevel = 3
+	UCS_LOG_LEVEL_INFO        UcsLogLevel = 4
+	UCS_LOG_LEVEL_DEBUG       UcsLogLevel = 5
+	UCS_LOG_LEVEL_TRACE       UcsLogLevel = 6
+	UCS_LOG_LEVEL_TRACE_REQ   UcsLogLevel = 7
+	UCS_LOG_LEVEL_TRACE_DATA  UcsLogLevel = 0x8
+	UCS_LOG_LEVEL_TRACE_ASYNC UcsLogLevel = 9
+	UCS_LOG_LEVEL_TRACE_FUNC  UcsLogLevel = 10
+	UCS_LOG_LEVEL_TRACE_POLL  UcsLogLevel = 11
+	UCS_LOG_LEVEL_PRINT       UcsLogLevel = 13
+)
+
+type UcsMemoryType int
+
+const (
+	UCS_MEMORY_TYPE_HOST         UcsMemoryType = 0
+	UCS_MEMORY_TYPE_CUDA         UcsMemoryType = 1
+	UCS_MEMORY_TYPE_CUDA_MANAGED UcsMemoryType = 2
+	UCS_MEMORY_TYPE_ROCM         UcsMemoryType = 3
+	UCS_MEMORY_TYPE_ROCM_MANAGED UcsMemoryType = 4
+	UCS_MEMORY_TYPE_RDMA         UcsMemoryType = 5
+	UCS_MEMORY_TYPE_ZE_HOST      UcsMemoryType = 6
+	UCS_MEMORY_TYPE_ZE_DEVICE    UcsMemoryType = 7
+	UCS_MEMORY_TYPE_ZE_MANAGED   UcsMemoryType = 0x8
+	UCS_MEMORY_TYPE_UNKNOWN      UcsMemoryType = 9
+)
+
+func (m UcsMemoryType) String() string {
+	return ""
+}
+
+func IsMemTypeSupported(memType UcsMemoryType, mask uint64) bool {
+	return false
+}
+
+type UcsStatus int
+
+const (
+	UCS_OK                         UcsStatus = 0
+	UCS_INPROGRESS                 UcsStatus = 1
+	UCS_ERR_NO_MESSAGE             UcsStatus = -1
+	UCS_ERR_NO_RESOURCE            UcsStatus = -2
+	UCS_ERR_IO_ERROR               UcsStatus = -3
+	UCS_ERR_NO_MEMORY              UcsStatus = -4
+	UCS_ERR_INVALID_PARAM          UcsStatus = -5
+	UCS_ERR_UNREACHABLE            UcsStatus = -6
+	UCS_ERR_INVALID_ADDR           UcsStatus = -7
+	UCS_ERR_NOT_IMPLEMENTED        UcsStatus = -8
+	UCS_ERR_MESSAGE_TRUNCATED      UcsStatus = -9
+	UCS_ERR_NO_PROGRESS            UcsStatus = -10
+	UCS_ERR_BUFFER_TOO_SMALL       UcsStatus = -11
+	UCS_ERR_NO_ELEM                UcsStatus = -12
+	UCS_ERR_SOME_CONNECTS_FAILED   UcsStatus = -13
+	UCS_ERR_NO_DEVICE              UcsStatus = -14
+	UCS_ERR_BUSY                   UcsStatus = -15
+	UCS_ERR_CANCELED               UcsStatus = -16
+	UCS_ERR_SHMEM_SEGMENT          UcsStatus = -17
+	UCS_ERR_ALREADY_EXISTS         UcsStatus = -18
+	UCS_ERR_OUT_OF_RANGE           UcsStatus = -19
+	UCS_ERR_TIMED_OUT              UcsStatus = -20
+	UCS_ERR_EXCEEDS_LIMIT          UcsStatus = -21
+	UCS_ERR_UNSUPPORTED            UcsStatus = -22
+	UCS_ERR_REJECTED               UcsStatus = -23
+	UCS_ERR_NOT_CONNECTED          UcsStatus = -24
+	UCS_ERR_CONNECTION_RESET       UcsStatus = -25
+	UCS_ERR_FIRST_LINK_FAILURE     UcsStatus = -40
+	UCS_ERR_LAST_LINK_FAILURE      UcsStatus = -59
+	UCS_ERR_FIRST_ENDPOINT_FAILURE UcsStatus = -60
+	UCS_ERR_ENDPOINT_TIMEOUT       UcsStatus = -80
+	UCS_ERR_LAST_ENDPOINT_FAILURE  UcsStatus = -89
+	UCS_ERR_LAST                   UcsStatus = -100
+)
+
+func (m UcsStatus) String() string {
+	return fmt.Sprintf("UCS status %d", int(m))
+}
+
+func AllocateNativeMemory(size uint64) unsafe.Pointer {
+	return nil
+}
+
+func FreeNativeMemory(pointer unsafe.Pointer) {}
+
+func CBytes(data []byte) unsafe.Pointer {
+	return nil
+}
+
+func GoBytes(p unsafe.Pointer, n uint64) []byte {
+	return nil
+}
+
+type UcxVersion struct {
+	Major   uint
+	Minor   uint
+	Release uint
+}
+
+func (v UcxVersion) String() string {
+	return ""
+}
+
+func (v UcxVersion) AtLeast(major uint, minor uint) bool {
+	return false
+}
+
+type UcxVersionError struct {
+	Required UcxVersion
+	Actual   UcxVersion
+}
+
+func (e *UcxVersionError) Error() string {
+	return ""
+}
+
+func (e *UcxVersionError) Is(target error) bool {
+	return false
+}
+
+func Version() UcxVersion {
+	return UcxVersion{}
+}
+
+func CompiledVersion() UcxVersion {
+	return UcxVersion{}
+}
+
+func RequireMinVersion(major uint, minor uint) error {
+	return errUnsupportedPlatform
+}
+
+type UcxCapabilities struct {
+	Version     UcxVersion
+	Features    UcpFeatures
+	MemoryTypes uint64
+}
+
+func (c *UcxCapabilities) HasFeatures(features UcpFeatures) bool {
+	return false
+}
+
+func (c *UcxCapabilities) HasMemoryType(memType UcsMemoryType) bool {
+	return false
+}
+
+func ProbeCapabilities() (*UcxCapabilities, error) {
+	return nil, errUnsupportedPlatform
+}
+
+type UcpWorker struct {
+	worker       unsafe.Pointer
+	cpus         []int
+	resourcesMu  sync.Mutex
+	listeners    map[*UcpListener]struct{}
+	progressLoop progressDriver
+}
+
+type progressDriver interface {
+	detach(worker *UcpWorker)
+}
+
+type UcpAddress struct {
+	Address *struct{}
+	Length  uint64
+}
+
+type UcpTagRecvInfo struct {
+	SenderTag uint64
+	Length    uint64
+}
+
+type UcpTagMessage struct {
+	Info UcpTagRecvInfo
+}
+
+type UcpWorkerAddressAttributes struct {
+	WorkerUid uint64
+}
+
+type UcpWorkerAttributes struct {
+	ThreadMode     UcsThreadMode
+	Address        *UcpAddress
+	MaxAmHeader    uint64
+	Name           string
+	MaxDebugString uint64
+}
+
+func (w *UcpWorker) Close() {}
+
+func (w *UcpWorker) ThreadMode() UcsThreadMode {
+	return 0
+}
+
+func (a *UcpAddress) Close() {}
+
+func (a *UcpAddress) MarshalBinary() ([]byte, error) {
+	return nil, errUnsupportedPlatform
+}
+
+func (a *UcpAddress) UnmarshalBinary(data []byte) error {
+	return errUnsupportedPlatform
+}
+
+func (a *UcpAddress) Query(attrs ...UcpWorkerAddressAttribute) (*UcpWorkerAddressAttributes, error) {
+	return nil, errUnsupportedPlatform
+}
+
+func (w *UcpWorker) Query(attrs ...UcpWorkerAttribute) (*UcpWorkerAttributes, error) {
+	return nil, errUnsupportedPlatform
+}
+
+func (w *UcpWorker) Arm() UcsStatus {
+	return UCS_ERR_UNSUPPORTED
+}
+
+func (w *UcpWorker) Progress() uint {
+	return 0
+}
+
+func (w *UcpWorker) ProgressN(maxEvents int) int {
+	return 0
+}
+
+func (w *UcpWorker) ProgressFor(d time.Duration) int {
+	return 0
+}
+
+func (w *UcpWorker) Wait() error {
+	return errUnsupportedPlatform
+}
+
+func (w *UcpWorker) GetEfd() (int, error) {
+	return 0, errUnsupportedPlatform
+}
+
+func (w *UcpWorker) Signal() error {
+	return errUnsupportedPlatform
+}
+
+func (w *UcpWorker) Fence() error {
+	return errUnsupportedPlatform
+}
+
+func (w *UcpWorker) FlushNonBlocking(params *UcpRequestParams) (*UcpRequest, error) {
+	return nil, errUnsupportedPlatform
+}
+
+func (w *UcpWorker) GetAddress() (*UcpAddress, error) {
+	return nil, errUnsupportedPlatform
+}
+
+func (w *UcpWorker) GetAddressWithFlags(flags UcpWorkerAddressFlags) (*UcpAddress, error) {
+	return nil, errUnsupportedPlatform
+}
+
+func (w *UcpWorker) NewEndpoint(epParams *UcpEpParams) (*UcpEp, error) {
+	return nil, errUnsupportedPlatform
+}
+
+func (w *UcpWorker) NewEndpointFromConnRequest(connRequest *UcpConnectionRequest, epParams *UcpEpParams) (*UcpEp, error) {
+	return nil, errUnsupportedPlatform
+}
+
+func (w *UcpWorker) RecvTagNonBlocking(address unsafe.Pointer, size uint64, tag uint64, tagMask uint64, params *UcpRequestParams) (*UcpRequest, error) {
+	return nil, errUnsupportedPlatform
+}
+
+func (w *UcpWorker) RecvTagIovNonBlocking(iov []UcpIov, tag uint64, tagMask uint64, params *UcpRequestParams) (*UcpRequest, error) {
+	return nil, errUnsupportedPlatform
+}
+
+func (w *UcpWorker) TagProbe(tag uint64, tagMask uint64, remove bool) *UcpTagMessage {
+	return nil
+}
+
+func (w *UcpWorker) RecvTagMsgNonBlocking(address unsafe.Pointer, size uint64, message *UcpTagMessage, params *UcpRequestParams) (*UcpRequest, error) {
+	return nil, errUnsupportedPlatform
+}
+
+func (w *UcpWorker) NewListener(listenerParams *UcpListenerParams) (*UcpListener, error) {
+	return nil, errUnsupportedPlatform
+}
+
+func (w *UcpWorker) SetAmRecvHandler(id uint, flags UcpAmCbFlags, cb UcpAmRecvCallback) error {
+	return errUnsupportedPlatform
+}
+
+func (w *UcpWorker) SetAmRecvHandlerWithMode(id uint, flags UcpAmCbFlags, mode UcpAmDataMode, cb UcpAmRecvCallback) error {
+	return errUnsupportedPlatform
+}
+
+func (w *UcpWorker) SetAmHandler(params *UcpAmHandlerParams) error {
+	return errUnsupportedPlatform
+}
+
+func (w *UcpWorker) RemoveAmRecvHandler(id uint) error {
+	return errUnsupportedPlatform
+}
+
+func (w *UcpWorker) RecvAmDataNonBlocking(dataDesc *UcpAmData, recvBuffer unsafe.Pointer, size uint64, params *UcpRequestParams) (*UcpRequest, error) {
+	return nil, errUnsupportedPlatform
+}
+
+type UcpWorkerParams struct{}
+
+func (p *UcpWorkerParams) SetThreadMode(threadMode UcsThreadMode) *UcpWorkerParams {
+	return p
+}
+
+func (p *UcpWorkerParams) SetCpuMask(mask *big.Int) *UcpWorkerParams {
+	return p
+}
+
+func (p *UcpWorkerParams) SetCpus(cpus []int) *UcpWorkerParams {
+	return p
+}
+
+func (p *UcpWorkerParams) SetWakeupEvent(event UcpWakeupEvent) *UcpWorkerParams {
+	return p
+}
+
+func (p *UcpWorkerParams) WakeupRMA() *UcpWorkerParams {
+	return p
+}
+
+func (p *UcpWorkerParams) WakeupAMO() *UcpWorkerParams {
+	return p
+}
+
+func (p *UcpWorkerParams) WakeupTagSend() *UcpWorkerParams {
+	return p
+}
+
+func (p *UcpWorkerParams) WakeupTagRecv() *UcpWorkerParams {
+	return p
+}
+
+func (p *UcpWorkerParams) WakeupTX() *UcpWorkerParams {
+	return p
+}
+
+func (p *UcpWorkerParams) WakeupRX() *UcpWorkerParams {
+	return p
+}
+
+func (p *UcpWorkerParams) WakeupEdge() *UcpWorkerParams {
+	return p
+}
+
+func (p *UcpWorkerParams) SetUserData(data []byte) *UcpWorkerParams {
+	return p
+}
+
+func (p *UcpWorkerParams) SetEventFD(fd uintptr) *UcpWorkerParams {
+	return p
+}
+
+func (p *UcpWorkerParams) SetName(name string) *UcpWorkerParams {
+	return p
+}
+
+func (p *UcpWorkerParams) SetAmAlignment(alignment uint64) *UcpWorkerParams {
+	return p
+}
+
+func (p *UcpWorkerParams) SetClientId(clientId uint64) *UcpWorkerParams {
+	return p
+}
+
+func (p *UcpWorkerParams) SetRequestPoolSize(size int) *UcpWorkerParams {
+	return p
+}
+
+func (p *UcpWorkerParams) EnableTransferStats() *UcpWorkerParams {
+	return p
+}
+
+func (p *UcpWorkerParams) SetMemoryCacheCapacity(capacity int) *UcpWorkerParams {
+	return p
+}
+
+func (p *UcpWorkerParams) SetTracer(tracer UcpTracer) *UcpWorkerParams {
+	return p
+}
+
+type UcpWorkerSet struct{}
+
+func NewUcpWorkerSet(workers ...*UcpWorker) (*UcpWorkerSet, error) {
+	return nil, errUnsupportedPlatform
+}
+
+func (s *UcpWorkerSet) Execute(f func()) error {
+	return errUnsupportedPlatform
+}
+
+func (s *UcpWorkerSet) Add(worker *UcpWorker) error {
+	return errUnsupportedPlatform
+}
+
+func (s *UcpWorkerSet) Remove(worker *UcpWorker) error {
+	return errUnsupportedPlatform
+}
+
+func (s *UcpWorkerSet) Stop() {}
+
+func (w *UcpWorker) getEfdFile() (*os.File, error) {
+	return nil, errUnsupportedPlatform
+}
+
+func (w *UcpWorker) WaitEvents() error {
+	return errUnsupportedPlatform
+}
+
+func (w *UcpWorker) WaitTimeout(timeout time.Duration) (bool, error) {
+	return false, errUnsupportedPlatform
+}
+
+func (w *UcpWorker) StartProgress() (func(), error) {
+	return nil, errUnsupportedPlatform
+}
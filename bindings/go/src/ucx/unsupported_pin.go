@@ -0,0 +1,24 @@
+//go:build (!linux || !cgo) && !go1.21
+// +build !linux !cgo
+// +build !go1.21
+
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package ucx
+
+import (
+	"unsafe"
+)
+
+// The slices aren't passed to UCX on the unsupported platforms, so there is
+// nothing to pin, see pin_copy.go.
+func pinBytes(data []byte) (unsafe.Pointer, func()) {
+	return nil, func() {}
+}
+
+func pinRecvBytes(data []byte) (unsafe.Pointer, func()) {
+	return nil, func() {}
+}
@@ -1,3 +1,6 @@
+//go:build linux
+// +build linux
+
 /*
  * Copyright (C) 2021, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
  * See file LICENSE for terms.
@@ -15,6 +18,7 @@ import "C"
 import (
 	"net"
 	"runtime"
+	"strconv"
 	"syscall"
 	"unsafe"
 )
@@ -81,31 +85,64 @@ func family(a *net.TCPAddr) int {
 	return syscall.AF_INET6
 }
 
+// Converts the address of the IP network to TCP address, which is used by the
+// client-server connection flow.
+func toTcpAddrFromNetAddr(a net.Addr) (*net.TCPAddr, error) {
+	switch a := a.(type) {
+	case *net.TCPAddr:
+		return a, nil
+	case *net.UDPAddr:
+		return &net.TCPAddr{IP: a.IP, Port: a.Port, Zone: a.Zone}, nil
+	case *net.IPAddr:
+		return &net.TCPAddr{IP: a.IP, Zone: a.Zone}, nil
+	case nil:
+		return nil, &net.AddrError{Err: "address is not set"}
+	}
+	return nil, &net.AddrError{Err: "unsupported address type " + a.Network(), Addr: a.String()}
+}
+
+// Index of the network interface of the IPv6 link-local address.
+func zoneToIndex(zone string) C.uint32_t {
+	if zone == "" {
+		return 0
+	}
+
+	if ifi, err := net.InterfaceByName(zone); err == nil {
+		return C.uint32_t(ifi.Index)
+	}
+
+	index, _ := strconv.Atoi(zone)
+	return C.uint32_t(index)
+}
+
 // Convert GO TCP address to C
 func toSockAddr(a *net.TCPAddr) (*C.ucs_sock_addr_t, error) {
-	// We can't assing to ucs_sock_addr_t->addr reference to Go's memory,
-	// so need to allocate
-	sockaddrPtr := AllocateNativeMemory(C.sizeof_struct_sockaddr_storage)
-	sa := (*C.struct_sockaddr_storage)(sockaddrPtr)
 	var result C.ucs_sock_addr_t
 	if a == nil {
 		a = &net.TCPAddr{}
 	}
+
+	ip := a.IP
 	switch family(a) {
 	case syscall.AF_INET:
-		if len(a.IP) == 0 {
-			a.IP = net.IPv4zero
+		if len(ip) == 0 {
+			ip = net.IPv4zero
 		}
-		ip4 := a.IP.To4()
+		ip4 := ip.To4()
 		if ip4 == nil {
-			return nil, &net.AddrError{Err: "non-IPv4 address", Addr: a.IP.String()}
+			return nil, &net.AddrError{Err: "non-IPv4 address", Addr: ip.String()}
 		}
-		sa.ss_family = C.AF_INET
-		var sin *C.struct_sockaddr_in = (*C.struct_sockaddr_in)(unsafe.Pointer(sa))
+
+		// We can't assing to ucs_sock_addr_t->addr reference to Go's memory,
+		// so need to allocate
+		sin := (*C.struct_sockaddr_in)(AllocateNativeMemory(C.sizeof_struct_sockaddr_in))
+		C.memset(unsafe.Pointer(sin), 0, C.sizeof_struct_sockaddr_in)
+		sin.sin_family = C.AF_INET
 		sin.sin_port = C.htons(C.ushort(a.Port))
-		charBytes := C.CString(ip4.String())
-		C.inet_pton(C.AF_INET, charBytes, unsafe.Pointer(&sin.sin_addr.s_addr))
-		FreeNativeMemory(unsafe.Pointer(charBytes))
+		copy((*[net.IPv4len]byte)(unsafe.Pointer(&sin.sin_addr))[:], ip4)
+
+		result.addrlen = C.sizeof_struct_sockaddr_in
+		result.addr = (*C.struct_sockaddr)(unsafe.Pointer(sin))
 	case syscall.AF_INET6:
 		// In general, an IP wildcard address, which is either
 		// "0.0.0.0" or "::", means the entire IP addressing
@@ -117,26 +154,28 @@ func toSockAddr(a *net.TCPAddr) (*C.ucs_sock_addr_t, error) {
 		// we allow a listener to listen to the wildcard
 		// address of both IP addressing spaces by specifying
 		// IPv6 wildcard address.
-		if len(a.IP) == 0 || a.IP.Equal(net.IPv4zero) {
-			a.IP = net.IPv6zero
+		if len(ip) == 0 || ip.Equal(net.IPv4zero) {
+			ip = net.IPv6zero
 		}
 		// We accept any IPv6 address including IPv4-mapped
 		// IPv6 address.
-		ip6 := a.IP.To16()
+		ip6 := ip.To16()
 		if ip6 == nil {
-			return nil, &net.AddrError{Err: "non-IPv6 address", Addr: a.IP.String()}
+			return nil, &net.AddrError{Err: "non-IPv6 address", Addr: ip.String()}
 		}
-		sa.ss_family = C.AF_INET6
-		var sin6 *C.struct_sockaddr_in6 = (*C.struct_sockaddr_in6)(unsafe.Pointer(sa))
+
+		sin6 := (*C.struct_sockaddr_in6)(AllocateNativeMemory(C.sizeof_struct_sockaddr_in6))
+		C.memset(unsafe.Pointer(sin6), 0, C.sizeof_struct_sockaddr_in6)
+		sin6.sin6_family = C.AF_INET6
 		sin6.sin6_port = C.htons(C.ushort(a.Port))
-		charBytes := C.CString(ip6.String())
-		C.inet_pton(C.AF_INET6, charBytes, unsafe.Pointer(&sin6.sin6_addr))
-		FreeNativeMemory(unsafe.Pointer(charBytes))
+		sin6.sin6_scope_id = zoneToIndex(a.Zone)
+		copy((*[net.IPv6len]byte)(unsafe.Pointer(&sin6.sin6_addr))[:], ip6)
+
+		result.addrlen = C.sizeof_struct_sockaddr_in6
+		result.addr = (*C.struct_sockaddr)(unsafe.Pointer(sin6))
 	default:
-		return nil, &net.AddrError{Err: "invalid address family", Addr: a.IP.String()}
+		return nil, &net.AddrError{Err: "invalid address family", Addr: ip.String()}
 	}
-	result.addrlen = C.socklen_t(len(a.IP))
-	result.addr = (*C.struct_sockaddr)(unsafe.Pointer(sa))
 	return &result, nil
 }
 
@@ -146,11 +185,18 @@ func toTcpAddr(sockaddr *C.struct_sockaddr_storage) *net.TCPAddr {
 	if sockaddr.ss_family == C.AF_INET6 {
 		var sin6 *C.struct_sockaddr_in6 = (*C.struct_sockaddr_in6)(unsafe.Pointer(sockaddr))
 		result.Port = int(C.ntohs(sin6.sin6_port))
-		C.memcpy(unsafe.Pointer(&result.IP), unsafe.Pointer(&sin6.sin6_addr), 16)
+		result.IP = net.IP(C.GoBytes(unsafe.Pointer(&sin6.sin6_addr), net.IPv6len))
+		if sin6.sin6_scope_id != 0 {
+			if ifi, err := net.InterfaceByIndex(int(sin6.sin6_scope_id)); err == nil {
+				result.Zone = ifi.Name
+			} else {
+				result.Zone = strconv.Itoa(int(sin6.sin6_scope_id))
+			}
+		}
 	} else {
 		var sin *C.struct_sockaddr_in = (*C.struct_sockaddr_in)(unsafe.Pointer(sockaddr))
 		result.Port = int(C.ntohs(sin.sin_port))
-		C.memcpy(unsafe.Pointer(&result.IP), unsafe.Pointer(&sin.sin_addr), 4)
+		result.IP = net.IP(C.GoBytes(unsafe.Pointer(&sin.sin_addr), net.IPv4len))
 	}
 
 	return result
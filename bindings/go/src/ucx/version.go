@@ -0,0 +1,133 @@
+//go:build linux
+// +build linux
+
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package ucx
+
+// #include <ucp/api/ucp.h>
+import "C"
+import "fmt"
+
+// Version of the UCX library.
+type UcxVersion struct {
+	Major   uint
+	Minor   uint
+	Release uint
+}
+
+func (v UcxVersion) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Release)
+}
+
+// Reports whether the version is the same or newer than major.minor.
+func (v UcxVersion) AtLeast(major, minor uint) bool {
+	return (v.Major > major) || ((v.Major == major) && (v.Minor >= minor))
+}
+
+// Error of RequireMinVersion(), that matches ErrUnsupported by errors.Is().
+type UcxVersionError struct {
+	Required UcxVersion
+	Actual   UcxVersion
+}
+
+func (e *UcxVersionError) Error() string {
+	return fmt.Sprintf("UCX %d.%d is required, the library is %v", e.Required.Major, e.Required.Minor,
+		e.Actual)
+}
+
+func (e *UcxVersionError) Is(target error) bool {
+	return target == error(ErrUnsupported)
+}
+
+// This routine returns the version of the UCX library, that is loaded at
+// runtime, which may differ from the one, that the bindings were compiled
+// with, see CompiledVersion().
+func Version() UcxVersion {
+	var major, minor, release C.uint
+	C.ucp_get_version(&major, &minor, &release)
+	return UcxVersion{Major: uint(major), Minor: uint(minor), Release: uint(release)}
+}
+
+// Returns the version of the UCP API headers, that the bindings were compiled
+// with. The headers don't define the release number, so it's zero.
+func CompiledVersion() UcxVersion {
+	return UcxVersion{Major: C.UCP_API_MAJOR, Minor: C.UCP_API_MINOR}
+}
+
+// This routine returns UcxVersionError, unless the loaded UCX library is the
+// same or newer than major.minor, so the program fails early with the
+// explicit reason, or falls back to the older API.
+func RequireMinVersion(major, minor uint) error {
+	if version := Version(); !version.AtLeast(major, minor) {
+		return &UcxVersionError{Required: UcxVersion{Major: major, Minor: minor}, Actual: version}
+	}
+	return nil
+}
+
+// Capabilities of the loaded UCX library, see ProbeCapabilities().
+type UcxCapabilities struct {
+	Version UcxVersion
+
+	// Features, that the contexts can be created with
+	Features UcpFeatures
+
+	// Memory types, that the library supports with its loaded modules, e.g.
+	// UCS_MEMORY_TYPE_CUDA once UCX is built with CUDA and the CUDA driver is
+	// available. Checked by IsMemTypeSupported().
+	MemoryTypes uint64
+}
+
+// Reports whether all the features are available.
+func (c *UcxCapabilities) HasFeatures(features UcpFeatures) bool {
+	return (c.Features & features) == features
+}
+
+// Reports whether the library supports the memory type, e.g. whether the GPU
+// memory can be passed to the operations directly.
+func (c *UcxCapabilities) HasMemoryType(memType UcsMemoryType) bool {
+	return IsMemTypeSupported(memType, c.MemoryTypes)
+}
+
+// This routine probes the capabilities of the loaded UCX library by creating
+// the temporary contexts with every feature, so the programs degrade, e.g. to
+// the tag API without Active Messages or to the host staging of the GPU
+// buffers, rather than fail. The contexts are created with the configuration
+// of the environment, so the probe is done once, at the start of the program.
+func ProbeCapabilities() (*UcxCapabilities, error) {
+	result := &UcxCapabilities{Version: Version()}
+	for _, n := range featureNames {
+		params := &UcpParams{}
+		params.params.field_mask |= C.UCP_PARAM_FIELD_FEATURES
+		params.params.features = C.uint64_t(n.feature)
+		if n.feature == UCP_FEATURE_WAKEUP {
+			// Wake-up alone isn't a communication feature
+			params.EnableTag()
+		}
+
+		context, err := NewUcpContext(params)
+		if err != nil {
+			continue
+		}
+
+		if result.Features == 0 {
+			attrs, err := context.Query(UCP_ATTR_FIELD_MEMORY_TYPES)
+			if err != nil {
+				context.Close()
+				return nil, err
+			}
+			result.MemoryTypes = attrs.MemoryTypes
+		}
+
+		result.Features |= n.feature
+		context.Close()
+	}
+
+	if result.Features == 0 {
+		return nil, ErrUnsupported
+	}
+	return result, nil
+}
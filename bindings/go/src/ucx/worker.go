@@ -1,3 +1,6 @@
+//go:build linux
+// +build linux
+
 /*
  * Copyright (C) 2021, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
  * See file LICENSE for terms.
@@ -5,10 +8,14 @@
 
 package ucx
 
+// #include <stdlib.h>
 // #include <ucp/api/ucp.h>
 // #include "goucx.h"
 import "C"
 import (
+	"os"
+	"sync"
+	"time"
 	"unsafe"
 )
 
@@ -29,8 +36,35 @@ import (
 //
 // Worker are parallel "threading points" that an upper layer may use to
 // optimize concurrent communications.
+//
+// The worker and its endpoints can be used from multiple goroutines
+// concurrently only if the worker is created with UCS_THREAD_MODE_MULTI (see
+// UcpWorker.ThreadMode()). Otherwise all the calls have to be serialized by
+// user, e.g. by submitting them to UcpProgressLoop.Execute().
 type UcpWorker struct {
 	worker C.ucp_worker_h
+	// Active message id to the registered callback id
+	amHandlers   map[uint]uint64
+	amHandlersMu sync.Mutex
+	// Actual thread mode of the worker
+	threadMode UcsThreadMode
+	// CPUs, which the worker resources are allocated on
+	cpus []int
+	// Event file descriptor, registered in Go runtime poller by WaitEvents()
+	efdFile *os.File
+	// Resources, that are released by UcpContext.Shutdown()
+	context      *UcpContext
+	resourcesMu  sync.Mutex
+	listeners    map[*UcpListener]struct{}
+	progressLoop progressDriver
+	// Request params reserved for the worker, see UcpWorkerParams.SetRequestPoolSize()
+	reserved int
+}
+
+// Progress loop or worker set, that progresses the worker.
+type progressDriver interface {
+	// Stops progressing the worker
+	detach(worker *UcpWorker)
 }
 
 type UcpAddress struct {
@@ -44,27 +78,135 @@ type UcpTagRecvInfo struct {
 	Length    uint64
 }
 
+// Handle of the tag message, that was matched by UcpWorker.TagProbe().
+type UcpTagMessage struct {
+	message C.ucp_tag_message_h
+	Info    UcpTagRecvInfo
+}
+
+type UcpWorkerAddressAttributes struct {
+	// Unique id of the worker, which the address belongs to
+	WorkerUid uint64
+}
+
 type UcpWorkerAttributes struct {
 	ThreadMode     UcsThreadMode
 	Address        *UcpAddress
 	MaxAmHeader    uint64
+	Name           string
 	MaxDebugString uint64
 }
 
 func (w *UcpWorker) Close() {
+	if w.worker == nil {
+		// Already closed by UcpContext.Shutdown()
+		return
+	}
+
+	if w.efdFile != nil {
+		w.efdFile.Close()
+	}
 	C.ucp_worker_destroy(w.worker)
+	untrackResource(unsafe.Pointer(w.worker))
+	removeWorkerEndpoints(w.worker)
+	removeWorkerDeadlines(w.worker)
+	removeWorkerFeatures(w.worker)
+	removeTransferStats(w.worker)
+	removeTracer(w.worker)
+	removeWorkerMemoryCache(w.worker)
+	removeProbedRecvs(w.worker)
+	removePingService(w.worker)
+	removeHandshakeService(w.worker)
+	releaseRequestParams(w.reserved)
+	w.worker = nil
+
+	w.context.resourcesMu.Lock()
+	delete(w.context.workers, w)
+	w.context.resourcesMu.Unlock()
+
+	w.amHandlersMu.Lock()
+	defer w.amHandlersMu.Unlock()
+	for id := range w.amHandlers {
+		w.releaseAmRecvHandler(id)
+	}
+}
+
+// Returns the actual thread mode of the worker, which may differ from the one
+// requested by UcpWorkerParams.SetThreadMode().
+func (w *UcpWorker) ThreadMode() UcsThreadMode {
+	return w.threadMode
 }
 
 func (a *UcpAddress) Close() {
-	C.ucp_worker_release_address(a.worker, a.Address)
+	if a.worker == nil {
+		// The address was created by UcpAddress.UnmarshalBinary()
+		C.free(unsafe.Pointer(a.Address))
+	} else {
+		C.ucp_worker_release_address(a.worker, a.Address)
+	}
+	a.Address = nil
+}
+
+// Serializes the address, so it can be passed to the remote peer over any
+// out-of-band channel. The peer connects to the worker by
+// UcpEpParams.SetUcpAddressBytes() or by the address restored with
+// UcpAddress.UnmarshalBinary().
+func (a *UcpAddress) MarshalBinary() ([]byte, error) {
+	return C.GoBytes(unsafe.Pointer(a.Address), C.int(a.Length)), nil
+}
+
+// Restores the address serialized by UcpAddress.MarshalBinary(). The address
+// must be closed by UcpAddress.Close().
+func (a *UcpAddress) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 {
+		return NewUcxError(UCS_ERR_INVALID_PARAM)
+	}
+
+	a.worker = nil
+	a.Address = (*C.ucp_address_t)(C.CBytes(data))
+	a.Length = uint64(len(data))
+	return nil
+}
+
+// Fetches the attributes of the address, which can be either the address of
+// the local worker or the one received from the remote peer. For example, the
+// unique id of the worker tells if the addresses belong to the same worker.
+func (a *UcpAddress) Query(attrs ...UcpWorkerAddressAttribute) (*UcpWorkerAddressAttributes, error) {
+	var addressAttr C.ucp_worker_address_attr_t
+
+	for _, attr := range attrs {
+		addressAttr.field_mask |= C.uint64_t(attr)
+	}
+
+	if status := C.ucp_worker_address_query(a.Address, &addressAttr); status != C.UCS_OK {
+		return nil, newUcxError(status)
+	}
+
+	result := &UcpWorkerAddressAttributes{}
+
+	for _, attr := range attrs {
+		switch attr {
+		case UCP_WORKER_ADDRESS_ATTR_FIELD_UID:
+			result.WorkerUid = uint64(addressAttr.worker_uid)
+		}
+	}
+
+	return result, nil
 }
 
 func (w *UcpWorker) Query(attrs ...UcpWorkerAttribute) (*UcpWorkerAttributes, error) {
+	return w.query(0, attrs...)
+}
+
+// Queries the worker attributes, and the address of the details specified by
+// the address flags, if UCP_WORKER_ATTR_FIELD_ADDRESS_FLAGS is requested.
+func (w *UcpWorker) query(addressFlags UcpWorkerAddressFlags, attrs ...UcpWorkerAttribute) (*UcpWorkerAttributes, error) {
 	var workerAttr C.ucp_worker_attr_t
 
 	for _, attr := range attrs {
 		workerAttr.field_mask |= C.ulong(attr)
 	}
+	workerAttr.address_flags = C.uint32_t(addressFlags)
 
 	if status := C.ucp_worker_query(w.worker, &workerAttr); status != C.UCS_OK {
 		return nil, newUcxError(status)
@@ -84,6 +226,8 @@ func (w *UcpWorker) Query(attrs ...UcpWorkerAttribute) (*UcpWorkerAttributes, er
 			}
 		case UCP_WORKER_ATTR_FIELD_MAX_AM_HEADER:
 			result.MaxAmHeader = uint64(workerAttr.max_am_header)
+		case UCP_WORKER_ATTR_FIELD_NAME:
+			result.Name = C.GoString(&workerAttr.name[0])
 		case UCP_WORKER_ATTR_FIELD_MAX_INFO_STRING:
 			result.MaxDebugString = uint64(workerAttr.max_debug_string)
 		}
@@ -121,7 +265,40 @@ func (w *UcpWorker) Arm() UcsStatus {
 // routines. Nevertheless, the non-blocking routines can not be used for
 // communication progress.
 func (w *UcpWorker) Progress() uint {
-	return uint(C.ucp_worker_progress(w.worker))
+	return progressWorker(w.worker)
+}
+
+// This routine progresses the worker until maxEvents events are reported, or
+// until the progress reports no events, and returns the number of the
+// events. The single progress call may report several events, so the result
+// may exceed maxEvents by the events of the last call. Returns 0 without
+// progressing the worker, unless maxEvents is positive.
+func (w *UcpWorker) ProgressN(maxEvents int) int {
+	events := 0
+	for events < maxEvents {
+		count := int(progressWorker(w.worker))
+		if count == 0 {
+			break
+		}
+		events += count
+	}
+	return events
+}
+
+// This routine progresses the worker until the budget d elapses, or until the
+// progress reports no events, and returns the number of the events. The
+// worker is progressed at least once, and the budget is checked between the
+// progress calls, so the routine may overrun it by a single call.
+func (w *UcpWorker) ProgressFor(d time.Duration) int {
+	deadline := time.Now().Add(d)
+	events := 0
+	for {
+		count := int(progressWorker(w.worker))
+		events += count
+		if (count == 0) || !time.Now().Before(deadline) {
+			return events
+		}
+	}
 }
 
 // This routine waits (blocking) until an event has happened, as part of the
@@ -141,6 +318,10 @@ func (w *UcpWorker) Progress() uint {
 // notification and may not progress some of the requests as it would when
 // calling UcpWorker.Progress() (which is not invoked in that duration).
 func (w *UcpWorker) Wait() error {
+	if err := checkFeature(w.worker, UCP_FEATURE_WAKEUP); err != nil {
+		return err
+	}
+
 	if status := C.ucp_worker_wait(w.worker); status != C.UCS_OK {
 		return newUcxError(status)
 	}
@@ -166,6 +347,10 @@ func (w *UcpWorker) Wait() error {
 // UcpWorker.Wait() function for waiting on the next event internally.
 func (w *UcpWorker) GetEfd() (int, error) {
 	var efd C.int
+	if err := checkFeature(w.worker, UCP_FEATURE_WAKEUP); err != nil {
+		return 0, err
+	}
+
 	if status := C.ucp_worker_get_efd(w.worker, &efd); status != C.UCS_OK {
 		return 0, newUcxError(status)
 	}
@@ -176,6 +361,12 @@ func (w *UcpWorker) GetEfd() (int, error) {
 // mechanism. This function causes a blocking call to UcpWorker.Wait() or
 // waiting on a file descriptor from UcpWorker.GetEfd() to return, even
 // if no event from the underlying interfaces has taken place.
+//
+// It's safe to call this routine from any goroutine, regardless of the worker
+// thread mode. So the goroutine, that progresses the worker and waits for its
+// events, can execute the operations submitted by others: they enqueue the
+// operation and signal the worker. The signal, that happens before the wait,
+// is not lost, since the wait returns immediately then.
 func (w *UcpWorker) Signal() error {
 	if status := C.ucp_worker_signal(w.worker); status != C.UCS_OK {
 		return newUcxError(status)
@@ -183,6 +374,34 @@ func (w *UcpWorker) Signal() error {
 	return nil
 }
 
+// This routine orders the AMO and RMA operations, that are issued on the
+// worker after this call, after the ones issued before it, so they are
+// completed at the target only after the previous ones. Unlike the flush, it
+// doesn't wait for the completion of the previous operations, and doesn't
+// order them with the operations of the other communication primitives. The
+// routine is local, so it returns once the fence is recorded.
+func (w *UcpWorker) Fence() error {
+	if status := C.ucp_worker_fence(w.worker); status != C.UCS_OK {
+		return newUcxError(status)
+	}
+	return nil
+}
+
+// This routine flushes all outstanding AMO and RMA communications on the
+// worker, i.e. on all its endpoints. All the AMO and RMA operations issued on
+// the worker prior to this call are completed both at the origin and at the
+// target when the returned request is completed.
+func (w *UcpWorker) FlushNonBlocking(params *UcpRequestParams) (*UcpRequest, error) {
+	requestParams := getRequestParams()
+	defer putRequestParams(requestParams)
+
+	params = withTransfer(params, w.worker, nil, UcpTraceEvent{Op: UcpTraceFlush})
+	cbId, done := setSendParams(params, requestParams)
+
+	request := C.ucp_worker_flush_nbx(w.worker, requestParams)
+	return NewRequest(request, w.worker, cbId, done, nil)
+}
+
 // This routine returns the address of the worker object. This address can be
 // passed to remote instances of the UCP library in order to connect to this
 // worker. Ucp worker address - is an opaque object that is used as an
@@ -197,23 +416,131 @@ func (w *UcpWorker) GetAddress() (*UcpAddress, error) {
 	return result.Address, nil
 }
 
+// This routine returns the address of the worker with the details specified by
+// the flags. For example, the address of UCP_WORKER_ADDRESS_FLAG_NET_ONLY packs
+// the network devices only, so it's much shorter and suits the exchange over
+// constrained bootstrap channels, but can be used only by the peers on the
+// other nodes: the shared memory and loopback transports are not included.
+func (w *UcpWorker) GetAddressWithFlags(flags UcpWorkerAddressFlags) (*UcpAddress, error) {
+	result, err := w.query(flags, UCP_WORKER_ATTR_FIELD_ADDRESS, UCP_WORKER_ATTR_FIELD_ADDRESS_FLAGS)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Address, nil
+}
+
 // This routine creates new UcpEndpoint.
 func (w *UcpWorker) NewEndpoint(epParams *UcpEpParams) (*UcpEp, error) {
 	var ep C.ucp_ep_h
 
+	// The host is resolved by UcpWorker.Connect()
+	if epParams.hostAddress != "" {
+		return nil, ErrInvalidParam
+	}
+
+	if err := epParams.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Pass the worker to the error handler to bind the failed endpoint to it
+	epParams.params.err_handler.arg = unsafe.Pointer(w.worker)
+
+	if epParams.addressBytes != nil {
+		address := C.CBytes(epParams.addressBytes)
+		defer C.free(address)
+		epParams.params.address = (*C.ucp_address_t)(address)
+	}
+
+	if epParams.localAddr != nil {
+		localAddr, err := toSockAddr(epParams.localAddr)
+		if err != nil {
+			return nil, err
+		}
+		defer FreeNativeMemory(unsafe.Pointer(localAddr.addr))
+		epParams.params.local_sockaddr = *localAddr
+	}
+
+	// The connection request is released by the endpoint creation, even if it
+	// fails
+	if (epParams.params.field_mask&C.UCP_EP_PARAM_FIELD_CONN_REQUEST) != 0 &&
+		!takeConnRequest(epParams.params.conn_request) {
+		return nil, ErrInvalidParam
+	}
+
 	if status := C.ucp_ep_create(w.worker, &epParams.params, &ep); status != C.UCS_OK {
 		return nil, newUcxError(status)
 	}
 
 	if epParams.errorHandler != nil {
-		errorHandles[ep] = epParams.errorHandler
+		setErrorHandler(ep, epParams.errorHandler)
 	}
+	addEndpoint(ep, w.worker, epParams.userData)
 
 	return &UcpEp{
-		ep: ep,
+		ep:     ep,
+		worker: w.worker,
 	}, nil
 }
 
+// This routine creates new UcpEndpoint on the server side from a connection
+// request, which was passed to UcpListenerConnectionHandler. epParams may be
+// nil, otherwise its connection request is overwritten.
+func (w *UcpWorker) NewEndpointFromConnRequest(connRequest *UcpConnectionRequest,
+	epParams *UcpEpParams) (*UcpEp, error) {
+	if epParams == nil {
+		epParams = &UcpEpParams{}
+	}
+
+	return w.NewEndpoint(epParams.SetConnRequest(connRequest))
+}
+
+// Sets the native info of the tag receive, which the library fills on the
+// immediate completion, since the completion callback is not invoked then.
+// The info must be released after the operation call.
+func setTagRecvInfo(requestParams *C.ucp_request_param_t) *C.ucp_tag_recv_info_t {
+	recvInfo := (*C.ucp_tag_recv_info_t)(AllocateNativeMemory(C.sizeof_ucp_tag_recv_info_t))
+	*recvInfo = C.ucp_tag_recv_info_t{}
+
+	requestParams.op_attr_mask |= C.UCP_OP_ATTR_FIELD_RECV_INFO
+	recvInfoAddr := (**C.ucp_tag_recv_info_t)(unsafe.Pointer(&requestParams.recv_info[0]))
+	*recvInfoAddr = recvInfo
+	return recvInfo
+}
+
+func setTagRecvParams(goRequestParams *UcpRequestParams, cRequestParams *C.ucp_request_param_t) (uint64, chan UcsStatus) {
+	var cbId uint64
+	var done chan UcsStatus
+	if goRequestParams != nil {
+		setCommonParams(goRequestParams, cRequestParams)
+
+		cb := goRequestParams.Cb
+		if goRequestParams.doneChannel {
+			done = make(chan UcsStatus, 1)
+			userCb, _ := cb.(UcpTagRecvCallback)
+			cb = UcpTagRecvCallback(func(request *UcpRequest, status UcsStatus, tagInfo *UcpTagRecvInfo) {
+				if userCb != nil {
+					userCb(request, status, tagInfo)
+				}
+				done <- status
+			})
+		}
+
+		if (cb != nil) || (goRequestParams.release != nil) || !goRequestParams.deadline.IsZero() ||
+			(goRequestParams.transfer != nil) || (goRequestParams.userData != nil) {
+			cbId = registerRequest(cb, goRequestParams.release, goRequestParams.deadline,
+				goRequestParams.transfer, goRequestParams.userData)
+			cRequestParams.op_attr_mask |= C.UCP_OP_ATTR_FIELD_CALLBACK | C.UCP_OP_ATTR_FIELD_USER_DATA
+			cbAddr := (*C.ucp_tag_recv_nbx_callback_t)(unsafe.Pointer(&cRequestParams.cb[0]))
+			*cbAddr = (C.ucp_tag_recv_nbx_callback_t)(C.ucxgo_tag_recv_cb)
+			C.ucxgo_request_param_set_user_data(cRequestParams, C.uint64_t(cbId))
+		}
+	}
+
+	return cbId, done
+}
+
 // This routine receives a message that is described by the local address and size on the worker.
 // The tag value of the receive message has to match thetag and tagMask values,
 // where the tagMask indicates what bits of the tag have to be matched. The
@@ -225,100 +552,291 @@ func (w *UcpWorker) NewEndpoint(epParams *UcpEpParams) (*UcpEp, error) {
 // receive operation cannot be stated the routine returns an error.
 func (w *UcpWorker) RecvTagNonBlocking(address unsafe.Pointer, size uint64,
 	tag uint64, tagMask uint64, params *UcpRequestParams) (*UcpRequest, error) {
-	var requestParams C.ucp_request_param_t
-	var recvInfo C.ucp_tag_recv_info_t
-	var cbId uint64
+	if request, err := checkRequestFeature(w.worker, UCP_FEATURE_TAG, params); err != nil {
+		return request, err
+	}
 
-	requestParams.op_attr_mask = C.UCP_OP_ATTR_FIELD_RECV_INFO
-	recvInfoPtr := (*C.ucp_tag_recv_info_t)(unsafe.Pointer(&requestParams.recv_info[0]))
-	*recvInfoPtr = recvInfo
+	if (params != nil) && (params.truncation != UcpTruncationError) {
+		return w.recvTagProbed(address, size, tag, tagMask, params), nil
+	}
 
-	if params != nil {
-		setMemType(params, &requestParams)
+	requestParams := getRequestParams()
+	defer putRequestParams(requestParams)
+	recvInfo := setTagRecvInfo(requestParams)
+	defer FreeNativeMemory(unsafe.Pointer(recvInfo))
 
-		if params.Cb != nil {
-			cbId = register(params.Cb)
-			requestParams.op_attr_mask |= C.UCP_OP_ATTR_FIELD_CALLBACK | C.UCP_OP_ATTR_FIELD_USER_DATA
-			cbAddr := (*C.ucp_tag_recv_nbx_callback_t)(unsafe.Pointer(&requestParams.cb[0]))
-			*cbAddr = (C.ucp_tag_recv_nbx_callback_t)(C.ucxgo_completeGoTagRecvRequest)
-			requestParams.user_data = unsafe.Pointer(uintptr(cbId))
-		}
-	}
+	params = setCachedMemory(params, address, size, requestParams)
+	params = withTransfer(params, w.worker, nil, UcpTraceEvent{Op: UcpTraceTagRecv, Tag: tag,
+		TagMask: tagMask, Size: size})
+	cbId, done := setTagRecvParams(params, requestParams)
 
 	request := C.ucp_tag_recv_nbx(w.worker, address, C.size_t(size), C.ucp_tag_t(tag),
-		C.ucp_tag_t(tagMask), &requestParams)
+		C.ucp_tag_t(tagMask), requestParams)
+
+	return NewRequest(request, w.worker, cbId, done, &UcpTagRecvInfo{
+		SenderTag: uint64(recvInfo.sender_tag),
+		Length:    uint64(recvInfo.length),
+	})
+}
+
+// This routine receives a message to the buffers of the io vector, which are
+// filled one after another. It's the same as UcpWorker.RecvTagNonBlocking()
+// otherwise.
+func (w *UcpWorker) RecvTagIovNonBlocking(iov []UcpIov, tag uint64, tagMask uint64,
+	params *UcpRequestParams) (*UcpRequest, error) {
+	if request, err := checkRequestFeature(w.worker, UCP_FEATURE_TAG, params); err != nil {
+		return request, err
+	}
 
-	return NewRequest(request, cbId, &UcpTagRecvInfo{
+	requestParams := getRequestParams()
+	defer putRequestParams(requestParams)
+	recvInfo := setTagRecvInfo(requestParams)
+	defer FreeNativeMemory(unsafe.Pointer(recvInfo))
+
+	cIov := setIovParams(iov, requestParams)
+	params = withRelease(params, func() { C.free(cIov) })
+	params = withTransfer(params, w.worker, nil, UcpTraceEvent{Op: UcpTraceTagRecv, Tag: tag,
+		TagMask: tagMask, Size: iovLength(iov)})
+	cbId, done := setTagRecvParams(params, requestParams)
+
+	request := C.ucp_tag_recv_nbx(w.worker, cIov, C.size_t(len(iov)), C.ucp_tag_t(tag),
+		C.ucp_tag_t(tagMask), requestParams)
+
+	return NewRequest(request, w.worker, cbId, done, &UcpTagRecvInfo{
 		SenderTag: uint64(recvInfo.sender_tag),
 		Length:    uint64(recvInfo.length),
 	})
 }
 
+// This routine probes (checks) if a message described by the tag and
+// tagMask was received (fully or partially) on the worker. The tag value of
+// the received message has to match the tag and tagMask values, where the
+// tagMask indicates what bits of the tag have to be matched. The function
+// returns immediately and if the message is matched it returns a handle for
+// the message, with the sender tag and the length of the message. Otherwise
+// nil is returned.
+//
+// If remove is true, the message is removed from the worker unexpected queue
+// and the message data has to be received later with
+// UcpWorker.RecvTagMsgNonBlocking(). Otherwise the message stays in the queue
+// and can be received by UcpWorker.RecvTagNonBlocking(), when the size of the
+// receive buffer is known.
+func (w *UcpWorker) TagProbe(tag uint64, tagMask uint64, remove bool) *UcpTagMessage {
+	var recvInfo C.ucp_tag_recv_info_t
+	var cRemove C.int
+
+	if remove {
+		cRemove = 1
+	}
+
+	message := C.ucp_tag_probe_nb(w.worker, C.ucp_tag_t(tag), C.ucp_tag_t(tagMask), cRemove, &recvInfo)
+	if message == nil {
+		return nil
+	}
+
+	return &UcpTagMessage{
+		message: message,
+		Info: UcpTagRecvInfo{
+			SenderTag: uint64(recvInfo.sender_tag),
+			Length:    uint64(recvInfo.length),
+		},
+	}
+}
+
+// This routine receives a message, that was removed from the worker queue by
+// UcpWorker.TagProbe(), to the local buffer described by address and size.
+// The routine is a non-blocking and therefore returns immediately. The receive
+// operation is considered completed when the message is delivered to the buffer.
+// In order to notify the application about completion of the receive
+// operation the UCP library will invoke the call-back when the received
+// message is in the receive buffer and ready for application access.
+func (w *UcpWorker) RecvTagMsgNonBlocking(address unsafe.Pointer, size uint64,
+	message *UcpTagMessage, params *UcpRequestParams) (*UcpRequest, error) {
+	if request, err := checkRequestFeature(w.worker, UCP_FEATURE_TAG, params); err != nil {
+		return request, err
+	}
+
+	requestParams := getRequestParams()
+	defer putRequestParams(requestParams)
+
+	params = setCachedMemory(params, address, size, requestParams)
+	params = withTransfer(params, w.worker, nil, UcpTraceEvent{Op: UcpTraceTagRecv,
+		Tag: message.Info.SenderTag, TagMask: ^uint64(0), Size: size})
+	cbId, done := setTagRecvParams(params, requestParams)
+
+	request := C.ucp_tag_msg_recv_nbx(w.worker, address, C.size_t(size), message.message, requestParams)
+
+	info := message.Info
+	return NewRequest(request, w.worker, cbId, done, &info)
+}
+
 // This routine creates new UcpListener.
 func (w *UcpWorker) NewListener(listenerParams *UcpListenerParams) (*UcpListener, error) {
 	var listener C.ucp_listener_h
 
+	if err := listenerParams.Validate(); err != nil {
+		return nil, err
+	}
+
 	if status := C.ucp_listener_create(w.worker, &listenerParams.params, &listener); status != C.UCS_OK {
 		return nil, newUcxError(status)
 	}
 
-	connHandles2Listener[listenerParams.connHandlerId] = listener
+	setListenerByConnHandler(listenerParams.connHandlerId, listener)
+	trackResource("listener", unsafe.Pointer(listener))
+
+	result := &UcpListener{
+		listener:      listener,
+		connHandlerId: listenerParams.connHandlerId,
+		worker:        w,
+	}
+
+	w.resourcesMu.Lock()
+	defer w.resourcesMu.Unlock()
+	w.listeners[result] = struct{}{}
+	return result, nil
+}
 
-	return &UcpListener{listener, listenerParams.connHandlerId}, nil
+// Releases go callback that was registered for Active Message id
+// Must be called with amHandlersMu held.
+func (w *UcpWorker) releaseAmRecvHandler(id uint) {
+	if cbId, found := w.amHandlers[id]; found {
+		deregister(cbId)
+		setWorkerById(cbId, nil)
+		delete(w.amHandlers, id)
+	}
 }
 
 // This routine installs a user defined callback to handle incoming Active
 // Messages with a specific id. This callback is called whenever an Active
 // Message that was sent from the remote peer by UcpEndpoint.SendAm is
-// received on this worker.
+// received on this worker. Installing a callback for an id that already has
+// one replaces the previous callback, nil callback removes it.
 func (w *UcpWorker) SetAmRecvHandler(id uint, flags UcpAmCbFlags, cb UcpAmRecvCallback) error {
+	return w.SetAmRecvHandlerWithMode(id, flags, UcpAmDataModeDescriptor, cb)
+}
+
+// This routine installs the Active Message callback same as
+// UcpWorker.SetAmRecvHandler(), with the ownership mode of the received data:
+// either holding the library buffer until UcpAmData.Release(), or copying the
+// data to a Go slice, see UcpAmDataMode.
+func (w *UcpWorker) SetAmRecvHandlerWithMode(id uint, flags UcpAmCbFlags, mode UcpAmDataMode,
+	cb UcpAmRecvCallback) error {
+	return w.SetAmHandler((&UcpAmHandlerParams{}).SetId(id).SetFlags(flags).SetDataMode(mode).SetCallback(cb))
+}
+
+// This routine installs the Active Message handler described by the params.
+// The handler of the id, that is already installed, is replaced atomically:
+// the messages, that arrive after this call, are passed to the new callback
+// with the new argument, so the handlers are upgraded at runtime without
+// dropping the messages. Nil callback removes the handler.
+func (w *UcpWorker) SetAmHandler(params *UcpAmHandlerParams) error {
 	var amHandlerParams C.ucp_am_handler_param_t
-	cbId := register(cb)
-	idToWorker[cbId] = w
+	var cbId uint64
+
+	if err := checkFeature(w.worker, UCP_FEATURE_AM); err != nil {
+		return err
+	}
 
 	amHandlerParams.field_mask = C.UCP_AM_HANDLER_PARAM_FIELD_ID |
 		C.UCP_AM_HANDLER_PARAM_FIELD_FLAGS |
 		C.UCP_AM_HANDLER_PARAM_FIELD_CB |
 		C.UCP_AM_HANDLER_PARAM_FIELD_ARG
+	flags := params.flags
+	if params.mode == UcpAmDataModePersist {
+		flags |= UCP_AM_FLAG_PERSISTENT_DATA
+	}
+
+	id := params.id
 	amHandlerParams.id = C.uint(id)
-	amHandlerParams.arg = unsafe.Pointer(uintptr(cbId))
 	amHandlerParams.flags = C.uint32_t(flags)
-	cbAddr := (*C.ucp_am_recv_callback_t)(unsafe.Pointer(&amHandlerParams.cb))
-	*cbAddr = (C.ucp_am_recv_callback_t)(C.ucxgo_amRecvCallback)
+
+	w.amHandlersMu.Lock()
+	defer w.amHandlersMu.Unlock()
+
+	if params.cb != nil {
+		cbId = register(&amRecvHandler{id: id, cb: params.cb, mode: params.mode, arg: params.arg})
+		setWorkerById(cbId, w)
+		C.ucxgo_am_handler_param_set_arg(&amHandlerParams, C.uint64_t(cbId))
+		cbAddr := (*C.ucp_am_recv_callback_t)(unsafe.Pointer(&amHandlerParams.cb))
+		*cbAddr = (C.ucp_am_recv_callback_t)(C.ucxgo_am_recv_cb)
+	}
 
 	status := C.ucp_worker_set_am_recv_handler(w.worker, &amHandlerParams)
 	if status != C.UCS_OK {
+		if params.cb != nil {
+			deregister(cbId)
+			setWorkerById(cbId, nil)
+		}
 		return newUcxError(status)
 	}
 
+	w.releaseAmRecvHandler(id)
+	if params.cb != nil {
+		w.amHandlers[id] = cbId
+	}
+
 	return nil
 }
 
-// Receive Active Message as defined by provided data descriptor.
-func (w *UcpWorker) RecvAmDataNonBlocking(dataDesc *UcpAmData, recvBuffer unsafe.Pointer, size uint64,
-	params *UcpRequestParams) (*UcpRequest, error) {
-	var requestParams C.ucp_request_param_t
-	var cbId uint64
-	var length C.size_t
-
-	requestParams.op_attr_mask = C.UCP_OP_ATTR_FIELD_RECV_INFO
-	recvInfoPtr := (**C.size_t)(unsafe.Pointer(&requestParams.recv_info[0]))
-	*recvInfoPtr = &length
+// This routine removes the callback installed by UcpWorker.SetAmRecvHandler()
+// or UcpWorker.SetAmHandler() for Active Messages with a specific id. Messages with this id, that arrive
+// after this call, are dropped.
+func (w *UcpWorker) RemoveAmRecvHandler(id uint) error {
+	return w.SetAmRecvHandler(id, 0, nil)
+}
 
+func setAmRecvDataParams(params *UcpRequestParams, requestParams *C.ucp_request_param_t) (uint64, chan UcsStatus) {
+	var cbId uint64
+	var done chan UcsStatus
 	if params != nil {
-		setMemType(params, &requestParams)
+		setCommonParams(params, requestParams)
+
+		cb := params.Cb
+		if params.doneChannel {
+			done = make(chan UcsStatus, 1)
+			userCb, _ := cb.(UcpAmDataRecvCallback)
+			cb = UcpAmDataRecvCallback(func(request *UcpRequest, status UcsStatus, length uint64) {
+				if userCb != nil {
+					userCb(request, status, length)
+				}
+				done <- status
+			})
+		}
 
-		if params.Cb != nil {
-			cbId = register(params.Cb)
+		if (cb != nil) || (params.release != nil) || !params.deadline.IsZero() || (params.userData != nil) {
+			cbId = registerRequest(cb, params.release, params.deadline, nil, params.userData)
 			requestParams.op_attr_mask |= C.UCP_OP_ATTR_FIELD_CALLBACK | C.UCP_OP_ATTR_FIELD_USER_DATA
 			cbAddr := (*C.ucp_am_recv_data_nbx_callback_t)(unsafe.Pointer(&requestParams.cb[0]))
-			*cbAddr = (C.ucp_am_recv_data_nbx_callback_t)(C.ucxgo_completeAmRecvData)
+			*cbAddr = (C.ucp_am_recv_data_nbx_callback_t)(C.ucxgo_am_recv_data_cb)
 
-			requestParams.user_data = unsafe.Pointer(uintptr(cbId))
+			C.ucxgo_request_param_set_user_data(requestParams, C.uint64_t(cbId))
 		}
 	}
 
-	request := C.ucp_am_recv_data_nbx(w.worker, dataDesc.dataPtr, recvBuffer, C.size_t(size), &requestParams)
+	return cbId, done
+}
+
+// Receive Active Message as defined by provided data descriptor.
+func (w *UcpWorker) RecvAmDataNonBlocking(dataDesc *UcpAmData, recvBuffer unsafe.Pointer, size uint64,
+	params *UcpRequestParams) (*UcpRequest, error) {
+	requestParams := getRequestParams()
+	defer putRequestParams(requestParams)
+
+	// The length of the immediate completion is stored by the library, so it
+	// has to be in the native memory
+	length := (*C.size_t)(AllocateNativeMemory(C.sizeof_size_t))
+	defer FreeNativeMemory(unsafe.Pointer(length))
+	*length = 0
+
+	requestParams.op_attr_mask = C.UCP_OP_ATTR_FIELD_RECV_INFO
+	recvInfoPtr := (**C.size_t)(unsafe.Pointer(&requestParams.recv_info[0]))
+	*recvInfoPtr = length
+
+	params = setCachedMemory(params, recvBuffer, size, requestParams)
+	cbId, done := setAmRecvDataParams(params, requestParams)
+
+	request := C.ucp_am_recv_data_nbx(w.worker, dataDesc.dataPtr, recvBuffer, C.size_t(size), requestParams)
 
-	return NewRequest(request, cbId, length)
+	return NewRequest(request, w.worker, cbId, done, *length)
 }
@@ -1,3 +1,6 @@
+//go:build linux
+// +build linux
+
 /*
  * Copyright (C) 2021, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
  * See file LICENSE for terms.
@@ -21,7 +24,14 @@ import (
 
 // Tuning parameters for the UCP worker.
 type UcpWorkerParams struct {
-	params C.ucp_worker_params_t
+	params          C.ucp_worker_params_t
+	requestPoolSize int
+	// CPUs of the mask, that the progress loop can be pinned to
+	cpus          []int
+	transferStats bool
+	tracer        UcpTracer
+	// See UcpWorkerParams.SetMemoryCacheCapacity()
+	memoryCacheCapacity int
 }
 
 // The parameter thread_mode suggests the thread safety mode which worker
@@ -41,11 +51,26 @@ func (p *UcpWorkerParams) SetThreadMode(threadMode UcsThreadMode) *UcpWorkerPara
 // Mask of which CPUs worker resources should preferably be allocated on.
 // This value is optional. If it's not set, resources are allocated according to system's default policy.
 func (p *UcpWorkerParams) SetCpuMask(mask *big.Int) *UcpWorkerParams {
-	var cpu_mask C.ucs_cpu_set_t
-	C.cpu_zero(&cpu_mask)
+	var cpus []int
 	for i := 0; i < mask.BitLen(); i++ {
 		if mask.Bit(i) != 0 {
-			C.set_cpu(C.int(i), &cpu_mask)
+			cpus = append(cpus, i)
+		}
+	}
+	return p.SetCpus(cpus)
+}
+
+// Same as SetCpuMask(), with the CPUs listed by their ids, e.g. the cores of
+// the NUMA node close to the network device. The ids beyond the maximal
+// supported CPU count are ignored.
+func (p *UcpWorkerParams) SetCpus(cpus []int) *UcpWorkerParams {
+	var cpu_mask C.ucs_cpu_set_t
+	C.cpu_zero(&cpu_mask)
+	p.cpus = nil
+	for _, cpu := range cpus {
+		if (cpu >= 0) && (cpu < C.UCS_CPU_SETSIZE) {
+			C.set_cpu(C.int(cpu), &cpu_mask)
+			p.cpus = append(p.cpus, cpu)
 		}
 	}
 	p.params.cpu_mask = cpu_mask
@@ -151,3 +176,41 @@ func (p *UcpWorkerParams) SetClientId(clientId uint64) *UcpWorkerParams {
 	p.params.field_mask |= C.UCP_WORKER_PARAM_FIELD_CLIENT_ID
 	return p
 }
+
+// Number of the request params to reserve when the worker is created, so the
+// non-blocking operations, up to this number of the calls at once, don't
+// allocate the params, that are passed to C, even after the GC empties the
+// pools. The params are taken only during the call, the reserve is shared by
+// all workers and released once the worker is closed. The UcpRequest objects,
+// returned by the operations, are not pooled.
+func (p *UcpWorkerParams) SetRequestPoolSize(size int) *UcpWorkerParams {
+	p.requestPoolSize = size
+	return p
+}
+
+// Collects the transfer counters of the worker and of its endpoints, that are
+// returned by UcpWorker.Stats() and UcpEp.Stats(). The counters don't need UCX
+// to be built with the statistics support, but cost a few atomic updates on
+// every tag, stream, RMA and atomic operation.
+func (p *UcpWorkerParams) EnableTransferStats() *UcpWorkerParams {
+	p.transferStats = true
+	return p
+}
+
+// Registers the Go slices of the operations of the worker, e.g.
+// UcpEp.SendTagBytesNonBlocking() and UcpAmData.ReceiveBytes(), once, by the
+// memory cache, which keeps up to capacity unused registrations, see
+// UcpWorker.MemoryCache(). The capacity 0, which is the default, disables the
+// cache, so the library registers the slices of every operation as usual.
+func (p *UcpWorkerParams) SetMemoryCacheCapacity(capacity int) *UcpWorkerParams {
+	p.memoryCacheCapacity = capacity
+	return p
+}
+
+// Traces the tag, Active Message and stream operations of the worker by the
+// tracer, see UcpTracer. The operations of the traced worker always register
+// their completion callbacks, like the ones with the transfer counters.
+func (p *UcpWorkerParams) SetTracer(tracer UcpTracer) *UcpWorkerParams {
+	p.tracer = tracer
+	return p
+}
@@ -0,0 +1,305 @@
+//go:build linux && cgo
+// +build linux,cgo
+
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package ucx
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+	"syscall"
+)
+
+// Worker set progresses multiple workers on a single goroutine, which is
+// locked to its OS thread. The event file descriptors of the workers are
+// multiplexed by one epoll instance, so the goroutine sleeps while none of the
+// workers has events, and progresses only the workers, that got them. All the
+// workers must be created from contexts with UcpParams.EnableWakeup().
+//
+// Same as for UcpProgressLoop, the callbacks of the workers are invoked from
+// the set goroutine, and the workers must not be progressed by anyone else.
+// Operations on the workers with thread mode other than UCS_THREAD_MODE_MULTI
+// have to be submitted by UcpWorkerSet.Execute().
+type UcpWorkerSet struct {
+	epfd int
+	// Pipe to wake up the goroutine for the tasks and the stop
+	wakeupRead  int
+	wakeupWrite int
+	fdsMu       sync.Mutex
+	fdsClosed   bool
+	// Event file descriptor to the worker, accessed only by the goroutine
+	workers  map[int32]*UcpWorker
+	tasks    chan func()
+	quit     chan struct{}
+	exited   chan struct{}
+	stopOnce sync.Once
+}
+
+var errWorkerSetStopped = errors.New("worker set is stopped")
+
+// This routine creates the worker set and starts its goroutine, which
+// progresses the workers. More workers can be added later by
+// UcpWorkerSet.Add().
+func NewUcpWorkerSet(workers ...*UcpWorker) (*UcpWorkerSet, error) {
+	epfd, err := syscall.EpollCreate1(syscall.EPOLL_CLOEXEC)
+	if err != nil {
+		return nil, err
+	}
+
+	var wakeup [2]int
+	if err = syscall.Pipe2(wakeup[:], syscall.O_NONBLOCK|syscall.O_CLOEXEC); err != nil {
+		syscall.Close(epfd)
+		return nil, err
+	}
+
+	s := &UcpWorkerSet{
+		epfd:        epfd,
+		wakeupRead:  wakeup[0],
+		wakeupWrite: wakeup[1],
+		workers:     make(map[int32]*UcpWorker),
+		tasks:       make(chan func(), 64),
+		quit:        make(chan struct{}),
+		exited:      make(chan struct{}),
+	}
+
+	event := syscall.EpollEvent{Events: syscall.EPOLLIN, Fd: int32(s.wakeupRead)}
+	if err = syscall.EpollCtl(epfd, syscall.EPOLL_CTL_ADD, s.wakeupRead, &event); err != nil {
+		s.closeFds()
+		return nil, err
+	}
+
+	go s.run()
+
+	for _, worker := range workers {
+		if err = s.Add(worker); err != nil {
+			s.Stop()
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+func (s *UcpWorkerSet) closeFds() {
+	s.fdsMu.Lock()
+	defer s.fdsMu.Unlock()
+	s.fdsClosed = true
+	syscall.Close(s.epfd)
+	syscall.Close(s.wakeupRead)
+	syscall.Close(s.wakeupWrite)
+}
+
+// Wakes up the goroutine from the epoll wait.
+func (s *UcpWorkerSet) wakeup() {
+	s.fdsMu.Lock()
+	defer s.fdsMu.Unlock()
+	// The pipe is full only if the goroutine is already woken up
+	if !s.fdsClosed {
+		syscall.Write(s.wakeupWrite, []byte{0})
+	}
+}
+
+func (s *UcpWorkerSet) drainWakeup() {
+	var buf [64]byte
+	for {
+		if n, _ := syscall.Read(s.wakeupRead, buf[:]); n <= 0 {
+			return
+		}
+	}
+}
+
+// Executes the tasks until none is left. Returns false if the set is stopped.
+func (s *UcpWorkerSet) runTasks() bool {
+	for {
+		select {
+		case task := <-s.tasks:
+			task()
+		case <-s.quit:
+			s.drain()
+			return false
+		default:
+			return true
+		}
+	}
+}
+
+// Executes tasks, that were submitted before the set was stopped.
+func (s *UcpWorkerSet) drain() {
+	for {
+		select {
+		case task := <-s.tasks:
+			task()
+		default:
+			return
+		}
+	}
+}
+
+// Progresses the workers, until all of them are drained and armed, then waits
+// for the events of any of them. Only the workers, which file descriptors got
+// the events, are progressed after the wait.
+func (s *UcpWorkerSet) run() {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	defer close(s.exited)
+	defer s.closeFds()
+
+	events := make([]syscall.EpollEvent, 64)
+	ready := make(map[int32]bool)
+
+	for {
+		if !s.runTasks() {
+			return
+		}
+
+		// Added workers may have events before they are armed
+		for efd := range s.workers {
+			if _, found := ready[efd]; !found {
+				ready[efd] = true
+			}
+		}
+
+		for efd, pending := range ready {
+			worker, found := s.workers[efd]
+			if !found {
+				delete(ready, efd)
+				continue
+			}
+
+			if !pending || (worker.Progress() != 0) {
+				continue
+			}
+
+			if status := worker.Arm(); status == UCS_OK {
+				ready[efd] = false
+			} else if status != UCS_ERR_BUSY {
+				return
+			}
+		}
+
+		if s.hasPending(ready) {
+			continue
+		}
+
+		n, err := syscall.EpollWait(s.epfd, events, -1)
+		if err == syscall.EINTR {
+			continue
+		} else if err != nil {
+			return
+		}
+
+		for _, event := range events[:n] {
+			if event.Fd == int32(s.wakeupRead) {
+				s.drainWakeup()
+			} else {
+				ready[event.Fd] = true
+			}
+		}
+	}
+}
+
+func (s *UcpWorkerSet) hasPending(ready map[int32]bool) bool {
+	for _, pending := range ready {
+		if pending {
+			return true
+		}
+	}
+	return false
+}
+
+// This routine executes f on the set goroutine and waits for its completion,
+// same as UcpProgressLoop.Execute(). It must not be called from the worker
+// callbacks.
+func (s *UcpWorkerSet) Execute(f func()) error {
+	done := make(chan struct{})
+
+	select {
+	case s.tasks <- func() { f(); close(done) }:
+	case <-s.exited:
+		return errWorkerSetStopped
+	}
+
+	s.wakeup()
+
+	select {
+	case <-done:
+		return nil
+	case <-s.exited:
+		select {
+		case <-done:
+			return nil
+		default:
+			return errWorkerSetStopped
+		}
+	}
+}
+
+// This routine adds the worker to the set, which starts progressing it.
+// The worker must not be progressed by anyone else, including other sets and
+// progress loops.
+func (s *UcpWorkerSet) Add(worker *UcpWorker) error {
+	var err error
+	if execErr := s.Execute(func() {
+		var efd int
+		if efd, err = worker.GetEfd(); err != nil {
+			return
+		}
+
+		event := syscall.EpollEvent{Events: syscall.EPOLLIN, Fd: int32(efd)}
+		if err = syscall.EpollCtl(s.epfd, syscall.EPOLL_CTL_ADD, efd, &event); err != nil {
+			return
+		}
+		s.workers[int32(efd)] = worker
+	}); execErr != nil {
+		return execErr
+	}
+
+	if err != nil {
+		return err
+	}
+
+	worker.resourcesMu.Lock()
+	worker.progressLoop = s
+	worker.resourcesMu.Unlock()
+	return nil
+}
+
+// This routine removes the worker from the set, so it's no longer progressed
+// by the set. The worker has to be removed before it's closed.
+func (s *UcpWorkerSet) Remove(worker *UcpWorker) error {
+	if err := s.Execute(func() {
+		for efd, w := range s.workers {
+			if w == worker {
+				syscall.EpollCtl(s.epfd, syscall.EPOLL_CTL_DEL, int(efd), nil)
+				delete(s.workers, efd)
+			}
+		}
+	}); err != nil {
+		return err
+	}
+
+	worker.resourcesMu.Lock()
+	if worker.progressLoop == progressDriver(s) {
+		worker.progressLoop = nil
+	}
+	worker.resourcesMu.Unlock()
+	return nil
+}
+
+func (s *UcpWorkerSet) detach(worker *UcpWorker) {
+	s.Remove(worker)
+}
+
+// This routine stops progressing all the workers and waits for the set
+// goroutine to exit. It must not be called from the worker callbacks.
+func (s *UcpWorkerSet) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.quit)
+		s.wakeup()
+	})
+	<-s.exited
+}
@@ -0,0 +1,123 @@
+//go:build linux && cgo
+// +build linux,cgo
+
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package ucx
+
+import (
+	"errors"
+	"os"
+	"syscall"
+	"time"
+)
+
+// Returns the worker event file descriptor, registered in Go runtime network
+// poller. The descriptor is duplicated, so closing the file does not affect
+// the one owned by the worker.
+func (w *UcpWorker) getEfdFile() (*os.File, error) {
+	if w.efdFile != nil {
+		return w.efdFile, nil
+	}
+
+	efd, err := w.GetEfd()
+	if err != nil {
+		return nil, err
+	}
+
+	fd, err := syscall.Dup(efd)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only non-blocking descriptors are added to the runtime poller
+	if err = syscall.SetNonblock(fd, true); err != nil {
+		syscall.Close(fd)
+		return nil, err
+	}
+
+	w.efdFile = os.NewFile(uintptr(fd), "ucp_worker_efd")
+	return w.efdFile, nil
+}
+
+// This routine waits until an event has happened on the worker, as part of the
+// wake-up mechanism. Unlike UcpWorker.Wait(), the wait is done by Go runtime
+// network poller on the file descriptor from UcpWorker.GetEfd(), so the
+// calling goroutine does not occupy an OS thread while waiting.
+//
+// The worker is armed by this routine, so it returns immediately if there are
+// unprocessed events. One must drain all existing events before waiting by
+// calling UcpWorker.Progress() repeatedly until it returns 0.
+// The worker has to be created from the context with UcpParams.EnableWakeup()
+// and this routine must not be called concurrently on the same worker.
+func (w *UcpWorker) WaitEvents() error {
+	_, err := w.waitEvents(time.Time{})
+	return err
+}
+
+// This routine waits for the events on the worker same as
+// UcpWorker.WaitEvents(), but no longer than the timeout, so the waiting loop
+// can do periodic work, e.g. health checks, while there is no traffic.
+// Returns true if the events arrived, and false if the timeout expired. With
+// non-positive timeout it only checks the events, that are already pending.
+func (w *UcpWorker) WaitTimeout(timeout time.Duration) (bool, error) {
+	return w.waitEvents(time.Now().Add(timeout))
+}
+
+// Waits for the events until the deadline, zero deadline means no timeout.
+func (w *UcpWorker) waitEvents(deadline time.Time) (bool, error) {
+	efdFile, err := w.getEfdFile()
+	if err != nil {
+		return false, err
+	}
+
+	if status := w.Arm(); status == UCS_ERR_BUSY {
+		return true, nil
+	} else if status != UCS_OK {
+		return false, NewUcxError(status)
+	}
+
+	rawConn, err := efdFile.SyscallConn()
+	if err != nil {
+		return false, err
+	}
+
+	if !deadline.IsZero() {
+		if err = efdFile.SetReadDeadline(deadline); err != nil {
+			return false, err
+		}
+		defer efdFile.SetReadDeadline(time.Time{})
+	}
+
+	// The first invocation parks the goroutine until the descriptor is readable
+	waited := false
+	err = rawConn.Read(func(fd uintptr) bool {
+		if waited {
+			return true
+		}
+		waited = true
+		return false
+	})
+
+	if errors.Is(err, os.ErrDeadlineExceeded) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// This routine starts a goroutine, that progresses the worker, and sleeps by
+// UcpWorker.WaitEvents() when there is nothing to progress. The returned
+// function stops the goroutine and waits for it to exit. It's the progress
+// loop in UcpProgressModeEvent mode, see UcpWorker.StartProgressLoop().
+func (w *UcpWorker) StartProgress() (stop func(), err error) {
+	loop, err := w.StartProgressLoop((&UcpProgressLoopParams{}).SetMode(UcpProgressModeEvent))
+	if err != nil {
+		return nil, err
+	}
+	return loop.Stop, nil
+}
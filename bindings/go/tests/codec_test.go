@@ -0,0 +1,69 @@
+//go:build go1.18
+// +build go1.18
+
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package goucxtests
+
+import (
+	"context"
+	"strings"
+	"testing"
+	. "ucx"
+	"ucx/ucxcodec"
+)
+
+type codecMessage struct {
+	Id      int
+	Name    string
+	Payload []byte
+}
+
+func TestUcxCodecSendRecv(t *testing.T) {
+	const tag uint64 = 11
+
+	entity := prepareContext(t, nil)
+	entity.worker, _ = entity.context.NewWorker(&UcpWorkerParams{})
+	createSelfEp(entity)
+	defer entity.Close()
+
+	ctx := context.Background()
+	codec := ucxcodec.GobCodec[codecMessage]{}
+
+	for i, name := range []string{"small", strings.Repeat("large", 256)} {
+		sent := codecMessage{Id: i, Name: name, Payload: []byte(name)}
+		if err := ucxcodec.Send(ctx, entity.selfEp, tag, sent, codec); err != nil {
+			t.Fatalf("Failed to send value %v", err)
+		}
+
+		received, err := ucxcodec.Recv(ctx, entity.worker, tag, ^uint64(0), codec)
+		if err != nil {
+			t.Fatalf("Failed to receive value %v", err)
+		}
+
+		if received.Id != sent.Id || received.Name != sent.Name || string(received.Payload) != name {
+			t.Fatalf("Sent value %v != received value %v", sent, received)
+		}
+	}
+
+	stringCodec := ucxcodec.CodecFuncs[string]{
+		MarshalFunc: func(buf []byte, value string) ([]byte, error) {
+			return append(buf, value...), nil
+		},
+		UnmarshalFunc: func(data []byte, value *string) error {
+			*value = string(data)
+			return nil
+		},
+	}
+
+	if err := ucxcodec.Send(ctx, entity.selfEp, tag, "Hello GO", stringCodec); err != nil {
+		t.Fatalf("Failed to send string %v", err)
+	}
+
+	if received, _ := ucxcodec.Recv(ctx, entity.worker, tag, ^uint64(0), stringCodec); received != "Hello GO" {
+		t.Fatalf("Sent string Hello GO != received string %s", received)
+	}
+}
@@ -6,7 +6,12 @@
 package goucxtests
 
 import (
+	"context"
+	"errors"
+	"net"
+	"strings"
 	"testing"
+	"time"
 	. "ucx"
 )
 
@@ -22,5 +27,391 @@ func TestUcpContext(t *testing.T) {
 
 	ucpParams.SetName("Go test2")
 
+	attrs, err := context.Query(UCP_ATTR_FIELD_NAME, UCP_ATTR_FIELD_REQUEST_SIZE, UCP_ATTR_FIELD_MEMORY_TYPES)
+	if err != nil {
+		t.Fatalf("Failed to query a context %v", err)
+	}
+
+	if attrs.Name != "GO_Test" {
+		t.Fatalf("Context name %s != GO_Test", attrs.Name)
+	}
+
+	if !IsMemTypeSupported(UCS_MEMORY_TYPE_HOST, attrs.MemoryTypes) {
+		t.Fatalf("Host memory is not supported")
+	}
+
+	if features := context.Features(); features != UCP_FEATURE_STREAM {
+		t.Fatalf("Context features %v != requested", features)
+	}
+
 	context.Close()
 }
+
+func TestUcpContextFeatures(t *testing.T) {
+	features := UCP_FEATURE_TAG | UCP_FEATURE_WAKEUP
+	entity := prepareContext(t, (&UcpParams{}).EnableStream().SetFeatures(features))
+	if entity.context.Features() != features {
+		t.Fatalf("Context features %v != %v", entity.context.Features(), features)
+	}
+
+	if s := features.String(); s != "TAG|WAKEUP" {
+		t.Fatalf("Features string %v != TAG|WAKEUP", s)
+	}
+
+	entity.worker, _ = entity.context.NewWorker(&UcpWorkerParams{})
+	createSelfEp(entity)
+	defer entity.Close()
+
+	params := (&UcpRequestParams{}).SetCallback(UcpSendCallback(func(request *UcpRequest, status UcsStatus) {
+		t.Fatalf("Callback of the unsupported operation is invoked")
+	}))
+
+	buffer := []byte("unsupported")
+	request, err := entity.selfEp.SendStreamBytesNonBlocking(buffer, params)
+	var featureErr *UcpFeatureError
+	if !errors.As(err, &featureErr) || (featureErr.Feature != UCP_FEATURE_STREAM) ||
+		!errors.Is(err, ErrUnsupported) {
+		t.Fatalf("Error %v is not the stream feature error", err)
+	}
+
+	if request.GetStatus() != UCS_ERR_UNSUPPORTED {
+		t.Fatalf("Request status %v != %v", request.GetStatus(), UCS_ERR_UNSUPPORTED)
+	}
+	request.Close()
+
+	if err := entity.worker.SetAmRecvHandler(1, UCP_AM_FLAG_WHOLE_MSG, nil); !errors.Is(err, ErrUnsupported) {
+		t.Fatalf("Error %v != %v", err, ErrUnsupported)
+	}
+
+	if _, err := entity.worker.GetEfd(); err != nil {
+		t.Fatalf("Failed to get event fd of the requested feature %v", err)
+	}
+}
+
+func TestUcxLeakCheck(t *testing.T) {
+	SetLeakCheck(true)
+	defer SetLeakCheck(false)
+
+	ucpContext, err := NewUcpContext((&UcpParams{}).EnableTag())
+	if err != nil {
+		t.Fatalf("Failed to create a context %v", err)
+	}
+
+	ucpWorker, err := ucpContext.NewWorker(&UcpWorkerParams{})
+	if err != nil {
+		t.Fatalf("Failed to create a worker %v", err)
+	}
+
+	memory, _, err := ucpContext.AllocAndMap(4096, nil)
+	if err != nil {
+		t.Fatalf("Failed to allocate memory %v", err)
+	}
+
+	leaks := Leaks()
+	if len(leaks) != 3 {
+		t.Fatalf("Leaks %v != 3", len(leaks))
+	}
+
+	for i, resource := range []string{"context", "worker", "memory"} {
+		if (leaks[i].Resource != resource) || !strings.Contains(leaks[i].Stack, "TestUcxLeakCheck") {
+			t.Fatalf("Leak %v is not %v created by the test", leaks[i], resource)
+		}
+	}
+
+	memory.Close()
+	ucpWorker.Close()
+	if err := CheckLeaks(); (err == nil) || !strings.Contains(err.Error(), "1 UCX resources") {
+		t.Fatalf("Context leak is not reported: %v", err)
+	}
+
+	ucpContext.Close()
+	if err := CheckLeaks(); err != nil {
+		t.Fatalf("Closed resources are reported: %v", err)
+	}
+}
+
+func TestUcpConfig(t *testing.T) {
+	config, err := NewUcpConfig("", "")
+	if err != nil {
+		t.Fatalf("Failed to read a config %v", err)
+	}
+	defer config.Close()
+
+	if err := config.Modify("TLS", "self"); err != nil {
+		t.Fatalf("Failed to modify a config %v", err)
+	}
+
+	if err := config.Modify("NO_SUCH_SETTING", "1"); err == nil {
+		t.Fatalf("Modified unknown setting")
+	}
+
+	if err := config.SetNetDevices("all"); err != nil {
+		t.Fatalf("Failed to set network devices %v", err)
+	}
+
+	if err := config.SetMaxRails(2); err != nil {
+		t.Fatalf("Failed to set max rails %v", err)
+	}
+
+	printed := config.Print("Go test", UCS_CONFIG_PRINT_CONFIG)
+	for _, setting := range []string{"UCX_TLS=self", "UCX_MAX_EAGER_RAILS=2", "UCX_MAX_RNDV_RAILS=2"} {
+		if !strings.Contains(printed, setting) {
+			t.Fatalf("Modified setting %v is not printed: %s", setting, printed)
+		}
+	}
+
+	context, err := NewUcpContext((&UcpParams{}).EnableTag().SetConfig(config))
+	if err != nil {
+		t.Fatalf("Failed to create a context %v", err)
+	}
+
+	context.Close()
+}
+
+func TestUcpConfigProtocolThresholds(t *testing.T) {
+	config, err := NewUcpConfig("", "")
+	if err != nil {
+		t.Fatalf("Failed to read a config %v", err)
+	}
+	defer config.Close()
+
+	if err := config.SetRndvThreshold(64 << 10); err != nil {
+		t.Fatalf("Failed to set rendezvous threshold %v", err)
+	}
+
+	if err := config.SetRndvThresholds(UCS_MEMUNITS_AUTO, 128<<10); err != nil {
+		t.Fatalf("Failed to set rendezvous thresholds %v", err)
+	}
+
+	if err := config.SetZcopyThreshold(UCS_MEMUNITS_INF); err != nil {
+		t.Fatalf("Failed to set zero copy threshold %v", err)
+	}
+
+	if err := config.SetBcopyThreshold(256); err != nil {
+		t.Fatalf("Failed to set buffer copy threshold %v", err)
+	}
+
+	if err := config.SetRndvScheme(UCP_RNDV_SCHEME_GET_ZCOPY); err != nil {
+		t.Fatalf("Failed to set rendezvous scheme %v", err)
+	}
+
+	if err := config.SetRndvScheme("no_such_scheme"); !errors.Is(err, ErrInvalidParam) {
+		t.Fatalf("Unknown rendezvous scheme is set: %v", err)
+	}
+
+	context, err := NewUcpContext((&UcpParams{}).EnableTag().SetConfig(config))
+	if err != nil {
+		t.Fatalf("Failed to create a context %v", err)
+	}
+	defer context.Close()
+
+	var printed strings.Builder
+	if err := context.PrintConfig(&printed); err != nil {
+		t.Fatalf("Failed to print context config %v", err)
+	}
+
+	for _, setting := range []string{"UCX_ZCOPY_THRESH=inf", "UCX_BCOPY_THRESH=256", "UCX_RNDV_SCHEME=get_zcopy",
+		"UCP context"} {
+		if !strings.Contains(printed.String(), setting) {
+			t.Fatalf("Setting %v is not printed: %s", setting, printed.String())
+		}
+	}
+}
+
+func TestUcpContextShutdown(t *testing.T) {
+	ucpContext, err := NewUcpContext((&UcpParams{}).EnableTag())
+	if err != nil {
+		t.Fatalf("Failed to create a context %v", err)
+	}
+
+	worker, _ := ucpContext.NewWorker(&UcpWorkerParams{})
+	address, _ := worker.GetAddress()
+	ep, err := worker.NewEndpoint((&UcpEpParams{}).SetUcpAddress(address))
+	address.Close()
+	if err != nil {
+		t.Fatalf("Failed to create endpoint %v", err)
+	}
+
+	memory, _, err := ucpContext.AllocAndMap(1024, nil)
+	if err != nil {
+		t.Fatalf("Failed to allocate memory %v", err)
+	}
+
+	loop, err := worker.StartProgressLoop(nil)
+	if err != nil {
+		t.Fatalf("Failed to start progress loop %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := ucpContext.Shutdown(ctx); err != nil {
+		t.Fatalf("Failed to shutdown context %v", err)
+	}
+
+	if err := loop.Execute(func() {}); err == nil {
+		t.Fatalf("Progress loop is still running after shutdown")
+	}
+
+	// Released resources can still be closed in any order
+	closeRequest, err := ep.CloseNonBlockingForce(nil)
+	if err != nil || closeRequest.GetStatus() != UCS_OK {
+		t.Fatalf("Closing endpoint after shutdown failed %v", err)
+	}
+	closeRequest.Close()
+	memory.Close()
+	worker.Close()
+	ucpContext.Close()
+
+	if err := ucpContext.Shutdown(ctx); err != nil {
+		t.Fatalf("Repeated shutdown failed %v", err)
+	}
+}
+
+func TestUcpTagSpace(t *testing.T) {
+	const senderMask uint64 = 0xff00
+	ucpContext, err := NewUcpContext((&UcpParams{}).EnableTag().SetTagSenderMask(senderMask))
+	if err != nil {
+		t.Fatalf("Failed to create a context %v", err)
+	}
+	defer ucpContext.Close()
+
+	if ucpContext.TagSenderMask() != senderMask {
+		t.Fatalf("Tag sender mask %x != %x", ucpContext.TagSenderMask(), senderMask)
+	}
+
+	if _, err := NewUcpTagSpace(ucpContext, 56); !errors.Is(err, ErrInvalidParam) {
+		t.Fatalf("Tag space overlapping sender mask is created: %v", err)
+	}
+
+	space, err := NewUcpTagSpace(ucpContext, 8)
+	if err != nil {
+		t.Fatalf("Failed to create tag space %v", err)
+	}
+
+	rpc, _ := space.Allocate("rpc")
+	coll, _ := space.Allocate("coll")
+	if _, err := space.Allocate("rpc"); !errors.Is(err, ErrAlreadyExists) {
+		t.Fatalf("Range is allocated twice: %v", err)
+	}
+
+	if (rpc.Id() == coll.Id()) || (space.Lookup("coll") != coll) {
+		t.Fatalf("Ranges %v and %v are not distinct", rpc.Id(), coll.Id())
+	}
+
+	tag, err := rpc.Tag(0x1ff)
+	if err != nil {
+		t.Fatalf("Failed to get tag %v", err)
+	}
+
+	// The value skips the sender bits
+	if (tag&senderMask != 0) || !rpc.Contains(tag) || coll.Contains(tag) || (rpc.Value(tag) != 0x1ff) {
+		t.Fatalf("Tag %x doesn't carry value of range %v", tag, rpc.Id())
+	}
+
+	matchTag, matchMask, _ := rpc.Match(0x1ff)
+	if (tag|senderMask)&matchMask != matchTag {
+		t.Fatalf("Tag %x from any sender doesn't match %x/%x", tag, matchTag, matchMask)
+	}
+
+	if _, err := rpc.Tag(rpc.Capacity()); !errors.Is(err, ErrOutOfRange) {
+		t.Fatalf("Value beyond capacity is accepted: %v", err)
+	}
+
+	anyTag, anyMask := coll.Any()
+	if tag&anyMask == anyTag {
+		t.Fatalf("Tag %x of range %v matches range %v", tag, rpc.Id(), coll.Id())
+	}
+
+	rpc.Release()
+	if _, err := space.AllocateId("rpc2", rpc.Id()); err != nil {
+		t.Fatalf("Released range is not reused %v", err)
+	}
+}
+
+func TestUcpParamsValidate(t *testing.T) {
+	var paramsErr *UcpParamsError
+	if _, err := NewUcpContext(&UcpParams{}); !errors.As(err, &paramsErr) || !errors.Is(err, ErrInvalidParam) {
+		t.Fatalf("Context without features returned %v", err)
+	}
+
+	ucpContext, err := NewUcpContext((&UcpParams{}).EnableTag())
+	if err != nil {
+		t.Fatalf("Failed to create a context %v", err)
+	}
+	defer ucpContext.Close()
+
+	if _, err := ucpContext.NewWorker((&UcpWorkerParams{}).WakeupTX()); !errors.Is(err, ErrInvalidParam) {
+		t.Fatalf("Wake-up worker of context without UCP_FEATURE_WAKEUP returned %v", err)
+	}
+
+	if err := (&UcpWorkerParams{}).SetAmAlignment(3).Validate(); !errors.Is(err, ErrInvalidParam) {
+		t.Fatalf("AM alignment 3 returned %v", err)
+	}
+
+	worker, err := ucpContext.NewWorker(&UcpWorkerParams{})
+	if err != nil {
+		t.Fatalf("Failed to create a worker %v", err)
+	}
+	defer worker.Close()
+
+	address, _ := worker.GetAddress()
+	defer address.Close()
+	addr, _ := net.ResolveTCPAddr("tcp", "127.0.0.1:13337")
+	socketParams, _ := (&UcpEpParams{}).SetSocketAddress(addr)
+
+	for _, c := range []struct {
+		name   string
+		params *UcpEpParams
+	}{
+		{"no destination", &UcpEpParams{}},
+		{"two destinations", func() *UcpEpParams {
+			params, _ := (&UcpEpParams{}).SetUcpAddress(address).SetSocketAddress(addr)
+			return params
+		}()},
+		{"client id without socket address", (&UcpEpParams{}).SetUcpAddress(address).SendClientId()},
+		{"error handler without error handling", socketParams.SetErrorHandlingMode(UCP_ERR_HANDLING_MODE_NONE).
+			SetErrorHandler(func(ep *UcpEp, status UcsStatus) {})},
+	} {
+		if _, err := worker.NewEndpoint(c.params); !errors.Is(err, ErrInvalidParam) {
+			t.Fatalf("Endpoint with %v returned %v", c.name, err)
+		}
+	}
+
+	if err := (&UcpEpParams{}).SetUcpAddress(address).Validate(); err != nil {
+		t.Fatalf("Valid endpoint params returned %v", err)
+	}
+
+	listenerParams, _ := (&UcpListenerParams{}).SetSocketAddress(addr)
+	if _, err := worker.NewListener(listenerParams); !errors.Is(err, ErrInvalidParam) {
+		t.Fatalf("Listener without connection handler returned %v", err)
+	}
+}
+
+func TestUcxVersion(t *testing.T) {
+	version := Version()
+	if (version.Major == 0) && (version.Minor == 0) {
+		t.Fatalf("Unexpected library version %v", version)
+	}
+
+	if compiled := CompiledVersion(); !version.AtLeast(compiled.Major, compiled.Minor) {
+		t.Logf("Library %v is older than the headers %v", version, compiled)
+	}
+
+	if err := RequireMinVersion(version.Major, version.Minor); err != nil {
+		t.Fatalf("Library doesn't satisfy its own version: %v", err)
+	}
+
+	if err := RequireMinVersion(version.Major+1, 0); !errors.Is(err, ErrUnsupported) {
+		t.Fatalf("Newer version requirement returned %v", err)
+	}
+
+	capabilities, err := ProbeCapabilities()
+	if err != nil {
+		t.Fatalf("Failed to probe capabilities %v", err)
+	}
+
+	if !capabilities.HasFeatures(UCP_FEATURE_TAG|UCP_FEATURE_AM) ||
+		!capabilities.HasMemoryType(UCS_MEMORY_TYPE_HOST) {
+		t.Fatalf("Unexpected capabilities %+v", capabilities)
+	}
+}
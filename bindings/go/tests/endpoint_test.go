@@ -5,9 +5,14 @@
 package goucxtests
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
+	"runtime"
+	"strings"
 	"testing"
+	"time"
 	. "ucx"
 	"unsafe"
 	. "cuda"
@@ -292,3 +297,1269 @@ func TestUcpEpAm(t *testing.T) {
 		receiver.Close()
 	}
 }
+
+func TestUcpEpRma(t *testing.T) {
+	const sendData string = "Hello GO RMA"
+	const dataLen uint64 = uint64(len(sendData))
+
+	for _, memType := range get_mem_types() {
+		ucpParams := (&UcpParams{}).EnableRMA().EnableTag()
+		sender := prepareContext(t, ucpParams)
+		receiver := prepareContext(t, ucpParams)
+		t.Logf("Testing RMA %v -> %v", memType.senderMemType, memType.recvMemType)
+
+		ucpWorkerParams := (&UcpWorkerParams{}).SetThreadMode(UCS_THREAD_MODE_SERIALIZED)
+		receiver.worker, _ = receiver.context.NewWorker(ucpWorkerParams)
+		sender.worker, _ = sender.context.NewWorker(ucpWorkerParams)
+		connect(sender, receiver)
+
+		sendMem := memoryAllocate(sender, dataLen, memType.senderMemType)
+		memorySet(sender, []byte(sendData))
+		remoteMem := memoryAllocate(receiver, 4096, memType.recvMemType)
+
+		rkeyBuffer, err := receiver.mem.RkeyPack()
+		if err != nil {
+			t.Fatalf("Failed to pack rkey %v", err)
+		}
+
+		rkey, err := sender.ep.UnpackRkey(rkeyBuffer)
+		if err != nil {
+			t.Fatalf("Failed to unpack rkey %v", err)
+		}
+
+		putRequest, err := sender.ep.RmaPutNonBlocking(sendMem, dataLen, uint64(uintptr(remoteMem)), rkey,
+			(&UcpRequestParams{}).SetMemType(memType.senderMemType))
+		if err != nil {
+			t.Fatalf("Failed to put %v", err)
+		}
+
+		flushRequest, _ := sender.ep.FlushNonBlocking(nil)
+		for (putRequest.GetStatus() == UCS_INPROGRESS) || (flushRequest.GetStatus() == UCS_INPROGRESS) {
+			sender.worker.Progress()
+			receiver.worker.Progress()
+		}
+		putRequest.Close()
+		flushRequest.Close()
+
+		if recvString := string(memoryGet(receiver)[:dataLen]); recvString != sendData {
+			t.Fatalf("Put data %s != remote data %s", sendData, recvString)
+		}
+
+		if localPtr, err := rkey.Ptr(uint64(uintptr(remoteMem))); err == nil {
+			if memType.recvMemType == UCS_MEMORY_TYPE_HOST {
+				if ptrString := string(GoBytes(localPtr, dataLen)); ptrString != sendData {
+					t.Fatalf("Put data %s != directly accessed data %s", sendData, ptrString)
+				}
+			}
+		} else if !errors.Is(err, NewUcxError(UCS_ERR_UNREACHABLE)) {
+			t.Fatalf("Failed to get pointer to remote memory %v", err)
+		}
+
+		getMem := AllocateNativeMemory(dataLen)
+		getRequest, err := sender.ep.RmaGetNonBlocking(getMem, dataLen, uint64(uintptr(remoteMem)), rkey, nil)
+		if err != nil {
+			t.Fatalf("Failed to get %v", err)
+		}
+
+		for getRequest.GetStatus() == UCS_INPROGRESS {
+			sender.worker.Progress()
+			receiver.worker.Progress()
+		}
+		getRequest.Close()
+
+		if getString := string(GoBytes(getMem, dataLen)); getString != sendData {
+			t.Fatalf("Remote data %s != get data %s", sendData, getString)
+		}
+		FreeNativeMemory(getMem)
+
+		putRequest, _ = sender.ep.RmaPutNonBlocking(sendMem, dataLen, uint64(uintptr(remoteMem)), rkey,
+			(&UcpRequestParams{}).SetMemType(memType.senderMemType))
+		workerFlushRequest, err := sender.worker.FlushNonBlocking(nil)
+		if err != nil {
+			t.Fatalf("Failed to flush worker %v", err)
+		}
+
+		for (putRequest.GetStatus() == UCS_INPROGRESS) || (workerFlushRequest.GetStatus() == UCS_INPROGRESS) {
+			sender.worker.Progress()
+			receiver.worker.Progress()
+		}
+		putRequest.Close()
+		workerFlushRequest.Close()
+
+		rkey.Close()
+		closeReq, _ := sender.ep.CloseNonBlockingFlush(nil)
+		for closeReq.GetStatus() == UCS_INPROGRESS {
+			sender.worker.Progress()
+			receiver.worker.Progress()
+		}
+		closeReq.Close()
+
+		sender.Close()
+		receiver.Close()
+	}
+}
+
+func TestUcpEpAtomic(t *testing.T) {
+	const opSize uint64 = 8
+	ucpParams := (&UcpParams{}).EnableAtomic64Bit().EnableTag()
+	sender := prepareContext(t, ucpParams)
+	receiver := prepareContext(t, ucpParams)
+
+	ucpWorkerParams := (&UcpWorkerParams{}).SetThreadMode(UCS_THREAD_MODE_SERIALIZED)
+	receiver.worker, _ = receiver.context.NewWorker(ucpWorkerParams)
+	sender.worker, _ = sender.context.NewWorker(ucpWorkerParams)
+	connect(sender, receiver)
+
+	remoteMem := memoryAllocate(receiver, opSize, UCS_MEMORY_TYPE_HOST)
+	*(*uint64)(remoteMem) = 1
+	remoteAddr := uint64(uintptr(remoteMem))
+
+	rkeyBuffer, _ := receiver.mem.RkeyPack()
+	rkey, err := sender.ep.UnpackRkey(rkeyBuffer)
+	if err != nil {
+		t.Fatalf("Failed to unpack rkey %v", err)
+	}
+
+	operand := AllocateNativeMemory(opSize)
+	defer FreeNativeMemory(operand)
+	result := AllocateNativeMemory(opSize)
+	defer FreeNativeMemory(result)
+
+	waitAtomic := func(request *UcpRequest, err error) {
+		if err != nil {
+			t.Fatalf("Atomic operation failed %v", err)
+		}
+		for request.GetStatus() == UCS_INPROGRESS {
+			sender.worker.Progress()
+			receiver.worker.Progress()
+		}
+		request.Close()
+	}
+
+	// Fetch and add: remote 1 -> 3, result 1
+	*(*uint64)(operand) = 2
+	waitAtomic(sender.ep.AtomicNonBlocking(UCP_ATOMIC_OP_ADD, operand, opSize, remoteAddr, rkey,
+		(&UcpRequestParams{}).SetReplyBuffer(result)))
+	if fetched := *(*uint64)(result); fetched != 1 {
+		t.Fatalf("Fetched value %v != 1", fetched)
+	}
+
+	// Compare and swap: remote 3 -> 10, result 3
+	*(*uint64)(operand) = 3
+	*(*uint64)(result) = 10
+	waitAtomic(sender.ep.AtomicNonBlocking(UCP_ATOMIC_OP_CSWAP, operand, opSize, remoteAddr, rkey,
+		(&UcpRequestParams{}).SetReplyBuffer(result)))
+	if fetched := *(*uint64)(result); fetched != 3 {
+		t.Fatalf("Fetched value %v != 3", fetched)
+	}
+
+	// Post xor without fetching: remote 10 -> 15
+	*(*uint64)(operand) = 5
+	waitAtomic(sender.ep.AtomicNonBlocking(UCP_ATOMIC_OP_XOR, operand, opSize, remoteAddr, rkey, nil))
+	waitAtomic(sender.ep.FlushNonBlocking(nil))
+	if remote := *(*uint64)(remoteMem); remote != 15 {
+		t.Fatalf("Remote value %v != 15", remote)
+	}
+
+	// Value based fetch and add: remote 15 -> 16, result 15
+	var fetched uint64
+	waitAtomic(sender.ep.Atomic64(UCP_ATOMIC_OP_ADD, 1, remoteAddr, rkey, &fetched, nil))
+	if fetched != 15 {
+		t.Fatalf("Fetched value %v != 15", fetched)
+	}
+
+	// Value based compare and swap: remote 16 -> 20, result 16
+	fetched = 20
+	waitAtomic(sender.ep.Atomic64(UCP_ATOMIC_OP_CSWAP, 16, remoteAddr, rkey, &fetched, nil))
+	if remote := *(*uint64)(remoteMem); (fetched != 16) || (remote != 20) {
+		t.Fatalf("Fetched value %v != 16 or remote value %v != 20", fetched, remote)
+	}
+
+	if _, err := sender.ep.AtomicNonBlocking(UCP_ATOMIC_OP_ADD, operand, 2, remoteAddr, rkey,
+		nil); !errors.Is(err, ErrInvalidParam) {
+		t.Fatalf("Atomic operation of 2 bytes operand error %v isn't ErrInvalidParam", err)
+	}
+
+	rkey.Close()
+	closeReq, _ := sender.ep.CloseNonBlockingFlush(nil)
+	for closeReq.GetStatus() == UCS_INPROGRESS {
+		sender.worker.Progress()
+		receiver.worker.Progress()
+	}
+	closeReq.Close()
+
+	sender.Close()
+	receiver.Close()
+}
+
+func TestUcpAmRecvHandlerReplace(t *testing.T) {
+	entity := prepareContext(t, nil)
+	entity.worker, _ = entity.context.NewWorker(&UcpWorkerParams{})
+	createSelfEp(entity)
+
+	oldCalled := false
+	newCalled := false
+	entity.worker.SetAmRecvHandler(1, UCP_AM_FLAG_WHOLE_MSG, func(header unsafe.Pointer, headerSize uint64,
+		data *UcpAmData, replyEp *UcpEp) UcsStatus {
+		oldCalled = true
+		return UCS_OK
+	})
+
+	if err := entity.worker.SetAmRecvHandler(1, UCP_AM_FLAG_WHOLE_MSG, func(header unsafe.Pointer, headerSize uint64,
+		data *UcpAmData, replyEp *UcpEp) UcsStatus {
+		newCalled = true
+		return UCS_OK
+	}); err != nil {
+		t.Fatalf("Failed to replace AM handler %v", err)
+	}
+
+	sendReq, _ := entity.selfEp.SendAmNonBlocking(1, nil, 0, nil, 0, UCP_AM_SEND_FLAG_EAGER, nil)
+	for !newCalled {
+		entity.worker.Progress()
+	}
+	for sendReq.GetStatus() == UCS_INPROGRESS {
+		entity.worker.Progress()
+	}
+	sendReq.Close()
+
+	if oldCalled {
+		t.Fatalf("Replaced AM handler was called")
+	}
+
+	if err := entity.worker.RemoveAmRecvHandler(1); err != nil {
+		t.Fatalf("Failed to remove AM handler %v", err)
+	}
+
+	entity.Close()
+}
+
+func TestUcpAmHandlerArg(t *testing.T) {
+	entity := prepareContext(t, nil)
+	entity.worker, _ = entity.context.NewWorker(&UcpWorkerParams{})
+	createSelfEp(entity)
+	defer entity.Close()
+
+	var args []interface{}
+	handler := func(header unsafe.Pointer, headerSize uint64, data *UcpAmData, replyEp *UcpEp) UcsStatus {
+		args = append(args, data.HandlerArg())
+		return UCS_OK
+	}
+
+	send := func() {
+		sendReq, _ := entity.selfEp.SendAmNonBlocking(1, nil, 0, nil, 0, UCP_AM_SEND_FLAG_EAGER, nil)
+		for sendReq.GetStatus() == UCS_INPROGRESS {
+			entity.worker.Progress()
+		}
+		sendReq.Close()
+		for i := 0; i < 10; i++ {
+			entity.worker.Progress()
+		}
+	}
+
+	// Upgrade of the handler with the same callback and the new argument
+	for _, version := range []string{"v1", "v2"} {
+		params := (&UcpAmHandlerParams{}).SetId(1).WholeMessage().SetArg(version).SetCallback(handler)
+		if err := entity.worker.SetAmHandler(params); err != nil {
+			t.Fatalf("Failed to set AM handler %v", err)
+		}
+		send()
+	}
+
+	if (len(args) != 2) || (args[0] != "v1") || (args[1] != "v2") {
+		t.Fatalf("Unexpected handler arguments %v", args)
+	}
+
+	if err := entity.worker.RemoveAmRecvHandler(1); err != nil {
+		t.Fatalf("Failed to remove AM handler %v", err)
+	}
+}
+
+func TestUcpAmHeader(t *testing.T) {
+	const header string = "rpc header"
+
+	entity := prepareContext(t, nil)
+	entity.worker, _ = entity.context.NewWorker(&UcpWorkerParams{})
+	createSelfEp(entity)
+	defer entity.Close()
+
+	var cloned []byte
+	entity.worker.SetAmRecvHandler(1, UCP_AM_FLAG_WHOLE_MSG, func(header unsafe.Pointer, headerSize uint64,
+		data *UcpAmData, replyEp *UcpEp) UcsStatus {
+		cloned = AmHeader(header, headerSize).Clone()
+		return UCS_OK
+	})
+
+	headerMem := CBytes([]byte(header))
+	defer FreeNativeMemory(headerMem)
+	sendReq, _ := entity.selfEp.SendAmNonBlocking(1, headerMem, uint64(len(header)), nil, 0,
+		UCP_AM_SEND_FLAG_EAGER, nil)
+	for (cloned == nil) || (sendReq.GetStatus() == UCS_INPROGRESS) {
+		entity.worker.Progress()
+	}
+	sendReq.Close()
+
+	if string(cloned) != header {
+		t.Fatalf("Received header %s != %s", cloned, header)
+	}
+
+	if (AmHeader(nil, 0) != nil) || (AmHeader(nil, 0).Clone() != nil) {
+		t.Fatalf("Empty header is not nil")
+	}
+}
+
+func TestUcpAmDataModes(t *testing.T) {
+	const sendData string = "Hello GO AM modes"
+	entity := prepareContext(t, nil)
+	entity.worker, _ = entity.context.NewWorker(&UcpWorkerParams{})
+	createSelfEp(entity)
+
+	var persisted, copied *UcpAmData
+	if err := entity.worker.SetAmRecvHandlerWithMode(1, UCP_AM_FLAG_WHOLE_MSG, UcpAmDataModePersist,
+		func(header unsafe.Pointer, headerSize uint64, data *UcpAmData, replyEp *UcpEp) UcsStatus {
+			persisted = data
+			return UCS_OK
+		}); err != nil {
+		t.Fatalf("Failed to set AM handler %v", err)
+	}
+
+	if err := entity.worker.SetAmRecvHandlerWithMode(2, UCP_AM_FLAG_WHOLE_MSG, UcpAmDataModeCopy,
+		func(header unsafe.Pointer, headerSize uint64, data *UcpAmData, replyEp *UcpEp) UcsStatus {
+			copied = data
+			return UCS_INPROGRESS
+		}); err != nil {
+		t.Fatalf("Failed to set AM handler %v", err)
+	}
+
+	sendMem := CBytes([]byte(sendData))
+	for id := uint(1); id <= 2; id++ {
+		sendReq, _ := entity.selfEp.SendAmNonBlocking(id, nil, 0, sendMem, uint64(len(sendData)),
+			UCP_AM_SEND_FLAG_EAGER, nil)
+		for sendReq.GetStatus() == UCS_INPROGRESS {
+			entity.worker.Progress()
+		}
+		sendReq.Close()
+	}
+
+	for (persisted == nil) || (copied == nil) {
+		entity.worker.Progress()
+	}
+
+	// Persisted data is valid until it's released
+	dataPtr, err := persisted.DataPointer()
+	if err != nil {
+		t.Fatalf("Persisted data is not valid %v", err)
+	}
+	if data := string(GoBytes(dataPtr, persisted.Length())); data != sendData {
+		t.Fatalf("Persisted data %v != %v", data, sendData)
+	}
+	persisted.Release()
+	persisted.Release()
+
+	if data := string(copied.Bytes()); data != sendData {
+		t.Fatalf("Copied data %v != %v", data, sendData)
+	}
+	copied.Release()
+
+	FreeNativeMemory(sendMem)
+	entity.Close()
+}
+
+func TestUcpAmDataReceiveBytes(t *testing.T) {
+	const dataLen = 64 * 1024
+	entity := prepareContext(t, nil)
+	entity.worker, _ = entity.context.NewWorker(&UcpWorkerParams{})
+	createSelfEp(entity)
+	defer entity.Close()
+
+	pool := NewNativeBufferPool(dataLen, 1)
+	defer pool.Close()
+
+	// Receives to the Go slice by id 1 and to the pool buffer by id 2
+	var requests []*UcpRequest
+	var destinations [][]byte
+	var poolBuffer unsafe.Pointer
+	for id := uint(1); id <= 2; id++ {
+		toPool := id == 2
+		if err := entity.worker.SetAmRecvHandler(id, UCP_AM_FLAG_WHOLE_MSG, func(header unsafe.Pointer,
+			headerSize uint64, data *UcpAmData, replyEp *UcpEp) UcsStatus {
+			var request *UcpRequest
+			var err error
+			if toPool {
+				poolBuffer, request, err = data.ReceiveToPool(pool, nil)
+			} else {
+				destination := make([]byte, data.Length())
+				destinations = append(destinations, destination)
+				request, err = data.ReceiveBytes(destination, nil)
+			}
+
+			if err != nil {
+				t.Errorf("Failed to receive AM data %v", err)
+			}
+			requests = append(requests, request)
+			return UCS_OK
+		}); err != nil {
+			t.Fatalf("Failed to set AM handler %v", err)
+		}
+	}
+
+	sendData := make([]byte, dataLen)
+	for i := range sendData {
+		sendData[i] = byte(i)
+	}
+	sendMem := CBytes(sendData)
+	defer FreeNativeMemory(sendMem)
+
+	for _, send := range []struct {
+		id    uint
+		flags UcpAmSendFlags
+	}{{1, UCP_AM_SEND_FLAG_EAGER}, {1, UCP_AM_SEND_FLAG_RNDV}, {2, UCP_AM_SEND_FLAG_RNDV}} {
+		sendReq, _ := entity.selfEp.SendAmNonBlocking(send.id, nil, 0, sendMem, dataLen, send.flags, nil)
+		for (sendReq.GetStatus() == UCS_INPROGRESS) || (len(requests) == 0) ||
+			(requests[len(requests)-1].GetStatus() == UCS_INPROGRESS) {
+			entity.worker.Progress()
+		}
+		sendReq.Close()
+
+		request := requests[len(requests)-1]
+		if status := request.GetStatus(); status != UCS_OK {
+			t.Fatalf("AM data receive failed %v", status)
+		}
+		request.Close()
+		requests = nil
+	}
+
+	for _, destination := range destinations {
+		if !bytes.Equal(destination, sendData) {
+			t.Fatalf("Received data differs from the sent one")
+		}
+	}
+
+	if !bytes.Equal(GoBytes(poolBuffer, dataLen), sendData) {
+		t.Fatalf("Data received to the pool differs from the sent one")
+	}
+	pool.Put(poolBuffer)
+
+	// The slice is shorter than the data
+	short := make([]byte, 1)
+	if err := entity.worker.SetAmRecvHandler(3, UCP_AM_FLAG_WHOLE_MSG, func(header unsafe.Pointer,
+		headerSize uint64, data *UcpAmData, replyEp *UcpEp) UcsStatus {
+		request, err := data.ReceiveBytes(short, nil)
+		if !errors.Is(err, ErrMessageTruncated) {
+			t.Errorf("Unexpected error of short receive %v", err)
+		}
+		requests = append(requests, request)
+		return UCS_OK
+	}); err != nil {
+		t.Fatalf("Failed to set AM handler %v", err)
+	}
+
+	sendReq, _ := entity.selfEp.SendAmNonBlocking(3, nil, 0, sendMem, dataLen, UCP_AM_SEND_FLAG_RNDV, nil)
+	for (sendReq.GetStatus() == UCS_INPROGRESS) || (len(requests) == 0) {
+		entity.worker.Progress()
+	}
+	sendReq.Close()
+	requests[0].Close()
+}
+
+func TestUcpEpUserData(t *testing.T) {
+	type connState struct {
+		name string
+	}
+
+	entity := prepareContext(t, nil)
+	entity.worker, _ = entity.context.NewWorker(&UcpWorkerParams{})
+	defer entity.Close()
+
+	clientEp, serverEp := connectStream(t, entity.worker)
+	serverState := &connState{name: "server"}
+	serverEp.SetUserData(serverState)
+	clientEp.SetUserData("client")
+
+	// Reply endpoint of the message is the server endpoint
+	var replyState interface{}
+	entity.worker.SetAmRecvHandler(1, UCP_AM_FLAG_WHOLE_MSG, func(header unsafe.Pointer, headerSize uint64,
+		data *UcpAmData, replyEp *UcpEp) UcsStatus {
+		if replyEp != nil {
+			replyState = replyEp.UserData()
+		}
+		return UCS_OK
+	})
+
+	sendReq, _ := clientEp.SendAmNonBlocking(1, nil, 0, nil, 0, UCP_AM_SEND_FLAG_REPLY, nil)
+	for replyState == nil {
+		entity.worker.Progress()
+	}
+	for sendReq.GetStatus() == UCS_INPROGRESS {
+		entity.worker.Progress()
+	}
+	sendReq.Close()
+
+	if replyState != serverState {
+		t.Fatalf("Reply endpoint user data %v != %v", replyState, serverState)
+	}
+
+	if userData := clientEp.UserData(); userData != "client" {
+		t.Fatalf("Client endpoint user data %v != client", userData)
+	}
+
+	for _, ep := range []*UcpEp{clientEp, serverEp} {
+		closeReq, _ := ep.CloseNonBlockingForce(nil)
+		for closeReq.GetStatus() == UCS_INPROGRESS {
+			entity.worker.Progress()
+		}
+		closeReq.Close()
+
+		if userData := ep.UserData(); userData != nil {
+			t.Fatalf("User data %v of the closed endpoint", userData)
+		}
+	}
+}
+
+func TestUcpTagProbe(t *testing.T) {
+	const sendData string = "Hello GO"
+	const tag uint64 = 7
+
+	entity := prepareContext(t, nil)
+	entity.worker, _ = entity.context.NewWorker(&UcpWorkerParams{})
+	createSelfEp(entity)
+
+	if message := entity.worker.TagProbe(tag, ^uint64(0), false); message != nil {
+		t.Fatalf("Probed message, that was not sent")
+	}
+
+	sendMem := CBytes([]byte(sendData))
+	sendRequest, _ := entity.selfEp.SendTagNonBlocking(tag, sendMem, uint64(len(sendData)), nil)
+
+	var message *UcpTagMessage
+	for message == nil {
+		entity.worker.Progress()
+		message = entity.worker.TagProbe(tag, ^uint64(0), true)
+	}
+
+	if message.Info.SenderTag != tag {
+		t.Fatalf("Sender tag %d != probed tag %d", tag, message.Info.SenderTag)
+	}
+
+	if message.Info.Length != uint64(len(sendData)) {
+		t.Fatalf("Data length %d != probed length %d", len(sendData), message.Info.Length)
+	}
+
+	recvMem := AllocateNativeMemory(message.Info.Length)
+	recvRequest, err := entity.worker.RecvTagMsgNonBlocking(recvMem, message.Info.Length, message, nil)
+	if err != nil {
+		t.Fatalf("Failed to receive probed message %v", err)
+	}
+
+	for (sendRequest.GetStatus() == UCS_INPROGRESS) || (recvRequest.GetStatus() == UCS_INPROGRESS) {
+		entity.worker.Progress()
+	}
+
+	if recvString := string(GoBytes(recvMem, message.Info.Length)); recvString != sendData {
+		t.Fatalf("Send data %s != recv data %s", sendData, recvString)
+	}
+
+	sendRequest.Close()
+	recvRequest.Close()
+	FreeNativeMemory(sendMem)
+	FreeNativeMemory(recvMem)
+	entity.Close()
+}
+
+func TestUcpTagRecvInfo(t *testing.T) {
+	const senderTag uint64 = 0x1234
+	sendData := []byte("short message")
+
+	entity := prepareContext(t, nil)
+	entity.worker, _ = entity.context.NewWorker(&UcpWorkerParams{})
+	createSelfEp(entity)
+	defer entity.Close()
+
+	sendRequest, _ := entity.selfEp.SendTagBytesNonBlocking(senderTag, sendData, nil)
+	defer sendRequest.Close()
+	for sendRequest.GetStatus() == UCS_INPROGRESS {
+		entity.worker.Progress()
+	}
+
+	// The unexpected message completes the receive immediately, with the info
+	// of the buffer, that is larger than the message
+	recvMem := AllocateNativeMemory(4096)
+	defer FreeNativeMemory(recvMem)
+	var recvInfo *UcpTagRecvInfo
+	recvRequest, err := entity.worker.RecvTagNonBlocking(recvMem, 4096, 0x1000, 0xf000,
+		(&UcpRequestParams{}).SetCallback(func(request *UcpRequest, status UcsStatus, tagInfo *UcpTagRecvInfo) {
+			recvInfo = tagInfo
+		}))
+	if err != nil {
+		t.Fatalf("Failed to receive %v", err)
+	}
+	defer recvRequest.Close()
+
+	for recvInfo == nil {
+		entity.worker.Progress()
+	}
+
+	if (recvInfo.SenderTag != senderTag) || (recvInfo.Length != uint64(len(sendData))) {
+		t.Fatalf("Received tag %x and length %d != sent tag %x and length %d", recvInfo.SenderTag,
+			recvInfo.Length, senderTag, len(sendData))
+	}
+}
+
+func TestUcpEpTagIov(t *testing.T) {
+	const header string = "Hello "
+	const payload string = "GO"
+
+	entity := prepareContext(t, nil)
+	entity.worker, _ = entity.context.NewWorker(&UcpWorkerParams{})
+	createSelfEp(entity)
+
+	headerMem := CBytes([]byte(header))
+	payloadMem := CBytes([]byte(payload))
+	sendIov := []UcpIov{
+		{Buffer: headerMem, Length: uint64(len(header))},
+		{Buffer: payloadMem, Length: uint64(len(payload))},
+	}
+
+	// Split the receive buffers differently from the send ones
+	recvMem1 := AllocateNativeMemory(2)
+	recvMem2 := AllocateNativeMemory(4096)
+	recvIov := []UcpIov{{Buffer: recvMem1, Length: 2}, {Buffer: recvMem2, Length: 4096}}
+
+	var recvLength uint64
+	recvRequest, err := entity.worker.RecvTagIovNonBlocking(recvIov, 1, ^uint64(0), &UcpRequestParams{
+		Cb: func(request *UcpRequest, status UcsStatus, tagInfo *UcpTagRecvInfo) {
+			if status != UCS_OK {
+				t.Fatalf("Request failed with status: %d", status)
+			}
+			recvLength = tagInfo.Length
+		}})
+	if err != nil {
+		t.Fatalf("Failed to post iov receive %v", err)
+	}
+
+	sendRequest, err := entity.selfEp.SendTagIovNonBlocking(1, sendIov, nil)
+	if err != nil {
+		t.Fatalf("Failed to post iov send %v", err)
+	}
+
+	for (sendRequest.GetStatus() == UCS_INPROGRESS) || (recvRequest.GetStatus() == UCS_INPROGRESS) {
+		entity.worker.Progress()
+	}
+
+	if recvLength != uint64(len(header+payload)) {
+		t.Fatalf("Data length %d != received length %d", len(header+payload), recvLength)
+	}
+
+	recvString := string(GoBytes(recvMem1, 2)) + string(GoBytes(recvMem2, recvLength-2))
+	if recvString != header+payload {
+		t.Fatalf("Send data %s != recv data %s", header+payload, recvString)
+	}
+
+	sendRequest.Close()
+	recvRequest.Close()
+	FreeNativeMemory(headerMem)
+	FreeNativeMemory(payloadMem)
+	FreeNativeMemory(recvMem1)
+	FreeNativeMemory(recvMem2)
+	entity.Close()
+}
+
+func TestUcpEpCloseForce(t *testing.T) {
+	const dataLen uint64 = 1 << 20
+
+	sender := prepareContext(t, nil)
+	receiver := prepareContext(t, nil)
+	sender.worker, _ = sender.context.NewWorker(&UcpWorkerParams{})
+	receiver.worker, _ = receiver.context.NewWorker(&UcpWorkerParams{})
+	connect(sender, receiver)
+
+	// Rendezvous send is not completed, since there is no matching receive
+	sendMem := AllocateNativeMemory(dataLen)
+	sendRequest, _ := sender.ep.SendTagNonBlocking(1, sendMem, dataLen, nil)
+
+	closeReq, err := sender.ep.CloseNonBlocking(UCP_EP_CLOSE_FLAG_FORCE, nil)
+	if err != nil {
+		t.Fatalf("Failed to close endpoint %v", err)
+	}
+
+	for (closeReq.GetStatus() == UCS_INPROGRESS) || (sendRequest.GetStatus() == UCS_INPROGRESS) {
+		sender.worker.Progress()
+		receiver.worker.Progress()
+	}
+
+	if status := sendRequest.GetStatus(); status == UCS_OK {
+		t.Fatalf("Outstanding send was completed by forced close")
+	}
+
+	sendRequest.Close()
+	closeReq.Close()
+	FreeNativeMemory(sendMem)
+	sender.Close()
+	receiver.Close()
+}
+
+func TestUcpTagPersistentRecv(t *testing.T) {
+	const numMessages int = 16
+	const tag uint64 = 3
+
+	entity := prepareContext(t, nil)
+	entity.worker, _ = entity.context.NewWorker(&UcpWorkerParams{})
+	createSelfEp(entity)
+
+	pool := NewNativeBufferPool(64, 4)
+	defer pool.Close()
+
+	// The receives to the native buffers skip the memory type detection
+	if memTypePool, ok := UcpBufferPool(pool).(UcpMemoryTypeBufferPool); !ok ||
+		(memTypePool.MemoryType() != UCS_MEMORY_TYPE_HOST) {
+		t.Fatalf("Native buffer pool is not of the host memory type")
+	}
+
+	var received []string
+	recv, err := entity.worker.RecvTagPersistent(tag, ^uint64(0), pool,
+		func(buffer unsafe.Pointer, info *UcpTagRecvInfo, status UcsStatus) {
+			if status != UCS_OK {
+				t.Errorf("Failed to receive message %v", status)
+				return
+			}
+			received = append(received, string(GoBytes(buffer, info.Length)))
+		})
+	if err != nil {
+		t.Fatalf("Failed to post persistent receive %v", err)
+	}
+
+	if active := recv.Active(); active != 4 {
+		t.Fatalf("Number of posted receives %d != number of buffers 4", active)
+	}
+
+	for i := 0; i < numMessages; i++ {
+		sendData := fmt.Sprintf("message %d", i)
+		sendMem := CBytes([]byte(sendData))
+		sendRequest, _ := entity.selfEp.SendTagNonBlocking(tag, sendMem, uint64(len(sendData)), nil)
+		for sendRequest.GetStatus() == UCS_INPROGRESS {
+			entity.worker.Progress()
+		}
+		sendRequest.Close()
+		FreeNativeMemory(sendMem)
+	}
+
+	for len(received) < numMessages {
+		entity.worker.Progress()
+	}
+
+	for i, message := range received {
+		if expected := fmt.Sprintf("message %d", i); message != expected {
+			t.Fatalf("Received message %s != sent message %s", message, expected)
+		}
+	}
+
+	recv.Close()
+	if active := recv.Active(); active != 0 {
+		t.Fatalf("Receives are still posted after close %d", active)
+	}
+
+	for i := 0; i < 4; i++ {
+		if pool.Get() == nil {
+			t.Fatalf("Buffer %d is not returned to the pool", i)
+		}
+	}
+	entity.Close()
+}
+
+func TestUcpTagDemux(t *testing.T) {
+	entity := prepareContext(t, nil)
+	entity.worker, _ = entity.context.NewWorker(&UcpWorkerParams{})
+	createSelfEp(entity)
+	defer entity.Close()
+
+	demux := NewUcpTagDemux(entity.worker)
+	defer demux.Close()
+
+	// Routes of the tags 0x10-0x1f and 0x20-0x2f
+	rangeRoute, err := demux.Route(0x10, ^uint64(0xf), 2)
+	if err != nil {
+		t.Fatalf("Failed to create route %v", err)
+	}
+	otherRoute, _ := demux.Route(0x20, ^uint64(0xf), 8)
+
+	if _, err := demux.Route(0, 0, 0); !errors.Is(err, ErrInvalidParam) {
+		t.Fatalf("Route without capacity returned %v", err)
+	}
+
+	send := func(tag uint64, data string) {
+		sendMem := CBytes([]byte(data))
+		sendRequest, _ := entity.selfEp.SendTagNonBlocking(tag, sendMem, uint64(len(data)), nil)
+		for sendRequest.GetStatus() == UCS_INPROGRESS {
+			entity.worker.Progress()
+		}
+		sendRequest.Close()
+		FreeNativeMemory(sendMem)
+	}
+
+	poll := func(done func() bool) {
+		for deadline := time.Now().Add(10 * time.Second); !done(); {
+			if time.Now().After(deadline) {
+				t.Fatalf("Messages were not delivered")
+			}
+			entity.worker.Progress()
+			demux.Poll()
+		}
+	}
+
+	for i := 0; i < 3; i++ {
+		send(0x10+uint64(i), fmt.Sprintf("range %d", i))
+	}
+	send(0x20, "other")
+
+	// The third message doesn't fit the range channel, so it stays unmatched,
+	// while the other route receives
+	poll(func() bool { return (len(rangeRoute.Messages()) == 2) && (len(otherRoute.Messages()) == 1) })
+	for i := 0; i < 100; i++ {
+		entity.worker.Progress()
+		demux.Poll()
+	}
+
+	if length := len(rangeRoute.Messages()); length != 2 {
+		t.Fatalf("Range route received %d messages beyond its capacity", length)
+	}
+
+	if message := <-otherRoute.Messages(); (message.Err != nil) || (string(message.Data) != "other") ||
+		(message.SenderTag != 0x20) {
+		t.Fatalf("Other route received %+v", message)
+	}
+
+	for i := 0; i < 3; i++ {
+		if i == 2 {
+			poll(func() bool { return len(rangeRoute.Messages()) == 1 })
+		}
+
+		message := <-rangeRoute.Messages()
+		if expected := fmt.Sprintf("range %d", i); (message.Err != nil) || (string(message.Data) != expected) ||
+			(message.SenderTag != 0x10+uint64(i)) {
+			t.Fatalf("Range route received %+v instead of %q", message, expected)
+		}
+	}
+
+	rangeRoute.Close()
+	poll(func() bool {
+		select {
+		case _, ok := <-rangeRoute.Messages():
+			return !ok
+		default:
+			return false
+		}
+	})
+}
+
+func TestUcpWorkerServe(t *testing.T) {
+	const serveTag uint64 = 0x100
+	const serveTagMask uint64 = 0xf00
+
+	entity := prepareContext(t, nil)
+	entity.worker, _ = entity.context.NewWorker(&UcpWorkerParams{})
+	createSelfEp(entity)
+	defer entity.Close()
+
+	// The large message is received by the rendezvous protocol
+	messages := [][]byte{[]byte("small"), bytes.Repeat([]byte("large"), 1<<16), {}}
+	var requests []*UcpRequest
+	for i, message := range messages {
+		request, _ := entity.selfEp.SendTagBytesNonBlocking(serveTag|uint64(i), message, nil)
+		requests = append(requests, request)
+	}
+
+	// Not matching message is left for the other receives
+	otherRequest, _ := entity.selfEp.SendTagBytesNonBlocking(0x200, []byte("other"), nil)
+	requests = append(requests, otherRequest)
+	defer func() {
+		for _, request := range requests {
+			request.Close()
+		}
+	}()
+
+	errServed := errors.New("all messages are served")
+	var received [][]byte
+	var tags []uint64
+	err := entity.worker.Serve(context.Background(), serveTag, serveTagMask,
+		func(buffer unsafe.Pointer, info *UcpTagRecvInfo) error {
+			received = append(received, GoBytes(buffer, info.Length))
+			tags = append(tags, info.SenderTag)
+			if len(received) == len(messages) {
+				return errServed
+			}
+			return nil
+		})
+	if err != errServed {
+		t.Fatalf("Serve returned %v != handler error", err)
+	}
+
+	for i, message := range messages {
+		if !bytes.Equal(received[i], message) || (tags[i] != serveTag|uint64(i)) {
+			t.Fatalf("Message %d of length %d with tag %x doesn't match the sent one", i,
+				len(received[i]), tags[i])
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := entity.worker.Serve(ctx, serveTag, serveTagMask,
+		func(buffer unsafe.Pointer, info *UcpTagRecvInfo) error {
+			return errors.New("unexpected message")
+		}); err != context.DeadlineExceeded {
+		t.Fatalf("Serve returned %v != %v", err, context.DeadlineExceeded)
+	}
+
+	if message := entity.worker.TagProbe(0x200, ^uint64(0), false); message == nil {
+		t.Fatalf("Not matching message was received")
+	}
+}
+
+func TestUcpEpSendOpFlags(t *testing.T) {
+	const sendData string = "Hello GO"
+
+	entity := prepareContext(t, nil)
+	entity.worker, _ = entity.context.NewWorker(&UcpWorkerParams{})
+	createSelfEp(entity)
+	defer entity.Close()
+
+	sendMem := CBytes([]byte(sendData))
+	defer FreeNativeMemory(sendMem)
+	recvMem := AllocateNativeMemory(4096)
+	defer FreeNativeMemory(recvMem)
+
+	for _, flags := range []UcpOpAttrFlags{UCP_OP_ATTR_FLAG_FAST_CMPL, UCP_OP_ATTR_FLAG_MULTI_SEND,
+		UCP_OP_ATTR_FLAG_NO_IMM_CMPL} {
+		sendCompleted := false
+		sendParams := (&UcpRequestParams{}).SetOpAttrFlags(flags).SetCallback(func(request *UcpRequest, status UcsStatus) {
+			sendCompleted = true
+		})
+
+		sendRequest, err := entity.selfEp.SendTagNonBlocking(1, sendMem, uint64(len(sendData)), sendParams)
+		if err != nil {
+			t.Fatalf("Failed to send with flags %v: %v", flags, err)
+		}
+
+		if (flags == UCP_OP_ATTR_FLAG_NO_IMM_CMPL) && sendCompleted {
+			t.Fatalf("Send completed immediately despite UCP_OP_ATTR_FLAG_NO_IMM_CMPL")
+		}
+
+		recvRequest, _ := entity.worker.RecvTagNonBlocking(recvMem, 4096, 1, ^uint64(0), nil)
+		for (sendRequest.GetStatus() == UCS_INPROGRESS) || (recvRequest.GetStatus() == UCS_INPROGRESS) {
+			entity.worker.Progress()
+		}
+
+		if !sendCompleted {
+			t.Fatalf("Send callback was not called with flags %v", flags)
+		}
+
+		sendRequest.Close()
+		recvRequest.Close()
+	}
+}
+
+func TestUcpEpSendTagBytes(t *testing.T) {
+	entity := prepareContext(t, nil)
+	entity.worker, _ = entity.context.NewWorker(&UcpWorkerParams{})
+	createSelfEp(entity)
+	defer entity.Close()
+
+	for _, size := range []int{0, 8, 1 << 20} {
+		sendData := make([]byte, size)
+		for i := range sendData {
+			sendData[i] = byte(i)
+		}
+
+		recvMem := AllocateNativeMemory(uint64(size) + 1)
+		sendCompleted := false
+		sendRequest, err := entity.selfEp.SendTagBytesNonBlocking(1, sendData,
+			(&UcpRequestParams{}).SetCallback(func(request *UcpRequest, status UcsStatus) {
+				sendCompleted = true
+			}))
+		if err != nil {
+			t.Fatalf("Failed to send %d bytes: %v", size, err)
+		}
+
+		recvRequest, _ := entity.worker.RecvTagNonBlocking(recvMem, uint64(size), 1, ^uint64(0), nil)
+		for (sendRequest.GetStatus() == UCS_INPROGRESS) || (recvRequest.GetStatus() == UCS_INPROGRESS) {
+			// Pinned slice must not be moved or collected meanwhile
+			runtime.GC()
+			entity.worker.Progress()
+		}
+
+		if !sendCompleted {
+			t.Fatalf("Send callback of %d bytes was not called", size)
+		}
+
+		if !bytes.Equal(GoBytes(recvMem, uint64(size)), sendData) {
+			t.Fatalf("Received data of %d bytes != sent", size)
+		}
+
+		sendRequest.Close()
+		recvRequest.Close()
+		FreeNativeMemory(recvMem)
+	}
+}
+
+func TestUcpEpSendTagBatch(t *testing.T) {
+	entity := prepareContext(t, nil)
+	entity.worker, _ = entity.context.NewWorker(&UcpWorkerParams{})
+	createSelfEp(entity)
+	defer entity.Close()
+
+	payloads := []string{"first", "second", strings.Repeat("third", 1<<16)}
+	msgs := make([]UcpTagMsg, len(payloads))
+	for i, payload := range payloads {
+		msgs[i] = UcpTagMsg{Tag: uint64(i), Address: CBytes([]byte(payload)), Size: uint64(len(payload))}
+		defer FreeNativeMemory(msgs[i].Address)
+	}
+
+	completed := 0
+	sendRequests, err := entity.selfEp.SendTagBatch(msgs,
+		(&UcpRequestParams{}).SetCallback(UcpSendCallback(func(request *UcpRequest, status UcsStatus) {
+			completed++
+		})))
+	if err != nil {
+		t.Fatalf("Failed to send batch %v", err)
+	}
+
+	if len(sendRequests) != len(msgs) {
+		t.Fatalf("Requests %d != messages %d", len(sendRequests), len(msgs))
+	}
+
+	// Receive in the reverse order to match the messages by tags
+	for i := len(payloads) - 1; i >= 0; i-- {
+		recvMem := AllocateNativeMemory(uint64(len(payloads[i])))
+		recvRequest, _ := entity.worker.RecvTagNonBlocking(recvMem, uint64(len(payloads[i])),
+			uint64(i), ^uint64(0), nil)
+		for recvRequest.GetStatus() == UCS_INPROGRESS {
+			entity.worker.Progress()
+		}
+
+		if string(GoBytes(recvMem, uint64(len(payloads[i])))) != payloads[i] {
+			t.Fatalf("Received message %d != sent", i)
+		}
+		recvRequest.Close()
+		FreeNativeMemory(recvMem)
+	}
+
+	for _, sendRequest := range sendRequests {
+		for sendRequest.GetStatus() == UCS_INPROGRESS {
+			entity.worker.Progress()
+		}
+		sendRequest.Close()
+	}
+
+	if completed != len(msgs) {
+		t.Fatalf("Send callbacks %d != messages %d", completed, len(msgs))
+	}
+}
+
+func TestUcpEpPing(t *testing.T) {
+	config, err := NewUcpConfig("", "")
+	if err != nil {
+		t.Fatalf("Failed to read config %v", err)
+	}
+	defer config.Close()
+
+	if err := config.SetKeepaliveInterval(100 * time.Millisecond); err != nil {
+		t.Fatalf("Failed to set keepalive interval %v", err)
+	}
+	if err := config.SetKeepaliveNumEps(0); err != nil {
+		t.Fatalf("Failed to set keepalive endpoints %v", err)
+	}
+
+	entity := prepareContext(t, (&UcpParams{}).EnableAM().SetConfig(config))
+	entity.worker, _ = entity.context.NewWorker(&UcpWorkerParams{})
+	createSelfEp(entity)
+	defer entity.Close()
+
+	if _, err := entity.selfEp.Ping(time.Second); !errors.Is(err, ErrInvalidParam) {
+		t.Fatalf("Ping without the handler returned %v", err)
+	}
+
+	if err := entity.worker.EnablePing(7); err != nil {
+		t.Fatalf("Failed to enable ping %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		rtt, err := entity.selfEp.Ping(5 * time.Second)
+		if err != nil {
+			t.Fatalf("Ping failed %v", err)
+		}
+
+		if rtt <= 0 {
+			t.Fatalf("Unexpected round trip time %v", rtt)
+		}
+	}
+}
+
+func TestUcpSendQueue(t *testing.T) {
+	const dataLen uint64 = 4 * 1024 * 1024
+	entity := prepareContext(t, nil)
+	entity.worker, _ = entity.context.NewWorker(&UcpWorkerParams{})
+	createSelfEp(entity)
+	defer entity.Close()
+
+	if _, err := NewUcpSendQueue(entity.selfEp, 0); !errors.Is(err, ErrInvalidParam) {
+		t.Fatalf("Queue without slots is created: %v", err)
+	}
+
+	queue, _ := NewUcpSendQueue(entity.selfEp, 1)
+	sendMem := AllocateNativeMemory(dataLen)
+	defer FreeNativeMemory(sendMem)
+	recvMem := AllocateNativeMemory(dataLen)
+	defer FreeNativeMemory(recvMem)
+
+	// The rendezvous send waits for the receive
+	sendRequest, err := queue.SendTag(nil, selfEpTag, sendMem, dataLen, nil)
+	if err != nil {
+		t.Fatalf("Failed to send %v", err)
+	}
+	defer sendRequest.Close()
+
+	if queue.Outstanding() == 0 {
+		t.Skip("Send was completed immediately")
+	}
+
+	if _, err := queue.SendTag(nil, selfEpTag, sendMem, dataLen, nil); !errors.Is(err, ErrWouldBlock) ||
+		!errors.Is(err, ErrNoResource) {
+		t.Fatalf("Send to the full queue returned %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	queue.SetBlocking(true)
+	if _, err := queue.SendTag(ctx, selfEpTag, sendMem, dataLen, nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Blocking send to the full queue returned %v", err)
+	}
+
+	recvRequest, _ := entity.worker.RecvTagNonBlocking(recvMem, dataLen, selfEpTag, selfEpTag, nil)
+	defer recvRequest.Close()
+	for (sendRequest.GetStatus() == UCS_INPROGRESS) || (recvRequest.GetStatus() == UCS_INPROGRESS) {
+		entity.worker.Progress()
+	}
+
+	if queue.Outstanding() != 0 {
+		t.Fatalf("Completed send still takes the slot of the queue")
+	}
+}
+
+func TestGpuReceiverStaged(t *testing.T) {
+	const dataLen uint64 = 256 * 1024
+	entity := prepareContext(t, nil)
+	entity.worker, _ = entity.context.NewWorker(&UcpWorkerParams{})
+	createSelfEp(entity)
+	defer entity.Close()
+
+	memTypeMask, _ := entity.context.MemoryTypesMask()
+	if !IsMemTypeSupported(UCS_MEMORY_TYPE_CUDA, memTypeMask) {
+		t.Skip("CUDA memory is not supported")
+	}
+
+	if err := CudaSetDevice(); err != nil {
+		t.Skip(err)
+	}
+
+	gpuMemory, err := memAlloc(entity.context, dataLen, UCS_MEMORY_TYPE_CUDA)
+	if err != nil {
+		t.Fatalf("Failed to allocate GPU memory %v", err)
+	}
+	defer gpuMemory.Close()
+	gpuAttrs, _ := gpuMemory.Query(UCP_MEM_ATTR_FIELD_ADDRESS)
+
+	receiver := NewGpuReceiver(entity.context, entity.worker, dataLen).ForceStaging()
+	defer receiver.Close()
+
+	data := make([]byte, dataLen)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	sendMem := CBytes(data)
+	defer FreeNativeMemory(sendMem)
+
+	sendRequest, _ := entity.selfEp.SendTagNonBlocking(selfEpTag, sendMem, dataLen, nil)
+	defer sendRequest.Close()
+
+	info, path, err := receiver.RecvTag(context.Background(), gpuAttrs.Address, dataLen, selfEpTag, selfEpTag,
+		(&UcpRequestParams{}).SetMemType(UCS_MEMORY_TYPE_CUDA))
+	if err != nil {
+		t.Fatalf("Failed to receive to GPU memory %v", err)
+	}
+
+	if (path != GpuRecvStaged) || (info.Length != dataLen) {
+		t.Fatalf("Unexpected path %v or length %d", path, info.Length)
+	}
+
+	// UCX copies the data back from the GPU memory
+	recvMem := AllocateNativeMemory(dataLen)
+	defer FreeNativeMemory(recvMem)
+	backRequest, _ := entity.selfEp.SendTagNonBlocking(selfEpTag, gpuAttrs.Address, dataLen,
+		(&UcpRequestParams{}).SetMemType(UCS_MEMORY_TYPE_CUDA))
+	defer backRequest.Close()
+	recvRequest, _ := entity.worker.RecvTagNonBlocking(recvMem, dataLen, selfEpTag, selfEpTag, nil)
+	defer recvRequest.Close()
+	for recvRequest.GetStatus() == UCS_INPROGRESS {
+		entity.worker.Progress()
+	}
+
+	if !bytes.Equal(GoBytes(recvMem, dataLen), data) {
+		t.Fatalf("GPU memory doesn't match the sent data")
+	}
+}
+
+func TestUcpPersistentRequest(t *testing.T) {
+	const dataLen uint64 = 8
+	entity := prepareContext(t, nil)
+	entity.worker, _ = entity.context.NewWorker(&UcpWorkerParams{})
+	createSelfEp(entity)
+	defer entity.Close()
+
+	sendMem := AllocateNativeMemory(dataLen)
+	defer FreeNativeMemory(sendMem)
+	recvMem := AllocateNativeMemory(dataLen)
+	defer FreeNativeMemory(recvMem)
+
+	completed := 0
+	request, err := entity.selfEp.NewPersistentSendTag(selfEpTag, sendMem, dataLen, nil,
+		func(request *UcpPersistentRequest, status UcsStatus) {
+			if status != UCS_OK {
+				t.Errorf("Submission failed %v", status)
+			}
+			completed++
+		})
+	if err != nil {
+		t.Fatalf("Failed to build persistent request %v", err)
+	}
+
+	sendBuffer := (*[dataLen]byte)(sendMem)
+	recvBuffer := (*[dataLen]byte)(recvMem)
+	for i := 1; i <= 3; i++ {
+		for j := range sendBuffer {
+			sendBuffer[j] = byte(i)
+		}
+
+		if err := request.Resubmit(); err != nil {
+			t.Fatalf("Failed to resubmit %v", err)
+		}
+
+		if request.GetStatus() == UCS_INPROGRESS {
+			if err := request.Resubmit(); !errors.Is(err, ErrBusy) {
+				t.Fatalf("Resubmit in progress returned %v", err)
+			}
+		}
+
+		recvRequest, _ := entity.worker.RecvTagNonBlocking(recvMem, dataLen, selfEpTag, selfEpTag, nil)
+		for (recvRequest.GetStatus() == UCS_INPROGRESS) || (request.GetStatus() == UCS_INPROGRESS) {
+			entity.worker.Progress()
+		}
+		recvRequest.Close()
+
+		if (completed != i) || (*recvBuffer != *sendBuffer) {
+			t.Fatalf("Submission %d: completed %d, received %v", i, completed, *recvBuffer)
+		}
+	}
+
+	request.Close()
+	if err := request.Resubmit(); !errors.Is(err, ErrInvalidParam) {
+		t.Fatalf("Resubmit of closed request returned %v", err)
+	}
+}
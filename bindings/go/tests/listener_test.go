@@ -5,8 +5,12 @@
 package goucxtests
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net"
 	"testing"
+	"time"
 	. "ucx"
 )
 
@@ -139,3 +143,624 @@ func TestUcpListener(t *testing.T) {
 		worker2.Progress()
 	}
 }
+
+func TestUcpWorkerNewEndpointFromConnRequest(t *testing.T) {
+	addr, _ := net.ResolveTCPAddr("tcp", "0.0.0.0:0")
+	ucpParams := (&UcpParams{}).EnableTag()
+
+	serverContext, _ := NewUcpContext(ucpParams)
+	defer serverContext.Close()
+	clientContext, _ := NewUcpContext(ucpParams)
+	defer clientContext.Close()
+	server, _ := serverContext.NewWorker(&UcpWorkerParams{})
+	defer server.Close()
+	client, _ := clientContext.NewWorker(&UcpWorkerParams{})
+	defer client.Close()
+
+	var requests []*UcpConnectionRequest
+	listenerParams := (&UcpListenerParams{}).SetConnectionHandler(func(connRequest *UcpConnectionRequest) {
+		requests = append(requests, connRequest)
+	})
+	listenerParams.SetSocketAddress(addr)
+	listener, err := server.NewListener(listenerParams)
+	if err != nil {
+		t.Fatalf("Failed to create listener %v", err)
+	}
+	defer listener.Close()
+	listenerAddress, _ := listener.Addr()
+
+	progress := func(done func() bool) {
+		for !done() {
+			server.Progress()
+			client.Progress()
+		}
+	}
+
+	// The endpoint is accepted with the default params, and with the given
+	// ones
+	var clientEps, serverEps []*UcpEp
+	for _, serverEpParams := range []*UcpEpParams{nil, (&UcpEpParams{}).SetPeerErrorHandling()} {
+		epParams := (&UcpEpParams{}).SetPeerErrorHandling()
+		epParams.SetSocketAddress(listenerAddress)
+		ep, err := client.NewEndpoint(epParams)
+		if err != nil {
+			t.Fatalf("Can't create endpoint %v", err)
+		}
+		clientEps = append(clientEps, ep)
+
+		progress(func() bool { return len(requests) == len(clientEps) })
+		serverEp, err := server.NewEndpointFromConnRequest(requests[len(requests)-1], serverEpParams)
+		if err != nil {
+			t.Fatalf("Can't create endpoint from connection request %v", err)
+		}
+		serverEps = append(serverEps, serverEp)
+	}
+
+	sendData := "connection request test"
+	sendMem := CBytes([]byte(sendData))
+	defer FreeNativeMemory(sendMem)
+	receiveMem := AllocateNativeMemory(4096)
+	defer FreeNativeMemory(receiveMem)
+
+	for i, serverEp := range serverEps {
+		recvReq, _ := client.RecvTagNonBlocking(receiveMem, 4096, uint64(i), 0xFFFFFFFFFFFFFFFF, nil)
+		sendReq, _ := serverEp.SendTagNonBlocking(uint64(i), sendMem, uint64(len(sendData)), nil)
+		progress(func() bool {
+			return (recvReq.GetStatus() != UCS_INPROGRESS) && (sendReq.GetStatus() != UCS_INPROGRESS)
+		})
+
+		if recvReq.GetStatus() != UCS_OK {
+			t.Fatalf("Receive from endpoint %d failed with %v", i, recvReq.GetStatus())
+		}
+
+		if received := string(GoBytes(receiveMem, uint64(len(sendData)))); received != sendData {
+			t.Fatalf("Received %q != %q", received, sendData)
+		}
+		recvReq.Close()
+		sendReq.Close()
+	}
+
+	for _, ep := range append(clientEps, serverEps...) {
+		closeReq, _ := ep.CloseNonBlockingForce(nil)
+		progress(func() bool { return closeReq.GetStatus() != UCS_INPROGRESS })
+		closeReq.Close()
+	}
+}
+
+func TestUcpEpSockAddrIPv6(t *testing.T) {
+	if l, err := net.Listen("tcp6", "[::1]:0"); err != nil {
+		t.Skipf("IPv6 loopback is not available %v", err)
+	} else {
+		l.Close()
+	}
+
+	addr, _ := net.ResolveTCPAddr("tcp6", "[::1]:0")
+	ucpParams := (&UcpParams{}).EnableTag()
+
+	context1, _ := NewUcpContext(ucpParams)
+	context2, _ := NewUcpContext(ucpParams)
+	defer context1.Close()
+	defer context2.Close()
+
+	worker1, _ := context1.NewWorker(&UcpWorkerParams{})
+	worker2, _ := context2.NewWorker(&UcpWorkerParams{})
+	defer worker1.Close()
+	defer worker2.Close()
+
+	var ipv6ConnReq *UcpConnectionRequest
+	listenerParams := &UcpListenerParams{}
+	if _, err := listenerParams.SetSocketAddress(addr); err != nil {
+		t.Fatalf("Failed to set listener address %v", err)
+	}
+	listenerParams.SetConnectionHandler(func(connRequest *UcpConnectionRequest) {
+		ipv6ConnReq = connRequest
+	})
+
+	listener, err := worker1.NewListener(listenerParams)
+	if err != nil {
+		t.Fatalf("Can't create listener %v", err)
+	}
+	defer listener.Close()
+
+	listenerAttrs, _ := listener.Query(UCP_LISTENER_ATTR_FIELD_SOCKADDR)
+	if (listenerAttrs.Address.IP.To4() != nil) || !listenerAttrs.Address.IP.IsLoopback() {
+		t.Fatalf("Listener address %v is not IPv6 loopback", listenerAttrs.Address)
+	}
+
+	epParams, err := (&UcpEpParams{}).SetSockAddr(&net.UDPAddr{IP: net.IPv6loopback,
+		Port: listenerAttrs.Address.Port})
+	if err != nil {
+		t.Fatalf("Failed to set endpoint address %v", err)
+	}
+	epParams.SetConnectionFlags(UCP_EP_PARAMS_FLAGS_NO_LOOPBACK)
+
+	ep, err := worker2.NewEndpoint(epParams)
+	if err != nil {
+		t.Fatalf("Can't create endpoint %v", err)
+	}
+
+	for ipv6ConnReq == nil {
+		worker1.Progress()
+		worker2.Progress()
+	}
+	ipv6ConnReq.Reject()
+
+	closeReq, _ := ep.CloseNonBlockingForce(nil)
+	for closeReq.GetStatus() == UCS_INPROGRESS {
+		worker1.Progress()
+		worker2.Progress()
+	}
+	closeReq.Close()
+}
+
+func TestUcpEpLocalSockAddr(t *testing.T) {
+	addr, _ := net.ResolveTCPAddr("tcp", "127.0.0.1:0")
+	ucpParams := (&UcpParams{}).EnableTag()
+
+	context1, _ := NewUcpContext(ucpParams)
+	context2, _ := NewUcpContext(ucpParams)
+	defer context1.Close()
+	defer context2.Close()
+
+	worker1, _ := context1.NewWorker(&UcpWorkerParams{})
+	worker2, _ := context2.NewWorker(&UcpWorkerParams{})
+	defer worker1.Close()
+	defer worker2.Close()
+
+	var clientAddr *net.TCPAddr
+	listenerParams := &UcpListenerParams{}
+	listenerParams.SetSocketAddress(addr)
+	listenerParams.SetConnectionHandler(func(connRequest *UcpConnectionRequest) {
+		attrs, err := connRequest.Query(UCP_CONN_REQUEST_ATTR_FIELD_CLIENT_ADDR)
+		if err != nil {
+			t.Fatalf("Failed to query connection request %v", err)
+		}
+		clientAddr = attrs.ClientAddress
+		connRequest.Reject()
+	})
+
+	listener, err := worker1.NewListener(listenerParams)
+	if err != nil {
+		t.Fatalf("Can't create listener %v", err)
+	}
+	defer listener.Close()
+
+	listenerAttrs, _ := listener.Query(UCP_LISTENER_ATTR_FIELD_SOCKADDR)
+
+	epParams, _ := (&UcpEpParams{}).SetSockAddr(listenerAttrs.Address)
+	if _, err := epParams.SetLocalSockAddr(&net.TCPAddr{IP: net.IPv4(127, 0, 0, 1)}); err != nil {
+		t.Fatalf("Failed to set local address %v", err)
+	}
+
+	ep, err := worker2.NewEndpoint(epParams)
+	if err != nil {
+		t.Fatalf("Can't create endpoint %v", err)
+	}
+
+	for clientAddr == nil {
+		worker1.Progress()
+		worker2.Progress()
+	}
+
+	if !clientAddr.IP.Equal(net.IPv4(127, 0, 0, 1)) {
+		t.Fatalf("Client address %v is not the local address", clientAddr)
+	}
+
+	closeReq, _ := ep.CloseNonBlockingForce(nil)
+	for closeReq.GetStatus() == UCS_INPROGRESS {
+		worker1.Progress()
+		worker2.Progress()
+	}
+	closeReq.Close()
+}
+
+func TestUcpListenerAddr(t *testing.T) {
+	addr, _ := net.ResolveTCPAddr("tcp", "0.0.0.0:0")
+	context, _ := NewUcpContext((&UcpParams{}).EnableTag())
+	defer context.Close()
+	worker, _ := context.NewWorker(&UcpWorkerParams{})
+	defer worker.Close()
+
+	listenerParams := (&UcpListenerParams{}).SetConnectionHandler(func(connRequest *UcpConnectionRequest) {
+		connRequest.Reject()
+	})
+	listenerParams.SetSocketAddress(addr)
+	listener, err := worker.NewListener(listenerParams)
+	if err != nil {
+		t.Fatalf("Failed to create listener %v", err)
+	}
+	defer listener.Close()
+
+	bound, err := listener.Addr()
+	if err != nil {
+		t.Fatalf("Failed to get listener address %v", err)
+	}
+
+	if bound.Port == 0 {
+		t.Fatalf("Listener port is not assigned")
+	}
+
+	advertised, err := listener.AdvertisedAddrs()
+	if err != nil {
+		t.Fatalf("Failed to get advertised addresses %v", err)
+	}
+
+	for _, a := range advertised {
+		if (a.Port != bound.Port) || a.IP.IsUnspecified() || a.IP.IsLoopback() {
+			t.Fatalf("Unexpected advertised address %v", a)
+		}
+	}
+}
+
+func TestUcpListenerConnectionFilter(t *testing.T) {
+	const allowedId = 1
+	addr, _ := net.ResolveTCPAddr("tcp", "0.0.0.0")
+	ucpParams := (&UcpParams{}).EnableTag()
+
+	var accepted *UcpConnectionRequest
+	var filtered []uint64
+	listenerParams := (&UcpListenerParams{}).SetConnectionFilter(func(attrs *UcpConnectionRequestAttributes) bool {
+		if attrs.ClientAddress == nil {
+			t.Errorf("Client address is empty")
+		}
+		filtered = append(filtered, attrs.ClientId)
+		return attrs.ClientId == allowedId
+	}).SetConnectionHandler(func(connRequest *UcpConnectionRequest) {
+		accepted = connRequest
+	})
+	listenerParams.SetSocketAddress(addr)
+
+	serverContext, _ := NewUcpContext(ucpParams)
+	defer serverContext.Close()
+	server, _ := serverContext.NewWorker(&UcpWorkerParams{})
+	defer server.Close()
+
+	listener, err := server.NewListener(listenerParams)
+	if err != nil {
+		t.Fatalf("Failed to create listener %v", err)
+	}
+	defer listener.Close()
+	listenerAddress, _ := listener.Addr()
+
+	clientContext, _ := NewUcpContext(ucpParams)
+	defer clientContext.Close()
+
+	// The client of the other id is rejected by the filter
+	var statuses []UcsStatus
+	var eps []*UcpEp
+	var workers []*UcpWorker
+	for _, clientId := range []uint64{allowedId + 1, allowedId} {
+		worker, _ := clientContext.NewWorker((&UcpWorkerParams{}).SetClientId(clientId))
+		defer worker.Close()
+		workers = append(workers, worker)
+
+		epParams := (&UcpEpParams{}).SetPeerErrorHandling().SendClientId().
+			SetErrorHandler(func(ep *UcpEp, status UcsStatus) {
+				statuses = append(statuses, status)
+			})
+		epParams.SetSocketAddress(listenerAddress)
+		ep, err := worker.NewEndpoint(epParams)
+		if err != nil {
+			t.Fatalf("Can't create endpoint %v", err)
+		}
+		eps = append(eps, ep)
+	}
+
+	for (accepted == nil) || (len(statuses) == 0) {
+		server.Progress()
+		for _, worker := range workers {
+			worker.Progress()
+		}
+	}
+
+	if statuses[0] != UCS_ERR_REJECTED {
+		t.Fatalf("Status of the filtered client %v != %v", statuses[0], UCS_ERR_REJECTED)
+	}
+
+	if len(filtered) != 2 {
+		t.Fatalf("Filter is called for %v clients", filtered)
+	}
+	accepted.Reject()
+
+	for i, ep := range eps {
+		closeReq, _ := ep.CloseNonBlockingForce(nil)
+		for closeReq.GetStatus() == UCS_INPROGRESS {
+			server.Progress()
+			workers[i].Progress()
+		}
+		closeReq.Close()
+	}
+}
+
+func TestUcpConnectionRequestReject(t *testing.T) {
+	addr, _ := net.ResolveTCPAddr("tcp", "0.0.0.0")
+	context, _ := NewUcpContext((&UcpParams{}).EnableTag())
+	defer context.Close()
+	server, _ := context.NewWorker(&UcpWorkerParams{})
+	defer server.Close()
+	client, _ := context.NewWorker(&UcpWorkerParams{})
+	defer client.Close()
+
+	var requests []*UcpConnectionRequest
+	listenerParams := (&UcpListenerParams{}).SetConnectionHandler(func(connRequest *UcpConnectionRequest) {
+		requests = append(requests, connRequest)
+	})
+	listenerParams.SetSocketAddress(addr)
+	listener, err := server.NewListener(listenerParams)
+	if err != nil {
+		t.Fatalf("Failed to create listener %v", err)
+	}
+	listenerAddress, _ := listener.Addr()
+
+	var statuses []UcsStatus
+	connect := func() *UcpEp {
+		epParams := (&UcpEpParams{}).SetPeerErrorHandling().SetErrorHandler(func(ep *UcpEp, status UcsStatus) {
+			statuses = append(statuses, status)
+		})
+		epParams.SetSocketAddress(listenerAddress)
+		ep, err := client.NewEndpoint(epParams)
+		if err != nil {
+			t.Fatalf("Can't create endpoint %v", err)
+		}
+		return ep
+	}
+
+	progress := func(done func() bool) {
+		for !done() {
+			server.Progress()
+			client.Progress()
+		}
+	}
+
+	// The request is released by the first reject
+	eps := []*UcpEp{connect()}
+	progress(func() bool { return len(requests) == 1 })
+	if err := requests[0].Reject(); err != nil {
+		t.Fatalf("Failed to reject connection request %v", err)
+	}
+
+	if err := requests[0].Reject(); !errors.Is(err, ErrInvalidParam) {
+		t.Fatalf("Second reject returned %v", err)
+	}
+
+	if _, err := server.NewEndpointFromConnRequest(requests[0], nil); !errors.Is(err, ErrInvalidParam) {
+		t.Fatalf("Accept of rejected request returned %v", err)
+	}
+
+	if _, err := requests[0].Query(UCP_CONN_REQUEST_ATTR_FIELD_CLIENT_ADDR); !errors.Is(err, ErrInvalidParam) {
+		t.Fatalf("Query of rejected request returned %v", err)
+	}
+	progress(func() bool { return len(statuses) == 1 })
+
+	// The pending request is rejected by the listener closure
+	eps = append(eps, connect())
+	progress(func() bool { return len(requests) == 2 })
+	listener.Close()
+	progress(func() bool { return len(statuses) == 2 })
+
+	for _, status := range statuses {
+		if status != UCS_ERR_REJECTED {
+			t.Fatalf("Status is not rejected %v", status)
+		}
+	}
+
+	for _, ep := range eps {
+		closeReq, _ := ep.CloseNonBlockingForce(nil)
+		progress(func() bool { return closeReq.GetStatus() != UCS_INPROGRESS })
+		closeReq.Close()
+	}
+}
+
+func TestUcpEpConnectAddress(t *testing.T) {
+	addr, _ := net.ResolveTCPAddr("tcp", "0.0.0.0:0")
+	serverContext, _ := NewUcpContext((&UcpParams{}).EnableTag())
+	defer serverContext.Close()
+	clientContext, _ := NewUcpContext((&UcpParams{}).EnableTag())
+	defer clientContext.Close()
+	server, _ := serverContext.NewWorker(&UcpWorkerParams{})
+	defer server.Close()
+	client, _ := clientContext.NewWorker(&UcpWorkerParams{})
+	defer client.Close()
+
+	var serverEps []*UcpEp
+	listenerParams := (&UcpListenerParams{}).SetConnectionHandler(func(connRequest *UcpConnectionRequest) {
+		ep, err := server.NewEndpointFromConnRequest(connRequest, (&UcpEpParams{}).SetPeerErrorHandling())
+		if err == nil {
+			serverEps = append(serverEps, ep)
+		}
+	})
+	listenerParams.SetSocketAddress(addr)
+	listener, err := server.NewListener(listenerParams)
+	if err != nil {
+		t.Fatalf("Failed to create listener %v", err)
+	}
+	defer listener.Close()
+	bound, _ := listener.Addr()
+
+	// The client progresses its worker, so the server is progressed by another
+	// goroutine
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				server.Progress()
+			}
+		}
+	}()
+
+	epParams := (&UcpEpParams{}).SetAddress(fmt.Sprintf("localhost:%d", bound.Port))
+	if _, err := client.NewEndpoint(epParams); !errors.Is(err, ErrInvalidParam) {
+		t.Fatalf("Endpoint creation by unresolved address returned %v", err)
+	}
+
+	// The listener accepts only IPv4, so the IPv6 loopback attempt fails
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	ep, err := client.Connect(ctx, epParams)
+	if err != nil {
+		close(stop)
+		<-stopped
+		t.Fatalf("Failed to connect to localhost %v", err)
+	}
+
+	if _, err := client.Connect(ctx, (&UcpEpParams{}).SetAddress("localhost")); err == nil {
+		t.Fatalf("Address without port is connected")
+	}
+
+	closeReq, _ := ep.CloseNonBlockingForce(nil)
+	for closeReq.GetStatus() == UCS_INPROGRESS {
+		client.Progress()
+	}
+	closeReq.Close()
+
+	close(stop)
+	<-stopped
+	for _, serverEp := range serverEps {
+		if closeReq, err := serverEp.CloseNonBlockingForce(nil); err == nil {
+			closeReq.Close()
+		}
+	}
+	server.Progress()
+}
+
+func TestUcpHandshake(t *testing.T) {
+	const handshakeId = 5
+	addr, _ := net.ResolveTCPAddr("tcp", "0.0.0.0:0")
+	serverContext, _ := NewUcpContext((&UcpParams{}).EnableAM())
+	defer serverContext.Close()
+	clientContext, _ := NewUcpContext((&UcpParams{}).EnableAM())
+	defer clientContext.Close()
+	server, _ := serverContext.NewWorker(&UcpWorkerParams{})
+	defer server.Close()
+	client, _ := clientContext.NewWorker(&UcpWorkerParams{})
+	defer client.Close()
+
+	for _, worker := range []*UcpWorker{server, client} {
+		if err := worker.EnableHandshake(handshakeId); err != nil {
+			t.Fatalf("Failed to enable handshake %v", err)
+		}
+	}
+
+	requests := make(chan *UcpConnectionRequest, 2)
+	listenerParams := (&UcpListenerParams{}).SetConnectionHandler(func(connRequest *UcpConnectionRequest) {
+		requests <- connRequest
+	})
+	listenerParams.SetSocketAddress(addr)
+	listener, err := server.NewListener(listenerParams)
+	if err != nil {
+		t.Fatalf("Failed to create listener %v", err)
+	}
+	defer listener.Close()
+	bound, _ := listener.Addr()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	serverHandshake := func(conn *UcpHandshakeConn) error {
+		token, err := conn.Recv()
+		if err != nil {
+			return err
+		}
+
+		if string(token) != "secret" {
+			return errors.New("invalid token")
+		}
+		return conn.Send([]byte("welcome"))
+	}
+
+	// The connection requests are accepted outside the connection handler
+	serverEps := make(chan *UcpEp, 2)
+	serverErrs := make(chan error, 2)
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		for {
+			select {
+			case <-stop:
+				return
+			case connRequest := <-requests:
+				ep, err := server.AcceptHandshake(ctx, connRequest, (&UcpEpParams{}).SetPeerErrorHandling(),
+					serverHandshake)
+				if err != nil {
+					serverErrs <- err
+				} else {
+					serverEps <- ep
+				}
+			default:
+				server.Progress()
+			}
+		}
+	}()
+	var serverEp *UcpEp
+	defer func() {
+		close(stop)
+		<-stopped
+		if serverEp != nil {
+			if closeReq, err := serverEp.CloseNonBlockingForce(nil); err == nil {
+				closeReq.Close()
+			}
+			server.Progress()
+		}
+	}()
+
+	connect := func(token string) (*UcpEp, string, error) {
+		var reply []byte
+		epParams := (&UcpEpParams{}).SetAddress(fmt.Sprintf("127.0.0.1:%d", bound.Port))
+		ep, err := client.ConnectHandshake(ctx, epParams, func(conn *UcpHandshakeConn) error {
+			if conn.IsServer() {
+				return errors.New("client is the server")
+			}
+
+			if err := conn.Send([]byte(token)); err != nil {
+				return err
+			}
+
+			var err error
+			reply, err = conn.Recv()
+			return err
+		})
+		return ep, string(reply), err
+	}
+
+	ep, reply, err := connect("secret")
+	if err != nil {
+		t.Fatalf("Failed to connect with valid token %v", err)
+	}
+
+	if reply != "welcome" {
+		t.Fatalf("Unexpected reply %q", reply)
+	}
+
+	select {
+	case serverEp = <-serverEps:
+	case err := <-serverErrs:
+		t.Fatalf("Server failed to accept valid token %v", err)
+	}
+
+	closeReq, _ := ep.CloseNonBlockingForce(nil)
+	for closeReq.GetStatus() == UCS_INPROGRESS {
+		client.Progress()
+	}
+	closeReq.Close()
+
+	// Both sides fail the rejected handshake
+	if _, _, err := connect("guess"); !errors.Is(err, ErrRejected) {
+		t.Fatalf("Connection with invalid token returned %v", err)
+	}
+
+	select {
+	case <-serverEps:
+		t.Fatalf("Server accepted invalid token")
+	case err := <-serverErrs:
+		var handshakeErr *UcpHandshakeError
+		if !errors.As(err, &handshakeErr) || (handshakeErr.Err == nil) {
+			t.Fatalf("Unexpected server error %v", err)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package goucxtests
+
+import (
+	"sync"
+	"testing"
+	. "ucx"
+)
+
+func TestUcsLogHandler(t *testing.T) {
+	var records []UcsLogRecord
+	var recordsMu sync.Mutex
+
+	SetLogHandler(func(record *UcsLogRecord) {
+		recordsMu.Lock()
+		defer recordsMu.Unlock()
+		records = append(records, *record)
+	})
+	defer SetLogHandler(nil)
+
+	if err := SetLogLevel(UCS_LOG_LEVEL_DEBUG); err != nil {
+		t.Fatalf("Failed to set log level %v", err)
+	}
+	defer SetLogLevel(UCS_LOG_LEVEL_WARN)
+
+	ucpContext, _ := NewUcpContext((&UcpParams{}).EnableTag())
+	worker, _ := ucpContext.NewWorker(&UcpWorkerParams{})
+	worker.Close()
+	ucpContext.Close()
+
+	recordsMu.Lock()
+	numRecords := len(records)
+	for _, record := range records {
+		if record.Level > UCS_LOG_LEVEL_DEBUG && record.Level != UCS_LOG_LEVEL_PRINT {
+			t.Fatalf("Unexpected log level %v of record %v", record.Level, record)
+		}
+	}
+	recordsMu.Unlock()
+
+	if numRecords == 0 {
+		t.Fatalf("No log records received")
+	}
+
+	for _, component := range []string{"UCX", "UCS", "UCT", "UCP"} {
+		SetLogComponentLevel(component, UCS_LOG_LEVEL_INFO)
+		defer SetLogComponentLevel(component, UCS_LOG_LEVEL_TRACE_POLL)
+	}
+
+	recordsMu.Lock()
+	records = nil
+	recordsMu.Unlock()
+
+	ucpContext, _ = NewUcpContext((&UcpParams{}).EnableTag())
+	ucpContext.Close()
+
+	recordsMu.Lock()
+	defer recordsMu.Unlock()
+	for _, record := range records {
+		if record.Level == UCS_LOG_LEVEL_DEBUG {
+			t.Fatalf("Debug record is not filtered %v", record)
+		}
+	}
+}
@@ -6,6 +6,7 @@
 package goucxtests
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"testing"
@@ -114,6 +115,13 @@ func TestUcpMmap(t *testing.T) {
 	}
 
 	mapedMemory.Close()
+
+	if memType, err := context.DetectMemoryType(nativeMemory, testMemorySize); err != nil {
+		t.Fatalf("Failed to detect memory type %v", err)
+	} else if memType != UCS_MEMORY_TYPE_HOST {
+		t.Fatalf("Detected memory type %v != %v", memType, UCS_MEMORY_TYPE_HOST)
+	}
+
 	FreeNativeMemory(nativeMemory)
 	memTypeMask, _ := context.MemoryTypesMask()
 
@@ -128,6 +136,306 @@ func TestUcpMmap(t *testing.T) {
 			t.Fatalf("Failed to allocate GPU memory %v", gpuMemory)
 		}
 
+		gpuAttrs, _ := gpuMemory.Query(UCP_MEM_ATTR_FIELD_ADDRESS)
+		if memType, err := context.DetectMemoryType(gpuAttrs.Address, testMemorySize); err != nil {
+			t.Fatalf("Failed to detect memory type %v", err)
+		} else if memType != UCS_MEMORY_TYPE_CUDA {
+			t.Fatalf("Detected memory type %v != %v", memType, UCS_MEMORY_TYPE_CUDA)
+		}
+
 		gpuMemory.Close()
 	}
 }
+
+func TestUcpMemoryCache(t *testing.T) {
+	const sendData string = "Hello GO"
+
+	entity := prepareContext(t, nil)
+	entity.worker, _ = entity.context.NewWorker(&UcpWorkerParams{})
+	createSelfEp(entity)
+
+	memoryCache := entity.context.NewMemoryCache(1)
+
+	sendMem := CBytes([]byte(sendData))
+	memory1, err := memoryCache.Get(sendMem, uint64(len(sendData)))
+	if err != nil {
+		t.Fatalf("Failed to register memory %v", err)
+	}
+
+	if memory2, _ := memoryCache.Get(sendMem, uint64(len(sendData))); memory2 != memory1 {
+		t.Fatalf("Cached registration was not reused")
+	}
+	memoryCache.Put(memory1)
+	memoryCache.Put(memory1)
+
+	// Send and receive buffers are looked up in the cache, which evicts the
+	// least recently used registrations
+	recvMem := AllocateNativeMemory(uint64(len(sendData)))
+	params := (&UcpRequestParams{}).SetMemoryCache(memoryCache)
+	recvRequest, _ := entity.worker.RecvTagNonBlocking(recvMem, uint64(len(sendData)), 1, 1, params)
+	sendRequest, _ := entity.selfEp.SendTagNonBlocking(1, sendMem, uint64(len(sendData)), params)
+
+	for (sendRequest.GetStatus() == UCS_INPROGRESS) || (recvRequest.GetStatus() == UCS_INPROGRESS) {
+		entity.worker.Progress()
+	}
+
+	if recvString := string(GoBytes(recvMem, uint64(len(sendData)))); recvString != sendData {
+		t.Fatalf("Send data %s != recv data %s", sendData, recvString)
+	}
+
+	sendRequest.Close()
+	recvRequest.Close()
+	memoryCache.Invalidate(sendMem, uint64(len(sendData)))
+	memoryCache.Close()
+	FreeNativeMemory(sendMem)
+	FreeNativeMemory(recvMem)
+	entity.Close()
+}
+
+func TestUcpWorkerMemoryCache(t *testing.T) {
+	const sendData string = "Hello GO"
+
+	entity := prepareContext(t, nil)
+	if worker, _ := entity.context.NewWorker(&UcpWorkerParams{}); worker.MemoryCache() != nil {
+		t.Fatalf("Memory cache of the worker without the cache capacity")
+	} else {
+		worker.Close()
+	}
+
+	if _, err := entity.context.NewWorker((&UcpWorkerParams{}).SetMemoryCacheCapacity(-1)); err == nil {
+		t.Fatalf("Worker with the negative memory cache capacity was created")
+	}
+
+	entity.worker, _ = entity.context.NewWorker((&UcpWorkerParams{}).SetMemoryCacheCapacity(2))
+	createSelfEp(entity)
+
+	memoryCache := entity.worker.MemoryCache()
+	if memoryCache == nil {
+		t.Fatalf("Worker has no memory cache")
+	}
+
+	// The sub-slices of the backing array reuse its registration
+	data := make([]byte, 2*len(sendData))
+	memory1, err := memoryCache.GetBytes(data[:len(sendData)])
+	if err != nil {
+		t.Fatalf("Failed to register slice %v", err)
+	}
+
+	if memory2, _ := memoryCache.GetBytes(data[len(sendData):]); memory2 != memory1 {
+		t.Fatalf("Registration of the backing array was not reused")
+	}
+	memoryCache.Put(memory1)
+	memoryCache.Put(memory1)
+
+	// The slice is sent with the registration of the cache
+	copy(data, sendData)
+	recvMem := AllocateNativeMemory(uint64(len(sendData)))
+	recvRequest, _ := entity.worker.RecvTagNonBlocking(recvMem, uint64(len(sendData)), 1, 1, nil)
+	sendRequest, _ := entity.selfEp.SendTagBytesNonBlocking(1, data[:len(sendData)], nil)
+
+	for (sendRequest.GetStatus() == UCS_INPROGRESS) || (recvRequest.GetStatus() == UCS_INPROGRESS) {
+		entity.worker.Progress()
+	}
+
+	if recvString := string(GoBytes(recvMem, uint64(len(sendData)))); recvString != sendData {
+		t.Fatalf("Send data %s != recv data %s", sendData, recvString)
+	}
+
+	sendRequest.Close()
+	recvRequest.Close()
+	FreeNativeMemory(recvMem)
+	entity.Close()
+}
+
+func TestUcpAllocAndMap(t *testing.T) {
+	const sendData string = "Hello GO"
+	const testMemorySize uint64 = 4096
+
+	entity := prepareContext(t, nil)
+	entity.worker, _ = entity.context.NewWorker(&UcpWorkerParams{})
+	createSelfEp(entity)
+	defer entity.Close()
+
+	memory, view, err := entity.context.AllocAndMap(testMemorySize, nil)
+	if err != nil {
+		t.Fatalf("Failed to allocate memory %v", err)
+	}
+	defer memory.Close()
+
+	if uint64(len(view)) < testMemorySize {
+		t.Fatalf("View length %d < allocated size %d", len(view), testMemorySize)
+	}
+
+	memAttrs, _ := memory.Query(UCP_MEM_ATTR_FIELD_ADDRESS)
+	if memAttrs.Address != unsafe.Pointer(&view[0]) {
+		t.Fatalf("View doesn't point to the allocated memory")
+	}
+
+	copy(view, sendData)
+	recvMem := AllocateNativeMemory(testMemorySize)
+	defer FreeNativeMemory(recvMem)
+
+	sendParams := (&UcpRequestParams{}).SetMemory(memory)
+	sendRequest, _ := entity.selfEp.SendTagNonBlocking(1, unsafe.Pointer(&view[0]), uint64(len(sendData)), sendParams)
+	recvRequest, _ := entity.worker.RecvTagNonBlocking(recvMem, testMemorySize, 1, ^uint64(0), nil)
+
+	for (sendRequest.GetStatus() == UCS_INPROGRESS) || (recvRequest.GetStatus() == UCS_INPROGRESS) {
+		entity.worker.Progress()
+	}
+	sendRequest.Close()
+	recvRequest.Close()
+
+	if recvString := string(GoBytes(recvMem, uint64(len(sendData)))); recvString != sendData {
+		t.Fatalf("Send data %s != recv data %s", sendData, recvString)
+	}
+}
+
+func TestUcpAllocAlignedAndHugePages(t *testing.T) {
+	const testMemorySize uint64 = 4096
+	const alignment uint64 = 1 << 21
+
+	entity := prepareContext(t, nil)
+	defer entity.Close()
+
+	params := (&UcpMmapParams{}).SetAlignment(alignment).SetHugePages(UcpHugePagesTransparent).Nonblocking()
+	memory, view, err := entity.context.AllocAndMap(testMemorySize, params)
+	if err != nil {
+		t.Fatalf("Failed to allocate aligned memory %v", err)
+	}
+	defer memory.Close()
+
+	attrs, err := memory.Attributes()
+	if err != nil {
+		t.Fatalf("Failed to query memory attributes %v", err)
+	}
+
+	if (attrs.Alignment < alignment) || (attrs.HugePages != UcpHugePagesTransparent) ||
+		(attrs.PageSize == 0) || (attrs.Length < testMemorySize) {
+		t.Fatalf("Unexpected memory attributes %+v", attrs)
+	}
+
+	copy(view, "aligned")
+
+	invalid := (&UcpMmapParams{}).SetAddress(unsafe.Pointer(&view[0])).SetLength(testMemorySize).SetAlignment(alignment)
+	if _, err := entity.context.MemMap(invalid); !errors.Is(err, ErrInvalidParam) {
+		t.Fatalf("Alignment of the registered memory is accepted: %v", err)
+	}
+
+	if _, _, err := entity.context.AllocAndMap(testMemorySize, (&UcpMmapParams{}).SetAlignment(3)); !errors.Is(err,
+		ErrInvalidParam) {
+		t.Fatalf("Alignment, that is not a power of 2, is accepted: %v", err)
+	}
+}
+
+func TestUcpMemoryExport(t *testing.T) {
+	const testMemorySize uint64 = 4096
+	ucpParams := (&UcpParams{}).EnableTag().EnableRMA().EnableExportedMemh()
+
+	exporter := prepareContext(t, ucpParams)
+	defer exporter.Close()
+	importer := prepareContext(t, ucpParams)
+	defer importer.Close()
+
+	memory, _, err := exporter.context.AllocAndMap(testMemorySize, nil)
+	if err != nil {
+		t.Fatalf("Failed to allocate memory %v", err)
+	}
+	defer memory.Close()
+
+	buffer, err := memory.Export()
+	if errors.Is(err, NewUcxError(UCS_ERR_UNSUPPORTED)) {
+		t.Skip("Memory export is not supported by the memory domains")
+	} else if err != nil {
+		t.Fatalf("Failed to export memory %v", err)
+	}
+
+	imported, err := importer.context.MemImport(buffer)
+	if err != nil {
+		t.Fatalf("Failed to import memory %v", err)
+	}
+	defer imported.Close()
+
+	memAttrs, _ := imported.Query(UCP_MEM_ATTR_FIELD_LENGTH)
+	if memAttrs.Length < testMemorySize {
+		t.Fatalf("Imported length %d < exported size %d", memAttrs.Length, testMemorySize)
+	}
+}
+
+func TestUcpBootstrap(t *testing.T) {
+	const sendData string = "Hello GO bootstrap"
+	const testMemorySize uint64 = 4096
+	const testAmId uint = 7
+
+	entity := prepareContext(t, (&UcpParams{}).EnableTag().EnableRMA())
+	entity.worker, _ = entity.context.NewWorker(&UcpWorkerParams{})
+	defer entity.Close()
+
+	memory, view, err := entity.context.AllocAndMap(testMemorySize, nil)
+	if err != nil {
+		t.Fatalf("Failed to allocate memory %v", err)
+	}
+	defer memory.Close()
+
+	info, err := entity.worker.BootstrapInfo()
+	if err != nil {
+		t.Fatalf("Failed to get bootstrap info %v", err)
+	}
+
+	if err := info.AddMemory("buffer", memory); err != nil {
+		t.Fatalf("Failed to add memory %v", err)
+	}
+	info.AddAmId("hello", testAmId)
+
+	text, err := info.MarshalText()
+	if err != nil {
+		t.Fatalf("Failed to marshal bootstrap info %v", err)
+	}
+
+	var received UcpBootstrapInfo
+	if err := received.UnmarshalText(text); err != nil {
+		t.Fatalf("Failed to unmarshal bootstrap info %v", err)
+	}
+
+	if err := received.UnmarshalText(text[:len(text)/2]); err == nil {
+		t.Fatalf("Truncated bootstrap info is unmarshaled")
+	}
+
+	peer, err := entity.worker.ConnectBootstrap(&received, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect bootstrap peer %v", err)
+	}
+
+	if id, ok := peer.AmIds["hello"]; !ok || (id != testAmId) {
+		t.Fatalf("Am id %v != %v", id, testAmId)
+	}
+
+	remote := peer.Memories["buffer"]
+	if (remote == nil) || (remote.Length < testMemorySize) {
+		t.Fatalf("Remote memory %v is not restored", remote)
+	}
+
+	sendMem := CBytes([]byte(sendData))
+	defer FreeNativeMemory(sendMem)
+
+	putRequest, err := peer.Ep.RmaPutNonBlocking(sendMem, uint64(len(sendData)), remote.Address, remote.Rkey, nil)
+	if err != nil {
+		t.Fatalf("Failed to put %v", err)
+	}
+
+	flushRequest, _ := peer.Ep.FlushNonBlocking(nil)
+	for (putRequest.GetStatus() == UCS_INPROGRESS) || (flushRequest.GetStatus() == UCS_INPROGRESS) {
+		entity.worker.Progress()
+	}
+	putRequest.Close()
+	flushRequest.Close()
+
+	if recvString := string(view[:len(sendData)]); recvString != sendData {
+		t.Fatalf("Put data %s != remote data %s", sendData, recvString)
+	}
+
+	closeRequest, _ := peer.Close()
+	for closeRequest.GetStatus() == UCS_INPROGRESS {
+		entity.worker.Progress()
+	}
+	closeRequest.Close()
+}
@@ -0,0 +1,697 @@
+/*
+ * Copyright (C) 2021, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package goucxtests
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"testing"
+	"time"
+	. "ucx"
+)
+
+func TestUcpRequestWaitContext(t *testing.T) {
+	const dataLen uint64 = 4096
+	entity := prepareContext(t, nil)
+	entity.worker, _ = entity.context.NewWorker(&UcpWorkerParams{})
+	defer entity.Close()
+
+	recvMem := AllocateNativeMemory(dataLen)
+	defer FreeNativeMemory(recvMem)
+
+	// Nobody sends the message, so the request will be canceled on timeout
+	recvRequest, _ := entity.worker.RecvTagNonBlocking(recvMem, dataLen, 1, selfEpTag, nil)
+	defer recvRequest.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	if err := recvRequest.WaitContext(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("Wait returned %v instead of %v", err, context.DeadlineExceeded)
+	}
+
+	if status := recvRequest.GetStatus(); status != UCS_ERR_CANCELED {
+		t.Fatalf("Request status %v != %v", status, UCS_ERR_CANCELED)
+	}
+
+	createSelfEp(entity)
+	sendData := []byte("Hello GO context")
+	sendMem := CBytes(sendData)
+	defer FreeNativeMemory(sendMem)
+
+	recvRequest2, _ := entity.worker.RecvTagNonBlocking(recvMem, dataLen, 2, selfEpTag, nil)
+	defer recvRequest2.Close()
+	sendRequest, _ := entity.selfEp.SendTagNonBlocking(2, sendMem, uint64(len(sendData)), nil)
+	defer sendRequest.Close()
+
+	if err := recvRequest2.WaitContext(context.Background()); err != nil {
+		t.Fatalf("Failed to wait receive request %v", err)
+	}
+
+	if err := sendRequest.WaitContext(context.Background()); err != nil {
+		t.Fatalf("Failed to wait send request %v", err)
+	}
+}
+
+func TestUcpRequestWaitContextCompleted(t *testing.T) {
+	const dataLen uint64 = 1024 * 1024
+	entity := prepareContext(t, nil)
+	entity.worker, _ = entity.context.NewWorker(&UcpWorkerParams{})
+	createSelfEp(entity)
+	defer entity.Close()
+
+	sendMem := AllocateNativeMemory(dataLen)
+	defer FreeNativeMemory(sendMem)
+	recvMem := AllocateNativeMemory(dataLen)
+	defer FreeNativeMemory(recvMem)
+
+	recvRequest, _ := entity.worker.RecvTagNonBlocking(recvMem, dataLen, 1, selfEpTag, nil)
+	defer recvRequest.Close()
+	sendRequest, _ := entity.selfEp.SendTagNonBlocking(1, sendMem, dataLen, nil)
+	defer sendRequest.Close()
+
+	// The context is done before the wait, but the send can't be canceled, so
+	// the requests complete with their own status
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := sendRequest.WaitContext(ctx); err != nil {
+		t.Fatalf("Wait of send returned %v", err)
+	}
+
+	// The receive is matched by the completed rendezvous send
+	if err := recvRequest.WaitContext(ctx); err != nil {
+		t.Fatalf("Wait of receive returned %v", err)
+	}
+
+	if status := recvRequest.GetStatus(); status != UCS_OK {
+		t.Fatalf("Request status %v != %v", status, UCS_OK)
+	}
+}
+
+func TestUcpRequestDoneChannel(t *testing.T) {
+	const dataLen uint64 = 4096
+	entity := prepareContext(t, nil)
+	entity.worker, _ = entity.context.NewWorker(&UcpWorkerParams{})
+	defer entity.Close()
+	createSelfEp(entity)
+
+	sendData := []byte("Hello GO channel")
+	sendMem := CBytes(sendData)
+	defer FreeNativeMemory(sendMem)
+	recvMem := AllocateNativeMemory(dataLen)
+	defer FreeNativeMemory(recvMem)
+
+	callbackCalled := false
+	recvRequest, _ := entity.worker.RecvTagNonBlocking(recvMem, dataLen, 1, selfEpTag,
+		(&UcpRequestParams{}).EnableDoneChannel().SetCallback(func(request *UcpRequest, status UcsStatus,
+			tagInfo *UcpTagRecvInfo) {
+			callbackCalled = true
+		}))
+	defer recvRequest.Close()
+	sendRequest, _ := entity.selfEp.SendTagNonBlocking(1, sendMem, uint64(len(sendData)),
+		(&UcpRequestParams{}).EnableDoneChannel())
+	defer sendRequest.Close()
+
+	if recvRequest.Done() == nil || sendRequest.Done() == nil {
+		t.Fatalf("Done channel is not set")
+	}
+
+	for completed := 0; completed < 2; {
+		select {
+		case status := <-recvRequest.Done():
+			if status != UCS_OK {
+				t.Fatalf("Receive failed with status %v", status)
+			}
+			completed++
+		case status := <-sendRequest.Done():
+			if status != UCS_OK {
+				t.Fatalf("Send failed with status %v", status)
+			}
+			completed++
+		default:
+			entity.worker.Progress()
+		}
+	}
+
+	if !callbackCalled {
+		t.Fatalf("Receive callback was not called")
+	}
+}
+
+func TestUcpRequestCloseInProgress(t *testing.T) {
+	const dataLen uint64 = 4096
+	entity := prepareContext(t, nil)
+	entity.worker, _ = entity.context.NewWorker(&UcpWorkerParams{})
+	defer entity.Close()
+	createSelfEp(entity)
+
+	sendData := []byte("Hello GO closed")
+	sendMem := CBytes(sendData)
+	defer FreeNativeMemory(sendMem)
+	recvMem := AllocateNativeMemory(dataLen)
+	defer FreeNativeMemory(recvMem)
+
+	// The receive keeps going after the close, but its callback is not invoked
+	callbackCalled := false
+	recvRequest, _ := entity.worker.RecvTagIovNonBlocking([]UcpIov{{Buffer: recvMem, Length: dataLen}},
+		1, selfEpTag, (&UcpRequestParams{}).SetCallback(func(request *UcpRequest, status UcsStatus,
+			tagInfo *UcpTagRecvInfo) {
+			callbackCalled = true
+		}))
+	recvRequest.Close()
+
+	sendRequest, _ := entity.selfEp.SendTagNonBlocking(1, sendMem, uint64(len(sendData)), nil)
+	defer sendRequest.Close()
+
+	if err := sendRequest.WaitContext(context.Background()); err != nil {
+		t.Fatalf("Failed to wait send request %v", err)
+	}
+
+	for i := 0; i < 100; i++ {
+		entity.worker.Progress()
+	}
+
+	if callbackCalled {
+		t.Fatalf("Callback of the closed request was called")
+	}
+
+	if recvData := GoBytes(recvMem, uint64(len(sendData))); string(recvData) != string(sendData) {
+		t.Fatalf("Received %q != sent %q", recvData, sendData)
+	}
+}
+
+func TestUcpRequestStatusAfterClose(t *testing.T) {
+	const dataLen uint64 = 64
+	entity := prepareContext(t, nil)
+	entity.worker, _ = entity.context.NewWorker(&UcpWorkerParams{})
+	createSelfEp(entity)
+	defer entity.Close()
+
+	sendMem := AllocateNativeMemory(dataLen)
+	defer FreeNativeMemory(sendMem)
+	recvMem := AllocateNativeMemory(dataLen)
+	defer FreeNativeMemory(recvMem)
+
+	recvRequest, _ := entity.worker.RecvTagNonBlocking(recvMem, dataLen, 1, selfEpTag, nil)
+	sendRequest, _ := entity.selfEp.SendTagNonBlocking(1, sendMem, dataLen, nil)
+	if err := recvRequest.WaitFor(nil, nil); err != nil {
+		t.Fatalf("Failed to receive %v", err)
+	}
+
+	if err := sendRequest.WaitFor(nil, nil); err != nil {
+		t.Fatalf("Failed to send %v", err)
+	}
+	recvRequest.Close()
+	sendRequest.Close()
+
+	// The closed request isn't reused by the next operation, that is still in
+	// progress
+	nextRequest, _ := entity.worker.RecvTagNonBlocking(recvMem, dataLen, 2, selfEpTag, nil)
+	defer nextRequest.Close()
+	if nextRequest == recvRequest {
+		t.Fatalf("Closed request is returned by the next operation")
+	}
+
+	if status := recvRequest.GetStatus(); status != UCS_OK {
+		t.Fatalf("Closed request status %v != %v", status, UCS_OK)
+	}
+
+	if status := nextRequest.GetStatus(); status != UCS_INPROGRESS {
+		t.Fatalf("Request status %v != %v", status, UCS_INPROGRESS)
+	}
+	nextRequest.Cancel()
+}
+
+func TestUcpRequestPoolSize(t *testing.T) {
+	const dataLen uint64 = 64
+	entity := prepareContext(t, nil)
+	defer entity.Close()
+
+	recvMem := AllocateNativeMemory(dataLen)
+	defer FreeNativeMemory(recvMem)
+
+	// The receive, that nobody sends to, is posted after the GC empties the
+	// pools, so only the reserved request params aren't allocated
+	allocs := func(params *UcpWorkerParams) float64 {
+		worker, err := entity.context.NewWorker(params)
+		if err != nil {
+			t.Fatalf("Failed to create worker %v", err)
+		}
+		defer worker.Close()
+
+		return testing.AllocsPerRun(10, func() {
+			runtime.GC()
+			runtime.GC()
+			request, _ := worker.RecvTagNonBlocking(recvMem, dataLen, 1, selfEpTag, nil)
+			request.Cancel()
+			request.Close()
+		})
+	}
+
+	unreserved := allocs(&UcpWorkerParams{})
+	reserved := allocs((&UcpWorkerParams{}).SetRequestPoolSize(4))
+	if reserved >= unreserved {
+		t.Fatalf("Receive with the reserved params allocates %v objects, without them %v", reserved,
+			unreserved)
+	}
+
+	// The reserve is released with the worker
+	if released := allocs(&UcpWorkerParams{}); released < unreserved {
+		t.Fatalf("Receive after the worker is closed allocates %v objects instead of %v", released,
+			unreserved)
+	}
+}
+
+func TestUcpRequestCancel(t *testing.T) {
+	const dataLen uint64 = 4096
+	entity := prepareContext(t, nil)
+	entity.worker, _ = entity.context.NewWorker(&UcpWorkerParams{})
+	defer entity.Close()
+
+	recvMem := AllocateNativeMemory(dataLen)
+	defer FreeNativeMemory(recvMem)
+
+	var callbackStatus UcsStatus = UCS_INPROGRESS
+	recvRequest, _ := entity.worker.RecvTagNonBlocking(recvMem, dataLen, 1, selfEpTag,
+		(&UcpRequestParams{}).EnableDoneChannel().SetCallback(func(request *UcpRequest, status UcsStatus,
+			tagInfo *UcpTagRecvInfo) {
+			callbackStatus = status
+		}))
+	defer recvRequest.Close()
+
+	recvRequest.Cancel()
+	for recvRequest.GetStatus() == UCS_INPROGRESS {
+		entity.worker.Progress()
+	}
+
+	if status := <-recvRequest.Done(); status != UCS_ERR_CANCELED {
+		t.Fatalf("Done channel status %v != %v", status, UCS_ERR_CANCELED)
+	}
+
+	if callbackStatus != UCS_ERR_CANCELED {
+		t.Fatalf("Callback status %v != %v", callbackStatus, UCS_ERR_CANCELED)
+	}
+
+	// Completed request is not affected
+	recvRequest.Cancel()
+	if status := recvRequest.GetStatus(); status != UCS_ERR_CANCELED {
+		t.Fatalf("Request status %v != %v", status, UCS_ERR_CANCELED)
+	}
+}
+
+func TestUcpRequestDeadline(t *testing.T) {
+	const dataLen uint64 = 4096
+	entity := prepareContext(t, nil)
+	entity.worker, _ = entity.context.NewWorker(&UcpWorkerParams{})
+	defer entity.Close()
+
+	recvMem := AllocateNativeMemory(dataLen)
+	defer FreeNativeMemory(recvMem)
+
+	deadline := time.Now().Add(50 * time.Millisecond)
+	recvRequest, _ := entity.worker.RecvTagNonBlocking(recvMem, dataLen, 1, selfEpTag,
+		(&UcpRequestParams{}).EnableDoneChannel().SetDeadline(deadline))
+	defer recvRequest.Close()
+
+	// The receive, that was matched before the deadline, is not affected
+	matchedRequest, _ := entity.worker.RecvTagNonBlocking(recvMem, dataLen, 2, selfEpTag,
+		(&UcpRequestParams{}).SetDeadline(deadline))
+	defer matchedRequest.Close()
+
+	createSelfEp(entity)
+	sendRequest, _ := entity.selfEp.SendTagNonBlocking(2, recvMem, 8, nil)
+	defer sendRequest.Close()
+
+	for recvRequest.GetStatus() == UCS_INPROGRESS {
+		entity.worker.Progress()
+	}
+
+	if time.Now().Before(deadline) {
+		t.Fatalf("Request completed before the deadline")
+	}
+
+	if status := <-recvRequest.Done(); status != UCS_ERR_CANCELED {
+		t.Fatalf("Done channel status %v != %v", status, UCS_ERR_CANCELED)
+	}
+
+	if status := matchedRequest.GetStatus(); status != UCS_OK {
+		t.Fatalf("Matched request status %v != %v", status, UCS_OK)
+	}
+}
+
+func TestUcxErrorIs(t *testing.T) {
+	err := fmt.Errorf("send failed: %w", NewUcxError(UCS_ERR_CONNECTION_RESET))
+
+	if !errors.Is(err, NewUcxError(UCS_ERR_CONNECTION_RESET)) {
+		t.Fatalf("Error %v doesn't match its status", err)
+	}
+
+	if errors.Is(err, NewUcxError(UCS_ERR_CANCELED)) {
+		t.Fatalf("Error %v matches other status", err)
+	}
+
+	var ucxErr *UcxError
+	if !errors.As(err, &ucxErr) || (ucxErr.GetStatus() != UCS_ERR_CONNECTION_RESET) {
+		t.Fatalf("Failed to get status of error %v", err)
+	}
+
+	if !errors.Is(err, ErrConnectionReset) || (NewUcxError(UCS_ERR_CANCELED) != ErrCanceled) {
+		t.Fatalf("Error %v doesn't match its sentinel error", err)
+	}
+
+	if !errors.Is(UCS_ERR_ENDPOINT_TIMEOUT.Err(), ErrEndpointFailure) ||
+		errors.Is(UCS_ERR_ENDPOINT_TIMEOUT.Err(), ErrLinkFailure) {
+		t.Fatalf("Endpoint timeout doesn't match its error class")
+	}
+
+	if !errors.Is(NewUcxError(UCS_ERR_FIRST_LINK_FAILURE), ErrLinkFailure) ||
+		errors.Is(err, ErrLinkFailure) || errors.Is(err, ErrEndpointFailure) {
+		t.Fatalf("Link failure doesn't match its error class")
+	}
+
+	if (UCS_OK.Err() != nil) || (UCS_INPROGRESS.Err() != nil) {
+		t.Fatalf("Non error status is converted to error")
+	}
+}
+
+func TestUcpRequestWaitFor(t *testing.T) {
+	const dataLen uint64 = 4096
+	entity := prepareContext(t, nil)
+	entity.worker, _ = entity.context.NewWorker(&UcpWorkerParams{})
+	createSelfEp(entity)
+	defer entity.Close()
+
+	recvMem := AllocateNativeMemory(dataLen)
+	defer FreeNativeMemory(recvMem)
+
+	recvRequest, _ := entity.worker.RecvTagNonBlocking(recvMem, dataLen, 3, selfEpTag, nil)
+	defer recvRequest.Close()
+
+	if done, err := recvRequest.Poll(); done || (err != nil) {
+		t.Fatalf("Unexpected poll of pending request %v %v", done, err)
+	}
+
+	sendData := []byte("Hello GO backoff")
+	sendRequest, _ := entity.selfEp.SendTagBytesNonBlocking(3, sendData, nil)
+	defer sendRequest.Close()
+
+	// Sleeps from the start, yet completes
+	backoff := &UcpWaitBackoff{SpinCount: -1, YieldCount: -1, MaxSleep: 10 * time.Microsecond}
+	if err := recvRequest.WaitFor(nil, backoff); err != nil {
+		t.Fatalf("Failed to wait receive request %v", err)
+	}
+
+	if err := sendRequest.WaitFor(entity.worker.Progress, nil); err != nil {
+		t.Fatalf("Failed to wait send request %v", err)
+	}
+
+	if done, err := recvRequest.Poll(); !done || (err != nil) {
+		t.Fatalf("Unexpected poll of completed request %v %v", done, err)
+	}
+
+	recvRequest2, _ := entity.worker.RecvTagNonBlocking(recvMem, dataLen, 4, selfEpTag, nil)
+	defer recvRequest2.Close()
+	recvRequest2.Cancel()
+
+	if err := recvRequest2.WaitFor(nil, nil); !errors.Is(err, NewUcxError(UCS_ERR_CANCELED)) {
+		t.Fatalf("Unexpected wait error of canceled request %v", err)
+	}
+}
+
+func TestUcpRequestTruncationPolicy(t *testing.T) {
+	const msgSize = 100
+	const recvSize = 10
+
+	entity := prepareContext(t, nil)
+	entity.worker, _ = entity.context.NewWorker(&UcpWorkerParams{})
+	createSelfEp(entity)
+	defer entity.Close()
+
+	message := make([]byte, msgSize)
+	for i := range message {
+		message[i] = byte(i)
+	}
+
+	recvBuffer := AllocateNativeMemory(recvSize)
+	defer FreeNativeMemory(recvBuffer)
+
+	recv := func(policy UcpTruncationPolicy) (*UcpRequest, []byte, uint64) {
+		var length uint64
+		recvRequest, err := entity.worker.RecvTagNonBlocking(recvBuffer, recvSize, selfEpTag, selfEpTag,
+			(&UcpRequestParams{}).SetTruncationPolicy(policy).SetCallback(
+				UcpTagRecvCallback(func(request *UcpRequest, status UcsStatus, info *UcpTagRecvInfo) {
+					length = info.Length
+				})))
+		if err != nil {
+			t.Fatalf("Failed to post receive %v", err)
+		}
+
+		sendRequest, _ := entity.selfEp.SendTagBytesNonBlocking(selfEpTag, message, nil)
+		for (recvRequest.GetStatus() == UCS_INPROGRESS) || (sendRequest.GetStatus() == UCS_INPROGRESS) {
+			entity.worker.Progress()
+		}
+		sendRequest.Close()
+		return recvRequest, GoBytes(recvBuffer, recvSize), length
+	}
+
+	request, buffer, length := recv(UcpTruncationTruncate)
+	if (request.GetStatus() != UCS_OK) || (length != msgSize) || !bytes.Equal(buffer, message[:recvSize]) {
+		t.Fatalf("Unexpected truncated receive %v %v %v", request.GetStatus(), length, buffer)
+	}
+	request.Close()
+
+	request, _, length = recv(UcpTruncationGrow)
+	grown := request.GrownBuffer()
+	if (request.GetStatus() != UCS_OK) || (length != msgSize) || (grown == nil) ||
+		!bytes.Equal(GoBytes(grown, msgSize), message) {
+		t.Fatalf("Unexpected grown receive %v %v", request.GetStatus(), length)
+	}
+	FreeNativeMemory(grown)
+	request.Close()
+
+	request, _, _ = recv(UcpTruncationError)
+	if !errors.Is(NewUcxError(request.GetStatus()), ErrMessageTruncated) {
+		t.Fatalf("Unexpected status %v", request.GetStatus())
+	}
+	request.Close()
+
+	// The receive, that isn't matched yet, is canceled
+	request, _ = entity.worker.RecvTagNonBlocking(recvBuffer, recvSize, 0, selfEpTag,
+		(&UcpRequestParams{}).SetTruncationPolicy(UcpTruncationTruncate))
+	request.Cancel()
+	if request.GetStatus() != UCS_ERR_CANCELED {
+		t.Fatalf("Unexpected canceled status %v", request.GetStatus())
+	}
+	request.Close()
+}
+
+func TestUcpRequestImmediateCompletion(t *testing.T) {
+	const dataLen uint64 = 8
+	entity := prepareContext(t, nil)
+	entity.worker, _ = entity.context.NewWorker((&UcpWorkerParams{}).SetRequestPoolSize(1))
+	createSelfEp(entity)
+	defer entity.Close()
+
+	sendMem := AllocateNativeMemory(dataLen)
+	defer FreeNativeMemory(sendMem)
+	recvMem := AllocateNativeMemory(dataLen)
+	defer FreeNativeMemory(recvMem)
+
+	// The callback gets its own request even on the immediate completion
+	completed := 0
+	params := (&UcpRequestParams{}).SetOpAttrFlags(UCP_OP_ATTR_FLAG_FAST_CMPL)
+	params.SetCallback(UcpSendCallback(func(request *UcpRequest, status UcsStatus) {
+		if (request == nil) || (status != UCS_OK) {
+			t.Errorf("Unexpected send completion %v %v", request, status)
+		}
+		completed++
+	}))
+
+	recvRequest, _ := entity.worker.RecvTagNonBlocking(recvMem, dataLen, selfEpTag, selfEpTag, nil)
+	defer recvRequest.Close()
+	sendRequest, err := entity.selfEp.SendTagNonBlocking(selfEpTag, sendMem, dataLen, params)
+	if (err != nil) || (sendRequest == nil) {
+		t.Fatalf("Failed to send %v %v", sendRequest, err)
+	}
+	defer sendRequest.Close()
+
+	if err := recvRequest.WaitFor(nil, nil); err != nil {
+		t.Fatalf("Failed to receive %v", err)
+	}
+
+	if err := sendRequest.WaitFor(nil, nil); err != nil {
+		t.Fatalf("Failed to wait send request %v", err)
+	}
+
+	if completed != 1 {
+		t.Fatalf("Unexpected number of completed sends %v", completed)
+	}
+
+	// The sends without the callback are unexpected on the self endpoint, and
+	// are released with the worker
+	request, err := entity.selfEp.SendTagNonBlocking(1, sendMem, dataLen, nil)
+	if err != nil {
+		t.Fatalf("Failed to send %v", err)
+	}
+
+	if request != nil {
+		request.WaitFor(nil, nil)
+		request.Close()
+		t.Skip("Send was not completed immediately")
+	}
+
+	// The nil request reports the completed operation
+	if status := request.GetStatus(); status != UCS_OK {
+		t.Fatalf("Status of completed request %v != %v", status, UCS_OK)
+	}
+
+	if err := request.WaitContext(context.Background()); err != nil {
+		t.Fatalf("Wait of completed request returned %v", err)
+	}
+
+	if err := request.WaitFor(nil, nil); err != nil {
+		t.Fatalf("Wait of completed request returned %v", err)
+	}
+	request.Cancel()
+	request.Close()
+
+	postponed := 0
+	allocs := testing.AllocsPerRun(100, func() {
+		if request, _ := entity.selfEp.SendTagNonBlocking(1, sendMem, dataLen, nil); request != nil {
+			request.WaitFor(nil, nil)
+			request.Close()
+			postponed++
+		}
+	})
+
+	if (postponed == 0) && (allocs != 0) {
+		t.Fatalf("Immediately completed send allocates %v objects", allocs)
+	}
+}
+
+func TestUcpRequestUserData(t *testing.T) {
+	const dataLen uint64 = 8
+	type transferState struct {
+		id        int
+		completed bool
+	}
+
+	entity := prepareContext(t, nil)
+	entity.worker, _ = entity.context.NewWorker(&UcpWorkerParams{})
+	createSelfEp(entity)
+	defer entity.Close()
+
+	sendMem := AllocateNativeMemory(dataLen)
+	defer FreeNativeMemory(sendMem)
+	recvMem := AllocateNativeMemory(dataLen)
+	defer FreeNativeMemory(recvMem)
+
+	recvState := &transferState{id: 1}
+	recvParams := (&UcpRequestParams{}).SetUserData(recvState).SetCallback(func(request *UcpRequest,
+		status UcsStatus, tagInfo *UcpTagRecvInfo) {
+		request.UserData().(*transferState).completed = true
+	})
+	recvRequest, _ := entity.worker.RecvTagNonBlocking(recvMem, dataLen, selfEpTag, selfEpTag, recvParams)
+	defer recvRequest.Close()
+
+	// The immediately completed send gets its own request with the user data
+	sendState := &transferState{id: 2}
+	sendParams := (&UcpRequestParams{}).SetUserData(sendState).SetOpAttrFlags(UCP_OP_ATTR_FLAG_FAST_CMPL)
+	sendParams.SetCallback(UcpSendCallback(func(request *UcpRequest, status UcsStatus) {
+		request.UserData().(*transferState).completed = true
+	}))
+	sendRequest, err := entity.selfEp.SendTagNonBlocking(selfEpTag, sendMem, dataLen, sendParams)
+	if err != nil {
+		t.Fatalf("Failed to send %v", err)
+	}
+	defer sendRequest.Close()
+
+	if (recvRequest.UserData() != recvState) || (sendRequest.UserData() != sendState) {
+		t.Fatalf("Requests don't have the user data")
+	}
+
+	for !recvState.completed || !sendState.completed {
+		entity.worker.Progress()
+	}
+}
+
+func TestUcpRequestRetryPolicy(t *testing.T) {
+	const dataLen uint64 = 4 * 1024 * 1024
+	const backoff = 5 * time.Millisecond
+	entity := prepareContext(t, nil)
+	entity.worker, _ = entity.context.NewWorker(&UcpWorkerParams{})
+	createSelfEp(entity)
+	defer entity.Close()
+
+	sendMem := AllocateNativeMemory(dataLen)
+	defer FreeNativeMemory(sendMem)
+
+	// The rendezvous send can't complete immediately, so every attempt fails
+	var statuses []UcsStatus
+	params := (&UcpRequestParams{}).SetOpAttrFlags(UCP_OP_ATTR_FLAG_FORCE_IMM_CMPL).SetRetryPolicy(2, backoff)
+	params.SetCallback(func(request *UcpRequest, status UcsStatus) {
+		statuses = append(statuses, status)
+	})
+
+	start := time.Now()
+	request, err := entity.selfEp.SendTagNonBlocking(selfEpTag, sendMem, dataLen, params)
+	if err == nil {
+		request.Close()
+		t.Skip("Send was completed immediately")
+	}
+
+	if !errors.Is(err, ErrNoResource) {
+		t.Fatalf("Send returned %v", err)
+	}
+
+	// The backoff is doubled before the second retry
+	if elapsed := time.Since(start); elapsed < 3*backoff {
+		t.Fatalf("Retries took %v, expected at least %v", elapsed, 3*backoff)
+	}
+
+	if (len(statuses) != 1) || (statuses[0] != UCS_ERR_NO_RESOURCE) {
+		t.Fatalf("Callback is invoked with %v", statuses)
+	}
+}
+
+func TestUcpRequestSubmit(t *testing.T) {
+	// Tag operations fail before they are posted without the tag feature
+	entity := prepareContext(t, (&UcpParams{}).EnableAM())
+	entity.worker, _ = entity.context.NewWorker(&UcpWorkerParams{})
+	createSelfEp(entity)
+	defer entity.Close()
+
+	sendMem := AllocateNativeMemory(8)
+	defer FreeNativeMemory(sendMem)
+
+	var statuses []UcsStatus
+	SubmitRequest(func(params *UcpRequestParams) (*UcpRequest, error) {
+		return entity.selfEp.SendTagNonBlocking(1, sendMem, 8, params)
+	}, func(status UcsStatus) {
+		statuses = append(statuses, status)
+	})
+
+	if (len(statuses) != 1) || (statuses[0] != UCS_ERR_UNSUPPORTED) {
+		t.Fatalf("Unsupported send completed with %v", statuses)
+	}
+
+	statuses = nil
+	SubmitRequest(entity.selfEp.FlushNonBlocking, func(status UcsStatus) {
+		statuses = append(statuses, status)
+	})
+
+	for len(statuses) == 0 {
+		entity.worker.Progress()
+	}
+
+	if (len(statuses) != 1) || (statuses[0] != UCS_OK) {
+		t.Fatalf("Flush completed with %v", statuses)
+	}
+}
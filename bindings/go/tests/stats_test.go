@@ -0,0 +1,338 @@
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package goucxtests
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	. "ucx"
+	"ucx/ucxmetrics"
+	"unsafe"
+)
+
+func TestUcsStatsSnapshot(t *testing.T) {
+	ucpContext, _ := NewUcpContext((&UcpParams{}).EnableTag())
+	defer ucpContext.Close()
+	worker, _ := ucpContext.NewWorker(&UcpWorkerParams{})
+	defer worker.Close()
+
+	counters, err := UcsStatsSnapshot()
+	if !UcsStatsSupported() {
+		if !errors.Is(err, NewUcxError(UCS_ERR_UNSUPPORTED)) {
+			t.Fatalf("Unexpected snapshot error without stats support %v", err)
+		}
+		t.Skip("UCX is built without statistics support")
+	}
+
+	if err != nil {
+		t.Fatalf("Failed to take statistics snapshot %v", err)
+	}
+
+	if !UcsStatsIsActive() {
+		t.Skip("Statistics are not active, UCX_STATS_DEST is not set")
+	}
+
+	for _, counter := range counters {
+		if counter.Class == "" || counter.Name == "" {
+			t.Fatalf("Counter without name %v", counter)
+		}
+	}
+
+	var buffer bytes.Buffer
+	if err := ucxmetrics.WriteMetrics(&buffer); err != nil {
+		t.Fatalf("Failed to write metrics %v", err)
+	}
+
+	for _, line := range strings.Split(strings.TrimSpace(buffer.String()), "\n") {
+		if line != "" && !strings.HasPrefix(line, "ucx_") && !strings.HasPrefix(line, "# TYPE ucx_") {
+			t.Fatalf("Unexpected metrics line %s", line)
+		}
+	}
+}
+
+func TestUcpTransferStats(t *testing.T) {
+	const messages = 3
+	const size = 100
+
+	entity := prepareContext(t, nil)
+	entity.worker, _ = entity.context.NewWorker((&UcpWorkerParams{}).EnableTransferStats())
+	createSelfEp(entity)
+	defer entity.Close()
+
+	recvBuffer := AllocateNativeMemory(size)
+	defer FreeNativeMemory(recvBuffer)
+
+	for i := 0; i < messages; i++ {
+		recvRequest, err := entity.worker.RecvTagNonBlocking(recvBuffer, size, selfEpTag, selfEpTag, nil)
+		if err != nil {
+			t.Fatalf("Failed to post receive %v", err)
+		}
+
+		sendRequest, err := entity.selfEp.SendTagBytesNonBlocking(selfEpTag, make([]byte, size), nil)
+		if err != nil {
+			t.Fatalf("Failed to send %v", err)
+		}
+
+		for (recvRequest.GetStatus() == UCS_INPROGRESS) || (sendRequest.GetStatus() == UCS_INPROGRESS) {
+			entity.worker.Progress()
+		}
+		recvRequest.Close()
+		sendRequest.Close()
+	}
+
+	// Canceled receive is accounted as the error
+	recvRequest, _ := entity.worker.RecvTagNonBlocking(recvBuffer, size, 0, selfEpTag, nil)
+	if stats := entity.worker.Stats(); stats.PendingRequests != 1 {
+		t.Fatalf("Unexpected pending requests %v", stats.PendingRequests)
+	}
+	recvRequest.Cancel()
+	for recvRequest.GetStatus() == UCS_INPROGRESS {
+		entity.worker.Progress()
+	}
+	recvRequest.Close()
+
+	epStats := entity.selfEp.Stats()
+	if (epStats.MessagesSent != messages) || (epStats.BytesSent != messages*size) ||
+		(epStats.PendingRequests != 0) {
+		t.Fatalf("Unexpected endpoint stats %+v", epStats)
+	}
+
+	workerStats := entity.worker.Stats()
+	if (workerStats.MessagesSent != messages) || (workerStats.MessagesReceived != messages) ||
+		(workerStats.BytesReceived != messages*size) || (workerStats.PendingRequests != 0) ||
+		(workerStats.Errors != 1) {
+		t.Fatalf("Unexpected worker stats %+v", workerStats)
+	}
+
+	if eps := entity.worker.EndpointStats(); (len(eps) != 1) || (eps[0].Stats != epStats) {
+		t.Fatalf("Unexpected endpoints stats %+v", eps)
+	}
+
+	var buffer bytes.Buffer
+	if err := ucxmetrics.WriteTransferStats(&buffer, map[string]*UcpWorker{"test": entity.worker}); err != nil {
+		t.Fatalf("Failed to write transfer stats %v", err)
+	}
+
+	if !strings.Contains(buffer.String(), `ucx_worker_bytes_sent_total{worker="test"} 300`) {
+		t.Fatalf("Unexpected transfer metrics %s", buffer.String())
+	}
+}
+
+type testTraceSpan struct {
+	event  UcpTraceEvent
+	status UcsStatus
+	length uint64
+	ended  bool
+}
+
+func (s *testTraceSpan) End(status UcsStatus, length uint64) {
+	s.status = status
+	s.length = length
+	s.ended = true
+}
+
+type testTracer struct {
+	spans []*testTraceSpan
+}
+
+func (t *testTracer) Start(event *UcpTraceEvent) UcpTraceSpan {
+	span := &testTraceSpan{event: *event}
+	t.spans = append(t.spans, span)
+	return span
+}
+
+type testTraceKey struct{}
+
+func TestUcpRmaTransferStats(t *testing.T) {
+	const size = 100
+	const opSize = 8
+
+	entity := prepareContext(t, (&UcpParams{}).EnableTag().EnableRMA().EnableAtomic64Bit())
+	entity.worker, _ = entity.context.NewWorker((&UcpWorkerParams{}).EnableTransferStats())
+	createSelfEp(entity)
+	defer entity.Close()
+
+	remoteMem := memoryAllocate(entity, size, UCS_MEMORY_TYPE_HOST)
+	rkeyBuffer, _ := entity.mem.RkeyPack()
+	rkey, err := entity.selfEp.UnpackRkey(rkeyBuffer)
+	if err != nil {
+		t.Fatalf("Failed to unpack rkey %v", err)
+	}
+	defer rkey.Close()
+
+	localMem := AllocateNativeMemory(size)
+	defer FreeNativeMemory(localMem)
+
+	wait := func(request *UcpRequest, err error) {
+		if err != nil {
+			t.Fatalf("Operation failed %v", err)
+		}
+		for request.GetStatus() == UCS_INPROGRESS {
+			entity.worker.Progress()
+		}
+		request.Close()
+	}
+
+	remoteAddr := uint64(uintptr(remoteMem))
+	wait(entity.selfEp.RmaPutNonBlocking(localMem, size, remoteAddr, rkey, nil))
+	wait(entity.selfEp.RmaGetNonBlocking(localMem, size, remoteAddr, rkey, nil))
+	wait(entity.selfEp.Atomic64(UCP_ATOMIC_OP_ADD, 1, remoteAddr, rkey, nil, nil))
+
+	// The puts and the atomics are sent, and the gets are received
+	epStats := entity.selfEp.Stats()
+	if (epStats.MessagesSent != 2) || (epStats.BytesSent != size+opSize) ||
+		(epStats.MessagesReceived != 1) || (epStats.BytesReceived != size) ||
+		(epStats.PendingRequests != 0) || (epStats.Errors != 0) {
+		t.Fatalf("Unexpected endpoint stats %+v", epStats)
+	}
+
+	if workerStats := entity.worker.Stats(); workerStats != epStats {
+		t.Fatalf("Unexpected worker stats %+v", workerStats)
+	}
+}
+
+func TestUcpTracer(t *testing.T) {
+	const size = 100
+	const amId = 7
+
+	tracer := &testTracer{}
+	entity := prepareContext(t, nil)
+	entity.worker, _ = entity.context.NewWorker((&UcpWorkerParams{}).SetTracer(tracer))
+	createSelfEp(entity)
+	defer entity.Close()
+
+	recvBuffer := AllocateNativeMemory(size)
+	defer FreeNativeMemory(recvBuffer)
+
+	ctx := context.WithValue(context.Background(), testTraceKey{}, "parent")
+	recvRequest, _ := entity.worker.RecvTagNonBlocking(recvBuffer, size, selfEpTag, selfEpTag,
+		(&UcpRequestParams{}).SetTraceContext(ctx))
+	sendRequest, _ := entity.selfEp.SendTagBytesNonBlocking(selfEpTag, make([]byte, size/2), nil)
+	for (recvRequest.GetStatus() == UCS_INPROGRESS) || (sendRequest.GetStatus() == UCS_INPROGRESS) {
+		entity.worker.Progress()
+	}
+	recvRequest.Close()
+	sendRequest.Close()
+
+	received := false
+	entity.worker.SetAmRecvHandler(amId, UCP_AM_FLAG_WHOLE_MSG, func(header unsafe.Pointer, headerSize uint64,
+		data *UcpAmData, replyEp *UcpEp) UcsStatus {
+		received = true
+		return UCS_OK
+	})
+	amRequest, _ := entity.selfEp.SendAmNonBlocking(amId, nil, 0, nil, 0, 0, nil)
+	for !received || (amRequest.GetStatus() == UCS_INPROGRESS) {
+		entity.worker.Progress()
+	}
+	amRequest.Close()
+
+	// Canceled receive ends with the error
+	recvRequest, _ = entity.worker.RecvTagNonBlocking(recvBuffer, size, 0, selfEpTag, nil)
+	recvRequest.Cancel()
+	for recvRequest.GetStatus() == UCS_INPROGRESS {
+		entity.worker.Progress()
+	}
+	recvRequest.Close()
+
+	if len(tracer.spans) != 5 {
+		t.Fatalf("Unexpected number of spans %v", len(tracer.spans))
+	}
+
+	for _, span := range tracer.spans {
+		if !span.ended {
+			t.Fatalf("Span of %v isn't ended", span.event.Op)
+		}
+	}
+
+	recv, send := tracer.spans[0], tracer.spans[1]
+	if (recv.event.Op != UcpTraceTagRecv) || (recv.event.Tag != selfEpTag) || (recv.event.Size != size) ||
+		(recv.event.Context.Value(testTraceKey{}) != "parent") || (recv.event.Ep != nil) ||
+		(recv.status != UCS_OK) || (recv.length != size/2) {
+		t.Fatalf("Unexpected receive span %+v", recv)
+	}
+
+	if (send.event.Op != UcpTraceTagSend) || (send.event.Tag != selfEpTag) || (send.event.Ep == nil) ||
+		(send.status != UCS_OK) || (send.length != size/2) {
+		t.Fatalf("Unexpected send span %+v", send)
+	}
+
+	ops := map[UcpTraceOp]bool{tracer.spans[2].event.Op: true, tracer.spans[3].event.Op: true}
+	if !ops[UcpTraceAmSend] || !ops[UcpTraceAmRecv] {
+		t.Fatalf("Unexpected Active Message spans %v", ops)
+	}
+
+	if canceled := tracer.spans[4]; (canceled.event.Op != UcpTraceTagRecv) ||
+		(canceled.status != UCS_ERR_CANCELED) {
+		t.Fatalf("Unexpected canceled span %+v", canceled)
+	}
+}
+
+func TestUcpRmaTracer(t *testing.T) {
+	const size = 100
+	const opSize = 8
+
+	tracer := &testTracer{}
+	entity := prepareContext(t, (&UcpParams{}).EnableTag().EnableRMA().EnableAtomic64Bit())
+	entity.worker, _ = entity.context.NewWorker((&UcpWorkerParams{}).SetTracer(tracer))
+	createSelfEp(entity)
+	defer entity.Close()
+
+	remoteMem := memoryAllocate(entity, size, UCS_MEMORY_TYPE_HOST)
+	rkeyBuffer, _ := entity.mem.RkeyPack()
+	rkey, err := entity.selfEp.UnpackRkey(rkeyBuffer)
+	if err != nil {
+		t.Fatalf("Failed to unpack rkey %v", err)
+	}
+	defer rkey.Close()
+
+	localMem := AllocateNativeMemory(size)
+	defer FreeNativeMemory(localMem)
+
+	wait := func(request *UcpRequest, err error) {
+		if err != nil {
+			t.Fatalf("Operation failed %v", err)
+		}
+		for request.GetStatus() == UCS_INPROGRESS {
+			entity.worker.Progress()
+		}
+		request.Close()
+	}
+
+	remoteAddr := uint64(uintptr(remoteMem))
+	wait(entity.selfEp.RmaPutNonBlocking(localMem, size, remoteAddr, rkey, nil))
+	wait(entity.selfEp.RmaGetNonBlocking(localMem, size, remoteAddr, rkey, nil))
+	wait(entity.selfEp.Atomic64(UCP_ATOMIC_OP_ADD, 1, remoteAddr, rkey, nil, nil))
+	wait(entity.selfEp.FlushNonBlocking(nil))
+	wait(entity.worker.FlushNonBlocking(nil))
+
+	expected := []struct {
+		op         UcpTraceOp
+		remoteAddr uint64
+		length     uint64
+		ep         bool
+	}{
+		{UcpTraceRmaPut, remoteAddr, size, true},
+		{UcpTraceRmaGet, remoteAddr, size, true},
+		{UcpTraceAtomic, remoteAddr, opSize, true},
+		{UcpTraceFlush, 0, 0, true},
+		{UcpTraceFlush, 0, 0, false},
+	}
+
+	if len(tracer.spans) != len(expected) {
+		t.Fatalf("Unexpected number of spans %v", len(tracer.spans))
+	}
+
+	for i, span := range tracer.spans {
+		if !span.ended || (span.event.Op != expected[i].op) || (span.event.RemoteAddr != expected[i].remoteAddr) ||
+			(span.status != UCS_OK) || (span.length != expected[i].length) ||
+			((span.event.Ep != nil) != expected[i].ep) {
+			t.Fatalf("Unexpected %v span %+v", expected[i].op, span)
+		}
+	}
+}
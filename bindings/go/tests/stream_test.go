@@ -0,0 +1,205 @@
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+package goucxtests
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"strings"
+	"testing"
+	"time"
+	. "ucx"
+)
+
+// Connects two stream endpoints on the same worker through the listener.
+func connectStream(t *testing.T, worker *UcpWorker) (*UcpEp, *UcpEp) {
+	var connRequest *UcpConnectionRequest
+
+	addr, _ := net.ResolveTCPAddr("tcp", "0.0.0.0")
+	listenerParams := (&UcpListenerParams{}).SetConnectionHandler(func(request *UcpConnectionRequest) {
+		connRequest = request
+	})
+	listenerParams.SetSocketAddress(addr)
+
+	listener, err := worker.NewListener(listenerParams)
+	if err != nil {
+		t.Fatalf("Failed to create listener %v", err)
+	}
+	defer listener.Close()
+
+	listenerAttrs, _ := listener.Query(UCP_LISTENER_ATTR_FIELD_SOCKADDR)
+	epParams, _ := (&UcpEpParams{}).SetSocketAddress(listenerAttrs.Address)
+	clientEp, err := worker.NewEndpoint(epParams)
+	if err != nil {
+		t.Fatalf("Failed to create endpoint %v", err)
+	}
+
+	for connRequest == nil {
+		worker.Progress()
+	}
+
+	serverEp, err := worker.NewEndpointFromConnRequest(connRequest, nil)
+	if err != nil {
+		t.Fatalf("Failed to create endpoint from connection request %v", err)
+	}
+
+	return clientEp, serverEp
+}
+
+func TestUcpEpStreamFramed(t *testing.T) {
+	messages := []string{"Hello", "", "GO framed stream"}
+
+	ucpContext, _ := NewUcpContext((&UcpParams{}).EnableStream())
+	defer ucpContext.Close()
+	worker, _ := ucpContext.NewWorker(&UcpWorkerParams{})
+	defer worker.Close()
+
+	clientEp, serverEp := connectStream(t, worker)
+	ctx := context.Background()
+
+	for _, message := range append(messages, "Too long message") {
+		sendMem := CBytes([]byte(message))
+		if err := clientEp.SendFramed(ctx, sendMem, uint64(len(message))); err != nil {
+			t.Fatalf("Failed to send framed message %v", err)
+		}
+		FreeNativeMemory(sendMem)
+	}
+
+	recvMem := AllocateNativeMemory(16)
+	defer FreeNativeMemory(recvMem)
+
+	for _, message := range messages {
+		length, err := serverEp.RecvFramed(ctx, recvMem, 16)
+		if err != nil {
+			t.Fatalf("Failed to receive framed message %v", err)
+		}
+
+		if recvString := string(GoBytes(recvMem, length)); recvString != message {
+			t.Fatalf("Send message %s != recv message %s", message, recvString)
+		}
+	}
+
+	if _, err := serverEp.RecvFramed(ctx, recvMem, 4); !errors.Is(err, NewUcxError(UCS_ERR_MESSAGE_TRUNCATED)) {
+		t.Fatalf("Long message is not truncated %v", err)
+	}
+
+	for _, ep := range []*UcpEp{clientEp, serverEp} {
+		closeReq, _ := ep.CloseNonBlockingForce(nil)
+		for closeReq.GetStatus() == UCS_INPROGRESS {
+			worker.Progress()
+		}
+		closeReq.Close()
+	}
+}
+
+func TestUcpEpStreamReaderWriter(t *testing.T) {
+	type message struct {
+		Id   int
+		Text string
+	}
+
+	ucpContext, _ := NewUcpContext((&UcpParams{}).EnableStream())
+	defer ucpContext.Close()
+	worker, _ := ucpContext.NewWorker(&UcpWorkerParams{})
+	defer worker.Close()
+
+	clientEp, serverEp := connectStream(t, worker)
+	writer := clientEp.Writer()
+	reader := serverEp.Reader()
+
+	// Nothing is sent yet
+	reader.SetReadDeadline(time.Now().Add(10 * time.Millisecond))
+	if _, err := reader.Read(make([]byte, 1)); !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Fatalf("Read before the deadline returned %v", err)
+	}
+	reader.SetReadDeadline(time.Time{})
+
+	sent := []message{{1, "Hello"}, {2, strings.Repeat("GO stream ", 10000)}}
+	encoder := json.NewEncoder(writer)
+	for _, m := range sent {
+		if err := encoder.Encode(m); err != nil {
+			t.Fatalf("Failed to encode message %v", err)
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Failed to flush writer %v", err)
+	}
+
+	decoder := json.NewDecoder(bufio.NewReader(reader))
+	for _, m := range sent {
+		var received message
+		if err := decoder.Decode(&received); err != nil {
+			t.Fatalf("Failed to decode message %v", err)
+		}
+
+		if received != m {
+			t.Fatalf("Received message %d != sent %d", received.Id, m.Id)
+		}
+	}
+	reader.Close()
+
+	for _, ep := range []*UcpEp{clientEp, serverEp} {
+		closeReq, _ := ep.CloseNonBlockingForce(nil)
+		for closeReq.GetStatus() == UCS_INPROGRESS {
+			worker.Progress()
+		}
+		closeReq.Close()
+	}
+}
+
+func TestUcpEpQuery(t *testing.T) {
+	ucpContext, _ := NewUcpContext((&UcpParams{}).EnableStream())
+	defer ucpContext.Close()
+	worker, _ := ucpContext.NewWorker(&UcpWorkerParams{})
+	defer worker.Close()
+
+	clientEp, serverEp := connectStream(t, worker)
+
+	clientAttrs, err := clientEp.Query(UCP_EP_ATTR_FIELD_NAME, UCP_EP_ATTR_FIELD_LOCAL_SOCKADDR,
+		UCP_EP_ATTR_FIELD_REMOTE_SOCKADDR, UCP_EP_ATTR_FIELD_TRANSPORTS)
+	if err != nil {
+		t.Fatalf("Failed to query endpoint %v", err)
+	}
+
+	serverAttrs, err := serverEp.Query(UCP_EP_ATTR_FIELD_LOCAL_SOCKADDR, UCP_EP_ATTR_FIELD_REMOTE_SOCKADDR)
+	if err != nil {
+		t.Fatalf("Failed to query endpoint %v", err)
+	}
+
+	if clientAttrs.Name == "" {
+		t.Fatalf("Endpoint name is empty")
+	}
+
+	if len(clientAttrs.Transports) == 0 {
+		t.Fatalf("Endpoint has no transports")
+	}
+
+	if clientAttrs.LocalSockAddr.Port != serverAttrs.RemoteSockAddr.Port {
+		t.Fatalf("Client local port %d != server remote port %d", clientAttrs.LocalSockAddr.Port,
+			serverAttrs.RemoteSockAddr.Port)
+	}
+
+	if clientAttrs.RemoteSockAddr.Port != serverAttrs.LocalSockAddr.Port {
+		t.Fatalf("Client remote port %d != server local port %d", clientAttrs.RemoteSockAddr.Port,
+			serverAttrs.LocalSockAddr.Port)
+	}
+
+	if info := clientEp.PrintInfo(); info == "" {
+		t.Fatalf("Endpoint info is empty")
+	}
+
+	for _, ep := range []*UcpEp{clientEp, serverEp} {
+		closeReq, _ := ep.CloseNonBlockingForce(nil)
+		for closeReq.GetStatus() == UCS_INPROGRESS {
+			worker.Progress()
+		}
+		closeReq.Close()
+	}
+}
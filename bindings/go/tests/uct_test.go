@@ -0,0 +1,111 @@
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package goucxtests
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+	. "ucx"
+	"ucx/uct"
+	"ucx/ucxinfo"
+)
+
+func TestUctSelfAm(t *testing.T) {
+	worker, err := uct.NewWorker()
+	if err != nil {
+		t.Fatalf("Failed to create worker %v", err)
+	}
+	defer worker.Close()
+
+	iface, err := worker.OpenIface("self", "memory")
+	if err != nil {
+		t.Fatalf("Failed to open self interface %v", err)
+	}
+	defer iface.Close()
+
+	const amId = 3
+	var received [][]byte
+	if err := iface.SetAmHandler(amId, func(data []byte) {
+		received = append(received, append([]byte{}, data...))
+	}); err != nil {
+		t.Fatalf("Failed to set handler %v", err)
+	}
+
+	deviceAddr, ifaceAddr, err := iface.Address()
+	if err != nil {
+		t.Fatalf("Failed to get address %v", err)
+	}
+
+	ep, err := iface.Connect(deviceAddr, ifaceAddr)
+	if err != nil {
+		t.Fatalf("Failed to connect %v", err)
+	}
+	defer ep.Close()
+
+	waitReceived := func(n int) {
+		for start := time.Now(); len(received) < n; worker.Progress() {
+			if time.Since(start) > 5*time.Second {
+				t.Fatalf("Received %d messages, expected %d", len(received), n)
+			}
+		}
+	}
+
+	if err := ep.AmShort(amId, 42, []byte("short")); err != nil {
+		t.Fatalf("Failed to send short %v", err)
+	}
+	waitReceived(1)
+
+	if binary.LittleEndian.Uint64(received[0]) != 42 || string(received[0][8:]) != "short" {
+		t.Fatalf("Unexpected short message %v", received[0])
+	}
+
+	if n, err := ep.AmBcopy(amId, []byte("bcopy")); err != nil || n != len("bcopy") {
+		t.Fatalf("Failed to send bcopy %d %v", n, err)
+	}
+	waitReceived(2)
+
+	if string(received[1]) != "bcopy" {
+		t.Fatalf("Unexpected bcopy message %v", received[1])
+	}
+
+	flushed := false
+	if err := ep.Flush(func(status UcsStatus) { flushed = status == UCS_OK }); err != nil {
+		t.Fatalf("Failed to flush %v", err)
+	}
+	for !flushed {
+		worker.Progress()
+	}
+
+	if !iface.Has(ucxinfo.UCT_IFACE_FLAG_AM_ZCOPY) || !iface.Attributes().RegisterMemory {
+		t.Skip("Self transport doesn't support zero-copy active messages")
+	}
+
+	payload := []byte("zcopy")
+	buffer := CBytes(payload)
+	defer FreeNativeMemory(buffer)
+
+	memory, err := iface.RegisterMemory(buffer, uint64(len(payload)))
+	if err != nil {
+		t.Fatalf("Failed to register memory %v", err)
+	}
+	defer memory.Close()
+
+	completed := false
+	if err := ep.AmZcopy(amId, nil, []uct.Iov{{Buffer: buffer, Length: uint64(len(payload)), Memory: memory}},
+		func(status UcsStatus) { completed = status == UCS_OK }); err != nil {
+		t.Fatalf("Failed to send zcopy %v", err)
+	}
+	waitReceived(3)
+	for !completed {
+		worker.Progress()
+	}
+
+	if !bytes.Equal(received[2], payload) {
+		t.Fatalf("Unexpected zcopy message %v", received[2])
+	}
+}
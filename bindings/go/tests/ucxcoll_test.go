@@ -0,0 +1,119 @@
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package goucxtests
+
+import (
+	"bytes"
+	"encoding/binary"
+	"sync"
+	"testing"
+	. "ucx"
+	"ucx/ucxcoll"
+)
+
+func TestUcxCollGroup(t *testing.T) {
+	const groupSize = 3
+
+	entities := make([]*TestEntity, groupSize)
+	for i := range entities {
+		entities[i] = prepareContext(t, (&UcpParams{}).EnableTag())
+		entities[i].worker, _ = entities[i].context.NewWorker(&UcpWorkerParams{})
+		defer entities[i].Close()
+	}
+
+	groups := make([]*ucxcoll.Group, groupSize)
+	for rank, entity := range entities {
+		eps := make([]*UcpEp, groupSize)
+		for peer, remote := range entities {
+			if peer == rank {
+				continue
+			}
+
+			address, _ := remote.worker.GetAddress()
+			ep, err := entity.worker.NewEndpoint((&UcpEpParams{}).SetUcpAddress(address))
+			address.Close()
+			if err != nil {
+				t.Fatalf("Failed to create endpoint %v", err)
+			}
+			eps[peer] = ep
+		}
+
+		group, err := ucxcoll.NewGroup(entity.worker, rank, eps, ucxcoll.Config{GroupId: 1})
+		if err != nil {
+			t.Fatalf("Failed to create group %v", err)
+		}
+		groups[rank] = group
+
+		defer func(entity *TestEntity, eps []*UcpEp) {
+			for _, ep := range eps {
+				if ep == nil {
+					continue
+				}
+
+				closeReq, _ := ep.CloseNonBlockingForce(nil)
+				for closeReq.GetStatus() == UCS_INPROGRESS {
+					entity.worker.Progress()
+				}
+				closeReq.Close()
+			}
+		}(entity, eps)
+	}
+
+	// Every member progresses its own worker in the blocking collectives
+	var wg sync.WaitGroup
+	for _, group := range groups {
+		wg.Add(1)
+		go func(g *ucxcoll.Group) {
+			defer wg.Done()
+
+			if err := g.Barrier(); err != nil {
+				t.Errorf("Rank %v failed barrier %v", g.Rank(), err)
+				return
+			}
+
+			data := make([]byte, 8)
+			if g.Rank() == 1 {
+				copy(data, "rank one")
+			}
+			if err := g.Broadcast(data, 1); err != nil {
+				t.Errorf("Rank %v failed broadcast %v", g.Rank(), err)
+				return
+			}
+			if string(data) != "rank one" {
+				t.Errorf("Rank %v broadcast data %q", g.Rank(), data)
+			}
+
+			send := make([]byte, 16)
+			binary.LittleEndian.PutUint64(send, uint64(g.Rank()+1))
+			binary.LittleEndian.PutUint64(send[8:], uint64(g.Rank()))
+			recv := make([]byte, 16)
+			if err := g.Allreduce(send, recv, ucxcoll.SumInt64); err != nil {
+				t.Errorf("Rank %v failed allreduce %v", g.Rank(), err)
+				return
+			}
+			if sum := binary.LittleEndian.Uint64(recv); sum != 6 {
+				t.Errorf("Rank %v allreduce sum %v != 6", g.Rank(), sum)
+			}
+			if sum := binary.LittleEndian.Uint64(recv[8:]); sum != 3 {
+				t.Errorf("Rank %v allreduce second sum %v != 3", g.Rank(), sum)
+			}
+
+			gathered := make([]byte, 2*groupSize)
+			if err := g.Allgather([]byte{byte(g.Rank()), 'x'}, gathered); err != nil {
+				t.Errorf("Rank %v failed allgather %v", g.Rank(), err)
+				return
+			}
+			if !bytes.Equal(gathered, []byte{0, 'x', 1, 'x', 2, 'x'}) {
+				t.Errorf("Rank %v allgather data %v", g.Rank(), gathered)
+			}
+
+			if err := g.Allgather([]byte{1}, gathered); err != ucxcoll.ErrSize {
+				t.Errorf("Rank %v allgather of wrong size: %v", g.Rank(), err)
+			}
+		}(group)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,275 @@
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package goucxtests
+
+import (
+	"errors"
+	"testing"
+	"time"
+	. "ucx"
+	"ucx/ucxconn"
+)
+
+func TestUcxConnManagerReconnect(t *testing.T) {
+	ucpContext, _ := NewUcpContext((&UcpParams{}).EnableTag())
+	defer ucpContext.Close()
+	ucpWorker, err := ucpContext.NewWorker(&UcpWorkerParams{})
+	if err != nil {
+		t.Fatalf("Failed to create a worker %v", err)
+	}
+	defer ucpWorker.Close()
+
+	address, _ := ucpWorker.GetAddress()
+	addressBytes, _ := address.MarshalBinary()
+	address.Close()
+
+	loop, err := ucpWorker.StartProgressLoop(nil)
+	if err != nil {
+		t.Fatalf("Failed to start progress loop %v", err)
+	}
+	defer loop.Stop()
+
+	// The first attempts fail, so the send is queued until the reconnect
+	attempts := 0
+	var states []ucxconn.State
+	manager, err := ucxconn.NewManager(loop, ucpWorker, ucxconn.Config{
+		EndpointParams: func(peer string) (*UcpEpParams, error) {
+			if attempts++; attempts < 3 {
+				return nil, NewUcxError(UCS_ERR_UNREACHABLE)
+			}
+			return (&UcpEpParams{}).SetUcpAddressBytes(addressBytes), nil
+		},
+		InitialBackoff: time.Millisecond,
+		OnStateChange: func(peer string, state ucxconn.State) {
+			states = append(states, state)
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create manager %v", err)
+	}
+
+	recvMem := AllocateNativeMemory(8)
+	defer FreeNativeMemory(recvMem)
+	var recvRequest *UcpRequest
+	loop.Execute(func() {
+		recvRequest, _ = ucpWorker.RecvTagNonBlocking(recvMem, 8, 1, ^uint64(0),
+			(&UcpRequestParams{}).EnableDoneChannel())
+	})
+
+	select {
+	case err := <-manager.Send("self", 1, []byte("Hello GO")):
+		if err != nil {
+			t.Fatalf("Failed to send %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("Send was not completed after reconnect")
+	}
+
+	<-recvRequest.Done()
+	if data := string(GoBytes(recvMem, 8)); data != "Hello GO" {
+		t.Fatalf("Received %q != sent", data)
+	}
+	loop.Execute(recvRequest.Close)
+
+	if state := manager.State("self"); state != ucxconn.StateConnected {
+		t.Fatalf("Peer state %v != connected", state)
+	}
+
+	if attempts != 3 {
+		t.Fatalf("Number of connection attempts %d != 3", attempts)
+	}
+
+	if (len(states) < 2) || (states[len(states)-1] != ucxconn.StateConnected) ||
+		(states[len(states)-2] != ucxconn.StateConnecting) {
+		t.Fatalf("Unexpected state changes %v", states)
+	}
+
+	if err := manager.Close(); err != nil {
+		t.Fatalf("Failed to close manager %v", err)
+	}
+
+	if err := <-manager.Send("self", 1, []byte("Hello GO")); !errors.Is(err, ucxconn.ErrClosed) {
+		t.Fatalf("Send after close returned %v", err)
+	}
+}
+
+func TestUcxConnManagerSendUnsupported(t *testing.T) {
+	// The endpoint is connected, but the tag send fails before it's posted
+	ucpContext, _ := NewUcpContext((&UcpParams{}).EnableAM())
+	defer ucpContext.Close()
+	ucpWorker, err := ucpContext.NewWorker(&UcpWorkerParams{})
+	if err != nil {
+		t.Fatalf("Failed to create a worker %v", err)
+	}
+	defer ucpWorker.Close()
+
+	address, _ := ucpWorker.GetAddress()
+	addressBytes, _ := address.MarshalBinary()
+	address.Close()
+
+	loop, err := ucpWorker.StartProgressLoop(nil)
+	if err != nil {
+		t.Fatalf("Failed to start progress loop %v", err)
+	}
+	defer loop.Stop()
+
+	manager, err := ucxconn.NewManager(loop, ucpWorker, ucxconn.Config{
+		EndpointParams: func(peer string) (*UcpEpParams, error) {
+			return (&UcpEpParams{}).SetUcpAddressBytes(addressBytes), nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create manager %v", err)
+	}
+	defer manager.Close()
+
+	select {
+	case err := <-manager.Send("self", 1, []byte("Hello GO")):
+		if !errors.Is(err, ErrUnsupported) {
+			t.Fatalf("Send returned %v instead of %v", err, ErrUnsupported)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("Failed send was not completed")
+	}
+}
+
+func TestUcxConnManagerIdle(t *testing.T) {
+	ucpContext, _ := NewUcpContext((&UcpParams{}).EnableTag())
+	defer ucpContext.Close()
+	ucpWorker, err := ucpContext.NewWorker(&UcpWorkerParams{})
+	if err != nil {
+		t.Fatalf("Failed to create a worker %v", err)
+	}
+	defer ucpWorker.Close()
+
+	address, _ := ucpWorker.GetAddress()
+	addressBytes, _ := address.MarshalBinary()
+	address.Close()
+
+	loop, err := ucpWorker.StartProgressLoop(nil)
+	if err != nil {
+		t.Fatalf("Failed to start progress loop %v", err)
+	}
+	defer loop.Stop()
+
+	// The first notification keeps the endpoint for another timeout
+	idle := make(chan string, 2)
+	calls := 0
+	manager, err := ucxconn.NewManager(loop, ucpWorker, ucxconn.Config{
+		EndpointParams: func(peer string) (*UcpEpParams, error) {
+			return (&UcpEpParams{}).SetUcpAddressBytes(addressBytes), nil
+		},
+		IdleTimeout:   50 * time.Millisecond,
+		SweepInterval: 10 * time.Millisecond,
+		OnIdle: func(peer string) bool {
+			if calls++; calls <= 2 {
+				idle <- peer
+			}
+			return calls > 1
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create manager %v", err)
+	}
+	defer manager.Close()
+
+	if err := <-manager.Send("self", 1, []byte("Hello GO")); err != nil {
+		t.Fatalf("Failed to send %v", err)
+	}
+
+	if err := manager.SetIdleTimeout("other", time.Second); !errors.Is(err, ucxconn.ErrRemoved) {
+		t.Fatalf("Idle timeout of unknown peer returned %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		select {
+		case peer := <-idle:
+			if peer != "self" {
+				t.Fatalf("Unexpected idle peer %v", peer)
+			}
+		case <-time.After(10 * time.Second):
+			t.Fatalf("Peer didn't become idle")
+		}
+	}
+
+	deadline := time.Now().Add(10 * time.Second)
+	for manager.State("self") != ucxconn.StateDisconnected {
+		if time.Now().After(deadline) {
+			t.Fatalf("Idle peer was not removed")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// The next send connects again
+	if err := <-manager.Send("self", 1, []byte("Hello GO")); err != nil {
+		t.Fatalf("Failed to send after the idle closure %v", err)
+	}
+}
+
+func TestUcxConnPool(t *testing.T) {
+	ucpContext, _ := NewUcpContext((&UcpParams{}).EnableTag())
+	defer ucpContext.Close()
+	ucpWorker, err := ucpContext.NewWorker(&UcpWorkerParams{})
+	if err != nil {
+		t.Fatalf("Failed to create a worker %v", err)
+	}
+	defer ucpWorker.Close()
+
+	address, _ := ucpWorker.GetAddress()
+	addressBytes, _ := address.MarshalBinary()
+	address.Close()
+
+	loop, err := ucpWorker.StartProgressLoop(nil)
+	if err != nil {
+		t.Fatalf("Failed to start progress loop %v", err)
+	}
+	defer loop.Stop()
+
+	pool := ucxconn.NewPool(loop, ucpWorker, ucxconn.PoolConfig{IdleTimeout: 10 * time.Millisecond})
+
+	ep1, err := pool.GetWorker(addressBytes)
+	if err != nil {
+		t.Fatalf("Failed to get endpoint %v", err)
+	}
+
+	ep2, err := pool.GetWorker(addressBytes)
+	if err != nil {
+		t.Fatalf("Failed to get endpoint %v", err)
+	}
+
+	if ep1.Ep() != ep2.Ep() {
+		t.Fatalf("Endpoints to the same worker are not shared")
+	}
+
+	// The endpoint in use isn't evicted
+	pool.Put(ep1)
+	time.Sleep(50 * time.Millisecond)
+	if count := pool.Len(); count != 1 {
+		t.Fatalf("Pool has %d endpoints != 1", count)
+	}
+
+	pool.Put(ep2)
+	for start := time.Now(); pool.Len() != 0; time.Sleep(time.Millisecond) {
+		if time.Since(start) > 10*time.Second {
+			t.Fatalf("Idle endpoint was not evicted")
+		}
+	}
+
+	ep3, err := pool.GetWorker(addressBytes)
+	if err != nil {
+		t.Fatalf("Failed to get endpoint %v", err)
+	}
+
+	// The idle endpoint is closed by the pool closure
+	pool.Put(ep3)
+	if err := pool.Close(); err != nil {
+		t.Fatalf("Failed to close pool %v", err)
+	}
+
+	if _, err := pool.GetWorker(addressBytes); !errors.Is(err, ucxconn.ErrPoolClosed) {
+		t.Fatalf("Get after close returned %v", err)
+	}
+}
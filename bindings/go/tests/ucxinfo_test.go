@@ -0,0 +1,116 @@
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package goucxtests
+
+import (
+	"testing"
+	. "ucx"
+	"ucx/ucxinfo"
+)
+
+func TestUcxInfoDevices(t *testing.T) {
+	devices, err := ucxinfo.Devices()
+	if err != nil {
+		t.Fatalf("Failed to query devices %v", err)
+	}
+
+	var self *ucxinfo.Device
+	for i := range devices {
+		if devices[i].Transport == "" || devices[i].Device == "" {
+			t.Fatalf("Device without transport or device name: %+v", devices[i])
+		}
+
+		if devices[i].Transport == "self" {
+			self = &devices[i]
+		}
+	}
+
+	if self == nil {
+		t.Fatalf("Self transport is not found among %d devices", len(devices))
+	}
+
+	if self.Err != nil {
+		t.Fatalf("Failed to query self interface %v", self.Err)
+	}
+
+	if self.Type != ucxinfo.UCT_DEVICE_TYPE_SELF {
+		t.Fatalf("Unexpected self device type %v", self.Type)
+	}
+
+	if !self.Has(ucxinfo.UCT_IFACE_FLAG_AM_SHORT) || (self.Am.MaxShort == 0) {
+		t.Fatalf("Self transport doesn't support short active messages: %+v", self)
+	}
+
+	if self.BandwidthDedicated+self.BandwidthShared <= 0 {
+		t.Fatalf("Unexpected self bandwidth %+v", self)
+	}
+}
+
+func TestUcxInfoMemoryDomains(t *testing.T) {
+	domains, err := ucxinfo.MemoryDomains()
+	if err != nil {
+		t.Fatalf("Failed to query memory domains %v", err)
+	}
+
+	var self *ucxinfo.MemoryDomain
+	for i := range domains {
+		if domains[i].Name == "self" {
+			self = &domains[i]
+		}
+	}
+
+	if self == nil {
+		t.Fatalf("Self memory domain is not found among %d domains", len(domains))
+	}
+
+	if !self.CanRegister(UCS_MEMORY_TYPE_HOST) {
+		t.Fatalf("Self memory domain can't register host memory: %+v", self)
+	}
+
+	if self.ExportedMemh {
+		t.Fatalf("Self memory domain exports the memory handles: %+v", self)
+	}
+
+	detector, err := ucxinfo.NewMemoryTypeDetector()
+	if err != nil {
+		t.Fatalf("Failed to create memory type detector %v", err)
+	}
+	defer detector.Close()
+
+	buffer := AllocateNativeMemory(4096)
+	defer FreeNativeMemory(buffer)
+	if memType := detector.Detect(buffer, 4096); memType != UCS_MEMORY_TYPE_HOST {
+		t.Fatalf("Unexpected memory type of the host buffer %v", memType)
+	}
+}
+
+func TestUcxInfoRegistrationCost(t *testing.T) {
+	domains, err := ucxinfo.MemoryDomains()
+	if err != nil {
+		t.Fatalf("Failed to query memory domains %v", err)
+	}
+
+	for i := range domains {
+		domain := &domains[i]
+		if domain.RegistrationCost(1<<20) < domain.RegistrationCost(0) {
+			t.Fatalf("Registration cost decreases with the size: %+v", domain)
+		}
+
+		if domain.MaxReg < ^uint64(0) && domain.PreferRegistration(UCS_MEMORY_TYPE_HOST, domain.MaxReg+1, 0) {
+			t.Fatalf("Registration is preferred above the maximal size: %+v", domain)
+		}
+
+		if domain.CanRegister(UCS_MEMORY_TYPE_HOST) &&
+			!domain.PreferRegistration(UCS_MEMORY_TYPE_HOST, 4096, 0) {
+			t.Fatalf("Copy is preferred without the copy bandwidth: %+v", domain)
+		}
+
+		if !domain.CanRegister(UCS_MEMORY_TYPE_HOST) &&
+			domain.PreferRegistration(UCS_MEMORY_TYPE_HOST, 4096, 0) {
+			t.Fatalf("Registration is preferred for the unsupported memory type: %+v", domain)
+		}
+	}
+}
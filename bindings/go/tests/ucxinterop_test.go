@@ -0,0 +1,299 @@
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package goucxtests
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+	. "ucx"
+	"ucx/ucxinterop"
+)
+
+// Programs of the other bindings, that the Go peers are run against, if they
+// are set, e.g. UCX_INTEROP_CLIENT_SERVER=<build>/examples/ucp_client_server
+// and UCX_INTEROP_JUCX="java -cp <jucx jar>".
+const (
+	clientServerEnv = "UCX_INTEROP_CLIENT_SERVER"
+	jucxEnv         = "UCX_INTEROP_JUCX"
+)
+
+var anyAddr, _ = net.ResolveTCPAddr("tcp", "0.0.0.0:0")
+
+func loopbackAddr(addr *net.TCPAddr) *net.TCPAddr {
+	return &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: addr.Port}
+}
+
+func freePort(t *testing.T) int {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to find free port %v", err)
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+// Worker of the peer, that is progressed by one goroutine.
+func newInteropWorker(t *testing.T, params *UcpParams) (*UcpContext, *UcpWorker) {
+	context, err := NewUcpContext(params)
+	if err != nil {
+		t.Fatalf("Failed to create context %v", err)
+	}
+
+	worker, err := context.NewWorker(&UcpWorkerParams{})
+	if err != nil {
+		context.Close()
+		t.Fatalf("Failed to create worker %v", err)
+	}
+	return context, worker
+}
+
+func closeInteropWorker(context *UcpContext, worker *UcpWorker) {
+	worker.Close()
+	context.Close()
+}
+
+// Program of the other bindings, which output is logged by the test.
+type interopProgram struct {
+	cmd *exec.Cmd
+	// Closed once the output is read to the end
+	output chan struct{}
+}
+
+// Starts the program and waits until it prints the line with the prefix.
+func startProgram(t *testing.T, command string, args []string, ready string) *interopProgram {
+	fields := strings.Fields(command)
+	p := &interopProgram{
+		cmd:    exec.Command(fields[0], append(fields[1:], args...)...),
+		output: make(chan struct{}),
+	}
+	stdout, _ := p.cmd.StdoutPipe()
+	p.cmd.Stderr = p.cmd.Stdout
+	if err := p.cmd.Start(); err != nil {
+		t.Fatalf("Failed to start %v %v", command, err)
+	}
+
+	started := make(chan struct{})
+	go func(prefix string) {
+		defer close(p.output)
+		scanner := bufio.NewScanner(stdout)
+		for scanner.Scan() {
+			t.Logf("%v: %v", fields[len(fields)-1], scanner.Text())
+			if (prefix != "") && strings.HasPrefix(scanner.Text(), prefix) {
+				close(started)
+				prefix = ""
+			}
+		}
+	}(ready)
+
+	if ready != "" {
+		select {
+		case <-started:
+		case <-time.After(30 * time.Second):
+			p.kill()
+			t.Fatalf("%v didn't start", command)
+		}
+	}
+	return p
+}
+
+func (p *interopProgram) wait() error {
+	<-p.output
+	return p.cmd.Wait()
+}
+
+func (p *interopProgram) kill() {
+	p.cmd.Process.Kill()
+	p.wait()
+}
+
+func TestUcxInteropFormats(t *testing.T) {
+	if data := ucxinterop.TestString(5); !bytes.Equal(data, []byte("ABCD\x00")) {
+		t.Fatalf("Test string %q doesn't match the C examples", data)
+	}
+
+	// Values of java.nio.ByteBuffer.hashCode()
+	for _, c := range []struct {
+		data     []byte
+		hashCode int32
+	}{{nil, 1}, {[]byte{1, 2, 3}, 32737}, {[]byte{0xff}, 30}} {
+		if hashCode := ucxinterop.ByteBufferHashCode(c.data); hashCode != c.hashCode {
+			t.Fatalf("Hash code of %v %v != %v", c.data, hashCode, c.hashCode)
+		}
+	}
+
+	b := &ucxinterop.RemoteBuffer{Address: 0x1000, Size: 16, Rkey: []byte{7, 8}, HashCode: -2}
+	data, _ := b.MarshalBinary()
+	expected := []byte{0, 0, 0, 0, 0, 0, 0x10, 0, 0, 0, 0, 0, 0, 0, 0, 16, 0, 0, 0, 2, 7, 8,
+		0xff, 0xff, 0xff, 0xfe}
+	if !bytes.Equal(data, expected) {
+		t.Fatalf("Remote buffer %v doesn't match the Java layout %v", data, expected)
+	}
+
+	var decoded ucxinterop.RemoteBuffer
+	if err := decoded.UnmarshalBinary(data); (err != nil) || (decoded.Address != b.Address) ||
+		(decoded.Size != b.Size) || !bytes.Equal(decoded.Rkey, b.Rkey) || (decoded.HashCode != b.HashCode) {
+		t.Fatalf("Decoded remote buffer %+v != %+v %v", decoded, b, err)
+	}
+
+	if err := decoded.UnmarshalBinary(data[:21]); !errors.Is(err, ucxinterop.ErrMismatch) {
+		t.Fatalf("Truncated remote buffer returned %v", err)
+	}
+}
+
+func TestUcxInteropClientServer(t *testing.T) {
+	for _, comm := range []ucxinterop.CommType{ucxinterop.CommStream, ucxinterop.CommTag, ucxinterop.CommAm} {
+		// The large messages are sent by the rendezvous protocol
+		for _, size := range []uint64{16, 1 << 20} {
+			t.Logf("Testing %v messages of %v bytes", comm, size)
+			serverContext, serverWorker := newInteropWorker(t, comm.Features(&UcpParams{}))
+			clientContext, clientWorker := newInteropWorker(t, comm.Features(&UcpParams{}))
+
+			clientErr := make(chan error, 1)
+			config := ucxinterop.ClientServerConfig{Comm: comm, Iterations: 3, MessageSize: size}
+			config.OnListen = func(addr *net.TCPAddr) {
+				go func() {
+					clientErr <- ucxinterop.RunClientServerClient(clientWorker, loopbackAddr(addr), config)
+				}()
+			}
+
+			if err := ucxinterop.RunClientServerServer(serverWorker, anyAddr, config); err != nil {
+				t.Fatalf("Server failed %v", err)
+			}
+
+			if err := <-clientErr; err != nil {
+				t.Fatalf("Client failed %v", err)
+			}
+
+			closeInteropWorker(clientContext, clientWorker)
+			closeInteropWorker(serverContext, serverWorker)
+		}
+	}
+}
+
+func TestUcxInteropReadBW(t *testing.T) {
+	params := (&UcpParams{}).EnableTag().EnableRMA()
+	receiverContext, receiverWorker := newInteropWorker(t, params)
+	defer closeInteropWorker(receiverContext, receiverWorker)
+	senderContext, senderWorker := newInteropWorker(t, params)
+	defer closeInteropWorker(senderContext, senderWorker)
+
+	senderErr := make(chan error, 1)
+	config := ucxinterop.ReadBWConfig{Iterations: 2, Size: 1 << 16}
+	config.OnListen = func(addr *net.TCPAddr) {
+		go func() {
+			senderErr <- ucxinterop.RunReadBWSender(senderContext, senderWorker, loopbackAddr(addr), config)
+		}()
+	}
+
+	if err := ucxinterop.RunReadBWReceiver(receiverContext, receiverWorker, anyAddr, config); err != nil {
+		t.Fatalf("Receiver failed %v", err)
+	}
+
+	if err := <-senderErr; err != nil {
+		t.Fatalf("Sender failed %v", err)
+	}
+}
+
+// The Go peers against ucp_client_server of the C examples.
+func TestUcxInteropClientServerC(t *testing.T) {
+	program := os.Getenv(clientServerEnv)
+	if program == "" {
+		t.Skipf("%v is not set", clientServerEnv)
+	}
+
+	for _, comm := range []ucxinterop.CommType{ucxinterop.CommStream, ucxinterop.CommTag, ucxinterop.CommAm} {
+		config := ucxinterop.ClientServerConfig{Comm: comm, Iterations: 3}
+		args := []string{"-c", comm.String(), "-i", fmt.Sprint(config.Iterations)}
+
+		// Go client, the server of the program serves the clients until it's
+		// killed
+		port := freePort(t)
+		server := startProgram(t, program, append(args, "-p", fmt.Sprint(port)), "server is listening")
+		context, worker := newInteropWorker(t, comm.Features(&UcpParams{}))
+		err := ucxinterop.RunClientServerClient(worker, &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: port},
+			config)
+		closeInteropWorker(context, worker)
+		server.kill()
+		if err != nil {
+			t.Fatalf("Go client against %v server failed %v", comm, err)
+		}
+
+		// Go server
+		var client *interopProgram
+		config.OnListen = func(addr *net.TCPAddr) {
+			client = startProgram(t, program, append(args, "-a", "127.0.0.1", "-p", fmt.Sprint(addr.Port)), "")
+		}
+		context, worker = newInteropWorker(t, comm.Features(&UcpParams{}))
+		err = ucxinterop.RunClientServerServer(worker, anyAddr, config)
+		closeInteropWorker(context, worker)
+		if client != nil {
+			if waitErr := client.wait(); (err == nil) && (waitErr != nil) {
+				err = waitErr
+			}
+		}
+
+		if err != nil {
+			t.Fatalf("Go server against %v client failed %v", comm, err)
+		}
+	}
+}
+
+// The Go peers against the read bandwidth benchmark of the Java bindings.
+func TestUcxInteropReadBWJava(t *testing.T) {
+	java := os.Getenv(jucxEnv)
+	if java == "" {
+		t.Skipf("%v is not set", jucxEnv)
+	}
+
+	const examples = "org.openucx.jucx.examples."
+	config := ucxinterop.ReadBWConfig{Iterations: 2, Size: 1 << 16}
+	args := func(port int) []string {
+		return []string{"s=127.0.0.1", fmt.Sprintf("p=%v", port), fmt.Sprintf("n=%v", config.Iterations),
+			fmt.Sprintf("t=%v", config.Size)}
+	}
+	params := (&UcpParams{}).EnableTag().EnableRMA()
+
+	// Go sender
+	port := freePort(t)
+	receiver := startProgram(t, java, append([]string{examples + "UcxReadBWBenchmarkReceiver"}, args(port)...),
+		"Waiting for connections")
+	context, worker := newInteropWorker(t, params)
+	err := ucxinterop.RunReadBWSender(context, worker, &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: port},
+		config)
+	closeInteropWorker(context, worker)
+	if waitErr := receiver.wait(); (err == nil) && (waitErr != nil) {
+		err = waitErr
+	}
+
+	if err != nil {
+		t.Fatalf("Go sender against Java receiver failed %v", err)
+	}
+
+	// Go receiver
+	var sender *interopProgram
+	config.OnListen = func(addr *net.TCPAddr) {
+		sender = startProgram(t, java, append([]string{examples + "UcxReadBWBenchmarkSender"}, args(addr.Port)...),
+			"")
+	}
+	context, worker = newInteropWorker(t, params)
+	err = ucxinterop.RunReadBWReceiver(context, worker, anyAddr, config)
+	closeInteropWorker(context, worker)
+	if sender != nil {
+		sender.wait()
+	}
+
+	if err != nil {
+		t.Fatalf("Go receiver against Java sender failed %v", err)
+	}
+}
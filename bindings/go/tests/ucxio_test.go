@@ -0,0 +1,67 @@
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package goucxtests
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"testing/iotest"
+	. "ucx"
+	"ucx/ucxio"
+)
+
+func TestUcxIoLargeObject(t *testing.T) {
+	ucpContext, _ := NewUcpContext((&UcpParams{}).EnableStream())
+	defer ucpContext.Close()
+	// Sender and receiver progress the worker concurrently
+	worker, _ := ucpContext.NewWorker((&UcpWorkerParams{}).SetThreadMode(UCS_THREAD_MODE_MULTI))
+	defer worker.Close()
+
+	clientEp, serverEp := connectStream(t, worker)
+	ctx := context.Background()
+	config := ucxio.Config{Context: ucpContext, ChunkSize: 64 << 10, Window: 4}
+
+	object := make([]byte, 3<<20+123)
+	for i := range object {
+		object[i] = byte(i * 7)
+	}
+	errRead := errors.New("read failed")
+
+	for _, test := range []struct {
+		reader  io.Reader
+		sent    []byte
+		sendErr error
+		recvErr error
+	}{
+		{bytes.NewReader(object), object, nil, nil},
+		{bytes.NewReader(nil), nil, nil, nil},
+		{io.MultiReader(bytes.NewReader(object[:200<<10]), iotest.ErrReader(errRead)), object[:192<<10],
+			errRead, ucxio.ErrAborted},
+	} {
+		sendResult := make(chan error, 1)
+		go func(reader io.Reader) {
+			_, err := ucxio.SendLargeObject(ctx, clientEp, reader, config)
+			sendResult <- err
+		}(test.reader)
+
+		var received bytes.Buffer
+		written, err := ucxio.RecvLargeObject(ctx, serverEp, &received, ucxio.Config{})
+		if err != test.recvErr {
+			t.Fatalf("Receive error %v != %v", err, test.recvErr)
+		}
+
+		if err := <-sendResult; err != test.sendErr {
+			t.Fatalf("Send error %v != %v", err, test.sendErr)
+		}
+
+		if (written != int64(len(test.sent))) || !bytes.Equal(received.Bytes(), test.sent) {
+			t.Fatalf("Received %v bytes != sent %v bytes", written, len(test.sent))
+		}
+	}
+}
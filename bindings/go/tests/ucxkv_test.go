@@ -0,0 +1,113 @@
+//go:build go1.18
+// +build go1.18
+
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package goucxtests
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	. "ucx"
+	"ucx/ucxkv"
+	"ucx/ucxrpc"
+)
+
+func TestUcxKvPutGet(t *testing.T) {
+	ucpContext, _ := NewUcpContext((&UcpParams{}).EnableAM().EnableRMA())
+	defer ucpContext.Close()
+	ucpWorker, err := ucpContext.NewWorker(&UcpWorkerParams{})
+	if err != nil {
+		t.Fatalf("Failed to create a worker %v", err)
+	}
+	defer ucpWorker.Close()
+
+	loop, err := ucpWorker.StartProgressLoop(nil)
+	if err != nil {
+		t.Fatalf("Failed to start progress loop %v", err)
+	}
+	defer loop.Stop()
+
+	node, err := ucxrpc.NewNode(loop, ucpWorker, ucxrpc.Config{Workers: 2})
+	if err != nil {
+		t.Fatalf("Failed to create node %v", err)
+	}
+
+	config := ucxkv.Config{SlotSize: 256, Slots: 2}
+	server, err := ucxkv.NewServer(ucpContext, node, config)
+	if err != nil {
+		t.Fatalf("Failed to create server %v", err)
+	}
+	defer server.Close()
+	defer node.Close()
+
+	var ep *UcpEp
+	loop.Execute(func() {
+		address, _ := ucpWorker.GetAddress()
+		ep, err = ucpWorker.NewEndpoint((&UcpEpParams{}).SetUcpAddress(address))
+		address.Close()
+	})
+	if err != nil {
+		t.Fatalf("Failed to create endpoint %v", err)
+	}
+	defer loop.Execute(func() {
+		if request, err := ep.CloseNonBlockingForce(nil); err == nil {
+			request.Close()
+		}
+	})
+
+	ctx := context.Background()
+	client, err := ucxkv.NewClient(ctx, loop, node, ep, config)
+	if err != nil {
+		t.Fatalf("Failed to create client %v", err)
+	}
+	defer client.Close()
+
+	if _, err := client.Get(ctx, "missing"); !errors.Is(err, ucxkv.ErrNotFound) {
+		t.Fatalf("Get of the missing key returned %v", err)
+	}
+
+	for _, value := range [][]byte{[]byte("first"), bytes.Repeat([]byte("x"), 248)} {
+		if err := client.Put(ctx, "key", value); err != nil {
+			t.Fatalf("Failed to put %v", err)
+		}
+
+		if data, err := client.Get(ctx, "key"); (err != nil) || !bytes.Equal(data, value) {
+			t.Fatalf("Unexpected value of size %v %v", len(data), err)
+		}
+	}
+
+	if err := client.Put(ctx, "key", make([]byte, 249)); !errors.Is(err, ucxkv.ErrTooLarge) {
+		t.Fatalf("Put of the large value returned %v", err)
+	}
+
+	// The overwritten value has released its slot, so one slot is free
+	if err := client.Put(ctx, "other", []byte("other")); err != nil {
+		t.Fatalf("Failed to put other key %v", err)
+	}
+
+	if err := client.Put(ctx, "third", []byte("third")); !errors.Is(err, ucxkv.ErrNoSpace) {
+		t.Fatalf("Put to the full store returned %v", err)
+	}
+
+	if server.Len() != 2 {
+		t.Fatalf("Unexpected number of keys %v", server.Len())
+	}
+
+	if err := client.Delete(ctx, "key"); err != nil {
+		t.Fatalf("Failed to delete %v", err)
+	}
+
+	if err := client.Delete(ctx, "key"); !errors.Is(err, ucxkv.ErrNotFound) {
+		t.Fatalf("Second delete returned %v", err)
+	}
+
+	if _, err := client.Get(ctx, "key"); !errors.Is(err, ucxkv.ErrNotFound) {
+		t.Fatalf("Get of the deleted key returned %v", err)
+	}
+}
@@ -0,0 +1,93 @@
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package goucxtests
+
+import (
+	"strings"
+	"testing"
+	. "ucx"
+	"ucx/ucxmem"
+)
+
+func TestUcxMemPool(t *testing.T) {
+	entity := prepareContext(t, nil)
+	entity.worker, _ = entity.context.NewWorker(&UcpWorkerParams{})
+	createSelfEp(entity)
+	defer entity.Close()
+
+	pool := ucxmem.NewPool(entity.context, ucxmem.Config{
+		SizeClasses: []uint64{4096, 64},
+		SlabSize:    8192,
+		MaxMemory:   2 * 8192,
+		TrackLeaks:  true,
+	})
+
+	sendBuffer, err := pool.Get(10)
+	if err != nil {
+		t.Fatalf("Failed to get buffer %v", err)
+	}
+
+	if b := sendBuffer.Bytes(); (len(b) != 10) || (cap(b) != 64) {
+		t.Fatalf("Buffer length %v and capacity %v != 10 and 64", len(b), cap(b))
+	}
+
+	recvBuffer, err := pool.Get(4000)
+	if err != nil {
+		t.Fatalf("Failed to get buffer %v", err)
+	}
+
+	if _, err := pool.Get(8192); err != ucxmem.ErrTooLarge {
+		t.Fatalf("Error %v != %v", err, ucxmem.ErrTooLarge)
+	}
+
+	copy(sendBuffer.Bytes(), "Hello GO")
+	recvRequest, _ := entity.worker.RecvTagNonBlocking(recvBuffer.Pointer(), recvBuffer.Length(), 1,
+		^uint64(0), recvBuffer.Params())
+	defer recvRequest.Close()
+	sendRequest, _ := entity.selfEp.SendTagNonBlocking(1, sendBuffer.Pointer(), 8, sendBuffer.Params())
+	defer sendRequest.Close()
+
+	for sendRequest.GetStatus() == UCS_INPROGRESS || recvRequest.GetStatus() == UCS_INPROGRESS {
+		entity.worker.Progress()
+	}
+
+	if data := string(recvBuffer.Bytes()[:8]); data != "Hello GO" {
+		t.Fatalf("Received data %q != sent", data)
+	}
+
+	// Another slab of the class exceeds the memory limit
+	lastBuffer, err := pool.Get(4096)
+	if err != nil {
+		t.Fatalf("Failed to get buffer %v", err)
+	}
+	if _, err := pool.Get(4096); err != ucxmem.ErrLimit {
+		t.Fatalf("Error %v != %v", err, ucxmem.ErrLimit)
+	}
+
+	leaks := pool.Leaks()
+	if (len(leaks) != 3) || !strings.Contains(leaks[0], "TestUcxMemPool") {
+		t.Fatalf("Unexpected leaks of the outstanding buffers %v", leaks)
+	}
+
+	if err := pool.Close(); err == nil {
+		t.Fatalf("Closed pool with outstanding buffers")
+	}
+
+	// The released buffer is reused
+	address := sendBuffer.Pointer()
+	sendBuffer.Release()
+	if reused, _ := pool.Get(64); reused.Pointer() != address {
+		t.Fatalf("Released buffer is not reused")
+	} else {
+		reused.Release()
+	}
+
+	recvBuffer.Release()
+	lastBuffer.Release()
+	if err := pool.Close(); err != nil {
+		t.Fatalf("Failed to close pool %v", err)
+	}
+}
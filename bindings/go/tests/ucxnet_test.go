@@ -0,0 +1,150 @@
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package goucxtests
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"testing"
+	"time"
+	"ucx/ucxnet"
+)
+
+func TestUcxNetEcho(t *testing.T) {
+	const sendData string = "Hello GO net"
+
+	listener, err := ucxnet.Listen("tcp", "0.0.0.0:0")
+	if err != nil {
+		t.Fatalf("Failed to listen %v", err)
+	}
+	defer listener.Close()
+
+	// t.Fatalf can't be called from non main thread need to pass an error
+	serverErr := make(chan error, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			serverErr <- err
+			return
+		}
+		defer conn.Close()
+
+		buffer := make([]byte, len(sendData))
+		if _, err = io.ReadFull(conn, buffer); err == nil {
+			_, err = conn.Write(buffer)
+		}
+		serverErr <- err
+	}()
+
+	conn, err := ucxnet.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatalf("Failed to dial %v", err)
+	}
+	defer conn.Close()
+
+	if _, err = conn.Write([]byte(sendData)); err != nil {
+		t.Fatalf("Failed to write %v", err)
+	}
+
+	buffer := make([]byte, len(sendData))
+	if _, err = io.ReadFull(conn, buffer); err != nil {
+		t.Fatalf("Failed to read %v", err)
+	}
+
+	if string(buffer) != sendData {
+		t.Fatalf("Received data %v != %v", string(buffer), sendData)
+	}
+
+	if err = <-serverErr; err != nil {
+		t.Fatalf("Server failed %v", err)
+	}
+}
+
+func TestUcxNetDialFunc(t *testing.T) {
+	// Small buffers take multiple receives to read the data
+	const readBufferSize = 16
+	sendData := bytes.Repeat([]byte("Hello GO net"), 100)
+
+	listener, err := (&ucxnet.ListenConfig{ReadBufferSize: readBufferSize}).Listen("tcp", "0.0.0.0:0")
+	if err != nil {
+		t.Fatalf("Failed to listen %v", err)
+	}
+	defer listener.Close()
+
+	serverErr := make(chan error, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			serverErr <- err
+			return
+		}
+		defer conn.Close()
+
+		buffer := make([]byte, len(sendData))
+		if _, err = io.ReadFull(conn, buffer); err == nil {
+			_, err = conn.Write(buffer)
+		}
+		serverErr <- err
+	}()
+
+	dial := (&ucxnet.Dialer{ReadBufferSize: readBufferSize}).DialFunc("tcp")
+	conn, err := dial(context.Background(), listener.Addr().String())
+	if err != nil {
+		t.Fatalf("Failed to dial %v", err)
+	}
+	defer conn.Close()
+
+	if _, err = conn.Write(sendData); err != nil {
+		t.Fatalf("Failed to write %v", err)
+	}
+
+	buffer := make([]byte, len(sendData))
+	if _, err = io.ReadFull(conn, buffer); err != nil {
+		t.Fatalf("Failed to read %v", err)
+	}
+
+	if !bytes.Equal(buffer, sendData) {
+		t.Fatalf("Received data doesn't match the sent data")
+	}
+
+	if err = <-serverErr; err != nil {
+		t.Fatalf("Server failed %v", err)
+	}
+
+	if conn, err := dial(context.Background(), "127.0.0.1"); (conn != nil) || (err == nil) {
+		t.Fatalf("Dialed address without port")
+	}
+}
+
+func TestUcxNetReadDeadline(t *testing.T) {
+	listener, err := ucxnet.Listen("tcp", "0.0.0.0:0")
+	if err != nil {
+		t.Fatalf("Failed to listen %v", err)
+	}
+	defer listener.Close()
+
+	accepted := make(chan io.Closer, 1)
+	go func() {
+		if conn, err := listener.Accept(); err == nil {
+			accepted <- conn
+		}
+	}()
+
+	conn, err := ucxnet.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatalf("Failed to dial %v", err)
+	}
+	defer conn.Close()
+	defer (<-accepted).Close()
+
+	conn.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+	if _, err = conn.Read(make([]byte, 1)); !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Fatalf("Read returned %v instead of %v", err, os.ErrDeadlineExceeded)
+	}
+}
@@ -0,0 +1,126 @@
+//go:build otel
+// +build otel
+
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package goucxtests
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	. "ucx"
+	"ucx/ucxotel"
+)
+
+func otelAttribute(span sdktrace.ReadOnlySpan, key attribute.Key) attribute.Value {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestUcxOtelTracer(t *testing.T) {
+	const size = 100
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	defer provider.Shutdown(context.Background())
+
+	entity := prepareContext(t, nil)
+	entity.worker, _ = entity.context.NewWorker((&UcpWorkerParams{}).SetTracer(ucxotel.NewTracer(provider)))
+	createSelfEp(entity)
+	defer entity.Close()
+
+	recvBuffer := AllocateNativeMemory(size)
+	defer FreeNativeMemory(recvBuffer)
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "parent")
+	recvRequest, _ := entity.worker.RecvTagNonBlocking(recvBuffer, size, selfEpTag, selfEpTag,
+		(&UcpRequestParams{}).SetTraceContext(ctx))
+	sendRequest, _ := entity.selfEp.SendTagBytesNonBlocking(selfEpTag, make([]byte, size/2), nil)
+	for (recvRequest.GetStatus() == UCS_INPROGRESS) || (sendRequest.GetStatus() == UCS_INPROGRESS) {
+		entity.worker.Progress()
+	}
+	recvRequest.Close()
+	sendRequest.Close()
+	parent.End()
+
+	// Canceled receive records the error
+	recvRequest, _ = entity.worker.RecvTagNonBlocking(recvBuffer, size, 0, selfEpTag, nil)
+	recvRequest.Cancel()
+	for recvRequest.GetStatus() == UCS_INPROGRESS {
+		entity.worker.Progress()
+	}
+	recvRequest.Close()
+
+	var recv, send, canceled sdktrace.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		switch {
+		case span.Name() == "ucx.tag_send":
+			send = span
+		case (span.Name() == "ucx.tag_recv") && (span.Status().Code == codes.Error):
+			canceled = span
+		case span.Name() == "ucx.tag_recv":
+			recv = span
+		}
+	}
+
+	if (recv == nil) || (send == nil) || (canceled == nil) {
+		t.Fatalf("Unexpected spans %v", recorder.Ended())
+	}
+
+	if (recv.SpanKind() != trace.SpanKindConsumer) || (recv.Parent().SpanID() != parent.SpanContext().SpanID()) ||
+		(uint64(otelAttribute(recv, "ucx.tag").AsInt64()) != selfEpTag) ||
+		(otelAttribute(recv, "ucx.size").AsInt64() != size) ||
+		(otelAttribute(recv, "ucx.length").AsInt64() != size/2) {
+		t.Fatalf("Unexpected receive span %v %v", recv.Parent(), recv.Attributes())
+	}
+
+	if (send.SpanKind() != trace.SpanKindProducer) || (send.Status().Code == codes.Error) ||
+		(otelAttribute(send, "ucx.length").AsInt64() != size/2) {
+		t.Fatalf("Unexpected send span %v", send.Attributes())
+	}
+
+	if (canceled.Status().Description != UCS_ERR_CANCELED.String()) || (len(canceled.Events()) == 0) {
+		t.Fatalf("Unexpected canceled span %v %v", canceled.Status(), canceled.Events())
+	}
+}
+
+func TestUcxOtelHeader(t *testing.T) {
+	provider := sdktrace.NewTracerProvider()
+	defer provider.Shutdown(context.Background())
+
+	if header := ucxotel.InjectHeader(context.Background()); len(header) != 0 {
+		t.Fatalf("Header of the context without span %q", header)
+	}
+
+	ctx := context.Background()
+	if ucxotel.ExtractHeader(ctx, nil) != ctx {
+		t.Fatalf("Empty header changed the context")
+	}
+
+	ctx, span := provider.Tracer("test").Start(ctx, "send")
+	defer span.End()
+
+	header := ucxotel.InjectHeader(ctx)
+	if len(header) == 0 {
+		t.Fatalf("Header of the span is empty")
+	}
+
+	remote := trace.SpanContextFromContext(ucxotel.ExtractHeader(context.Background(), header))
+	if !remote.IsRemote() || (remote.TraceID() != span.SpanContext().TraceID()) ||
+		(remote.SpanID() != span.SpanContext().SpanID()) {
+		t.Fatalf("Extracted span context %v != %v", remote, span.SpanContext())
+	}
+}
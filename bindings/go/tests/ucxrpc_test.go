@@ -0,0 +1,133 @@
+//go:build go1.18
+// +build go1.18
+
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package goucxtests
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+	. "ucx"
+	"ucx/ucxcodec"
+	"ucx/ucxrpc"
+)
+
+func TestUcxRpcCall(t *testing.T) {
+	ucpContext, _ := NewUcpContext((&UcpParams{}).EnableAM())
+	defer ucpContext.Close()
+	ucpWorker, err := ucpContext.NewWorker(&UcpWorkerParams{})
+	if err != nil {
+		t.Fatalf("Failed to create a worker %v", err)
+	}
+	defer ucpWorker.Close()
+
+	loop, err := ucpWorker.StartProgressLoop(nil)
+	if err != nil {
+		t.Fatalf("Failed to start progress loop %v", err)
+	}
+	defer loop.Stop()
+
+	node, err := ucxrpc.NewNode(loop, ucpWorker, ucxrpc.Config{Workers: 2})
+	if err != nil {
+		t.Fatalf("Failed to create node %v", err)
+	}
+	defer node.Close()
+
+	var ep *UcpEp
+	loop.Execute(func() {
+		address, _ := ucpWorker.GetAddress()
+		ep, err = ucpWorker.NewEndpoint((&UcpEpParams{}).SetUcpAddress(address))
+		address.Close()
+	})
+	if err != nil {
+		t.Fatalf("Failed to create endpoint %v", err)
+	}
+	defer loop.Execute(func() {
+		if request, err := ep.CloseNonBlockingForce(nil); err == nil {
+			request.Close()
+		}
+	})
+
+	codec := ucxcodec.GobCodec[string]{}
+	echo := ucxrpc.Method[string, string]{Id: 1, RequestCodec: codec, ResponseCodec: codec}
+	fail := ucxrpc.Method[string, string]{Id: 2, RequestCodec: codec, ResponseCodec: codec}
+	wait := ucxrpc.Method[string, string]{Id: 3, RequestCodec: codec, ResponseCodec: codec}
+	unknown := ucxrpc.Method[string, string]{Id: 4, RequestCodec: codec, ResponseCodec: codec}
+
+	echo.Handle(node, func(ctx context.Context, req string) (string, error) {
+		return "echo " + req, nil
+	})
+	fail.Handle(node, func(ctx context.Context, req string) (string, error) {
+		return "", errors.New(req)
+	})
+	wait.Handle(node, func(ctx context.Context, req string) (string, error) {
+		if _, ok := ctx.Deadline(); !ok {
+			return "", errors.New("deadline is not propagated")
+		}
+		<-ctx.Done()
+		return "", ctx.Err()
+	})
+
+	ctx := context.Background()
+	// The large request and response are sent by the rendezvous protocol
+	for _, req := range []string{"small", strings.Repeat("large", 1<<16)} {
+		resp, err := echo.Call(ctx, node, ep, req)
+		if err != nil {
+			t.Fatalf("Failed to call echo %v", err)
+		}
+
+		if resp != "echo "+req {
+			t.Fatalf("Response of size %v doesn't match the request", len(resp))
+		}
+	}
+
+	var remoteErr *ucxrpc.RemoteError
+	if _, err := fail.Call(ctx, node, ep, "failed"); !errors.As(err, &remoteErr) ||
+		(remoteErr.Message != "failed") {
+		t.Fatalf("Error %v is not the remote error", err)
+	}
+
+	if _, err := unknown.Call(ctx, node, ep, ""); err != ucxrpc.ErrUnknownMethod {
+		t.Fatalf("Error %v != %v", err, ucxrpc.ErrUnknownMethod)
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	if _, err := wait.Call(waitCtx, node, ep, ""); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Error %v != %v", err, context.DeadlineExceeded)
+	}
+
+	// The peers of the other bindings call with JSON, while the Go peers use gob
+	jsonCodec := ucxcodec.JSONCodec[string]{}
+	multi := ucxrpc.Method[string, string]{Id: 5, RequestCodec: codec, ResponseCodec: codec,
+		ContentType: ucxrpc.ContentTypeGob, Codecs: map[ucxrpc.ContentType]ucxrpc.Codecs[string, string]{
+			ucxrpc.ContentTypeJSON: {RequestCodec: jsonCodec, ResponseCodec: jsonCodec},
+		}}
+	multi.Handle(node, func(ctx context.Context, req string) (string, error) {
+		return "multi " + req, nil
+	})
+
+	for _, contentType := range []ucxrpc.ContentType{ucxrpc.ContentTypeGob, ucxrpc.ContentTypeJSON} {
+		if resp, err := multi.CallAs(ctx, node, ep, "call", contentType); (err != nil) || (resp != "multi call") {
+			t.Fatalf("Call with content type %v returned %q %v", contentType, resp, err)
+		}
+	}
+
+	if _, err := multi.CallAs(ctx, node, ep, "", ucxrpc.ContentTypeMsgpack); err != ucxrpc.ErrUnsupportedContentType {
+		t.Fatalf("Error %v != %v", err, ucxrpc.ErrUnsupportedContentType)
+	}
+
+	// The caller, that doesn't share the codecs of the handler
+	raw := ucxrpc.Method[[]byte, []byte]{Id: 5, RequestCodec: ucxcodec.RawCodec{},
+		ResponseCodec: ucxcodec.RawCodec{}, ContentType: ucxrpc.ContentTypeRaw}
+	if _, err := raw.Call(ctx, node, ep, []byte("raw")); err != ucxrpc.ErrUnsupportedContentType {
+		t.Fatalf("Error %v != %v", err, ucxrpc.ErrUnsupportedContentType)
+	}
+}
@@ -0,0 +1,55 @@
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package goucxtests
+
+import (
+	"flag"
+	"testing"
+	"time"
+	. "ucx"
+	"ucx/ucxtest"
+)
+
+var (
+	fuzzSeed  = flag.Int64("fuzzseed", 0, "seed of the fuzz calls, a random one by default")
+	fuzzSteps = flag.Int("fuzzsteps", 1000, "number of the fuzz calls")
+)
+
+func TestUcxTestPairKill(t *testing.T) {
+	pair := ucxtest.NewPair(t, ucxtest.Config{})
+
+	// The connected peers exchange messages before the failure
+	sendMem := CBytes([]byte("ping"))
+	defer FreeNativeMemory(sendMem)
+	recvMem := AllocateNativeMemory(4)
+	defer FreeNativeMemory(recvMem)
+
+	recvRequest, _ := pair.B.Worker.RecvTagNonBlocking(recvMem, 4, 1, ^uint64(0), nil)
+	sendRequest, err := pair.A.Ep.SendTagNonBlocking(1, sendMem, 4, nil)
+	if err != nil {
+		t.Fatalf("Failed to send %v", err)
+	}
+
+	for (sendRequest.GetStatus() == UCS_INPROGRESS) || (recvRequest.GetStatus() == UCS_INPROGRESS) {
+		pair.Progress()
+	}
+	sendRequest.Close()
+	recvRequest.Close()
+
+	if string(GoBytes(recvMem, 4)) != "ping" {
+		t.Fatalf("Received data != sent")
+	}
+
+	pair.Kill(pair.B)
+	if status := pair.WaitPeerError(pair.A, 10*time.Second); status == UCS_OK {
+		t.Fatalf("Endpoint error with OK status")
+	}
+}
+
+func TestUcxTestFuzz(t *testing.T) {
+	// The failure reports the seed, that is passed back by -fuzzseed
+	ucxtest.Fuzz(t, ucxtest.FuzzConfig{Seed: *fuzzSeed, Steps: *fuzzSteps, CheckLeaks: true})
+}
@@ -0,0 +1,100 @@
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package goucxtests
+
+import (
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"testing"
+)
+
+// Exported declarations of the package files, that match the build context:
+// the functions, the types, the constants and the variables by their names,
+// and the methods of the exported types by "Type.Method".
+func exportedDecls(t *testing.T, ctx build.Context, dir string) map[string]bool {
+	pkg, err := ctx.ImportDir(dir, 0)
+	if err != nil {
+		t.Fatalf("Failed to import %s: %v", dir, err)
+	}
+
+	decls := make(map[string]bool)
+	fset := token.NewFileSet()
+	for _, name := range append(pkg.GoFiles, pkg.CgoFiles...) {
+		file, err := parser.ParseFile(fset, dir+"/"+name, nil, parser.SkipObjectResolution)
+		if err != nil {
+			t.Fatalf("Failed to parse %s: %v", name, err)
+		}
+
+		for _, decl := range file.Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				if decl.Recv == nil {
+					if decl.Name.IsExported() {
+						decls[decl.Name.Name] = true
+					}
+					continue
+				}
+
+				recv := decl.Recv.List[0].Type
+				if star, ok := recv.(*ast.StarExpr); ok {
+					recv = star.X
+				}
+				if ident, ok := recv.(*ast.Ident); ok && ident.IsExported() && decl.Name.IsExported() {
+					decls[ident.Name+"."+decl.Name.Name] = true
+				}
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					switch spec := spec.(type) {
+					case *ast.TypeSpec:
+						if spec.Name.IsExported() {
+							decls[spec.Name.Name] = true
+						}
+					case *ast.ValueSpec:
+						for _, ident := range spec.Names {
+							if ident.IsExported() {
+								decls[ident.Name] = true
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+
+	return decls
+}
+
+func TestUnsupportedPlatformApi(t *testing.T) {
+	linux := build.Default
+	linux.GOOS = "linux"
+	linux.CgoEnabled = true
+
+	unsupported := build.Default
+	unsupported.GOOS = "darwin"
+	unsupported.CgoEnabled = false
+
+	for _, path := range []string{"ucx", "ucx/uct", "ucx/ucxinfo"} {
+		pkg, err := build.Import(path, ".", build.FindOnly)
+		if err != nil {
+			t.Fatalf("Failed to find %s: %v", path, err)
+		}
+
+		expected := exportedDecls(t, linux, pkg.Dir)
+		actual := exportedDecls(t, unsupported, pkg.Dir)
+		for name := range expected {
+			if !actual[name] {
+				t.Errorf("%s: %s has no unsupported platform stub", path, name)
+			}
+		}
+		for name := range actual {
+			if !expected[name] {
+				t.Errorf("%s: stub %s isn't declared on linux", path, name)
+			}
+		}
+	}
+}
@@ -0,0 +1,42 @@
+//go:build !linux || !cgo
+// +build !linux !cgo
+
+/*
+ * Copyright (C) 2026, NVIDIA CORPORATION & AFFILIATES. ALL RIGHTS RESERVED.
+ * See file LICENSE for terms.
+ */
+
+package goucxtests
+
+import (
+	"errors"
+	"testing"
+	. "ucx"
+	"ucx/uct"
+	"ucx/ucxinfo"
+	"ucx/ucxnet"
+)
+
+func TestUnsupportedPlatform(t *testing.T) {
+	params := (&UcpParams{}).EnableTag().EnableAM()
+	if params == nil {
+		t.Fatalf("Params setters returned nil")
+	}
+
+	if _, err := NewUcpContext(params); !errors.Is(err, ErrUnsupported) {
+		t.Fatalf("NewUcpContext error %v isn't ErrUnsupported", err)
+	}
+
+	if _, err := uct.NewWorker(); !errors.Is(err, ErrUnsupported) {
+		t.Fatalf("uct.NewWorker error %v isn't ErrUnsupported", err)
+	}
+
+	if _, err := ucxinfo.Devices(); !errors.Is(err, ErrUnsupported) {
+		t.Fatalf("ucxinfo.Devices error %v isn't ErrUnsupported", err)
+	}
+
+	// The packages on top of the bindings fail the same way
+	if _, err := ucxnet.Listen("tcp", "127.0.0.1:0"); !errors.Is(err, ErrUnsupported) {
+		t.Fatalf("ucxnet.Listen error %v isn't ErrUnsupported", err)
+	}
+}
@@ -6,11 +6,16 @@
 package goucxtests
 
 import (
+	"errors"
+	"fmt"
 	"math/big"
+	"runtime"
+	"strconv"
 	"syscall"
 	"testing"
 	"time"
 	. "ucx"
+	"unsafe"
 )
 
 func TestUcpWorkerEfd(t *testing.T) {
@@ -108,3 +113,671 @@ func TestUcpWorkerWakeup(t *testing.T) {
 		t.Fatalf("Didn't exit from wait")
 	}
 }
+
+func TestUcpWorkerSignalSubmitQueue(t *testing.T) {
+	const numSends = 8
+	const tag uint64 = 7
+
+	ucpContext, _ := NewUcpContext((&UcpParams{}).EnableTag().EnableWakeup())
+	defer ucpContext.Close()
+	ucpWorkerParams := (&UcpWorkerParams{}).SetThreadMode(UCS_THREAD_MODE_SINGLE)
+	ucpWorkerParams.WakeupTX().WakeupRX()
+	ucpWorker, err := ucpContext.NewWorker(ucpWorkerParams)
+	if err != nil {
+		t.Fatalf("Failed to create a worker %v", err)
+	}
+	defer ucpWorker.Close()
+
+	address, _ := ucpWorker.GetAddress()
+	ep, err := ucpWorker.NewEndpoint((&UcpEpParams{}).SetUcpAddress(address))
+	address.Close()
+	if err != nil {
+		t.Fatalf("Failed to create endpoint %v", err)
+	}
+
+	recvMem := AllocateNativeMemory(8)
+	defer FreeNativeMemory(recvMem)
+
+	// The worker is used only by this goroutine, which sleeps in Wait()
+	// until the senders signal it
+	submitted := make(chan uint64, numSends)
+	received := make(chan uint64, numSends)
+	go func() {
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+
+		for count := 0; count < numSends; {
+			select {
+			case value := <-submitted:
+				sendMem := CBytes([]byte(fmt.Sprintf("%08d", value)))
+				sendRequest, _ := ep.SendTagNonBlocking(tag, sendMem, 8, nil)
+				recvRequest, _ := ucpWorker.RecvTagNonBlocking(recvMem, 8, tag, ^uint64(0), nil)
+				for (sendRequest.GetStatus() == UCS_INPROGRESS) || (recvRequest.GetStatus() == UCS_INPROGRESS) {
+					ucpWorker.Progress()
+				}
+				sendRequest.Close()
+				recvRequest.Close()
+				FreeNativeMemory(sendMem)
+
+				parsed, _ := strconv.ParseUint(string(GoBytes(recvMem, 8)), 10, 64)
+				received <- parsed
+				count++
+				continue
+			default:
+			}
+
+			if ucpWorker.Progress() == 0 {
+				ucpWorker.Wait()
+			}
+		}
+
+		closeRequest, _ := ep.CloseNonBlockingForce(nil)
+		for closeRequest.GetStatus() == UCS_INPROGRESS {
+			ucpWorker.Progress()
+		}
+		closeRequest.Close()
+		close(received)
+	}()
+
+	for i := uint64(0); i < numSends; i++ {
+		submitted <- i
+		ucpWorker.Signal()
+
+		select {
+		case value := <-received:
+			if value != i {
+				t.Fatalf("Received %d != sent %d", value, i)
+			}
+		case <-time.After(10 * time.Second):
+			t.Fatalf("Submitted send %d was not executed", i)
+		}
+	}
+	<-received
+}
+
+func TestUcpWorkerWaitEvents(t *testing.T) {
+	ucpContext, _ := NewUcpContext((&UcpParams{}).EnableTag().EnableWakeup())
+	defer ucpContext.Close()
+	ucpWorkerParams := &UcpWorkerParams{}
+	ucpWorkerParams.WakeupTX().WakeupRX()
+	ucpWorkerParams.SetThreadMode(UCS_THREAD_MODE_MULTI)
+	ucpWorker, err := ucpContext.NewWorker(ucpWorkerParams)
+
+	if err != nil {
+		t.Fatalf("Failed to create a worker %v", err)
+	}
+	defer ucpWorker.Close()
+
+	for ucpWorker.Progress() != 0 {
+	}
+
+	quit := make(chan error)
+
+	go func() {
+		quit <- ucpWorker.WaitEvents()
+	}()
+
+	ucpWorker.Signal()
+
+	if err := <-quit; err != nil {
+		t.Fatalf("Failed to wait for events %v", err)
+	}
+
+	stop, err := ucpWorker.StartProgress()
+	if err != nil {
+		t.Fatalf("Failed to start progress %v", err)
+	}
+
+	stop()
+}
+
+func TestUcpWorkerWaitTimeout(t *testing.T) {
+	ucpContext, _ := NewUcpContext((&UcpParams{}).EnableTag().EnableWakeup())
+	defer ucpContext.Close()
+	ucpWorker, err := ucpContext.NewWorker((&UcpWorkerParams{}).WakeupTX().WakeupRX())
+	if err != nil {
+		t.Fatalf("Failed to create a worker %v", err)
+	}
+	defer ucpWorker.Close()
+
+	// Spurious events of the transports only make the wait return earlier
+	expired := false
+	for i := 0; (i < 10) && !expired; i++ {
+		for ucpWorker.Progress() != 0 {
+		}
+
+		events, err := ucpWorker.WaitTimeout(10 * time.Millisecond)
+		if err != nil {
+			t.Fatalf("Failed to wait for events %v", err)
+		}
+		expired = !events
+	}
+
+	if !expired {
+		t.Fatalf("Wait did not time out without events")
+	}
+
+	for ucpWorker.Progress() != 0 {
+	}
+
+	ucpWorker.Signal()
+	if events, err := ucpWorker.WaitTimeout(time.Second); (err != nil) || !events {
+		t.Fatalf("Signal is not received: %v %v", events, err)
+	}
+}
+
+func TestUcpWorkerProgressLoop(t *testing.T) {
+	ucpContext, _ := NewUcpContext((&UcpParams{}).EnableTag().EnableWakeup())
+	defer ucpContext.Close()
+	ucpWorkerParams := &UcpWorkerParams{}
+	ucpWorkerParams.WakeupTX().WakeupRX()
+	ucpWorkerParams.SetThreadMode(UCS_THREAD_MODE_MULTI)
+	ucpWorker, err := ucpContext.NewWorker(ucpWorkerParams)
+
+	if err != nil {
+		t.Fatalf("Failed to create a worker %v", err)
+	}
+	defer ucpWorker.Close()
+
+	for _, mode := range []UcpProgressMode{UcpProgressModePoll, UcpProgressModeEvent} {
+		loop, err := ucpWorker.StartProgressLoop((&UcpProgressLoopParams{}).SetMode(mode))
+		if err != nil {
+			t.Fatalf("Failed to start progress loop %v", err)
+		}
+
+		// Tag receive is completed by the loop
+		sendMem := CBytes([]byte("Hello GO"))
+		recvMem := AllocateNativeMemory(8)
+		recvRequest, _ := ucpWorker.RecvTagNonBlocking(recvMem, 8, 1, ^uint64(0),
+			(&UcpRequestParams{}).EnableDoneChannel())
+
+		address, _ := ucpWorker.GetAddress()
+		ep, _ := ucpWorker.NewEndpoint((&UcpEpParams{}).SetUcpAddress(address))
+		address.Close()
+		sendRequest, _ := ep.SendTagNonBlocking(1, sendMem, 8, nil)
+
+		select {
+		case <-recvRequest.Done():
+		case <-time.After(10 * time.Second):
+			t.Fatalf("Receive was not progressed by the loop in mode %v", mode)
+		}
+
+		loop.Stop()
+		loop.Stop()
+
+		for sendRequest.GetStatus() == UCS_INPROGRESS {
+			ucpWorker.Progress()
+		}
+		closeReq, _ := ep.CloseNonBlockingForce(nil)
+		for closeReq.GetStatus() == UCS_INPROGRESS {
+			ucpWorker.Progress()
+		}
+
+		closeReq.Close()
+		sendRequest.Close()
+		recvRequest.Close()
+		FreeNativeMemory(sendMem)
+		FreeNativeMemory(recvMem)
+	}
+}
+
+func TestUcpWorkerSet(t *testing.T) {
+	ucpContext, _ := NewUcpContext((&UcpParams{}).EnableTag().EnableWakeup())
+	defer ucpContext.Close()
+	ucpWorkerParams := &UcpWorkerParams{}
+	ucpWorkerParams.WakeupTX().WakeupRX()
+
+	workers := make([]*UcpWorker, 2)
+	for i := range workers {
+		worker, err := ucpContext.NewWorker(ucpWorkerParams)
+		if err != nil {
+			t.Fatalf("Failed to create a worker %v", err)
+		}
+		defer worker.Close()
+		workers[i] = worker
+	}
+
+	set, err := NewUcpWorkerSet(workers[0])
+	if err != nil {
+		t.Fatalf("Failed to create worker set %v", err)
+	}
+	defer set.Stop()
+
+	if err = set.Add(workers[1]); err != nil {
+		t.Fatalf("Failed to add worker %v", err)
+	}
+
+	// Operations of the workers are submitted to the set, that completes them
+	sendMem := CBytes([]byte("Hello GO"))
+	defer FreeNativeMemory(sendMem)
+	recvMems := make([]unsafe.Pointer, len(workers))
+	eps := make([]*UcpEp, len(workers))
+	recvRequests := make([]*UcpRequest, len(workers))
+	sendRequests := make([]*UcpRequest, len(workers))
+	for i, worker := range workers {
+		recvMems[i] = AllocateNativeMemory(8)
+		defer FreeNativeMemory(recvMems[i])
+
+		set.Execute(func() {
+			address, _ := worker.GetAddress()
+			eps[i], _ = worker.NewEndpoint((&UcpEpParams{}).SetUcpAddress(address))
+			address.Close()
+			recvRequests[i], _ = worker.RecvTagNonBlocking(recvMems[i], 8, 1, ^uint64(0),
+				(&UcpRequestParams{}).EnableDoneChannel())
+			sendRequests[i], _ = eps[i].SendTagNonBlocking(1, sendMem, 8, nil)
+		})
+	}
+
+	for i := range workers {
+		select {
+		case <-recvRequests[i].Done():
+		case <-time.After(10 * time.Second):
+			t.Fatalf("Receive of worker %d was not progressed by the set", i)
+		}
+
+		if data := string(GoBytes(recvMems[i], 8)); data != "Hello GO" {
+			t.Fatalf("Received %q != sent", data)
+		}
+	}
+
+	for i := range workers {
+		set.Execute(func() {
+			recvRequests[i].Close()
+			sendRequests[i].Close()
+			closeReq, _ := eps[i].CloseNonBlockingForce(nil)
+			closeReq.Close()
+		})
+	}
+
+	for _, worker := range workers {
+		if err = set.Remove(worker); err != nil {
+			t.Fatalf("Failed to remove worker %v", err)
+		}
+	}
+
+	set.Stop()
+	if err = set.Execute(func() {}); err == nil {
+		t.Fatalf("Execute succeeded after stop")
+	}
+}
+
+func TestUcpProgressEngine(t *testing.T) {
+	ucpContext, _ := NewUcpContext((&UcpParams{}).EnableTag())
+	defer ucpContext.Close()
+
+	single, _ := ucpContext.NewWorker((&UcpWorkerParams{}).SetThreadMode(UCS_THREAD_MODE_SINGLE))
+	if (single != nil) && (single.ThreadMode() == UCS_THREAD_MODE_SINGLE) {
+		if _, err := NewProgressEngine([]*UcpWorker{single}, nil); !errors.Is(err, ErrInvalidParam) {
+			t.Fatalf("Engine of single thread worker returned %v", err)
+		}
+	}
+	if single != nil {
+		single.Close()
+	}
+
+	workers := make([]*UcpWorker, 2)
+	for i := range workers {
+		worker, err := ucpContext.NewWorker((&UcpWorkerParams{}).SetThreadMode(UCS_THREAD_MODE_SERIALIZED))
+		if err != nil {
+			t.Fatalf("Failed to create a worker %v", err)
+		}
+		defer worker.Close()
+		workers[i] = worker
+	}
+
+	// Any completion scales the engine up, and the idle engine scales down
+	engine, err := NewProgressEngine(workers, &UcpProgressEngineOptions{
+		MaxThreads:   2,
+		Interval:     10 * time.Millisecond,
+		ScaleUpYield: 1e-9,
+		Hysteresis:   1,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create progress engine %v", err)
+	}
+	defer engine.Stop()
+
+	if threads := engine.Threads(); threads != 1 {
+		t.Fatalf("Engine started %d threads", threads)
+	}
+
+	sendMem := CBytes([]byte("Hello GO"))
+	defer FreeNativeMemory(sendMem)
+	recvMems := make([]unsafe.Pointer, len(workers))
+	eps := make([]*UcpEp, len(workers))
+	for i, worker := range workers {
+		recvMems[i] = AllocateNativeMemory(8)
+		defer FreeNativeMemory(recvMems[i])
+
+		engine.Execute(worker, func() {
+			address, _ := worker.GetAddress()
+			eps[i], _ = worker.NewEndpoint((&UcpEpParams{}).SetUcpAddress(address))
+			address.Close()
+		})
+	}
+
+	// The messages of the workers are completed by the engine threads
+	deadline := time.Now().Add(10 * time.Second)
+	for engine.Threads() != 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Engine didn't scale up")
+		}
+
+		for i, worker := range workers {
+			var recvRequest, sendRequest *UcpRequest
+			engine.Execute(worker, func() {
+				recvRequest, _ = worker.RecvTagNonBlocking(recvMems[i], 8, 1, ^uint64(0),
+					(&UcpRequestParams{}).EnableDoneChannel())
+				sendRequest, _ = eps[i].SendTagNonBlocking(1, sendMem, 8, nil)
+			})
+
+			select {
+			case <-recvRequest.Done():
+			case <-time.After(10 * time.Second):
+				t.Fatalf("Receive of worker %d was not progressed by the engine", i)
+			}
+
+			if data := string(GoBytes(recvMems[i], 8)); data != "Hello GO" {
+				t.Fatalf("Received %q != sent", data)
+			}
+
+			engine.Execute(worker, func() {
+				recvRequest.Close()
+				sendRequest.Close()
+			})
+		}
+	}
+
+	for engine.Threads() != 1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Engine didn't scale down")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	for i, worker := range workers {
+		engine.Execute(worker, func() {
+			closeReq, _ := eps[i].CloseNonBlockingForce(nil)
+			closeReq.Close()
+		})
+
+		if err = engine.Remove(worker); err != nil {
+			t.Fatalf("Failed to remove worker %v", err)
+		}
+	}
+
+	if err = engine.Execute(workers[0], func() {}); !errors.Is(err, ErrInvalidParam) {
+		t.Fatalf("Execute of removed worker returned %v", err)
+	}
+}
+
+func TestUcpAddressMarshal(t *testing.T) {
+	ucpContext, _ := NewUcpContext((&UcpParams{}).EnableTag())
+	defer ucpContext.Close()
+
+	ucpWorker, err := ucpContext.NewWorker(&UcpWorkerParams{})
+	if err != nil {
+		t.Fatalf("Failed to create a worker %v", err)
+	}
+	defer ucpWorker.Close()
+
+	workerAttrs, err := ucpWorker.Query(UCP_WORKER_ATTR_FIELD_NAME, UCP_WORKER_ATTR_FIELD_THREAD_MODE)
+	if err != nil {
+		t.Fatalf("Failed to query a worker %v", err)
+	}
+
+	if (workerAttrs.Name == "") || (workerAttrs.ThreadMode != ucpWorker.ThreadMode()) {
+		t.Fatalf("Unexpected worker attributes %v", workerAttrs)
+	}
+
+	workerAddress, _ := ucpWorker.GetAddress()
+	addressBytes, err := workerAddress.MarshalBinary()
+	workerAddress.Close()
+	if err != nil {
+		t.Fatalf("Failed to marshal address %v", err)
+	}
+
+	restoredAddress := &UcpAddress{}
+	if err := restoredAddress.UnmarshalBinary(addressBytes); err != nil {
+		t.Fatalf("Failed to unmarshal address %v", err)
+	}
+
+	for _, epParams := range []*UcpEpParams{
+		(&UcpEpParams{}).SetUcpAddress(restoredAddress),
+		(&UcpEpParams{}).SetUcpAddressBytes(addressBytes),
+	} {
+		ep, err := ucpWorker.NewEndpoint(epParams)
+		if err != nil {
+			t.Fatalf("Failed to create endpoint %v", err)
+		}
+
+		closeReq, _ := ep.CloseNonBlockingForce(nil)
+		for closeReq.GetStatus() == UCS_INPROGRESS {
+			ucpWorker.Progress()
+		}
+		closeReq.Close()
+	}
+
+	restoredAddress.Close()
+}
+
+func TestUcpWorkerAddressFlags(t *testing.T) {
+	ucpContext, _ := NewUcpContext((&UcpParams{}).EnableTag())
+	defer ucpContext.Close()
+
+	ucpWorker, err := ucpContext.NewWorker(&UcpWorkerParams{})
+	if err != nil {
+		t.Fatalf("Failed to create a worker %v", err)
+	}
+	defer ucpWorker.Close()
+
+	fullAddress, err := ucpWorker.GetAddress()
+	if err != nil {
+		t.Fatalf("Failed to get address %v", err)
+	}
+	defer fullAddress.Close()
+
+	netAddress, err := ucpWorker.GetAddressWithFlags(UCP_WORKER_ADDRESS_FLAG_NET_ONLY)
+	if err != nil {
+		t.Fatalf("Failed to get network only address %v", err)
+	}
+	defer netAddress.Close()
+
+	if netAddress.Length > fullAddress.Length {
+		t.Fatalf("Network only address length %v > %v", netAddress.Length, fullAddress.Length)
+	}
+
+	fullAttrs, err := fullAddress.Query(UCP_WORKER_ADDRESS_ATTR_FIELD_UID)
+	if err != nil {
+		t.Fatalf("Failed to query address %v", err)
+	}
+
+	netAttrs, err := netAddress.Query(UCP_WORKER_ADDRESS_ATTR_FIELD_UID)
+	if err != nil {
+		t.Fatalf("Failed to query address %v", err)
+	}
+
+	if fullAttrs.WorkerUid != netAttrs.WorkerUid {
+		t.Fatalf("Worker uid %v != %v", netAttrs.WorkerUid, fullAttrs.WorkerUid)
+	}
+}
+
+func TestUcpProgressLoopExecute(t *testing.T) {
+	const goroutines = 8
+	const tasks = 100
+
+	ucpContext, _ := NewUcpContext((&UcpParams{}).EnableTag().EnableWakeup())
+	defer ucpContext.Close()
+	ucpWorkerParams := (&UcpWorkerParams{}).SetThreadMode(UCS_THREAD_MODE_SINGLE)
+	ucpWorkerParams.WakeupTX().WakeupRX()
+	ucpWorker, err := ucpContext.NewWorker(ucpWorkerParams)
+
+	if err != nil {
+		t.Fatalf("Failed to create a worker %v", err)
+	}
+	defer ucpWorker.Close()
+
+	if ucpWorker.ThreadMode() == UCS_THREAD_MODE_MULTI {
+		t.Fatalf("Worker thread mode %v != requested", ucpWorker.ThreadMode())
+	}
+
+	loop, err := ucpWorker.StartProgressLoop((&UcpProgressLoopParams{}).SetMode(UcpProgressModeEvent))
+	if err != nil {
+		t.Fatalf("Failed to start progress loop %v", err)
+	}
+
+	// Tasks are serialized by the loop, so the counter needs no locking
+	counter := 0
+	done := make(chan error)
+	for i := 0; i < goroutines; i++ {
+		go func() {
+			for j := 0; j < tasks; j++ {
+				if err := loop.Execute(func() { counter++ }); err != nil {
+					done <- err
+					return
+				}
+			}
+			done <- nil
+		}()
+	}
+
+	for i := 0; i < goroutines; i++ {
+		if err := <-done; err != nil {
+			t.Fatalf("Failed to execute task %v", err)
+		}
+	}
+
+	loop.Stop()
+
+	if counter != goroutines*tasks {
+		t.Fatalf("Executed %d tasks != %d", counter, goroutines*tasks)
+	}
+
+	if err := loop.Execute(func() {}); err == nil {
+		t.Fatalf("Executed task on stopped loop")
+	}
+}
+
+func TestUcpWorkerCpus(t *testing.T) {
+	ucpContext, _ := NewUcpContext((&UcpParams{}).EnableTag())
+	defer ucpContext.Close()
+
+	plainWorker, err := ucpContext.NewWorker(&UcpWorkerParams{})
+	if err != nil {
+		t.Fatalf("Failed to create a worker %v", err)
+	}
+	defer plainWorker.Close()
+
+	if _, err := plainWorker.StartProgressLoop((&UcpProgressLoopParams{}).PinToWorkerCpus()); err == nil {
+		t.Fatalf("Progress loop is pinned to the worker without CPUs")
+	}
+
+	ucpWorker, err := ucpContext.NewWorker((&UcpWorkerParams{}).SetCpus([]int{0, -1}))
+	if err != nil {
+		t.Fatalf("Failed to create a worker %v", err)
+	}
+	defer ucpWorker.Close()
+
+	if cpus := ucpWorker.Cpus(); (len(cpus) != 1) || (cpus[0] != 0) {
+		t.Fatalf("Worker CPUs %v != [0]", cpus)
+	}
+
+	loop, err := ucpWorker.StartProgressLoop((&UcpProgressLoopParams{}).PinToWorkerCpus())
+	if err != nil {
+		t.Fatalf("Failed to start pinned progress loop %v", err)
+	}
+	defer loop.Stop()
+
+	if err := loop.Execute(func() {}); err != nil {
+		t.Fatalf("Failed to execute on pinned progress loop %v", err)
+	}
+
+	if err := LockOSThreadToCpus(nil); err == nil {
+		t.Fatalf("Thread is pinned to no CPUs")
+	}
+}
+
+func TestUcpWorkerProgressN(t *testing.T) {
+	entity := prepareContext(t, nil)
+	entity.worker, _ = entity.context.NewWorker(&UcpWorkerParams{})
+	createSelfEp(entity)
+	defer entity.Close()
+
+	for entity.worker.Progress() != 0 {
+	}
+
+	if events := entity.worker.ProgressN(0); events != 0 {
+		t.Fatalf("Non-positive budget progressed %v events", events)
+	}
+
+	start := time.Now()
+	if events := entity.worker.ProgressFor(time.Second); (events != 0) || (time.Since(start) >= time.Second) {
+		t.Fatalf("Idle worker progressed %v events in %v", events, time.Since(start))
+	}
+
+	recvBuffer := AllocateNativeMemory(4096)
+	defer FreeNativeMemory(recvBuffer)
+	recvRequest, _ := entity.worker.RecvTagNonBlocking(recvBuffer, 4096, selfEpTag, selfEpTag, nil)
+	sendRequest, _ := entity.selfEp.SendTagBytesNonBlocking(selfEpTag, make([]byte, 4096), nil)
+
+	for (recvRequest.GetStatus() == UCS_INPROGRESS) || (sendRequest.GetStatus() == UCS_INPROGRESS) {
+		entity.worker.ProgressN(1)
+		entity.worker.ProgressFor(time.Millisecond)
+	}
+	recvRequest.Close()
+	sendRequest.Close()
+}
+
+func TestUcpWorkerFence(t *testing.T) {
+	const dataLen uint64 = 8
+	ucpParams := (&UcpParams{}).EnableRMA().EnableTag()
+	sender := prepareContext(t, ucpParams)
+	receiver := prepareContext(t, ucpParams)
+	defer sender.Close()
+	defer receiver.Close()
+
+	ucpWorkerParams := (&UcpWorkerParams{}).SetThreadMode(UCS_THREAD_MODE_SERIALIZED)
+	receiver.worker, _ = receiver.context.NewWorker(ucpWorkerParams)
+	sender.worker, _ = sender.context.NewWorker(ucpWorkerParams)
+	connect(sender, receiver)
+
+	remoteMem := memoryAllocate(receiver, dataLen, UCS_MEMORY_TYPE_HOST)
+	rkeyBuffer, _ := receiver.mem.RkeyPack()
+	rkey, err := sender.ep.UnpackRkey(rkeyBuffer)
+	if err != nil {
+		t.Fatalf("Failed to unpack rkey %v", err)
+	}
+	defer rkey.Close()
+
+	// The second put to the same address is ordered after the first one
+	var requests []*UcpRequest
+	for i, data := range []string{"GO first", "GO fence"} {
+		if i != 0 {
+			if err := sender.worker.Fence(); err != nil {
+				t.Fatalf("Failed to fence %v", err)
+			}
+		}
+
+		sendMem := CBytes([]byte(data))
+		defer FreeNativeMemory(sendMem)
+		request, err := sender.ep.RmaPutNonBlocking(sendMem, dataLen, uint64(uintptr(remoteMem)), rkey, nil)
+		if err != nil {
+			t.Fatalf("Failed to put %v", err)
+		}
+		requests = append(requests, request)
+	}
+
+	flushRequest, _ := sender.worker.FlushNonBlocking(nil)
+	requests = append(requests, flushRequest)
+	for _, request := range requests {
+		for request.GetStatus() == UCS_INPROGRESS {
+			sender.worker.Progress()
+			receiver.worker.Progress()
+		}
+		request.Close()
+	}
+
+	if data := string(GoBytes(remoteMem, dataLen)); data != "GO fence" {
+		t.Fatalf("Remote data %q != last put", data)
+	}
+}